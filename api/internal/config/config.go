@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"os"
 	"strconv"
 	"time"
@@ -13,6 +14,7 @@ type Config struct {
 	Redis    RedisConfig
 	Auth     AuthConfig
 	App      AppConfig
+	Outbound OutboundConfig
 }
 
 type ServerConfig struct {
@@ -79,9 +81,26 @@ type AuthConfig struct {
 }
 
 type AppConfig struct {
-	Environment        string
-	BaseURL            string
-	CORSAllowedOrigins []string
+	Environment         string
+	BaseURL             string
+	CORSAllowedOrigins  []string
+	ICalSyncInterval    time.Duration // how often subscribed availability calendars are re-fetched
+	PDFRenderer         string        // "chrome" (headless Chrome) or "native" (pure Go)
+	ExportMaxConcurrent int           // export renders running at once per instance
+	ExportCacheTTL      time.Duration // how long export jobs and rendered files are kept
+	DigestHour          int           // hour (event time zone) at which daily notification digests are sent
+	PushSubject         string        // VAPID contact (mailto: or https: URL) sent to push services
+	WebhookDisableAfter int           // consecutive failed deliveries after which a webhook is disabled; 0 = never
+}
+
+// OutboundConfig restricts requests to URLs that users and admins configure,
+// like webhooks and calendar feeds. Internal addresses are denied unless
+// listed in Allow.
+type OutboundConfig struct {
+	Allow        []netip.Prefix
+	Deny         []netip.Prefix
+	HTTPSOnly    bool
+	MaxRedirects int
 }
 
 func Load() (*Config, error) {
@@ -119,15 +138,43 @@ func Load() (*Config, error) {
 			BcryptCost:   getEnvInt("AUTH_BCRYPT_COST", 12),
 		},
 		App: AppConfig{
-			Environment:        getEnv("APP_ENV", "production"),
-			BaseURL:            getEnv("APP_BASE_URL", "http://localhost:8080"),
-			CORSAllowedOrigins: getEnvSlice("CORS_ALLOWED_ORIGINS", []string{"http://localhost:5173"}),
+			Environment:         getEnv("APP_ENV", "production"),
+			BaseURL:             getEnv("APP_BASE_URL", "http://localhost:8080"),
+			CORSAllowedOrigins:  getEnvSlice("CORS_ALLOWED_ORIGINS", []string{"http://localhost:5173"}),
+			ICalSyncInterval:    getEnvDuration("ICAL_SYNC_INTERVAL", time.Hour),
+			PDFRenderer:         getEnv("PDF_RENDERER", "chrome"),
+			ExportMaxConcurrent: getEnvInt("EXPORT_MAX_CONCURRENT", 2),
+			ExportCacheTTL:      getEnvDuration("EXPORT_CACHE_TTL", 24*time.Hour),
+			DigestHour:          getEnvInt("NOTIFICATION_DIGEST_HOUR", 8),
+			PushSubject:         getEnv("VAPID_SUBJECT", ""),
+			WebhookDisableAfter: getEnvInt("WEBHOOK_DISABLE_AFTER", 5),
+		},
+		Outbound: OutboundConfig{
+			HTTPSOnly:    getEnvBool("OUTBOUND_HTTPS_ONLY", false),
+			MaxRedirects: getEnvInt("OUTBOUND_MAX_REDIRECTS", 3),
 		},
 	}
 
+	var err error
+	if cfg.Outbound.Allow, err = getEnvPrefixes("OUTBOUND_ALLOW_CIDRS"); err != nil {
+		return nil, err
+	}
+	if cfg.Outbound.Deny, err = getEnvPrefixes("OUTBOUND_DENY_CIDRS"); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
+// VAPIDSubject returns the contact sent to push services: VAPID_SUBJECT, or
+// the app's base URL.
+func (c AppConfig) VAPIDSubject() string {
+	if c.PushSubject != "" {
+		return c.PushSubject
+	}
+	return c.BaseURL
+}
+
 func (c *Config) IsDev() bool {
 	return c.App.Environment == "development"
 }
@@ -181,6 +228,23 @@ func getEnvSlice(key string, fallback []string) []string {
 	return fallback
 }
 
+// getEnvPrefixes parses a comma-separated list of CIDR ranges or addresses.
+func getEnvPrefixes(key string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, s := range getEnvSlice(key, nil) {
+		if addr, err := netip.ParseAddr(s); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid CIDR range %q", key, s)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
 func splitAndTrim(s string) []string {
 	var result []string
 	start := 0
@@ -40,6 +40,10 @@ func (h *AdminWebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Secret:       req.Secret,
 		Format:       req.Format,
 		TriggerTypes: req.TriggerTypes,
+		Template:     req.Template,
+		ContentType:  req.ContentType,
+		Headers:      req.Headers,
+		Filters:      req.Filters,
 	})
 	if err != nil {
 		model.ErrorResponse(w, err)
@@ -48,6 +52,16 @@ func (h *AdminWebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
 	model.JSON(w, http.StatusCreated, webhook)
 }
 
+// Triggers lists the trigger types global webhooks can subscribe to.
+func (h *AdminWebhookHandler) Triggers(w http.ResponseWriter, r *http.Request) {
+	model.JSON(w, http.StatusOK, h.webhookService.ListTriggers(service.WebhookScopeGlobal))
+}
+
+// Preview renders a template webhook body against sample data.
+func (h *AdminWebhookHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	previewWebhookTemplate(w, r, h.webhookService, nil)
+}
+
 func (h *AdminWebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
 	webhookID, err := uuid.Parse(chi.URLParam(r, "webhookId"))
 	if err != nil {
@@ -68,6 +82,10 @@ func (h *AdminWebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
 		Format:       req.Format,
 		TriggerTypes: req.TriggerTypes,
 		IsEnabled:    req.IsEnabled,
+		Template:     req.Template,
+		ContentType:  req.ContentType,
+		Headers:      req.Headers,
+		Filters:      req.Filters,
 	})
 	if err != nil {
 		model.ErrorResponse(w, err)
@@ -87,7 +105,24 @@ func (h *AdminWebhookHandler) Test(w http.ResponseWriter, r *http.Request) {
 		model.ErrorResponse(w, err)
 		return
 	}
-	model.JSON(w, http.StatusOK, map[string]string{"message": "test webhook sent"})
+	model.JSON(w, http.StatusOK, map[string]string{"message": "test webhook queued"})
+}
+
+// RotateSecret replaces the webhook's secret and returns the new one. This is
+// the only time the secret is shown.
+func (h *AdminWebhookHandler) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := uuid.Parse(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid webhook ID"))
+		return
+	}
+
+	rotation, err := h.webhookService.RotateSecret(r.Context(), webhookID)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, rotation)
 }
 
 func (h *AdminWebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
@@ -103,3 +138,34 @@ func (h *AdminWebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 	model.JSON(w, http.StatusOK, map[string]string{"message": "webhook deleted"})
 }
+
+func (h *AdminWebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := uuid.Parse(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid webhook ID"))
+		return
+	}
+	if _, err := h.webhookService.GetByID(r.Context(), webhookID); err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	listWebhookDeliveries(w, r, h.webhookService, webhookID)
+}
+
+func (h *AdminWebhookHandler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := uuid.Parse(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid webhook ID"))
+		return
+	}
+	getWebhookDelivery(w, r, h.webhookService, webhookID)
+}
+
+func (h *AdminWebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := uuid.Parse(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid webhook ID"))
+		return
+	}
+	redeliverWebhook(w, r, h.webhookService, webhookID)
+}
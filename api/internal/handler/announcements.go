@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/server/middleware"
+	"github.com/echtkpvl/rncasp/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type AnnouncementHandler struct {
+	announcementService *service.AnnouncementService
+}
+
+func NewAnnouncementHandler(announcementService *service.AnnouncementService) *AnnouncementHandler {
+	return &AnnouncementHandler{announcementService: announcementService}
+}
+
+type createAnnouncementRequest struct {
+	Title       string   `json:"title"`
+	Body        string   `json:"body"`
+	TeamIDs     []string `json:"team_ids"`
+	WindowStart *string  `json:"window_start"`
+	WindowEnd   *string  `json:"window_end"`
+}
+
+func (h *AnnouncementHandler) List(w http.ResponseWriter, r *http.Request) {
+	announcements, err := h.announcementService.ListByEvent(r.Context(), chi.URLParam(r, "slug"))
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, announcements)
+}
+
+func (h *AnnouncementHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrUnauthorized, "not authenticated"))
+		return
+	}
+
+	var req createAnnouncementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid request body"))
+		return
+	}
+
+	input := service.CreateAnnouncementInput{
+		Title:   req.Title,
+		Body:    req.Body,
+		TeamIDs: req.TeamIDs,
+	}
+	if req.WindowStart != nil {
+		t, err := time.Parse(time.RFC3339, *req.WindowStart)
+		if err != nil {
+			model.ErrorResponse(w, model.NewFieldError(model.ErrInvalidInput, "window_start", "invalid datetime format, use RFC3339"))
+			return
+		}
+		input.WindowStart = &t
+	}
+	if req.WindowEnd != nil {
+		t, err := time.Parse(time.RFC3339, *req.WindowEnd)
+		if err != nil {
+			model.ErrorResponse(w, model.NewFieldError(model.ErrInvalidInput, "window_end", "invalid datetime format, use RFC3339"))
+			return
+		}
+		input.WindowEnd = &t
+	}
+
+	announcement, err := h.announcementService.Create(r.Context(), chi.URLParam(r, "slug"), *userID, input)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusCreated, announcement)
+}
+
+func (h *AnnouncementHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	announcementID, err := uuid.Parse(chi.URLParam(r, "announcementId"))
+	if err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid announcement ID"))
+		return
+	}
+
+	if err := h.announcementService.Delete(r.Context(), chi.URLParam(r, "slug"), announcementID); err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, map[string]string{"message": "announcement deleted"})
+}
+
+// ListPublic returns the announcements shown on a public event's page.
+func (h *AnnouncementHandler) ListPublic(w http.ResponseWriter, r *http.Request) {
+	announcements, err := h.announcementService.ListPublic(r.Context(), chi.URLParam(r, "slug"))
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, announcements)
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/server/middleware"
+	"github.com/echtkpvl/rncasp/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// APITokenHandler manages personal API tokens, which scripts send as
+// "Authorization: Bearer" instead of logging in.
+type APITokenHandler struct {
+	apiTokenService *service.APITokenService
+}
+
+func NewAPITokenHandler(apiTokenService *service.APITokenService) *APITokenHandler {
+	return &APITokenHandler{apiTokenService: apiTokenService}
+}
+
+type createAPITokenRequest struct {
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	EventID   *string  `json:"event_id"`
+	ExpiresAt *string  `json:"expires_at"`
+}
+
+// List returns the API tokens of the logged-in user.
+func (h *APITokenHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrUnauthorized, "not authenticated"))
+		return
+	}
+
+	tokens, err := h.apiTokenService.List(r.Context(), userID)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, tokens)
+}
+
+// Create generates an API token. The response is the only time the token is
+// shown.
+func (h *APITokenHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrUnauthorized, "not authenticated"))
+		return
+	}
+
+	var req createAPITokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid request body"))
+		return
+	}
+
+	input := service.CreateAPITokenInput{
+		Name:   req.Name,
+		Scopes: req.Scopes,
+	}
+	if req.EventID != nil {
+		id, err := uuid.Parse(*req.EventID)
+		if err != nil {
+			model.ErrorResponse(w, model.NewFieldError(model.ErrInvalidInput, "event_id", "invalid event ID"))
+			return
+		}
+		input.EventID = &id
+	}
+	if req.ExpiresAt != nil {
+		t, err := time.Parse(time.RFC3339, *req.ExpiresAt)
+		if err != nil {
+			model.ErrorResponse(w, model.NewFieldError(model.ErrInvalidInput, "expires_at", "invalid time format, use RFC3339"))
+			return
+		}
+		input.ExpiresAt = &t
+	}
+
+	token, err := h.apiTokenService.Create(r.Context(), *userID, input)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusCreated, token)
+}
+
+// Revoke deletes one of the logged-in user's API tokens.
+func (h *APITokenHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrUnauthorized, "not authenticated"))
+		return
+	}
+
+	tokenID, err := uuid.Parse(chi.URLParam(r, "tokenId"))
+	if err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid token ID"))
+		return
+	}
+
+	if err := h.apiTokenService.Revoke(r.Context(), *userID, tokenID); err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, map[string]string{"message": "token revoked"})
+}
+
+// ListAll returns the API tokens of all users (super-admin only).
+func (h *APITokenHandler) ListAll(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.apiTokenService.List(r.Context(), nil)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, tokens)
+}
+
+// RevokeAny deletes an API token of any user (super-admin only).
+func (h *APITokenHandler) RevokeAny(w http.ResponseWriter, r *http.Request) {
+	tokenID, err := uuid.Parse(chi.URLParam(r, "tokenId"))
+	if err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid token ID"))
+		return
+	}
+
+	if err := h.apiTokenService.RevokeAny(r.Context(), tokenID); err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, map[string]string{"message": "token revoked"})
+}
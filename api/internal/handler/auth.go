@@ -13,16 +13,18 @@ import (
 )
 
 type AuthHandler struct {
-	authService *service.AuthService
-	authCfg     *config.AuthConfig
-	isDev       bool
+	authService  *service.AuthService
+	oauthService *service.OAuthService
+	authCfg      *config.AuthConfig
+	isDev        bool
 }
 
-func NewAuthHandler(authService *service.AuthService, authCfg *config.AuthConfig, isDev bool) *AuthHandler {
+func NewAuthHandler(authService *service.AuthService, oauthService *service.OAuthService, authCfg *config.AuthConfig, isDev bool) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
-		authCfg:     authCfg,
-		isDev:       isDev,
+		authService:  authService,
+		oauthService: oauthService,
+		authCfg:      authCfg,
+		isDev:        isDev,
 	}
 }
 
@@ -127,6 +129,10 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Sessions from an OIDC provider with RP-initiated logout also end at
+	// the provider; the client navigates to logout_url for that.
+	logoutURL := h.oauthService.LogoutURL(r.Context(), cookie.Value)
+
 	if err := h.authService.Logout(r.Context(), cookie.Value); err != nil {
 		model.ErrorResponse(w, err)
 		return
@@ -144,7 +150,11 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 		SameSite: http.SameSiteLaxMode,
 	})
 
-	model.JSON(w, http.StatusOK, map[string]string{"message": "logged out"})
+	resp := map[string]string{"message": "logged out"}
+	if logoutURL != "" {
+		resp["logout_url"] = logoutURL
+	}
+	model.JSON(w, http.StatusOK, resp)
 }
 
 type updateProfileRequest struct {
@@ -153,6 +163,7 @@ type updateProfileRequest struct {
 	Email       *string `json:"email"`
 	Password    *string `json:"password"`
 	TimeFormat  *string `json:"time_format"`
+	Phone       *string `json:"phone"`
 }
 
 func (h *AuthHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
@@ -174,6 +185,7 @@ func (h *AuthHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 		Email:       req.Email,
 		Password:    req.Password,
 		TimeFormat:  req.TimeFormat,
+		Phone:       req.Phone,
 	})
 	if err != nil {
 		model.ErrorResponse(w, err)
@@ -2,7 +2,9 @@ package handler
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/echtkpvl/rncasp/internal/model"
@@ -161,3 +163,99 @@ func (h *AvailabilityHandler) SetForUser(w http.ResponseWriter, r *http.Request)
 	}
 	model.JSON(w, http.StatusOK, result)
 }
+
+// ImportMine imports busy times from an uploaded .ics file into the current
+// user's availability. Accepts a multipart "file" field or a raw
+// text/calendar body.
+func (h *AvailabilityHandler) ImportMine(w http.ResponseWriter, r *http.Request) {
+	slug := chi.URLParam(r, "slug")
+	callerID := middleware.GetUserID(r.Context())
+	if callerID == nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrUnauthorized, "not authenticated"))
+		return
+	}
+	callerRole := middleware.GetRole(r.Context())
+
+	r.Body = http.MaxBytesReader(w, r.Body, service.MaxICalImportSize)
+
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			model.ErrorResponse(w, model.NewFieldError(model.ErrInvalidInput, "file", "missing or too large calendar file"))
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	result, err := h.availabilityService.ImportICal(r.Context(), slug, *callerID, body, *callerID, callerRole)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, result)
+}
+
+type setICalFeedRequest struct {
+	URL string `json:"url"`
+}
+
+// GetMyFeed returns the current user's calendar subscription for an event.
+func (h *AvailabilityHandler) GetMyFeed(w http.ResponseWriter, r *http.Request) {
+	slug := chi.URLParam(r, "slug")
+	callerID := middleware.GetUserID(r.Context())
+	if callerID == nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrUnauthorized, "not authenticated"))
+		return
+	}
+
+	feed, err := h.availabilityService.GetICalFeed(r.Context(), slug, *callerID)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, feed)
+}
+
+// SetMyFeed subscribes the current user's availability to a calendar URL.
+func (h *AvailabilityHandler) SetMyFeed(w http.ResponseWriter, r *http.Request) {
+	slug := chi.URLParam(r, "slug")
+	callerID := middleware.GetUserID(r.Context())
+	if callerID == nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrUnauthorized, "not authenticated"))
+		return
+	}
+	callerRole := middleware.GetRole(r.Context())
+
+	var req setICalFeedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid request body"))
+		return
+	}
+
+	feed, err := h.availabilityService.SetICalFeed(r.Context(), slug, *callerID, req.URL, *callerID, callerRole)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, feed)
+}
+
+// DeleteMyFeed removes the current user's calendar subscription and the
+// availability imported from it.
+func (h *AvailabilityHandler) DeleteMyFeed(w http.ResponseWriter, r *http.Request) {
+	slug := chi.URLParam(r, "slug")
+	callerID := middleware.GetUserID(r.Context())
+	if callerID == nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrUnauthorized, "not authenticated"))
+		return
+	}
+	callerRole := middleware.GetRole(r.Context())
+
+	if err := h.availabilityService.DeleteICalFeed(r.Context(), slug, *callerID, *callerID, callerRole); err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -29,6 +29,7 @@ type createEventRequest struct {
 	StartTime        string  `json:"start_time"`
 	EndTime          string  `json:"end_time"`
 	TimeGranularity  string  `json:"time_granularity"`
+	Timezone         string  `json:"timezone"`
 }
 
 type updateEventRequest struct {
@@ -40,6 +41,7 @@ type updateEventRequest struct {
 	StartTime        *string `json:"start_time"`
 	EndTime          *string `json:"end_time"`
 	TimeGranularity  *string `json:"time_granularity"`
+	Timezone         *string `json:"timezone"`
 }
 
 type setLockedRequest struct {
@@ -131,6 +133,7 @@ func (h *EventHandler) Create(w http.ResponseWriter, r *http.Request) {
 		StartTime:        startTime,
 		EndTime:          endTime,
 		TimeGranularity:  req.TimeGranularity,
+		Timezone:         req.Timezone,
 		CreatedBy:        *userID,
 	})
 	if err != nil {
@@ -156,6 +159,7 @@ func (h *EventHandler) Update(w http.ResponseWriter, r *http.Request) {
 		Location:         req.Location,
 		ParticipantCount: req.ParticipantCount,
 		TimeGranularity:  req.TimeGranularity,
+		Timezone:         req.Timezone,
 	}
 
 	if req.StartTime != nil {
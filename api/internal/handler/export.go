@@ -3,8 +3,10 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/echtkpvl/rncasp/internal/model"
 	"github.com/echtkpvl/rncasp/internal/pdf"
@@ -16,18 +18,27 @@ import (
 
 type ExportHandler struct {
 	exportService *service.ExportService
+	exportJobs    *service.ExportJobService
 	baseURL       string
 }
 
-func NewExportHandler(exportService *service.ExportService, baseURL string) *ExportHandler {
-	return &ExportHandler{exportService: exportService, baseURL: baseURL}
+func NewExportHandler(exportService *service.ExportService, exportJobs *service.ExportJobService, baseURL string) *ExportHandler {
+	return &ExportHandler{exportService: exportService, exportJobs: exportJobs, baseURL: baseURL}
 }
 
 // ExportCSV downloads a CSV of shifts for an event.
 func (h *ExportHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
 	slug := chi.URLParam(r, "slug")
 
-	data, filename, err := h.exportService.ExportCSV(r.Context(), slug)
+	opts, err := parseCSVOptions(r.URL.Query())
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	opts.ViewerID = middleware.GetUserID(r.Context())
+	opts.ViewerRole = middleware.GetRole(r.Context())
+
+	data, filename, err := h.exportService.ExportCSV(r.Context(), slug, opts)
 	if err != nil {
 		model.ErrorResponse(w, err)
 		return
@@ -58,59 +69,162 @@ func (h *ExportHandler) ExportICal(w http.ResponseWriter, r *http.Request) {
 // ExportPDF downloads a PDF of the shift plan for an event.
 func (h *ExportHandler) ExportPDF(w http.ResponseWriter, r *http.Request) {
 	slug := chi.URLParam(r, "slug")
-	q := r.URL.Query()
+	opts, err := h.pdfOptions(r, slug)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
 
-	opts := pdf.PDFOptions{
-		Layout:         q.Get("layout"),
-		PaperSize:      q.Get("paper"),
-		Landscape:      q.Get("landscape") != "false",
-		ShowCoverage:   q.Get("coverage") != "false",
+	// Goes through the job queue so the result is cached and the render
+	// counts against the concurrency limit
+	data, filename, err := h.exportJobs.RunPDF(r.Context(), slug, opts)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
 	}
-	if opts.Layout == "" {
-		opts.Layout = "grid"
+	writeExportFile(w, data, filename)
+}
+
+// pdfOptions parses the PDF export options and checks that the viewer may
+// see phone numbers if they asked for them.
+func (h *ExportHandler) pdfOptions(r *http.Request, slug string) (pdf.PDFOptions, error) {
+	opts, err := parsePDFOptions(r.URL.Query())
+	if err != nil {
+		return pdf.PDFOptions{}, err
 	}
-	if opts.PaperSize == "" {
-		opts.PaperSize = "A4"
+	if opts.ShowPhones {
+		ok, err := h.exportService.CanExportPrivate(r.Context(), slug, middleware.GetUserID(r.Context()), middleware.GetRole(r.Context()))
+		if err != nil {
+			return pdf.PDFOptions{}, err
+		}
+		if !ok {
+			return pdf.PDFOptions{}, model.NewFieldError(model.ErrForbidden, "phones", "only admins can export phone numbers")
+		}
 	}
+	return opts, nil
+}
+
+// exportFilters holds the shift filters shared by the CSV and PDF exports.
+type exportFilters struct {
+	Start   *time.Time
+	End     *time.Time
+	UserIDs []string
+	TeamIDs []string
+}
+
+// parseExportFilters reads start/end (RFC3339) and the comma-separated
+// users/teams query params. Unparseable times are ignored.
+func parseExportFilters(q url.Values) exportFilters {
+	var f exportFilters
 	if s := q.Get("start"); s != "" {
 		if t, err := time.Parse(time.RFC3339, s); err == nil {
-			opts.Start = &t
+			f.Start = &t
 		}
 	}
 	if s := q.Get("end"); s != "" {
 		if t, err := time.Parse(time.RFC3339, s); err == nil {
-			opts.End = &t
+			f.End = &t
 		}
 	}
 	if u := q.Get("users"); u != "" {
-		opts.UserIDs = strings.Split(u, ",")
+		f.UserIDs = strings.Split(u, ",")
 	}
 	if t := q.Get("teams"); t != "" {
-		opts.TeamIDs = strings.Split(t, ",")
-	}
-	if q.Get("onePerPage") == "true" {
-		opts.OnePerPage = true
+		f.TeamIDs = strings.Split(t, ",")
 	}
+	return f
+}
 
-	data, filename, err := h.exportService.ExportPDF(r.Context(), slug, opts)
-	if err != nil {
-		model.ErrorResponse(w, err)
-		return
+// parsePDFOptions reads the PDF export query params: the shared filters plus
+// layout, paper, landscape, coverage, onePerPage, bundle and phones.
+func parsePDFOptions(q url.Values) (pdf.PDFOptions, error) {
+	f := parseExportFilters(q)
+	opts := pdf.PDFOptions{
+		Layout:       q.Get("layout"),
+		PaperSize:    q.Get("paper"),
+		Landscape:    q.Get("landscape") != "false",
+		ShowCoverage: q.Get("coverage") != "false",
+		Start:        f.Start,
+		End:          f.End,
+		UserIDs:      f.UserIDs,
+		TeamIDs:      f.TeamIDs,
+		OnePerPage:   q.Get("onePerPage") == "true",
+		Bundle:       q.Get("bundle"),
+		ShowPhones:   q.Get("phones") == "true",
+	}
+	if opts.Layout == "" {
+		opts.Layout = "grid"
+	}
+	if opts.PaperSize == "" {
+		opts.PaperSize = "A4"
+	}
+	if !pdf.IsLayout(opts.Layout) {
+		return pdf.PDFOptions{}, model.NewFieldError(model.ErrInvalidInput, "layout", "must be grid, list, timesheet, certificate, or roster")
 	}
+	// Only the roster has a phone column
+	opts.ShowPhones = opts.ShowPhones && opts.Layout == "roster"
+	switch opts.Bundle {
+	case "", "pdf":
+		opts.Bundle = ""
+	case "zip":
+		if !pdf.IsPerUserLayout(opts.Layout) {
+			return pdf.PDFOptions{}, model.NewFieldError(model.ErrInvalidInput, "bundle", "zip is only available for the list, timesheet, and certificate layouts")
+		}
+	default:
+		return pdf.PDFOptions{}, model.NewFieldError(model.ErrInvalidInput, "bundle", "must be pdf or zip")
+	}
+	return opts, nil
+}
 
-	w.Header().Set("Content-Type", "application/pdf")
-	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
-	w.WriteHeader(http.StatusOK)
-	w.Write(data)
+// csvDelimiters maps the named delimiters accepted by the CSV export.
+var csvDelimiters = map[string]rune{
+	"comma":     ',',
+	"semicolon": ';',
+	"tab":       '\t',
+	"pipe":      '|',
+}
+
+// parseCSVOptions reads the CSV export query params: the shared filters plus
+// columns (comma-separated keys), delimiter (name or single character),
+// date_format, time_format and aggregate.
+func parseCSVOptions(q url.Values) (service.CSVOptions, error) {
+	f := parseExportFilters(q)
+	opts := service.CSVOptions{
+		Start:      f.Start,
+		End:        f.End,
+		UserIDs:    f.UserIDs,
+		TeamIDs:    f.TeamIDs,
+		DateFormat: q.Get("date_format"),
+		TimeFormat: q.Get("time_format"),
+		Aggregate:  q.Get("aggregate") == "true",
+	}
+	if c := q.Get("columns"); c != "" {
+		opts.Columns = strings.Split(c, ",")
+	}
+	if d := q.Get("delimiter"); d != "" {
+		if r, ok := csvDelimiters[d]; ok {
+			opts.Delimiter = r
+		} else if utf8.RuneCountInString(d) == 1 {
+			opts.Delimiter, _ = utf8.DecodeRuneInString(d)
+		} else {
+			return service.CSVOptions{}, model.NewFieldError(model.ErrInvalidInput, "delimiter", "must be comma, semicolon, tab, pipe, or a single character")
+		}
+	}
+	return opts, nil
 }
 
 // iCal Token management
 
 type createTokenRequest struct {
-	Label   string  `json:"label"`
-	Scope   string  `json:"scope"`
-	EventID *string `json:"event_id"`
-	TeamID  *string `json:"team_id"`
+	Label        string  `json:"label"`
+	Scope        string  `json:"scope"`
+	EventID      *string `json:"event_id"`
+	TeamID       *string `json:"team_id"`
+	AlarmMinutes *int32  `json:"alarm_minutes"`
+}
+
+type updateTokenRequest struct {
+	AlarmMinutes *int32 `json:"alarm_minutes"`
 }
 
 func (h *ExportHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
@@ -127,8 +241,9 @@ func (h *ExportHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
 	}
 
 	input := service.CreateICalTokenInput{
-		Label: req.Label,
-		Scope: req.Scope,
+		Label:        req.Label,
+		Scope:        req.Scope,
+		AlarmMinutes: req.AlarmMinutes,
 	}
 
 	if req.EventID != nil {
@@ -195,18 +310,78 @@ func (h *ExportHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
 	model.JSON(w, http.StatusOK, map[string]string{"message": "token revoked"})
 }
 
+// UpdateToken changes the reminder settings of an iCal token.
+// Sending alarm_minutes: null removes reminders.
+func (h *ExportHandler) UpdateToken(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrUnauthorized, "not authenticated"))
+		return
+	}
+
+	tokenID, err := uuid.Parse(chi.URLParam(r, "tokenId"))
+	if err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid token ID"))
+		return
+	}
+
+	var req updateTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid request body"))
+		return
+	}
+
+	if err := h.exportService.UpdateTokenAlarm(r.Context(), tokenID, *userID, req.AlarmMinutes); err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+
+	model.JSON(w, http.StatusOK, map[string]string{"message": "token updated"})
+}
+
 // ServeICalFeed serves an iCal subscription feed (public, no auth required).
+// Feeds carry an ETag derived from the events' plan versions and the people
+// and teams they name, so polling clients get 304 Not Modified without the
+// feed being rendered until something in the feed changes.
 func (h *ExportHandler) ServeICalFeed(w http.ResponseWriter, r *http.Request) {
 	rawToken := chi.URLParam(r, "token")
 
-	data, err := h.exportService.ServeICalSubscription(r.Context(), rawToken)
+	sub, err := h.exportService.OpenICalSubscription(r.Context(), rawToken)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", sub.ETag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), sub.ETag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	data, err := h.exportService.ServeICalSubscription(r.Context(), sub)
 	if err != nil {
+		w.Header().Del("ETag")
 		model.ErrorResponse(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
-	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
 	w.WriteHeader(http.StatusOK)
 	w.Write(data)
 }
+
+// etagMatches reports whether an If-None-Match header matches etag, using
+// the weak comparison required for If-None-Match (RFC 9110 section 13.1.2).
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
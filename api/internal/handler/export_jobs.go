@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/server/middleware"
+	"github.com/echtkpvl/rncasp/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// CreateJob starts a background export. The format query param selects the
+// export (currently only pdf); the remaining params are the usual export
+// options.
+func (h *ExportHandler) CreateJob(w http.ResponseWriter, r *http.Request) {
+	slug := chi.URLParam(r, "slug")
+	q := r.URL.Query()
+
+	if format := q.Get("format"); format != "" && format != "pdf" {
+		model.ErrorResponse(w, model.NewFieldError(model.ErrInvalidInput, "format", "must be pdf"))
+		return
+	}
+
+	opts, err := h.pdfOptions(r, slug)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+
+	job, err := h.exportJobs.SubmitPDF(r.Context(), slug, opts, middleware.GetUserID(r.Context()), false)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	withDownloadURL(job, "/api/export/jobs/")
+	model.JSON(w, http.StatusAccepted, job)
+}
+
+// GetJob returns the status of an export job.
+func (h *ExportHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.exportJobs.Get(r.Context(), chi.URLParam(r, "jobId"),
+		middleware.GetUserID(r.Context()), middleware.GetRole(r.Context()), false)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	withDownloadURL(job, "/api/export/jobs/")
+	model.JSON(w, http.StatusOK, job)
+}
+
+// DownloadJob serves the file of a finished export job.
+func (h *ExportHandler) DownloadJob(w http.ResponseWriter, r *http.Request) {
+	data, filename, err := h.exportJobs.Download(r.Context(), chi.URLParam(r, "jobId"),
+		middleware.GetUserID(r.Context()), middleware.GetRole(r.Context()), false)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	writeExportFile(w, data, filename)
+}
+
+// CreateJob starts a background PDF export of a public event.
+func (h *PublicHandler) CreateJob(w http.ResponseWriter, r *http.Request) {
+	slug := chi.URLParam(r, "slug")
+	q := r.URL.Query()
+
+	event, err := h.eventService.GetBySlug(r.Context(), slug)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	if !event.IsPublic {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrNotFound, "event not found"))
+		return
+	}
+	if format := q.Get("format"); format != "" && format != "pdf" {
+		model.ErrorResponse(w, model.NewFieldError(model.ErrInvalidInput, "format", "must be pdf"))
+		return
+	}
+
+	opts, err := parsePDFOptions(q)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	if opts.ShowPhones {
+		model.ErrorResponse(w, model.NewFieldError(model.ErrForbidden, "phones", "phone numbers are not available publicly"))
+		return
+	}
+
+	job, err := h.exportJobs.SubmitPDF(r.Context(), slug, opts, nil, true)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	withDownloadURL(job, "/api/public/export/jobs/")
+	model.JSON(w, http.StatusAccepted, job)
+}
+
+// GetJob returns the status of a public export job.
+func (h *PublicHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.exportJobs.Get(r.Context(), chi.URLParam(r, "jobId"), nil, "", true)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	withDownloadURL(job, "/api/public/export/jobs/")
+	model.JSON(w, http.StatusOK, job)
+}
+
+// DownloadJob serves the file of a finished public export job.
+func (h *PublicHandler) DownloadJob(w http.ResponseWriter, r *http.Request) {
+	data, filename, err := h.exportJobs.Download(r.Context(), chi.URLParam(r, "jobId"), nil, "", true)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	writeExportFile(w, data, filename)
+}
+
+// withDownloadURL sets the download link once the job has finished.
+func withDownloadURL(job *service.ExportJobResponse, prefix string) {
+	if job.Status == service.ExportJobDone {
+		u := prefix + job.ID + "/download"
+		job.DownloadURL = &u
+	}
+}
+
+func writeExportFile(w http.ResponseWriter, data []byte, filename string) {
+	contentType := "application/octet-stream"
+	switch {
+	case strings.HasSuffix(filename, ".pdf"):
+		contentType = "application/pdf"
+	case strings.HasSuffix(filename, ".zip"):
+		contentType = "application/zip"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
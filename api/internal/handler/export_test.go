@@ -0,0 +1,28 @@
+package handler
+
+import "testing"
+
+func TestEtagMatches(t *testing.T) {
+	const etag = `"abc123"`
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{name: "empty header", header: "", want: false},
+		{name: "exact match", header: `"abc123"`, want: true},
+		{name: "weak match", header: `W/"abc123"`, want: true},
+		{name: "list match", header: `"old", "abc123"`, want: true},
+		{name: "wildcard", header: "*", want: true},
+		{name: "mismatch", header: `"other"`, want: false},
+		{name: "unquoted", header: "abc123", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := etagMatches(tt.header, etag); got != tt.want {
+				t.Errorf("etagMatches(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
@@ -141,3 +141,125 @@ func (h *NotificationHandler) UpdatePreference(w http.ResponseWriter, r *http.Re
 	}
 	model.JSON(w, http.StatusOK, map[string]string{"message": "preference updated"})
 }
+
+func (h *NotificationHandler) GetDeliveryModes(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrUnauthorized, "not authenticated"))
+		return
+	}
+
+	modes, err := h.notificationService.GetDeliveryModes(r.Context(), *userID)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, modes)
+}
+
+type updateDeliveryModeRequest struct {
+	Channel string `json:"channel"`
+	Mode    string `json:"mode"`
+}
+
+func (h *NotificationHandler) UpdateDeliveryMode(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrUnauthorized, "not authenticated"))
+		return
+	}
+
+	var req updateDeliveryModeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid request body"))
+		return
+	}
+
+	if err := h.notificationService.UpdateDeliveryMode(r.Context(), *userID, req.Channel, req.Mode); err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, map[string]string{"message": "delivery mode updated"})
+}
+
+func (h *NotificationHandler) ListEventStates(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrUnauthorized, "not authenticated"))
+		return
+	}
+
+	states, err := h.notificationService.ListEventStates(r.Context(), *userID)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, states)
+}
+
+func (h *NotificationHandler) GetEventSettings(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrUnauthorized, "not authenticated"))
+		return
+	}
+
+	settings, err := h.notificationService.GetEventSettings(r.Context(), *userID, chi.URLParam(r, "slug"))
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, settings)
+}
+
+type setEventStateRequest struct {
+	State string `json:"state"`
+}
+
+func (h *NotificationHandler) SetEventState(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrUnauthorized, "not authenticated"))
+		return
+	}
+
+	var req setEventStateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid request body"))
+		return
+	}
+
+	if err := h.notificationService.SetEventState(r.Context(), *userID, chi.URLParam(r, "slug"), req.State); err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, map[string]string{"message": "event notification state updated"})
+}
+
+// updateEventPreferenceRequest overrides a preference for one event; a null
+// is_enabled removes the override.
+type updateEventPreferenceRequest struct {
+	TriggerType string `json:"trigger_type"`
+	Channel     string `json:"channel"`
+	IsEnabled   *bool  `json:"is_enabled"`
+}
+
+func (h *NotificationHandler) UpdateEventPreference(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrUnauthorized, "not authenticated"))
+		return
+	}
+
+	var req updateEventPreferenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid request body"))
+		return
+	}
+
+	if err := h.notificationService.UpdateEventPreference(r.Context(), *userID, chi.URLParam(r, "slug"), req.TriggerType, req.Channel, req.IsEnabled); err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, map[string]string{"message": "preference updated"})
+}
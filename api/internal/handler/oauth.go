@@ -31,23 +31,29 @@ func NewOAuthHandler(oauthService *service.OAuthService, authCfg *config.AuthCon
 
 type createProviderRequest struct {
 	Name         string `json:"name"`
+	ProviderType string `json:"provider_type"`
 	ClientID     string `json:"client_id"`
 	ClientSecret string `json:"client_secret"`
+	IssuerURL    string `json:"issuer_url"`
 	AuthorizeURL string `json:"authorize_url"`
 	TokenURL     string `json:"token_url"`
 	UserinfoURL  string `json:"userinfo_url"`
 	Scopes       string `json:"scopes"`
+	RPLogout     bool   `json:"rp_logout"`
 }
 
 type updateProviderRequest struct {
 	Name         *string `json:"name"`
+	ProviderType *string `json:"provider_type"`
 	ClientID     *string `json:"client_id"`
 	ClientSecret *string `json:"client_secret"`
+	IssuerURL    *string `json:"issuer_url"`
 	AuthorizeURL *string `json:"authorize_url"`
 	TokenURL     *string `json:"token_url"`
 	UserinfoURL  *string `json:"userinfo_url"`
 	Scopes       *string `json:"scopes"`
 	IsEnabled    *bool   `json:"is_enabled"`
+	RPLogout     *bool   `json:"rp_logout"`
 }
 
 func (h *OAuthHandler) ListProviders(w http.ResponseWriter, r *http.Request) {
@@ -93,12 +99,15 @@ func (h *OAuthHandler) CreateProvider(w http.ResponseWriter, r *http.Request) {
 
 	provider, err := h.oauthService.CreateProvider(r.Context(), service.CreateProviderInput{
 		Name:         req.Name,
+		ProviderType: req.ProviderType,
 		ClientID:     req.ClientID,
 		ClientSecret: req.ClientSecret,
+		IssuerURL:    req.IssuerURL,
 		AuthorizeURL: req.AuthorizeURL,
 		TokenURL:     req.TokenURL,
 		UserinfoURL:  req.UserinfoURL,
 		Scopes:       req.Scopes,
+		RPLogout:     req.RPLogout,
 	})
 	if err != nil {
 		model.ErrorResponse(w, err)
@@ -123,13 +132,16 @@ func (h *OAuthHandler) UpdateProvider(w http.ResponseWriter, r *http.Request) {
 
 	provider, err := h.oauthService.UpdateProvider(r.Context(), id, service.UpdateProviderInput{
 		Name:         req.Name,
+		ProviderType: req.ProviderType,
 		ClientID:     req.ClientID,
 		ClientSecret: req.ClientSecret,
+		IssuerURL:    req.IssuerURL,
 		AuthorizeURL: req.AuthorizeURL,
 		TokenURL:     req.TokenURL,
 		UserinfoURL:  req.UserinfoURL,
 		Scopes:       req.Scopes,
 		IsEnabled:    req.IsEnabled,
+		RPLogout:     req.RPLogout,
 	})
 	if err != nil {
 		model.ErrorResponse(w, err)
@@ -2,11 +2,8 @@ package handler
 
 import (
 	"net/http"
-	"strings"
-	"time"
 
 	"github.com/echtkpvl/rncasp/internal/model"
-	"github.com/echtkpvl/rncasp/internal/pdf"
 	"github.com/echtkpvl/rncasp/internal/service"
 	"github.com/go-chi/chi/v5"
 )
@@ -15,10 +12,11 @@ type PublicHandler struct {
 	eventService  *service.EventService
 	shiftService  *service.ShiftService
 	exportService *service.ExportService
+	exportJobs    *service.ExportJobService
 }
 
-func NewPublicHandler(eventService *service.EventService, shiftService *service.ShiftService, exportService *service.ExportService) *PublicHandler {
-	return &PublicHandler{eventService: eventService, shiftService: shiftService, exportService: exportService}
+func NewPublicHandler(eventService *service.EventService, shiftService *service.ShiftService, exportService *service.ExportService, exportJobs *service.ExportJobService) *PublicHandler {
+	return &PublicHandler{eventService: eventService, shiftService: shiftService, exportService: exportService, exportJobs: exportJobs}
 }
 
 // GetEvent returns a public event by slug (only if is_public=true).
@@ -47,6 +45,7 @@ func (h *PublicHandler) GetEvent(w http.ResponseWriter, r *http.Request) {
 		"start_time":       event.StartTime,
 		"end_time":         event.EndTime,
 		"time_granularity": event.TimeGranularity,
+		"timezone":         event.Timezone,
 		"is_locked":        event.IsLocked,
 		"is_public":        event.IsPublic,
 	}
@@ -88,7 +87,14 @@ func (h *PublicHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data, filename, err := h.exportService.ExportCSV(r.Context(), slug)
+	// Anonymous export: no viewer, so private columns are rejected.
+	opts, err := parseCSVOptions(r.URL.Query())
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+
+	data, filename, err := h.exportService.ExportCSV(r.Context(), slug, opts)
 	if err != nil {
 		model.ErrorResponse(w, err)
 		return
@@ -142,47 +148,20 @@ func (h *PublicHandler) ExportPDF(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	q := r.URL.Query()
-	opts := pdf.PDFOptions{
-		Layout:         q.Get("layout"),
-		PaperSize:      q.Get("paper"),
-		Landscape:      q.Get("landscape") != "false",
-		ShowCoverage:   q.Get("coverage") != "false",
-	}
-	if opts.Layout == "" {
-		opts.Layout = "grid"
-	}
-	if opts.PaperSize == "" {
-		opts.PaperSize = "A4"
-	}
-	if s := q.Get("start"); s != "" {
-		if t, err := time.Parse(time.RFC3339, s); err == nil {
-			opts.Start = &t
-		}
-	}
-	if s := q.Get("end"); s != "" {
-		if t, err := time.Parse(time.RFC3339, s); err == nil {
-			opts.End = &t
-		}
-	}
-	if u := q.Get("users"); u != "" {
-		opts.UserIDs = strings.Split(u, ",")
-	}
-	if t := q.Get("teams"); t != "" {
-		opts.TeamIDs = strings.Split(t, ",")
+	opts, err := parsePDFOptions(r.URL.Query())
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
 	}
-	if q.Get("onePerPage") == "true" {
-		opts.OnePerPage = true
+	if opts.ShowPhones {
+		model.ErrorResponse(w, model.NewFieldError(model.ErrForbidden, "phones", "phone numbers are not available publicly"))
+		return
 	}
 
-	data, filename, err := h.exportService.ExportPDF(r.Context(), slug, opts)
+	data, filename, err := h.exportJobs.RunPDF(r.Context(), slug, opts)
 	if err != nil {
 		model.ErrorResponse(w, err)
 		return
 	}
-
-	w.Header().Set("Content-Type", "application/pdf")
-	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
-	w.WriteHeader(http.StatusOK)
-	w.Write(data)
+	writeExportFile(w, data, filename)
 }
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/server/middleware"
+	"github.com/echtkpvl/rncasp/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type PushHandler struct {
+	pushService *service.PushService
+}
+
+func NewPushHandler(pushService *service.PushService) *PushHandler {
+	return &PushHandler{pushService: pushService}
+}
+
+// subscribePushRequest mirrors the JSON form of a browser PushSubscription.
+type subscribePushRequest struct {
+	Endpoint       string `json:"endpoint"`
+	ExpirationTime *int64 `json:"expirationTime"`
+	Keys           struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+func (h *PushHandler) GetPublicKey(w http.ResponseWriter, r *http.Request) {
+	key, err := h.pushService.PublicKey()
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, map[string]string{"public_key": key})
+}
+
+func (h *PushHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrUnauthorized, "not authenticated"))
+		return
+	}
+
+	subs, err := h.pushService.ListSubscriptions(r.Context(), *userID)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, subs)
+}
+
+func (h *PushHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrUnauthorized, "not authenticated"))
+		return
+	}
+
+	var req subscribePushRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid request body"))
+		return
+	}
+
+	sub, err := h.pushService.Subscribe(r.Context(), *userID, service.SubscribePushInput{
+		Endpoint:       req.Endpoint,
+		P256dh:         req.Keys.P256dh,
+		Auth:           req.Keys.Auth,
+		ExpirationTime: req.ExpirationTime,
+		UserAgent:      r.UserAgent(),
+	})
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusCreated, sub)
+}
+
+func (h *PushHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrUnauthorized, "not authenticated"))
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "subscriptionId"))
+	if err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid subscription ID"))
+		return
+	}
+
+	if err := h.pushService.Unsubscribe(r.Context(), *userID, id); err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, map[string]string{"message": "push subscription deleted"})
+}
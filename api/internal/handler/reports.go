@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/server/middleware"
+	"github.com/echtkpvl/rncasp/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type ReportHandler struct {
+	reportService *service.ReportService
+}
+
+func NewReportHandler(reportService *service.ReportService) *ReportHandler {
+	return &ReportHandler{reportService: reportService}
+}
+
+type createReportRequest struct {
+	Name             string   `json:"name"`
+	Format           string   `json:"format"`
+	PDFLayout        string   `json:"pdf_layout"`
+	TeamIDs          []string `json:"team_ids"`
+	TimeWindow       string   `json:"time_window"`
+	RecipientUserIDs []string `json:"recipient_user_ids"`
+	RecipientEmails  []string `json:"recipient_emails"`
+	Frequency        string   `json:"frequency"`
+	Weekday          *int16   `json:"weekday"`
+	SendTime         string   `json:"send_time"`
+}
+
+type updateReportRequest struct {
+	Name             *string   `json:"name"`
+	Format           *string   `json:"format"`
+	PDFLayout        *string   `json:"pdf_layout"`
+	TeamIDs          *[]string `json:"team_ids"`
+	TimeWindow       *string   `json:"time_window"`
+	RecipientUserIDs *[]string `json:"recipient_user_ids"`
+	RecipientEmails  *[]string `json:"recipient_emails"`
+	Frequency        *string   `json:"frequency"`
+	Weekday          *int16    `json:"weekday"`
+	SendTime         *string   `json:"send_time"`
+	IsEnabled        *bool     `json:"is_enabled"`
+}
+
+func (h *ReportHandler) List(w http.ResponseWriter, r *http.Request) {
+	reports, err := h.reportService.ListByEvent(r.Context(), chi.URLParam(r, "slug"))
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, reports)
+}
+
+func (h *ReportHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req createReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid request body"))
+		return
+	}
+
+	report, err := h.reportService.Create(r.Context(), chi.URLParam(r, "slug"), middleware.GetUserID(r.Context()), service.CreateReportSubscriptionInput{
+		Name:             req.Name,
+		Format:           req.Format,
+		PDFLayout:        req.PDFLayout,
+		TeamIDs:          req.TeamIDs,
+		TimeWindow:       req.TimeWindow,
+		RecipientUserIDs: req.RecipientUserIDs,
+		RecipientEmails:  req.RecipientEmails,
+		Frequency:        req.Frequency,
+		Weekday:          req.Weekday,
+		SendTime:         req.SendTime,
+	})
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusCreated, report)
+}
+
+func (h *ReportHandler) Update(w http.ResponseWriter, r *http.Request) {
+	reportID, err := uuid.Parse(chi.URLParam(r, "reportId"))
+	if err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid report ID"))
+		return
+	}
+
+	var req updateReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid request body"))
+		return
+	}
+
+	report, err := h.reportService.Update(r.Context(), chi.URLParam(r, "slug"), reportID, service.UpdateReportSubscriptionInput{
+		Name:             req.Name,
+		Format:           req.Format,
+		PDFLayout:        req.PDFLayout,
+		TeamIDs:          req.TeamIDs,
+		TimeWindow:       req.TimeWindow,
+		RecipientUserIDs: req.RecipientUserIDs,
+		RecipientEmails:  req.RecipientEmails,
+		Frequency:        req.Frequency,
+		Weekday:          req.Weekday,
+		SendTime:         req.SendTime,
+		IsEnabled:        req.IsEnabled,
+	})
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, report)
+}
+
+func (h *ReportHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	reportID, err := uuid.Parse(chi.URLParam(r, "reportId"))
+	if err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid report ID"))
+		return
+	}
+
+	if err := h.reportService.Delete(r.Context(), chi.URLParam(r, "slug"), reportID); err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, map[string]string{"message": "report deleted"})
+}
+
+// Send delivers a report right away. Delivery problems are reported in the
+// returned report's last_error rather than as an error response.
+func (h *ReportHandler) Send(w http.ResponseWriter, r *http.Request) {
+	reportID, err := uuid.Parse(chi.URLParam(r, "reportId"))
+	if err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid report ID"))
+		return
+	}
+
+	report, err := h.reportService.SendNow(r.Context(), chi.URLParam(r, "slug"), reportID)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, report)
+}
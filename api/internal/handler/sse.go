@@ -20,7 +20,8 @@ func NewSSEHandler(broker *sse.Broker) *SSEHandler {
 
 // Subscribe handles SSE connections. Clients can subscribe to a specific
 // event's updates via the slug URL parameter, or receive all updates
-// if no slug is provided.
+// if no slug is provided. Either way the connection also receives the
+// user's own notifications.
 func (h *SSEHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
 	if userID == nil {
@@ -28,15 +29,36 @@ func (h *SSEHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Optional: subscribe to a specific event's updates
+	eventSlug := chi.URLParam(r, "slug")
+	h.stream(w, r, func() (<-chan []byte, func()) {
+		return h.broker.Subscribe(eventSlug, userID.String())
+	})
+}
+
+// SubscribeNotifications streams only the authenticated user's notification
+// events: new notifications and unread count changes.
+func (h *SSEHandler) SubscribeNotifications(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrUnauthorized, "not authenticated"))
+		return
+	}
+
+	h.stream(w, r, func() (<-chan []byte, func()) {
+		return h.broker.SubscribeUser(userID.String())
+	})
+}
+
+// stream writes events from the subscription to w until the client
+// disconnects.
+func (h *SSEHandler) stream(w http.ResponseWriter, r *http.Request, subscribe func() (<-chan []byte, func())) {
 	// Use ResponseController to handle middleware-wrapped writers
 	rc := http.NewResponseController(w)
 
 	// Disable the server's WriteTimeout for this long-lived connection
 	rc.SetWriteDeadline(time.Time{})
 
-	// Optional: subscribe to a specific event's updates
-	eventSlug := chi.URLParam(r, "slug")
-
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
@@ -49,7 +71,7 @@ func (h *SSEHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ch, cleanup := h.broker.Subscribe(eventSlug)
+	ch, cleanup := subscribe()
 	defer cleanup()
 
 	for {
@@ -50,6 +50,7 @@ type updateUserRequest struct {
 	Email       *string `json:"email"`
 	Password    *string `json:"password"`
 	TimeFormat  *string `json:"time_format"`
+	Phone       *string `json:"phone"`
 	Username    *string `json:"username"`
 	AccountType *string `json:"account_type"`
 }
@@ -183,6 +184,7 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		Email:       req.Email,
 		Password:    req.Password,
 		TimeFormat:  req.TimeFormat,
+		Phone:       req.Phone,
 		Username:    req.Username,
 		AccountType: req.AccountType,
 	})
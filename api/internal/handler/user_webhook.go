@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/server/middleware"
+	"github.com/echtkpvl/rncasp/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// UserWebhookHandler manages the personal webhooks of the logged-in user,
+// which are sent for changes to the user's own shifts in any event.
+type UserWebhookHandler struct {
+	webhookService *service.WebhookService
+}
+
+func NewUserWebhookHandler(webhookService *service.WebhookService) *UserWebhookHandler {
+	return &UserWebhookHandler{webhookService: webhookService}
+}
+
+func (h *UserWebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrUnauthorized, "not authenticated"))
+		return
+	}
+
+	webhooks, err := h.webhookService.ListByUser(r.Context(), *userID)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, webhooks)
+}
+
+func (h *UserWebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrUnauthorized, "not authenticated"))
+		return
+	}
+
+	var req createWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid request body"))
+		return
+	}
+
+	webhook, err := h.webhookService.CreatePersonal(r.Context(), *userID, service.CreateWebhookInput{
+		Name:         req.Name,
+		URL:          req.URL,
+		Secret:       req.Secret,
+		Format:       req.Format,
+		TriggerTypes: req.TriggerTypes,
+		Template:     req.Template,
+		ContentType:  req.ContentType,
+		Headers:      req.Headers,
+		Filters:      req.Filters,
+	})
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusCreated, webhook)
+}
+
+// Triggers lists the trigger types personal webhooks can subscribe to.
+func (h *UserWebhookHandler) Triggers(w http.ResponseWriter, r *http.Request) {
+	model.JSON(w, http.StatusOK, h.webhookService.ListTriggers(service.WebhookScopePersonal))
+}
+
+// Preview renders a template webhook body against sample data.
+func (h *UserWebhookHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	previewWebhookTemplate(w, r, h.webhookService, nil)
+}
+
+func (h *UserWebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	webhookID, ok := h.personalWebhookID(w, r)
+	if !ok {
+		return
+	}
+
+	var req updateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid request body"))
+		return
+	}
+
+	webhook, err := h.webhookService.Update(r.Context(), webhookID, service.UpdateWebhookInput{
+		Name:         req.Name,
+		URL:          req.URL,
+		Secret:       req.Secret,
+		Format:       req.Format,
+		TriggerTypes: req.TriggerTypes,
+		IsEnabled:    req.IsEnabled,
+		Template:     req.Template,
+		ContentType:  req.ContentType,
+		Headers:      req.Headers,
+		Filters:      req.Filters,
+	})
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, webhook)
+}
+
+func (h *UserWebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	webhookID, ok := h.personalWebhookID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.Delete(r.Context(), webhookID); err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, map[string]string{"message": "webhook deleted"})
+}
+
+func (h *UserWebhookHandler) Test(w http.ResponseWriter, r *http.Request) {
+	webhookID, ok := h.personalWebhookID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.Test(r.Context(), webhookID); err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, map[string]string{"message": "test webhook queued"})
+}
+
+// RotateSecret replaces the webhook's secret and returns the new one. This is
+// the only time the secret is shown.
+func (h *UserWebhookHandler) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	webhookID, ok := h.personalWebhookID(w, r)
+	if !ok {
+		return
+	}
+	rotation, err := h.webhookService.RotateSecret(r.Context(), webhookID)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, rotation)
+}
+
+func (h *UserWebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhookID, ok := h.personalWebhookID(w, r)
+	if !ok {
+		return
+	}
+	listWebhookDeliveries(w, r, h.webhookService, webhookID)
+}
+
+func (h *UserWebhookHandler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	webhookID, ok := h.personalWebhookID(w, r)
+	if !ok {
+		return
+	}
+	getWebhookDelivery(w, r, h.webhookService, webhookID)
+}
+
+func (h *UserWebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	webhookID, ok := h.personalWebhookID(w, r)
+	if !ok {
+		return
+	}
+	redeliverWebhook(w, r, h.webhookService, webhookID)
+}
+
+// personalWebhookID returns the webhook ID from the URL after checking that
+// the webhook belongs to the logged-in user.
+func (h *UserWebhookHandler) personalWebhookID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrUnauthorized, "not authenticated"))
+		return uuid.Nil, false
+	}
+
+	webhookID, err := uuid.Parse(chi.URLParam(r, "webhookId"))
+	if err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid webhook ID"))
+		return uuid.Nil, false
+	}
+	if _, err := h.webhookService.GetPersonal(r.Context(), *userID, webhookID); err != nil {
+		model.ErrorResponse(w, err)
+		return uuid.Nil, false
+	}
+	return webhookID, true
+}
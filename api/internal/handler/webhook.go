@@ -3,6 +3,7 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/echtkpvl/rncasp/internal/model"
 	"github.com/echtkpvl/rncasp/internal/service"
@@ -20,20 +21,37 @@ func NewWebhookHandler(webhookService *service.WebhookService, eventService *ser
 }
 
 type createWebhookRequest struct {
-	Name         string   `json:"name"`
-	URL          string   `json:"url"`
-	Secret       string   `json:"secret"`
-	Format       string   `json:"format"`
-	TriggerTypes []string `json:"trigger_types"`
+	Name         string            `json:"name"`
+	URL          string            `json:"url"`
+	Secret       string            `json:"secret"`
+	Format       string            `json:"format"`
+	TriggerTypes []string          `json:"trigger_types"`
+	Template     *string           `json:"template"`
+	ContentType  *string           `json:"content_type"`
+	Headers      map[string]string `json:"headers"`
+
+	Filters *service.WebhookFilters `json:"filters"`
 }
 
 type updateWebhookRequest struct {
-	Name         *string   `json:"name"`
-	URL          *string   `json:"url"`
-	Secret       *string   `json:"secret"`
-	Format       *string   `json:"format"`
-	TriggerTypes *[]string `json:"trigger_types"`
-	IsEnabled    *bool     `json:"is_enabled"`
+	Name         *string           `json:"name"`
+	URL          *string           `json:"url"`
+	Secret       *string           `json:"secret"`
+	Format       *string           `json:"format"`
+	TriggerTypes *[]string         `json:"trigger_types"`
+	IsEnabled    *bool             `json:"is_enabled"`
+	Template     *string           `json:"template"`
+	ContentType  *string           `json:"content_type"`
+	Headers      map[string]string `json:"headers"`
+
+	Filters *service.WebhookFilters `json:"filters"`
+}
+
+type previewWebhookRequest struct {
+	TriggerType string            `json:"trigger_type"`
+	Template    string            `json:"template"`
+	ContentType string            `json:"content_type"`
+	Headers     map[string]string `json:"headers"`
 }
 
 func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
@@ -75,6 +93,10 @@ func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Secret:       req.Secret,
 		Format:       req.Format,
 		TriggerTypes: req.TriggerTypes,
+		Template:     req.Template,
+		ContentType:  req.ContentType,
+		Headers:      req.Headers,
+		Filters:      req.Filters,
 	})
 	if err != nil {
 		model.ErrorResponse(w, err)
@@ -84,9 +106,8 @@ func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
-	webhookID, err := uuid.Parse(chi.URLParam(r, "webhookId"))
-	if err != nil {
-		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid webhook ID"))
+	webhookID, ok := h.eventWebhookID(w, r)
+	if !ok {
 		return
 	}
 
@@ -103,6 +124,10 @@ func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
 		Format:       req.Format,
 		TriggerTypes: req.TriggerTypes,
 		IsEnabled:    req.IsEnabled,
+		Template:     req.Template,
+		ContentType:  req.ContentType,
+		Headers:      req.Headers,
+		Filters:      req.Filters,
 	})
 	if err != nil {
 		model.ErrorResponse(w, err)
@@ -112,9 +137,8 @@ func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *WebhookHandler) Test(w http.ResponseWriter, r *http.Request) {
-	webhookID, err := uuid.Parse(chi.URLParam(r, "webhookId"))
-	if err != nil {
-		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid webhook ID"))
+	webhookID, ok := h.eventWebhookID(w, r)
+	if !ok {
 		return
 	}
 
@@ -122,19 +146,176 @@ func (h *WebhookHandler) Test(w http.ResponseWriter, r *http.Request) {
 		model.ErrorResponse(w, err)
 		return
 	}
-	model.JSON(w, http.StatusOK, map[string]string{"message": "test webhook sent"})
+	model.JSON(w, http.StatusOK, map[string]string{"message": "test webhook queued"})
+}
+
+// RotateSecret replaces the webhook's secret and returns the new one. This is
+// the only time the secret is shown.
+func (h *WebhookHandler) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	webhookID, ok := h.eventWebhookID(w, r)
+	if !ok {
+		return
+	}
+	rotation, err := h.webhookService.RotateSecret(r.Context(), webhookID)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, rotation)
 }
 
 func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	webhookID, ok := h.eventWebhookID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.Delete(r.Context(), webhookID); err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, map[string]string{"message": "webhook deleted"})
+}
+
+// Triggers lists the trigger types event webhooks can subscribe to.
+func (h *WebhookHandler) Triggers(w http.ResponseWriter, r *http.Request) {
+	model.JSON(w, http.StatusOK, h.webhookService.ListTriggers(service.WebhookScopeEvent))
+}
+
+// Preview renders a template webhook body against sample data for the event.
+func (h *WebhookHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	event, err := h.eventService.GetBySlug(r.Context(), chi.URLParam(r, "slug"))
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	eventID, _ := uuid.Parse(event.ID)
+	previewWebhookTemplate(w, r, h.webhookService, &eventID)
+}
+
+// eventWebhookID returns the webhook ID from the URL after checking that the
+// webhook belongs to the event, so event admins can't see or change other
+// events' or users' webhooks.
+func (h *WebhookHandler) eventWebhookID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	event, err := h.eventService.GetBySlug(r.Context(), chi.URLParam(r, "slug"))
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return uuid.Nil, false
+	}
+
 	webhookID, err := uuid.Parse(chi.URLParam(r, "webhookId"))
 	if err != nil {
 		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid webhook ID"))
+		return uuid.Nil, false
+	}
+	webhook, err := h.webhookService.GetByID(r.Context(), webhookID)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return uuid.Nil, false
+	}
+	if webhook.EventID == nil || *webhook.EventID != event.ID {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrNotFound, "webhook not found"))
+		return uuid.Nil, false
+	}
+	return webhookID, true
+}
+
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhookID, ok := h.eventWebhookID(w, r)
+	if !ok {
 		return
 	}
+	listWebhookDeliveries(w, r, h.webhookService, webhookID)
+}
 
-	if err := h.webhookService.Delete(r.Context(), webhookID); err != nil {
+func (h *WebhookHandler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	webhookID, ok := h.eventWebhookID(w, r)
+	if !ok {
+		return
+	}
+	getWebhookDelivery(w, r, h.webhookService, webhookID)
+}
+
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	webhookID, ok := h.eventWebhookID(w, r)
+	if !ok {
+		return
+	}
+	redeliverWebhook(w, r, h.webhookService, webhookID)
+}
+
+// The preview and delivery handlers below are shared by event and global
+// webhooks.
+
+func previewWebhookTemplate(w http.ResponseWriter, r *http.Request, webhookService *service.WebhookService, eventID *uuid.UUID) {
+	var req previewWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid request body"))
+		return
+	}
+
+	preview, err := webhookService.PreviewTemplate(r.Context(), service.WebhookPreviewInput{
+		EventID:     eventID,
+		TriggerType: req.TriggerType,
+		Template:    req.Template,
+		ContentType: req.ContentType,
+		Headers:     req.Headers,
+	})
+	if err != nil {
 		model.ErrorResponse(w, err)
 		return
 	}
-	model.JSON(w, http.StatusOK, map[string]string{"message": "webhook deleted"})
+	model.JSON(w, http.StatusOK, preview)
+}
+
+func listWebhookDeliveries(w http.ResponseWriter, r *http.Request, webhookService *service.WebhookService, webhookID uuid.UUID) {
+	limit := int32(50)
+	offset := int32(0)
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			limit = int32(n)
+		}
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			offset = int32(n)
+		}
+	}
+
+	deliveries, err := webhookService.ListDeliveries(r.Context(), webhookID, limit, offset)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, deliveries)
+}
+
+func getWebhookDelivery(w http.ResponseWriter, r *http.Request, webhookService *service.WebhookService, webhookID uuid.UUID) {
+	deliveryID, err := uuid.Parse(chi.URLParam(r, "deliveryId"))
+	if err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid delivery ID"))
+		return
+	}
+
+	delivery, err := webhookService.GetDelivery(r.Context(), webhookID, deliveryID)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusOK, delivery)
+}
+
+func redeliverWebhook(w http.ResponseWriter, r *http.Request, webhookService *service.WebhookService, webhookID uuid.UUID) {
+	deliveryID, err := uuid.Parse(chi.URLParam(r, "deliveryId"))
+	if err != nil {
+		model.ErrorResponse(w, model.NewDomainError(model.ErrInvalidInput, "invalid delivery ID"))
+		return
+	}
+
+	delivery, err := webhookService.Redeliver(r.Context(), webhookID, deliveryID)
+	if err != nil {
+		model.ErrorResponse(w, err)
+		return
+	}
+	model.JSON(w, http.StatusAccepted, delivery)
 }
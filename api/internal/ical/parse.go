@@ -0,0 +1,399 @@
+// Package ical parses the subset of RFC 5545 needed to import busy times
+// from personal calendars: VEVENTs with their time zone, transparency and
+// status, plus the common recurrence rules.
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is a single (possibly expanded) VEVENT occurrence.
+type Event struct {
+	UID         string
+	Summary     string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Transparent bool // TRANSP:TRANSPARENT, i.e. does not block time
+	Cancelled   bool // STATUS:CANCELLED
+}
+
+// Busy reports whether the event blocks time in the owner's calendar.
+func (e Event) Busy() bool {
+	return !e.Transparent && !e.Cancelled
+}
+
+// maxLineLength bounds a single unfolded content line.
+const maxLineLength = 1 << 20
+
+// maxOccurrences bounds recurrence expansion per VEVENT.
+const maxOccurrences = 100000
+
+var ErrNotCalendar = errors.New("not an iCalendar file")
+
+// Parse reads a calendar and returns every event occurrence overlapping
+// [windowStart, windowEnd). Recurring events are expanded inside the window.
+// Floating times, all-day dates and unknown TZIDs are interpreted in loc.
+func Parse(r io.Reader, windowStart, windowEnd time.Time, loc *time.Location) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 || !strings.EqualFold(lines[0], "BEGIN:VCALENDAR") {
+		return nil, ErrNotCalendar
+	}
+
+	var vevents []*vevent
+	var cur *vevent
+	depth := 0 // nesting inside the current VEVENT (e.g. VALARM)
+	for _, line := range lines {
+		p := parseProperty(line)
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VEVENT") && cur == nil:
+			cur = &vevent{}
+		case p.name == "BEGIN" && cur != nil:
+			depth++
+		case p.name == "END" && cur != nil && depth > 0:
+			depth--
+		case p.name == "END" && strings.EqualFold(p.value, "VEVENT") && cur != nil:
+			vevents = append(vevents, cur)
+			cur = nil
+		case cur != nil && depth == 0:
+			cur.props = append(cur.props, p)
+		}
+	}
+
+	// Instances replaced by a RECURRENCE-ID override must not be expanded
+	// from the master event as well.
+	overridden := make(map[string]map[int64]bool)
+	for _, v := range vevents {
+		if rid, ok := v.get("RECURRENCE-ID"); ok {
+			if t, _, err := parseDateTime(rid, loc); err == nil {
+				uid, _ := v.get("UID")
+				if overridden[uid.value] == nil {
+					overridden[uid.value] = make(map[int64]bool)
+				}
+				overridden[uid.value][t.Unix()] = true
+			}
+		}
+	}
+
+	var events []Event
+	for _, v := range vevents {
+		occ, err := v.expand(windowStart, windowEnd, loc, overridden)
+		if err != nil {
+			// Skip malformed events rather than rejecting the whole calendar
+			continue
+		}
+		events = append(events, occ...)
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Start.Before(events[j].Start)
+	})
+	return events, nil
+}
+
+// unfold splits the input into logical content lines (RFC 5545 section 3.1).
+func unfold(r io.Reader) ([]string, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), maxLineLength)
+
+	var lines []string
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			last := lines[len(lines)-1] + line[1:]
+			if len(last) > maxLineLength {
+				return nil, fmt.Errorf("content line exceeds %d bytes", maxLineLength)
+			}
+			lines[len(lines)-1] = last
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading calendar: %w", err)
+	}
+	if len(lines) > 0 {
+		lines[0] = strings.TrimPrefix(lines[0], "\ufeff")
+	}
+	return lines, nil
+}
+
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+func parseProperty(line string) property {
+	// The value starts at the first colon outside a quoted parameter value
+	inQuotes := false
+	sep := -1
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			inQuotes = !inQuotes
+		case ':':
+			if !inQuotes {
+				sep = i
+			}
+		}
+		if sep >= 0 {
+			break
+		}
+	}
+	if sep < 0 {
+		return property{name: strings.ToUpper(line)}
+	}
+
+	p := property{value: line[sep+1:], params: make(map[string]string)}
+	parts := strings.Split(line[:sep], ";")
+	p.name = strings.ToUpper(parts[0])
+	for _, part := range parts[1:] {
+		if k, v, ok := strings.Cut(part, "="); ok {
+			p.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return p
+}
+
+type vevent struct {
+	props []property
+}
+
+func (v *vevent) get(name string) (property, bool) {
+	for _, p := range v.props {
+		if p.name == name {
+			return p, true
+		}
+	}
+	return property{}, false
+}
+
+func (v *vevent) all(name string) []property {
+	var out []property
+	for _, p := range v.props {
+		if p.name == name {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func (v *vevent) expand(windowStart, windowEnd time.Time, loc *time.Location, overridden map[string]map[int64]bool) ([]Event, error) {
+	dtstart, ok := v.get("DTSTART")
+	if !ok {
+		return nil, errors.New("missing DTSTART")
+	}
+	start, allDay, err := parseDateTime(dtstart, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	var duration time.Duration
+	if dtend, ok := v.get("DTEND"); ok {
+		end, _, err := parseDateTime(dtend, loc)
+		if err != nil {
+			return nil, err
+		}
+		duration = end.Sub(start)
+	} else if dur, ok := v.get("DURATION"); ok {
+		duration, err = parseDuration(dur.value)
+		if err != nil {
+			return nil, err
+		}
+	} else if allDay {
+		duration = 24 * time.Hour
+	}
+	if duration <= 0 {
+		return nil, errors.New("event has no duration")
+	}
+
+	base := Event{AllDay: allDay}
+	if p, ok := v.get("UID"); ok {
+		base.UID = p.value
+	}
+	if p, ok := v.get("SUMMARY"); ok {
+		base.Summary = unescapeText(p.value)
+	}
+	if p, ok := v.get("TRANSP"); ok {
+		base.Transparent = strings.EqualFold(p.value, "TRANSPARENT")
+	}
+	if p, ok := v.get("STATUS"); ok {
+		base.Cancelled = strings.EqualFold(p.value, "CANCELLED")
+	}
+
+	emit := func(s time.Time) (Event, bool) {
+		e := base
+		e.Start = s
+		e.End = s.Add(duration)
+		return e, e.Start.Before(windowEnd) && e.End.After(windowStart)
+	}
+
+	rrule, hasRule := v.get("RRULE")
+	_, isOverride := v.get("RECURRENCE-ID")
+	if !hasRule || isOverride {
+		if e, ok := emit(start); ok {
+			return []Event{e}, nil
+		}
+		return nil, nil
+	}
+
+	rule, err := parseRRule(rrule.value, loc)
+	if err != nil {
+		// Unsupported rule: fall back to the first occurrence only
+		if e, ok := emit(start); ok {
+			return []Event{e}, nil
+		}
+		return nil, nil
+	}
+
+	excluded := make(map[int64]bool)
+	for k := range overridden[base.UID] {
+		excluded[k] = true
+	}
+	for _, ex := range v.all("EXDATE") {
+		for _, val := range strings.Split(ex.value, ",") {
+			t, _, err := parseDateTime(property{params: ex.params, value: val}, loc)
+			if err == nil {
+				excluded[t.Unix()] = true
+			}
+		}
+	}
+
+	var events []Event
+	rule.each(start, func(s time.Time) bool {
+		if !s.Before(windowEnd) {
+			return false
+		}
+		if excluded[s.Unix()] {
+			return true
+		}
+		if e, ok := emit(s); ok {
+			events = append(events, e)
+		}
+		return true
+	})
+	return events, nil
+}
+
+// parseDateTime parses DATE and DATE-TIME values. The bool reports whether
+// the value is a DATE (all-day).
+func parseDateTime(p property, loc *time.Location) (time.Time, bool, error) {
+	val := strings.TrimSpace(p.value)
+	if strings.EqualFold(p.params["VALUE"], "DATE") || len(val) == 8 {
+		t, err := time.ParseInLocation("20060102", val, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(val, "Z") {
+		t, err := time.Parse("20060102T150405Z", val)
+		return t, false, err
+	}
+	zone := loc
+	if tzid := p.params["TZID"]; tzid != "" {
+		zone = lookupZone(tzid, loc)
+	}
+	t, err := time.ParseInLocation("20060102T150405", val, zone)
+	return t, false, err
+}
+
+// windowsZones maps the Windows zone names used by Outlook/Exchange exports
+// to IANA locations.
+var windowsZones = map[string]string{
+	"W. Europe Standard Time":        "Europe/Berlin",
+	"Central Europe Standard Time":   "Europe/Budapest",
+	"Romance Standard Time":          "Europe/Paris",
+	"Central European Standard Time": "Europe/Warsaw",
+	"GMT Standard Time":              "Europe/London",
+	"Greenwich Standard Time":        "Atlantic/Reykjavik",
+	"E. Europe Standard Time":        "Europe/Chisinau",
+	"FLE Standard Time":              "Europe/Kiev",
+	"Eastern Standard Time":          "America/New_York",
+	"Central Standard Time":          "America/Chicago",
+	"Mountain Standard Time":         "America/Denver",
+	"Pacific Standard Time":          "America/Los_Angeles",
+	"UTC":                            "UTC",
+}
+
+func lookupZone(tzid string, fallback *time.Location) *time.Location {
+	tzid = strings.TrimPrefix(tzid, "/")
+	if name, ok := windowsZones[tzid]; ok {
+		tzid = name
+	}
+	if l, err := time.LoadLocation(tzid); err == nil && tzid != "Local" {
+		return l
+	}
+	return fallback
+}
+
+// parseDuration parses an RFC 5545 DURATION such as PT1H30M or P1DT2H.
+func parseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	neg := false
+	if strings.HasPrefix(s, "-") {
+		neg = true
+		s = s[1:]
+	}
+	s = strings.TrimPrefix(s, "+")
+	if !strings.HasPrefix(s, "P") {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	s = s[1:]
+
+	var d time.Duration
+	inTime := false
+	num := ""
+	for _, c := range s {
+		switch {
+		case c == 'T':
+			inTime = true
+		case c >= '0' && c <= '9':
+			num += string(c)
+		default:
+			n, err := strconv.Atoi(num)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			num = ""
+			switch {
+			case c == 'W':
+				d += time.Duration(n) * 7 * 24 * time.Hour
+			case c == 'D':
+				d += time.Duration(n) * 24 * time.Hour
+			case c == 'H' && inTime:
+				d += time.Duration(n) * time.Hour
+			case c == 'M' && inTime:
+				d += time.Duration(n) * time.Minute
+			case c == 'S' && inTime:
+				d += time.Duration(n) * time.Second
+			default:
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+		}
+	}
+	if num != "" {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
+
+var textUnescaper = strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+
+func unescapeText(s string) string {
+	return textUnescaper.Replace(s)
+}
@@ -0,0 +1,123 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup\r\n" +
+	"SUMMARY:Stand-up\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20250630T090000\r\n" +
+	"DTEND;TZID=Europe/Berlin:20250630T093000\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR\r\n" +
+	"EXDATE;TZID=Europe/Berlin:20250702T090000\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"TRIGGER:-PT5M\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup\r\n" +
+	"RECURRENCE-ID;TZID=Europe/Berlin:20250704T090000\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20250704T110000\r\n" +
+	"DTEND;TZID=Europe/Berlin:20250704T113000\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:holiday\r\n" +
+	"SUMMARY:Day off\\, finally\r\n" +
+	"DTSTART;VALUE=DATE:20250703\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:reminder\r\n" +
+	"DTSTART:20250701T120000Z\r\n" +
+	"DURATION:PT1H\r\n" +
+	"TRANSP:TRANSPARENT\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:outside\r\n" +
+	"DTSTART:20250801T120000Z\r\n" +
+	"DTEND:20250801T130000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParse(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("tzdata not available: %v", err)
+	}
+	windowStart := time.Date(2025, 6, 30, 0, 0, 0, 0, berlin)
+	windowEnd := time.Date(2025, 7, 5, 0, 0, 0, 0, berlin)
+
+	events, err := Parse(strings.NewReader(testCalendar), windowStart, windowEnd, berlin)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	type occurrence struct {
+		uid   string
+		start string
+		busy  bool
+	}
+	want := []occurrence{
+		{uid: "standup", start: "2025-06-30T09:00:00+02:00", busy: true},
+		{uid: "reminder", start: "2025-07-01T14:00:00+02:00", busy: false},
+		{uid: "holiday", start: "2025-07-03T00:00:00+02:00", busy: true},
+		{uid: "standup", start: "2025-07-04T11:00:00+02:00", busy: true},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		e := events[i]
+		if got := e.Start.In(berlin).Format(time.RFC3339); e.UID != w.uid || got != w.start || e.Busy() != w.busy {
+			t.Errorf("event %d = %s %s busy=%v, want %s %s busy=%v", i, e.UID, got, e.Busy(), w.uid, w.start, w.busy)
+		}
+	}
+
+	holiday := events[2]
+	if !holiday.AllDay || holiday.End.Sub(holiday.Start) != 24*time.Hour || holiday.Summary != "Day off, finally" {
+		t.Errorf("all-day event parsed as %+v", holiday)
+	}
+}
+
+func TestParseRejectsNonCalendar(t *testing.T) {
+	_, err := Parse(strings.NewReader("BEGIN:VCARD\r\nEND:VCARD\r\n"), time.Time{}, time.Now(), time.UTC)
+	if err != ErrNotCalendar {
+		t.Errorf("Parse() error = %v, want ErrNotCalendar", err)
+	}
+}
+
+func TestRRuleCountAndUntil(t *testing.T) {
+	start := time.Date(2025, 1, 31, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		rule string
+		want int
+	}{
+		{rule: "FREQ=DAILY;COUNT=3", want: 3},
+		{rule: "FREQ=DAILY;INTERVAL=2;UNTIL=20250206T100000Z", want: 4},
+		{rule: "FREQ=MONTHLY;COUNT=3", want: 3}, // Jan 31, Mar 31, May 31
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			r, err := parseRRule(tt.rule, time.UTC)
+			if err != nil {
+				t.Fatalf("parseRRule() error: %v", err)
+			}
+			var got []time.Time
+			r.each(start, func(s time.Time) bool {
+				got = append(got, s)
+				return len(got) < 100
+			})
+			if len(got) != tt.want {
+				t.Errorf("got %d occurrences %v, want %d", len(got), got, tt.want)
+			}
+		})
+	}
+
+	if _, err := parseRRule("FREQ=MONTHLY;BYSETPOS=-1;BYDAY=FR", time.UTC); err == nil {
+		t.Error("expected unsupported rule to be rejected")
+	}
+}
@@ -0,0 +1,153 @@
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rrule is the supported subset of RFC 5545 recurrence rules: FREQ with
+// INTERVAL, COUNT and UNTIL, plus BYDAY for weekly rules. Anything else
+// (BYMONTHDAY, BYSETPOS, ...) is rejected so callers can fall back.
+type rrule struct {
+	freq     string
+	interval int
+	count    int       // 0 = unbounded
+	until    time.Time // zero = unbounded
+	byDay    []time.Weekday
+}
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+var errUnsupportedRule = errors.New("unsupported recurrence rule")
+
+func parseRRule(s string, loc *time.Location) (rrule, error) {
+	r := rrule{interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		k, v, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		switch strings.ToUpper(k) {
+		case "FREQ":
+			r.freq = strings.ToUpper(v)
+		case "INTERVAL":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return rrule{}, fmt.Errorf("invalid INTERVAL %q", v)
+			}
+			r.interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return rrule{}, fmt.Errorf("invalid COUNT %q", v)
+			}
+			r.count = n
+		case "UNTIL":
+			t, _, err := parseDateTime(property{value: v}, loc)
+			if err != nil {
+				return rrule{}, fmt.Errorf("invalid UNTIL %q", v)
+			}
+			if len(v) == 8 {
+				// A DATE bound includes the whole day
+				t = t.AddDate(0, 0, 1).Add(-time.Second)
+			}
+			r.until = t
+		case "BYDAY":
+			for _, d := range strings.Split(v, ",") {
+				wd, ok := weekdays[strings.ToUpper(d)]
+				if !ok {
+					// Ordinal forms like 1MO or -1FR are not supported
+					return rrule{}, errUnsupportedRule
+				}
+				r.byDay = append(r.byDay, wd)
+			}
+		case "WKST":
+			// Only affects BYDAY expansion with INTERVAL > 1; Monday assumed
+		default:
+			return rrule{}, errUnsupportedRule
+		}
+	}
+
+	switch r.freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	default:
+		return rrule{}, errUnsupportedRule
+	}
+	if len(r.byDay) > 0 && r.freq != "WEEKLY" {
+		return rrule{}, errUnsupportedRule
+	}
+	return r, nil
+}
+
+// each calls fn with every occurrence start in chronological order, starting
+// with dtstart itself, until fn returns false or the rule is exhausted.
+func (r rrule) each(dtstart time.Time, fn func(time.Time) bool) {
+	emitted := 0
+	yield := func(t time.Time) bool {
+		if !r.until.IsZero() && t.After(r.until) {
+			return false
+		}
+		if r.count > 0 && emitted >= r.count {
+			return false
+		}
+		emitted++
+		return fn(t)
+	}
+
+	if r.freq == "WEEKLY" && len(r.byDay) > 0 {
+		// Walk week by week from the Monday of DTSTART's week
+		offset := (int(dtstart.Weekday()) + 6) % 7
+		weekStart := dtstart.AddDate(0, 0, -offset)
+		for i := 0; i < maxOccurrences; i++ {
+			for d := 0; d < 7; d++ {
+				day := weekStart.AddDate(0, 0, d)
+				if !r.hasDay(day.Weekday()) || day.Before(dtstart) {
+					continue
+				}
+				if !yield(day) {
+					return
+				}
+			}
+			weekStart = weekStart.AddDate(0, 0, 7*r.interval)
+		}
+		return
+	}
+
+	for i := 0; i < maxOccurrences; i++ {
+		var t time.Time
+		switch r.freq {
+		case "DAILY":
+			t = dtstart.AddDate(0, 0, i*r.interval)
+		case "WEEKLY":
+			t = dtstart.AddDate(0, 0, 7*i*r.interval)
+		case "MONTHLY":
+			t = dtstart.AddDate(0, i*r.interval, 0)
+			if t.Day() != dtstart.Day() {
+				continue // e.g. the 31st in a 30-day month does not occur
+			}
+		case "YEARLY":
+			t = dtstart.AddDate(i*r.interval, 0, 0)
+			if t.Day() != dtstart.Day() {
+				continue // Feb 29 in non-leap years
+			}
+		}
+		if !yield(t) {
+			return
+		}
+	}
+}
+
+func (r rrule) hasDay(wd time.Weekday) bool {
+	for _, d := range r.byDay {
+		if d == wd {
+			return true
+		}
+	}
+	return false
+}
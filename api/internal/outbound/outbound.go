@@ -0,0 +1,231 @@
+// Package outbound builds the HTTP clients used for requests to URLs that
+// users and admins configure: webhooks, calendar feeds, OAuth providers and
+// push services. Requests to loopback, private, link-local and other internal
+// addresses are refused unless allowed explicitly, so these URLs can't be
+// used to reach the database, Redis or cloud metadata endpoints.
+package outbound
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrBlocked is returned for destinations the policy doesn't allow.
+	ErrBlocked = errors.New("destination not allowed")
+	// ErrResponseTooLarge is returned when reading past a client's response limit.
+	ErrResponseTooLarge = errors.New("response too large")
+)
+
+// Ranges denied by default, in addition to what netip.Addr reports as
+// loopback, private, link-local, multicast or unspecified.
+var defaultDeny = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved, including broadcast
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+	netip.MustParsePrefix("100::/64"),       // discard-only
+	netip.MustParsePrefix("2001::/32"),      // Teredo, can embed any IPv4 address
+	netip.MustParsePrefix("2002::/16"),      // 6to4, same
+	netip.MustParsePrefix("2001:db8::/32"),  // documentation
+	netip.MustParsePrefix("fec0::/10"),      // deprecated site-local
+}
+
+// nat64WellKnown is the well-known NAT64 prefix. Its addresses reach the
+// IPv4 address in their last 32 bits, which is checked instead.
+var nat64WellKnown = netip.MustParsePrefix("64:ff9b::/96")
+
+// Config is the policy for outbound requests.
+type Config struct {
+	// Allow lists ranges that may be reached even though they are denied,
+	// e.g. an internal ntfy server or identity provider.
+	Allow []netip.Prefix
+	// Deny lists ranges refused in addition to the internal ones.
+	Deny []netip.Prefix
+	// HTTPSOnly refuses plain http:// URLs.
+	HTTPSOnly bool
+	// MaxRedirects is how many redirects a request follows; 0 follows none.
+	MaxRedirects int
+}
+
+// Policy decides which destinations outbound requests may reach. Clients
+// created from it share one transport.
+type Policy struct {
+	cfg       Config
+	transport *http.Transport
+	resolver  *net.Resolver
+}
+
+// New creates a policy.
+func New(cfg Config) *Policy {
+	p := &Policy{cfg: cfg, resolver: net.DefaultResolver}
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		// Control runs for the address actually dialed, after DNS
+		// resolution, so a host can't resolve to a public address when
+		// checked and to an internal one when connected.
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil {
+				return err
+			}
+			return p.checkAddr(addr)
+		},
+	}
+	p.transport = &http.Transport{
+		// Environment proxies are ignored: the proxy would be dialed
+		// instead of the destination, bypassing the checks.
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+	}
+	return p
+}
+
+// Client returns a client that enforces the policy, gives up on requests
+// after timeout and fails reads past maxResponseBytes (0 = no limit).
+func (p *Policy) Client(timeout time.Duration, maxResponseBytes int64) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &guardedTransport{policy: p, maxResponseBytes: maxResponseBytes},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > p.cfg.MaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", p.cfg.MaxRedirects)
+			}
+			return nil
+		},
+	}
+}
+
+// CheckURL checks a URL before it is saved, so users get an error right
+// away rather than failed deliveries. Host names are resolved; ones that
+// don't resolve pass, as the dial-time check still applies.
+func (p *Policy) CheckURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return errors.New("invalid URL")
+	}
+	if err := p.checkScheme(u.Scheme); err != nil {
+		return err
+	}
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil {
+		return p.checkAddr(addr)
+	}
+	if strings.EqualFold(u.Hostname(), "localhost") || strings.HasSuffix(strings.ToLower(u.Hostname()), ".localhost") {
+		return p.checkAddr(netip.IPv6Loopback())
+	}
+
+	addrs, err := p.resolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if err := p.checkAddr(addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Policy) checkScheme(scheme string) error {
+	switch strings.ToLower(scheme) {
+	case "https":
+		return nil
+	case "http":
+		if !p.cfg.HTTPSOnly {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: scheme %q", ErrBlocked, scheme)
+}
+
+func (p *Policy) checkAddr(addr netip.Addr) error {
+	addr = addr.Unmap().WithZone("")
+	if contains(p.cfg.Allow, addr) {
+		return nil
+	}
+	if nat64WellKnown.Contains(addr) {
+		b := addr.As16()
+		return p.checkAddr(netip.AddrFrom4([4]byte(b[12:])))
+	}
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() ||
+		contains(defaultDeny, addr) || contains(p.cfg.Deny, addr) {
+		return fmt.Errorf("%w: %s", ErrBlocked, addr)
+	}
+	return nil
+}
+
+func contains(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// guardedTransport checks the scheme of every request, including redirects,
+// and limits response bodies. Callers that only read the start of a large
+// response aren't affected.
+type guardedTransport struct {
+	policy           *Policy
+	maxResponseBytes int64
+}
+
+func (t *guardedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.policy.checkScheme(req.URL.Scheme); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	resp, err := t.policy.transport.RoundTrip(req)
+	if err != nil || t.maxResponseBytes <= 0 {
+		return resp, err
+	}
+	resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: t.maxResponseBytes}
+	return resp, nil
+}
+
+// limitedBody fails reads once more than the limit has been read.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, ErrResponseTooLarge
+	}
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n + int(b.remaining), ErrResponseTooLarge
+	}
+	return n, err
+}
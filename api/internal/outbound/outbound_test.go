@@ -0,0 +1,127 @@
+package outbound
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckAddr(t *testing.T) {
+	p := New(Config{
+		Allow: []netip.Prefix{netip.MustParsePrefix("10.1.2.0/24")},
+		Deny:  []netip.Prefix{netip.MustParsePrefix("203.0.113.0/24")},
+	})
+	tests := []struct {
+		addr    string
+		allowed bool
+	}{
+		{"93.184.215.14", true},
+		{"2606:4700::1111", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.0.0.5", false},
+		{"172.17.0.2", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.100.100.200", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"2002:7f00:1::", false},
+		{"64:ff9b::a9fe:a9fe", false}, // NAT64 to 169.254.169.254
+		{"64:ff9b::a00:5", false},     // NAT64 to 10.0.0.5
+		{"64:ff9b::5db8:d70e", true},  // NAT64 to 93.184.215.14
+		{"203.0.113.7", false},
+		{"10.1.2.3", true},
+	}
+	for _, tt := range tests {
+		err := p.checkAddr(netip.MustParseAddr(tt.addr))
+		if (err == nil) != tt.allowed {
+			t.Errorf("%s: err = %v, want allowed %v", tt.addr, err, tt.allowed)
+		}
+		if err != nil && !errors.Is(err, ErrBlocked) {
+			t.Errorf("%s: err = %v, want ErrBlocked", tt.addr, err)
+		}
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	p := New(Config{HTTPSOnly: true})
+	tests := []struct {
+		url     string
+		allowed bool
+	}{
+		{"https://93.184.215.14/hook", true},
+		{"http://93.184.215.14/hook", false},
+		{"ftp://93.184.215.14/hook", false},
+		{"https://127.0.0.1:6379/", false},
+		{"https://[::1]/", false},
+		{"https://localhost/hook", false},
+		{"https://redis.localhost/hook", false},
+		{"not a url", false},
+	}
+	for _, tt := range tests {
+		err := p.CheckURL(context.Background(), tt.url)
+		if (err == nil) != tt.allowed {
+			t.Errorf("%s: err = %v, want allowed %v", tt.url, err, tt.allowed)
+		}
+	}
+}
+
+func TestClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "/redirect", http.StatusFound)
+		case "/big":
+			w.(http.Flusher).Flush() // no Content-Length
+			io.WriteString(w, strings.Repeat("x", 2000))
+		default:
+			io.WriteString(w, "ok")
+		}
+	}))
+	defer srv.Close()
+
+	// The test server listens on loopback, which is denied by default.
+	_, err := New(Config{}).Client(time.Second, 0).Get(srv.URL)
+	if !errors.Is(err, ErrBlocked) {
+		t.Fatalf("loopback request: err = %v, want ErrBlocked", err)
+	}
+
+	allowed := New(Config{Allow: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}, MaxRedirects: 2})
+	client := allowed.Client(time.Second, 1000)
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "ok" {
+		t.Errorf("body = %q", body)
+	}
+
+	if _, err := client.Get(srv.URL + "/redirect"); err == nil || !strings.Contains(err.Error(), "redirects") {
+		t.Errorf("redirect loop: err = %v", err)
+	}
+
+	resp, err = client.Get(srv.URL + "/big")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !errors.Is(err, ErrResponseTooLarge) || len(body) != 1000 {
+		t.Errorf("big response: %d bytes, err = %v", len(body), err)
+	}
+
+	if _, err := New(Config{HTTPSOnly: true}).Client(time.Second, 0).Get(srv.URL); !errors.Is(err, ErrBlocked) {
+		t.Errorf("http with HTTPSOnly: err = %v", err)
+	}
+}
@@ -0,0 +1,286 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// document is a minimal PDF 1.4 writer: pages of vector graphics and text in
+// the standard Type 1 fonts, which every viewer provides, so no font files
+// need to be embedded. Coordinates are in points with the origin at the top
+// left of the page.
+type document struct {
+	width  float64
+	height float64
+	pages  []*bytes.Buffer
+	cur    *bytes.Buffer
+}
+
+type font int
+
+const (
+	fontRegular font = iota
+	fontBold
+	fontItalic
+)
+
+var fontNames = [...]string{"Helvetica", "Helvetica-Bold", "Helvetica-Oblique"}
+
+type rgb struct{ r, g, b float64 }
+
+var (
+	black     = rgb{0, 0, 0}
+	gray333   = hexColor("#333333")
+	gray666   = hexColor("#666666")
+	gray999   = hexColor("#999999")
+	grayCCC   = hexColor("#cccccc")
+	coverOK   = hexColor("#dcfce7")
+	coverLow  = hexColor("#fef2f2")
+	noColor   = rgb{-1, -1, -1}
+	mmToPoint = 72 / 25.4
+)
+
+func newDocument(width, height float64) *document {
+	return &document{width: width, height: height}
+}
+
+func (d *document) addPage() {
+	d.cur = &bytes.Buffer{}
+	d.pages = append(d.pages, d.cur)
+}
+
+func (d *document) y(top float64) float64 {
+	return d.height - top
+}
+
+// rect fills and/or strokes a rectangle; pass noColor to skip either.
+func (d *document) rect(x, y, w, h float64, fill, stroke rgb, lineWidth float64) {
+	op := ""
+	if fill != noColor {
+		fmt.Fprintf(d.cur, "%s %s %s rg\n", num(fill.r), num(fill.g), num(fill.b))
+		op = "f"
+	}
+	if stroke != noColor {
+		fmt.Fprintf(d.cur, "%s %s %s RG %s w\n", num(stroke.r), num(stroke.g), num(stroke.b), num(lineWidth))
+		op = "S"
+		if fill != noColor {
+			op = "B"
+		}
+	}
+	if op == "" {
+		return
+	}
+	fmt.Fprintf(d.cur, "%s %s %s %s re %s\n", num(x), num(d.y(y+h)), num(w), num(h), op)
+}
+
+func (d *document) line(x1, y1, x2, y2 float64, color rgb, lineWidth float64) {
+	fmt.Fprintf(d.cur, "%s %s %s RG %s w %s %s m %s %s l S\n",
+		num(color.r), num(color.g), num(color.b), num(lineWidth),
+		num(x1), num(d.y(y1)), num(x2), num(d.y(y2)))
+}
+
+// text draws s with its baseline at y.
+func (d *document) text(x, y float64, f font, size float64, color rgb, s string) {
+	fmt.Fprintf(d.cur, "BT %s %s %s rg /F%d %s Tf %s %s Td (%s) Tj ET\n",
+		num(color.r), num(color.g), num(color.b), int(f)+1, num(size),
+		num(x), num(d.y(y)), escapePDFString(encodeWinAnsi(s)))
+}
+
+// bytes serializes the document.
+func (d *document) bytes() ([]byte, error) {
+	var out bytes.Buffer
+	var offsets []int
+	obj := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1: catalog, 2: page tree, 3..5: fonts, then page + content pairs
+	const firstPageObj = 3 + len(fontNames)
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageObj+2*i)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+
+	var fonts []string
+	for i, name := range fontNames {
+		obj(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+		fonts = append(fonts, fmt.Sprintf("/F%d %d 0 R", i+1, 3+i))
+	}
+	resources := fmt.Sprintf("<< /Font << %s >> >>", strings.Join(fonts, " "))
+
+	for i, content := range d.pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources %s /Contents %d 0 R >>",
+			num(d.width), num(d.height), resources, firstPageObj+2*i+1))
+
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		if _, err := zw.Write(content.Bytes()); err != nil {
+			return nil, fmt.Errorf("compressing page %d: %w", i+1, err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("compressing page %d: %w", i+1, err)
+		}
+		obj(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", z.Len(), z.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes(), nil
+}
+
+// num formats a coordinate compactly with two decimals.
+func num(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "" || s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// hexColor parses #rgb, #rrggbb and #rrggbbaa. The alpha channel is blended
+// against white since the pages have a white background.
+func hexColor(s string) rgb {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	fallback := rgb{0.6, 0.6, 0.6}
+	if len(s) != 6 && len(s) != 8 {
+		return fallback
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return fallback
+	}
+	alpha := 1.0
+	if len(s) == 8 {
+		alpha = float64(v&0xff) / 255
+		v >>= 8
+	}
+	blend := func(c uint64) float64 {
+		return 1 - alpha*(1-float64(c)/255)
+	}
+	return rgb{blend(v >> 16 & 0xff), blend(v >> 8 & 0xff), blend(v & 0xff)}
+}
+
+// winAnsiSpecials maps the characters outside Latin-1 that WinAnsiEncoding
+// places in 0x80-0x9F.
+var winAnsiSpecials = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// encodeWinAnsi converts UTF-8 to WinAnsiEncoding, replacing characters the
+// standard fonts cannot show with '?'.
+func encodeWinAnsi(s string) string {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r < 0x80 || (r >= 0xa0 && r <= 0xff):
+			b = append(b, byte(r))
+		case winAnsiSpecials[r] != 0:
+			b = append(b, winAnsiSpecials[r])
+		default:
+			b = append(b, '?')
+		}
+	}
+	return string(b)
+}
+
+var pdfStringEscaper = strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`, "\r", `\r`, "\n", `\n`)
+
+func escapePDFString(s string) string {
+	return pdfStringEscaper.Replace(s)
+}
+
+// textWidth returns the width of s in points.
+func textWidth(s string, f font, size float64) float64 {
+	widths := &helveticaWidths
+	if f == fontBold {
+		widths = &helveticaBoldWidths
+	}
+	units := 0
+	for _, r := range s {
+		if r >= 32 && r <= 126 {
+			units += widths[r-32]
+		} else if r == '…' || r == '—' {
+			units += 1000
+		} else {
+			units += 556
+		}
+	}
+	return float64(units) * size / 1000
+}
+
+// fitText truncates s with an ellipsis so that it fits into width.
+func fitText(s string, f font, size, width float64) string {
+	if textWidth(s, f, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		t := string(runes) + "…"
+		if textWidth(t, f, size) <= width {
+			return t
+		}
+	}
+	return ""
+}
+
+// wrapText breaks s at spaces into lines no wider than width. Words longer
+// than a line are truncated.
+func wrapText(s string, f font, size, width float64) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if textWidth(candidate, f, size) <= width || line == "" {
+			line = candidate
+			continue
+		}
+		lines = append(lines, fitText(line, f, size, width))
+		line = word
+	}
+	if line != "" {
+		lines = append(lines, fitText(line, f, size, width))
+	}
+	return lines
+}
+
+// Glyph widths of the printable ASCII range (32-126) in 1/1000 em, from the
+// Adobe font metrics of the standard fonts. The oblique face shares the
+// regular widths.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
@@ -18,16 +18,26 @@ import (
 	"github.com/google/uuid"
 )
 
+// Renderers selectable through PDF_RENDERER.
+const (
+	RendererChrome = "chrome" // HTML printed by headless Chrome via chromedp
+	RendererNative = "native" // pure Go, no browser required
+)
+
 type PDFGenerator struct {
-	logger *slog.Logger
+	logger   *slog.Logger
+	renderer string
 }
 
-func NewPDFGenerator(logger *slog.Logger) *PDFGenerator {
-	return &PDFGenerator{logger: logger}
+func NewPDFGenerator(logger *slog.Logger, renderer string) *PDFGenerator {
+	if renderer != RendererNative {
+		renderer = RendererChrome
+	}
+	return &PDFGenerator{logger: logger, renderer: renderer}
 }
 
 type PDFOptions struct {
-	Layout       string     // "grid" or "list"
+	Layout       string     // "grid", "list", "timesheet", "certificate" or "roster"
 	PaperSize    string     // "A4" or "A3"
 	Landscape    bool
 	ShowCoverage bool
@@ -36,6 +46,32 @@ type PDFOptions struct {
 	UserIDs      []string   // UUIDs; empty = all
 	TeamIDs      []string   // UUIDs; empty = all
 	OnePerPage   bool       // list mode: one user per page
+	Bundle       string     // "" = one PDF, "zip" = one PDF per user in a ZIP
+	ShowPhones   bool       // roster: phone column (event admins only)
+}
+
+// layouts lists every layout the generator can render.
+var layouts = map[string]bool{"grid": true, "list": true, "timesheet": true, "certificate": true, "roster": true}
+
+// IsLayout reports whether layout names a known PDF layout.
+func IsLayout(layout string) bool {
+	return layouts[layout]
+}
+
+// Layouts that render one section per user and can therefore be bundled as
+// individual files.
+var perUserLayouts = map[string]bool{"list": true, "timesheet": true, "certificate": true}
+
+// IsPerUserLayout reports whether layout can be bundled per user.
+func IsPerUserLayout(layout string) bool {
+	return perUserLayouts[layout]
+}
+
+// UserDocument is one user's file of a bundled export.
+type UserDocument struct {
+	UserID   uuid.UUID
+	Username string
+	Data     []byte
 }
 
 type PDFData struct {
@@ -44,31 +80,60 @@ type PDFData struct {
 	EventTeams   []repository.ListEventTeamsRow
 	Coverage     []repository.CoverageRequirement
 	HiddenRanges []repository.EventHiddenRange
+	Phones       map[uuid.UUID]string // user ID -> phone, only loaded for ShowPhones
 }
 
-func (g *PDFGenerator) Generate(ctx context.Context, data PDFData, opts PDFOptions) ([]byte, error) {
-	// Resolve time range (defaults to full event)
-	rangeStart := data.Event.StartTime
-	rangeEnd := data.Event.EndTime
+// resolveRange returns the export time range, defaulting to the full event.
+func resolveRange(event repository.Event, opts PDFOptions) (rangeStart, rangeEnd time.Time) {
+	rangeStart, rangeEnd = event.StartTime, event.EndTime
 	if opts.Start != nil {
 		rangeStart = *opts.Start
 	}
 	if opts.End != nil {
 		rangeEnd = *opts.End
 	}
+	return rangeStart, rangeEnd
+}
+
+// GenerateEach renders a per-user layout separately for every user with
+// shifts in the selection, for bundling as individual files.
+func (g *PDFGenerator) GenerateEach(ctx context.Context, data PDFData, opts PDFOptions) ([]UserDocument, error) {
+	if !IsPerUserLayout(opts.Layout) {
+		return nil, fmt.Errorf("layout %q cannot be split per user", opts.Layout)
+	}
+	rangeStart, rangeEnd := resolveRange(data.Event, opts)
+	users := groupShiftsByUser(FilterShifts(data.Shifts, rangeStart, rangeEnd, opts.UserIDs, opts.TeamIDs))
 
-	// Filter shifts by time range
-	shifts := filterShiftsByTimeRange(data.Shifts, rangeStart, rangeEnd)
-	if len(opts.TeamIDs) > 0 {
-		shifts = filterShiftsByTeams(shifts, opts.TeamIDs)
+	docs := make([]UserDocument, 0, len(users))
+	for _, u := range users {
+		userOpts := opts
+		userOpts.UserIDs = []string{u.id.String()}
+		userOpts.Bundle = ""
+		buf, err := g.Generate(ctx, data, userOpts)
+		if err != nil {
+			return nil, fmt.Errorf("rendering %s: %w", u.username, err)
+		}
+		docs = append(docs, UserDocument{UserID: u.id, Username: u.username, Data: buf})
 	}
-	// Keep day+team-filtered (but not user-filtered) shifts for coverage totals
-	allShifts := shifts
+	return docs, nil
+}
+
+func (g *PDFGenerator) Generate(ctx context.Context, data PDFData, opts PDFOptions) ([]byte, error) {
+	rangeStart, rangeEnd := resolveRange(data.Event, opts)
+
+	// Filter shifts by time range and teams; keep day+team-filtered
+	// (but not user-filtered) shifts for coverage totals
+	allShifts := FilterShifts(data.Shifts, rangeStart, rangeEnd, nil, opts.TeamIDs)
+	shifts := allShifts
 	if len(opts.UserIDs) > 0 {
 		shifts = filterShiftsByUsers(shifts, opts.UserIDs)
 	}
 
-	htmlContent := renderHTML(data.Event, shifts, allShifts, data.EventTeams, data.Coverage, data.HiddenRanges, opts, rangeStart, rangeEnd)
+	if g.renderer == RendererNative {
+		return renderNative(data.Event, shifts, allShifts, data.EventTeams, data.Coverage, data.HiddenRanges, data.Phones, opts, rangeStart, rangeEnd, time.Now())
+	}
+
+	htmlContent := renderHTML(data.Event, shifts, allShifts, data.EventTeams, data.Coverage, data.HiddenRanges, data.Phones, opts, rangeStart, rangeEnd)
 
 	// Paper dimensions in inches
 	width, height := paperDimensions(opts.PaperSize, opts.Landscape)
@@ -137,6 +202,20 @@ func paperDimensions(paperSize string, landscape bool) (width, height float64) {
 
 // --- Shift filtering ---
 
+// FilterShifts returns the shifts overlapping [rangeStart, rangeEnd) that
+// belong to one of userIDs and teamIDs. Empty ID lists match everything.
+// Shared with the CSV export so both honour the same filter semantics.
+func FilterShifts(shifts []repository.ListShiftsByEventRow, rangeStart, rangeEnd time.Time, userIDs, teamIDs []string) []repository.ListShiftsByEventRow {
+	result := filterShiftsByTimeRange(shifts, rangeStart, rangeEnd)
+	if len(teamIDs) > 0 {
+		result = filterShiftsByTeams(result, teamIDs)
+	}
+	if len(userIDs) > 0 {
+		result = filterShiftsByUsers(result, userIDs)
+	}
+	return result
+}
+
 func filterShiftsByTimeRange(shifts []repository.ListShiftsByEventRow, rangeStart, rangeEnd time.Time) []repository.ListShiftsByEventRow {
 	var result []repository.ListShiftsByEventRow
 	for _, s := range shifts {
@@ -272,44 +351,19 @@ func getEventDays(start, end time.Time) []time.Time {
 
 // --- HTML rendering ---
 
-func renderHTML(event repository.Event, shifts []repository.ListShiftsByEventRow, allShifts []repository.ListShiftsByEventRow, eventTeams []repository.ListEventTeamsRow, coverage []repository.CoverageRequirement, hiddenRanges []repository.EventHiddenRange, opts PDFOptions, rangeStart, rangeEnd time.Time) string {
+func renderHTML(event repository.Event, shifts []repository.ListShiftsByEventRow, allShifts []repository.ListShiftsByEventRow, eventTeams []repository.ListEventTeamsRow, coverage []repository.CoverageRequirement, hiddenRanges []repository.EventHiddenRange, phones map[uuid.UUID]string, opts PDFOptions, rangeStart, rangeEnd time.Time) string {
 	// Format-dependent CSS values based on paper + orientation
-	// Printable widths:  A4P=194mm, A4L=281mm, A3P=281mm, A3L=404mm
-	// 3 width tiers: Narrow (A4P), Medium (A4L/A3P), Wide (A3L)
-	bodyFont := "9pt"
-	eventNameFont := "10pt"
-	gridFont := "7pt"
-	shiftFont := "7pt"
-	coverageFont := "6pt"
-	nameColWidth := "25mm"
-	listUserFont := "10pt"
-	listDayFont := "8pt"
-	listShiftFont := "8pt"
-
-	if opts.PaperSize == "A4" && !opts.Landscape {
-		// A4 portrait — narrowest (194mm), use smallest fonts
-		bodyFont = "7pt"
-		eventNameFont = "8pt"
-		gridFont = "6pt"
-		shiftFont = "6pt"
-		coverageFont = "5pt"
-		nameColWidth = "20mm"
-		listUserFont = "9pt"
-		listDayFont = "7pt"
-		listShiftFont = "7pt"
-	} else if opts.PaperSize == "A3" && opts.Landscape {
-		// A3 landscape — widest (404mm), use largest fonts
-		bodyFont = "10pt"
-		eventNameFont = "12pt"
-		gridFont = "9pt"
-		shiftFont = "9pt"
-		coverageFont = "7pt"
-		nameColWidth = "30mm"
-		listUserFont = "12pt"
-		listDayFont = "9pt"
-		listShiftFont = "9pt"
-	}
-	// A4 landscape and A3 portrait share the same width (281mm) — use base values
+	m := metricsFor(opts)
+	pt := func(v float64) string { return fmt.Sprintf("%gpt", v) }
+	bodyFont := pt(m.bodyFont)
+	eventNameFont := pt(m.eventNameFont)
+	gridFont := pt(m.gridFont)
+	shiftFont := pt(m.shiftFont)
+	coverageFont := pt(m.coverageFont)
+	nameColWidth := fmt.Sprintf("%gmm", m.nameColWidth)
+	listUserFont := pt(m.listUserFont)
+	listDayFont := pt(m.listDayFont)
+	listShiftFont := pt(m.listShiftFont)
 
 	var b strings.Builder
 	b.WriteString(`<!DOCTYPE html>
@@ -411,14 +465,25 @@ func renderHTML(event repository.Event, shifts []repository.ListShiftsByEventRow
   color: #666;
   font-style: italic;
 }
-</style>
+`)
+	if opts.Layout == "timesheet" || opts.Layout == "certificate" || opts.Layout == "roster" {
+		writeSheetCSS(&b, m)
+	}
+	b.WriteString(`</style>
 </head>
 <body>
 `)
 
-	if opts.Layout == "list" {
+	switch opts.Layout {
+	case "list":
 		renderListLayout(&b, event, shifts, opts, rangeStart, rangeEnd)
-	} else {
+	case "timesheet":
+		renderTimesheetLayout(&b, event, shifts)
+	case "certificate":
+		renderCertificateLayout(&b, event, shifts)
+	case "roster":
+		renderRosterLayout(&b, event, shifts, phones, opts.ShowPhones)
+	default:
 		renderGridLayout(&b, event, shifts, allShifts, eventTeams, coverage, hiddenRanges, opts, rangeStart, rangeEnd)
 	}
 
@@ -427,75 +492,9 @@ func renderHTML(event repository.Event, shifts []repository.ListShiftsByEventRow
 }
 
 func renderGridLayout(b *strings.Builder, event repository.Event, shifts []repository.ListShiftsByEventRow, allShifts []repository.ListShiftsByEventRow, eventTeams []repository.ListEventTeamsRow, coverage []repository.CoverageRequirement, hiddenRanges []repository.EventHiddenRange, opts PDFOptions, rangeStart, rangeEnd time.Time) {
-	days := getEventDays(rangeStart, rangeEnd)
-	granMinutes := granularityToMinutes(event.TimeGranularity)
 	now := time.Now()
 
-	// Build team map
-	teamMap := make(map[uuid.UUID]teamEntry)
-	for _, et := range eventTeams {
-		teamMap[et.ID] = teamEntry{name: et.Name, abbreviation: et.Abbreviation, color: et.Color}
-	}
-	for _, s := range shifts {
-		if _, ok := teamMap[s.TeamID]; !ok {
-			teamMap[s.TeamID] = teamEntry{name: s.TeamName, abbreviation: s.TeamAbbreviation, color: s.TeamColor}
-		}
-	}
-
-	// Filter teamMap to only selected teams (for coverage rows)
-	if len(opts.TeamIDs) > 0 {
-		teamSet := make(map[string]bool, len(opts.TeamIDs))
-		for _, id := range opts.TeamIDs {
-			teamSet[id] = true
-		}
-		for id := range teamMap {
-			if !teamSet[id.String()] {
-				delete(teamMap, id)
-			}
-		}
-	}
-
-	for dayIdx, day := range days {
-		// Compute day boundaries clamped to event range and time range
-		dayStart := day
-		dayEnd := day.AddDate(0, 0, 1)
-		if dayStart.Before(event.StartTime) {
-			dayStart = event.StartTime
-		}
-		if dayEnd.After(event.EndTime) {
-			dayEnd = event.EndTime
-		}
-		if dayStart.Before(rangeStart) {
-			dayStart = rangeStart
-		}
-		if dayEnd.After(rangeEnd) {
-			dayEnd = rangeEnd
-		}
-
-		slots := generateTimeSlots(dayStart, dayEnd, event.TimeGranularity, hiddenRanges)
-		if len(slots) == 0 {
-			continue
-		}
-
-		// Filter shifts for this day
-		dayStartMs := slots[0]
-		dayEndMs := slots[len(slots)-1].Add(time.Duration(granMinutes) * time.Minute)
-		var dayShifts []repository.ListShiftsByEventRow
-		for _, s := range shifts {
-			if s.StartTime.Before(dayEndMs) && s.EndTime.After(dayStartMs) {
-				dayShifts = append(dayShifts, s)
-			}
-		}
-		// All shifts for this day (not user-filtered) for coverage totals
-		var allDayShifts []repository.ListShiftsByEventRow
-		for _, s := range allShifts {
-			if s.StartTime.Before(dayEndMs) && s.EndTime.After(dayStartMs) {
-				allDayShifts = append(allDayShifts, s)
-			}
-		}
-
-		users := groupShiftsByUser(dayShifts)
-
+	for dayIdx, gd := range buildGrid(event, shifts, allShifts, eventTeams, coverage, hiddenRanges, opts, rangeStart, rangeEnd) {
 		// Page break for non-first days
 		if dayIdx > 0 {
 			b.WriteString(`<div class="print-day-break">`)
@@ -508,14 +507,14 @@ func renderGridLayout(b *strings.Builder, event repository.Event, shifts []repos
 		b.WriteString(`<span class="print-event-name">`)
 		b.WriteString(html.EscapeString(event.Name))
 		b.WriteString(`</span><span>`)
-		b.WriteString(html.EscapeString(formatDay(day)))
+		b.WriteString(html.EscapeString(formatDay(gd.day)))
 		b.WriteString(`</span><span>`)
 		b.WriteString(html.EscapeString(formatTime24(now)))
 		b.WriteString(`</span></div>`)
 
 		// Grid table
 		b.WriteString(`<table class="print-grid-table"><thead><tr><th class="print-name-col">&nbsp;</th>`)
-		for _, slot := range slots {
+		for _, slot := range gd.slots {
 			if slot.Minute() == 0 {
 				b.WriteString(`<th class="print-hour-start">`)
 				b.WriteString(html.EscapeString(formatTime24(slot)))
@@ -527,21 +526,16 @@ func renderGridLayout(b *strings.Builder, event repository.Event, shifts []repos
 		b.WriteString("</tr></thead><tbody>")
 
 		// User rows
-		for _, user := range users {
+		for _, row := range gd.rows {
 			b.WriteString(`<tr><td class="print-name-col">`)
-			b.WriteString(html.EscapeString(userName(user)))
+			b.WriteString(html.EscapeString(row.label))
 			b.WriteString("</td>")
-
-			userShifts := filterShiftsForUser(dayShifts, user.id)
-			renderUserCells(b, userShifts, slots, granMinutes)
-
+			renderUserCells(b, row.cells, gd.slots)
 			b.WriteString("</tr>")
 		}
 
-		// Coverage rows (use allDayShifts to always show total counts)
-		if opts.ShowCoverage {
-			renderCoverageRows(b, teamMap, slots, granMinutes, allDayShifts, coverage)
-		}
+		// Coverage rows (built from all shifts to always show total counts)
+		renderCoverageRows(b, gd.coverage, gd.slots)
 
 		b.WriteString("</tbody></table></div>")
 	}
@@ -557,62 +551,27 @@ func filterShiftsForUser(shifts []repository.ListShiftsByEventRow, userID uuid.U
 	return result
 }
 
-func renderUserCells(b *strings.Builder, userShifts []repository.ListShiftsByEventRow, slots []time.Time, granMinutes int) {
-	rendered := make(map[uuid.UUID]bool)
-	skipUntil := -1
-
-	for i := 0; i < len(slots); i++ {
-		if i < skipUntil {
-			continue
-		}
-
-		slotMs := slots[i]
-		slotEnd := slotMs.Add(time.Duration(granMinutes) * time.Minute)
-
-		// Find shift covering this slot
-		var found *repository.ListShiftsByEventRow
-		for idx := range userShifts {
-			s := &userShifts[idx]
-			if rendered[s.ID] {
-				continue
-			}
-			if s.StartTime.Before(slotEnd) && s.EndTime.After(slotMs) {
-				found = s
-				break
-			}
-		}
-
-		if found != nil {
-			rendered[found.ID] = true
-			// Calculate colspan
-			span := 0
-			for j := i; j < len(slots); j++ {
-				if !slots[j].Before(found.EndTime) {
-					break
-				}
-				span++
-			}
-			if span < 1 {
-				span = 1
-			}
-			skipUntil = i + span
-
-			bgColor := found.TeamColor + "33"
-
-			cls := "print-shift-cell"
-			if slots[i].Minute() == 0 {
-				cls = "print-shift-cell print-hour-start"
-			}
-			b.WriteString(fmt.Sprintf(`<td colspan="%d" class="%s" style="background-color:%s">`, span, cls, html.EscapeString(bgColor)))
-			b.WriteString(html.EscapeString(found.TeamAbbreviation))
-			b.WriteString("</td>")
-		} else {
-			if slots[i].Minute() == 0 {
+func renderUserCells(b *strings.Builder, cells []gridCell, slots []time.Time) {
+	for _, c := range cells {
+		hourStart := slots[c.slot].Minute() == 0
+		if c.shift == nil {
+			if hourStart {
 				b.WriteString(`<td class="print-hour-start"></td>`)
 			} else {
 				b.WriteString("<td></td>")
 			}
+			continue
+		}
+
+		bgColor := c.shift.TeamColor + "33"
+
+		cls := "print-shift-cell"
+		if hourStart {
+			cls = "print-shift-cell print-hour-start"
 		}
+		b.WriteString(fmt.Sprintf(`<td colspan="%d" class="%s" style="background-color:%s">`, c.span, cls, html.EscapeString(bgColor)))
+		b.WriteString(html.EscapeString(c.shift.TeamAbbreviation))
+		b.WriteString("</td>")
 	}
 }
 
@@ -622,63 +581,29 @@ type teamEntry struct {
 	color        string
 }
 
-func renderCoverageRows(b *strings.Builder, teamMap map[uuid.UUID]teamEntry, slots []time.Time, granMinutes int, dayShifts []repository.ListShiftsByEventRow, coverage []repository.CoverageRequirement) {
-	// Sort teams deterministically
-	type teamKV struct {
-		id   uuid.UUID
-		team teamEntry
+// coverageColor returns the cell background: green when met, red when short.
+func coverageColor(c coverageCell) string {
+	if c.required == 0 {
+		return "transparent"
 	}
-	var teams []teamKV
-	for id, t := range teamMap {
-		teams = append(teams, teamKV{id, t})
+	if c.count >= c.required {
+		return "#dcfce7"
 	}
-	sort.Slice(teams, func(i, j int) bool {
-		return teams[i].team.abbreviation < teams[j].team.abbreviation
-	})
+	return "#fef2f2"
+}
 
-	for _, tkv := range teams {
+func renderCoverageRows(b *strings.Builder, rows []coverageRow, slots []time.Time) {
+	for _, row := range rows {
 		b.WriteString(`<tr class="print-coverage-row"><td class="print-name-col">`)
-		b.WriteString(html.EscapeString(tkv.team.abbreviation))
+		b.WriteString(html.EscapeString(row.label))
 		b.WriteString("</td>")
 
-		for _, slot := range slots {
-			slotEnd := slot.Add(time.Duration(granMinutes) * time.Minute)
-
-			// Count shifts for this team in this slot
-			count := 0
-			for _, s := range dayShifts {
-				if s.TeamID == tkv.id && s.StartTime.Before(slotEnd) && s.EndTime.After(slot) {
-					count++
-				}
-			}
-
-			// Find coverage requirement
-			required := 0
-			for _, c := range coverage {
-				if c.TeamID == tkv.id && !c.StartTime.After(slot) && c.EndTime.After(slot) {
-					required = int(c.RequiredCount)
-					break
-				}
-			}
-
-			bgColor := "transparent"
-			if required > 0 {
-				if count >= required {
-					bgColor = "#dcfce7"
-				} else {
-					bgColor = "#fef2f2"
-				}
-			}
-
-			content := ""
-			if required > 0 {
-				content = fmt.Sprintf("%d/%d", count, required)
-			}
-
+		for i, slot := range slots {
+			c := row.cells[i]
 			if slot.Minute() == 0 {
-				b.WriteString(fmt.Sprintf(`<td class="print-hour-start" style="background-color:%s">%s</td>`, bgColor, content))
+				b.WriteString(fmt.Sprintf(`<td class="print-hour-start" style="background-color:%s">%s</td>`, coverageColor(c), c.text()))
 			} else {
-				b.WriteString(fmt.Sprintf(`<td style="background-color:%s">%s</td>`, bgColor, content))
+				b.WriteString(fmt.Sprintf(`<td style="background-color:%s">%s</td>`, coverageColor(c), c.text()))
 			}
 		}
 
@@ -688,15 +613,7 @@ func renderCoverageRows(b *strings.Builder, teamMap map[uuid.UUID]teamEntry, slo
 
 func renderListLayout(b *strings.Builder, event repository.Event, shifts []repository.ListShiftsByEventRow, opts PDFOptions, rangeStart, rangeEnd time.Time) {
 	now := time.Now()
-	users := groupShiftsByUser(shifts)
-	days := getEventDays(rangeStart, rangeEnd)
-	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
-
-	// Date range label
-	dateRange := ""
-	if len(days) > 0 {
-		dateRange = formatDay(days[0]) + " – " + formatDay(days[len(days)-1])
-	}
+	blocks, dateRange := buildList(shifts, rangeStart, rangeEnd)
 
 	renderListHeader := func() {
 		b.WriteString(`<div class="print-page-header">`)
@@ -715,7 +632,7 @@ func renderListLayout(b *strings.Builder, event repository.Event, shifts []repos
 	}
 
 	// User blocks
-	for userIdx, user := range users {
+	for userIdx, block := range blocks {
 		if opts.OnePerPage {
 			if userIdx > 0 {
 				b.WriteString(`<div class="print-day-break">`)
@@ -727,41 +644,22 @@ func renderListLayout(b *strings.Builder, event repository.Event, shifts []repos
 
 		b.WriteString(`<div class="print-list-user">`)
 		b.WriteString(`<div class="print-list-user-name">`)
-		b.WriteString(html.EscapeString(userName(user)))
+		b.WriteString(html.EscapeString(userName(block.user)))
 		b.WriteString("</div>")
 
-		userShifts := filterShiftsForUser(shifts, user.id)
-		sort.Slice(userShifts, func(i, j int) bool {
-			return userShifts[i].StartTime.Before(userShifts[j].StartTime)
-		})
-
-		for _, day := range days {
-			dayStart := day
-			dayEnd := day.AddDate(0, 0, 1)
-
-			var overlapping []repository.ListShiftsByEventRow
-			for _, s := range userShifts {
-				if s.StartTime.Before(dayEnd) && s.EndTime.After(dayStart) {
-					overlapping = append(overlapping, s)
-				}
-			}
-
-			if len(overlapping) == 0 {
-				continue
-			}
-
+		for _, ld := range block.days {
 			b.WriteString(`<div class="print-list-day-header">`)
-			b.WriteString(html.EscapeString(formatDay(day)))
+			b.WriteString(html.EscapeString(formatDay(ld.day)))
 			b.WriteString("</div>")
 
-			for _, shift := range overlapping {
+			for _, shift := range ld.shifts {
 				b.WriteString(`<div class="print-list-shift">`)
 				b.WriteString(fmt.Sprintf(`<span class="print-team-dot" style="background-color:%s"></span>`, html.EscapeString(shift.TeamColor)))
 				b.WriteString("<span>")
 				b.WriteString(html.EscapeString(formatTime24(shift.StartTime)))
 				b.WriteString("–")
 				b.WriteString(html.EscapeString(formatTime24(shift.EndTime)))
-				if shift.StartTime.YearDay() != shift.EndTime.YearDay() || shift.StartTime.Year() != shift.EndTime.Year() {
+				if crossesMidnight(shift) {
 					b.WriteString(` <span class="print-cross-midnight">(`)
 					b.WriteString(html.EscapeString(formatDay(shift.EndTime)))
 					b.WriteString(")</span>")
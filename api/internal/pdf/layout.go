@@ -0,0 +1,480 @@
+package pdf
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Layout model shared by the Chrome (HTML) and native renderers, so both
+// produce the same pages from the same data.
+
+// printMetrics holds the paper-dependent font sizes (pt) and column widths (mm).
+type printMetrics struct {
+	bodyFont      float64
+	eventNameFont float64
+	gridFont      float64
+	shiftFont     float64
+	coverageFont  float64
+	nameColWidth  float64
+	listUserFont  float64
+	listDayFont   float64
+	listShiftFont float64
+}
+
+// metricsFor picks one of three width tiers.
+// Printable widths:  A4P=194mm, A4L=281mm, A3P=281mm, A3L=404mm
+func metricsFor(opts PDFOptions) printMetrics {
+	switch {
+	case opts.PaperSize == "A4" && !opts.Landscape:
+		// A4 portrait — narrowest (194mm), use smallest fonts
+		return printMetrics{
+			bodyFont: 7, eventNameFont: 8, gridFont: 6, shiftFont: 6, coverageFont: 5,
+			nameColWidth: 20, listUserFont: 9, listDayFont: 7, listShiftFont: 7,
+		}
+	case opts.PaperSize == "A3" && opts.Landscape:
+		// A3 landscape — widest (404mm), use largest fonts
+		return printMetrics{
+			bodyFont: 10, eventNameFont: 12, gridFont: 9, shiftFont: 9, coverageFont: 7,
+			nameColWidth: 30, listUserFont: 12, listDayFont: 9, listShiftFont: 9,
+		}
+	default:
+		// A4 landscape and A3 portrait share the same width (281mm)
+		return printMetrics{
+			bodyFont: 9, eventNameFont: 10, gridFont: 7, shiftFont: 7, coverageFont: 6,
+			nameColWidth: 25, listUserFont: 10, listDayFont: 8, listShiftFont: 8,
+		}
+	}
+}
+
+// gridDay is one page (or run of pages) of the grid layout.
+type gridDay struct {
+	day      time.Time
+	slots    []time.Time
+	rows     []gridRow
+	coverage []coverageRow
+}
+
+type gridRow struct {
+	label string
+	cells []gridCell
+}
+
+// gridCell spans one or more slots starting at slot; shift is nil for an
+// empty cell.
+type gridCell struct {
+	slot  int
+	span  int
+	shift *repository.ListShiftsByEventRow
+}
+
+type coverageRow struct {
+	label string
+	cells []coverageCell
+}
+
+type coverageCell struct {
+	count    int
+	required int
+}
+
+func (c coverageCell) text() string {
+	if c.required == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", c.count, c.required)
+}
+
+// buildGrid computes the per-day grid pages. Days without visible slots are
+// skipped.
+func buildGrid(event repository.Event, shifts, allShifts []repository.ListShiftsByEventRow, eventTeams []repository.ListEventTeamsRow, coverage []repository.CoverageRequirement, hiddenRanges []repository.EventHiddenRange, opts PDFOptions, rangeStart, rangeEnd time.Time) []gridDay {
+	days := getEventDays(rangeStart, rangeEnd)
+	granMinutes := granularityToMinutes(event.TimeGranularity)
+
+	// Build team map
+	teamMap := make(map[uuid.UUID]teamEntry)
+	for _, et := range eventTeams {
+		teamMap[et.ID] = teamEntry{name: et.Name, abbreviation: et.Abbreviation, color: et.Color}
+	}
+	for _, s := range shifts {
+		if _, ok := teamMap[s.TeamID]; !ok {
+			teamMap[s.TeamID] = teamEntry{name: s.TeamName, abbreviation: s.TeamAbbreviation, color: s.TeamColor}
+		}
+	}
+
+	// Filter teamMap to only selected teams (for coverage rows)
+	if len(opts.TeamIDs) > 0 {
+		teamSet := make(map[string]bool, len(opts.TeamIDs))
+		for _, id := range opts.TeamIDs {
+			teamSet[id] = true
+		}
+		for id := range teamMap {
+			if !teamSet[id.String()] {
+				delete(teamMap, id)
+			}
+		}
+	}
+
+	var result []gridDay
+	for _, day := range days {
+		// Compute day boundaries clamped to event range and time range
+		dayStart := day
+		dayEnd := day.AddDate(0, 0, 1)
+		if dayStart.Before(event.StartTime) {
+			dayStart = event.StartTime
+		}
+		if dayEnd.After(event.EndTime) {
+			dayEnd = event.EndTime
+		}
+		if dayStart.Before(rangeStart) {
+			dayStart = rangeStart
+		}
+		if dayEnd.After(rangeEnd) {
+			dayEnd = rangeEnd
+		}
+
+		slots := generateTimeSlots(dayStart, dayEnd, event.TimeGranularity, hiddenRanges)
+		if len(slots) == 0 {
+			continue
+		}
+
+		// Filter shifts for this day
+		dayStartMs := slots[0]
+		dayEndMs := slots[len(slots)-1].Add(time.Duration(granMinutes) * time.Minute)
+		var dayShifts []repository.ListShiftsByEventRow
+		for _, s := range shifts {
+			if s.StartTime.Before(dayEndMs) && s.EndTime.After(dayStartMs) {
+				dayShifts = append(dayShifts, s)
+			}
+		}
+		// All shifts for this day (not user-filtered) for coverage totals
+		var allDayShifts []repository.ListShiftsByEventRow
+		for _, s := range allShifts {
+			if s.StartTime.Before(dayEndMs) && s.EndTime.After(dayStartMs) {
+				allDayShifts = append(allDayShifts, s)
+			}
+		}
+
+		gd := gridDay{day: day, slots: slots}
+		for _, user := range groupShiftsByUser(dayShifts) {
+			gd.rows = append(gd.rows, gridRow{
+				label: userName(user),
+				cells: userCells(filterShiftsForUser(dayShifts, user.id), slots, granMinutes),
+			})
+		}
+		if opts.ShowCoverage {
+			gd.coverage = coverageRows(teamMap, slots, granMinutes, allDayShifts, coverage)
+		}
+		result = append(result, gd)
+	}
+	return result
+}
+
+func userCells(userShifts []repository.ListShiftsByEventRow, slots []time.Time, granMinutes int) []gridCell {
+	var cells []gridCell
+	rendered := make(map[uuid.UUID]bool)
+	skipUntil := -1
+
+	for i := 0; i < len(slots); i++ {
+		if i < skipUntil {
+			continue
+		}
+
+		slotMs := slots[i]
+		slotEnd := slotMs.Add(time.Duration(granMinutes) * time.Minute)
+
+		// Find shift covering this slot
+		var found *repository.ListShiftsByEventRow
+		for idx := range userShifts {
+			s := &userShifts[idx]
+			if rendered[s.ID] {
+				continue
+			}
+			if s.StartTime.Before(slotEnd) && s.EndTime.After(slotMs) {
+				found = s
+				break
+			}
+		}
+
+		if found == nil {
+			cells = append(cells, gridCell{slot: i, span: 1})
+			continue
+		}
+
+		rendered[found.ID] = true
+		// Calculate colspan
+		span := 0
+		for j := i; j < len(slots); j++ {
+			if !slots[j].Before(found.EndTime) {
+				break
+			}
+			span++
+		}
+		if span < 1 {
+			span = 1
+		}
+		skipUntil = i + span
+		cells = append(cells, gridCell{slot: i, span: span, shift: found})
+	}
+	return cells
+}
+
+func coverageRows(teamMap map[uuid.UUID]teamEntry, slots []time.Time, granMinutes int, dayShifts []repository.ListShiftsByEventRow, coverage []repository.CoverageRequirement) []coverageRow {
+	// Sort teams deterministically
+	type teamKV struct {
+		id   uuid.UUID
+		team teamEntry
+	}
+	var teams []teamKV
+	for id, t := range teamMap {
+		teams = append(teams, teamKV{id, t})
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		return teams[i].team.abbreviation < teams[j].team.abbreviation
+	})
+
+	rows := make([]coverageRow, 0, len(teams))
+	for _, tkv := range teams {
+		row := coverageRow{label: tkv.team.abbreviation, cells: make([]coverageCell, len(slots))}
+		for i, slot := range slots {
+			slotEnd := slot.Add(time.Duration(granMinutes) * time.Minute)
+
+			// Count shifts for this team in this slot
+			for _, s := range dayShifts {
+				if s.TeamID == tkv.id && s.StartTime.Before(slotEnd) && s.EndTime.After(slot) {
+					row.cells[i].count++
+				}
+			}
+
+			// Find coverage requirement
+			for _, c := range coverage {
+				if c.TeamID == tkv.id && !c.StartTime.After(slot) && c.EndTime.After(slot) {
+					row.cells[i].required = int(c.RequiredCount)
+					break
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// listBlock is one user's section of the list layout.
+type listBlock struct {
+	user userInfo
+	days []listDay
+}
+
+type listDay struct {
+	day    time.Time
+	shifts []repository.ListShiftsByEventRow
+}
+
+// buildList groups shifts per user and day and returns the blocks together
+// with the date range label for the page header.
+func buildList(shifts []repository.ListShiftsByEventRow, rangeStart, rangeEnd time.Time) ([]listBlock, string) {
+	users := groupShiftsByUser(shifts)
+	days := getEventDays(rangeStart, rangeEnd)
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	// Date range label
+	dateRange := ""
+	if len(days) > 0 {
+		dateRange = formatDay(days[0]) + " – " + formatDay(days[len(days)-1])
+	}
+
+	blocks := make([]listBlock, 0, len(users))
+	for _, user := range users {
+		userShifts := filterShiftsForUser(shifts, user.id)
+		sort.Slice(userShifts, func(i, j int) bool {
+			return userShifts[i].StartTime.Before(userShifts[j].StartTime)
+		})
+
+		block := listBlock{user: user}
+		for _, day := range days {
+			dayStart := day
+			dayEnd := day.AddDate(0, 0, 1)
+
+			var overlapping []repository.ListShiftsByEventRow
+			for _, s := range userShifts {
+				if s.StartTime.Before(dayEnd) && s.EndTime.After(dayStart) {
+					overlapping = append(overlapping, s)
+				}
+			}
+			if len(overlapping) > 0 {
+				block.days = append(block.days, listDay{day: day, shifts: overlapping})
+			}
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, dateRange
+}
+
+func crossesMidnight(s repository.ListShiftsByEventRow) bool {
+	return s.StartTime.YearDay() != s.EndTime.YearDay() || s.StartTime.Year() != s.EndTime.Year()
+}
+
+// timesheet is one user's page of the timesheet and certificate layouts.
+type timesheet struct {
+	user   userInfo
+	shifts []repository.ListShiftsByEventRow // by start time
+	teams  []teamHours                       // by hours, descending
+	total  time.Duration
+}
+
+type teamHours struct {
+	name   string
+	shifts int
+	hours  time.Duration
+}
+
+// buildTimesheets groups shifts per user and sums the planned hours overall
+// and per team.
+func buildTimesheets(shifts []repository.ListShiftsByEventRow) []timesheet {
+	users := groupShiftsByUser(shifts)
+	sheets := make([]timesheet, 0, len(users))
+	for _, user := range users {
+		sheet := timesheet{user: user, shifts: filterShiftsForUser(shifts, user.id)}
+		sort.Slice(sheet.shifts, func(i, j int) bool {
+			return sheet.shifts[i].StartTime.Before(sheet.shifts[j].StartTime)
+		})
+
+		byTeam := make(map[uuid.UUID]*teamHours)
+		var order []uuid.UUID
+		for _, s := range sheet.shifts {
+			th, ok := byTeam[s.TeamID]
+			if !ok {
+				th = &teamHours{name: s.TeamName}
+				byTeam[s.TeamID] = th
+				order = append(order, s.TeamID)
+			}
+			d := s.EndTime.Sub(s.StartTime)
+			th.shifts++
+			th.hours += d
+			sheet.total += d
+		}
+		for _, id := range order {
+			sheet.teams = append(sheet.teams, *byTeam[id])
+		}
+		sort.SliceStable(sheet.teams, func(i, j int) bool {
+			return sheet.teams[i].hours > sheet.teams[j].hours
+		})
+		sheets = append(sheets, sheet)
+	}
+	return sheets
+}
+
+// period returns the first and last day the user worked.
+func (t timesheet) period() (first, last time.Time) {
+	if len(t.shifts) == 0 {
+		return
+	}
+	first = t.shifts[0].StartTime
+	for _, s := range t.shifts {
+		if s.EndTime.After(last) {
+			last = s.EndTime
+		}
+	}
+	return first, last
+}
+
+func formatHours(d time.Duration) string {
+	return fmt.Sprintf("%.2f", d.Hours())
+}
+
+func formatDate(t time.Time) string {
+	return t.Format("2 January 2006")
+}
+
+// certificatePeriod describes the days worked: "on 4 July 2025" or
+// "from 4 July 2025 to 6 July 2025".
+func certificatePeriod(t timesheet) string {
+	first, last := t.period()
+	// A shift ending at midnight belongs to the day before
+	last = last.Add(-time.Nanosecond)
+	if first.Year() == last.Year() && first.YearDay() == last.YearDay() {
+		return "on " + formatDate(first)
+	}
+	return "from " + formatDate(first) + " to " + formatDate(last)
+}
+
+// certificateText is the body of a volunteer certificate.
+func certificateText(event repository.Event, t timesheet) string {
+	where := event.Name
+	if event.Location != nil && *event.Location != "" {
+		where += " (" + *event.Location + ")"
+	}
+	return fmt.Sprintf("%s volunteered at %s %s, working %d shifts with a total of %s hours.",
+		userName(t.user), where, certificatePeriod(t), len(t.shifts), formatHours(t.total))
+}
+
+// rosterTeam is one team's section of the roster layout.
+type rosterTeam struct {
+	name string
+	days []listDay
+}
+
+// buildRoster groups shifts by team and by the day they start, one entry per
+// shift. Teams are sorted by name, shifts by start time and then name.
+func buildRoster(shifts []repository.ListShiftsByEventRow) []rosterTeam {
+	byTeam := make(map[uuid.UUID]*rosterTeam)
+	var order []uuid.UUID
+	sorted := append([]repository.ListShiftsByEventRow(nil), shifts...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].StartTime.Equal(sorted[j].StartTime) {
+			return sorted[i].StartTime.Before(sorted[j].StartTime)
+		}
+		return sorted[i].Username < sorted[j].Username
+	})
+
+	for _, s := range sorted {
+		team, ok := byTeam[s.TeamID]
+		if !ok {
+			team = &rosterTeam{name: s.TeamName}
+			byTeam[s.TeamID] = team
+			order = append(order, s.TeamID)
+		}
+		day := time.Date(s.StartTime.Year(), s.StartTime.Month(), s.StartTime.Day(), 0, 0, 0, 0, s.StartTime.Location())
+		if n := len(team.days); n == 0 || !team.days[n-1].day.Equal(day) {
+			team.days = append(team.days, listDay{day: day})
+		}
+		last := &team.days[len(team.days)-1]
+		last.shifts = append(last.shifts, s)
+	}
+
+	teams := make([]rosterTeam, 0, len(order))
+	for _, id := range order {
+		teams = append(teams, *byTeam[id])
+	}
+	sort.SliceStable(teams, func(i, j int) bool { return teams[i].name < teams[j].name })
+	return teams
+}
+
+// rosterColumns returns the roster table header; the phone column is only
+// present when phone numbers are shown.
+func rosterColumns(showPhones bool) []string {
+	if showPhones {
+		return []string{"Time", "Name", "Phone", "Check-in", "Signature"}
+	}
+	return []string{"Time", "Name", "Check-in", "Signature"}
+}
+
+// rosterCells returns the cells of one roster row; check-in and signature
+// are left blank for writing in.
+func rosterCells(s repository.ListShiftsByEventRow, phones map[uuid.UUID]string, showPhones bool) []string {
+	cells := []string{formatTime24(s.StartTime) + "–" + shiftEndLabel(s), rosterName(s)}
+	if showPhones {
+		cells = append(cells, phones[s.UserID])
+	}
+	return append(cells, "", "")
+}
+
+func rosterName(s repository.ListShiftsByEventRow) string {
+	if s.UserDisplayName != nil && *s.UserDisplayName != "" {
+		return *s.UserDisplayName
+	}
+	return s.UserFullName
+}
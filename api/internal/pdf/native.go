@@ -0,0 +1,502 @@
+package pdf
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+)
+
+// renderNative draws the grid or list layout directly as PDF, mirroring the
+// HTML/CSS used by the Chrome renderer.
+func renderNative(event repository.Event, shifts []repository.ListShiftsByEventRow, allShifts []repository.ListShiftsByEventRow, eventTeams []repository.ListEventTeamsRow, coverage []repository.CoverageRequirement, hiddenRanges []repository.EventHiddenRange, phones map[uuid.UUID]string, opts PDFOptions, rangeStart, rangeEnd, now time.Time) ([]byte, error) {
+	width, height := paperDimensions(opts.PaperSize, opts.Landscape)
+	doc := newDocument(width*72, height*72)
+	w := &nativeWriter{
+		doc:    doc,
+		m:      metricsFor(opts),
+		margin: 0.12 * 72,
+		now:    formatTime24(now),
+	}
+
+	emptyTitle := ""
+	switch opts.Layout {
+	case "list":
+		blocks, dateRange := buildList(shifts, rangeStart, rangeEnd)
+		w.listLayout(event, blocks, dateRange, opts.OnePerPage)
+	case "timesheet":
+		w.timesheetLayout(event, buildTimesheets(shifts))
+		emptyTitle = timesheetTitle
+	case "certificate":
+		w.certificateLayout(event, buildTimesheets(shifts))
+		emptyTitle = certificateTitle
+	case "roster":
+		w.rosterLayout(event, buildRoster(shifts), phones, opts.ShowPhones)
+		emptyTitle = rosterTitle
+	default:
+		w.gridLayout(event, buildGrid(event, shifts, allShifts, eventTeams, coverage, hiddenRanges, opts, rangeStart, rangeEnd))
+	}
+
+	// An empty export still yields a valid one-page document
+	if len(doc.pages) == 0 {
+		w.newPage()
+		w.pageHeader(event.Name, emptyTitle)
+	}
+	return doc.bytes()
+}
+
+type nativeWriter struct {
+	doc    *document
+	m      printMetrics
+	margin float64
+	now    string
+	y      float64 // current top position on the page
+}
+
+const (
+	thinLine  = 0.5
+	thickLine = 1.5
+	headerPt  = 7.0
+)
+
+func (w *nativeWriter) contentWidth() float64 {
+	return w.doc.width - 2*w.margin
+}
+
+func (w *nativeWriter) bottom() float64 {
+	return w.doc.height - w.margin
+}
+
+func (w *nativeWriter) newPage() {
+	w.doc.addPage()
+	w.y = w.margin
+}
+
+// pageHeader draws the event name, the centre label and the print time on
+// one line with a rule below, like .print-page-header.
+func (w *nativeWriter) pageHeader(eventName, center string) {
+	lineHeight := w.m.eventNameFont * 1.25
+	baseline := w.y + w.m.eventNameFont
+
+	left := w.margin
+	cw := w.contentWidth()
+	nowWidth := textWidth(w.now, fontRegular, headerPt)
+	centerWidth := textWidth(center, fontRegular, headerPt)
+
+	name := fitText(eventName, fontBold, w.m.eventNameFont, cw/2-centerWidth/2-2*mmToPoint)
+	w.doc.text(left, baseline, fontBold, w.m.eventNameFont, black, name)
+	w.doc.text(left+(cw-centerWidth)/2, baseline, fontRegular, headerPt, black, center)
+	w.doc.text(left+cw-nowWidth, baseline, fontRegular, headerPt, black, w.now)
+
+	w.y += lineHeight + 0.5*mmToPoint
+	w.doc.line(left, w.y, left+cw, w.y, gray999, thinLine)
+	w.y += 1 * mmToPoint
+}
+
+// --- Grid layout ---
+
+func (w *nativeWriter) gridLayout(event repository.Event, days []gridDay) {
+	for _, gd := range days {
+		w.newPage()
+		w.pageHeader(event.Name, formatDay(gd.day))
+
+		nameCol := w.m.nameColWidth * mmToPoint
+		slotWidth := (w.contentWidth() - nameCol) / float64(len(gd.slots))
+		rowHeight := w.m.gridFont*1.2 + 0.6*mmToPoint + 1
+		coverageHeight := w.m.coverageFont*1.2 + 0.6*mmToPoint + 1
+		x := func(slot int) float64 { return w.margin + nameCol + float64(slot)*slotWidth }
+
+		tableHeader := func() {
+			top := w.y
+			for i, slot := range gd.slots {
+				if slot.Minute() != 0 {
+					continue
+				}
+				// Hour labels may run into the following sub-hour columns
+				span := 1
+				for span < len(gd.slots)-i && gd.slots[i+span].Minute() != 0 {
+					span++
+				}
+				label := fitText(formatTime24(slot), fontBold, w.m.gridFont, float64(span)*slotWidth-1)
+				w.doc.text(x(i)+(slotWidth*float64(span)-textWidth(label, fontBold, w.m.gridFont))/2,
+					top+rowHeight-(rowHeight-w.m.gridFont)/2-1, fontBold, w.m.gridFont, black, label)
+			}
+			w.gridLines(top, rowHeight, []gridCell{{slot: 0, span: len(gd.slots)}}, gd.slots, slotWidth, nameCol, true)
+			w.y += rowHeight
+		}
+
+		tableHeader()
+		ensureRow := func(h float64) {
+			if w.y+h > w.bottom() {
+				// Continue the day on a new page with the header repeated,
+				// like a <thead> in print
+				w.newPage()
+				w.pageHeader(event.Name, formatDay(gd.day))
+				tableHeader()
+			}
+		}
+
+		for _, row := range gd.rows {
+			ensureRow(rowHeight)
+			top := w.y
+			for _, c := range row.cells {
+				if c.shift == nil {
+					continue
+				}
+				cellWidth := float64(c.span) * slotWidth
+				w.doc.rect(x(c.slot), top, cellWidth, rowHeight, hexColor(c.shift.TeamColor+"33"), noColor, 0)
+				label := fitText(c.shift.TeamAbbreviation, fontBold, w.m.shiftFont, cellWidth-1)
+				w.doc.text(x(c.slot)+(cellWidth-textWidth(label, fontBold, w.m.shiftFont))/2,
+					top+rowHeight-(rowHeight-w.m.shiftFont)/2-1, fontBold, w.m.shiftFont, black, label)
+			}
+			w.nameCell(row.label, top, rowHeight, nameCol, w.m.gridFont)
+			w.gridLines(top, rowHeight, row.cells, gd.slots, slotWidth, nameCol, false)
+			w.y += rowHeight
+		}
+
+		for _, row := range gd.coverage {
+			ensureRow(coverageHeight)
+			top := w.y
+			cells := make([]gridCell, len(row.cells))
+			for i, c := range row.cells {
+				cells[i] = gridCell{slot: i, span: 1}
+				if c.required == 0 {
+					continue
+				}
+				fill := coverLow
+				if c.count >= c.required {
+					fill = coverOK
+				}
+				w.doc.rect(x(i), top, slotWidth, coverageHeight, fill, noColor, 0)
+				label := fitText(c.text(), fontRegular, w.m.coverageFont, slotWidth-1)
+				w.doc.text(x(i)+(slotWidth-textWidth(label, fontRegular, w.m.coverageFont))/2,
+					top+coverageHeight-(coverageHeight-w.m.coverageFont)/2-1, fontRegular, w.m.coverageFont, black, label)
+			}
+			w.nameCell(row.label, top, coverageHeight, nameCol, w.m.coverageFont)
+			w.gridLines(top, coverageHeight, cells, gd.slots, slotWidth, nameCol, false)
+			w.y += coverageHeight
+		}
+	}
+}
+
+func (w *nativeWriter) nameCell(label string, top, height, nameCol, size float64) {
+	label = fitText(label, fontRegular, size, nameCol-0.6*mmToPoint-thickLine)
+	w.doc.text(w.margin+0.3*mmToPoint, top+height-(height-size)/2-1, fontRegular, size, black, label)
+}
+
+// gridLines draws the borders of one table row: thin lines around every
+// cell, a thick rule right of the name column and at each full hour. The
+// header row gets separators only at the hour starts.
+func (w *nativeWriter) gridLines(top, height float64, cells []gridCell, slots []time.Time, slotWidth, nameCol float64, header bool) {
+	left := w.margin
+	right := w.margin + w.contentWidth()
+	w.doc.line(left, top, right, top, gray999, thinLine)
+	w.doc.line(left, top+height, right, top+height, gray999, thinLine)
+	w.doc.line(left, top, left, top+height, gray999, thinLine)
+	w.doc.line(right, top, right, top+height, gray999, thinLine)
+
+	edge := func(slot int) {
+		xPos := left + nameCol + float64(slot)*slotWidth
+		if slots[slot].Minute() == 0 {
+			w.doc.line(xPos, top, xPos, top+height, gray333, thickLine)
+		} else {
+			w.doc.line(xPos, top, xPos, top+height, gray999, thinLine)
+		}
+	}
+	for _, c := range cells {
+		if header {
+			for i := c.slot + 1; i < c.slot+c.span; i++ {
+				if slots[i].Minute() == 0 {
+					edge(i)
+				}
+			}
+			continue
+		}
+		if c.slot > 0 {
+			edge(c.slot)
+		}
+	}
+	w.doc.line(left+nameCol, top, left+nameCol, top+height, gray333, thickLine)
+}
+
+// --- List layout ---
+
+func (w *nativeWriter) listLayout(event repository.Event, blocks []listBlock, dateRange string, onePerPage bool) {
+	userLine := w.m.listUserFont*1.25 + 1.5*mmToPoint
+	dayLine := w.m.listDayFont*1.25 + 1*mmToPoint
+	shiftLine := w.m.listShiftFont * 1.35
+	blockGap := 4 * mmToPoint
+
+	if !onePerPage {
+		w.newPage()
+		w.pageHeader(event.Name, dateRange)
+	}
+	pageTop := w.y
+
+	for i, block := range blocks {
+		if onePerPage {
+			w.newPage()
+			w.pageHeader(event.Name, dateRange)
+			pageTop = w.y
+		} else if i > 0 {
+			// Keep a user's block on one page where possible
+			height := userLine
+			for _, ld := range block.days {
+				height += dayLine + float64(len(ld.shifts))*shiftLine
+			}
+			if w.y+height > w.bottom() && w.y > pageTop {
+				w.newPage()
+				pageTop = w.y
+			}
+		}
+
+		ensure := func(h float64) {
+			if w.y+h > w.bottom() {
+				w.newPage()
+				pageTop = w.y
+			}
+		}
+
+		// User name with underline
+		ensure(userLine)
+		left := w.margin
+		w.doc.text(left, w.y+w.m.listUserFont, fontBold, w.m.listUserFont, black,
+			fitText(userName(block.user), fontBold, w.m.listUserFont, w.contentWidth()))
+		underline := w.y + w.m.listUserFont*1.25 + 0.5*mmToPoint
+		w.doc.line(left, underline, left+w.contentWidth(), underline, grayCCC, thinLine)
+		w.y += userLine
+
+		for _, ld := range block.days {
+			ensure(dayLine + shiftLine)
+			w.y += 1 * mmToPoint
+			w.doc.text(left+3*mmToPoint, w.y+w.m.listDayFont, fontBold, w.m.listDayFont, black, formatDay(ld.day))
+			w.y += dayLine - 1*mmToPoint
+
+			for _, s := range ld.shifts {
+				ensure(shiftLine)
+				w.shiftLine(s)
+				w.y += shiftLine
+			}
+		}
+		w.y += blockGap
+	}
+}
+
+// shiftLine draws one .print-list-shift row: team dot, times and team.
+func (w *nativeWriter) shiftLine(s repository.ListShiftsByEventRow) {
+	size := w.m.listShiftFont
+	baseline := w.y + size
+	x := w.margin + 6*mmToPoint
+	gap := 2 * mmToPoint
+
+	dot := 2.5 * mmToPoint
+	w.doc.rect(x, baseline-dot+0.5, dot, dot, hexColor(s.TeamColor), noColor, 0)
+	x += dot + gap
+
+	times := formatTime24(s.StartTime) + "–" + formatTime24(s.EndTime)
+	w.doc.text(x, baseline, fontRegular, size, black, times)
+	x += textWidth(times, fontRegular, size)
+	if crossesMidnight(s) {
+		x += textWidth(" ", fontRegular, size)
+		next := "(" + formatDay(s.EndTime) + ")"
+		w.doc.text(x, baseline, fontItalic, size, gray666, next)
+		x += textWidth(next, fontItalic, size)
+	}
+	x += gap
+
+	team := fitText(s.TeamName+" ("+s.TeamAbbreviation+")", fontRegular, size, w.margin+w.contentWidth()-x)
+	w.doc.text(x, baseline, fontRegular, size, black, team)
+}
+
+// --- Timesheet and certificate layouts ---
+
+type sheetColumn struct {
+	width float64
+	right bool // right-aligned (numbers)
+}
+
+// sheetColumns splits width by the given fractions.
+func sheetColumns(width float64, fractions []float64, rightFrom int) []sheetColumn {
+	cols := make([]sheetColumn, len(fractions))
+	for i, f := range fractions {
+		cols[i] = sheetColumn{width: width * f, right: i >= rightFrom}
+	}
+	return cols
+}
+
+// sheetRow draws one bordered .print-sheet-table row at the current position.
+func (w *nativeWriter) sheetRow(left float64, cols []sheetColumn, cells []string, f font, size float64, fill rgb) {
+	w.sheetRowSized(left, cols, cells, f, size, fill, w.sheetRowHeight(size))
+}
+
+// sheetRowSized draws a sheetRow with an explicit height.
+func (w *nativeWriter) sheetRowSized(left float64, cols []sheetColumn, cells []string, f font, size float64, fill rgb, height float64) {
+	pad := 1.5 * mmToPoint
+	x := left
+	for i, col := range cols {
+		w.doc.rect(x, w.y, col.width, height, fill, gray999, thinLine)
+		if i < len(cells) && cells[i] != "" {
+			text := fitText(cells[i], f, size, col.width-2*pad)
+			tx := x + pad
+			if col.right {
+				tx = x + col.width - pad - textWidth(text, f, size)
+			}
+			w.doc.text(tx, w.y+height-(height-size)/2-1, f, size, black, text)
+		}
+		x += col.width
+	}
+	w.y += height
+}
+
+func (w *nativeWriter) sheetRowHeight(size float64) float64 {
+	return size*1.2 + 2*mmToPoint
+}
+
+// signatures draws the .print-signatures block: labelled lines side by side.
+func (w *nativeWriter) signatures(left, width float64, labels ...string) {
+	gap := 15 * mmToPoint
+	w.y += 15 * mmToPoint
+	lineWidth := (width - gap*float64(len(labels)-1)) / float64(len(labels))
+	for i, l := range labels {
+		x := left + float64(i)*(lineWidth+gap)
+		w.doc.line(x, w.y, x+lineWidth, w.y, black, thinLine)
+		w.doc.text(x, w.y+1*mmToPoint+headerPt, fontRegular, headerPt, gray666, fitText(l, fontRegular, headerPt, lineWidth))
+	}
+	w.y += 1*mmToPoint + headerPt*1.25
+}
+
+var (
+	signatureHeight = 16*mmToPoint + headerPt*1.25
+	sheetHeaderFill = hexColor("#f3f4f6")
+)
+
+func (w *nativeWriter) timesheetLayout(event repository.Event, sheets []timesheet) {
+	size := w.m.listShiftFont
+	nameSize := w.m.listUserFont + 2
+	left := w.margin
+	cols := sheetColumns(w.contentWidth(), []float64{0.16, 0.1, 0.2, 0.3, 0.12, 0.12}, 4)
+	totalCols := []sheetColumn{{width: cols[0].width + cols[1].width + cols[2].width + cols[3].width}, cols[4], cols[5]}
+	rowHeight := w.sheetRowHeight(size)
+
+	for _, sheet := range sheets {
+		w.newPage()
+		w.pageHeader(event.Name, timesheetTitle)
+
+		w.y += 3 * mmToPoint
+		w.doc.text(left, w.y+nameSize, fontBold, nameSize, black, fitText(userName(sheet.user), fontBold, nameSize, w.contentWidth()))
+		w.y += nameSize*1.25 + 2*mmToPoint
+
+		w.sheetRow(left, cols, timesheetColumns, fontBold, size, sheetHeaderFill)
+		for _, s := range sheet.shifts {
+			if w.y+rowHeight > w.bottom() {
+				// Continue on a new page with the header repeated
+				w.newPage()
+				w.pageHeader(event.Name, timesheetTitle)
+				w.sheetRow(left, cols, timesheetColumns, fontBold, size, sheetHeaderFill)
+			}
+			w.sheetRow(left, cols, []string{
+				formatDay(s.StartTime),
+				formatTime24(s.StartTime),
+				shiftEndLabel(s),
+				s.TeamName,
+				formatHours(s.EndTime.Sub(s.StartTime)),
+				"",
+			}, fontRegular, size, noColor)
+		}
+		if w.y+rowHeight+signatureHeight > w.bottom() {
+			w.newPage()
+			w.pageHeader(event.Name, timesheetTitle)
+		}
+		w.sheetRow(left, totalCols, []string{"Total", formatHours(sheet.total), ""}, fontBold, size, noColor)
+
+		w.signatures(left, w.contentWidth(), "Date, volunteer signature", "Date, coordinator signature")
+	}
+}
+
+func (w *nativeWriter) certificateLayout(event repository.Event, sheets []timesheet) {
+	const (
+		titleSize = 24.0
+		nameSize  = 18.0
+		textSize  = 12.0
+		tableSize = 11.0
+	)
+	left := w.margin + 10*mmToPoint
+	width := w.contentWidth() - 20*mmToPoint
+	cols := sheetColumns(width, []float64{0.6, 0.2, 0.2}, 1)
+
+	centered := func(f font, size float64, s string) {
+		s = fitText(s, f, size, width)
+		w.doc.text(left+(width-textWidth(s, f, size))/2, w.y+size, f, size, black, s)
+		w.y += size * 1.25
+	}
+
+	for _, sheet := range sheets {
+		w.newPage()
+		w.y += 15 * mmToPoint
+
+		centered(fontBold, titleSize, certificateTitle)
+		w.y += 12 * mmToPoint
+		centered(fontBold, nameSize, userName(sheet.user))
+		w.y += 8 * mmToPoint
+
+		for _, line := range wrapText(certificateText(event, sheet), fontRegular, textSize, width) {
+			w.doc.text(left, w.y+textSize, fontRegular, textSize, black, line)
+			w.y += textSize * 1.5
+		}
+		w.y += 8 * mmToPoint
+
+		w.sheetRow(left, cols, []string{"Team", "Shifts", "Hours"}, fontBold, tableSize, sheetHeaderFill)
+		for _, t := range sheet.teams {
+			if w.y+w.sheetRowHeight(tableSize) > w.bottom() {
+				w.newPage()
+			}
+			w.sheetRow(left, cols, []string{t.name, fmt.Sprint(t.shifts), formatHours(t.hours)}, fontRegular, tableSize, noColor)
+		}
+		if w.y+w.sheetRowHeight(tableSize)+signatureHeight > w.bottom() {
+			w.newPage()
+		}
+		w.sheetRow(left, cols, []string{"Total", fmt.Sprint(len(sheet.shifts)), formatHours(sheet.total)}, fontBold, tableSize, noColor)
+
+		w.signatures(left, width, "Place, date", "Signature, "+event.Name)
+	}
+}
+
+// --- Roster layout ---
+
+func (w *nativeWriter) rosterLayout(event repository.Event, teams []rosterTeam, phones map[uuid.UUID]string, showPhones bool) {
+	size := w.m.listShiftFont
+	titleSize := w.m.listUserFont + 2
+	left := w.margin
+	columns := rosterColumns(showPhones)
+	cols := sheetColumns(w.contentWidth(), rosterWidths(showPhones), len(columns))
+	dayCols := []sheetColumn{{width: w.contentWidth()}}
+	rowHeight := max(w.sheetRowHeight(size), rosterMinRowHeight*mmToPoint)
+	dayHeight := w.sheetRowHeight(size)
+
+	for _, team := range teams {
+		page := func() {
+			w.newPage()
+			w.pageHeader(event.Name, rosterTitle)
+			w.y += 2 * mmToPoint
+			w.doc.text(left, w.y+titleSize, fontBold, titleSize, black, fitText(team.name, fontBold, titleSize, w.contentWidth()))
+			w.y += titleSize*1.25 + 2*mmToPoint
+			w.sheetRow(left, cols, columns, fontBold, size, sheetHeaderFill)
+		}
+		page()
+
+		for _, day := range team.days {
+			// Keep the day heading with its first shift
+			if w.y+dayHeight+rowHeight > w.bottom() {
+				page()
+			}
+			w.sheetRow(left, dayCols, []string{formatDay(day.day)}, fontBold, size, sheetHeaderFill)
+			for _, s := range day.shifts {
+				if w.y+rowHeight > w.bottom() {
+					page()
+				}
+				w.sheetRowSized(left, cols, rosterCells(s, phones, showPhones), fontRegular, size, noColor, rowHeight)
+			}
+		}
+	}
+}
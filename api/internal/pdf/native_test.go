@@ -0,0 +1,167 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+)
+
+func TestRenderNative(t *testing.T) {
+	bar := uuid.New()
+	alice, bob := uuid.New(), uuid.New()
+	start := time.Date(2025, 7, 4, 8, 0, 0, 0, time.UTC)
+	event := repository.Event{
+		Name:            "Sommerfest (Zürich)",
+		StartTime:       start,
+		EndTime:         start.Add(40 * time.Hour),
+		TimeGranularity: "30min",
+	}
+	shift := func(user uuid.UUID, name string, from, to int) repository.ListShiftsByEventRow {
+		return repository.ListShiftsByEventRow{
+			ID: uuid.New(), UserID: user, Username: name, UserFullName: name,
+			TeamID: bar, TeamName: "Bar", TeamAbbreviation: "B", TeamColor: "#3b82f6",
+			StartTime: start.Add(time.Duration(from) * time.Hour), EndTime: start.Add(time.Duration(to) * time.Hour),
+		}
+	}
+	shifts := []repository.ListShiftsByEventRow{
+		shift(alice, "alice", 1, 3),
+		shift(bob, "bob", 14, 18), // crosses midnight
+	}
+	teams := []repository.ListEventTeamsRow{{ID: bar, Name: "Bar", Abbreviation: "B", Color: "#3b82f6"}}
+	phones := map[uuid.UUID]string{alice: "+49 30 1234"}
+
+	tests := []struct {
+		name      string
+		opts      PDFOptions
+		wantPages int
+		wantText  []string
+	}{
+		{
+			name:      "grid",
+			opts:      PDFOptions{Layout: "grid", PaperSize: "A4", Landscape: true, ShowCoverage: true},
+			wantPages: 2, // one per day
+			wantText:  []string{"(Sommerfest \\(Z\xfcrich\\))", "(alice)", "(09:00)"},
+		},
+		{
+			name:      "list one per page",
+			opts:      PDFOptions{Layout: "list", PaperSize: "A4", OnePerPage: true},
+			wantPages: 2,
+			wantText:  []string{"(bob)", "(22:00\x9602:00)", "(\\(Sat 5 Jul\\))"},
+		},
+		{
+			name:      "timesheet",
+			opts:      PDFOptions{Layout: "timesheet", PaperSize: "A4"},
+			wantPages: 2,
+			wantText:  []string{"(Timesheet)", "(Actual \\(h\\))", "(02:00 \\(Sat 5 Jul\\))", "(4.00)", "(Date, coordinator signature)"},
+		},
+		{
+			name:      "certificate",
+			opts:      PDFOptions{Layout: "certificate", PaperSize: "A4"},
+			wantPages: 2,
+			wantText:  []string{"(Certificate of Volunteer Service)", "(alice)", "(Bar)", "(2.00)"},
+		},
+		{
+			name:      "roster",
+			opts:      PDFOptions{Layout: "roster", PaperSize: "A4", ShowPhones: true},
+			wantPages: 1, // one team
+			wantText:  []string{"(Roster)", "(Bar)", "(Fri 4 Jul)", "(22:00\x9602:00 \\(Sat 5 Jul\\))", "(09:00\x9611:00)", "(+49 30 1234)", "(Signature)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := renderNative(event, shifts, shifts, teams, nil, nil, phones, tt.opts, event.StartTime, event.EndTime, start)
+			if err != nil {
+				t.Fatalf("renderNative() error: %v", err)
+			}
+			content := checkPDFStructure(t, out)
+			if got := strings.Count(string(out), "/Type /Page "); got != tt.wantPages {
+				t.Errorf("got %d pages, want %d", got, tt.wantPages)
+			}
+			for _, want := range tt.wantText {
+				if !strings.Contains(content, want) {
+					t.Errorf("page content does not contain %q", want)
+				}
+			}
+		})
+	}
+}
+
+// checkPDFStructure verifies that every xref offset points at its object and
+// returns the decompressed content of all pages.
+func checkPDFStructure(t *testing.T, out []byte) string {
+	t.Helper()
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4\n")) {
+		t.Fatal("missing PDF header")
+	}
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(out)
+	if m == nil {
+		t.Fatal("missing startxref trailer")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(out[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[xref:], -1)
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(out[off:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", i+1, out[off:off+10])
+		}
+	}
+
+	var content strings.Builder
+	for _, s := range regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`).FindAllSubmatch(out, -1) {
+		zr, err := zlib.NewReader(bytes.NewReader(s[1]))
+		if err != nil {
+			t.Fatalf("content stream: %v", err)
+		}
+		b, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("content stream: %v", err)
+		}
+		content.Write(b)
+	}
+	return content.String()
+}
+
+func TestCertificateText(t *testing.T) {
+	start := time.Date(2025, 7, 4, 20, 0, 0, 0, time.UTC)
+	location := "Zürich"
+	event := repository.Event{Name: "Sommerfest", Location: &location}
+	user := uuid.New()
+	shift := func(team string, from, to int) repository.ListShiftsByEventRow {
+		return repository.ListShiftsByEventRow{
+			UserID: user, Username: "bob", UserFullName: "Bob Example", TeamName: team, TeamID: uuid.NewSHA1(uuid.Nil, []byte(team)),
+			StartTime: start.Add(time.Duration(from) * time.Hour), EndTime: start.Add(time.Duration(to) * time.Hour),
+		}
+	}
+
+	sheets := buildTimesheets([]repository.ListShiftsByEventRow{shift("Bar", 0, 4), shift("Entry", 20, 21), shift("Bar", 22, 23)})
+	if len(sheets) != 1 {
+		t.Fatalf("got %d timesheets, want 1", len(sheets))
+	}
+	if got := sheets[0].teams; len(got) != 2 || got[0].name != "Bar" || got[0].shifts != 2 || got[0].hours != 5*time.Hour {
+		t.Errorf("team totals = %+v", got)
+	}
+
+	want := "Bob Example volunteered at Sommerfest (Zürich) from 4 July 2025 to 5 July 2025, working 3 shifts with a total of 6.00 hours."
+	if got := certificateText(event, sheets[0]); got != want {
+		t.Errorf("certificateText() =\n%s\nwant\n%s", got, want)
+	}
+
+	// A single night shift ending at midnight counts as one day
+	sheets = buildTimesheets([]repository.ListShiftsByEventRow{shift("Bar", 0, 4)})
+	if got := certificatePeriod(sheets[0]); got != "on 4 July 2025" {
+		t.Errorf("certificatePeriod() = %q", got)
+	}
+}
@@ -0,0 +1,72 @@
+package pdf
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Roster layout: a sign-in sheet per team to hang up at the station. One
+// row per shift, grouped by day, with blank columns for the check-in time
+// and signature. Each team starts on a new page.
+
+const rosterTitle = "Roster"
+
+// rosterMinRowHeight leaves room to sign by hand (mm).
+const rosterMinRowHeight = 8.0
+
+// rosterWidths are the column widths as fractions of the page width.
+func rosterWidths(showPhones bool) []float64 {
+	if showPhones {
+		return []float64{0.17, 0.28, 0.17, 0.12, 0.26}
+	}
+	return []float64{0.18, 0.34, 0.14, 0.34}
+}
+
+func renderRosterLayout(b *strings.Builder, event repository.Event, shifts []repository.ListShiftsByEventRow, phones map[uuid.UUID]string, showPhones bool) {
+	now := time.Now()
+	teams := buildRoster(shifts)
+	if len(teams) == 0 {
+		writeSheetHeader(b, event.Name, rosterTitle, now)
+		return
+	}
+
+	columns := rosterColumns(showPhones)
+	widths := rosterWidths(showPhones)
+	for i, team := range teams {
+		sheetPage(b, i, "print-roster")
+		writeSheetHeader(b, event.Name, rosterTitle, now)
+
+		b.WriteString(`<div class="print-roster-title">`)
+		b.WriteString(html.EscapeString(team.name))
+		b.WriteString("</div>")
+
+		b.WriteString(`<table class="print-sheet-table"><thead><tr>`)
+		for c, col := range columns {
+			fmt.Fprintf(b, `<th style="width:%.0f%%">`, widths[c]*100)
+			b.WriteString(html.EscapeString(col))
+			b.WriteString("</th>")
+		}
+		b.WriteString("</tr></thead><tbody>")
+
+		for _, day := range team.days {
+			fmt.Fprintf(b, `<tr class="print-roster-day"><td colspan="%d">`, len(columns))
+			b.WriteString(html.EscapeString(formatDay(day.day)))
+			b.WriteString("</td></tr>")
+			for _, s := range day.shifts {
+				b.WriteString(`<tr class="print-roster-row">`)
+				for _, cell := range rosterCells(s, phones, showPhones) {
+					b.WriteString("<td>")
+					b.WriteString(html.EscapeString(cell))
+					b.WriteString("</td>")
+				}
+				b.WriteString("</tr>")
+			}
+		}
+		b.WriteString("</tbody></table></div>")
+	}
+}
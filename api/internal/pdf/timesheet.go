@@ -0,0 +1,217 @@
+package pdf
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/repository"
+)
+
+// Timesheet and certificate layouts: one page per user. The timesheet lists
+// every shift with its planned hours, a blank column for the hours actually
+// worked and a signature block; the certificate confirms the total hours per
+// team.
+
+const (
+	timesheetTitle   = "Timesheet"
+	certificateTitle = "Certificate of Volunteer Service"
+)
+
+// Table header of the timesheet layout.
+var timesheetColumns = []string{"Date", "Start", "End", "Team", "Planned (h)", "Actual (h)"}
+
+// writeSheetCSS styles the table-based layouts: timesheet, certificate and
+// roster.
+func writeSheetCSS(b *strings.Builder, m printMetrics) {
+	fmt.Fprintf(b, `.print-sheet-name {
+  font-size: %gpt;
+  font-weight: bold;
+  margin: 3mm 0 2mm;
+}
+.print-sheet-table {
+  width: 100%%;
+  border-collapse: collapse;
+  font-size: %gpt;
+}
+.print-sheet-table th,
+.print-sheet-table td {
+  border: 0.5pt solid #999;
+  padding: 1mm 1.5mm;
+  text-align: left;
+  white-space: nowrap;
+}
+.print-sheet-table th { background-color: #f3f4f6; }
+.print-sheet-table tr { break-inside: avoid; }
+.print-sheet-table .print-num { text-align: right; }
+.print-sheet-table tfoot td { font-weight: bold; }
+.print-signatures {
+  display: flex;
+  gap: 15mm;
+  margin-top: 15mm;
+  break-inside: avoid;
+}
+.print-signature {
+  flex: 1;
+  border-top: 0.5pt solid #000;
+  padding-top: 1mm;
+  font-size: 7pt;
+  color: #666;
+}
+.print-certificate {
+  padding: 15mm 10mm 0;
+  font-size: 12pt;
+}
+.print-certificate .print-sheet-table { font-size: 11pt; }
+.print-roster-title {
+  font-size: %gpt;
+  font-weight: bold;
+  margin: 2mm 0;
+}
+.print-roster-day td {
+  background-color: #f3f4f6;
+  font-weight: bold;
+}
+.print-roster-row td { height: 8mm; }
+.print-certificate-title {
+  font-size: 24pt;
+  font-weight: bold;
+  text-align: center;
+  margin-bottom: 12mm;
+}
+.print-certificate-name {
+  font-size: 18pt;
+  font-weight: bold;
+  text-align: center;
+  margin-bottom: 8mm;
+}
+.print-certificate-text {
+  line-height: 1.5;
+  margin-bottom: 8mm;
+}
+`, m.listUserFont+2, m.listShiftFont, m.listUserFont+2)
+}
+
+func writeSheetHeader(b *strings.Builder, eventName, center string, now time.Time) {
+	b.WriteString(`<div class="print-page-header">`)
+	b.WriteString(`<span class="print-event-name">`)
+	b.WriteString(html.EscapeString(eventName))
+	b.WriteString(`</span><span>`)
+	b.WriteString(html.EscapeString(center))
+	b.WriteString(`</span><span>`)
+	b.WriteString(html.EscapeString(formatTime24(now)))
+	b.WriteString(`</span></div>`)
+}
+
+func writeSignatures(b *strings.Builder, labels ...string) {
+	b.WriteString(`<div class="print-signatures">`)
+	for _, l := range labels {
+		b.WriteString(`<div class="print-signature">`)
+		b.WriteString(html.EscapeString(l))
+		b.WriteString("</div>")
+	}
+	b.WriteString("</div>")
+}
+
+// sheetPage opens the page of the i-th user.
+func sheetPage(b *strings.Builder, i int, class string) {
+	if i > 0 {
+		class += " print-day-break"
+	}
+	fmt.Fprintf(b, `<div class="%s">`, class)
+}
+
+// shiftEndLabel is the end time, with the day appended for shifts running
+// past midnight.
+func shiftEndLabel(s repository.ListShiftsByEventRow) string {
+	end := formatTime24(s.EndTime)
+	if crossesMidnight(s) {
+		end += " (" + formatDay(s.EndTime) + ")"
+	}
+	return end
+}
+
+func renderTimesheetLayout(b *strings.Builder, event repository.Event, shifts []repository.ListShiftsByEventRow) {
+	now := time.Now()
+	sheets := buildTimesheets(shifts)
+	if len(sheets) == 0 {
+		writeSheetHeader(b, event.Name, timesheetTitle, now)
+		return
+	}
+
+	for i, sheet := range sheets {
+		sheetPage(b, i, "print-sheet")
+		writeSheetHeader(b, event.Name, timesheetTitle, now)
+
+		b.WriteString(`<div class="print-sheet-name">`)
+		b.WriteString(html.EscapeString(userName(sheet.user)))
+		b.WriteString("</div>")
+
+		b.WriteString(`<table class="print-sheet-table"><thead><tr>`)
+		for c, col := range timesheetColumns {
+			if c >= 4 { // hour columns
+				b.WriteString(`<th class="print-num">`)
+			} else {
+				b.WriteString("<th>")
+			}
+			b.WriteString(html.EscapeString(col))
+			b.WriteString("</th>")
+		}
+		b.WriteString("</tr></thead><tbody>")
+
+		for _, s := range sheet.shifts {
+			b.WriteString("<tr><td>")
+			b.WriteString(html.EscapeString(formatDay(s.StartTime)))
+			b.WriteString("</td><td>")
+			b.WriteString(html.EscapeString(formatTime24(s.StartTime)))
+			b.WriteString("</td><td>")
+			b.WriteString(html.EscapeString(shiftEndLabel(s)))
+			b.WriteString("</td><td>")
+			b.WriteString(html.EscapeString(s.TeamName))
+			b.WriteString(`</td><td class="print-num">`)
+			b.WriteString(formatHours(s.EndTime.Sub(s.StartTime)))
+			b.WriteString(`</td><td class="print-num"></td></tr>`)
+		}
+
+		b.WriteString(`</tbody><tfoot><tr><td colspan="4">Total</td><td class="print-num">`)
+		b.WriteString(formatHours(sheet.total))
+		b.WriteString(`</td><td class="print-num"></td></tr></tfoot></table>`)
+
+		writeSignatures(b, "Date, volunteer signature", "Date, coordinator signature")
+		b.WriteString("</div>")
+	}
+}
+
+func renderCertificateLayout(b *strings.Builder, event repository.Event, shifts []repository.ListShiftsByEventRow) {
+	now := time.Now()
+	sheets := buildTimesheets(shifts)
+	if len(sheets) == 0 {
+		writeSheetHeader(b, event.Name, certificateTitle, now)
+		return
+	}
+
+	for i, sheet := range sheets {
+		sheetPage(b, i, "print-certificate")
+
+		b.WriteString(`<div class="print-certificate-title">`)
+		b.WriteString(certificateTitle)
+		b.WriteString(`</div><div class="print-certificate-name">`)
+		b.WriteString(html.EscapeString(userName(sheet.user)))
+		b.WriteString(`</div><p class="print-certificate-text">`)
+		b.WriteString(html.EscapeString(certificateText(event, sheet)))
+		b.WriteString("</p>")
+
+		b.WriteString(`<table class="print-sheet-table"><thead><tr><th>Team</th><th class="print-num">Shifts</th><th class="print-num">Hours</th></tr></thead><tbody>`)
+		for _, t := range sheet.teams {
+			b.WriteString("<tr><td>")
+			b.WriteString(html.EscapeString(t.name))
+			fmt.Fprintf(b, `</td><td class="print-num">%d</td><td class="print-num">%s</td></tr>`, t.shifts, formatHours(t.hours))
+		}
+		fmt.Fprintf(b, `</tbody><tfoot><tr><td>Total</td><td class="print-num">%d</td><td class="print-num">%s</td></tr></tfoot></table>`,
+			len(sheet.shifts), formatHours(sheet.total))
+
+		writeSignatures(b, "Place, date", "Signature, "+event.Name)
+		b.WriteString("</div>")
+	}
+}
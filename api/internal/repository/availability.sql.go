@@ -13,7 +13,7 @@ import (
 )
 
 const listAvailabilityByEvent = `-- name: ListAvailabilityByEvent :many
-SELECT ua.id, ua.event_id, ua.user_id, ua.start_time, ua.end_time, ua.status, ua.note, u.username, u.full_name AS user_full_name, u.display_name AS user_display_name
+SELECT ua.id, ua.event_id, ua.user_id, ua.start_time, ua.end_time, ua.status, ua.note, ua.source, u.username, u.full_name AS user_full_name, u.display_name AS user_display_name
 FROM user_availability ua
 JOIN users u ON ua.user_id = u.id
 WHERE ua.event_id = $1
@@ -28,6 +28,7 @@ type ListAvailabilityByEventRow struct {
 	EndTime         time.Time `json:"end_time"`
 	Status          string    `json:"status"`
 	Note            *string   `json:"note"`
+	Source          string    `json:"source"`
 	Username        string    `json:"username"`
 	UserFullName    string    `json:"user_full_name"`
 	UserDisplayName *string   `json:"user_display_name"`
@@ -50,6 +51,7 @@ func (q *Queries) ListAvailabilityByEvent(ctx context.Context, eventID uuid.UUID
 			&i.EndTime,
 			&i.Status,
 			&i.Note,
+			&i.Source,
 			&i.Username,
 			&i.UserFullName,
 			&i.UserDisplayName,
@@ -65,7 +67,7 @@ func (q *Queries) ListAvailabilityByEvent(ctx context.Context, eventID uuid.UUID
 }
 
 const listAvailabilityByEventAndUser = `-- name: ListAvailabilityByEventAndUser :many
-SELECT id, event_id, user_id, start_time, end_time, status, note FROM user_availability WHERE event_id = $1 AND user_id = $2 ORDER BY start_time
+SELECT id, event_id, user_id, start_time, end_time, status, note, source FROM user_availability WHERE event_id = $1 AND user_id = $2 ORDER BY start_time
 `
 
 func (q *Queries) ListAvailabilityByEventAndUser(ctx context.Context, eventID uuid.UUID, userID uuid.UUID) ([]UserAvailability, error) {
@@ -85,6 +87,7 @@ func (q *Queries) ListAvailabilityByEventAndUser(ctx context.Context, eventID uu
 			&i.EndTime,
 			&i.Status,
 			&i.Note,
+			&i.Source,
 		); err != nil {
 			return nil, err
 		}
@@ -97,7 +100,7 @@ func (q *Queries) ListAvailabilityByEventAndUser(ctx context.Context, eventID uu
 }
 
 const createAvailability = `-- name: CreateAvailability :one
-INSERT INTO user_availability (event_id, user_id, start_time, end_time, status, note) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, event_id, user_id, start_time, end_time, status, note
+INSERT INTO user_availability (event_id, user_id, start_time, end_time, status, note, source) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, event_id, user_id, start_time, end_time, status, note, source
 `
 
 type CreateAvailabilityParams struct {
@@ -107,6 +110,7 @@ type CreateAvailabilityParams struct {
 	EndTime   time.Time `json:"end_time"`
 	Status    string    `json:"status"`
 	Note      *string   `json:"note"`
+	Source    string    `json:"source"`
 }
 
 func (q *Queries) CreateAvailability(ctx context.Context, arg CreateAvailabilityParams) (UserAvailability, error) {
@@ -117,6 +121,7 @@ func (q *Queries) CreateAvailability(ctx context.Context, arg CreateAvailability
 		arg.EndTime,
 		arg.Status,
 		arg.Note,
+		arg.Source,
 	)
 	var i UserAvailability
 	err := row.Scan(
@@ -127,15 +132,122 @@ func (q *Queries) CreateAvailability(ctx context.Context, arg CreateAvailability
 		&i.EndTime,
 		&i.Status,
 		&i.Note,
+		&i.Source,
 	)
 	return i, err
 }
 
 const deleteAvailabilityByEventAndUser = `-- name: DeleteAvailabilityByEventAndUser :exec
-DELETE FROM user_availability WHERE event_id = $1 AND user_id = $2
+DELETE FROM user_availability WHERE event_id = $1 AND user_id = $2 AND source = $3
 `
 
-func (q *Queries) DeleteAvailabilityByEventAndUser(ctx context.Context, eventID uuid.UUID, userID uuid.UUID) error {
-	_, err := q.db.Exec(ctx, deleteAvailabilityByEventAndUser, eventID, userID)
+func (q *Queries) DeleteAvailabilityByEventAndUser(ctx context.Context, eventID uuid.UUID, userID uuid.UUID, source string) error {
+	_, err := q.db.Exec(ctx, deleteAvailabilityByEventAndUser, eventID, userID, source)
 	return err
 }
+
+const lockUserAvailability = `-- name: LockUserAvailability :exec
+SELECT pg_advisory_xact_lock(hashtextextended($1::text || '/' || $2::text, 0))
+`
+
+// LockUserAvailability serializes changes to a user's availability for an
+// event until the end of the transaction.
+func (q *Queries) LockUserAvailability(ctx context.Context, eventID uuid.UUID, userID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, lockUserAvailability, eventID, userID)
+	return err
+}
+
+const getAvailabilityICalFeed = `-- name: GetAvailabilityICalFeed :one
+SELECT event_id, user_id, url, last_synced_at, last_error, created_at FROM availability_ical_feeds
+WHERE event_id = $1 AND user_id = $2
+`
+
+func (q *Queries) GetAvailabilityICalFeed(ctx context.Context, eventID uuid.UUID, userID uuid.UUID) (AvailabilityIcalFeed, error) {
+	row := q.db.QueryRow(ctx, getAvailabilityICalFeed, eventID, userID)
+	var i AvailabilityIcalFeed
+	err := row.Scan(
+		&i.EventID,
+		&i.UserID,
+		&i.Url,
+		&i.LastSyncedAt,
+		&i.LastError,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const upsertAvailabilityICalFeed = `-- name: UpsertAvailabilityICalFeed :one
+INSERT INTO availability_ical_feeds (event_id, user_id, url)
+VALUES ($1, $2, $3)
+ON CONFLICT (event_id, user_id)
+DO UPDATE SET url = EXCLUDED.url, last_synced_at = NULL, last_error = NULL
+RETURNING event_id, user_id, url, last_synced_at, last_error, created_at
+`
+
+func (q *Queries) UpsertAvailabilityICalFeed(ctx context.Context, eventID uuid.UUID, userID uuid.UUID, url string) (AvailabilityIcalFeed, error) {
+	row := q.db.QueryRow(ctx, upsertAvailabilityICalFeed, eventID, userID, url)
+	var i AvailabilityIcalFeed
+	err := row.Scan(
+		&i.EventID,
+		&i.UserID,
+		&i.Url,
+		&i.LastSyncedAt,
+		&i.LastError,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateAvailabilityICalFeedSync = `-- name: UpdateAvailabilityICalFeedSync :exec
+UPDATE availability_ical_feeds SET last_synced_at = NOW(), last_error = $3
+WHERE event_id = $1 AND user_id = $2
+`
+
+func (q *Queries) UpdateAvailabilityICalFeedSync(ctx context.Context, eventID uuid.UUID, userID uuid.UUID, lastError *string) error {
+	_, err := q.db.Exec(ctx, updateAvailabilityICalFeedSync, eventID, userID, lastError)
+	return err
+}
+
+const deleteAvailabilityICalFeed = `-- name: DeleteAvailabilityICalFeed :exec
+DELETE FROM availability_ical_feeds WHERE event_id = $1 AND user_id = $2
+`
+
+func (q *Queries) DeleteAvailabilityICalFeed(ctx context.Context, eventID uuid.UUID, userID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteAvailabilityICalFeed, eventID, userID)
+	return err
+}
+
+const listAvailabilityICalFeedsDue = `-- name: ListAvailabilityICalFeedsDue :many
+SELECT f.event_id, f.user_id, f.url, f.last_synced_at, f.last_error, f.created_at
+FROM availability_ical_feeds f
+JOIN events e ON f.event_id = e.id
+WHERE e.end_time > NOW() AND (f.last_synced_at IS NULL OR f.last_synced_at < $1)
+ORDER BY f.last_synced_at NULLS FIRST
+`
+
+func (q *Queries) ListAvailabilityICalFeedsDue(ctx context.Context, syncedBefore time.Time) ([]AvailabilityIcalFeed, error) {
+	rows, err := q.db.Query(ctx, listAvailabilityICalFeedsDue, syncedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AvailabilityIcalFeed{}
+	for rows.Next() {
+		var i AvailabilityIcalFeed
+		if err := rows.Scan(
+			&i.EventID,
+			&i.UserID,
+			&i.Url,
+			&i.LastSyncedAt,
+			&i.LastError,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
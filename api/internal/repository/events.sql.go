@@ -13,7 +13,7 @@ import (
 )
 
 const getEventByID = `-- name: GetEventByID :one
-SELECT id, name, slug, description, location, participant_count, start_time, end_time, time_granularity, is_locked, is_public, created_by, created_at, updated_at FROM events WHERE id = $1
+SELECT id, name, slug, description, location, participant_count, start_time, end_time, time_granularity, is_locked, is_public, created_by, created_at, updated_at, timezone FROM events WHERE id = $1
 `
 
 func (q *Queries) GetEventByID(ctx context.Context, id uuid.UUID) (Event, error) {
@@ -34,12 +34,13 @@ func (q *Queries) GetEventByID(ctx context.Context, id uuid.UUID) (Event, error)
 		&i.CreatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Timezone,
 	)
 	return i, err
 }
 
 const getEventBySlug = `-- name: GetEventBySlug :one
-SELECT id, name, slug, description, location, participant_count, start_time, end_time, time_granularity, is_locked, is_public, created_by, created_at, updated_at FROM events WHERE slug = $1
+SELECT id, name, slug, description, location, participant_count, start_time, end_time, time_granularity, is_locked, is_public, created_by, created_at, updated_at, timezone FROM events WHERE slug = $1
 `
 
 func (q *Queries) GetEventBySlug(ctx context.Context, slug string) (Event, error) {
@@ -60,12 +61,13 @@ func (q *Queries) GetEventBySlug(ctx context.Context, slug string) (Event, error
 		&i.CreatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Timezone,
 	)
 	return i, err
 }
 
 const listEvents = `-- name: ListEvents :many
-SELECT id, name, slug, description, location, participant_count, start_time, end_time, time_granularity, is_locked, is_public, created_by, created_at, updated_at FROM events ORDER BY start_time DESC
+SELECT id, name, slug, description, location, participant_count, start_time, end_time, time_granularity, is_locked, is_public, created_by, created_at, updated_at, timezone FROM events ORDER BY start_time DESC
 `
 
 func (q *Queries) ListEvents(ctx context.Context) ([]Event, error) {
@@ -92,6 +94,7 @@ func (q *Queries) ListEvents(ctx context.Context) ([]Event, error) {
 			&i.CreatedBy,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Timezone,
 		); err != nil {
 			return nil, err
 		}
@@ -104,9 +107,9 @@ func (q *Queries) ListEvents(ctx context.Context) ([]Event, error) {
 }
 
 const createEvent = `-- name: CreateEvent :one
-INSERT INTO events (name, slug, description, location, participant_count, start_time, end_time, time_granularity, created_by)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-RETURNING id, name, slug, description, location, participant_count, start_time, end_time, time_granularity, is_locked, is_public, created_by, created_at, updated_at
+INSERT INTO events (name, slug, description, location, participant_count, start_time, end_time, time_granularity, created_by, timezone)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, name, slug, description, location, participant_count, start_time, end_time, time_granularity, is_locked, is_public, created_by, created_at, updated_at, timezone
 `
 
 type CreateEventParams struct {
@@ -119,6 +122,7 @@ type CreateEventParams struct {
 	EndTime          time.Time  `json:"end_time"`
 	TimeGranularity  string     `json:"time_granularity"`
 	CreatedBy        *uuid.UUID `json:"created_by"`
+	Timezone         string     `json:"timezone"`
 }
 
 func (q *Queries) CreateEvent(ctx context.Context, arg CreateEventParams) (Event, error) {
@@ -132,6 +136,7 @@ func (q *Queries) CreateEvent(ctx context.Context, arg CreateEventParams) (Event
 		arg.EndTime,
 		arg.TimeGranularity,
 		arg.CreatedBy,
+		arg.Timezone,
 	)
 	var i Event
 	err := row.Scan(
@@ -149,6 +154,7 @@ func (q *Queries) CreateEvent(ctx context.Context, arg CreateEventParams) (Event
 		&i.CreatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Timezone,
 	)
 	return i, err
 }
@@ -163,9 +169,10 @@ UPDATE events SET
     start_time = COALESCE($7, start_time),
     end_time = COALESCE($8, end_time),
     time_granularity = COALESCE($9, time_granularity),
+    timezone = COALESCE($10, timezone),
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, name, slug, description, location, participant_count, start_time, end_time, time_granularity, is_locked, is_public, created_by, created_at, updated_at
+RETURNING id, name, slug, description, location, participant_count, start_time, end_time, time_granularity, is_locked, is_public, created_by, created_at, updated_at, timezone
 `
 
 type UpdateEventParams struct {
@@ -178,6 +185,7 @@ type UpdateEventParams struct {
 	StartTime        *time.Time `json:"start_time"`
 	EndTime          *time.Time `json:"end_time"`
 	TimeGranularity  *string    `json:"time_granularity"`
+	Timezone         *string    `json:"timezone"`
 }
 
 func (q *Queries) UpdateEvent(ctx context.Context, arg UpdateEventParams) (Event, error) {
@@ -191,6 +199,7 @@ func (q *Queries) UpdateEvent(ctx context.Context, arg UpdateEventParams) (Event
 		arg.StartTime,
 		arg.EndTime,
 		arg.TimeGranularity,
+		arg.Timezone,
 	)
 	var i Event
 	err := row.Scan(
@@ -208,6 +217,7 @@ func (q *Queries) UpdateEvent(ctx context.Context, arg UpdateEventParams) (Event
 		&i.CreatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Timezone,
 	)
 	return i, err
 }
@@ -240,7 +250,7 @@ func (q *Queries) SetEventPublic(ctx context.Context, id uuid.UUID, isPublic boo
 }
 
 const listEventTeams = `-- name: ListEventTeams :many
-SELECT t.id, t.name, t.abbreviation, t.color, t.sort_order, t.is_active, t.created_at, et.is_visible
+SELECT t.id, t.name, t.abbreviation, t.color, t.sort_order, t.is_active, t.created_at, t.updated_at, et.is_visible
 FROM teams t
 JOIN event_teams et ON t.id = et.team_id
 WHERE et.event_id = $1
@@ -255,6 +265,7 @@ type ListEventTeamsRow struct {
 	SortOrder    int32     `json:"sort_order"`
 	IsActive     bool      `json:"is_active"`
 	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 	IsVisible    bool      `json:"is_visible"`
 }
 
@@ -275,6 +286,7 @@ func (q *Queries) ListEventTeams(ctx context.Context, eventID uuid.UUID) ([]List
 			&i.SortOrder,
 			&i.IsActive,
 			&i.CreatedAt,
+			&i.UpdatedAt,
 			&i.IsVisible,
 		); err != nil {
 			return nil, err
@@ -13,19 +13,20 @@ import (
 )
 
 const createICalToken = `-- name: CreateICalToken :one
-INSERT INTO ical_tokens (user_id, token_hash, token, label, scope, event_id, team_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, user_id, token_hash, token, label, scope, event_id, team_id, created_at, last_used_at, is_active
+INSERT INTO ical_tokens (user_id, token_hash, token, label, scope, event_id, team_id, alarm_minutes)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, user_id, token_hash, token, label, scope, event_id, team_id, created_at, last_used_at, is_active, alarm_minutes
 `
 
 type CreateICalTokenParams struct {
-	UserID    uuid.UUID  `json:"user_id"`
-	TokenHash string     `json:"token_hash"`
-	Token     string     `json:"token"`
-	Label     string     `json:"label"`
-	Scope     string     `json:"scope"`
-	EventID   *uuid.UUID `json:"event_id"`
-	TeamID    *uuid.UUID `json:"team_id"`
+	UserID       uuid.UUID  `json:"user_id"`
+	TokenHash    string     `json:"token_hash"`
+	Token        string     `json:"token"`
+	Label        string     `json:"label"`
+	Scope        string     `json:"scope"`
+	EventID      *uuid.UUID `json:"event_id"`
+	TeamID       *uuid.UUID `json:"team_id"`
+	AlarmMinutes *int32     `json:"alarm_minutes"`
 }
 
 func (q *Queries) CreateICalToken(ctx context.Context, arg CreateICalTokenParams) (IcalToken, error) {
@@ -37,6 +38,7 @@ func (q *Queries) CreateICalToken(ctx context.Context, arg CreateICalTokenParams
 		arg.Scope,
 		arg.EventID,
 		arg.TeamID,
+		arg.AlarmMinutes,
 	)
 	var i IcalToken
 	err := row.Scan(
@@ -51,12 +53,13 @@ func (q *Queries) CreateICalToken(ctx context.Context, arg CreateICalTokenParams
 		&i.CreatedAt,
 		&i.LastUsedAt,
 		&i.IsActive,
+		&i.AlarmMinutes,
 	)
 	return i, err
 }
 
 const listICalTokensByUser = `-- name: ListICalTokensByUser :many
-SELECT it.id, it.user_id, it.token_hash, it.token, it.label, it.scope, it.event_id, it.team_id, it.created_at, it.last_used_at, it.is_active,
+SELECT it.id, it.user_id, it.token_hash, it.token, it.label, it.scope, it.event_id, it.team_id, it.created_at, it.last_used_at, it.is_active, it.alarm_minutes,
        e.slug AS event_slug,
        t.abbreviation AS team_abbreviation
 FROM ical_tokens it
@@ -78,6 +81,7 @@ type ListICalTokensByUserRow struct {
 	CreatedAt        time.Time  `json:"created_at"`
 	LastUsedAt       *time.Time `json:"last_used_at"`
 	IsActive         bool       `json:"is_active"`
+	AlarmMinutes     *int32     `json:"alarm_minutes"`
 	EventSlug        *string    `json:"event_slug"`
 	TeamAbbreviation *string    `json:"team_abbreviation"`
 }
@@ -103,6 +107,7 @@ func (q *Queries) ListICalTokensByUser(ctx context.Context, userID uuid.UUID) ([
 			&i.CreatedAt,
 			&i.LastUsedAt,
 			&i.IsActive,
+			&i.AlarmMinutes,
 			&i.EventSlug,
 			&i.TeamAbbreviation,
 		); err != nil {
@@ -117,25 +122,26 @@ func (q *Queries) ListICalTokensByUser(ctx context.Context, userID uuid.UUID) ([
 }
 
 const getICalTokenByHash = `-- name: GetICalTokenByHash :one
-SELECT it.id, it.user_id, it.token_hash, it.token, it.label, it.scope, it.event_id, it.team_id, it.created_at, it.last_used_at, it.is_active, u.username
+SELECT it.id, it.user_id, it.token_hash, it.token, it.label, it.scope, it.event_id, it.team_id, it.created_at, it.last_used_at, it.is_active, it.alarm_minutes, u.username
 FROM ical_tokens it
 JOIN users u ON it.user_id = u.id
 WHERE it.token_hash = $1 AND it.is_active = true
 `
 
 type GetICalTokenByHashRow struct {
-	ID         uuid.UUID  `json:"id"`
-	UserID     uuid.UUID  `json:"user_id"`
-	TokenHash  string     `json:"token_hash"`
-	Token      string     `json:"token"`
-	Label      string     `json:"label"`
-	Scope      string     `json:"scope"`
-	EventID    *uuid.UUID `json:"event_id"`
-	TeamID     *uuid.UUID `json:"team_id"`
-	CreatedAt  time.Time  `json:"created_at"`
-	LastUsedAt *time.Time `json:"last_used_at"`
-	IsActive   bool       `json:"is_active"`
-	Username   string     `json:"username"`
+	ID           uuid.UUID  `json:"id"`
+	UserID       uuid.UUID  `json:"user_id"`
+	TokenHash    string     `json:"token_hash"`
+	Token        string     `json:"token"`
+	Label        string     `json:"label"`
+	Scope        string     `json:"scope"`
+	EventID      *uuid.UUID `json:"event_id"`
+	TeamID       *uuid.UUID `json:"team_id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastUsedAt   *time.Time `json:"last_used_at"`
+	IsActive     bool       `json:"is_active"`
+	AlarmMinutes *int32     `json:"alarm_minutes"`
+	Username     string     `json:"username"`
 }
 
 func (q *Queries) GetICalTokenByHash(ctx context.Context, tokenHash string) (GetICalTokenByHashRow, error) {
@@ -153,6 +159,7 @@ func (q *Queries) GetICalTokenByHash(ctx context.Context, tokenHash string) (Get
 		&i.CreatedAt,
 		&i.LastUsedAt,
 		&i.IsActive,
+		&i.AlarmMinutes,
 		&i.Username,
 	)
 	return i, err
@@ -175,3 +182,15 @@ func (q *Queries) UpdateICalTokenLastUsed(ctx context.Context, id uuid.UUID) err
 	_, err := q.db.Exec(ctx, updateICalTokenLastUsed, id)
 	return err
 }
+
+const updateICalTokenAlarm = `-- name: UpdateICalTokenAlarm :execrows
+UPDATE ical_tokens SET alarm_minutes = $3 WHERE id = $1 AND user_id = $2 AND is_active = true
+`
+
+func (q *Queries) UpdateICalTokenAlarm(ctx context.Context, id uuid.UUID, userID uuid.UUID, alarmMinutes *int32) (int64, error) {
+	result, err := q.db.Exec(ctx, updateICalTokenAlarm, id, userID, alarmMinutes)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -25,6 +25,7 @@ type User struct {
 	TotpEnabled  bool       `json:"totp_enabled"`
 	IsActive     bool       `json:"is_active"`
 	TimeFormat   string     `json:"time_format"`
+	Phone        *string    `json:"phone"`
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
 }
@@ -40,16 +41,21 @@ type Session struct {
 }
 
 type OauthProvider struct {
-	ID           uuid.UUID `json:"id"`
-	Name         string    `json:"name"`
-	ClientID     string    `json:"client_id"`
-	ClientSecret string    `json:"client_secret"`
-	AuthorizeUrl string    `json:"authorize_url"`
-	TokenUrl     string    `json:"token_url"`
-	UserinfoUrl  string    `json:"userinfo_url"`
-	Scopes       string    `json:"scopes"`
-	IsEnabled    bool      `json:"is_enabled"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID            uuid.UUID `json:"id"`
+	Name          string    `json:"name"`
+	ClientID      string    `json:"client_id"`
+	ClientSecret  string    `json:"client_secret"`
+	AuthorizeUrl  string    `json:"authorize_url"`
+	TokenUrl      string    `json:"token_url"`
+	UserinfoUrl   string    `json:"userinfo_url"`
+	Scopes        string    `json:"scopes"`
+	IsEnabled     bool      `json:"is_enabled"`
+	CreatedAt     time.Time `json:"created_at"`
+	ProviderType  string    `json:"provider_type"`
+	IssuerUrl     string    `json:"issuer_url"`
+	JwksUrl       string    `json:"jwks_url"`
+	EndSessionUrl string    `json:"end_session_url"`
+	RpLogout      bool      `json:"rp_logout"`
 }
 
 type OauthConnection struct {
@@ -77,6 +83,7 @@ type Team struct {
 	SortOrder    int32     `json:"sort_order"`
 	IsActive     bool      `json:"is_active"`
 	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
 
 type Event struct {
@@ -94,6 +101,7 @@ type Event struct {
 	CreatedBy        *uuid.UUID `json:"created_by"`
 	CreatedAt        time.Time  `json:"created_at"`
 	UpdatedAt        time.Time  `json:"updated_at"`
+	Timezone         string     `json:"timezone"`
 }
 
 type EventTeam struct {
@@ -124,6 +132,7 @@ type Shift struct {
 	CreatedBy *uuid.UUID `json:"created_by"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
+	Sequence  int32      `json:"sequence"`
 }
 
 type CoverageRequirement struct {
@@ -143,20 +152,49 @@ type UserAvailability struct {
 	EndTime   time.Time `json:"end_time"`
 	Status    string    `json:"status"`
 	Note      *string   `json:"note"`
+	Source    string    `json:"source"`
+}
+
+type AvailabilityIcalFeed struct {
+	EventID      uuid.UUID  `json:"event_id"`
+	UserID       uuid.UUID  `json:"user_id"`
+	Url          string     `json:"url"`
+	LastSyncedAt *time.Time `json:"last_synced_at"`
+	LastError    *string    `json:"last_error"`
+	CreatedAt    time.Time  `json:"created_at"`
 }
 
 type IcalToken struct {
-	ID         uuid.UUID  `json:"id"`
-	UserID     uuid.UUID  `json:"user_id"`
-	TokenHash  string     `json:"token_hash"`
-	Token      string     `json:"token"`
-	Label      string     `json:"label"`
-	Scope      string     `json:"scope"`
-	EventID    *uuid.UUID `json:"event_id"`
-	TeamID     *uuid.UUID `json:"team_id"`
-	CreatedAt  time.Time  `json:"created_at"`
-	LastUsedAt *time.Time `json:"last_used_at"`
-	IsActive   bool       `json:"is_active"`
+	ID           uuid.UUID  `json:"id"`
+	UserID       uuid.UUID  `json:"user_id"`
+	TokenHash    string     `json:"token_hash"`
+	Token        string     `json:"token"`
+	Label        string     `json:"label"`
+	Scope        string     `json:"scope"`
+	EventID      *uuid.UUID `json:"event_id"`
+	TeamID       *uuid.UUID `json:"team_id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastUsedAt   *time.Time `json:"last_used_at"`
+	IsActive     bool       `json:"is_active"`
+	AlarmMinutes *int32     `json:"alarm_minutes"`
+}
+
+type ShiftDeletion struct {
+	ShiftID   uuid.UUID `json:"shift_id"`
+	EventID   uuid.UUID `json:"event_id"`
+	TeamID    uuid.UUID `json:"team_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	CreatedAt time.Time `json:"created_at"`
+	DeletedAt time.Time `json:"deleted_at"`
+	Sequence  int32     `json:"sequence"`
+}
+
+type EventPlanVersion struct {
+	EventID   uuid.UUID `json:"event_id"`
+	Version   int64     `json:"version"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type Notification struct {
@@ -178,15 +216,26 @@ type NotificationPreference struct {
 }
 
 type WebhookConfig struct {
-	ID           uuid.UUID  `json:"id"`
-	EventID      *uuid.UUID `json:"event_id"`
-	Name         string     `json:"name"`
-	Url          string     `json:"url"`
-	Secret       string     `json:"secret"`
-	Format       string     `json:"format"`
-	TriggerTypes []string   `json:"trigger_types"`
-	IsEnabled    bool       `json:"is_enabled"`
-	CreatedAt    time.Time  `json:"created_at"`
+	ID                  uuid.UUID  `json:"id"`
+	EventID             *uuid.UUID `json:"event_id"`
+	Name                string     `json:"name"`
+	Url                 string     `json:"url"`
+	Secret              string     `json:"secret"`
+	Format              string     `json:"format"`
+	TriggerTypes        []string   `json:"trigger_types"`
+	IsEnabled           bool       `json:"is_enabled"`
+	CreatedAt           time.Time  `json:"created_at"`
+	ConsecutiveFailures int32           `json:"consecutive_failures"`
+	DisabledReason      *string         `json:"disabled_reason"`
+	Template            *string         `json:"template"`
+	ContentType         *string         `json:"content_type"`
+	Headers             json.RawMessage `json:"headers"`
+	PreviousSecret          *string    `json:"previous_secret"`
+	PreviousSecretExpiresAt *time.Time `json:"previous_secret_expires_at"`
+	FilterTeamIds           []uuid.UUID `json:"filter_team_ids"`
+	FilterUserIds           []uuid.UUID `json:"filter_user_ids"`
+	FilterWithinHours       *int32      `json:"filter_within_hours"`
+	UserID                  *uuid.UUID  `json:"user_id"`
 }
 
 type AuditLog struct {
@@ -219,3 +268,134 @@ type AppSetting struct {
 	Value     json.RawMessage `json:"value"`
 	UpdatedAt time.Time       `json:"updated_at"`
 }
+
+type ReportSubscription struct {
+	ID               uuid.UUID   `json:"id"`
+	EventID          uuid.UUID   `json:"event_id"`
+	Name             string      `json:"name"`
+	Format           string      `json:"format"`
+	PdfLayout        string      `json:"pdf_layout"`
+	TeamIds          []uuid.UUID `json:"team_ids"`
+	TimeWindow       string      `json:"time_window"`
+	RecipientUserIds []uuid.UUID `json:"recipient_user_ids"`
+	RecipientEmails  []string    `json:"recipient_emails"`
+	Frequency        string      `json:"frequency"`
+	Weekday          *int16      `json:"weekday"`
+	SendTime         string      `json:"send_time"`
+	IsEnabled        bool        `json:"is_enabled"`
+	NextRunAt        time.Time   `json:"next_run_at"`
+	LastRunAt        *time.Time  `json:"last_run_at"`
+	LastError        *string     `json:"last_error"`
+	CreatedBy        *uuid.UUID  `json:"created_by"`
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+}
+
+type EmailOutbox struct {
+	ID            uuid.UUID  `json:"id"`
+	UserID        *uuid.UUID `json:"user_id"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject"`
+	HtmlBody      string     `json:"html_body"`
+	TextBody      string     `json:"text_body"`
+	Status        string     `json:"status"`
+	Attempts      int32      `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     *string    `json:"last_error"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type NotificationDeliveryMode struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Channel string    `json:"channel"`
+	Mode    string    `json:"mode"`
+}
+
+type PendingNotification struct {
+	ID        uuid.UUID       `json:"id"`
+	UserID    uuid.UUID       `json:"user_id"`
+	EventID   *uuid.UUID      `json:"event_id"`
+	Channel   string          `json:"channel"`
+	Mode      string          `json:"mode"`
+	Message   json.RawMessage `json:"message"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type Announcement struct {
+	ID             uuid.UUID   `json:"id"`
+	EventID        uuid.UUID   `json:"event_id"`
+	Title          string      `json:"title"`
+	Body           string      `json:"body"`
+	TeamIds        []uuid.UUID `json:"team_ids"`
+	WindowStart    *time.Time  `json:"window_start"`
+	WindowEnd      *time.Time  `json:"window_end"`
+	RecipientCount int32       `json:"recipient_count"`
+	CreatedBy      *uuid.UUID  `json:"created_by"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+type EventNotificationPreference struct {
+	UserID      uuid.UUID `json:"user_id"`
+	EventID     uuid.UUID `json:"event_id"`
+	TriggerType string    `json:"trigger_type"`
+	Channel     string    `json:"channel"`
+	IsEnabled   bool      `json:"is_enabled"`
+}
+
+type VapidKey struct {
+	ID         int16     `json:"id"`
+	PublicKey  string    `json:"public_key"`
+	PrivateKey string    `json:"private_key"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type PushSubscription struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Endpoint   string     `json:"endpoint"`
+	P256dh     string     `json:"p256dh"`
+	Auth       string     `json:"auth"`
+	UserAgent  *string    `json:"user_agent"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID            uuid.UUID  `json:"id"`
+	WebhookID     uuid.UUID  `json:"webhook_id"`
+	TriggerType   string     `json:"trigger_type"`
+	Payload       string     `json:"payload"`
+	Status        string     `json:"status"`
+	Attempts      int32      `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	RedeliveryOf  *uuid.UUID `json:"redelivery_of"`
+	DeliveredAt   *time.Time `json:"delivered_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type WebhookDeliveryAttempt struct {
+	ID             uuid.UUID `json:"id"`
+	DeliveryID     uuid.UUID `json:"delivery_id"`
+	Attempt        int32     `json:"attempt"`
+	ResponseStatus *int32    `json:"response_status"`
+	DurationMs     int32     `json:"duration_ms"`
+	Error          *string   `json:"error"`
+	ResponseBody   *string   `json:"response_body"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type ApiToken struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Name        string     `json:"name"`
+	TokenHash   string     `json:"token_hash"`
+	TokenPrefix string     `json:"token_prefix"`
+	Scopes      []string   `json:"scopes"`
+	EventID     *uuid.UUID `json:"event_id"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	LastUsedIp  *string    `json:"last_used_ip"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -7,6 +7,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -183,3 +184,285 @@ func (q *Queries) UpsertNotificationPreference(ctx context.Context, arg UpsertNo
 	)
 	return err
 }
+
+const getNotificationDeliveryModes = `-- name: GetNotificationDeliveryModes :many
+SELECT user_id, channel, mode FROM notification_delivery_modes WHERE user_id = $1
+`
+
+func (q *Queries) GetNotificationDeliveryModes(ctx context.Context, userID uuid.UUID) ([]NotificationDeliveryMode, error) {
+	rows, err := q.db.Query(ctx, getNotificationDeliveryModes, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []NotificationDeliveryMode{}
+	for rows.Next() {
+		var i NotificationDeliveryMode
+		if err := rows.Scan(&i.UserID, &i.Channel, &i.Mode); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertNotificationDeliveryMode = `-- name: UpsertNotificationDeliveryMode :exec
+INSERT INTO notification_delivery_modes (user_id, channel, mode)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, channel)
+DO UPDATE SET mode = EXCLUDED.mode
+`
+
+func (q *Queries) UpsertNotificationDeliveryMode(ctx context.Context, userID uuid.UUID, channel, mode string) error {
+	_, err := q.db.Exec(ctx, upsertNotificationDeliveryMode, userID, channel, mode)
+	return err
+}
+
+const createPendingNotification = `-- name: CreatePendingNotification :exec
+INSERT INTO pending_notifications (user_id, event_id, channel, mode, message)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+type CreatePendingNotificationParams struct {
+	UserID  uuid.UUID       `json:"user_id"`
+	EventID *uuid.UUID      `json:"event_id"`
+	Channel string          `json:"channel"`
+	Mode    string          `json:"mode"`
+	Message json.RawMessage `json:"message"`
+}
+
+func (q *Queries) CreatePendingNotification(ctx context.Context, arg CreatePendingNotificationParams) error {
+	_, err := q.db.Exec(ctx, createPendingNotification,
+		arg.UserID,
+		arg.EventID,
+		arg.Channel,
+		arg.Mode,
+		arg.Message,
+	)
+	return err
+}
+
+const listPendingNotifications = `-- name: ListPendingNotifications :many
+SELECT id, user_id, event_id, channel, mode, message, created_at FROM pending_notifications ORDER BY created_at
+`
+
+func (q *Queries) ListPendingNotifications(ctx context.Context) ([]PendingNotification, error) {
+	rows, err := q.db.Query(ctx, listPendingNotifications)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []PendingNotification{}
+	for rows.Next() {
+		var i PendingNotification
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.EventID,
+			&i.Channel,
+			&i.Mode,
+			&i.Message,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deletePendingNotifications = `-- name: DeletePendingNotifications :many
+DELETE FROM pending_notifications WHERE id = ANY($1::UUID[])
+RETURNING id
+`
+
+func (q *Queries) DeletePendingNotifications(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, deletePendingNotifications, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEventNotificationState = `-- name: GetEventNotificationState :one
+SELECT state FROM event_notification_settings WHERE user_id = $1 AND event_id = $2
+`
+
+func (q *Queries) GetEventNotificationState(ctx context.Context, userID, eventID uuid.UUID) (string, error) {
+	row := q.db.QueryRow(ctx, getEventNotificationState, userID, eventID)
+	var state string
+	err := row.Scan(&state)
+	return state, err
+}
+
+const upsertEventNotificationState = `-- name: UpsertEventNotificationState :exec
+INSERT INTO event_notification_settings (user_id, event_id, state)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, event_id)
+DO UPDATE SET state = EXCLUDED.state
+`
+
+func (q *Queries) UpsertEventNotificationState(ctx context.Context, userID, eventID uuid.UUID, state string) error {
+	_, err := q.db.Exec(ctx, upsertEventNotificationState, userID, eventID, state)
+	return err
+}
+
+const deleteEventNotificationState = `-- name: DeleteEventNotificationState :exec
+DELETE FROM event_notification_settings WHERE user_id = $1 AND event_id = $2
+`
+
+func (q *Queries) DeleteEventNotificationState(ctx context.Context, userID, eventID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteEventNotificationState, userID, eventID)
+	return err
+}
+
+const listEventNotificationStates = `-- name: ListEventNotificationStates :many
+SELECT s.event_id, e.slug AS event_slug, e.name AS event_name, s.state, s.created_at
+FROM event_notification_settings s
+JOIN events e ON e.id = s.event_id
+WHERE s.user_id = $1
+ORDER BY e.start_time DESC
+`
+
+type ListEventNotificationStatesRow struct {
+	EventID   uuid.UUID `json:"event_id"`
+	EventSlug string    `json:"event_slug"`
+	EventName string    `json:"event_name"`
+	State     string    `json:"state"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) ListEventNotificationStates(ctx context.Context, userID uuid.UUID) ([]ListEventNotificationStatesRow, error) {
+	rows, err := q.db.Query(ctx, listEventNotificationStates, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListEventNotificationStatesRow{}
+	for rows.Next() {
+		var i ListEventNotificationStatesRow
+		if err := rows.Scan(
+			&i.EventID,
+			&i.EventSlug,
+			&i.EventName,
+			&i.State,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEventWatchers = `-- name: ListEventWatchers :many
+SELECT user_id FROM event_notification_settings
+WHERE event_id = $1 AND state = 'watching'
+`
+
+func (q *Queries) ListEventWatchers(ctx context.Context, eventID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listEventWatchers, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		items = append(items, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEventNotificationPreferences = `-- name: GetEventNotificationPreferences :many
+SELECT user_id, event_id, trigger_type, channel, is_enabled FROM event_notification_preferences WHERE user_id = $1 AND event_id = $2
+`
+
+func (q *Queries) GetEventNotificationPreferences(ctx context.Context, userID, eventID uuid.UUID) ([]EventNotificationPreference, error) {
+	rows, err := q.db.Query(ctx, getEventNotificationPreferences, userID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []EventNotificationPreference{}
+	for rows.Next() {
+		var i EventNotificationPreference
+		if err := rows.Scan(
+			&i.UserID,
+			&i.EventID,
+			&i.TriggerType,
+			&i.Channel,
+			&i.IsEnabled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertEventNotificationPreference = `-- name: UpsertEventNotificationPreference :exec
+INSERT INTO event_notification_preferences (user_id, event_id, trigger_type, channel, is_enabled)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, event_id, trigger_type, channel)
+DO UPDATE SET is_enabled = EXCLUDED.is_enabled
+`
+
+type UpsertEventNotificationPreferenceParams struct {
+	UserID      uuid.UUID `json:"user_id"`
+	EventID     uuid.UUID `json:"event_id"`
+	TriggerType string    `json:"trigger_type"`
+	Channel     string    `json:"channel"`
+	IsEnabled   bool      `json:"is_enabled"`
+}
+
+func (q *Queries) UpsertEventNotificationPreference(ctx context.Context, arg UpsertEventNotificationPreferenceParams) error {
+	_, err := q.db.Exec(ctx, upsertEventNotificationPreference,
+		arg.UserID,
+		arg.EventID,
+		arg.TriggerType,
+		arg.Channel,
+		arg.IsEnabled,
+	)
+	return err
+}
+
+const deleteEventNotificationPreference = `-- name: DeleteEventNotificationPreference :exec
+DELETE FROM event_notification_preferences
+WHERE user_id = $1 AND event_id = $2 AND trigger_type = $3 AND channel = $4
+`
+
+func (q *Queries) DeleteEventNotificationPreference(ctx context.Context, userID, eventID uuid.UUID, triggerType, channel string) error {
+	_, err := q.db.Exec(ctx, deleteEventNotificationPreference, userID, eventID, triggerType, channel)
+	return err
+}
@@ -13,7 +13,7 @@ import (
 )
 
 const listOAuthProviders = `-- name: ListOAuthProviders :many
-SELECT id, name, client_id, client_secret, authorize_url, token_url, userinfo_url, scopes, is_enabled, created_at FROM oauth_providers ORDER BY name
+SELECT id, name, client_id, client_secret, authorize_url, token_url, userinfo_url, scopes, is_enabled, created_at, provider_type, issuer_url, jwks_url, end_session_url, rp_logout FROM oauth_providers ORDER BY name
 `
 
 func (q *Queries) ListOAuthProviders(ctx context.Context) ([]OauthProvider, error) {
@@ -36,6 +36,11 @@ func (q *Queries) ListOAuthProviders(ctx context.Context) ([]OauthProvider, erro
 			&i.Scopes,
 			&i.IsEnabled,
 			&i.CreatedAt,
+			&i.ProviderType,
+			&i.IssuerUrl,
+			&i.JwksUrl,
+			&i.EndSessionUrl,
+			&i.RpLogout,
 		); err != nil {
 			return nil, err
 		}
@@ -80,7 +85,7 @@ func (q *Queries) ListEnabledOAuthProviders(ctx context.Context) ([]ListEnabledO
 }
 
 const getOAuthProviderByID = `-- name: GetOAuthProviderByID :one
-SELECT id, name, client_id, client_secret, authorize_url, token_url, userinfo_url, scopes, is_enabled, created_at FROM oauth_providers WHERE id = $1
+SELECT id, name, client_id, client_secret, authorize_url, token_url, userinfo_url, scopes, is_enabled, created_at, provider_type, issuer_url, jwks_url, end_session_url, rp_logout FROM oauth_providers WHERE id = $1
 `
 
 func (q *Queries) GetOAuthProviderByID(ctx context.Context, id uuid.UUID) (OauthProvider, error) {
@@ -97,12 +102,17 @@ func (q *Queries) GetOAuthProviderByID(ctx context.Context, id uuid.UUID) (Oauth
 		&i.Scopes,
 		&i.IsEnabled,
 		&i.CreatedAt,
+		&i.ProviderType,
+		&i.IssuerUrl,
+		&i.JwksUrl,
+		&i.EndSessionUrl,
+		&i.RpLogout,
 	)
 	return i, err
 }
 
 const getOAuthProviderByName = `-- name: GetOAuthProviderByName :one
-SELECT id, name, client_id, client_secret, authorize_url, token_url, userinfo_url, scopes, is_enabled, created_at FROM oauth_providers WHERE name = $1
+SELECT id, name, client_id, client_secret, authorize_url, token_url, userinfo_url, scopes, is_enabled, created_at, provider_type, issuer_url, jwks_url, end_session_url, rp_logout FROM oauth_providers WHERE name = $1
 `
 
 func (q *Queries) GetOAuthProviderByName(ctx context.Context, name string) (OauthProvider, error) {
@@ -119,24 +129,34 @@ func (q *Queries) GetOAuthProviderByName(ctx context.Context, name string) (Oaut
 		&i.Scopes,
 		&i.IsEnabled,
 		&i.CreatedAt,
+		&i.ProviderType,
+		&i.IssuerUrl,
+		&i.JwksUrl,
+		&i.EndSessionUrl,
+		&i.RpLogout,
 	)
 	return i, err
 }
 
 const createOAuthProvider = `-- name: CreateOAuthProvider :one
-INSERT INTO oauth_providers (name, client_id, client_secret, authorize_url, token_url, userinfo_url, scopes)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, name, client_id, client_secret, authorize_url, token_url, userinfo_url, scopes, is_enabled, created_at
+INSERT INTO oauth_providers (name, client_id, client_secret, authorize_url, token_url, userinfo_url, scopes, provider_type, issuer_url, jwks_url, end_session_url, rp_logout)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING id, name, client_id, client_secret, authorize_url, token_url, userinfo_url, scopes, is_enabled, created_at, provider_type, issuer_url, jwks_url, end_session_url, rp_logout
 `
 
 type CreateOAuthProviderParams struct {
-	Name         string `json:"name"`
-	ClientID     string `json:"client_id"`
-	ClientSecret string `json:"client_secret"`
-	AuthorizeUrl string `json:"authorize_url"`
-	TokenUrl     string `json:"token_url"`
-	UserinfoUrl  string `json:"userinfo_url"`
-	Scopes       string `json:"scopes"`
+	Name          string `json:"name"`
+	ClientID      string `json:"client_id"`
+	ClientSecret  string `json:"client_secret"`
+	AuthorizeUrl  string `json:"authorize_url"`
+	TokenUrl      string `json:"token_url"`
+	UserinfoUrl   string `json:"userinfo_url"`
+	Scopes        string `json:"scopes"`
+	ProviderType  string `json:"provider_type"`
+	IssuerUrl     string `json:"issuer_url"`
+	JwksUrl       string `json:"jwks_url"`
+	EndSessionUrl string `json:"end_session_url"`
+	RpLogout      bool   `json:"rp_logout"`
 }
 
 func (q *Queries) CreateOAuthProvider(ctx context.Context, arg CreateOAuthProviderParams) (OauthProvider, error) {
@@ -148,6 +168,11 @@ func (q *Queries) CreateOAuthProvider(ctx context.Context, arg CreateOAuthProvid
 		arg.TokenUrl,
 		arg.UserinfoUrl,
 		arg.Scopes,
+		arg.ProviderType,
+		arg.IssuerUrl,
+		arg.JwksUrl,
+		arg.EndSessionUrl,
+		arg.RpLogout,
 	)
 	var i OauthProvider
 	err := row.Scan(
@@ -161,6 +186,11 @@ func (q *Queries) CreateOAuthProvider(ctx context.Context, arg CreateOAuthProvid
 		&i.Scopes,
 		&i.IsEnabled,
 		&i.CreatedAt,
+		&i.ProviderType,
+		&i.IssuerUrl,
+		&i.JwksUrl,
+		&i.EndSessionUrl,
+		&i.RpLogout,
 	)
 	return i, err
 }
@@ -174,21 +204,31 @@ UPDATE oauth_providers SET
     token_url = COALESCE($6, token_url),
     userinfo_url = COALESCE($7, userinfo_url),
     scopes = COALESCE($8, scopes),
-    is_enabled = COALESCE($9, is_enabled)
+    is_enabled = COALESCE($9, is_enabled),
+    provider_type = COALESCE($10, provider_type),
+    issuer_url = COALESCE($11, issuer_url),
+    jwks_url = COALESCE($12, jwks_url),
+    end_session_url = COALESCE($13, end_session_url),
+    rp_logout = COALESCE($14, rp_logout)
 WHERE id = $1
-RETURNING id, name, client_id, client_secret, authorize_url, token_url, userinfo_url, scopes, is_enabled, created_at
+RETURNING id, name, client_id, client_secret, authorize_url, token_url, userinfo_url, scopes, is_enabled, created_at, provider_type, issuer_url, jwks_url, end_session_url, rp_logout
 `
 
 type UpdateOAuthProviderParams struct {
-	ID           uuid.UUID `json:"id"`
-	Name         *string   `json:"name"`
-	ClientID     *string   `json:"client_id"`
-	ClientSecret *string   `json:"client_secret"`
-	AuthorizeUrl *string   `json:"authorize_url"`
-	TokenUrl     *string   `json:"token_url"`
-	UserinfoUrl  *string   `json:"userinfo_url"`
-	Scopes       *string   `json:"scopes"`
-	IsEnabled    *bool     `json:"is_enabled"`
+	ID            uuid.UUID `json:"id"`
+	Name          *string   `json:"name"`
+	ClientID      *string   `json:"client_id"`
+	ClientSecret  *string   `json:"client_secret"`
+	AuthorizeUrl  *string   `json:"authorize_url"`
+	TokenUrl      *string   `json:"token_url"`
+	UserinfoUrl   *string   `json:"userinfo_url"`
+	Scopes        *string   `json:"scopes"`
+	IsEnabled     *bool     `json:"is_enabled"`
+	ProviderType  *string   `json:"provider_type"`
+	IssuerUrl     *string   `json:"issuer_url"`
+	JwksUrl       *string   `json:"jwks_url"`
+	EndSessionUrl *string   `json:"end_session_url"`
+	RpLogout      *bool     `json:"rp_logout"`
 }
 
 func (q *Queries) UpdateOAuthProvider(ctx context.Context, arg UpdateOAuthProviderParams) (OauthProvider, error) {
@@ -202,6 +242,11 @@ func (q *Queries) UpdateOAuthProvider(ctx context.Context, arg UpdateOAuthProvid
 		arg.UserinfoUrl,
 		arg.Scopes,
 		arg.IsEnabled,
+		arg.ProviderType,
+		arg.IssuerUrl,
+		arg.JwksUrl,
+		arg.EndSessionUrl,
+		arg.RpLogout,
 	)
 	var i OauthProvider
 	err := row.Scan(
@@ -215,6 +260,11 @@ func (q *Queries) UpdateOAuthProvider(ctx context.Context, arg UpdateOAuthProvid
 		&i.Scopes,
 		&i.IsEnabled,
 		&i.CreatedAt,
+		&i.ProviderType,
+		&i.IssuerUrl,
+		&i.JwksUrl,
+		&i.EndSessionUrl,
+		&i.RpLogout,
 	)
 	return i, err
 }
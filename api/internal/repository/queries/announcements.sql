@@ -0,0 +1,15 @@
+-- name: ListAnnouncementsByEvent :many
+SELECT * FROM announcements WHERE event_id = $1 ORDER BY created_at DESC;
+
+-- name: ListPublicAnnouncementsByEvent :many
+SELECT * FROM announcements
+WHERE event_id = $1 AND team_ids = '{}' AND window_start IS NULL
+ORDER BY created_at DESC;
+
+-- name: CreateAnnouncement :one
+INSERT INTO announcements (event_id, title, body, team_ids, window_start, window_end, recipient_count, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: DeleteAnnouncement :execrows
+DELETE FROM announcements WHERE id = $1 AND event_id = $2;
@@ -0,0 +1,31 @@
+-- name: CreateAPIToken :one
+INSERT INTO api_tokens (user_id, name, token_hash, token_prefix, scopes, event_id, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: ListAPITokens :many
+SELECT t.*, e.slug AS event_slug, u.username
+FROM api_tokens t
+JOIN users u ON t.user_id = u.id
+LEFT JOIN events e ON t.event_id = e.id
+WHERE ($1::uuid IS NULL OR t.user_id = $1)
+ORDER BY t.created_at DESC;
+
+-- name: CountAPITokensByUser :one
+SELECT COUNT(*) FROM api_tokens WHERE user_id = $1;
+
+-- name: GetAPITokenByHash :one
+SELECT t.*, e.slug AS event_slug, u.username, u.role, u.is_active
+FROM api_tokens t
+JOIN users u ON t.user_id = u.id
+LEFT JOIN events e ON t.event_id = e.id
+WHERE t.token_hash = $1 AND (t.expires_at IS NULL OR t.expires_at > NOW());
+
+-- name: MarkAPITokenUsed :exec
+UPDATE api_tokens SET last_used_at = NOW(), last_used_ip = $2 WHERE id = $1;
+
+-- name: DeleteAPIToken :execrows
+DELETE FROM api_tokens WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteAPITokenByID :execrows
+DELETE FROM api_tokens WHERE id = $1;
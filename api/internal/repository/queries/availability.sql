@@ -11,9 +11,39 @@ WHERE event_id = $1 AND user_id = $2
 ORDER BY start_time;
 
 -- name: CreateAvailability :one
-INSERT INTO user_availability (event_id, user_id, start_time, end_time, status, note)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO user_availability (event_id, user_id, start_time, end_time, status, note, source)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING *;
 
 -- name: DeleteAvailabilityByEventAndUser :exec
-DELETE FROM user_availability WHERE event_id = $1 AND user_id = $2;
+DELETE FROM user_availability WHERE event_id = $1 AND user_id = $2 AND source = $3;
+
+-- name: LockUserAvailability :exec
+-- Serializes changes to a user's availability for an event until the end of
+-- the transaction.
+SELECT pg_advisory_xact_lock(hashtextextended($1::text || '/' || $2::text, 0));
+
+-- name: GetAvailabilityICalFeed :one
+SELECT * FROM availability_ical_feeds
+WHERE event_id = $1 AND user_id = $2;
+
+-- name: UpsertAvailabilityICalFeed :one
+INSERT INTO availability_ical_feeds (event_id, user_id, url)
+VALUES ($1, $2, $3)
+ON CONFLICT (event_id, user_id)
+DO UPDATE SET url = EXCLUDED.url, last_synced_at = NULL, last_error = NULL
+RETURNING *;
+
+-- name: UpdateAvailabilityICalFeedSync :exec
+UPDATE availability_ical_feeds SET last_synced_at = NOW(), last_error = $3
+WHERE event_id = $1 AND user_id = $2;
+
+-- name: DeleteAvailabilityICalFeed :exec
+DELETE FROM availability_ical_feeds WHERE event_id = $1 AND user_id = $2;
+
+-- name: ListAvailabilityICalFeedsDue :many
+SELECT f.*
+FROM availability_ical_feeds f
+JOIN events e ON f.event_id = e.id
+WHERE e.end_time > NOW() AND (f.last_synced_at IS NULL OR f.last_synced_at < $1)
+ORDER BY f.last_synced_at NULLS FIRST;
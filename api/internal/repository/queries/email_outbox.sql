@@ -0,0 +1,31 @@
+-- name: EnqueueEmail :exec
+INSERT INTO email_outbox (user_id, recipient, subject, html_body, text_body)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ClaimDueEmails :many
+-- Leases a batch of due messages by pushing next_attempt_at past the lease, so
+-- concurrent workers skip them. SKIP LOCKED keeps workers from blocking.
+UPDATE email_outbox SET next_attempt_at = $2
+WHERE id IN (
+    SELECT id FROM email_outbox
+    WHERE status = 'pending' AND next_attempt_at <= $1
+    ORDER BY next_attempt_at
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkEmailSent :exec
+UPDATE email_outbox SET status = 'sent', sent_at = NOW(), attempts = attempts + 1, last_error = NULL
+WHERE id = $1;
+
+-- name: MarkEmailAttemptFailed :exec
+UPDATE email_outbox SET
+    attempts = attempts + 1,
+    last_error = $2,
+    next_attempt_at = $3,
+    status = CASE WHEN $4::BOOLEAN THEN 'failed' ELSE 'pending' END
+WHERE id = $1;
+
+-- name: DeleteOldEmails :execrows
+DELETE FROM email_outbox WHERE status <> 'pending' AND created_at < $1;
@@ -8,8 +8,8 @@ SELECT * FROM events WHERE slug = $1;
 SELECT * FROM events ORDER BY start_time DESC;
 
 -- name: CreateEvent :one
-INSERT INTO events (name, slug, description, location, participant_count, start_time, end_time, time_granularity, created_by)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+INSERT INTO events (name, slug, description, location, participant_count, start_time, end_time, time_granularity, created_by, timezone)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 RETURNING *;
 
 -- name: UpdateEvent :one
@@ -22,6 +22,7 @@ UPDATE events SET
     start_time = COALESCE(sqlc.narg('start_time'), start_time),
     end_time = COALESCE(sqlc.narg('end_time'), end_time),
     time_granularity = COALESCE(sqlc.narg('time_granularity'), time_granularity),
+    timezone = COALESCE(sqlc.narg('timezone'), timezone),
     updated_at = NOW()
 WHERE id = $1
 RETURNING *;
@@ -1,6 +1,6 @@
 -- name: CreateICalToken :one
-INSERT INTO ical_tokens (user_id, token_hash, token, label, scope, event_id, team_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO ical_tokens (user_id, token_hash, token, label, scope, event_id, team_id, alarm_minutes)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING *;
 
 -- name: ListICalTokensByUser :many
@@ -24,3 +24,6 @@ UPDATE ical_tokens SET is_active = false WHERE id = $1 AND user_id = $2;
 
 -- name: UpdateICalTokenLastUsed :exec
 UPDATE ical_tokens SET last_used_at = NOW() WHERE id = $1;
+
+-- name: UpdateICalTokenAlarm :execrows
+UPDATE ical_tokens SET alarm_minutes = $3 WHERE id = $1 AND user_id = $2 AND is_active = true;
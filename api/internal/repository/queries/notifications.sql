@@ -29,3 +29,59 @@ DO UPDATE SET is_enabled = EXCLUDED.is_enabled;
 
 -- name: DeleteOldNotifications :execrows
 DELETE FROM notifications WHERE created_at < $1 AND is_read = true;
+
+-- name: GetNotificationDeliveryModes :many
+SELECT * FROM notification_delivery_modes WHERE user_id = $1;
+
+-- name: UpsertNotificationDeliveryMode :exec
+INSERT INTO notification_delivery_modes (user_id, channel, mode)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, channel)
+DO UPDATE SET mode = EXCLUDED.mode;
+
+-- name: CreatePendingNotification :exec
+INSERT INTO pending_notifications (user_id, event_id, channel, mode, message)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ListPendingNotifications :many
+SELECT * FROM pending_notifications ORDER BY created_at;
+
+-- name: DeletePendingNotifications :many
+DELETE FROM pending_notifications WHERE id = ANY($1::UUID[])
+RETURNING id;
+
+-- name: GetEventNotificationState :one
+SELECT state FROM event_notification_settings WHERE user_id = $1 AND event_id = $2;
+
+-- name: UpsertEventNotificationState :exec
+INSERT INTO event_notification_settings (user_id, event_id, state)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, event_id)
+DO UPDATE SET state = EXCLUDED.state;
+
+-- name: DeleteEventNotificationState :exec
+DELETE FROM event_notification_settings WHERE user_id = $1 AND event_id = $2;
+
+-- name: ListEventNotificationStates :many
+SELECT s.event_id, e.slug AS event_slug, e.name AS event_name, s.state, s.created_at
+FROM event_notification_settings s
+JOIN events e ON e.id = s.event_id
+WHERE s.user_id = $1
+ORDER BY e.start_time DESC;
+
+-- name: ListEventWatchers :many
+SELECT user_id FROM event_notification_settings
+WHERE event_id = $1 AND state = 'watching';
+
+-- name: GetEventNotificationPreferences :many
+SELECT * FROM event_notification_preferences WHERE user_id = $1 AND event_id = $2;
+
+-- name: UpsertEventNotificationPreference :exec
+INSERT INTO event_notification_preferences (user_id, event_id, trigger_type, channel, is_enabled)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, event_id, trigger_type, channel)
+DO UPDATE SET is_enabled = EXCLUDED.is_enabled;
+
+-- name: DeleteEventNotificationPreference :exec
+DELETE FROM event_notification_preferences
+WHERE user_id = $1 AND event_id = $2 AND trigger_type = $3 AND channel = $4;
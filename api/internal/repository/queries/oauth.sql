@@ -11,8 +11,8 @@ SELECT * FROM oauth_providers WHERE id = $1;
 SELECT * FROM oauth_providers WHERE name = $1;
 
 -- name: CreateOAuthProvider :one
-INSERT INTO oauth_providers (name, client_id, client_secret, authorize_url, token_url, userinfo_url, scopes)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO oauth_providers (name, client_id, client_secret, authorize_url, token_url, userinfo_url, scopes, provider_type, issuer_url, jwks_url, end_session_url, rp_logout)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 RETURNING *;
 
 -- name: UpdateOAuthProvider :one
@@ -24,7 +24,12 @@ UPDATE oauth_providers SET
     token_url = COALESCE(sqlc.narg('token_url'), token_url),
     userinfo_url = COALESCE(sqlc.narg('userinfo_url'), userinfo_url),
     scopes = COALESCE(sqlc.narg('scopes'), scopes),
-    is_enabled = COALESCE(sqlc.narg('is_enabled'), is_enabled)
+    is_enabled = COALESCE(sqlc.narg('is_enabled'), is_enabled),
+    provider_type = COALESCE(sqlc.narg('provider_type'), provider_type),
+    issuer_url = COALESCE(sqlc.narg('issuer_url'), issuer_url),
+    jwks_url = COALESCE(sqlc.narg('jwks_url'), jwks_url),
+    end_session_url = COALESCE(sqlc.narg('end_session_url'), end_session_url),
+    rp_logout = COALESCE(sqlc.narg('rp_logout'), rp_logout)
 WHERE id = $1
 RETURNING *;
 
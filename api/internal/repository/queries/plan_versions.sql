@@ -0,0 +1,36 @@
+-- name: GetEventPlanVersion :one
+SELECT COALESCE((SELECT version FROM event_plan_versions WHERE event_id = $1), 0)::BIGINT AS version;
+
+-- name: BumpEventPlanVersion :exec
+INSERT INTO event_plan_versions (event_id, version, updated_at)
+VALUES ($1, 1, NOW())
+ON CONFLICT (event_id) DO UPDATE SET version = event_plan_versions.version + 1, updated_at = NOW();
+
+-- name: ListICalFeedPlanStates :many
+-- Returns the update time and plan version of the events an iCal feed shows:
+-- the token's event, or the events a user has or recently had shifts in.
+-- names_updated_at covers the users and teams the feed names, and the
+-- tombstone count and oldest deletion move when cancellations expire.
+SELECT e.id, e.updated_at, COALESCE(v.version, 0)::BIGINT AS version,
+       GREATEST(
+           (SELECT MAX(u.updated_at) FROM users u
+            WHERE u.id IN (SELECT user_id FROM shifts WHERE event_id = e.id)
+               OR u.id IN (SELECT user_id FROM shift_deletions WHERE event_id = e.id AND deleted_at > sqlc.arg('deleted_after'))),
+           (SELECT MAX(t.updated_at) FROM teams t
+            WHERE t.id IN (SELECT team_id FROM event_teams WHERE event_id = e.id)
+               OR t.id IN (SELECT team_id FROM shifts WHERE event_id = e.id)
+               OR t.id IN (SELECT team_id FROM shift_deletions WHERE event_id = e.id AND deleted_at > sqlc.arg('deleted_after')))
+       )::TIMESTAMPTZ AS names_updated_at,
+       d.tombstones, d.oldest_tombstone::TIMESTAMPTZ AS oldest_tombstone
+FROM events e
+LEFT JOIN event_plan_versions v ON v.event_id = e.id
+CROSS JOIN LATERAL (
+    SELECT COUNT(*) AS tombstones, MIN(deleted_at) AS oldest_tombstone
+    FROM shift_deletions
+    WHERE event_id = e.id AND deleted_at > sqlc.arg('deleted_after')
+      AND (sqlc.narg('user_id')::UUID IS NULL OR user_id = sqlc.narg('user_id'))
+) d
+WHERE e.id = sqlc.narg('event_id')
+   OR e.id IN (SELECT event_id FROM shifts WHERE user_id = sqlc.narg('user_id'))
+   OR e.id IN (SELECT event_id FROM shift_deletions WHERE user_id = sqlc.narg('user_id') AND deleted_at > sqlc.arg('deleted_after'))
+ORDER BY e.id;
@@ -0,0 +1,35 @@
+-- name: GetVAPIDKeys :one
+SELECT * FROM vapid_keys WHERE id = 1;
+
+-- name: InsertVAPIDKeys :exec
+INSERT INTO vapid_keys (id, public_key, private_key)
+VALUES (1, $1, $2)
+ON CONFLICT (id) DO NOTHING;
+
+-- name: ListPushSubscriptionsByUser :many
+SELECT * FROM push_subscriptions
+WHERE user_id = $1 AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY created_at;
+
+-- name: UpsertPushSubscription :one
+INSERT INTO push_subscriptions (user_id, endpoint, p256dh, auth, user_agent, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (endpoint) DO UPDATE SET
+    user_id = EXCLUDED.user_id,
+    p256dh = EXCLUDED.p256dh,
+    auth = EXCLUDED.auth,
+    user_agent = EXCLUDED.user_agent,
+    expires_at = EXCLUDED.expires_at
+RETURNING *;
+
+-- name: DeletePushSubscription :execrows
+DELETE FROM push_subscriptions WHERE id = $1 AND user_id = $2;
+
+-- name: DeletePushSubscriptionByID :exec
+DELETE FROM push_subscriptions WHERE id = $1;
+
+-- name: MarkPushSubscriptionUsed :exec
+UPDATE push_subscriptions SET last_used_at = NOW() WHERE id = $1;
+
+-- name: DeleteExpiredPushSubscriptions :execrows
+DELETE FROM push_subscriptions WHERE expires_at < NOW();
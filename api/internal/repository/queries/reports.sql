@@ -0,0 +1,50 @@
+-- name: ListReportSubscriptionsByEvent :many
+SELECT * FROM report_subscriptions WHERE event_id = $1 ORDER BY name;
+
+-- name: GetReportSubscription :one
+SELECT * FROM report_subscriptions WHERE id = $1;
+
+-- name: CreateReportSubscription :one
+INSERT INTO report_subscriptions (
+    event_id, name, format, pdf_layout, team_ids, time_window,
+    recipient_user_ids, recipient_emails, frequency, weekday, send_time,
+    is_enabled, next_run_at, created_by
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+RETURNING *;
+
+-- name: UpdateReportSubscription :one
+UPDATE report_subscriptions SET
+    name = $2,
+    format = $3,
+    pdf_layout = $4,
+    team_ids = $5,
+    time_window = $6,
+    recipient_user_ids = $7,
+    recipient_emails = $8,
+    frequency = $9,
+    weekday = $10,
+    send_time = $11,
+    is_enabled = $12,
+    next_run_at = $13,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteReportSubscription :exec
+DELETE FROM report_subscriptions WHERE id = $1;
+
+-- name: ListDueReportSubscriptions :many
+SELECT * FROM report_subscriptions
+WHERE is_enabled = true AND next_run_at <= $1
+  AND event_id IN (SELECT id FROM events WHERE end_time > $1)
+ORDER BY next_run_at;
+
+-- name: ClaimReportSubscriptionRun :execrows
+-- Moves next_run_at forward only if no other instance has done so already.
+UPDATE report_subscriptions SET next_run_at = $3
+WHERE id = $1 AND next_run_at = $2;
+
+-- name: SetReportSubscriptionResult :exec
+UPDATE report_subscriptions SET last_run_at = $2, last_error = $3
+WHERE id = $1;
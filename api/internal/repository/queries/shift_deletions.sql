@@ -0,0 +1,23 @@
+-- name: CreateShiftDeletion :exec
+INSERT INTO shift_deletions (shift_id, event_id, team_id, user_id, start_time, end_time, created_at, sequence)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (shift_id) DO NOTHING;
+
+-- name: ListShiftDeletionsByEvent :many
+SELECT sd.*, t.abbreviation AS team_abbreviation, t.name AS team_name, u.username
+FROM shift_deletions sd
+JOIN teams t ON sd.team_id = t.id
+JOIN users u ON sd.user_id = u.id
+WHERE sd.event_id = $1 AND sd.deleted_at > $2
+ORDER BY sd.start_time, sd.shift_id;
+
+-- name: ListShiftDeletionsByUser :many
+SELECT sd.*, t.abbreviation AS team_abbreviation, t.name AS team_name, e.name AS event_name
+FROM shift_deletions sd
+JOIN teams t ON sd.team_id = t.id
+JOIN events e ON sd.event_id = e.id
+WHERE sd.user_id = $1 AND sd.deleted_at > $2
+ORDER BY sd.start_time, sd.shift_id;
+
+-- name: DeleteOldShiftDeletions :execrows
+DELETE FROM shift_deletions WHERE deleted_at < $1;
@@ -44,7 +44,8 @@ UPDATE shifts SET
     user_id = COALESCE(sqlc.narg('user_id'), user_id),
     start_time = COALESCE(sqlc.narg('start_time'), start_time),
     end_time = COALESCE(sqlc.narg('end_time'), end_time),
-    updated_at = NOW()
+    updated_at = NOW(),
+    sequence = sequence + 1
 WHERE id = $1
 RETURNING *;
 
@@ -21,7 +21,8 @@ UPDATE teams SET
     abbreviation = COALESCE(sqlc.narg('abbreviation'), abbreviation),
     color = COALESCE(sqlc.narg('color'), color),
     sort_order = COALESCE(sqlc.narg('sort_order'), sort_order),
-    is_active = COALESCE(sqlc.narg('is_active'), is_active)
+    is_active = COALESCE(sqlc.narg('is_active'), is_active),
+    updated_at = NOW()
 WHERE id = $1
 RETURNING *;
 
@@ -37,6 +37,7 @@ UPDATE users SET
     time_format = COALESCE(sqlc.narg('time_format'), time_format),
     username = COALESCE(sqlc.narg('username'), username),
     account_type = COALESCE(sqlc.narg('account_type'), account_type),
+    phone = COALESCE(sqlc.narg('phone'), phone),
     updated_at = NOW()
 WHERE id = $1
 RETURNING *;
@@ -59,3 +60,11 @@ SELECT * FROM users
 WHERE (username ILIKE '%' || $1 || '%' OR full_name ILIKE '%' || $1 || '%' OR email ILIKE '%' || $1 || '%')
 ORDER BY username
 LIMIT $2 OFFSET $3;
+
+-- name: ListUserPhonesByEvent :many
+SELECT DISTINCT u.id, u.phone FROM users u
+JOIN shifts s ON s.user_id = u.id
+WHERE s.event_id = $1 AND u.phone IS NOT NULL AND u.phone <> '';
+
+-- name: ListSuperAdminIDs :many
+SELECT id FROM users WHERE role = 'super_admin' AND is_active = true;
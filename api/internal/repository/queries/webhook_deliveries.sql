@@ -0,0 +1,81 @@
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (webhook_id, trigger_type, payload, redelivery_of)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ClaimDueWebhookDeliveries :many
+-- Leases a batch of due deliveries like ClaimDueEmails and returns them with
+-- the webhook's current URL, secrets, format, content type and headers. The
+-- previous secret is only returned while it hasn't expired. Deliveries of
+-- disabled webhooks are skipped, except explicit test deliveries and
+-- redeliveries.
+UPDATE webhook_deliveries d SET next_attempt_at = $2
+FROM webhook_configs w
+WHERE w.id = d.webhook_id AND d.id IN (
+    SELECT p.id FROM webhook_deliveries p
+    JOIN webhook_configs c ON c.id = p.webhook_id
+    WHERE p.status = 'pending' AND p.next_attempt_at <= $1
+        AND (c.is_enabled OR p.trigger_type = 'webhook.test' OR p.redelivery_of IS NOT NULL)
+    ORDER BY p.next_attempt_at
+    LIMIT $3
+    FOR UPDATE OF p SKIP LOCKED
+)
+RETURNING d.id, d.webhook_id, d.trigger_type, d.payload, d.attempts, w.url, w.secret,
+    CASE WHEN w.previous_secret_expires_at > $1 THEN w.previous_secret END AS previous_secret,
+    w.format, w.content_type, w.headers;
+
+-- name: RecordWebhookDeliveryAttempt :exec
+INSERT INTO webhook_delivery_attempts (delivery_id, attempt, response_status, duration_ms, error, response_body)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: MarkWebhookDeliveryDelivered :exec
+UPDATE webhook_deliveries SET status = 'delivered', delivered_at = NOW(), attempts = attempts + 1
+WHERE id = $1;
+
+-- name: MarkWebhookDeliveryAttemptFailed :exec
+UPDATE webhook_deliveries SET
+    attempts = attempts + 1,
+    next_attempt_at = $2,
+    status = CASE WHEN $3::BOOLEAN THEN 'failed' ELSE 'pending' END
+WHERE id = $1;
+
+-- name: CancelPendingWebhookDeliveries :exec
+-- Fails queued deliveries of a webhook that was disabled. Test deliveries and
+-- redeliveries are kept, since those are sent to disabled webhooks on purpose.
+UPDATE webhook_deliveries SET status = 'failed'
+WHERE webhook_id = $1 AND status = 'pending'
+    AND trigger_type <> 'webhook.test' AND redelivery_of IS NULL;
+
+-- name: ResetWebhookFailures :exec
+UPDATE webhook_configs SET consecutive_failures = 0 WHERE id = $1 AND consecutive_failures <> 0;
+
+-- name: IncrementWebhookFailures :one
+UPDATE webhook_configs SET consecutive_failures = consecutive_failures + 1
+WHERE id = $1
+RETURNING consecutive_failures;
+
+-- name: DisableWebhook :execrows
+UPDATE webhook_configs SET is_enabled = false, disabled_reason = $2
+WHERE id = $1 AND is_enabled = true;
+
+-- name: ListWebhookDeliveries :many
+SELECT d.*, a.response_status AS last_response_status, a.duration_ms AS last_duration_ms, a.error AS last_error
+FROM webhook_deliveries d
+LEFT JOIN LATERAL (
+    SELECT response_status, duration_ms, error FROM webhook_delivery_attempts
+    WHERE delivery_id = d.id
+    ORDER BY attempt DESC
+    LIMIT 1
+) a ON true
+WHERE d.webhook_id = $1
+ORDER BY d.created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries WHERE id = $1 AND webhook_id = $2;
+
+-- name: ListWebhookDeliveryAttempts :many
+SELECT * FROM webhook_delivery_attempts WHERE delivery_id = $1 ORDER BY attempt;
+
+-- name: DeleteOldWebhookDeliveries :execrows
+DELETE FROM webhook_deliveries WHERE status <> 'pending' AND created_at < $1;
@@ -5,8 +5,8 @@ SELECT * FROM webhook_configs WHERE event_id = $1 ORDER BY name;
 SELECT * FROM webhook_configs WHERE id = $1;
 
 -- name: CreateWebhook :one
-INSERT INTO webhook_configs (event_id, name, url, secret, trigger_types, format)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO webhook_configs (event_id, name, url, secret, trigger_types, format, template, content_type, headers, filter_team_ids, filter_user_ids, filter_within_hours)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 RETURNING *;
 
 -- name: UpdateWebhook :one
@@ -16,7 +16,30 @@ UPDATE webhook_configs SET
     secret = COALESCE(sqlc.narg('secret'), secret),
     trigger_types = COALESCE(sqlc.narg('trigger_types'), trigger_types),
     is_enabled = COALESCE(sqlc.narg('is_enabled'), is_enabled),
-    format = COALESCE(sqlc.narg('format'), format)
+    format = COALESCE(sqlc.narg('format'), format),
+    -- Template settings are dropped when switching to another format
+    template = CASE WHEN COALESCE(sqlc.narg('format'), format) = 'template' THEN COALESCE(sqlc.narg('template'), template) END,
+    content_type = CASE WHEN COALESCE(sqlc.narg('format'), format) = 'template' THEN COALESCE(sqlc.narg('content_type'), content_type) END,
+    headers = CASE WHEN COALESCE(sqlc.narg('format'), format) = 'template' THEN COALESCE(sqlc.narg('headers'), headers) END,
+    -- Enabling or disabling by hand starts the failure count over
+    consecutive_failures = CASE WHEN sqlc.narg('is_enabled')::BOOLEAN IS NOT NULL THEN 0 ELSE consecutive_failures END,
+    disabled_reason = CASE WHEN sqlc.narg('is_enabled')::BOOLEAN IS NOT NULL THEN NULL ELSE disabled_reason END,
+    -- Setting a secret by hand ends a rotation, e.g. after a leak
+    previous_secret = CASE WHEN sqlc.narg('secret')::TEXT IS NOT NULL THEN NULL ELSE previous_secret END,
+    previous_secret_expires_at = CASE WHEN sqlc.narg('secret')::TEXT IS NOT NULL THEN NULL ELSE previous_secret_expires_at END,
+    -- Filters are replaced together, signalled by filter_team_ids being set
+    filter_team_ids = COALESCE(sqlc.narg('filter_team_ids'), filter_team_ids),
+    filter_user_ids = COALESCE(sqlc.narg('filter_user_ids'), filter_user_ids),
+    filter_within_hours = CASE WHEN sqlc.narg('filter_team_ids')::UUID[] IS NOT NULL THEN sqlc.narg('filter_within_hours') ELSE filter_within_hours END
+WHERE id = $1
+RETURNING *;
+
+-- name: RotateWebhookSecret :one
+-- Replaces the secret, keeping the current one valid until previous_secret_expires_at.
+UPDATE webhook_configs SET
+    previous_secret = secret,
+    previous_secret_expires_at = $3,
+    secret = $2
 WHERE id = $1
 RETURNING *;
 
@@ -28,13 +51,33 @@ SELECT * FROM webhook_configs
 WHERE event_id = $1 AND is_enabled = true AND $2 = ANY(trigger_types);
 
 -- name: ListGlobalWebhooks :many
-SELECT * FROM webhook_configs WHERE event_id IS NULL ORDER BY name;
+SELECT * FROM webhook_configs WHERE event_id IS NULL AND user_id IS NULL ORDER BY name;
 
 -- name: CreateGlobalWebhook :one
-INSERT INTO webhook_configs (name, url, secret, trigger_types, format)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO webhook_configs (name, url, secret, trigger_types, format, template, content_type, headers)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING *;
 
 -- name: ListActiveGlobalWebhooksForTrigger :many
 SELECT * FROM webhook_configs
-WHERE event_id IS NULL AND is_enabled = true AND $1 = ANY(trigger_types);
+WHERE event_id IS NULL AND user_id IS NULL AND is_enabled = true AND $1 = ANY(trigger_types);
+
+-- name: ListWebhooksByUser :many
+SELECT * FROM webhook_configs WHERE user_id = $1 ORDER BY name;
+
+-- name: CountWebhooksByUser :one
+SELECT COUNT(*) FROM webhook_configs WHERE user_id = $1;
+
+-- name: LockUserWebhooks :exec
+-- Serializes creating personal webhooks of a user until the end of the
+-- transaction, so the per-user limit holds.
+SELECT pg_advisory_xact_lock(hashtextextended('webhooks/' || $1::text, 0));
+
+-- name: CreatePersonalWebhook :one
+INSERT INTO webhook_configs (user_id, name, url, secret, trigger_types, format, template, content_type, headers)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: ListActivePersonalWebhooksForTrigger :many
+SELECT * FROM webhook_configs
+WHERE user_id = ANY(sqlc.arg('user_ids')::UUID[]) AND is_enabled = true AND sqlc.arg('trigger_type') = ANY(trigger_types);
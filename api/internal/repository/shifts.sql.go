@@ -13,7 +13,7 @@ import (
 )
 
 const getShiftByID = `-- name: GetShiftByID :one
-SELECT s.id, s.event_id, s.team_id, s.user_id, s.start_time, s.end_time, s.created_by, s.created_at, s.updated_at, t.abbreviation AS team_abbreviation, t.color AS team_color, t.name AS team_name,
+SELECT s.id, s.event_id, s.team_id, s.user_id, s.start_time, s.end_time, s.created_by, s.created_at, s.updated_at, s.sequence, t.abbreviation AS team_abbreviation, t.color AS team_color, t.name AS team_name,
        u.username, u.full_name AS user_full_name, u.display_name AS user_display_name
 FROM shifts s
 JOIN teams t ON s.team_id = t.id
@@ -31,6 +31,7 @@ type GetShiftByIDRow struct {
 	CreatedBy        *uuid.UUID `json:"created_by"`
 	CreatedAt        time.Time  `json:"created_at"`
 	UpdatedAt        time.Time  `json:"updated_at"`
+	Sequence         int32      `json:"sequence"`
 	TeamAbbreviation string     `json:"team_abbreviation"`
 	TeamColor        string     `json:"team_color"`
 	TeamName         string     `json:"team_name"`
@@ -52,6 +53,7 @@ func (q *Queries) GetShiftByID(ctx context.Context, id uuid.UUID) (GetShiftByIDR
 		&i.CreatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Sequence,
 		&i.TeamAbbreviation,
 		&i.TeamColor,
 		&i.TeamName,
@@ -63,7 +65,7 @@ func (q *Queries) GetShiftByID(ctx context.Context, id uuid.UUID) (GetShiftByIDR
 }
 
 const listShiftsByEvent = `-- name: ListShiftsByEvent :many
-SELECT s.id, s.event_id, s.team_id, s.user_id, s.start_time, s.end_time, s.created_by, s.created_at, s.updated_at, t.abbreviation AS team_abbreviation, t.color AS team_color, t.name AS team_name,
+SELECT s.id, s.event_id, s.team_id, s.user_id, s.start_time, s.end_time, s.created_by, s.created_at, s.updated_at, s.sequence, t.abbreviation AS team_abbreviation, t.color AS team_color, t.name AS team_name,
        u.username, u.full_name AS user_full_name, u.display_name AS user_display_name, u.account_type
 FROM shifts s
 JOIN teams t ON s.team_id = t.id
@@ -82,6 +84,7 @@ type ListShiftsByEventRow struct {
 	CreatedBy        *uuid.UUID `json:"created_by"`
 	CreatedAt        time.Time  `json:"created_at"`
 	UpdatedAt        time.Time  `json:"updated_at"`
+	Sequence         int32      `json:"sequence"`
 	TeamAbbreviation string     `json:"team_abbreviation"`
 	TeamColor        string     `json:"team_color"`
 	TeamName         string     `json:"team_name"`
@@ -110,6 +113,7 @@ func (q *Queries) ListShiftsByEvent(ctx context.Context, eventID uuid.UUID) ([]L
 			&i.CreatedBy,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Sequence,
 			&i.TeamAbbreviation,
 			&i.TeamColor,
 			&i.TeamName,
@@ -129,7 +133,7 @@ func (q *Queries) ListShiftsByEvent(ctx context.Context, eventID uuid.UUID) ([]L
 }
 
 const listShiftsByEventAndTeam = `-- name: ListShiftsByEventAndTeam :many
-SELECT s.id, s.event_id, s.team_id, s.user_id, s.start_time, s.end_time, s.created_by, s.created_at, s.updated_at, t.abbreviation AS team_abbreviation, t.color AS team_color, t.name AS team_name,
+SELECT s.id, s.event_id, s.team_id, s.user_id, s.start_time, s.end_time, s.created_by, s.created_at, s.updated_at, s.sequence, t.abbreviation AS team_abbreviation, t.color AS team_color, t.name AS team_name,
        u.username, u.full_name AS user_full_name, u.display_name AS user_display_name
 FROM shifts s
 JOIN teams t ON s.team_id = t.id
@@ -148,6 +152,7 @@ type ListShiftsByEventAndTeamRow struct {
 	CreatedBy        *uuid.UUID `json:"created_by"`
 	CreatedAt        time.Time  `json:"created_at"`
 	UpdatedAt        time.Time  `json:"updated_at"`
+	Sequence         int32      `json:"sequence"`
 	TeamAbbreviation string     `json:"team_abbreviation"`
 	TeamColor        string     `json:"team_color"`
 	TeamName         string     `json:"team_name"`
@@ -180,6 +185,7 @@ func (q *Queries) ListShiftsByEventAndTeam(ctx context.Context, arg ListShiftsBy
 			&i.CreatedBy,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Sequence,
 			&i.TeamAbbreviation,
 			&i.TeamColor,
 			&i.TeamName,
@@ -198,7 +204,7 @@ func (q *Queries) ListShiftsByEventAndTeam(ctx context.Context, arg ListShiftsBy
 }
 
 const listShiftsByUser = `-- name: ListShiftsByUser :many
-SELECT s.id, s.event_id, s.team_id, s.user_id, s.start_time, s.end_time, s.created_by, s.created_at, s.updated_at, t.abbreviation AS team_abbreviation, t.color AS team_color, t.name AS team_name,
+SELECT s.id, s.event_id, s.team_id, s.user_id, s.start_time, s.end_time, s.created_by, s.created_at, s.updated_at, s.sequence, t.abbreviation AS team_abbreviation, t.color AS team_color, t.name AS team_name,
        e.name AS event_name, e.slug AS event_slug
 FROM shifts s
 JOIN teams t ON s.team_id = t.id
@@ -217,6 +223,7 @@ type ListShiftsByUserRow struct {
 	CreatedBy        *uuid.UUID `json:"created_by"`
 	CreatedAt        time.Time  `json:"created_at"`
 	UpdatedAt        time.Time  `json:"updated_at"`
+	Sequence         int32      `json:"sequence"`
 	TeamAbbreviation string     `json:"team_abbreviation"`
 	TeamColor        string     `json:"team_color"`
 	TeamName         string     `json:"team_name"`
@@ -243,6 +250,7 @@ func (q *Queries) ListShiftsByUser(ctx context.Context, userID uuid.UUID) ([]Lis
 			&i.CreatedBy,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Sequence,
 			&i.TeamAbbreviation,
 			&i.TeamColor,
 			&i.TeamName,
@@ -262,7 +270,7 @@ func (q *Queries) ListShiftsByUser(ctx context.Context, userID uuid.UUID) ([]Lis
 const createShift = `-- name: CreateShift :one
 INSERT INTO shifts (event_id, team_id, user_id, start_time, end_time, created_by)
 VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, event_id, team_id, user_id, start_time, end_time, created_by, created_at, updated_at
+RETURNING id, event_id, team_id, user_id, start_time, end_time, created_by, created_at, updated_at, sequence
 `
 
 type CreateShiftParams struct {
@@ -294,6 +302,7 @@ func (q *Queries) CreateShift(ctx context.Context, arg CreateShiftParams) (Shift
 		&i.CreatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Sequence,
 	)
 	return i, err
 }
@@ -304,9 +313,10 @@ UPDATE shifts SET
     user_id = COALESCE($3, user_id),
     start_time = COALESCE($4, start_time),
     end_time = COALESCE($5, end_time),
-    updated_at = NOW()
+    updated_at = NOW(),
+    sequence = sequence + 1
 WHERE id = $1
-RETURNING id, event_id, team_id, user_id, start_time, end_time, created_by, created_at, updated_at
+RETURNING id, event_id, team_id, user_id, start_time, end_time, created_by, created_at, updated_at, sequence
 `
 
 // NOTE: manually updated to add UserID field — regenerate with sqlc generate
@@ -337,6 +347,7 @@ func (q *Queries) UpdateShift(ctx context.Context, arg UpdateShiftParams) (Shift
 		&i.CreatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Sequence,
 	)
 	return i, err
 }
@@ -376,7 +387,7 @@ func (q *Queries) CountShiftsInTimeRange(ctx context.Context, arg CountShiftsInT
 }
 
 const getOverlappingShifts = `-- name: GetOverlappingShifts :many
-SELECT id, event_id, team_id, user_id, start_time, end_time, created_by, created_at, updated_at FROM shifts
+SELECT id, event_id, team_id, user_id, start_time, end_time, created_by, created_at, updated_at, sequence FROM shifts
 WHERE user_id = $1 AND event_id = $2
   AND start_time < $4 AND end_time > $3
   AND ($5::uuid IS NULL OR id != $5)
@@ -415,6 +426,7 @@ func (q *Queries) GetOverlappingShifts(ctx context.Context, arg GetOverlappingSh
 			&i.CreatedBy,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Sequence,
 		); err != nil {
 			return nil, err
 		}
@@ -12,7 +12,7 @@ import (
 )
 
 const getTeamByID = `-- name: GetTeamByID :one
-SELECT id, name, abbreviation, color, sort_order, is_active, created_at FROM teams WHERE id = $1
+SELECT id, name, abbreviation, color, sort_order, is_active, created_at, updated_at FROM teams WHERE id = $1
 `
 
 func (q *Queries) GetTeamByID(ctx context.Context, id uuid.UUID) (Team, error) {
@@ -26,12 +26,13 @@ func (q *Queries) GetTeamByID(ctx context.Context, id uuid.UUID) (Team, error) {
 		&i.SortOrder,
 		&i.IsActive,
 		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
 const getTeamByAbbreviation = `-- name: GetTeamByAbbreviation :one
-SELECT id, name, abbreviation, color, sort_order, is_active, created_at FROM teams WHERE abbreviation = $1
+SELECT id, name, abbreviation, color, sort_order, is_active, created_at, updated_at FROM teams WHERE abbreviation = $1
 `
 
 func (q *Queries) GetTeamByAbbreviation(ctx context.Context, abbreviation string) (Team, error) {
@@ -45,12 +46,13 @@ func (q *Queries) GetTeamByAbbreviation(ctx context.Context, abbreviation string
 		&i.SortOrder,
 		&i.IsActive,
 		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
 const listTeams = `-- name: ListTeams :many
-SELECT id, name, abbreviation, color, sort_order, is_active, created_at FROM teams ORDER BY sort_order, name
+SELECT id, name, abbreviation, color, sort_order, is_active, created_at, updated_at FROM teams ORDER BY sort_order, name
 `
 
 func (q *Queries) ListTeams(ctx context.Context) ([]Team, error) {
@@ -70,6 +72,7 @@ func (q *Queries) ListTeams(ctx context.Context) ([]Team, error) {
 			&i.SortOrder,
 			&i.IsActive,
 			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -82,7 +85,7 @@ func (q *Queries) ListTeams(ctx context.Context) ([]Team, error) {
 }
 
 const listActiveTeams = `-- name: ListActiveTeams :many
-SELECT id, name, abbreviation, color, sort_order, is_active, created_at FROM teams WHERE is_active = true ORDER BY sort_order, name
+SELECT id, name, abbreviation, color, sort_order, is_active, created_at, updated_at FROM teams WHERE is_active = true ORDER BY sort_order, name
 `
 
 func (q *Queries) ListActiveTeams(ctx context.Context) ([]Team, error) {
@@ -102,6 +105,7 @@ func (q *Queries) ListActiveTeams(ctx context.Context) ([]Team, error) {
 			&i.SortOrder,
 			&i.IsActive,
 			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -116,7 +120,7 @@ func (q *Queries) ListActiveTeams(ctx context.Context) ([]Team, error) {
 const createTeam = `-- name: CreateTeam :one
 INSERT INTO teams (name, abbreviation, color, sort_order)
 VALUES ($1, $2, $3, $4)
-RETURNING id, name, abbreviation, color, sort_order, is_active, created_at
+RETURNING id, name, abbreviation, color, sort_order, is_active, created_at, updated_at
 `
 
 type CreateTeamParams struct {
@@ -142,6 +146,7 @@ func (q *Queries) CreateTeam(ctx context.Context, arg CreateTeamParams) (Team, e
 		&i.SortOrder,
 		&i.IsActive,
 		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -152,9 +157,10 @@ UPDATE teams SET
     abbreviation = COALESCE($3, abbreviation),
     color = COALESCE($4, color),
     sort_order = COALESCE($5, sort_order),
-    is_active = COALESCE($6, is_active)
+    is_active = COALESCE($6, is_active),
+    updated_at = NOW()
 WHERE id = $1
-RETURNING id, name, abbreviation, color, sort_order, is_active, created_at
+RETURNING id, name, abbreviation, color, sort_order, is_active, created_at, updated_at
 `
 
 type UpdateTeamParams struct {
@@ -184,6 +190,7 @@ func (q *Queries) UpdateTeam(ctx context.Context, arg UpdateTeamParams) (Team, e
 		&i.SortOrder,
 		&i.IsActive,
 		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
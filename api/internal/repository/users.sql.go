@@ -12,7 +12,7 @@ import (
 )
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, username, full_name, display_name, email, password_hash, role, language, account_type, totp_secret, totp_enabled, is_active, time_format, created_at, updated_at FROM users WHERE id = $1
+SELECT id, username, full_name, display_name, email, password_hash, role, language, account_type, totp_secret, totp_enabled, is_active, time_format, phone, created_at, updated_at FROM users WHERE id = $1
 `
 
 func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
@@ -32,6 +32,7 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.TotpEnabled,
 		&i.IsActive,
 		&i.TimeFormat,
+		&i.Phone,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -39,7 +40,7 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, username, full_name, display_name, email, password_hash, role, language, account_type, totp_secret, totp_enabled, is_active, time_format, created_at, updated_at FROM users WHERE LOWER(username) = LOWER($1)
+SELECT id, username, full_name, display_name, email, password_hash, role, language, account_type, totp_secret, totp_enabled, is_active, time_format, phone, created_at, updated_at FROM users WHERE LOWER(username) = LOWER($1)
 `
 
 func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User, error) {
@@ -59,6 +60,7 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 		&i.TotpEnabled,
 		&i.IsActive,
 		&i.TimeFormat,
+		&i.Phone,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -66,7 +68,7 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, username, full_name, display_name, email, password_hash, role, language, account_type, totp_secret, totp_enabled, is_active, time_format, created_at, updated_at FROM users WHERE email = $1
+SELECT id, username, full_name, display_name, email, password_hash, role, language, account_type, totp_secret, totp_enabled, is_active, time_format, phone, created_at, updated_at FROM users WHERE email = $1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email *string) (User, error) {
@@ -86,6 +88,7 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email *string) (User, erro
 		&i.TotpEnabled,
 		&i.IsActive,
 		&i.TimeFormat,
+		&i.Phone,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -93,7 +96,7 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email *string) (User, erro
 }
 
 const listUsers = `-- name: ListUsers :many
-SELECT id, username, full_name, display_name, email, password_hash, role, language, account_type, totp_secret, totp_enabled, is_active, time_format, created_at, updated_at FROM users
+SELECT id, username, full_name, display_name, email, password_hash, role, language, account_type, totp_secret, totp_enabled, is_active, time_format, phone, created_at, updated_at FROM users
 WHERE ($1::varchar IS NULL OR role = $1)
   AND ($2::varchar IS NULL OR account_type = $2)
   AND ($5::varchar IS NULL OR account_type != $5)
@@ -132,6 +135,7 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.TotpEnabled,
 			&i.IsActive,
 			&i.TimeFormat,
+			&i.Phone,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -168,7 +172,7 @@ func (q *Queries) CountUsers(ctx context.Context, arg CountUsersParams) (int64,
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (username, full_name, display_name, email, password_hash, role, language, account_type)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, username, full_name, display_name, email, password_hash, role, language, account_type, totp_secret, totp_enabled, is_active, time_format, created_at, updated_at
+RETURNING id, username, full_name, display_name, email, password_hash, role, language, account_type, totp_secret, totp_enabled, is_active, time_format, phone, created_at, updated_at
 `
 
 type CreateUserParams struct {
@@ -208,6 +212,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.TotpEnabled,
 		&i.IsActive,
 		&i.TimeFormat,
+		&i.Phone,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -225,9 +230,10 @@ UPDATE users SET
     time_format = COALESCE($8, time_format),
     username = COALESCE($9, username),
     account_type = COALESCE($10, account_type),
+    phone = COALESCE($11, phone),
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, username, full_name, display_name, email, password_hash, role, language, account_type, totp_secret, totp_enabled, is_active, time_format, created_at, updated_at
+RETURNING id, username, full_name, display_name, email, password_hash, role, language, account_type, totp_secret, totp_enabled, is_active, time_format, phone, created_at, updated_at
 `
 
 type UpdateUserParams struct {
@@ -241,6 +247,7 @@ type UpdateUserParams struct {
 	TimeFormat  *string   `json:"time_format"`
 	Username    *string   `json:"username"`
 	AccountType *string   `json:"account_type"`
+	Phone       *string   `json:"phone"`
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
@@ -255,6 +262,7 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		arg.TimeFormat,
 		arg.Username,
 		arg.AccountType,
+		arg.Phone,
 	)
 	var i User
 	err := row.Scan(
@@ -271,6 +279,7 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.TotpEnabled,
 		&i.IsActive,
 		&i.TimeFormat,
+		&i.Phone,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -330,7 +339,7 @@ func (q *Queries) ListSuperAdminEmails(ctx context.Context) ([]*string, error) {
 }
 
 const searchUsers = `-- name: SearchUsers :many
-SELECT id, username, full_name, display_name, email, password_hash, role, language, account_type, totp_secret, totp_enabled, is_active, time_format, created_at, updated_at FROM users
+SELECT id, username, full_name, display_name, email, password_hash, role, language, account_type, totp_secret, totp_enabled, is_active, time_format, phone, created_at, updated_at FROM users
 WHERE (username ILIKE '%' || $1 || '%' OR full_name ILIKE '%' || $1 || '%' OR email ILIKE '%' || $1 || '%')
 ORDER BY username
 LIMIT $2 OFFSET $3
@@ -365,6 +374,7 @@ func (q *Queries) SearchUsers(ctx context.Context, arg SearchUsersParams) ([]Use
 			&i.TotpEnabled,
 			&i.IsActive,
 			&i.TimeFormat,
+			&i.Phone,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -377,3 +387,58 @@ func (q *Queries) SearchUsers(ctx context.Context, arg SearchUsersParams) ([]Use
 	}
 	return items, nil
 }
+
+const listUserPhonesByEvent = `-- name: ListUserPhonesByEvent :many
+SELECT DISTINCT u.id, u.phone FROM users u
+JOIN shifts s ON s.user_id = u.id
+WHERE s.event_id = $1 AND u.phone IS NOT NULL AND u.phone <> ''
+`
+
+type ListUserPhonesByEventRow struct {
+	ID    uuid.UUID `json:"id"`
+	Phone *string   `json:"phone"`
+}
+
+func (q *Queries) ListUserPhonesByEvent(ctx context.Context, eventID uuid.UUID) ([]ListUserPhonesByEventRow, error) {
+	rows, err := q.db.Query(ctx, listUserPhonesByEvent, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUserPhonesByEventRow{}
+	for rows.Next() {
+		var i ListUserPhonesByEventRow
+		if err := rows.Scan(&i.ID, &i.Phone); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSuperAdminIDs = `-- name: ListSuperAdminIDs :many
+SELECT id FROM users WHERE role = 'super_admin' AND is_active = true
+`
+
+func (q *Queries) ListSuperAdminIDs(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listSuperAdminIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -8,12 +8,14 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 const listWebhooksByEvent = `-- name: ListWebhooksByEvent :many
-SELECT id, event_id, name, url, secret, format, trigger_types, is_enabled, created_at FROM webhook_configs WHERE event_id = $1 ORDER BY name
+SELECT id, event_id, name, url, secret, format, trigger_types, is_enabled, created_at, consecutive_failures, disabled_reason, template, content_type, headers, previous_secret, previous_secret_expires_at, filter_team_ids, filter_user_ids, filter_within_hours, user_id FROM webhook_configs WHERE event_id = $1 ORDER BY name
 `
 
 func (q *Queries) ListWebhooksByEvent(ctx context.Context, eventID uuid.UUID) ([]WebhookConfig, error) {
@@ -35,6 +37,17 @@ func (q *Queries) ListWebhooksByEvent(ctx context.Context, eventID uuid.UUID) ([
 			&i.TriggerTypes,
 			&i.IsEnabled,
 			&i.CreatedAt,
+			&i.ConsecutiveFailures,
+			&i.DisabledReason,
+			&i.Template,
+			&i.ContentType,
+			&i.Headers,
+			&i.PreviousSecret,
+			&i.PreviousSecretExpiresAt,
+			&i.FilterTeamIds,
+			&i.FilterUserIds,
+			&i.FilterWithinHours,
+			&i.UserID,
 		); err != nil {
 			return nil, err
 		}
@@ -47,7 +60,7 @@ func (q *Queries) ListWebhooksByEvent(ctx context.Context, eventID uuid.UUID) ([
 }
 
 const getWebhookByID = `-- name: GetWebhookByID :one
-SELECT id, event_id, name, url, secret, format, trigger_types, is_enabled, created_at FROM webhook_configs WHERE id = $1
+SELECT id, event_id, name, url, secret, format, trigger_types, is_enabled, created_at, consecutive_failures, disabled_reason, template, content_type, headers, previous_secret, previous_secret_expires_at, filter_team_ids, filter_user_ids, filter_within_hours, user_id FROM webhook_configs WHERE id = $1
 `
 
 func (q *Queries) GetWebhookByID(ctx context.Context, id uuid.UUID) (WebhookConfig, error) {
@@ -63,23 +76,41 @@ func (q *Queries) GetWebhookByID(ctx context.Context, id uuid.UUID) (WebhookConf
 		&i.TriggerTypes,
 		&i.IsEnabled,
 		&i.CreatedAt,
+		&i.ConsecutiveFailures,
+		&i.DisabledReason,
+		&i.Template,
+		&i.ContentType,
+		&i.Headers,
+		&i.PreviousSecret,
+		&i.PreviousSecretExpiresAt,
+		&i.FilterTeamIds,
+		&i.FilterUserIds,
+		&i.FilterWithinHours,
+		&i.UserID,
 	)
 	return i, err
 }
 
 const createWebhook = `-- name: CreateWebhook :one
-INSERT INTO webhook_configs (event_id, name, url, secret, trigger_types, format)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, event_id, name, url, secret, format, trigger_types, is_enabled, created_at
+INSERT INTO webhook_configs (event_id, name, url, secret, trigger_types, format, template, content_type, headers, filter_team_ids, filter_user_ids, filter_within_hours)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING id, event_id, name, url, secret, format, trigger_types, is_enabled, created_at, consecutive_failures, disabled_reason, template, content_type, headers, previous_secret, previous_secret_expires_at, filter_team_ids, filter_user_ids, filter_within_hours, user_id
 `
 
 type CreateWebhookParams struct {
-	EventID      *uuid.UUID `json:"event_id"`
-	Name         string     `json:"name"`
-	Url          string     `json:"url"`
-	Secret       string     `json:"secret"`
-	TriggerTypes []string   `json:"trigger_types"`
-	Format       string     `json:"format"`
+	EventID      *uuid.UUID      `json:"event_id"`
+	Name         string          `json:"name"`
+	Url          string          `json:"url"`
+	Secret       string          `json:"secret"`
+	TriggerTypes []string        `json:"trigger_types"`
+	Format       string          `json:"format"`
+	Template     *string         `json:"template"`
+	ContentType  *string         `json:"content_type"`
+	Headers      json.RawMessage `json:"headers"`
+
+	FilterTeamIds     []uuid.UUID `json:"filter_team_ids"`
+	FilterUserIds     []uuid.UUID `json:"filter_user_ids"`
+	FilterWithinHours *int32      `json:"filter_within_hours"`
 }
 
 func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (WebhookConfig, error) {
@@ -90,6 +121,12 @@ func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (W
 		arg.Secret,
 		arg.TriggerTypes,
 		arg.Format,
+		arg.Template,
+		arg.ContentType,
+		arg.Headers,
+		arg.FilterTeamIds,
+		arg.FilterUserIds,
+		arg.FilterWithinHours,
 	)
 	var i WebhookConfig
 	err := row.Scan(
@@ -102,6 +139,17 @@ func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (W
 		&i.TriggerTypes,
 		&i.IsEnabled,
 		&i.CreatedAt,
+		&i.ConsecutiveFailures,
+		&i.DisabledReason,
+		&i.Template,
+		&i.ContentType,
+		&i.Headers,
+		&i.PreviousSecret,
+		&i.PreviousSecretExpiresAt,
+		&i.FilterTeamIds,
+		&i.FilterUserIds,
+		&i.FilterWithinHours,
+		&i.UserID,
 	)
 	return i, err
 }
@@ -113,19 +161,37 @@ UPDATE webhook_configs SET
     secret = COALESCE($4, secret),
     trigger_types = COALESCE($5, trigger_types),
     is_enabled = COALESCE($6, is_enabled),
-    format = COALESCE($7, format)
+    format = COALESCE($7, format),
+    template = CASE WHEN COALESCE($7, format) = 'template' THEN COALESCE($8, template) END,
+    content_type = CASE WHEN COALESCE($7, format) = 'template' THEN COALESCE($9, content_type) END,
+    headers = CASE WHEN COALESCE($7, format) = 'template' THEN COALESCE($10, headers) END,
+    consecutive_failures = CASE WHEN $6::BOOLEAN IS NOT NULL THEN 0 ELSE consecutive_failures END,
+    disabled_reason = CASE WHEN $6::BOOLEAN IS NOT NULL THEN NULL ELSE disabled_reason END,
+    previous_secret = CASE WHEN $4::TEXT IS NOT NULL THEN NULL ELSE previous_secret END,
+    previous_secret_expires_at = CASE WHEN $4::TEXT IS NOT NULL THEN NULL ELSE previous_secret_expires_at END,
+    filter_team_ids = COALESCE($11, filter_team_ids),
+    filter_user_ids = COALESCE($12, filter_user_ids),
+    filter_within_hours = CASE WHEN $11::UUID[] IS NOT NULL THEN $13 ELSE filter_within_hours END
 WHERE id = $1
-RETURNING id, event_id, name, url, secret, format, trigger_types, is_enabled, created_at
+RETURNING id, event_id, name, url, secret, format, trigger_types, is_enabled, created_at, consecutive_failures, disabled_reason, template, content_type, headers, previous_secret, previous_secret_expires_at, filter_team_ids, filter_user_ids, filter_within_hours, user_id
 `
 
 type UpdateWebhookParams struct {
-	ID           uuid.UUID `json:"id"`
-	Name         *string   `json:"name"`
-	Url          *string   `json:"url"`
-	Secret       *string   `json:"secret"`
-	TriggerTypes *[]string `json:"trigger_types"`
-	IsEnabled    *bool     `json:"is_enabled"`
-	Format       *string   `json:"format"`
+	ID           uuid.UUID       `json:"id"`
+	Name         *string         `json:"name"`
+	Url          *string         `json:"url"`
+	Secret       *string         `json:"secret"`
+	TriggerTypes *[]string       `json:"trigger_types"`
+	IsEnabled    *bool           `json:"is_enabled"`
+	Format       *string         `json:"format"`
+	Template     *string         `json:"template"`
+	ContentType  *string         `json:"content_type"`
+	Headers      json.RawMessage `json:"headers"`
+
+	// Filters are replaced together; nil FilterTeamIds keeps them.
+	FilterTeamIds     []uuid.UUID `json:"filter_team_ids"`
+	FilterUserIds     []uuid.UUID `json:"filter_user_ids"`
+	FilterWithinHours *int32      `json:"filter_within_hours"`
 }
 
 func (q *Queries) UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) (WebhookConfig, error) {
@@ -137,6 +203,12 @@ func (q *Queries) UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) (W
 		arg.TriggerTypes,
 		arg.IsEnabled,
 		arg.Format,
+		arg.Template,
+		arg.ContentType,
+		arg.Headers,
+		arg.FilterTeamIds,
+		arg.FilterUserIds,
+		arg.FilterWithinHours,
 	)
 	var i WebhookConfig
 	err := row.Scan(
@@ -149,6 +221,56 @@ func (q *Queries) UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) (W
 		&i.TriggerTypes,
 		&i.IsEnabled,
 		&i.CreatedAt,
+		&i.ConsecutiveFailures,
+		&i.DisabledReason,
+		&i.Template,
+		&i.ContentType,
+		&i.Headers,
+		&i.PreviousSecret,
+		&i.PreviousSecretExpiresAt,
+		&i.FilterTeamIds,
+		&i.FilterUserIds,
+		&i.FilterWithinHours,
+		&i.UserID,
+	)
+	return i, err
+}
+
+const rotateWebhookSecret = `-- name: RotateWebhookSecret :one
+UPDATE webhook_configs SET
+    previous_secret = secret,
+    previous_secret_expires_at = $3,
+    secret = $2
+WHERE id = $1
+RETURNING id, event_id, name, url, secret, format, trigger_types, is_enabled, created_at, consecutive_failures, disabled_reason, template, content_type, headers, previous_secret, previous_secret_expires_at, filter_team_ids, filter_user_ids, filter_within_hours, user_id
+`
+
+// RotateWebhookSecret replaces the secret, keeping the current one valid
+// until previous_secret_expires_at.
+func (q *Queries) RotateWebhookSecret(ctx context.Context, iD uuid.UUID, secret string, previousSecretExpiresAt *time.Time) (WebhookConfig, error) {
+	row := q.db.QueryRow(ctx, rotateWebhookSecret, iD, secret, previousSecretExpiresAt)
+	var i WebhookConfig
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.Name,
+		&i.Url,
+		&i.Secret,
+		&i.Format,
+		&i.TriggerTypes,
+		&i.IsEnabled,
+		&i.CreatedAt,
+		&i.ConsecutiveFailures,
+		&i.DisabledReason,
+		&i.Template,
+		&i.ContentType,
+		&i.Headers,
+		&i.PreviousSecret,
+		&i.PreviousSecretExpiresAt,
+		&i.FilterTeamIds,
+		&i.FilterUserIds,
+		&i.FilterWithinHours,
+		&i.UserID,
 	)
 	return i, err
 }
@@ -163,7 +285,7 @@ func (q *Queries) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
 }
 
 const listActiveWebhooksForTrigger = `-- name: ListActiveWebhooksForTrigger :many
-SELECT id, event_id, name, url, secret, format, trigger_types, is_enabled, created_at FROM webhook_configs
+SELECT id, event_id, name, url, secret, format, trigger_types, is_enabled, created_at, consecutive_failures, disabled_reason, template, content_type, headers, previous_secret, previous_secret_expires_at, filter_team_ids, filter_user_ids, filter_within_hours, user_id FROM webhook_configs
 WHERE event_id = $1 AND is_enabled = true AND $2 = ANY(trigger_types)
 `
 
@@ -191,6 +313,17 @@ func (q *Queries) ListActiveWebhooksForTrigger(ctx context.Context, arg ListActi
 			&i.TriggerTypes,
 			&i.IsEnabled,
 			&i.CreatedAt,
+			&i.ConsecutiveFailures,
+			&i.DisabledReason,
+			&i.Template,
+			&i.ContentType,
+			&i.Headers,
+			&i.PreviousSecret,
+			&i.PreviousSecretExpiresAt,
+			&i.FilterTeamIds,
+			&i.FilterUserIds,
+			&i.FilterWithinHours,
+			&i.UserID,
 		); err != nil {
 			return nil, err
 		}
@@ -203,7 +336,7 @@ func (q *Queries) ListActiveWebhooksForTrigger(ctx context.Context, arg ListActi
 }
 
 const listGlobalWebhooks = `-- name: ListGlobalWebhooks :many
-SELECT id, event_id, name, url, secret, format, trigger_types, is_enabled, created_at FROM webhook_configs WHERE event_id IS NULL ORDER BY name
+SELECT id, event_id, name, url, secret, format, trigger_types, is_enabled, created_at, consecutive_failures, disabled_reason, template, content_type, headers, previous_secret, previous_secret_expires_at, filter_team_ids, filter_user_ids, filter_within_hours, user_id FROM webhook_configs WHERE event_id IS NULL AND user_id IS NULL ORDER BY name
 `
 
 func (q *Queries) ListGlobalWebhooks(ctx context.Context) ([]WebhookConfig, error) {
@@ -225,6 +358,17 @@ func (q *Queries) ListGlobalWebhooks(ctx context.Context) ([]WebhookConfig, erro
 			&i.TriggerTypes,
 			&i.IsEnabled,
 			&i.CreatedAt,
+			&i.ConsecutiveFailures,
+			&i.DisabledReason,
+			&i.Template,
+			&i.ContentType,
+			&i.Headers,
+			&i.PreviousSecret,
+			&i.PreviousSecretExpiresAt,
+			&i.FilterTeamIds,
+			&i.FilterUserIds,
+			&i.FilterWithinHours,
+			&i.UserID,
 		); err != nil {
 			return nil, err
 		}
@@ -237,17 +381,20 @@ func (q *Queries) ListGlobalWebhooks(ctx context.Context) ([]WebhookConfig, erro
 }
 
 const createGlobalWebhook = `-- name: CreateGlobalWebhook :one
-INSERT INTO webhook_configs (name, url, secret, trigger_types, format)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, event_id, name, url, secret, format, trigger_types, is_enabled, created_at
+INSERT INTO webhook_configs (name, url, secret, trigger_types, format, template, content_type, headers)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, event_id, name, url, secret, format, trigger_types, is_enabled, created_at, consecutive_failures, disabled_reason, template, content_type, headers, previous_secret, previous_secret_expires_at, filter_team_ids, filter_user_ids, filter_within_hours, user_id
 `
 
 type CreateGlobalWebhookParams struct {
-	Name         string   `json:"name"`
-	Url          string   `json:"url"`
-	Secret       string   `json:"secret"`
-	TriggerTypes []string `json:"trigger_types"`
-	Format       string   `json:"format"`
+	Name         string          `json:"name"`
+	Url          string          `json:"url"`
+	Secret       string          `json:"secret"`
+	TriggerTypes []string        `json:"trigger_types"`
+	Format       string          `json:"format"`
+	Template     *string         `json:"template"`
+	ContentType  *string         `json:"content_type"`
+	Headers      json.RawMessage `json:"headers"`
 }
 
 func (q *Queries) CreateGlobalWebhook(ctx context.Context, arg CreateGlobalWebhookParams) (WebhookConfig, error) {
@@ -257,6 +404,9 @@ func (q *Queries) CreateGlobalWebhook(ctx context.Context, arg CreateGlobalWebho
 		arg.Secret,
 		arg.TriggerTypes,
 		arg.Format,
+		arg.Template,
+		arg.ContentType,
+		arg.Headers,
 	)
 	var i WebhookConfig
 	err := row.Scan(
@@ -269,13 +419,24 @@ func (q *Queries) CreateGlobalWebhook(ctx context.Context, arg CreateGlobalWebho
 		&i.TriggerTypes,
 		&i.IsEnabled,
 		&i.CreatedAt,
+		&i.ConsecutiveFailures,
+		&i.DisabledReason,
+		&i.Template,
+		&i.ContentType,
+		&i.Headers,
+		&i.PreviousSecret,
+		&i.PreviousSecretExpiresAt,
+		&i.FilterTeamIds,
+		&i.FilterUserIds,
+		&i.FilterWithinHours,
+		&i.UserID,
 	)
 	return i, err
 }
 
 const listActiveGlobalWebhooksForTrigger = `-- name: ListActiveGlobalWebhooksForTrigger :many
-SELECT id, event_id, name, url, secret, format, trigger_types, is_enabled, created_at FROM webhook_configs
-WHERE event_id IS NULL AND is_enabled = true AND $1 = ANY(trigger_types)
+SELECT id, event_id, name, url, secret, format, trigger_types, is_enabled, created_at, consecutive_failures, disabled_reason, template, content_type, headers, previous_secret, previous_secret_expires_at, filter_team_ids, filter_user_ids, filter_within_hours, user_id FROM webhook_configs
+WHERE event_id IS NULL AND user_id IS NULL AND is_enabled = true AND $1 = ANY(trigger_types)
 `
 
 func (q *Queries) ListActiveGlobalWebhooksForTrigger(ctx context.Context, triggerType string) ([]WebhookConfig, error) {
@@ -297,6 +458,186 @@ func (q *Queries) ListActiveGlobalWebhooksForTrigger(ctx context.Context, trigge
 			&i.TriggerTypes,
 			&i.IsEnabled,
 			&i.CreatedAt,
+			&i.ConsecutiveFailures,
+			&i.DisabledReason,
+			&i.Template,
+			&i.ContentType,
+			&i.Headers,
+			&i.PreviousSecret,
+			&i.PreviousSecretExpiresAt,
+			&i.FilterTeamIds,
+			&i.FilterUserIds,
+			&i.FilterWithinHours,
+			&i.UserID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhooksByUser = `-- name: ListWebhooksByUser :many
+SELECT id, event_id, name, url, secret, format, trigger_types, is_enabled, created_at, consecutive_failures, disabled_reason, template, content_type, headers, previous_secret, previous_secret_expires_at, filter_team_ids, filter_user_ids, filter_within_hours, user_id FROM webhook_configs WHERE user_id = $1 ORDER BY name
+`
+
+func (q *Queries) ListWebhooksByUser(ctx context.Context, userID uuid.UUID) ([]WebhookConfig, error) {
+	rows, err := q.db.Query(ctx, listWebhooksByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookConfig{}
+	for rows.Next() {
+		var i WebhookConfig
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.Name,
+			&i.Url,
+			&i.Secret,
+			&i.Format,
+			&i.TriggerTypes,
+			&i.IsEnabled,
+			&i.CreatedAt,
+			&i.ConsecutiveFailures,
+			&i.DisabledReason,
+			&i.Template,
+			&i.ContentType,
+			&i.Headers,
+			&i.PreviousSecret,
+			&i.PreviousSecretExpiresAt,
+			&i.FilterTeamIds,
+			&i.FilterUserIds,
+			&i.FilterWithinHours,
+			&i.UserID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countWebhooksByUser = `-- name: CountWebhooksByUser :one
+SELECT COUNT(*) FROM webhook_configs WHERE user_id = $1
+`
+
+func (q *Queries) CountWebhooksByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countWebhooksByUser, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const lockUserWebhooks = `-- name: LockUserWebhooks :exec
+SELECT pg_advisory_xact_lock(hashtextextended('webhooks/' || $1::text, 0))
+`
+
+// LockUserWebhooks serializes creating personal webhooks of a user until the
+// end of the transaction, so the per-user limit holds.
+func (q *Queries) LockUserWebhooks(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, lockUserWebhooks, userID)
+	return err
+}
+
+const createPersonalWebhook = `-- name: CreatePersonalWebhook :one
+INSERT INTO webhook_configs (user_id, name, url, secret, trigger_types, format, template, content_type, headers)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, event_id, name, url, secret, format, trigger_types, is_enabled, created_at, consecutive_failures, disabled_reason, template, content_type, headers, previous_secret, previous_secret_expires_at, filter_team_ids, filter_user_ids, filter_within_hours, user_id
+`
+
+type CreatePersonalWebhookParams struct {
+	UserID       uuid.UUID       `json:"user_id"`
+	Name         string          `json:"name"`
+	Url          string          `json:"url"`
+	Secret       string          `json:"secret"`
+	TriggerTypes []string        `json:"trigger_types"`
+	Format       string          `json:"format"`
+	Template     *string         `json:"template"`
+	ContentType  *string         `json:"content_type"`
+	Headers      json.RawMessage `json:"headers"`
+}
+
+func (q *Queries) CreatePersonalWebhook(ctx context.Context, arg CreatePersonalWebhookParams) (WebhookConfig, error) {
+	row := q.db.QueryRow(ctx, createPersonalWebhook,
+		arg.UserID,
+		arg.Name,
+		arg.Url,
+		arg.Secret,
+		arg.TriggerTypes,
+		arg.Format,
+		arg.Template,
+		arg.ContentType,
+		arg.Headers,
+	)
+	var i WebhookConfig
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.Name,
+		&i.Url,
+		&i.Secret,
+		&i.Format,
+		&i.TriggerTypes,
+		&i.IsEnabled,
+		&i.CreatedAt,
+		&i.ConsecutiveFailures,
+		&i.DisabledReason,
+		&i.Template,
+		&i.ContentType,
+		&i.Headers,
+		&i.PreviousSecret,
+		&i.PreviousSecretExpiresAt,
+		&i.FilterTeamIds,
+		&i.FilterUserIds,
+		&i.FilterWithinHours,
+		&i.UserID,
+	)
+	return i, err
+}
+
+const listActivePersonalWebhooksForTrigger = `-- name: ListActivePersonalWebhooksForTrigger :many
+SELECT id, event_id, name, url, secret, format, trigger_types, is_enabled, created_at, consecutive_failures, disabled_reason, template, content_type, headers, previous_secret, previous_secret_expires_at, filter_team_ids, filter_user_ids, filter_within_hours, user_id FROM webhook_configs
+WHERE user_id = ANY($1::UUID[]) AND is_enabled = true AND $2 = ANY(trigger_types)
+`
+
+func (q *Queries) ListActivePersonalWebhooksForTrigger(ctx context.Context, userIds []uuid.UUID, triggerType string) ([]WebhookConfig, error) {
+	rows, err := q.db.Query(ctx, listActivePersonalWebhooksForTrigger, userIds, triggerType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookConfig{}
+	for rows.Next() {
+		var i WebhookConfig
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.Name,
+			&i.Url,
+			&i.Secret,
+			&i.Format,
+			&i.TriggerTypes,
+			&i.IsEnabled,
+			&i.CreatedAt,
+			&i.ConsecutiveFailures,
+			&i.DisabledReason,
+			&i.Template,
+			&i.ContentType,
+			&i.Headers,
+			&i.PreviousSecret,
+			&i.PreviousSecretExpiresAt,
+			&i.FilterTeamIds,
+			&i.FilterUserIds,
+			&i.FilterWithinHours,
+			&i.UserID,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/service"
+)
+
+// bearerToken returns the token of an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// remoteIP returns the client IP of a request, without the port.
+func remoteIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// eventTokenPaths are the paths outside /api/events/{slug}/ that a token
+// restricted to an event can read. Everything else outside the event, like
+// the user's shifts or notifications, spans all events.
+var eventTokenPaths = map[string]bool{
+	"/api/auth/me": true,
+}
+
+// checkAPITokenAccess limits a request made with an API token to the token's
+// event and scopes. Any token can read; writes to shifts and availability
+// need their write scope or admin, all other writes need admin. Tokens can't
+// manage API tokens, so a leaked token can't be used to mint new ones.
+// Tokens restricted to an event can't reach other paths except
+// eventTokenPaths.
+func checkAPITokenAccess(token *service.APITokenAuth, method, path string) error {
+	if strings.HasPrefix(path, "/api/users/me/api-tokens") || strings.HasPrefix(path, "/api/admin/api-tokens") {
+		return model.NewDomainError(model.ErrForbidden, "API tokens can't be managed with an API token")
+	}
+
+	read := method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+	slug, rest := splitEventPath(path)
+	if token.EventSlug != nil {
+		inEvent := slug == *token.EventSlug
+		if slug == "" && read {
+			inEvent = eventTokenPaths[strings.TrimSuffix(path, "/")]
+		}
+		if !inEvent {
+			return model.NewDomainError(model.ErrForbidden, "token is restricted to event "+*token.EventSlug)
+		}
+	}
+
+	if read {
+		return nil
+	}
+	// Starting an export job only reads
+	if slug != "" && rest == "export/jobs" {
+		return nil
+	}
+
+	scope := service.APIScopeAdmin
+	switch {
+	case slug != "" && (rest == "shifts" || strings.HasPrefix(rest, "shifts/")):
+		scope = service.APIScopeShiftsWrite
+	case slug != "" && (rest == "availability" || strings.HasPrefix(rest, "availability/")):
+		scope = service.APIScopeAvailabilityWrite
+	}
+	if !token.HasScope(scope) && !token.HasScope(service.APIScopeAdmin) {
+		return model.NewDomainError(model.ErrForbidden, fmt.Sprintf("token lacks the %s scope", scope))
+	}
+	return nil
+}
+
+// splitEventPath splits /api/events/{slug}/{rest} into slug and rest. slug is
+// empty for paths outside an event.
+func splitEventPath(path string) (slug, rest string) {
+	after, ok := strings.CutPrefix(path, "/api/events/")
+	if !ok {
+		return "", ""
+	}
+	slug, rest, _ = strings.Cut(after, "/")
+	return slug, strings.TrimSuffix(rest, "/")
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/echtkpvl/rncasp/internal/service"
+)
+
+func TestCheckAPITokenAccess(t *testing.T) {
+	camp := "camp"
+	readOnly := &service.APITokenAuth{Scopes: []string{service.APIScopeRead}}
+	shifts := &service.APITokenAuth{Scopes: []string{service.APIScopeShiftsWrite}}
+	admin := &service.APITokenAuth{Scopes: []string{service.APIScopeAdmin}}
+	campShifts := &service.APITokenAuth{Scopes: []string{service.APIScopeShiftsWrite}, EventSlug: &camp}
+	campAdmin := &service.APITokenAuth{Scopes: []string{service.APIScopeAdmin}, EventSlug: &camp}
+
+	tests := []struct {
+		name   string
+		token  *service.APITokenAuth
+		method string
+		path   string
+		ok     bool
+	}{
+		{"read", readOnly, http.MethodGet, "/api/events/camp/shifts", true},
+		{"read cannot write", readOnly, http.MethodPost, "/api/events/camp/shifts", false},
+		{"export job", readOnly, http.MethodPost, "/api/events/camp/export/jobs", true},
+		{"shift write", shifts, http.MethodPut, "/api/events/camp/shifts/1", true},
+		{"shift scope on availability", shifts, http.MethodPut, "/api/events/camp/availability/mine", false},
+		{"shift scope on coverage", shifts, http.MethodPost, "/api/events/camp/coverage", false},
+		{"admin writes anything", admin, http.MethodPut, "/api/admin/settings/app_name", true},
+		{"admin on availability", admin, http.MethodPut, "/api/events/camp/availability/mine", true},
+		{"no token management", admin, http.MethodPost, "/api/users/me/api-tokens", false},
+		{"no token listing", admin, http.MethodGet, "/api/admin/api-tokens", false},
+		{"own event", campShifts, http.MethodPost, "/api/events/camp/shifts", true},
+		{"other event read", campShifts, http.MethodGet, "/api/events/other/shifts", false},
+		{"other event write", campShifts, http.MethodPost, "/api/events/other/shifts", false},
+		{"own profile", campShifts, http.MethodGet, "/api/auth/me", true},
+		{"own shifts in all events", campShifts, http.MethodGet, "/api/users/me/shifts", false},
+		{"notifications", campShifts, http.MethodGet, "/api/notifications", false},
+		{"users", campShifts, http.MethodGet, "/api/users", false},
+		{"event list", campShifts, http.MethodGet, "/api/events", false},
+		{"export jobs", campShifts, http.MethodGet, "/api/export/jobs", false},
+		{"admin", campAdmin, http.MethodGet, "/api/admin/settings", false},
+		{"outside event write", campAdmin, http.MethodPut, "/api/auth/me", false},
+		{"unrestricted outside event read", readOnly, http.MethodGet, "/api/users/me/shifts", true},
+	}
+	for _, tt := range tests {
+		err := checkAPITokenAccess(tt.token, tt.method, tt.path)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: err = %v, want ok = %v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/events", nil)
+	if _, ok := bearerToken(r); ok {
+		t.Error("token found without header")
+	}
+
+	r.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	if _, ok := bearerToken(r); ok {
+		t.Error("basic auth taken as bearer token")
+	}
+
+	r.Header.Set("Authorization", "bearer rncasp_abc")
+	if token, ok := bearerToken(r); !ok || token != "rncasp_abc" {
+		t.Errorf("token = %q, %v", token, ok)
+	}
+}
@@ -16,12 +16,35 @@ const (
 	roleKey     contextKey = "role"
 )
 
-// Authenticate extracts the session token from the cookie and validates it.
+// Authenticate extracts the API token from the Authorization header, or else
+// the session token from the cookie, and validates it.
 // If valid, it injects user info into the request context.
 // It does NOT reject unauthenticated requests - use RequireAuth for that.
-func Authenticate(authService *service.AuthService, cookieName string) func(http.Handler) http.Handler {
+// Requests with an API token outside its scopes or event are rejected with 403.
+func Authenticate(authService *service.AuthService, apiTokenService *service.APITokenService, cookieName string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if rawToken, ok := bearerToken(r); ok {
+				token, err := apiTokenService.Validate(r.Context(), rawToken, remoteIP(r))
+				if err != nil {
+					// Invalid token = treat as anonymous
+					next.ServeHTTP(w, r)
+					return
+				}
+				if err := checkAPITokenAccess(token, r.Method, r.URL.Path); err != nil {
+					model.ErrorResponse(w, err)
+					return
+				}
+
+				ctx := r.Context()
+				ctx = context.WithValue(ctx, userIDKey, token.UserID)
+				ctx = context.WithValue(ctx, usernameKey, token.Username)
+				ctx = context.WithValue(ctx, roleKey, token.EffectiveRole())
+
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			cookie, err := r.Cookie(cookieName)
 			if err != nil {
 				// No cookie = anonymous request, continue without user context
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/echtkpvl/rncasp/internal/handler"
+	"github.com/echtkpvl/rncasp/internal/outbound"
 	"github.com/echtkpvl/rncasp/internal/pdf"
 	"github.com/echtkpvl/rncasp/internal/repository"
 	"github.com/echtkpvl/rncasp/internal/server/middleware"
@@ -39,30 +40,65 @@ func (s *Server) setupRoutes() http.Handler {
 	sseBroker := sse.NewBroker(s.rdb, s.logger)
 	s.sseBroker = sseBroker
 
+	// Outbound requests to user-configured URLs go through one policy
+	outboundPolicy := outbound.New(outbound.Config{
+		Allow:        s.cfg.Outbound.Allow,
+		Deny:         s.cfg.Outbound.Deny,
+		HTTPSOnly:    s.cfg.Outbound.HTTPSOnly,
+		MaxRedirects: s.cfg.Outbound.MaxRedirects,
+	})
+
 	// Initialize services
 	authService := service.NewAuthService(queries, s.rdb, &s.cfg.Auth, s.logger)
-	oauthService := service.NewOAuthService(queries, s.rdb, &s.cfg.App, &s.cfg.Auth, s.logger)
+	oauthService := service.NewOAuthService(queries, s.rdb, &s.cfg.App, &s.cfg.Auth, s.logger, outboundPolicy)
 	teamService := service.NewTeamService(queries, s.logger)
-	notificationService := service.NewNotificationService(queries, s.logger)
-	webhookService := service.NewWebhookService(queries, s.logger)
+	notificationService := service.NewNotificationService(queries, s.logger, sseBroker)
+	webhookService := service.NewWebhookService(s.db, queries, s.logger, outboundPolicy, s.cfg.App.BaseURL, s.cfg.App.WebhookDisableAfter)
 	smtpService := service.NewSMTPService(queries, s.logger)
-	availabilityService := service.NewAvailabilityService(queries, s.logger)
+	emailOutbox := service.NewEmailOutboxService(queries, smtpService, s.logger)
+	availabilityService := service.NewAvailabilityService(s.db, queries, s.logger, outboundPolicy)
 	userService := service.NewUserService(queries, s.logger)
-	pdfGen := pdf.NewPDFGenerator(s.logger)
+	pdfGen := pdf.NewPDFGenerator(s.logger, s.cfg.App.PDFRenderer)
 	exportService := service.NewExportService(queries, s.logger, pdfGen)
+	exportJobService := service.NewExportJobService(queries, s.rdb, s.logger, exportService, s.cfg.App.ExportMaxConcurrent, s.cfg.App.ExportCacheTTL)
 	auditService := service.NewAuditService(queries, s.logger)
 	appSettingsService := service.NewAppSettingsService(queries, s.logger)
 	cleanupService := service.NewCleanupService(queries, s.logger)
 	eventService := service.NewEventService(queries, s.logger, sseBroker)
 	shiftService := service.NewShiftService(queries, s.logger, sseBroker)
+	reportService := service.NewReportService(queries, s.logger, exportService, exportJobService, smtpService)
+	announcementService := service.NewAnnouncementService(queries, s.logger, notificationService, webhookService)
+	pushService := service.NewPushService(queries, s.logger, outboundPolicy, s.cfg.App.VAPIDSubject())
+	apiTokenService := service.NewAPITokenService(queries, s.logger)
 
 	// Start background cleanup scheduler
 	s.cleanupService = cleanupService
 	go cleanupService.Start(context.Background())
 
-	// Wire SMTP and webhooks into auth service for registration notifications
+	// Start background sync of subscribed availability calendars
+	s.icalSync = service.NewICalSyncService(availabilityService, s.cfg.App.ICalSyncInterval, s.logger)
+	go s.icalSync.Start(context.Background())
+
+	// Start background delivery of queued notification emails
+	s.emailOutbox = emailOutbox
+	go emailOutbox.Start(context.Background())
+
+	// Start background delivery of queued webhook deliveries
+	s.webhookService = webhookService
+	go webhookService.Start(context.Background())
+
+	// Start background delivery of notification digests
+	s.notificationDigests = service.NewNotificationDigestService(notificationService, s.cfg.App.DigestHour, s.logger)
+	go s.notificationDigests.Start(context.Background())
+
+	// Start background delivery of scheduled reports
+	s.reportScheduler = service.NewReportSchedulerService(reportService, s.logger)
+	go s.reportScheduler.Start(context.Background())
+
+	// Wire SMTP and webhooks into auth services for registration and login notifications
 	authService.SetSMTPService(smtpService)
 	authService.SetWebhookService(webhookService)
+	oauthService.SetWebhookService(webhookService)
 
 	// Wire webhooks and audit into app settings service
 	appSettingsService.SetWebhookService(webhookService)
@@ -71,8 +107,21 @@ func (s *Server) setupRoutes() http.Handler {
 	// Wire webhooks and audit into user and team services
 	userService.SetWebhookService(webhookService)
 	userService.SetAuditService(auditService)
+	teamService.SetWebhookService(webhookService)
 	teamService.SetAuditService(auditService)
 
+	// Wire the email channel into notifications
+	notificationService.SetEmailOutbox(emailOutbox, s.cfg.App.BaseURL)
+
+	// Wire the Web Push channel into notifications
+	if err := pushService.LoadKeys(context.Background()); err != nil {
+		s.logger.Error("web push disabled", "error", err)
+	}
+	notificationService.SetPushService(pushService)
+
+	// Notify super admins about webhooks disabled after failing
+	webhookService.SetNotificationService(notificationService)
+
 	// Wire notification, webhook, and audit triggers into event/shift services
 	eventService.SetNotificationService(notificationService)
 	eventService.SetWebhookService(webhookService)
@@ -80,28 +129,34 @@ func (s *Server) setupRoutes() http.Handler {
 	shiftService.SetNotificationService(notificationService)
 	shiftService.SetWebhookService(webhookService)
 	shiftService.SetAuditService(auditService)
+	availabilityService.SetWebhookService(webhookService)
 
 	// Initialize handlers
 	healthHandler := handler.NewHealthHandler(s.db, s.rdb)
-	authHandler := handler.NewAuthHandler(authService, &s.cfg.Auth, s.cfg.IsDev())
+	authHandler := handler.NewAuthHandler(authService, oauthService, &s.cfg.Auth, s.cfg.IsDev())
 	oauthHandler := handler.NewOAuthHandler(oauthService, &s.cfg.Auth, &s.cfg.App)
 	teamHandler := handler.NewTeamHandler(teamService)
 	eventHandler := handler.NewEventHandler(eventService)
 	shiftHandler := handler.NewShiftHandler(shiftService)
 	sseHandler := handler.NewSSEHandler(sseBroker)
 	notificationHandler := handler.NewNotificationHandler(notificationService)
+	pushHandler := handler.NewPushHandler(pushService)
 	webhookHandler := handler.NewWebhookHandler(webhookService, eventService)
+	reportHandler := handler.NewReportHandler(reportService)
+	announcementHandler := handler.NewAnnouncementHandler(announcementService)
 	smtpHandler := handler.NewSMTPHandler(smtpService)
 	availabilityHandler := handler.NewAvailabilityHandler(availabilityService)
 	userHandler := handler.NewUserHandler(userService)
-	exportHandler := handler.NewExportHandler(exportService, s.cfg.App.BaseURL)
+	exportHandler := handler.NewExportHandler(exportService, exportJobService, s.cfg.App.BaseURL)
 	auditHandler := handler.NewAuditHandler(auditService)
 	adminHandler := handler.NewAdminHandler(appSettingsService, cleanupService)
 	adminWebhookHandler := handler.NewAdminWebhookHandler(webhookService)
-	publicHandler := handler.NewPublicHandler(eventService, shiftService, exportService)
+	userWebhookHandler := handler.NewUserWebhookHandler(webhookService)
+	apiTokenHandler := handler.NewAPITokenHandler(apiTokenService)
+	publicHandler := handler.NewPublicHandler(eventService, shiftService, exportService, exportJobService)
 
-	// Authentication middleware (extracts user from cookie, does not reject)
-	r.Use(middleware.Authenticate(authService, s.cfg.Auth.CookieName))
+	// Authentication middleware (extracts user from API token or cookie, does not reject)
+	r.Use(middleware.Authenticate(authService, apiTokenService, s.cfg.Auth.CookieName))
 
 	r.Route("/api", func(r chi.Router) {
 		// Public endpoints
@@ -170,10 +225,18 @@ func (s *Server) setupRoutes() http.Handler {
 			r.Use(middleware.RequireAuth)
 			r.Get("/", notificationHandler.List)
 			r.Get("/unread-count", notificationHandler.CountUnread)
+			r.Get("/sse", sseHandler.SubscribeNotifications)
 			r.Post("/{notificationId}/read", notificationHandler.MarkRead)
 			r.Post("/read-all", notificationHandler.MarkAllRead)
 			r.Get("/preferences", notificationHandler.GetPreferences)
 			r.Put("/preferences", notificationHandler.UpdatePreference)
+			r.Get("/delivery", notificationHandler.GetDeliveryModes)
+			r.Put("/delivery", notificationHandler.UpdateDeliveryMode)
+			r.Get("/events", notificationHandler.ListEventStates)
+			r.Get("/push/public-key", pushHandler.GetPublicKey)
+			r.Get("/push/subscriptions", pushHandler.ListSubscriptions)
+			r.Post("/push/subscriptions", pushHandler.Subscribe)
+			r.Delete("/push/subscriptions/{subscriptionId}", pushHandler.Unsubscribe)
 		})
 
 		// SMTP configuration (super-admin only)
@@ -191,6 +254,28 @@ func (s *Server) setupRoutes() http.Handler {
 			r.Get("/", userHandler.List)
 			r.Get("/search", userHandler.Search)
 			r.Get("/me/shifts", shiftHandler.ListByUser)
+
+			// Personal webhooks for the user's own shifts
+			r.Route("/me/webhooks", func(r chi.Router) {
+				r.Get("/", userWebhookHandler.List)
+				r.Post("/", userWebhookHandler.Create)
+				r.Get("/triggers", userWebhookHandler.Triggers)
+				r.Post("/preview", userWebhookHandler.Preview)
+				r.Put("/{webhookId}", userWebhookHandler.Update)
+				r.Delete("/{webhookId}", userWebhookHandler.Delete)
+				r.Post("/{webhookId}/test", userWebhookHandler.Test)
+				r.Post("/{webhookId}/rotate-secret", userWebhookHandler.RotateSecret)
+				r.Get("/{webhookId}/deliveries", userWebhookHandler.ListDeliveries)
+				r.Get("/{webhookId}/deliveries/{deliveryId}", userWebhookHandler.GetDelivery)
+				r.Post("/{webhookId}/deliveries/{deliveryId}/redeliver", userWebhookHandler.Redeliver)
+			})
+
+			// API tokens for scripts
+			r.Route("/me/api-tokens", func(r chi.Router) {
+				r.Get("/", apiTokenHandler.List)
+				r.Post("/", apiTokenHandler.Create)
+				r.Delete("/{tokenId}", apiTokenHandler.Revoke)
+			})
 			r.Get("/{userId}", userHandler.GetByID)
 
 			// User management: super-admin only
@@ -209,13 +294,14 @@ func (s *Server) setupRoutes() http.Handler {
 			r.Use(middleware.RequireAuth)
 			r.Get("/", exportHandler.ListTokens)
 			r.Post("/", exportHandler.CreateToken)
+			r.Patch("/{tokenId}", exportHandler.UpdateToken)
 			r.Delete("/{tokenId}", exportHandler.RevokeToken)
 		})
 
 		// Audit log (super-admin only)
 		r.With(middleware.RequireAuth, middleware.RequireSuperAdmin).Get("/audit-log", auditHandler.List)
 
-		// Admin: app settings + dashboard + global webhooks (super-admin only)
+		// Admin: app settings + dashboard + API tokens + global webhooks (super-admin only)
 		r.Route("/admin", func(r chi.Router) {
 			r.Use(middleware.RequireAuth)
 			r.Use(middleware.RequireSuperAdmin)
@@ -225,12 +311,20 @@ func (s *Server) setupRoutes() http.Handler {
 			r.Delete("/settings/{key}", adminHandler.DeleteSetting)
 			r.Get("/stats", adminHandler.DashboardStats)
 			r.Post("/cleanup", adminHandler.RunCleanup)
+			r.Get("/api-tokens", apiTokenHandler.ListAll)
+			r.Delete("/api-tokens/{tokenId}", apiTokenHandler.RevokeAny)
 			r.Route("/webhooks", func(r chi.Router) {
 				r.Get("/", adminWebhookHandler.List)
 				r.Post("/", adminWebhookHandler.Create)
+				r.Get("/triggers", adminWebhookHandler.Triggers)
+				r.Post("/preview", adminWebhookHandler.Preview)
 				r.Put("/{webhookId}", adminWebhookHandler.Update)
 				r.Delete("/{webhookId}", adminWebhookHandler.Delete)
 				r.Post("/{webhookId}/test", adminWebhookHandler.Test)
+				r.Post("/{webhookId}/rotate-secret", adminWebhookHandler.RotateSecret)
+				r.Get("/{webhookId}/deliveries", adminWebhookHandler.ListDeliveries)
+				r.Get("/{webhookId}/deliveries/{deliveryId}", adminWebhookHandler.GetDelivery)
+				r.Post("/{webhookId}/deliveries/{deliveryId}/redeliver", adminWebhookHandler.Redeliver)
 			})
 		})
 
@@ -244,6 +338,19 @@ func (s *Server) setupRoutes() http.Handler {
 			r.Get("/export/csv", publicHandler.ExportCSV)
 			r.Get("/export/ical", publicHandler.ExportICal)
 			r.Get("/export/pdf", publicHandler.ExportPDF)
+			r.Post("/export/jobs", publicHandler.CreateJob)
+			r.Get("/announcements", announcementHandler.ListPublic)
+		})
+
+		// Public export jobs (only jobs started through the public endpoint)
+		r.Get("/public/export/jobs/{jobId}", publicHandler.GetJob)
+		r.Get("/public/export/jobs/{jobId}/download", publicHandler.DownloadJob)
+
+		// Export jobs (authenticated; visible to their creator and super-admins)
+		r.Route("/export/jobs/{jobId}", func(r chi.Router) {
+			r.Use(middleware.RequireAuth)
+			r.Get("/", exportHandler.GetJob)
+			r.Get("/download", exportHandler.DownloadJob)
 		})
 
 		// Events endpoints
@@ -262,6 +369,11 @@ func (s *Server) setupRoutes() http.Handler {
 				// SSE for this event
 				r.Get("/sse", sseHandler.Subscribe)
 
+				// The current user's notification settings for this event
+				r.Get("/notifications", notificationHandler.GetEventSettings)
+				r.Put("/notifications/state", notificationHandler.SetEventState)
+				r.Put("/notifications/preferences", notificationHandler.UpdateEventPreference)
+
 				// Lock/public toggles: super-admin only
 				r.With(middleware.RequireSuperAdmin).Put("/lock", eventHandler.SetLocked)
 				r.With(middleware.RequireSuperAdmin).Put("/public", eventHandler.SetPublic)
@@ -306,22 +418,48 @@ func (s *Server) setupRoutes() http.Handler {
 				r.Get("/availability", availabilityHandler.ListByEvent)
 				r.Get("/availability/mine", availabilityHandler.ListMine)
 				r.Put("/availability/mine", availabilityHandler.SetMine)
+				r.Post("/availability/mine/import", availabilityHandler.ImportMine)
+				r.Get("/availability/mine/ical-feed", availabilityHandler.GetMyFeed)
+				r.Put("/availability/mine/ical-feed", availabilityHandler.SetMyFeed)
+				r.Delete("/availability/mine/ical-feed", availabilityHandler.DeleteMyFeed)
 				r.With(middleware.RequireEventAdminOrSuperAdmin(eventService)).Put("/availability/{userId}", availabilityHandler.SetForUser)
 
 				// Export: CSV and iCal downloads
 				r.Get("/export/csv", exportHandler.ExportCSV)
 				r.Get("/export/ical", exportHandler.ExportICal)
 				r.Get("/export/pdf", exportHandler.ExportPDF)
+				r.Post("/export/jobs", exportHandler.CreateJob)
 
 				// Webhooks: event admin or super-admin
 				r.Route("/webhooks", func(r chi.Router) {
 					r.Use(middleware.RequireEventAdminOrSuperAdmin(eventService))
 					r.Get("/", webhookHandler.List)
 					r.Post("/", webhookHandler.Create)
+					r.Get("/triggers", webhookHandler.Triggers)
+					r.Post("/preview", webhookHandler.Preview)
 					r.Put("/{webhookId}", webhookHandler.Update)
 					r.Delete("/{webhookId}", webhookHandler.Delete)
 					r.Post("/{webhookId}/test", webhookHandler.Test)
+					r.Post("/{webhookId}/rotate-secret", webhookHandler.RotateSecret)
+					r.Get("/{webhookId}/deliveries", webhookHandler.ListDeliveries)
+					r.Get("/{webhookId}/deliveries/{deliveryId}", webhookHandler.GetDelivery)
+					r.Post("/{webhookId}/deliveries/{deliveryId}/redeliver", webhookHandler.Redeliver)
 				})
+
+				// Scheduled email reports: event admin or super-admin
+				r.Route("/reports", func(r chi.Router) {
+					r.Use(middleware.RequireEventAdminOrSuperAdmin(eventService))
+					r.Get("/", reportHandler.List)
+					r.Post("/", reportHandler.Create)
+					r.Put("/{reportId}", reportHandler.Update)
+					r.Delete("/{reportId}", reportHandler.Delete)
+					r.Post("/{reportId}/send", reportHandler.Send)
+				})
+
+				// Announcements: any authenticated user can read, admins can send
+				r.Get("/announcements", announcementHandler.List)
+				r.With(middleware.RequireEventAdminOrSuperAdmin(eventService)).Post("/announcements", announcementHandler.Create)
+				r.With(middleware.RequireEventAdminOrSuperAdmin(eventService)).Delete("/announcements/{announcementId}", announcementHandler.Delete)
 			})
 		})
 	})
@@ -21,13 +21,18 @@ import (
 )
 
 type Server struct {
-	cfg            *config.Config
-	db             *pgxpool.Pool
-	rdb            *redis.Client
-	router         http.Handler
-	logger         *slog.Logger
-	sseBroker      *sse.Broker
-	cleanupService *service.CleanupService
+	cfg                 *config.Config
+	db                  *pgxpool.Pool
+	rdb                 *redis.Client
+	router              http.Handler
+	logger              *slog.Logger
+	sseBroker           *sse.Broker
+	cleanupService      *service.CleanupService
+	icalSync            *service.ICalSyncService
+	reportScheduler     *service.ReportSchedulerService
+	emailOutbox         *service.EmailOutboxService
+	webhookService      *service.WebhookService
+	notificationDigests *service.NotificationDigestService
 }
 
 func New(cfg *config.Config, logger *slog.Logger) (*Server, error) {
@@ -151,6 +156,21 @@ func (s *Server) Start() error {
 	if s.cleanupService != nil {
 		s.cleanupService.Stop()
 	}
+	if s.icalSync != nil {
+		s.icalSync.Stop()
+	}
+	if s.reportScheduler != nil {
+		s.reportScheduler.Stop()
+	}
+	if s.notificationDigests != nil {
+		s.notificationDigests.Stop()
+	}
+	if s.emailOutbox != nil {
+		s.emailOutbox.Stop()
+	}
+	if s.webhookService != nil {
+		s.webhookService.Stop()
+	}
 	if s.sseBroker != nil {
 		s.sseBroker.Close()
 	}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// AnnouncementService lets event admins send messages to the volunteers of an
+// event, optionally limited to some teams or to those working in a time
+// window. Announcements are delivered as notifications and to the event's
+// webhooks, and kept in a per-event list.
+type AnnouncementService struct {
+	queries             *repository.Queries
+	logger              *slog.Logger
+	notificationService *NotificationService
+	webhookService      *WebhookService
+}
+
+func NewAnnouncementService(queries *repository.Queries, logger *slog.Logger, notificationService *NotificationService, webhookService *WebhookService) *AnnouncementService {
+	return &AnnouncementService{
+		queries:             queries,
+		logger:              logger,
+		notificationService: notificationService,
+		webhookService:      webhookService,
+	}
+}
+
+const (
+	maxAnnouncementTitle = 200
+	maxAnnouncementBody  = 5000
+)
+
+type AnnouncementResponse struct {
+	ID             string   `json:"id"`
+	EventID        string   `json:"event_id"`
+	Title          string   `json:"title"`
+	Body           string   `json:"body"`
+	TeamIDs        []string `json:"team_ids"`
+	WindowStart    *string  `json:"window_start"`
+	WindowEnd      *string  `json:"window_end"`
+	RecipientCount int32    `json:"recipient_count"`
+	CreatedBy      *string  `json:"created_by"`
+	CreatedAt      string   `json:"created_at"`
+}
+
+// PublicAnnouncementResponse is an announcement as shown on the public event
+// page.
+type PublicAnnouncementResponse struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	CreatedAt string `json:"created_at"`
+}
+
+type CreateAnnouncementInput struct {
+	Title       string
+	Body        string
+	TeamIDs     []string
+	WindowStart *time.Time
+	WindowEnd   *time.Time
+}
+
+func announcementToResponse(a repository.Announcement) AnnouncementResponse {
+	resp := AnnouncementResponse{
+		ID:             a.ID.String(),
+		EventID:        a.EventID.String(),
+		Title:          a.Title,
+		Body:           a.Body,
+		TeamIDs:        uuidStrings(a.TeamIds),
+		RecipientCount: a.RecipientCount,
+		CreatedAt:      a.CreatedAt.Format(time.RFC3339),
+	}
+	if a.WindowStart != nil && a.WindowEnd != nil {
+		start, end := a.WindowStart.Format(time.RFC3339), a.WindowEnd.Format(time.RFC3339)
+		resp.WindowStart, resp.WindowEnd = &start, &end
+	}
+	if a.CreatedBy != nil {
+		s := a.CreatedBy.String()
+		resp.CreatedBy = &s
+	}
+	return resp
+}
+
+func (s *AnnouncementService) ListByEvent(ctx context.Context, slug string) ([]AnnouncementResponse, error) {
+	event, err := s.getEvent(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	announcements, err := s.queries.ListAnnouncementsByEvent(ctx, event.ID)
+	if err != nil {
+		return nil, fmt.Errorf("listing announcements: %w", err)
+	}
+
+	result := make([]AnnouncementResponse, len(announcements))
+	for i, a := range announcements {
+		result[i] = announcementToResponse(a)
+	}
+	return result, nil
+}
+
+// ListPublic returns the announcements addressed to the whole event, if the
+// event is public.
+func (s *AnnouncementService) ListPublic(ctx context.Context, slug string) ([]PublicAnnouncementResponse, error) {
+	event, err := s.getEvent(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if !event.IsPublic {
+		return nil, model.NewDomainError(model.ErrNotFound, "event not found")
+	}
+
+	announcements, err := s.queries.ListPublicAnnouncementsByEvent(ctx, event.ID)
+	if err != nil {
+		return nil, fmt.Errorf("listing announcements: %w", err)
+	}
+
+	result := make([]PublicAnnouncementResponse, len(announcements))
+	for i, a := range announcements {
+		result[i] = PublicAnnouncementResponse{
+			ID:        a.ID.String(),
+			Title:     a.Title,
+			Body:      a.Body,
+			CreatedAt: a.CreatedAt.Format(time.RFC3339),
+		}
+	}
+	return result, nil
+}
+
+// Create stores an announcement and delivers it to its recipients in the
+// background.
+func (s *AnnouncementService) Create(ctx context.Context, slug string, authorID uuid.UUID, input CreateAnnouncementInput) (AnnouncementResponse, error) {
+	event, err := s.getEvent(ctx, slug)
+	if err != nil {
+		return AnnouncementResponse{}, err
+	}
+
+	input.Title = strings.TrimSpace(input.Title)
+	input.Body = strings.TrimSpace(input.Body)
+	if input.Title == "" {
+		return AnnouncementResponse{}, model.NewFieldError(model.ErrInvalidInput, "title", "title is required")
+	}
+	if len(input.Title) > maxAnnouncementTitle {
+		return AnnouncementResponse{}, model.NewFieldError(model.ErrInvalidInput, "title", fmt.Sprintf("must be at most %d characters", maxAnnouncementTitle))
+	}
+	if input.Body == "" {
+		return AnnouncementResponse{}, model.NewFieldError(model.ErrInvalidInput, "body", "body is required")
+	}
+	if len(input.Body) > maxAnnouncementBody {
+		return AnnouncementResponse{}, model.NewFieldError(model.ErrInvalidInput, "body", fmt.Sprintf("must be at most %d characters", maxAnnouncementBody))
+	}
+	if (input.WindowStart == nil) != (input.WindowEnd == nil) {
+		return AnnouncementResponse{}, model.NewFieldError(model.ErrInvalidInput, "window_end", "window_start and window_end must be set together")
+	}
+	if input.WindowStart != nil && !input.WindowEnd.After(*input.WindowStart) {
+		return AnnouncementResponse{}, model.NewFieldError(model.ErrInvalidInput, "window_end", "window end must be after window start")
+	}
+
+	teamIDs := []uuid.UUID{}
+	if len(input.TeamIDs) > 0 {
+		eventTeams, err := s.queries.ListEventTeams(ctx, event.ID)
+		if err != nil {
+			return AnnouncementResponse{}, fmt.Errorf("listing event teams: %w", err)
+		}
+		inEvent := make(map[uuid.UUID]bool, len(eventTeams))
+		for _, t := range eventTeams {
+			inEvent[t.ID] = true
+		}
+		for _, raw := range input.TeamIDs {
+			id, err := uuid.Parse(raw)
+			if err != nil || !inEvent[id] {
+				return AnnouncementResponse{}, model.NewFieldError(model.ErrInvalidInput, "team_ids", "unknown team: "+raw)
+			}
+			teamIDs = append(teamIDs, id)
+		}
+	}
+
+	shifts, err := s.queries.ListShiftsByEvent(ctx, event.ID)
+	if err != nil {
+		return AnnouncementResponse{}, fmt.Errorf("listing shifts: %w", err)
+	}
+	var watchers []uuid.UUID
+	if s.notificationService != nil {
+		watchers = s.notificationService.eventWatchers(ctx, event.ID)
+	}
+	recipients := announcementRecipients(shifts, watchers, teamIDs, input.WindowStart, input.WindowEnd, authorID)
+
+	announcement, err := s.queries.CreateAnnouncement(ctx, repository.CreateAnnouncementParams{
+		EventID:        event.ID,
+		Title:          input.Title,
+		Body:           input.Body,
+		TeamIds:        teamIDs,
+		WindowStart:    input.WindowStart,
+		WindowEnd:      input.WindowEnd,
+		RecipientCount: int32(len(recipients)),
+		CreatedBy:      &authorID,
+	})
+	if err != nil {
+		return AnnouncementResponse{}, fmt.Errorf("creating announcement: %w", err)
+	}
+
+	resp := announcementToResponse(announcement)
+	s.logger.Info("announcement created", "announcement_id", announcement.ID, "event", slug, "recipients", len(recipients))
+
+	go func() {
+		bgCtx := context.Background()
+		if s.notificationService != nil {
+			msg := NotificationMessage{
+				TriggerType: TriggerAnnouncement,
+				EventName:   event.Name,
+				EventSlug:   event.Slug,
+				Title:       announcement.Title,
+				Body:        announcement.Body,
+			}
+			for _, userID := range recipients {
+				if err := s.notificationService.Notify(bgCtx, userID, &event.ID, msg); err != nil {
+					s.logger.Error("failed to deliver announcement", "error", err, "user_id", userID)
+				}
+			}
+		}
+		if s.webhookService != nil {
+			s.webhookService.Dispatch(bgCtx, event.ID, TriggerAnnouncement, resp)
+		}
+	}()
+
+	return resp, nil
+}
+
+// Delete removes an announcement from the event's list. Notifications that
+// were already delivered are kept.
+func (s *AnnouncementService) Delete(ctx context.Context, slug string, id uuid.UUID) error {
+	event, err := s.getEvent(ctx, slug)
+	if err != nil {
+		return err
+	}
+
+	n, err := s.queries.DeleteAnnouncement(ctx, id, event.ID)
+	if err != nil {
+		return fmt.Errorf("deleting announcement: %w", err)
+	}
+	if n == 0 {
+		return model.NewDomainError(model.ErrNotFound, "announcement not found")
+	}
+	return nil
+}
+
+func (s *AnnouncementService) getEvent(ctx context.Context, slug string) (repository.Event, error) {
+	event, err := s.queries.GetEventBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.Event{}, model.NewDomainError(model.ErrNotFound, "event not found")
+		}
+		return repository.Event{}, fmt.Errorf("fetching event: %w", err)
+	}
+	return event, nil
+}
+
+// announcementRecipients returns the users with a shift matching the
+// announcement's audience, excluding the author. An empty teamIDs matches
+// every team and a nil window every time. Watchers of the event receive
+// announcements addressed to the whole event.
+func announcementRecipients(shifts []repository.ListShiftsByEventRow, watcherIDs, teamIDs []uuid.UUID, windowStart, windowEnd *time.Time, authorID uuid.UUID) []uuid.UUID {
+	teams := make(map[uuid.UUID]bool, len(teamIDs))
+	for _, id := range teamIDs {
+		teams[id] = true
+	}
+
+	var recipients []uuid.UUID
+	seen := map[uuid.UUID]bool{authorID: true}
+	for _, sh := range shifts {
+		if seen[sh.UserID] {
+			continue
+		}
+		if len(teams) > 0 && !teams[sh.TeamID] {
+			continue
+		}
+		if windowStart != nil && (!sh.StartTime.Before(*windowEnd) || !windowStart.Before(sh.EndTime)) {
+			continue
+		}
+		seen[sh.UserID] = true
+		recipients = append(recipients, sh.UserID)
+	}
+
+	if len(teamIDs) == 0 && windowStart == nil {
+		for _, id := range watcherIDs {
+			if !seen[id] {
+				seen[id] = true
+				recipients = append(recipients, id)
+			}
+		}
+	}
+	return recipients
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+)
+
+func TestAnnouncementRecipients(t *testing.T) {
+	at := func(h int) time.Time { return time.Date(2025, 7, 4, h, 0, 0, 0, time.UTC) }
+	author, alice, bob, carol, watcher := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	bar, gate := uuid.New(), uuid.New()
+
+	shifts := []repository.ListShiftsByEventRow{
+		{UserID: alice, TeamID: bar, StartTime: at(8), EndTime: at(12)},
+		{UserID: alice, TeamID: gate, StartTime: at(14), EndTime: at(18)},
+		{UserID: bob, TeamID: gate, StartTime: at(12), EndTime: at(16)},
+		{UserID: carol, TeamID: bar, StartTime: at(18), EndTime: at(22)},
+		{UserID: author, TeamID: bar, StartTime: at(8), EndTime: at(12)},
+	}
+	start, end := at(12), at(14)
+
+	tests := []struct {
+		name       string
+		teamIDs    []uuid.UUID
+		start, end *time.Time
+		want       []uuid.UUID
+	}{
+		{"whole event", nil, nil, nil, []uuid.UUID{alice, bob, carol, watcher}},
+		{"one team", []uuid.UUID{bar}, nil, nil, []uuid.UUID{alice, carol}},
+		{"time window", nil, &start, &end, []uuid.UUID{bob}},
+		{"team and window", []uuid.UUID{bar}, &start, &end, nil},
+	}
+	for _, tt := range tests {
+		got := announcementRecipients(shifts, []uuid.UUID{watcher, bob}, tt.teamIDs, tt.start, tt.end, author)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestAnnouncementNotification(t *testing.T) {
+	msg := NotificationMessage{
+		TriggerType: TriggerAnnouncement,
+		EventName:   "Camp",
+		Title:       "Meeting point moved",
+		Body:        "We now meet in Hall 2.",
+	}
+	for _, lang := range []string{"en", "de"} {
+		title, body := msg.Render(lang)
+		if title != "Camp: Meeting point moved" || body != "We now meet in Hall 2." {
+			t.Errorf("Render(%q) = %q, %q", lang, title, body)
+		}
+	}
+
+	if !channelEnabled(nil, TriggerAnnouncement, ChannelEmail) {
+		t.Error("announcements should be emailed by default")
+	}
+}
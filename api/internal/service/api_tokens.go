@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// API token scopes. Every token can read what its user can read; write scopes
+// allow changes to shifts or availability, and admin allows all other
+// changes. A token never has more rights than its user.
+const (
+	APIScopeRead              = "read"
+	APIScopeShiftsWrite       = "shifts:write"
+	APIScopeAvailabilityWrite = "availability:write"
+	APIScopeAdmin             = "admin"
+)
+
+var apiTokenScopes = []string{APIScopeRead, APIScopeShiftsWrite, APIScopeAvailabilityWrite, APIScopeAdmin}
+
+const (
+	// apiTokenPrefix starts every API token, so leaked tokens are easy to
+	// recognize and bearer tokens easy to tell apart from other credentials.
+	apiTokenPrefix = "rncasp_"
+	// maxAPITokens is how many API tokens a user can have.
+	maxAPITokens = 25
+	// apiTokenTouchInterval limits how often last_used_at is written for a
+	// token in steady use.
+	apiTokenTouchInterval = time.Minute
+)
+
+type APITokenService struct {
+	queries *repository.Queries
+	logger  *slog.Logger
+}
+
+func NewAPITokenService(queries *repository.Queries, logger *slog.Logger) *APITokenService {
+	return &APITokenService{queries: queries, logger: logger}
+}
+
+type APITokenResponse struct {
+	ID         string   `json:"id"`
+	UserID     string   `json:"user_id"`
+	Username   string   `json:"username"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	EventID    *string  `json:"event_id"`
+	EventSlug  *string  `json:"event_slug"`
+	ExpiresAt  *string  `json:"expires_at"`
+	LastUsedAt *string  `json:"last_used_at"`
+	LastUsedIP *string  `json:"last_used_ip"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+// CreatedAPITokenResponse is returned once when a token is created. The token
+// itself can't be shown again.
+type CreatedAPITokenResponse struct {
+	APITokenResponse
+	Token string `json:"token"`
+}
+
+type CreateAPITokenInput struct {
+	Name      string
+	Scopes    []string
+	EventID   *uuid.UUID // restricts the token to one event
+	ExpiresAt *time.Time // nil = never expires
+}
+
+// APITokenAuth is a valid API token and the user it acts for.
+type APITokenAuth struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Username  string
+	Role      string
+	Scopes    []string
+	EventSlug *string // the only event the token may access, if restricted
+}
+
+// HasScope reports whether the token was granted a scope.
+func (t *APITokenAuth) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, scope)
+}
+
+// EffectiveRole is the role requests with the token run as. Tokens of super
+// admins only act as super admin with the admin scope.
+func (t *APITokenAuth) EffectiveRole() string {
+	if t.Role == "super_admin" && !t.HasScope(APIScopeAdmin) {
+		return "user"
+	}
+	return t.Role
+}
+
+// List returns the API tokens of a user, or of all users if userID is nil.
+func (s *APITokenService) List(ctx context.Context, userID *uuid.UUID) ([]APITokenResponse, error) {
+	tokens, err := s.queries.ListAPITokens(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("listing API tokens: %w", err)
+	}
+
+	result := make([]APITokenResponse, len(tokens))
+	for i, t := range tokens {
+		result[i] = apiTokenToResponse(repository.ApiToken{
+			ID:          t.ID,
+			UserID:      t.UserID,
+			Name:        t.Name,
+			TokenPrefix: t.TokenPrefix,
+			Scopes:      t.Scopes,
+			EventID:     t.EventID,
+			ExpiresAt:   t.ExpiresAt,
+			LastUsedAt:  t.LastUsedAt,
+			LastUsedIp:  t.LastUsedIp,
+			CreatedAt:   t.CreatedAt,
+		}, t.Username, t.EventSlug)
+	}
+	return result, nil
+}
+
+// Create generates a new API token for a user.
+func (s *APITokenService) Create(ctx context.Context, userID uuid.UUID, input CreateAPITokenInput) (CreatedAPITokenResponse, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return CreatedAPITokenResponse{}, model.NewFieldError(model.ErrInvalidInput, "name", "name is required")
+	}
+	if len(input.Name) > 255 {
+		return CreatedAPITokenResponse{}, model.NewFieldError(model.ErrInvalidInput, "name", "name must be at most 255 characters")
+	}
+	scopes, err := parseAPITokenScopes(input.Scopes)
+	if err != nil {
+		return CreatedAPITokenResponse{}, err
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return CreatedAPITokenResponse{}, model.NewFieldError(model.ErrInvalidInput, "expires_at", "expiry must be in the future")
+	}
+
+	user, err := s.queries.GetUserByID(ctx, userID)
+	if err != nil {
+		return CreatedAPITokenResponse{}, fmt.Errorf("getting user: %w", err)
+	}
+
+	var eventSlug *string
+	if input.EventID != nil {
+		event, err := s.queries.GetEventByID(ctx, *input.EventID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return CreatedAPITokenResponse{}, model.NewFieldError(model.ErrInvalidInput, "event_id", "event not found")
+			}
+			return CreatedAPITokenResponse{}, fmt.Errorf("getting event: %w", err)
+		}
+		eventSlug = &event.Slug
+	}
+
+	count, err := s.queries.CountAPITokensByUser(ctx, userID)
+	if err != nil {
+		return CreatedAPITokenResponse{}, fmt.Errorf("counting API tokens: %w", err)
+	}
+	if count >= maxAPITokens {
+		return CreatedAPITokenResponse{}, model.NewDomainError(model.ErrInvalidInput, fmt.Sprintf("you can have at most %d API tokens", maxAPITokens))
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return CreatedAPITokenResponse{}, fmt.Errorf("generating token: %w", err)
+	}
+	rawToken := apiTokenPrefix + hex.EncodeToString(tokenBytes)
+
+	token, err := s.queries.CreateAPIToken(ctx, repository.CreateAPITokenParams{
+		UserID:      userID,
+		Name:        input.Name,
+		TokenHash:   hashToken(rawToken),
+		TokenPrefix: rawToken[:len(apiTokenPrefix)+8],
+		Scopes:      scopes,
+		EventID:     input.EventID,
+		ExpiresAt:   input.ExpiresAt,
+	})
+	if err != nil {
+		return CreatedAPITokenResponse{}, fmt.Errorf("creating API token: %w", err)
+	}
+
+	s.logger.Info("API token created", "token_id", token.ID, "user_id", userID, "scopes", scopes)
+	return CreatedAPITokenResponse{
+		APITokenResponse: apiTokenToResponse(token, user.Username, eventSlug),
+		Token:            rawToken,
+	}, nil
+}
+
+// Revoke deletes one of a user's API tokens.
+func (s *APITokenService) Revoke(ctx context.Context, userID, tokenID uuid.UUID) error {
+	n, err := s.queries.DeleteAPIToken(ctx, tokenID, userID)
+	if err != nil {
+		return fmt.Errorf("revoking API token: %w", err)
+	}
+	if n == 0 {
+		return model.NewDomainError(model.ErrNotFound, "token not found")
+	}
+	s.logger.Info("API token revoked", "token_id", tokenID, "user_id", userID)
+	return nil
+}
+
+// RevokeAny deletes an API token of any user.
+func (s *APITokenService) RevokeAny(ctx context.Context, tokenID uuid.UUID) error {
+	n, err := s.queries.DeleteAPITokenByID(ctx, tokenID)
+	if err != nil {
+		return fmt.Errorf("revoking API token: %w", err)
+	}
+	if n == 0 {
+		return model.NewDomainError(model.ErrNotFound, "token not found")
+	}
+	s.logger.Info("API token revoked by admin", "token_id", tokenID)
+	return nil
+}
+
+// Validate looks up an API token sent from ip and records its use.
+func (s *APITokenService) Validate(ctx context.Context, rawToken, ip string) (*APITokenAuth, error) {
+	if !strings.HasPrefix(rawToken, apiTokenPrefix) {
+		return nil, model.NewDomainError(model.ErrUnauthorized, "invalid or expired token")
+	}
+
+	token, err := s.queries.GetAPITokenByHash(ctx, hashToken(rawToken))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.NewDomainError(model.ErrUnauthorized, "invalid or expired token")
+		}
+		return nil, fmt.Errorf("fetching API token: %w", err)
+	}
+	if !token.IsActive {
+		return nil, model.NewDomainError(model.ErrInactiveAccount, "account is deactivated")
+	}
+
+	lastIP := ""
+	if token.LastUsedIp != nil {
+		lastIP = *token.LastUsedIp
+	}
+	if token.LastUsedAt == nil || time.Since(*token.LastUsedAt) > apiTokenTouchInterval || lastIP != ip {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			var usedFrom *string
+			if ip != "" {
+				usedFrom = &ip
+			}
+			if err := s.queries.MarkAPITokenUsed(ctx, token.ID, usedFrom); err != nil {
+				s.logger.Error("failed to record API token use", "error", err, "token_id", token.ID)
+			}
+		}()
+	}
+
+	return &APITokenAuth{
+		ID:        token.ID,
+		UserID:    token.UserID,
+		Username:  token.Username,
+		Role:      token.Role,
+		Scopes:    token.Scopes,
+		EventSlug: token.EventSlug,
+	}, nil
+}
+
+// parseAPITokenScopes checks requested scopes and returns them without
+// duplicates, in a fixed order.
+func parseAPITokenScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, model.NewFieldError(model.ErrInvalidInput, "scopes", "at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(apiTokenScopes, scope) {
+			return nil, model.NewFieldError(model.ErrInvalidInput, "scopes", "unknown scope: "+scope)
+		}
+	}
+	result := make([]string, 0, len(scopes))
+	for _, scope := range apiTokenScopes {
+		if slices.Contains(scopes, scope) {
+			result = append(result, scope)
+		}
+	}
+	return result, nil
+}
+
+func apiTokenToResponse(t repository.ApiToken, username string, eventSlug *string) APITokenResponse {
+	resp := APITokenResponse{
+		ID:         t.ID.String(),
+		UserID:     t.UserID.String(),
+		Username:   username,
+		Name:       t.Name,
+		Prefix:     t.TokenPrefix,
+		Scopes:     t.Scopes,
+		EventSlug:  eventSlug,
+		LastUsedIP: t.LastUsedIp,
+		CreatedAt:  t.CreatedAt.Format(time.RFC3339),
+	}
+	if t.EventID != nil {
+		id := t.EventID.String()
+		resp.EventID = &id
+	}
+	if t.ExpiresAt != nil {
+		s := t.ExpiresAt.Format(time.RFC3339)
+		resp.ExpiresAt = &s
+	}
+	if t.LastUsedAt != nil {
+		s := t.LastUsedAt.Format(time.RFC3339)
+		resp.LastUsedAt = &s
+	}
+	return resp
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseAPITokenScopes(t *testing.T) {
+	scopes, err := parseAPITokenScopes([]string{APIScopeAdmin, APIScopeRead, APIScopeAdmin})
+	if err != nil {
+		t.Fatalf("valid scopes: %v", err)
+	}
+	if want := []string{APIScopeRead, APIScopeAdmin}; !slices.Equal(scopes, want) {
+		t.Errorf("scopes = %v, want %v", scopes, want)
+	}
+
+	if _, err := parseAPITokenScopes(nil); err == nil {
+		t.Error("token without scopes accepted")
+	}
+	if _, err := parseAPITokenScopes([]string{"shifts:delete"}); err == nil {
+		t.Error("unknown scope accepted")
+	}
+}
+
+func TestAPITokenEffectiveRole(t *testing.T) {
+	tests := []struct {
+		role   string
+		scopes []string
+		want   string
+	}{
+		{"super_admin", []string{APIScopeAdmin}, "super_admin"},
+		{"super_admin", []string{APIScopeRead, APIScopeShiftsWrite}, "user"},
+		{"user", []string{APIScopeAdmin}, "user"},
+		{"read_only", []string{APIScopeAdmin}, "read_only"},
+	}
+	for _, tt := range tests {
+		token := APITokenAuth{Role: tt.role, Scopes: tt.scopes}
+		if got := token.EffectiveRole(); got != tt.want {
+			t.Errorf("%s with %v: role = %q, want %q", tt.role, tt.scopes, got, tt.want)
+		}
+	}
+}
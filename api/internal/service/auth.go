@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"html"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/echtkpvl/rncasp/internal/config"
@@ -80,6 +81,7 @@ type UserResponse struct {
 	Language    string  `json:"language"`
 	AccountType string  `json:"account_type"`
 	TimeFormat  string  `json:"time_format"`
+	Phone       *string `json:"phone"`
 	TotpEnabled bool    `json:"totp_enabled"`
 	IsActive    bool    `json:"is_active"`
 	CreatedAt   string  `json:"created_at"`
@@ -96,6 +98,7 @@ func userToResponse(u repository.User) UserResponse {
 		Language:    u.Language,
 		AccountType: u.AccountType,
 		TimeFormat:  u.TimeFormat,
+		Phone:       u.Phone,
 		TotpEnabled: u.TotpEnabled,
 		IsActive:    u.IsActive,
 		CreatedAt:   u.CreatedAt.Format(time.RFC3339),
@@ -300,10 +303,15 @@ type UpdateProfileInput struct {
 	Email       *string
 	Password    *string
 	TimeFormat  *string
+	Phone       *string
 }
 
 // UpdateProfile lets an authenticated user update their own profile fields.
 func (s *AuthService) UpdateProfile(ctx context.Context, userID uuid.UUID, input UpdateProfileInput) (UserResponse, error) {
+	if err := validatePhone(input.Phone); err != nil {
+		return UserResponse{}, err
+	}
+
 	// Hash password if provided
 	if input.Password != nil && *input.Password != "" {
 		hash, err := bcrypt.GenerateFromPassword([]byte(*input.Password), s.cfg.BcryptCost)
@@ -322,6 +330,7 @@ func (s *AuthService) UpdateProfile(ctx context.Context, userID uuid.UUID, input
 		DisplayName: input.DisplayName,
 		Email:       input.Email,
 		TimeFormat:  input.TimeFormat,
+		Phone:       input.Phone,
 	})
 	if err != nil {
 		return UserResponse{}, fmt.Errorf("updating profile: %w", err)
@@ -463,3 +472,20 @@ func validateRegisterInput(input RegisterInput) error {
 	}
 	return nil
 }
+
+// validatePhone accepts digits, spaces and the usual separators; an empty
+// string clears the number.
+func validatePhone(phone *string) error {
+	if phone == nil || *phone == "" {
+		return nil
+	}
+	if len(*phone) > 50 {
+		return model.NewFieldError(model.ErrInvalidInput, "phone", "phone must be at most 50 characters")
+	}
+	for _, r := range *phone {
+		if !strings.ContainsRune("0123456789 +-()/.", r) {
+			return model.NewFieldError(model.ErrInvalidInput, "phone", "phone may only contain digits, spaces and + - ( ) / .")
+		}
+	}
+	return nil
+}
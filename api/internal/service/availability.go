@@ -5,21 +5,41 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"time"
 
 	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/outbound"
 	"github.com/echtkpvl/rncasp/internal/repository"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type AvailabilityService struct {
-	queries *repository.Queries
-	logger  *slog.Logger
+	db             *pgxpool.Pool
+	queries        *repository.Queries
+	logger         *slog.Logger
+	httpClient     *http.Client
+	outbound       *outbound.Policy
+	webhookService *WebhookService
 }
 
-func NewAvailabilityService(queries *repository.Queries, logger *slog.Logger) *AvailabilityService {
-	return &AvailabilityService{queries: queries, logger: logger}
+// NewAvailabilityService creates an availability service. Calendar feeds are
+// fetched through the outbound policy.
+func NewAvailabilityService(db *pgxpool.Pool, queries *repository.Queries, logger *slog.Logger, policy *outbound.Policy) *AvailabilityService {
+	return &AvailabilityService{
+		db:         db,
+		queries:    queries,
+		logger:     logger,
+		httpClient: policy.Client(20*time.Second, MaxICalImportSize),
+		outbound:   policy,
+	}
+}
+
+// SetWebhookService sets the webhook service for trigger dispatch.
+func (s *AvailabilityService) SetWebhookService(ws *WebhookService) {
+	s.webhookService = ws
 }
 
 type AvailabilityResponse struct {
@@ -30,6 +50,7 @@ type AvailabilityResponse struct {
 	EndTime   string  `json:"end_time"`
 	Status    string  `json:"status"`
 	Note      *string `json:"note"`
+	Source    string  `json:"source"`
 }
 
 type AvailabilityWithUserResponse struct {
@@ -51,6 +72,11 @@ type AvailabilityEntry struct {
 	Note      *string
 }
 
+const (
+	availabilitySourceManual = "manual"
+	availabilitySourceICal   = "ical"
+)
+
 var validStatuses = map[string]bool{
 	"available":   true,
 	"preferred":   true,
@@ -83,6 +109,7 @@ func (s *AvailabilityService) ListByEvent(ctx context.Context, slug string) ([]A
 				EndTime:   r.EndTime.Format(time.RFC3339),
 				Status:    r.Status,
 				Note:      r.Note,
+				Source:    r.Source,
 			},
 			Username:     r.Username,
 			UserFullName: r.UserFullName,
@@ -113,8 +140,11 @@ func (s *AvailabilityService) ListByEventAndUser(ctx context.Context, slug strin
 	return result, nil
 }
 
-// SetAvailability replaces all availability entries for a user in an event.
-// This is a "replace all" operation: delete existing, then insert new entries.
+// SetAvailability replaces the manually entered availability for a user in an
+// event. Entries imported from a calendar are kept, but trimmed where they
+// overlap a manual entry so the manual entry wins. Submitted entries that are
+// exactly an imported entry are dropped, so clients sending back everything
+// they listed don't turn imported blocks into manual ones.
 func (s *AvailabilityService) SetAvailability(ctx context.Context, input SetAvailabilityInput, callerID uuid.UUID, callerRole string) ([]AvailabilityResponse, error) {
 	event, err := s.queries.GetEventBySlug(ctx, input.EventSlug)
 	if err != nil {
@@ -124,14 +154,8 @@ func (s *AvailabilityService) SetAvailability(ctx context.Context, input SetAvai
 		return nil, fmt.Errorf("fetching event: %w", err)
 	}
 
-	// Users can only set their own availability, admins can set for anyone
-	if callerRole == "user" && input.UserID != callerID {
-		return nil, model.NewDomainError(model.ErrForbidden, "users can only set their own availability")
-	}
-
-	// Read-only users cannot set availability
-	if callerRole == "read_only" {
-		return nil, model.NewDomainError(model.ErrForbidden, "read-only users cannot set availability")
+	if err := checkAvailabilityAccess(input.UserID, callerID, callerRole); err != nil {
+		return nil, err
 	}
 
 	// Validate entries
@@ -147,32 +171,124 @@ func (s *AvailabilityService) SetAvailability(ctx context.Context, input SetAvai
 		}
 	}
 
-	// Delete existing availability for this user/event
-	if err := s.queries.DeleteAvailabilityByEventAndUser(ctx, event.ID, input.UserID); err != nil {
-		return nil, fmt.Errorf("deleting existing availability: %w", err)
-	}
-
-	// Insert new entries
-	result := make([]AvailabilityResponse, len(input.Entries))
-	for i, entry := range input.Entries {
-		avail, err := s.queries.CreateAvailability(ctx, repository.CreateAvailabilityParams{
-			EventID:   event.ID,
-			UserID:    input.UserID,
-			StartTime: entry.StartTime,
-			EndTime:   entry.EndTime,
-			Status:    entry.Status,
-			Note:      entry.Note,
-		})
+	var result []AvailabilityResponse
+	err = s.inTx(ctx, event.ID, input.UserID, func(q *repository.Queries) error {
+		current, err := q.ListAvailabilityByEventAndUser(ctx, event.ID, input.UserID)
 		if err != nil {
-			return nil, fmt.Errorf("creating availability entry %d: %w", i, err)
+			return fmt.Errorf("listing availability: %w", err)
 		}
-		result[i] = availabilityToResponse(avail)
+		entries := withoutImported(input.Entries, current)
+
+		// Delete existing manual availability for this user/event
+		if err := q.DeleteAvailabilityByEventAndUser(ctx, event.ID, input.UserID, availabilitySourceManual); err != nil {
+			return fmt.Errorf("deleting existing availability: %w", err)
+		}
+
+		// Insert new entries
+		result = make([]AvailabilityResponse, len(entries))
+		for i, entry := range entries {
+			avail, err := q.CreateAvailability(ctx, repository.CreateAvailabilityParams{
+				EventID:   event.ID,
+				UserID:    input.UserID,
+				StartTime: entry.StartTime,
+				EndTime:   entry.EndTime,
+				Status:    entry.Status,
+				Note:      entry.Note,
+				Source:    availabilitySourceManual,
+			})
+			if err != nil {
+				return fmt.Errorf("creating availability entry %d: %w", i, err)
+			}
+			result[i] = availabilityToResponse(avail)
+		}
+
+		return trimImported(ctx, q, event.ID, input.UserID)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	s.logger.Info("availability set", "event", input.EventSlug, "user", input.UserID, "entries", len(input.Entries))
+	s.logger.Info("availability set", "event", input.EventSlug, "user", input.UserID, "entries", len(result))
+	s.dispatchAvailability(event.ID, input.UserID, availabilitySourceManual, result)
 	return result, nil
 }
 
+// withoutImported returns the entries that don't exactly match one of the
+// imported entries in current.
+func withoutImported(entries []AvailabilityEntry, current []repository.UserAvailability) []AvailabilityEntry {
+	type key struct{ start, end int64 }
+	imported := make(map[key]bool)
+	for _, r := range current {
+		if r.Source == availabilitySourceICal {
+			imported[key{r.StartTime.UnixNano(), r.EndTime.UnixNano()}] = true
+		}
+	}
+	kept := make([]AvailabilityEntry, 0, len(entries))
+	for _, e := range entries {
+		if !imported[key{e.StartTime.UnixNano(), e.EndTime.UnixNano()}] {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// inTx runs fn with queries bound to a transaction holding the lock on the
+// user's availability for the event, so that grid edits, uploads and feed
+// syncs for the same user don't interleave.
+func (s *AvailabilityService) inTx(ctx context.Context, eventID, userID uuid.UUID, fn func(q *repository.Queries) error) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := s.queries.WithTx(tx)
+	if err := q.LockUserAvailability(ctx, eventID, userID); err != nil {
+		return fmt.Errorf("locking availability: %w", err)
+	}
+	if err := fn(q); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing availability: %w", err)
+	}
+	return nil
+}
+
+// dispatchAvailability sends availability.updated for a user's changed
+// entries. Scheduled calendar feed syncs don't call it, as they would fire
+// for every feed on every sync.
+func (s *AvailabilityService) dispatchAvailability(eventID, userID uuid.UUID, source string, entries []AvailabilityResponse) {
+	if s.webhookService == nil {
+		return
+	}
+	go func() {
+		bgCtx := context.Background()
+		change := AvailabilityChange{
+			EventID: eventID.String(),
+			UserID:  userID.String(),
+			Source:  source,
+			Entries: entries,
+		}
+		if user, err := s.queries.GetUserByID(bgCtx, userID); err == nil {
+			change.Username = user.Username
+		}
+		s.webhookService.Dispatch(bgCtx, eventID, TriggerAvailabilityUpdated, change)
+	}()
+}
+
+// checkAvailabilityAccess enforces that users only change their own
+// availability while admins may change anyone's.
+func checkAvailabilityAccess(userID, callerID uuid.UUID, callerRole string) error {
+	if callerRole == "user" && userID != callerID {
+		return model.NewDomainError(model.ErrForbidden, "users can only set their own availability")
+	}
+	if callerRole == "read_only" {
+		return model.NewDomainError(model.ErrForbidden, "read-only users cannot set availability")
+	}
+	return nil
+}
+
 func availabilityToResponse(a repository.UserAvailability) AvailabilityResponse {
 	return AvailabilityResponse{
 		ID:        a.ID.String(),
@@ -182,5 +298,6 @@ func availabilityToResponse(a repository.UserAvailability) AvailabilityResponse
 		EndTime:   a.EndTime.Format(time.RFC3339),
 		Status:    a.Status,
 		Note:      a.Note,
+		Source:    a.Source,
 	}
 }
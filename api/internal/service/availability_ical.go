@@ -0,0 +1,438 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/ical"
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// MaxICalImportSize bounds uploaded and fetched calendars.
+const MaxICalImportSize = 5 << 20
+
+type ICalImportResult struct {
+	Imported int                    `json:"imported"`
+	Entries  []AvailabilityResponse `json:"entries"`
+}
+
+type ICalFeedResponse struct {
+	URL          string  `json:"url"`
+	LastSyncedAt *string `json:"last_synced_at"`
+	LastError    *string `json:"last_error"`
+	CreatedAt    string  `json:"created_at"`
+}
+
+type timeRange struct {
+	start time.Time
+	end   time.Time
+}
+
+// ImportICal converts the busy events of an uploaded calendar into
+// "unavailable" entries, replacing whatever was imported before. Manually
+// entered availability is left untouched and takes precedence.
+func (s *AvailabilityService) ImportICal(ctx context.Context, slug string, userID uuid.UUID, r io.Reader, callerID uuid.UUID, callerRole string) (*ICalImportResult, error) {
+	event, err := s.queries.GetEventBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.NewDomainError(model.ErrNotFound, "event not found")
+		}
+		return nil, fmt.Errorf("fetching event: %w", err)
+	}
+	if err := checkAvailabilityAccess(userID, callerID, callerRole); err != nil {
+		return nil, err
+	}
+
+	imported, err := s.importCalendar(ctx, event, userID, r, "")
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.ListByEventAndUser(ctx, slug, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("availability imported from calendar", "event", slug, "user", userID, "entries", imported)
+	s.dispatchAvailability(event.ID, userID, availabilitySourceICal, entries)
+	return &ICalImportResult{Imported: imported, Entries: entries}, nil
+}
+
+// errICalFeedGone means a subscription was removed or changed while it was
+// being synced, so its calendar isn't imported.
+var errICalFeedGone = errors.New("calendar subscription was removed or changed")
+
+// importCalendar replaces the user's imported entries with the busy events of
+// a calendar. feedURL is the subscription the calendar was fetched from, or
+// empty for uploads; a subscription that no longer has that URL isn't
+// imported, so a sync can't bring back entries after DeleteICalFeed.
+func (s *AvailabilityService) importCalendar(ctx context.Context, event repository.Event, userID uuid.UUID, r io.Reader, feedURL string) (int, error) {
+	events, err := ical.Parse(r, event.StartTime, event.EndTime, eventLocation(event))
+	if err != nil {
+		if errors.Is(err, ical.ErrNotCalendar) {
+			return 0, model.NewFieldError(model.ErrInvalidInput, "file", "file is not an iCalendar (.ics) file")
+		}
+		return 0, model.NewFieldError(model.ErrInvalidInput, "file", "calendar could not be read")
+	}
+
+	var busy []timeRange
+	for _, e := range events {
+		if e.Busy() {
+			busy = append(busy, timeRange{start: e.Start, end: e.End})
+		}
+	}
+	busy = clampRanges(busy, event.StartTime, event.EndTime)
+
+	err = s.inTx(ctx, event.ID, userID, func(q *repository.Queries) error {
+		if feedURL != "" {
+			feed, err := q.GetAvailabilityICalFeed(ctx, event.ID, userID)
+			if errors.Is(err, pgx.ErrNoRows) || (err == nil && feed.Url != feedURL) {
+				return errICalFeedGone
+			}
+			if err != nil {
+				return fmt.Errorf("fetching calendar subscription: %w", err)
+			}
+		}
+		manual, err := manualRanges(ctx, q, event.ID, userID)
+		if err != nil {
+			return err
+		}
+		busy = subtractRanges(busy, manual)
+		return replaceImported(ctx, q, event.ID, userID, busy)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(busy), nil
+}
+
+// trimImported removes the parts of imported entries that overlap manual
+// entries, e.g. after the user edited the grid.
+func trimImported(ctx context.Context, q *repository.Queries, eventID, userID uuid.UUID) error {
+	rows, err := q.ListAvailabilityByEventAndUser(ctx, eventID, userID)
+	if err != nil {
+		return fmt.Errorf("listing availability: %w", err)
+	}
+	var imported, manual []timeRange
+	for _, r := range rows {
+		tr := timeRange{start: r.StartTime, end: r.EndTime}
+		if r.Source == availabilitySourceICal {
+			imported = append(imported, tr)
+		} else {
+			manual = append(manual, tr)
+		}
+	}
+	if len(imported) == 0 {
+		return nil
+	}
+	return replaceImported(ctx, q, eventID, userID, subtractRanges(imported, mergeRanges(manual)))
+}
+
+func manualRanges(ctx context.Context, q *repository.Queries, eventID, userID uuid.UUID) ([]timeRange, error) {
+	rows, err := q.ListAvailabilityByEventAndUser(ctx, eventID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("listing availability: %w", err)
+	}
+	var manual []timeRange
+	for _, r := range rows {
+		if r.Source == availabilitySourceManual {
+			manual = append(manual, timeRange{start: r.StartTime, end: r.EndTime})
+		}
+	}
+	return mergeRanges(manual), nil
+}
+
+func replaceImported(ctx context.Context, q *repository.Queries, eventID, userID uuid.UUID, busy []timeRange) error {
+	if err := q.DeleteAvailabilityByEventAndUser(ctx, eventID, userID, availabilitySourceICal); err != nil {
+		return fmt.Errorf("deleting imported availability: %w", err)
+	}
+	for i, r := range busy {
+		if _, err := q.CreateAvailability(ctx, repository.CreateAvailabilityParams{
+			EventID:   eventID,
+			UserID:    userID,
+			StartTime: r.start,
+			EndTime:   r.end,
+			Status:    "unavailable",
+			Source:    availabilitySourceICal,
+		}); err != nil {
+			return fmt.Errorf("creating imported availability entry %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// GetICalFeed returns the calendar subscription of a user for an event.
+func (s *AvailabilityService) GetICalFeed(ctx context.Context, slug string, userID uuid.UUID) (*ICalFeedResponse, error) {
+	event, err := s.queries.GetEventBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.NewDomainError(model.ErrNotFound, "event not found")
+		}
+		return nil, fmt.Errorf("fetching event: %w", err)
+	}
+
+	feed, err := s.queries.GetAvailabilityICalFeed(ctx, event.ID, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.NewDomainError(model.ErrNotFound, "no calendar subscription")
+		}
+		return nil, fmt.Errorf("fetching calendar subscription: %w", err)
+	}
+	resp := icalFeedToResponse(feed)
+	return &resp, nil
+}
+
+// SetICalFeed stores the URL of a calendar that is re-fetched periodically
+// and imports it right away. Fetch errors are recorded on the subscription
+// rather than failing the request.
+func (s *AvailabilityService) SetICalFeed(ctx context.Context, slug string, userID uuid.UUID, rawURL string, callerID uuid.UUID, callerRole string) (*ICalFeedResponse, error) {
+	event, err := s.queries.GetEventBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.NewDomainError(model.ErrNotFound, "event not found")
+		}
+		return nil, fmt.Errorf("fetching event: %w", err)
+	}
+	if err := checkAvailabilityAccess(userID, callerID, callerRole); err != nil {
+		return nil, err
+	}
+
+	feedURL, err := normalizeFeedURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.outbound.CheckURL(ctx, feedURL); err != nil {
+		return nil, model.NewFieldError(model.ErrInvalidInput, "url", "calendar URL is not allowed: "+err.Error())
+	}
+
+	if _, err := s.queries.UpsertAvailabilityICalFeed(ctx, event.ID, userID, feedURL); err != nil {
+		return nil, fmt.Errorf("saving calendar subscription: %w", err)
+	}
+
+	s.syncFeed(ctx, event, userID, feedURL)
+
+	feed, err := s.queries.GetAvailabilityICalFeed(ctx, event.ID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching calendar subscription: %w", err)
+	}
+	resp := icalFeedToResponse(feed)
+	return &resp, nil
+}
+
+// DeleteICalFeed removes the calendar subscription and everything imported
+// from calendars for the user, under the same lock as imports so a running
+// sync can't bring the entries back.
+func (s *AvailabilityService) DeleteICalFeed(ctx context.Context, slug string, userID uuid.UUID, callerID uuid.UUID, callerRole string) error {
+	event, err := s.queries.GetEventBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.NewDomainError(model.ErrNotFound, "event not found")
+		}
+		return fmt.Errorf("fetching event: %w", err)
+	}
+	if err := checkAvailabilityAccess(userID, callerID, callerRole); err != nil {
+		return err
+	}
+
+	err = s.inTx(ctx, event.ID, userID, func(q *repository.Queries) error {
+		if err := q.DeleteAvailabilityICalFeed(ctx, event.ID, userID); err != nil {
+			return fmt.Errorf("deleting calendar subscription: %w", err)
+		}
+		if err := q.DeleteAvailabilityByEventAndUser(ctx, event.ID, userID, availabilitySourceICal); err != nil {
+			return fmt.Errorf("deleting imported availability: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	entries, err := s.ListByEventAndUser(ctx, slug, userID)
+	if err != nil {
+		return err
+	}
+	s.logger.Info("calendar subscription removed", "event", slug, "user", userID)
+	s.dispatchAvailability(event.ID, userID, availabilitySourceICal, entries)
+	return nil
+}
+
+// SyncDueFeeds re-fetches every subscription of a running or upcoming event
+// that was last synced more than maxAge ago.
+func (s *AvailabilityService) SyncDueFeeds(ctx context.Context, maxAge time.Duration) (int, error) {
+	feeds, err := s.queries.ListAvailabilityICalFeedsDue(ctx, time.Now().Add(-maxAge))
+	if err != nil {
+		return 0, fmt.Errorf("listing calendar subscriptions: %w", err)
+	}
+
+	synced := 0
+	for _, f := range feeds {
+		event, err := s.queries.GetEventByID(ctx, f.EventID)
+		if err != nil {
+			s.logger.Error("failed to fetch event for calendar sync", "event_id", f.EventID, "error", err)
+			continue
+		}
+		if s.syncFeed(ctx, event, f.UserID, f.Url) {
+			synced++
+		}
+	}
+	return synced, nil
+}
+
+// syncFeed fetches and imports one subscription and records the outcome.
+func (s *AvailabilityService) syncFeed(ctx context.Context, event repository.Event, userID uuid.UUID, feedURL string) bool {
+	err := s.fetchAndImport(ctx, event, userID, feedURL)
+	if errors.Is(err, errICalFeedGone) {
+		return false
+	}
+
+	var lastError *string
+	if err != nil {
+		msg := err.Error()
+		var domainErr *model.DomainError
+		if errors.As(err, &domainErr) {
+			msg = domainErr.Message
+		}
+		lastError = &msg
+		s.logger.Warn("calendar sync failed", "event", event.Slug, "user", userID, "error", err)
+	}
+	if err := s.queries.UpdateAvailabilityICalFeedSync(ctx, event.ID, userID, lastError); err != nil {
+		s.logger.Error("failed to record calendar sync", "event", event.Slug, "user", userID, "error", err)
+	}
+	return lastError == nil
+}
+
+func (s *AvailabilityService) fetchAndImport(ctx context.Context, event repository.Event, userID uuid.UUID, feedURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return fmt.Errorf("building request: %w", err)
+	}
+	req.Header.Set("Accept", "text/calendar")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetching calendar: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching calendar: unexpected status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxICalImportSize+1))
+	if err != nil {
+		return fmt.Errorf("reading calendar: %w", err)
+	}
+	if len(body) > MaxICalImportSize {
+		return fmt.Errorf("calendar exceeds %d bytes", MaxICalImportSize)
+	}
+
+	_, err = s.importCalendar(ctx, event, userID, bytes.NewReader(body), feedURL)
+	return err
+}
+
+// normalizeFeedURL validates a subscription URL and rewrites webcal:// links
+// to https://.
+func normalizeFeedURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return "", model.NewFieldError(model.ErrInvalidInput, "url", "invalid calendar URL")
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "webcal", "webcals":
+		u.Scheme = "https"
+	case "http", "https":
+	default:
+		return "", model.NewFieldError(model.ErrInvalidInput, "url", "calendar URL must use http, https or webcal")
+	}
+	return u.String(), nil
+}
+
+func icalFeedToResponse(f repository.AvailabilityIcalFeed) ICalFeedResponse {
+	resp := ICalFeedResponse{
+		URL:       f.Url,
+		LastError: f.LastError,
+		CreatedAt: f.CreatedAt.Format(time.RFC3339),
+	}
+	if f.LastSyncedAt != nil {
+		s := f.LastSyncedAt.Format(time.RFC3339)
+		resp.LastSyncedAt = &s
+	}
+	return resp
+}
+
+// mergeRanges sorts ranges and joins overlapping or touching ones.
+func mergeRanges(ranges []timeRange) []timeRange {
+	if len(ranges) == 0 {
+		return nil
+	}
+	sorted := make([]timeRange, len(ranges))
+	copy(sorted, ranges)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].start.Before(sorted[j].start) })
+
+	merged := []timeRange{sorted[0]}
+	for _, r := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		if !r.start.After(last.end) {
+			if r.end.After(last.end) {
+				last.end = r.end
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// clampRanges limits ranges to [start, end) and merges the result.
+func clampRanges(ranges []timeRange, start, end time.Time) []timeRange {
+	var out []timeRange
+	for _, r := range ranges {
+		if r.start.Before(start) {
+			r.start = start
+		}
+		if r.end.After(end) {
+			r.end = end
+		}
+		if r.end.After(r.start) {
+			out = append(out, r)
+		}
+	}
+	return mergeRanges(out)
+}
+
+// subtractRanges removes every part of ranges covered by cut. Both inputs
+// must be merged.
+func subtractRanges(ranges, cut []timeRange) []timeRange {
+	var out []timeRange
+	for _, r := range ranges {
+		for _, c := range cut {
+			if !c.end.After(r.start) {
+				continue
+			}
+			if !c.start.Before(r.end) {
+				break
+			}
+			if c.start.After(r.start) {
+				out = append(out, timeRange{start: r.start, end: c.start})
+			}
+			r.start = c.end
+			if !r.end.After(r.start) {
+				break
+			}
+		}
+		if r.end.After(r.start) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/repository"
+)
+
+func TestSubtractRanges(t *testing.T) {
+	base := time.Date(2025, 7, 4, 0, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return base.Add(time.Duration(h) * time.Hour) }
+	r := func(from, to int) timeRange { return timeRange{start: at(from), end: at(to)} }
+
+	busy := mergeRanges([]timeRange{r(8, 12), r(10, 14), r(20, 22)})
+	manual := mergeRanges([]timeRange{r(9, 10), r(13, 21)})
+
+	got := subtractRanges(busy, manual)
+	want := []timeRange{r(8, 9), r(10, 13), r(21, 22)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("subtractRanges() = %v, want %v", got, want)
+	}
+
+	clamped := clampRanges([]timeRange{r(-2, 1), r(23, 26)}, at(0), at(24))
+	if want := []timeRange{r(0, 1), r(23, 24)}; !reflect.DeepEqual(clamped, want) {
+		t.Errorf("clampRanges() = %v, want %v", clamped, want)
+	}
+}
+
+func TestWithoutImported(t *testing.T) {
+	base := time.Date(2025, 7, 4, 0, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return base.Add(time.Duration(h) * time.Hour) }
+	current := []repository.UserAvailability{
+		{StartTime: at(8), EndTime: at(10), Status: "unavailable", Source: availabilitySourceICal},
+		{StartTime: at(12), EndTime: at(14), Status: "available", Source: availabilitySourceManual},
+	}
+	entries := []AvailabilityEntry{
+		{StartTime: at(8).In(time.FixedZone("CEST", 2*3600)), EndTime: at(10), Status: "unavailable"},
+		{StartTime: at(8), EndTime: at(11), Status: "unavailable"},
+		{StartTime: at(12), EndTime: at(14), Status: "available"},
+	}
+
+	got := withoutImported(entries, current)
+	if want := entries[1:]; !reflect.DeepEqual(got, want) {
+		t.Errorf("withoutImported() = %v, want %v", got, want)
+	}
+}
@@ -24,10 +24,14 @@ type CleanupSettings struct {
 }
 
 type CleanupResult struct {
-	ExpiredSessions    int64 `json:"expired_sessions"`
-	OldAuditEntries    int64 `json:"old_audit_entries"`
-	OldNotifications   int64 `json:"old_notifications"`
-	UsedRecoveryCodes  int64 `json:"used_recovery_codes"`
+	ExpiredSessions          int64 `json:"expired_sessions"`
+	OldAuditEntries          int64 `json:"old_audit_entries"`
+	OldNotifications         int64 `json:"old_notifications"`
+	UsedRecoveryCodes        int64 `json:"used_recovery_codes"`
+	OldShiftDeletions        int64 `json:"old_shift_deletions"`
+	OldEmails                int64 `json:"old_emails"`
+	ExpiredPushSubscriptions int64 `json:"expired_push_subscriptions"`
+	OldWebhookDeliveries     int64 `json:"old_webhook_deliveries"`
 }
 
 func NewCleanupService(queries *repository.Queries, logger *slog.Logger) *CleanupService {
@@ -58,6 +62,7 @@ func (s *CleanupService) Start(ctx context.Context) {
 					"old_audit_entries", result.OldAuditEntries,
 					"old_notifications", result.OldNotifications,
 					"used_recovery_codes", result.UsedRecoveryCodes,
+					"old_shift_deletions", result.OldShiftDeletions,
 				)
 			} else {
 				s.logger.Debug("scheduled cleanup skipped (disabled)")
@@ -149,5 +154,38 @@ func (s *CleanupService) runCleanup(ctx context.Context, settings CleanupSetting
 		result.UsedRecoveryCodes = count
 	}
 
+	// Delete sent and abandoned notification emails
+	cutoff = time.Now().AddDate(0, 0, -settings.RetentionDaysNotifications)
+	count, err = s.queries.DeleteOldEmails(ctx, cutoff)
+	if err != nil {
+		s.logger.Error("failed to delete old emails", "error", err)
+	} else {
+		result.OldEmails = count
+	}
+
+	// Delete finished webhook deliveries and their attempts
+	count, err = s.queries.DeleteOldWebhookDeliveries(ctx, cutoff)
+	if err != nil {
+		s.logger.Error("failed to delete old webhook deliveries", "error", err)
+	} else {
+		result.OldWebhookDeliveries = count
+	}
+
+	// Delete push subscriptions past the expiry the browser reported
+	count, err = s.queries.DeleteExpiredPushSubscriptions(ctx)
+	if err != nil {
+		s.logger.Error("failed to delete expired push subscriptions", "error", err)
+	} else {
+		result.ExpiredPushSubscriptions = count
+	}
+
+	// Delete shift tombstones that iCal feeds no longer report
+	count, err = s.queries.DeleteOldShiftDeletions(ctx, time.Now().Add(-icalCancellationWindow))
+	if err != nil {
+		s.logger.Error("failed to delete old shift deletions", "error", err)
+	} else {
+		result.OldShiftDeletions = count
+	}
+
 	return result
 }
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	emailBatchSize    = 20
+	emailLease        = 5 * time.Minute // how long a claimed message is hidden from other workers
+	emailMaxAttempts  = 10
+	emailPollInterval = 30 * time.Second
+	emailMaxBackoff   = 6 * time.Hour
+)
+
+// EmailOutboxService queues emails in the database and delivers them in the
+// background, retrying with exponential backoff while SMTP is unavailable.
+type EmailOutboxService struct {
+	queries     *repository.Queries
+	smtpService *SMTPService
+	logger      *slog.Logger
+	wakeCh      chan struct{}
+	stopCh      chan struct{}
+}
+
+func NewEmailOutboxService(queries *repository.Queries, smtpService *SMTPService, logger *slog.Logger) *EmailOutboxService {
+	return &EmailOutboxService{
+		queries:     queries,
+		smtpService: smtpService,
+		logger:      logger,
+		wakeCh:      make(chan struct{}, 1),
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// Enqueue stores an email for delivery. Nothing is queued while SMTP is not
+// configured, matching SMTPService.SendEmail.
+func (s *EmailOutboxService) Enqueue(ctx context.Context, userID *uuid.UUID, to, subject, htmlBody, textBody string) error {
+	cfg, err := s.smtpService.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		s.logger.Debug("SMTP not configured, skipping email", "to", to)
+		return nil
+	}
+
+	if err := s.queries.EnqueueEmail(ctx, repository.EnqueueEmailParams{
+		UserID:    userID,
+		Recipient: to,
+		Subject:   subject,
+		HtmlBody:  htmlBody,
+		TextBody:  textBody,
+	}); err != nil {
+		return fmt.Errorf("queueing email: %w", err)
+	}
+
+	// Wake the worker so mail goes out without waiting for the next poll
+	select {
+	case s.wakeCh <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (s *EmailOutboxService) Start(ctx context.Context) {
+	timer := time.NewTimer(emailPollInterval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			return
+		case <-s.wakeCh:
+		case <-timer.C:
+		}
+
+		sent, err := s.DeliverDue(ctx)
+		if err != nil {
+			s.logger.Error("email delivery failed", "error", err)
+		} else if sent > 0 {
+			s.logger.Debug("emails delivered", "count", sent)
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(emailPollInterval)
+	}
+}
+
+func (s *EmailOutboxService) Stop() {
+	close(s.stopCh)
+}
+
+// DeliverDue sends queued emails whose next attempt is due and returns how
+// many were sent. Messages stay queued while SMTP is not configured.
+func (s *EmailOutboxService) DeliverDue(ctx context.Context) (int, error) {
+	cfg, err := s.smtpService.GetConfig(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if cfg == nil {
+		return 0, nil
+	}
+
+	sent := 0
+	for {
+		now := time.Now()
+		emails, err := s.queries.ClaimDueEmails(ctx, repository.ClaimDueEmailsParams{
+			Now:        now,
+			LeaseUntil: now.Add(emailLease),
+			Limit:      emailBatchSize,
+		})
+		if err != nil {
+			return sent, fmt.Errorf("claiming emails: %w", err)
+		}
+
+		for _, e := range emails {
+			err := s.smtpService.Send(ctx, Email{
+				To:       []string{e.Recipient},
+				Subject:  e.Subject,
+				HTMLBody: e.HtmlBody,
+				TextBody: e.TextBody,
+			})
+			if err == nil {
+				if err := s.queries.MarkEmailSent(ctx, e.ID); err != nil {
+					s.logger.Error("failed to mark email sent", "email_id", e.ID, "error", err)
+				}
+				sent++
+				continue
+			}
+
+			attempts := int(e.Attempts) + 1
+			giveUp := attempts >= emailMaxAttempts
+			msg := err.Error()
+			if giveUp {
+				s.logger.Error("giving up on email", "email_id", e.ID, "to", e.Recipient, "attempts", attempts, "error", err)
+			} else {
+				s.logger.Warn("email delivery failed, will retry", "email_id", e.ID, "attempts", attempts, "error", err)
+			}
+			if err := s.queries.MarkEmailAttemptFailed(ctx, repository.MarkEmailAttemptFailedParams{
+				ID:            e.ID,
+				LastError:     &msg,
+				NextAttemptAt: time.Now().Add(emailBackoff(attempts)),
+				GiveUp:        giveUp,
+			}); err != nil {
+				s.logger.Error("failed to record email failure", "email_id", e.ID, "error", err)
+			}
+		}
+
+		if len(emails) < emailBatchSize {
+			return sent, nil
+		}
+	}
+}
+
+// emailBackoff returns the delay before the next attempt after the given
+// number of failed attempts: 1m, 2m, 4m, ... capped at emailMaxBackoff.
+func emailBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	d := time.Minute
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= emailMaxBackoff {
+			return emailMaxBackoff
+		}
+	}
+	return d
+}
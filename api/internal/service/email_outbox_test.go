@@ -0,0 +1,25 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEmailBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{5, 16 * time.Minute},
+		{9, 256 * time.Minute},
+		{10, emailMaxBackoff},
+		{50, emailMaxBackoff},
+	}
+	for _, tt := range tests {
+		if got := emailBackoff(tt.attempts); got != tt.want {
+			t.Errorf("emailBackoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
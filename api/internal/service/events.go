@@ -52,6 +52,7 @@ type CreateEventInput struct {
 	StartTime        time.Time
 	EndTime          time.Time
 	TimeGranularity  string
+	Timezone         string
 	CreatedBy        uuid.UUID
 }
 
@@ -64,6 +65,7 @@ type UpdateEventInput struct {
 	StartTime        *time.Time
 	EndTime          *time.Time
 	TimeGranularity  *string
+	Timezone         *string
 }
 
 type SetEventTeamInput struct {
@@ -86,6 +88,7 @@ type EventResponse struct {
 	StartTime        string  `json:"start_time"`
 	EndTime          string  `json:"end_time"`
 	TimeGranularity  string  `json:"time_granularity"`
+	Timezone         string  `json:"timezone"`
 	IsLocked         bool    `json:"is_locked"`
 	IsPublic         bool    `json:"is_public"`
 	IsEventAdmin     bool    `json:"is_event_admin"`
@@ -140,6 +143,7 @@ func eventToResponse(e repository.Event) EventResponse {
 		StartTime:        e.StartTime.Format(time.RFC3339),
 		EndTime:          e.EndTime.Format(time.RFC3339),
 		TimeGranularity:  e.TimeGranularity,
+		Timezone:         e.Timezone,
 		IsLocked:         e.IsLocked,
 		IsPublic:         e.IsPublic,
 		CreatedBy:        createdBy,
@@ -176,6 +180,9 @@ func (s *EventService) GetBySlug(ctx context.Context, slug string) (EventRespons
 }
 
 func (s *EventService) Create(ctx context.Context, input CreateEventInput) (EventResponse, error) {
+	if input.Timezone == "" {
+		input.Timezone = "UTC"
+	}
 	if err := validateEventInput(input); err != nil {
 		return EventResponse{}, err
 	}
@@ -199,6 +206,7 @@ func (s *EventService) Create(ctx context.Context, input CreateEventInput) (Even
 		EndTime:          input.EndTime,
 		TimeGranularity:  input.TimeGranularity,
 		CreatedBy:        &input.CreatedBy,
+		Timezone:         input.Timezone,
 	})
 	if err != nil {
 		return EventResponse{}, fmt.Errorf("creating event: %w", err)
@@ -249,6 +257,11 @@ func (s *EventService) Update(ctx context.Context, slug string, input UpdateEven
 		return EventResponse{}, model.NewFieldError(model.ErrInvalidInput, "time_granularity", "must be 15min, 30min, or 1hour")
 	}
 
+	// Validate timezone if changing
+	if input.Timezone != nil && !validTimezone(*input.Timezone) {
+		return EventResponse{}, model.NewFieldError(model.ErrInvalidInput, "timezone", "must be a valid IANA time zone")
+	}
+
 	// Validate time range if changing
 	startTime := event.StartTime
 	endTime := event.EndTime
@@ -272,6 +285,7 @@ func (s *EventService) Update(ctx context.Context, slug string, input UpdateEven
 		StartTime:        input.StartTime,
 		EndTime:          input.EndTime,
 		TimeGranularity:  input.TimeGranularity,
+		Timezone:         input.Timezone,
 	})
 	if err != nil {
 		return EventResponse{}, fmt.Errorf("updating event: %w", err)
@@ -354,15 +368,15 @@ func (s *EventService) SetLocked(ctx context.Context, slug string, locked bool)
 	go func() {
 		bgCtx := context.Background()
 		triggerType := TriggerEventLocked
-		title := fmt.Sprintf("%s: Locked", event.Name)
-		body := fmt.Sprintf("Event \"%s\" has been locked — shifts can no longer be edited", event.Name)
 		if !locked {
 			triggerType = TriggerEventUnlocked
-			title = fmt.Sprintf("%s: Unlocked", event.Name)
-			body = fmt.Sprintf("Event \"%s\" has been unlocked — shifts can be edited again", event.Name)
 		}
 		if s.notificationService != nil {
-			s.notificationService.NotifyEventUsers(bgCtx, event.ID, uuid.Nil, triggerType, title, &body)
+			s.notificationService.NotifyEventUsers(bgCtx, event.ID, uuid.Nil, NotificationMessage{
+				TriggerType: triggerType,
+				EventName:   event.Name,
+				EventSlug:   event.Slug,
+			})
 		}
 		if s.webhookService != nil {
 			s.webhookService.Dispatch(bgCtx, event.ID, triggerType, map[string]any{"slug": slug, "locked": locked})
@@ -391,6 +405,14 @@ func (s *EventService) SetPublic(ctx context.Context, slug string, public bool)
 		go s.auditService.Log(context.Background(), nil, &event.ID, "public_toggle", "event", &event.ID, map[string]bool{"is_public": event.IsPublic}, map[string]bool{"is_public": public}, nil)
 	}
 
+	if s.webhookService != nil && public != event.IsPublic {
+		go s.webhookService.Dispatch(context.Background(), event.ID, TriggerEventVisibilityChange, map[string]any{
+			"event_id":  event.ID.String(),
+			"slug":      slug,
+			"is_public": public,
+		})
+	}
+
 	return nil
 }
 
@@ -434,7 +456,7 @@ func (s *EventService) SetTeam(ctx context.Context, slug string, input SetEventT
 	}
 
 	// Verify team exists
-	_, err = s.queries.GetTeamByID(ctx, input.TeamID)
+	team, err := s.queries.GetTeamByID(ctx, input.TeamID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return model.NewDomainError(model.ErrNotFound, "team not found")
@@ -449,6 +471,17 @@ func (s *EventService) SetTeam(ctx context.Context, slug string, input SetEventT
 	}); err != nil {
 		return fmt.Errorf("setting event team: %w", err)
 	}
+	bumpPlanVersion(ctx, s.queries, s.logger, event.ID)
+
+	if s.webhookService != nil {
+		go s.webhookService.Dispatch(context.Background(), event.ID, TriggerEventTeamChanged, EventTeamChange{
+			EventID:   event.ID.String(),
+			Slug:      slug,
+			TeamID:    team.ID.String(),
+			TeamName:  team.Name,
+			IsVisible: input.IsVisible,
+		})
+	}
 
 	return nil
 }
@@ -465,6 +498,18 @@ func (s *EventService) RemoveTeam(ctx context.Context, slug string, teamID uuid.
 	if err := s.queries.RemoveEventTeam(ctx, event.ID, teamID); err != nil {
 		return fmt.Errorf("removing event team: %w", err)
 	}
+	bumpPlanVersion(ctx, s.queries, s.logger, event.ID)
+
+	if s.webhookService != nil {
+		go func() {
+			bgCtx := context.Background()
+			change := EventTeamChange{EventID: event.ID.String(), Slug: slug, TeamID: teamID.String(), Removed: true}
+			if team, err := s.queries.GetTeamByID(bgCtx, teamID); err == nil {
+				change.TeamName = team.Name
+			}
+			s.webhookService.Dispatch(bgCtx, event.ID, TriggerEventTeamChanged, change)
+		}()
+	}
 
 	return nil
 }
@@ -635,6 +680,14 @@ func (s *EventService) AddPinnedUser(ctx context.Context, slug string, userID uu
 		go s.auditService.Log(context.Background(), nil, &event.ID, "create", "event_pinned_user", nil, nil, map[string]string{"user_id": userID.String(), "event_slug": slug}, nil)
 	}
 
+	if s.webhookService != nil {
+		go s.webhookService.Dispatch(context.Background(), event.ID, TriggerEventPinnedAdded, map[string]string{
+			"event_id": event.ID.String(),
+			"slug":     slug,
+			"user_id":  userID.String(),
+		})
+	}
+
 	return nil
 }
 
@@ -657,6 +710,14 @@ func (s *EventService) RemovePinnedUser(ctx context.Context, slug string, userID
 		go s.auditService.Log(context.Background(), nil, &event.ID, "delete", "event_pinned_user", nil, map[string]string{"user_id": userID.String(), "event_slug": slug}, nil, nil)
 	}
 
+	if s.webhookService != nil {
+		go s.webhookService.Dispatch(context.Background(), event.ID, TriggerEventPinnedRemoved, map[string]string{
+			"event_id": event.ID.String(),
+			"slug":     slug,
+			"user_id":  userID.String(),
+		})
+	}
+
 	return nil
 }
 
@@ -730,6 +791,15 @@ func (s *EventService) SetHiddenRanges(ctx context.Context, slug string, ranges
 			HideEndHour:   created.HideEndHour,
 		}
 	}
+	bumpPlanVersion(ctx, s.queries, s.logger, event.ID)
+
+	if s.webhookService != nil {
+		go s.webhookService.Dispatch(context.Background(), event.ID, TriggerEventHiddenRanges, HiddenRangesChange{
+			EventID: event.ID.String(),
+			Slug:    slug,
+			Ranges:  result,
+		})
+	}
 
 	return result, nil
 }
@@ -756,5 +826,30 @@ func validateEventInput(input CreateEventInput) error {
 	if !validGranularities[input.TimeGranularity] {
 		return model.NewFieldError(model.ErrInvalidInput, "time_granularity", "must be 15min, 30min, or 1hour")
 	}
+	if !validTimezone(input.Timezone) {
+		return model.NewFieldError(model.ErrInvalidInput, "timezone", "must be a valid IANA time zone")
+	}
 	return nil
 }
+
+// validTimezone reports whether tz names a loadable IANA location.
+// "Local" is rejected so that exports don't depend on the server's zone.
+func validTimezone(tz string) bool {
+	if tz == "" || tz == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(tz)
+	return err == nil
+}
+
+// eventLocation returns the event's time zone, falling back to UTC.
+func eventLocation(e repository.Event) *time.Location {
+	if e.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(e.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
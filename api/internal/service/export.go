@@ -1,6 +1,7 @@
 package service
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
 	"crypto/rand"
@@ -10,8 +11,11 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/echtkpvl/rncasp/internal/model"
 	"github.com/echtkpvl/rncasp/internal/pdf"
@@ -30,8 +34,50 @@ func NewExportService(queries *repository.Queries, logger *slog.Logger, pdfGen *
 	return &ExportService{queries: queries, logger: logger, pdfGen: pdfGen}
 }
 
+// CSVOptions controls which shifts end up in a CSV export and how they are
+// formatted. The zero value reproduces the classic export: all shifts,
+// default columns, comma-separated, RFC3339 timestamps.
+type CSVOptions struct {
+	Start      *time.Time // nil = event start
+	End        *time.Time // nil = event end
+	UserIDs    []string   // UUIDs; empty = all
+	TeamIDs    []string   // UUIDs; empty = all
+	Columns    []string   // column keys, see csvColumnHeaders; empty = defaults
+	Delimiter  rune       // 0 = ','
+	DateFormat string     // "rfc3339" (default), "iso", "eu" or "us"
+	TimeFormat string     // "24h" or "12h"; empty = viewer preference
+	Aggregate  bool       // one row per user with total hours per team
+	ViewerID   *uuid.UUID // nil for anonymous (public) exports
+	ViewerRole string
+}
+
+var csvColumnHeaders = map[string]string{
+	"start":             "Start Time",
+	"end":               "End Time",
+	"team":              "Team",
+	"team_abbreviation": "Team Abbreviation",
+	"duration_hours":    "Duration (h)",
+	"username":          "Username",
+	"full_name":         "Full Name",
+	"display_name":      "Display Name",
+	"account_type":      "Account Type",
+	"created_by":        "Created By",
+	"email":             "Email",
+}
+
+// csvPrivateColumns may only be exported by super-admins and event admins.
+var csvPrivateColumns = map[string]bool{"created_by": true, "email": true}
+
+var csvDefaultColumns = []string{"start", "end", "team", "username", "full_name", "display_name"}
+
+var validCSVDateFormats = map[string]bool{"rfc3339": true, "iso": true, "eu": true, "us": true}
+
 // ExportCSV generates a CSV export of shifts for an event.
-func (s *ExportService) ExportCSV(ctx context.Context, slug string) ([]byte, string, error) {
+func (s *ExportService) ExportCSV(ctx context.Context, slug string, opts CSVOptions) ([]byte, string, error) {
+	if err := validateCSVOptions(&opts); err != nil {
+		return nil, "", err
+	}
+
 	event, err := s.queries.GetEventBySlug(ctx, slug)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -40,42 +86,291 @@ func (s *ExportService) ExportCSV(ctx context.Context, slug string) ([]byte, str
 		return nil, "", fmt.Errorf("fetching event: %w", err)
 	}
 
+	wantsPrivate := false
+	for _, c := range opts.Columns {
+		if csvPrivateColumns[c] {
+			wantsPrivate = true
+		}
+	}
+	if wantsPrivate && !s.canExportPrivate(ctx, event.ID, opts.ViewerID, opts.ViewerRole) {
+		return nil, "", model.NewFieldError(model.ErrForbidden, "columns", "only admins can export email and created_by")
+	}
+
+	if opts.TimeFormat == "" {
+		opts.TimeFormat = "24h"
+		if opts.ViewerID != nil {
+			if u, err := s.queries.GetUserByID(ctx, *opts.ViewerID); err == nil {
+				opts.TimeFormat = u.TimeFormat
+			}
+		}
+	}
+
 	shifts, err := s.queries.ListShiftsByEvent(ctx, event.ID)
 	if err != nil {
 		return nil, "", fmt.Errorf("listing shifts: %w", err)
 	}
 
+	rangeStart := event.StartTime
+	rangeEnd := event.EndTime
+	if opts.Start != nil {
+		rangeStart = *opts.Start
+	}
+	if opts.End != nil {
+		rangeEnd = *opts.End
+	}
+	shifts = pdf.FilterShifts(shifts, rangeStart, rangeEnd, opts.UserIDs, opts.TeamIDs)
+
 	var buf bytes.Buffer
 	w := csv.NewWriter(&buf)
+	w.Comma = opts.Delimiter
+
+	if opts.Aggregate {
+		eventTeams, err := s.queries.ListEventTeams(ctx, event.ID)
+		if err != nil {
+			return nil, "", fmt.Errorf("listing event teams: %w", err)
+		}
+		for _, row := range aggregateShiftHours(shifts, eventTeams) {
+			w.Write(row)
+		}
+	} else {
+		users := make(map[uuid.UUID]*repository.User)
+		lookupUser := func(id uuid.UUID) *repository.User {
+			if u, ok := users[id]; ok {
+				return u
+			}
+			var u *repository.User
+			if row, err := s.queries.GetUserByID(ctx, id); err == nil {
+				u = &row
+			}
+			users[id] = u
+			return u
+		}
 
-	// Header
-	w.Write([]string{"Start Time", "End Time", "Team", "Username", "Full Name", "Display Name"})
+		loc := eventLocation(event)
+		headers := make([]string, len(opts.Columns))
+		for i, c := range opts.Columns {
+			headers[i] = csvColumnHeaders[c]
+		}
+		w.Write(headers)
 
-	for _, sh := range shifts {
-		displayName := ""
-		if sh.UserDisplayName != nil {
-			displayName = *sh.UserDisplayName
-		}
-		w.Write([]string{
-			sh.StartTime.Format(time.RFC3339),
-			sh.EndTime.Format(time.RFC3339),
-			sh.TeamName,
-			sh.Username,
-			sh.UserFullName,
-			displayName,
-		})
+		for _, sh := range shifts {
+			record := make([]string, len(opts.Columns))
+			for i, c := range opts.Columns {
+				switch c {
+				case "start":
+					record[i] = formatCSVTime(sh.StartTime.In(loc), opts.DateFormat, opts.TimeFormat)
+				case "end":
+					record[i] = formatCSVTime(sh.EndTime.In(loc), opts.DateFormat, opts.TimeFormat)
+				case "team":
+					record[i] = sh.TeamName
+				case "team_abbreviation":
+					record[i] = sh.TeamAbbreviation
+				case "duration_hours":
+					record[i] = formatHours(sh.EndTime.Sub(sh.StartTime))
+				case "username":
+					record[i] = sh.Username
+				case "full_name":
+					record[i] = sh.UserFullName
+				case "display_name":
+					if sh.UserDisplayName != nil {
+						record[i] = *sh.UserDisplayName
+					}
+				case "account_type":
+					record[i] = sh.AccountType
+				case "created_by":
+					if sh.CreatedBy != nil {
+						if u := lookupUser(*sh.CreatedBy); u != nil {
+							record[i] = u.Username
+						}
+					}
+				case "email":
+					if u := lookupUser(sh.UserID); u != nil && u.Email != nil {
+						record[i] = *u.Email
+					}
+				}
+			}
+			w.Write(record)
+		}
 	}
+
 	w.Flush()
 	if err := w.Error(); err != nil {
 		return nil, "", fmt.Errorf("writing CSV: %w", err)
 	}
 
 	filename := fmt.Sprintf("%s-shifts.csv", event.Slug)
+	if opts.Aggregate {
+		filename = fmt.Sprintf("%s-hours.csv", event.Slug)
+	}
 	return buf.Bytes(), filename, nil
 }
 
+// canExportPrivate reports whether the viewer may export private columns.
+func (s *ExportService) canExportPrivate(ctx context.Context, eventID uuid.UUID, viewerID *uuid.UUID, viewerRole string) bool {
+	if viewerID == nil {
+		return false
+	}
+	if viewerRole == "super_admin" {
+		return true
+	}
+	isAdmin, err := s.queries.IsEventAdmin(ctx, eventID, *viewerID)
+	return err == nil && isAdmin
+}
+
+// CanExportPrivate reports whether the viewer may export private data such
+// as email addresses and phone numbers of an event's participants.
+func (s *ExportService) CanExportPrivate(ctx context.Context, slug string, viewerID *uuid.UUID, viewerRole string) (bool, error) {
+	event, err := s.queries.GetEventBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, model.NewDomainError(model.ErrNotFound, "event not found")
+		}
+		return false, fmt.Errorf("fetching event: %w", err)
+	}
+	return s.canExportPrivate(ctx, event.ID, viewerID, viewerRole), nil
+}
+
+func validateCSVOptions(opts *CSVOptions) error {
+	if len(opts.Columns) == 0 {
+		opts.Columns = csvDefaultColumns
+	}
+	for _, c := range opts.Columns {
+		if _, ok := csvColumnHeaders[c]; !ok {
+			return model.NewFieldError(model.ErrInvalidInput, "columns", fmt.Sprintf("unknown column %q", c))
+		}
+	}
+	if opts.Delimiter == 0 {
+		opts.Delimiter = ','
+	}
+	if opts.Delimiter == '"' || opts.Delimiter == '\r' || opts.Delimiter == '\n' || opts.Delimiter == utf8.RuneError {
+		return model.NewFieldError(model.ErrInvalidInput, "delimiter", "invalid delimiter")
+	}
+	if opts.DateFormat == "" {
+		opts.DateFormat = "rfc3339"
+	}
+	if !validCSVDateFormats[opts.DateFormat] {
+		return model.NewFieldError(model.ErrInvalidInput, "date_format", "must be rfc3339, iso, eu, or us")
+	}
+	if opts.TimeFormat != "" && opts.TimeFormat != "24h" && opts.TimeFormat != "12h" {
+		return model.NewFieldError(model.ErrInvalidInput, "time_format", "must be 24h or 12h")
+	}
+	return nil
+}
+
+// formatCSVTime formats t (already converted to the event time zone) using
+// the requested date layout; the clock part follows timeFormat except for
+// RFC3339, which is machine-readable and always 24h.
+func formatCSVTime(t time.Time, dateFormat, timeFormat string) string {
+	if dateFormat == "rfc3339" {
+		return t.Format(time.RFC3339)
+	}
+	clock := "15:04"
+	if timeFormat == "12h" {
+		clock = "3:04 PM"
+	}
+	switch dateFormat {
+	case "eu":
+		return t.Format("02.01.2006 " + clock)
+	case "us":
+		return t.Format("01/02/2006 " + clock)
+	default: // iso
+		return t.Format("2006-01-02 " + clock)
+	}
+}
+
+func formatHours(d time.Duration) string {
+	return strconv.FormatFloat(d.Hours(), 'f', 2, 64)
+}
+
+// aggregateShiftHours builds the aggregated CSV: a header row followed by one
+// row per user with the total hours worked in each team and overall. Team
+// columns follow the event's team order; teams without hours are omitted.
+// Teams that have shifts but are no longer linked to the event get a column
+// after the others, named from the shift rows, so the columns add up to the
+// total.
+func aggregateShiftHours(shifts []repository.ListShiftsByEventRow, eventTeams []repository.ListEventTeamsRow) [][]string {
+	type userTotals struct {
+		username    string
+		fullName    string
+		displayName string
+		perTeam     map[uuid.UUID]time.Duration
+		total       time.Duration
+	}
+
+	totals := make(map[uuid.UUID]*userTotals)
+	teamUsed := make(map[uuid.UUID]bool)
+	var order, usedOrder []uuid.UUID
+	usedNames := make(map[uuid.UUID]string)
+	for _, sh := range shifts {
+		ut, ok := totals[sh.UserID]
+		if !ok {
+			ut = &userTotals{
+				username: sh.Username,
+				fullName: sh.UserFullName,
+				perTeam:  make(map[uuid.UUID]time.Duration),
+			}
+			if sh.UserDisplayName != nil {
+				ut.displayName = *sh.UserDisplayName
+			}
+			totals[sh.UserID] = ut
+			order = append(order, sh.UserID)
+		}
+		d := sh.EndTime.Sub(sh.StartTime)
+		ut.perTeam[sh.TeamID] += d
+		ut.total += d
+		if !teamUsed[sh.TeamID] {
+			teamUsed[sh.TeamID] = true
+			usedOrder = append(usedOrder, sh.TeamID)
+			usedNames[sh.TeamID] = sh.TeamName
+		}
+	}
+
+	sort.Slice(order, func(i, j int) bool {
+		return strings.ToLower(totals[order[i]].username) < strings.ToLower(totals[order[j]].username)
+	})
+
+	var teams []repository.ListEventTeamsRow
+	listed := make(map[uuid.UUID]bool)
+	for _, t := range eventTeams {
+		if teamUsed[t.ID] {
+			teams = append(teams, t)
+			listed[t.ID] = true
+		}
+	}
+	for _, id := range usedOrder {
+		if !listed[id] {
+			teams = append(teams, repository.ListEventTeamsRow{ID: id, Name: usedNames[id]})
+		}
+	}
+
+	header := []string{"Username", "Full Name", "Display Name"}
+	for _, t := range teams {
+		header = append(header, t.Name+" (h)")
+	}
+	header = append(header, "Total (h)")
+
+	rows := [][]string{header}
+	for _, id := range order {
+		ut := totals[id]
+		row := []string{ut.username, ut.fullName, ut.displayName}
+		for _, t := range teams {
+			row = append(row, formatHours(ut.perTeam[t.ID]))
+		}
+		row = append(row, formatHours(ut.total))
+		rows = append(rows, row)
+	}
+	return rows
+}
+
 // ExportICalEvent generates an iCal (.ics) file for all shifts in an event.
 func (s *ExportService) ExportICalEvent(ctx context.Context, slug string) ([]byte, string, error) {
+	return s.exportICal(ctx, slug, nil, nil, nil)
+}
+
+// exportICal generates an iCal file for the shifts of an event, optionally
+// limited to a time range and teams. Cancellations are only included in the
+// unfiltered calendar.
+func (s *ExportService) exportICal(ctx context.Context, slug string, start, end *time.Time, teamIDs []string) ([]byte, string, error) {
 	event, err := s.queries.GetEventBySlug(ctx, slug)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -89,7 +384,24 @@ func (s *ExportService) ExportICalEvent(ctx context.Context, slug string) ([]byt
 		return nil, "", fmt.Errorf("listing shifts: %w", err)
 	}
 
-	cal := buildICalFromShifts(event.Name, event.Slug, shifts)
+	var deleted []repository.ListShiftDeletionsByEventRow
+	if start == nil && end == nil && len(teamIDs) == 0 {
+		deleted, err = s.queries.ListShiftDeletionsByEvent(ctx, event.ID, time.Now().Add(-icalCancellationWindow))
+		if err != nil {
+			return nil, "", fmt.Errorf("listing deleted shifts: %w", err)
+		}
+	} else {
+		rangeStart, rangeEnd := event.StartTime, event.EndTime
+		if start != nil {
+			rangeStart = *start
+		}
+		if end != nil {
+			rangeEnd = *end
+		}
+		shifts = pdf.FilterShifts(shifts, rangeStart, rangeEnd, nil, teamIDs)
+	}
+
+	cal := buildICalFromShifts(event, shifts, deleted, nil)
 	filename := fmt.Sprintf("%s-shifts.ics", event.Slug)
 	return []byte(cal), filename, nil
 }
@@ -132,36 +444,113 @@ func (s *ExportService) ExportPDF(ctx context.Context, slug string, opts pdf.PDF
 		HiddenRanges: hiddenRanges,
 	}
 
+	// Callers check CanExportPrivate before setting ShowPhones
+	if opts.ShowPhones {
+		phones, err := s.queries.ListUserPhonesByEvent(ctx, event.ID)
+		if err != nil {
+			return nil, "", fmt.Errorf("listing phone numbers: %w", err)
+		}
+		data.Phones = make(map[uuid.UUID]string, len(phones))
+		for _, p := range phones {
+			data.Phones[p.ID] = derefString(p.Phone)
+		}
+	}
+
+	if opts.Bundle == "zip" {
+		return s.exportPDFBundle(ctx, data, opts)
+	}
+
 	pdfBytes, err := s.pdfGen.Generate(ctx, data, opts)
 	if err != nil {
 		s.logger.Error("PDF generation failed", "slug", slug, "error", err)
 		return nil, "", fmt.Errorf("generating PDF: %w", err)
 	}
 
-	filename := fmt.Sprintf("%s-shifts.pdf", event.Slug)
+	filename := fmt.Sprintf("%s-%s.pdf", event.Slug, pdfFileSuffix(opts.Layout))
 	return pdfBytes, filename, nil
 }
 
+// exportPDFBundle renders a per-user layout once per user and packs the files
+// into a ZIP archive.
+func (s *ExportService) exportPDFBundle(ctx context.Context, data pdf.PDFData, opts pdf.PDFOptions) ([]byte, string, error) {
+	docs, err := s.pdfGen.GenerateEach(ctx, data, opts)
+	if err != nil {
+		s.logger.Error("PDF generation failed", "slug", data.Event.Slug, "error", err)
+		return nil, "", fmt.Errorf("generating PDFs: %w", err)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, d := range docs {
+		name := fmt.Sprintf("%s-%s-%s.pdf", data.Event.Slug, pdfFileSuffix(opts.Layout), safeFilename(d.Username))
+		f, err := zw.Create(name)
+		if err != nil {
+			return nil, "", fmt.Errorf("adding %s: %w", name, err)
+		}
+		if _, err := f.Write(d.Data); err != nil {
+			return nil, "", fmt.Errorf("writing %s: %w", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, "", fmt.Errorf("closing zip: %w", err)
+	}
+
+	filename := fmt.Sprintf("%s-%s.zip", data.Event.Slug, pdfFileSuffix(opts.Layout))
+	return buf.Bytes(), filename, nil
+}
+
+// pdfFileSuffix names export files after their layout.
+func pdfFileSuffix(layout string) string {
+	switch layout {
+	case "timesheet":
+		return "timesheets"
+	case "certificate":
+		return "certificates"
+	default:
+		return "shifts"
+	}
+}
+
+// safeFilename keeps letters, digits, dots, dashes and underscores.
+func safeFilename(s string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < utf8.RuneSelf && (r == '-' || r == '_' || r == '.' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return r
+		}
+		return '_'
+	}, s)
+	return strings.TrimLeft(name, ".")
+}
+
 // iCal Token management
 
 type ICalTokenResponse struct {
-	ID         string  `json:"id"`
-	Label      string  `json:"label"`
-	Scope      string  `json:"scope"`
-	EventID    *string `json:"event_id"`
-	TeamID     *string `json:"team_id"`
-	CreatedAt  string  `json:"created_at"`
-	LastUsedAt *string `json:"last_used_at"`
-	URL        string  `json:"url"`
+	ID           string  `json:"id"`
+	Label        string  `json:"label"`
+	Scope        string  `json:"scope"`
+	EventID      *string `json:"event_id"`
+	TeamID       *string `json:"team_id"`
+	CreatedAt    string  `json:"created_at"`
+	LastUsedAt   *string `json:"last_used_at"`
+	AlarmMinutes *int32  `json:"alarm_minutes"`
+	URL          string  `json:"url"`
 }
 
 type CreateICalTokenInput struct {
-	Label   string
-	Scope   string // "user", "event", "team"
-	EventID *uuid.UUID
-	TeamID  *uuid.UUID
+	Label        string
+	Scope        string // "user", "event", "team"
+	EventID      *uuid.UUID
+	TeamID       *uuid.UUID
+	AlarmMinutes *int32 // minutes before shift start; nil = no reminder
 }
 
+// maxICalAlarmMinutes caps VALARM reminders at one week before the shift.
+const maxICalAlarmMinutes = 7 * 24 * 60
+
+// icalCancellationWindow is how long deleted shifts keep appearing in feeds
+// as STATUS:CANCELLED before their tombstones are purged by cleanup.
+const icalCancellationWindow = 30 * 24 * time.Hour
+
 // CreateToken generates a new iCal subscription token for a user.
 func (s *ExportService) CreateToken(ctx context.Context, userID uuid.UUID, input CreateICalTokenInput, baseURL string) (ICalTokenResponse, error) {
 	if input.Label == "" {
@@ -179,6 +568,9 @@ func (s *ExportService) CreateToken(ctx context.Context, userID uuid.UUID, input
 	if input.Scope == "team" && (input.EventID == nil || input.TeamID == nil) {
 		return ICalTokenResponse{}, model.NewFieldError(model.ErrInvalidInput, "team_id", "event_id and team_id required for team scope")
 	}
+	if err := validateAlarmMinutes(input.AlarmMinutes); err != nil {
+		return ICalTokenResponse{}, err
+	}
 
 	// Generate random token
 	tokenBytes := make([]byte, 32)
@@ -192,13 +584,14 @@ func (s *ExportService) CreateToken(ctx context.Context, userID uuid.UUID, input
 	tokenHash := hex.EncodeToString(hash[:])
 
 	token, err := s.queries.CreateICalToken(ctx, repository.CreateICalTokenParams{
-		UserID:    userID,
-		TokenHash: tokenHash,
-		Token:     rawToken,
-		Label:     input.Label,
-		Scope:     input.Scope,
-		EventID:   input.EventID,
-		TeamID:    input.TeamID,
+		UserID:       userID,
+		TokenHash:    tokenHash,
+		Token:        rawToken,
+		Label:        input.Label,
+		Scope:        input.Scope,
+		EventID:      input.EventID,
+		TeamID:       input.TeamID,
+		AlarmMinutes: input.AlarmMinutes,
 	})
 	if err != nil {
 		return ICalTokenResponse{}, fmt.Errorf("creating token: %w", err)
@@ -220,7 +613,7 @@ func (s *ExportService) CreateToken(ctx context.Context, userID uuid.UUID, input
 	url := buildICalURL(baseURL, token.Scope, rawToken, userID.String(), eventSlug, teamAbbr)
 
 	s.logger.Info("iCal token created", "token_id", token.ID, "user_id", userID, "scope", input.Scope)
-	return toICalTokenResponse(token.ID, token.Label, token.Scope, token.EventID, token.TeamID, token.CreatedAt, token.LastUsedAt, url), nil
+	return toICalTokenResponse(token.ID, token.Label, token.Scope, token.EventID, token.TeamID, token.CreatedAt, token.LastUsedAt, token.AlarmMinutes, url), nil
 }
 
 // ListTokens returns all active iCal tokens for a user.
@@ -240,7 +633,7 @@ func (s *ExportService) ListTokens(ctx context.Context, userID uuid.UUID, baseUR
 			teamAbbr = *t.TeamAbbreviation
 		}
 		url := buildICalURL(baseURL, t.Scope, t.Token, t.UserID.String(), eventSlug, teamAbbr)
-		result[i] = toICalTokenResponse(t.ID, t.Label, t.Scope, t.EventID, t.TeamID, t.CreatedAt, t.LastUsedAt, url)
+		result[i] = toICalTokenResponse(t.ID, t.Label, t.Scope, t.EventID, t.TeamID, t.CreatedAt, t.LastUsedAt, t.AlarmMinutes, url)
 	}
 	return result, nil
 }
@@ -254,8 +647,46 @@ func (s *ExportService) RevokeToken(ctx context.Context, tokenID, userID uuid.UU
 	return nil
 }
 
-// ServeICalSubscription serves an iCal feed for a subscription token.
-func (s *ExportService) ServeICalSubscription(ctx context.Context, rawToken string) ([]byte, error) {
+// UpdateTokenAlarm changes the VALARM reminder of one of the user's tokens.
+// A nil alarm removes reminders from the feed.
+func (s *ExportService) UpdateTokenAlarm(ctx context.Context, tokenID, userID uuid.UUID, alarmMinutes *int32) error {
+	if err := validateAlarmMinutes(alarmMinutes); err != nil {
+		return err
+	}
+	n, err := s.queries.UpdateICalTokenAlarm(ctx, tokenID, userID, alarmMinutes)
+	if err != nil {
+		return fmt.Errorf("updating token alarm: %w", err)
+	}
+	if n == 0 {
+		return model.NewDomainError(model.ErrNotFound, "token not found")
+	}
+	return nil
+}
+
+func validateAlarmMinutes(alarmMinutes *int32) error {
+	if alarmMinutes != nil && (*alarmMinutes < 0 || *alarmMinutes > maxICalAlarmMinutes) {
+		return model.NewFieldError(model.ErrInvalidInput, "alarm_minutes", fmt.Sprintf("must be between 0 and %d", maxICalAlarmMinutes))
+	}
+	return nil
+}
+
+// icalFeedFormat is part of every feed ETag. Bump it when the rendered
+// calendar changes for the same data, so clients don't keep stale copies.
+const icalFeedFormat = 2
+
+// ICalSubscription is a resolved iCal subscription token.
+type ICalSubscription struct {
+	token repository.GetICalTokenByHashRow
+	// ETag of the feed, see OpenICalSubscription
+	ETag string
+}
+
+// OpenICalSubscription resolves a subscription token and computes the feed's
+// ETag without rendering it: it covers the token's settings, the update time
+// and plan version of every event in the feed, which shift changes bump, the
+// last change to the users and teams the feed names, and the tombstones still
+// inside the cancellation window.
+func (s *ExportService) OpenICalSubscription(ctx context.Context, rawToken string) (*ICalSubscription, error) {
 	hash := sha256.Sum256([]byte(rawToken))
 	tokenHash := hex.EncodeToString(hash[:])
 
@@ -272,6 +703,48 @@ func (s *ExportService) ServeICalSubscription(ctx context.Context, rawToken stri
 		s.queries.UpdateICalTokenLastUsed(context.Background(), tokenRow.ID)
 	}()
 
+	params := repository.ListICalFeedPlanStatesParams{
+		EventID:      tokenRow.EventID,
+		DeletedAfter: time.Now().Add(-icalCancellationWindow),
+	}
+	if tokenRow.Scope == "user" {
+		params.EventID, params.UserID = nil, &tokenRow.UserID
+	}
+	states, err := s.queries.ListICalFeedPlanStates(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("fetching plan versions: %w", err)
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%d|%s|%s|%s|", icalFeedFormat, tokenRow.ID, tokenRow.Scope, tokenRow.Username)
+	if tokenRow.AlarmMinutes != nil {
+		fmt.Fprintf(h, "alarm=%d|", *tokenRow.AlarmMinutes)
+	}
+	for _, st := range states {
+		fmt.Fprintf(h, "%s@%d/%d|", st.ID, st.UpdatedAt.UnixNano(), st.Version)
+		// Renames show up in summaries and co-worker lines, and expiring
+		// tombstones drop cancellations without a plan change.
+		if st.NamesUpdatedAt != nil {
+			fmt.Fprintf(h, "names=%d|", st.NamesUpdatedAt.UnixNano())
+		}
+		fmt.Fprintf(h, "deleted=%d", st.Tombstones)
+		if st.OldestTombstone != nil {
+			fmt.Fprintf(h, "@%d", st.OldestTombstone.UnixNano())
+		}
+		h.Write([]byte("|"))
+	}
+	sum := h.Sum(nil)
+
+	return &ICalSubscription{
+		token: tokenRow,
+		ETag:  `"` + hex.EncodeToString(sum[:16]) + `"`,
+	}, nil
+}
+
+// ServeICalSubscription renders the iCal feed of an opened subscription.
+func (s *ExportService) ServeICalSubscription(ctx context.Context, sub *ICalSubscription) ([]byte, error) {
+	tokenRow := sub.token
+	deletedSince := time.Now().Add(-icalCancellationWindow)
 	var cal string
 
 	switch tokenRow.Scope {
@@ -280,7 +753,30 @@ func (s *ExportService) ServeICalSubscription(ctx context.Context, rawToken stri
 		if err != nil {
 			return nil, fmt.Errorf("listing user shifts: %w", err)
 		}
-		cal = buildICalFromUserShifts(tokenRow.Username, shifts)
+		deleted, err := s.queries.ListShiftDeletionsByUser(ctx, tokenRow.UserID, deletedSince)
+		if err != nil {
+			return nil, fmt.Errorf("listing deleted shifts: %w", err)
+		}
+
+		// Load each event once for its location and co-worker lookup
+		events := make(map[uuid.UUID]repository.Event)
+		eventShifts := make(map[uuid.UUID][]repository.ListShiftsByEventRow)
+		for _, sh := range shifts {
+			if _, ok := events[sh.EventID]; ok {
+				continue
+			}
+			event, err := s.queries.GetEventByID(ctx, sh.EventID)
+			if err != nil {
+				return nil, fmt.Errorf("fetching event: %w", err)
+			}
+			all, err := s.queries.ListShiftsByEvent(ctx, sh.EventID)
+			if err != nil {
+				return nil, fmt.Errorf("listing event shifts: %w", err)
+			}
+			events[sh.EventID] = event
+			eventShifts[sh.EventID] = all
+		}
+		cal = buildICalFromUserShifts(tokenRow.Username, shifts, events, eventShifts, deleted, tokenRow.AlarmMinutes)
 
 	case "event":
 		if tokenRow.EventID == nil {
@@ -294,7 +790,11 @@ func (s *ExportService) ServeICalSubscription(ctx context.Context, rawToken stri
 		if err != nil {
 			return nil, fmt.Errorf("listing event shifts: %w", err)
 		}
-		cal = buildICalFromShifts(event.Name, event.Slug, shifts)
+		deleted, err := s.queries.ListShiftDeletionsByEvent(ctx, event.ID, deletedSince)
+		if err != nil {
+			return nil, fmt.Errorf("listing deleted shifts: %w", err)
+		}
+		cal = buildICalFromShifts(event, shifts, deleted, tokenRow.AlarmMinutes)
 
 	case "team":
 		if tokenRow.EventID == nil || tokenRow.TeamID == nil {
@@ -304,14 +804,19 @@ func (s *ExportService) ServeICalSubscription(ctx context.Context, rawToken stri
 		if err != nil {
 			return nil, fmt.Errorf("fetching event: %w", err)
 		}
-		shifts, err := s.queries.ListShiftsByEventAndTeam(ctx, repository.ListShiftsByEventAndTeamParams{
-			EventID: *tokenRow.EventID,
-			TeamID:  *tokenRow.TeamID,
-		})
+		team, err := s.queries.GetTeamByID(ctx, *tokenRow.TeamID)
+		if err != nil {
+			return nil, fmt.Errorf("fetching team: %w", err)
+		}
+		shifts, err := s.queries.ListShiftsByEvent(ctx, event.ID)
 		if err != nil {
 			return nil, fmt.Errorf("listing team shifts: %w", err)
 		}
-		cal = buildICalFromTeamShifts(event.Name, event.Slug, shifts)
+		deleted, err := s.queries.ListShiftDeletionsByEvent(ctx, event.ID, deletedSince)
+		if err != nil {
+			return nil, fmt.Errorf("listing deleted shifts: %w", err)
+		}
+		cal = buildICalFromTeamShifts(event, team, shifts, deleted, tokenRow.AlarmMinutes)
 
 	default:
 		return nil, model.NewDomainError(model.ErrInvalidInput, "unknown token scope")
@@ -322,20 +827,70 @@ func (s *ExportService) ServeICalSubscription(ctx context.Context, rawToken stri
 
 // iCal generation helpers
 
-func buildICalFromShifts(eventName, eventSlug string, shifts []repository.ListShiftsByEventRow) string {
+// icalEntry is one VEVENT. UIDs are the shift IDs, so clients can match
+// updates and cancellations to the events they already have.
+type icalEntry struct {
+	ID          uuid.UUID
+	Start       time.Time
+	End         time.Time
+	Created     time.Time
+	Modified    time.Time
+	Sequence    int32
+	Summary     string
+	Description string
+	Location    string
+	Cancelled   bool
+}
+
+type icalCalendar struct {
+	ProdID       string
+	Name         string
+	AlarmMinutes *int32
+	Entries      []icalEntry
+}
+
+const icalTimeFormat = "20060102T150405Z"
+
+// render writes the calendar. Output only depends on stored data (DTSTAMP
+// is the last modification, not the fetch time), so a feed's ETag describes
+// its content.
+func (c icalCalendar) render() string {
 	var b strings.Builder
 	b.WriteString("BEGIN:VCALENDAR\r\n")
 	b.WriteString("VERSION:2.0\r\n")
-	b.WriteString(fmt.Sprintf("PRODID:-//Rncasp//%s//EN\r\n", eventSlug))
-	b.WriteString(fmt.Sprintf("X-WR-CALNAME:%s Shifts\r\n", eventName))
+	writeICalLine(&b, "PRODID", fmt.Sprintf("-//Rncasp//%s//EN", c.ProdID))
+	b.WriteString("CALSCALE:GREGORIAN\r\n")
+	b.WriteString("METHOD:PUBLISH\r\n")
+	writeICalLine(&b, "X-WR-CALNAME", escapeICalText(c.Name))
 
-	for _, sh := range shifts {
+	for _, e := range c.Entries {
 		b.WriteString("BEGIN:VEVENT\r\n")
-		b.WriteString(fmt.Sprintf("UID:%s@rncasp\r\n", sh.ID.String()))
-		b.WriteString(fmt.Sprintf("DTSTART:%s\r\n", sh.StartTime.UTC().Format("20060102T150405Z")))
-		b.WriteString(fmt.Sprintf("DTEND:%s\r\n", sh.EndTime.UTC().Format("20060102T150405Z")))
-		b.WriteString(fmt.Sprintf("SUMMARY:%s - %s\r\n", sh.TeamName, sh.Username))
-		b.WriteString(fmt.Sprintf("DESCRIPTION:%s (%s)\r\n", sh.UserFullName, sh.TeamAbbreviation))
+		b.WriteString(fmt.Sprintf("UID:%s@rncasp\r\n", e.ID.String()))
+		b.WriteString(fmt.Sprintf("DTSTAMP:%s\r\n", e.Modified.UTC().Format(icalTimeFormat)))
+		b.WriteString(fmt.Sprintf("CREATED:%s\r\n", e.Created.UTC().Format(icalTimeFormat)))
+		b.WriteString(fmt.Sprintf("LAST-MODIFIED:%s\r\n", e.Modified.UTC().Format(icalTimeFormat)))
+		b.WriteString(fmt.Sprintf("SEQUENCE:%d\r\n", e.Sequence))
+		b.WriteString(fmt.Sprintf("DTSTART:%s\r\n", e.Start.UTC().Format(icalTimeFormat)))
+		b.WriteString(fmt.Sprintf("DTEND:%s\r\n", e.End.UTC().Format(icalTimeFormat)))
+		writeICalLine(&b, "SUMMARY", escapeICalText(e.Summary))
+		if e.Description != "" {
+			writeICalLine(&b, "DESCRIPTION", escapeICalText(e.Description))
+		}
+		if e.Location != "" {
+			writeICalLine(&b, "LOCATION", escapeICalText(e.Location))
+		}
+		if e.Cancelled {
+			b.WriteString("STATUS:CANCELLED\r\n")
+		} else {
+			b.WriteString("STATUS:CONFIRMED\r\n")
+			if c.AlarmMinutes != nil {
+				b.WriteString("BEGIN:VALARM\r\n")
+				b.WriteString("ACTION:DISPLAY\r\n")
+				writeICalLine(&b, "DESCRIPTION", escapeICalText(e.Summary))
+				b.WriteString(fmt.Sprintf("TRIGGER:-PT%dM\r\n", *c.AlarmMinutes))
+				b.WriteString("END:VALARM\r\n")
+			}
+		}
 		b.WriteString("END:VEVENT\r\n")
 	}
 
@@ -343,51 +898,192 @@ func buildICalFromShifts(eventName, eventSlug string, shifts []repository.ListSh
 	return b.String()
 }
 
-func buildICalFromUserShifts(username string, shifts []repository.ListShiftsByUserRow) string {
-	var b strings.Builder
-	b.WriteString("BEGIN:VCALENDAR\r\n")
-	b.WriteString("VERSION:2.0\r\n")
-	b.WriteString(fmt.Sprintf("PRODID:-//Rncasp//%s//EN\r\n", username))
-	b.WriteString(fmt.Sprintf("X-WR-CALNAME:%s's Shifts\r\n", username))
+var icalTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// escapeICalText escapes a TEXT value per RFC 5545 section 3.3.11.
+func escapeICalText(s string) string {
+	return icalTextEscaper.Replace(s)
+}
+
+// writeICalLine writes "name:value" folded at 75 octets (RFC 5545 section
+// 3.1) without splitting multi-byte UTF-8 sequences.
+func writeICalLine(b *strings.Builder, name, value string) {
+	line := name + ":" + value
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74 // continuation lines start with a space
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+// icalDescription lists team, event, location and co-workers, one per line.
+func icalDescription(teamName, teamAbbr, eventName, location string, coworkers []string) string {
+	lines := []string{fmt.Sprintf("Team: %s (%s)", teamName, teamAbbr)}
+	if eventName != "" {
+		lines = append(lines, "Event: "+eventName)
+	}
+	if location != "" {
+		lines = append(lines, "Location: "+location)
+	}
+	if len(coworkers) > 0 {
+		lines = append(lines, "Co-workers: "+strings.Join(coworkers, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// icalCoworkers returns the other users working on the same team during an
+// overlapping time slot, in the order of the given shift list.
+func icalCoworkers(all []repository.ListShiftsByEventRow, shiftID, userID, teamID uuid.UUID, start, end time.Time) []string {
+	var names []string
+	seen := map[uuid.UUID]bool{userID: true}
+	for _, other := range all {
+		if other.ID == shiftID || other.TeamID != teamID || seen[other.UserID] {
+			continue
+		}
+		if other.StartTime.Before(end) && other.EndTime.After(start) {
+			seen[other.UserID] = true
+			name := other.Username
+			if other.UserDisplayName != nil && *other.UserDisplayName != "" {
+				name = *other.UserDisplayName
+			}
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func buildICalFromShifts(event repository.Event, shifts []repository.ListShiftsByEventRow, deleted []repository.ListShiftDeletionsByEventRow, alarmMinutes *int32) string {
+	cal := icalCalendar{
+		ProdID:       event.Slug,
+		Name:         fmt.Sprintf("%s Shifts", event.Name),
+		AlarmMinutes: alarmMinutes,
+	}
+	location := derefString(event.Location)
 
 	for _, sh := range shifts {
-		b.WriteString("BEGIN:VEVENT\r\n")
-		b.WriteString(fmt.Sprintf("UID:%s@rncasp\r\n", sh.ID.String()))
-		b.WriteString(fmt.Sprintf("DTSTART:%s\r\n", sh.StartTime.UTC().Format("20060102T150405Z")))
-		b.WriteString(fmt.Sprintf("DTEND:%s\r\n", sh.EndTime.UTC().Format("20060102T150405Z")))
-		b.WriteString(fmt.Sprintf("SUMMARY:%s - %s\r\n", sh.EventName, sh.TeamName))
-		b.WriteString(fmt.Sprintf("DESCRIPTION:Event: %s, Team: %s (%s)\r\n", sh.EventName, sh.TeamName, sh.TeamAbbreviation))
-		b.WriteString("END:VEVENT\r\n")
+		cal.Entries = append(cal.Entries, icalEntry{
+			ID:          sh.ID,
+			Start:       sh.StartTime,
+			End:         sh.EndTime,
+			Created:     sh.CreatedAt,
+			Modified:    sh.UpdatedAt,
+			Sequence:    sh.Sequence,
+			Summary:     fmt.Sprintf("%s - %s", sh.TeamName, sh.Username),
+			Description: icalDescription(sh.TeamName, sh.TeamAbbreviation, "", location, icalCoworkers(shifts, sh.ID, sh.UserID, sh.TeamID, sh.StartTime, sh.EndTime)),
+			Location:    location,
+		})
+	}
+	for _, d := range deleted {
+		cal.Entries = append(cal.Entries, deletedEventEntry(d, fmt.Sprintf("%s - %s", d.TeamName, d.Username), location))
 	}
 
-	b.WriteString("END:VCALENDAR\r\n")
-	return b.String()
+	return cal.render()
 }
 
-func buildICalFromTeamShifts(eventName, eventSlug string, shifts []repository.ListShiftsByEventAndTeamRow) string {
-	var b strings.Builder
-	b.WriteString("BEGIN:VCALENDAR\r\n")
-	b.WriteString("VERSION:2.0\r\n")
-	b.WriteString(fmt.Sprintf("PRODID:-//Rncasp//%s//EN\r\n", eventSlug))
+func buildICalFromUserShifts(username string, shifts []repository.ListShiftsByUserRow, events map[uuid.UUID]repository.Event, eventShifts map[uuid.UUID][]repository.ListShiftsByEventRow, deleted []repository.ListShiftDeletionsByUserRow, alarmMinutes *int32) string {
+	cal := icalCalendar{
+		ProdID:       username,
+		Name:         fmt.Sprintf("%s's Shifts", username),
+		AlarmMinutes: alarmMinutes,
+	}
+
+	for _, sh := range shifts {
+		location := derefString(events[sh.EventID].Location)
+		coworkers := icalCoworkers(eventShifts[sh.EventID], sh.ID, sh.UserID, sh.TeamID, sh.StartTime, sh.EndTime)
+		cal.Entries = append(cal.Entries, icalEntry{
+			ID:          sh.ID,
+			Start:       sh.StartTime,
+			End:         sh.EndTime,
+			Created:     sh.CreatedAt,
+			Modified:    sh.UpdatedAt,
+			Sequence:    sh.Sequence,
+			Summary:     fmt.Sprintf("%s - %s", sh.EventName, sh.TeamName),
+			Description: icalDescription(sh.TeamName, sh.TeamAbbreviation, sh.EventName, location, coworkers),
+			Location:    location,
+		})
+	}
+	for _, d := range deleted {
+		cal.Entries = append(cal.Entries, icalEntry{
+			ID:          d.ShiftID,
+			Start:       d.StartTime,
+			End:         d.EndTime,
+			Created:     d.CreatedAt,
+			Modified:    d.DeletedAt,
+			Sequence:    d.Sequence,
+			Summary:     fmt.Sprintf("%s - %s", d.EventName, d.TeamName),
+			Description: icalDescription(d.TeamName, d.TeamAbbreviation, d.EventName, "", nil),
+			Cancelled:   true,
+		})
+	}
+
+	return cal.render()
+}
 
-	teamName := "Team"
-	if len(shifts) > 0 {
-		teamName = shifts[0].TeamName
+func buildICalFromTeamShifts(event repository.Event, team repository.Team, shifts []repository.ListShiftsByEventRow, deleted []repository.ListShiftDeletionsByEventRow, alarmMinutes *int32) string {
+	cal := icalCalendar{
+		ProdID:       event.Slug,
+		Name:         fmt.Sprintf("%s - %s Shifts", event.Name, team.Name),
+		AlarmMinutes: alarmMinutes,
 	}
-	b.WriteString(fmt.Sprintf("X-WR-CALNAME:%s - %s Shifts\r\n", eventName, teamName))
+	location := derefString(event.Location)
 
+	var teamShifts []repository.ListShiftsByEventRow
 	for _, sh := range shifts {
-		b.WriteString("BEGIN:VEVENT\r\n")
-		b.WriteString(fmt.Sprintf("UID:%s@rncasp\r\n", sh.ID.String()))
-		b.WriteString(fmt.Sprintf("DTSTART:%s\r\n", sh.StartTime.UTC().Format("20060102T150405Z")))
-		b.WriteString(fmt.Sprintf("DTEND:%s\r\n", sh.EndTime.UTC().Format("20060102T150405Z")))
-		b.WriteString(fmt.Sprintf("SUMMARY:%s - %s\r\n", sh.TeamName, sh.Username))
-		b.WriteString(fmt.Sprintf("DESCRIPTION:%s (%s)\r\n", sh.UserFullName, sh.TeamAbbreviation))
-		b.WriteString("END:VEVENT\r\n")
+		if sh.TeamID == team.ID {
+			teamShifts = append(teamShifts, sh)
+		}
 	}
 
-	b.WriteString("END:VCALENDAR\r\n")
-	return b.String()
+	for _, sh := range teamShifts {
+		cal.Entries = append(cal.Entries, icalEntry{
+			ID:          sh.ID,
+			Start:       sh.StartTime,
+			End:         sh.EndTime,
+			Created:     sh.CreatedAt,
+			Modified:    sh.UpdatedAt,
+			Sequence:    sh.Sequence,
+			Summary:     fmt.Sprintf("%s - %s", sh.TeamName, sh.Username),
+			Description: icalDescription(sh.TeamName, sh.TeamAbbreviation, "", location, icalCoworkers(teamShifts, sh.ID, sh.UserID, sh.TeamID, sh.StartTime, sh.EndTime)),
+			Location:    location,
+		})
+	}
+	for _, d := range deleted {
+		if d.TeamID == team.ID {
+			cal.Entries = append(cal.Entries, deletedEventEntry(d, fmt.Sprintf("%s - %s", d.TeamName, d.Username), location))
+		}
+	}
+
+	return cal.render()
+}
+
+func deletedEventEntry(d repository.ListShiftDeletionsByEventRow, summary, location string) icalEntry {
+	return icalEntry{
+		ID:          d.ShiftID,
+		Start:       d.StartTime,
+		End:         d.EndTime,
+		Created:     d.CreatedAt,
+		Modified:    d.DeletedAt,
+		Sequence:    d.Sequence,
+		Summary:     summary,
+		Description: icalDescription(d.TeamName, d.TeamAbbreviation, "", location, nil),
+		Location:    location,
+		Cancelled:   true,
+	}
 }
 
 func buildICalURL(baseURL, scope, token, userUUID, eventSlug, teamAbbr string) string {
@@ -403,7 +1099,7 @@ func buildICalURL(baseURL, scope, token, userUUID, eventSlug, teamAbbr string) s
 	}
 }
 
-func toICalTokenResponse(id uuid.UUID, label, scope string, eventID, teamID *uuid.UUID, createdAt time.Time, lastUsedAt *time.Time, url string) ICalTokenResponse {
+func toICalTokenResponse(id uuid.UUID, label, scope string, eventID, teamID *uuid.UUID, createdAt time.Time, lastUsedAt *time.Time, alarmMinutes *int32, url string) ICalTokenResponse {
 	var eventIDStr *string
 	if eventID != nil {
 		s := eventID.String()
@@ -421,13 +1117,14 @@ func toICalTokenResponse(id uuid.UUID, label, scope string, eventID, teamID *uui
 	}
 
 	return ICalTokenResponse{
-		ID:         id.String(),
-		Label:      label,
-		Scope:      scope,
-		EventID:    eventIDStr,
-		TeamID:     teamIDStr,
-		CreatedAt:  createdAt.Format(time.RFC3339),
-		LastUsedAt: lastUsed,
-		URL:        url,
+		ID:           id.String(),
+		Label:        label,
+		Scope:        scope,
+		EventID:      eventIDStr,
+		TeamID:       teamIDStr,
+		CreatedAt:    createdAt.Format(time.RFC3339),
+		LastUsedAt:   lastUsed,
+		AlarmMinutes: alarmMinutes,
+		URL:          url,
 	}
 }
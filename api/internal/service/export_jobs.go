@@ -0,0 +1,493 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/pdf"
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+// Export job states.
+const (
+	ExportJobQueued  = "queued"
+	ExportJobRunning = "running"
+	ExportJobDone    = "done"
+	ExportJobFailed  = "failed"
+)
+
+const (
+	exportQueueTimeout    = 5 * time.Minute
+	exportRenderTimeout   = 2 * time.Minute
+	exportJobPollInterval = 250 * time.Millisecond
+)
+
+// ExportJobService renders expensive exports in the background. At most
+// maxConcurrent renders run at once per instance; results are cached in Redis
+// keyed by event, plan version and options, so repeated downloads of an
+// unchanged plan are served without rendering. Job state also lives in Redis,
+// so any instance can report status and serve the download.
+type ExportJobService struct {
+	queries       *repository.Queries
+	rdb           *redis.Client
+	logger        *slog.Logger
+	exportService *ExportService
+	slots         chan struct{}
+	cacheTTL      time.Duration
+	maxQueued     int64
+	queued        atomic.Int64
+
+	mu      sync.Mutex
+	running map[string]chan struct{} // jobs rendering on this instance
+}
+
+func NewExportJobService(queries *repository.Queries, rdb *redis.Client, logger *slog.Logger, exportService *ExportService, maxConcurrent int, cacheTTL time.Duration) *ExportJobService {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	if cacheTTL <= 0 {
+		cacheTTL = 24 * time.Hour
+	}
+	return &ExportJobService{
+		queries:       queries,
+		rdb:           rdb,
+		logger:        logger,
+		exportService: exportService,
+		slots:         make(chan struct{}, maxConcurrent),
+		cacheTTL:      cacheTTL,
+		maxQueued:     int64(maxConcurrent) * 20,
+		running:       make(map[string]chan struct{}),
+	}
+}
+
+type ExportJobResponse struct {
+	ID          string  `json:"id"`
+	Kind        string  `json:"kind"`
+	EventSlug   string  `json:"event_slug"`
+	Status      string  `json:"status"`
+	Progress    int     `json:"progress"`
+	Error       *string `json:"error"`
+	Filename    *string `json:"filename"`
+	DownloadURL *string `json:"download_url"`
+	CreatedAt   string  `json:"created_at"`
+	FinishedAt  *string `json:"finished_at"`
+}
+
+// exportJobRecord is the job state stored in Redis.
+type exportJobRecord struct {
+	ID         string     `json:"id"`
+	Kind       string     `json:"kind"`
+	EventSlug  string     `json:"event_slug"`
+	Status     string     `json:"status"`
+	Progress   int        `json:"progress"`
+	Error      string     `json:"error,omitempty"`
+	Filename   string     `json:"filename,omitempty"`
+	CacheKey   string     `json:"cache_key"`
+	FollowID   string     `json:"follow_id,omitempty"` // identical job rendering elsewhere
+	OwnerID    *uuid.UUID `json:"owner_id,omitempty"`
+	Public     bool       `json:"public"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+func (r *exportJobRecord) final() bool {
+	return r.Status == ExportJobDone || r.Status == ExportJobFailed
+}
+
+func (r *exportJobRecord) response() ExportJobResponse {
+	resp := ExportJobResponse{
+		ID:        r.ID,
+		Kind:      r.Kind,
+		EventSlug: r.EventSlug,
+		Status:    r.Status,
+		Progress:  r.Progress,
+		CreatedAt: r.CreatedAt.Format(time.RFC3339),
+	}
+	if r.Error != "" {
+		resp.Error = &r.Error
+	}
+	if r.Filename != "" {
+		resp.Filename = &r.Filename
+	}
+	if r.FinishedAt != nil {
+		f := r.FinishedAt.Format(time.RFC3339)
+		resp.FinishedAt = &f
+	}
+	return resp
+}
+
+type exportRenderFunc func(ctx context.Context) ([]byte, string, error)
+
+// SubmitPDF starts a background PDF export and returns the job.
+func (s *ExportJobService) SubmitPDF(ctx context.Context, slug string, opts pdf.PDFOptions, ownerID *uuid.UUID, public bool) (*ExportJobResponse, error) {
+	rec, err := s.submit(ctx, slug, "pdf", opts, ownerID, public, func(ctx context.Context) ([]byte, string, error) {
+		return s.exportService.ExportPDF(ctx, slug, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := rec.response()
+	return &resp, nil
+}
+
+// RunPDF renders a PDF through the job queue and waits for it, so synchronous
+// downloads share the cache and the concurrency limit.
+func (s *ExportJobService) RunPDF(ctx context.Context, slug string, opts pdf.PDFOptions) ([]byte, string, error) {
+	rec, err := s.submit(ctx, slug, "pdf", opts, nil, false, func(ctx context.Context) ([]byte, string, error) {
+		return s.exportService.ExportPDF(ctx, slug, opts)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return s.waitForResult(ctx, rec.ID)
+}
+
+// waitForResult blocks until a job finishes and returns its file.
+func (s *ExportJobService) waitForResult(ctx context.Context, id string) ([]byte, string, error) {
+	rec, err := s.wait(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if rec.Status == ExportJobFailed {
+		return nil, "", fmt.Errorf("export job %s failed: %s", rec.ID, rec.Error)
+	}
+	return s.result(ctx, rec)
+}
+
+// Get returns the status of a job. Public callers can only see jobs started
+// through the public endpoints; signed-in users see their own jobs, super
+// admins all of them.
+func (s *ExportJobService) Get(ctx context.Context, id string, viewerID *uuid.UUID, viewerRole string, public bool) (*ExportJobResponse, error) {
+	rec, err := s.authorizedJob(ctx, id, viewerID, viewerRole, public)
+	if err != nil {
+		return nil, err
+	}
+	resp := rec.response()
+	return &resp, nil
+}
+
+// Download returns the file of a finished job.
+func (s *ExportJobService) Download(ctx context.Context, id string, viewerID *uuid.UUID, viewerRole string, public bool) ([]byte, string, error) {
+	rec, err := s.authorizedJob(ctx, id, viewerID, viewerRole, public)
+	if err != nil {
+		return nil, "", err
+	}
+	switch rec.Status {
+	case ExportJobDone:
+		return s.result(ctx, rec)
+	case ExportJobFailed:
+		return nil, "", model.NewDomainError(model.ErrConflict, "export failed")
+	default:
+		return nil, "", model.NewDomainError(model.ErrConflict, "export is not ready yet")
+	}
+}
+
+func (s *ExportJobService) authorizedJob(ctx context.Context, id string, viewerID *uuid.UUID, viewerRole string, public bool) (*exportJobRecord, error) {
+	rec, err := s.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	allowed := rec.Public
+	if !public && !allowed {
+		allowed = viewerRole == "super_admin" ||
+			(viewerID != nil && rec.OwnerID != nil && *viewerID == *rec.OwnerID)
+	}
+	if !allowed {
+		return nil, model.NewDomainError(model.ErrNotFound, "export job not found")
+	}
+	return rec, nil
+}
+
+func (s *ExportJobService) submit(ctx context.Context, slug, kind string, opts any, ownerID *uuid.UUID, public bool, render exportRenderFunc) (*exportJobRecord, error) {
+	event, err := s.queries.GetEventBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.NewDomainError(model.ErrNotFound, "event not found")
+		}
+		return nil, fmt.Errorf("fetching event: %w", err)
+	}
+	version, err := s.queries.GetEventPlanVersion(ctx, event.ID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching plan version: %w", err)
+	}
+
+	now := time.Now()
+	rec := &exportJobRecord{
+		ID:        uuid.NewString(),
+		Kind:      kind,
+		EventSlug: slug,
+		Status:    ExportJobQueued,
+		CacheKey:  exportCacheKey(event, version, kind, opts),
+		OwnerID:   ownerID,
+		Public:    public,
+		CreatedAt: now,
+	}
+
+	// Cached result: the job is done right away
+	if name, err := s.rdb.HGet(ctx, exportResultKey(rec.CacheKey), "name").Result(); err == nil {
+		rec.Status = ExportJobDone
+		rec.Progress = 100
+		rec.Filename = name
+		rec.FinishedAt = &now
+		return rec, s.save(ctx, rec)
+	}
+
+	// Follow an identical render that is already queued or running. If the
+	// leader finishes between SetNX and Get, try to become the leader again.
+	won := false
+	for attempt := 0; attempt < 3; attempt++ {
+		won, err = s.rdb.SetNX(ctx, exportInflightKey(rec.CacheKey), rec.ID, exportQueueTimeout+exportRenderTimeout).Result()
+		if err != nil {
+			return nil, fmt.Errorf("locking export: %w", err)
+		}
+		if won {
+			break
+		}
+		leaderID, err := s.rdb.Get(ctx, exportInflightKey(rec.CacheKey)).Result()
+		if err == nil {
+			rec.FollowID = leaderID
+			return rec, s.save(ctx, rec)
+		}
+		if !errors.Is(err, redis.Nil) {
+			return nil, fmt.Errorf("fetching export lock: %w", err)
+		}
+	}
+	if !won {
+		return nil, errors.New("locking export: lock kept changing hands")
+	}
+
+	if s.queued.Load() >= s.maxQueued {
+		s.releaseInflight(ctx, rec)
+		return nil, model.NewDomainError(model.ErrTooManyRequests, "too many exports in progress, try again later")
+	}
+	if err := s.save(ctx, rec); err != nil {
+		s.releaseInflight(ctx, rec)
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	s.mu.Lock()
+	s.running[rec.ID] = done
+	s.mu.Unlock()
+	s.queued.Add(1)
+
+	go s.run(rec, render, done)
+	return rec, nil
+}
+
+func (s *ExportJobService) run(rec *exportJobRecord, render exportRenderFunc, done chan struct{}) {
+	ctx := context.Background()
+	defer func() {
+		s.releaseInflight(ctx, rec)
+		s.mu.Lock()
+		delete(s.running, rec.ID)
+		s.mu.Unlock()
+		close(done)
+	}()
+
+	fail := func(msg string, err error) {
+		s.logger.Error("export job failed", "job", rec.ID, "event", rec.EventSlug, "kind", rec.Kind, "error", err)
+		now := time.Now()
+		rec.Status = ExportJobFailed
+		rec.Error = msg
+		rec.FinishedAt = &now
+		if err := s.save(ctx, rec); err != nil {
+			s.logger.Error("failed to save export job", "job", rec.ID, "error", err)
+		}
+	}
+
+	// Wait for a render slot
+	select {
+	case s.slots <- struct{}{}:
+		s.queued.Add(-1)
+	case <-time.After(exportQueueTimeout):
+		s.queued.Add(-1)
+		fail("export timed out in queue", errors.New("queue timeout"))
+		return
+	}
+	defer func() { <-s.slots }()
+
+	rec.Status = ExportJobRunning
+	rec.Progress = 10
+	if err := s.save(ctx, rec); err != nil {
+		s.logger.Error("failed to save export job", "job", rec.ID, "error", err)
+	}
+
+	renderCtx, cancel := context.WithTimeout(ctx, exportRenderTimeout)
+	defer cancel()
+	data, filename, err := render(renderCtx)
+	if err != nil {
+		fail("export failed", err)
+		return
+	}
+
+	resultKey := exportResultKey(rec.CacheKey)
+	pipe := s.rdb.TxPipeline()
+	pipe.HSet(ctx, resultKey, "name", filename, "data", data)
+	pipe.Expire(ctx, resultKey, s.cacheTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		fail("export could not be stored", err)
+		return
+	}
+
+	now := time.Now()
+	rec.Status = ExportJobDone
+	rec.Progress = 100
+	rec.Filename = filename
+	rec.FinishedAt = &now
+	if err := s.save(ctx, rec); err != nil {
+		s.logger.Error("failed to save export job", "job", rec.ID, "error", err)
+	}
+	s.logger.Info("export job finished", "job", rec.ID, "event", rec.EventSlug, "kind", rec.Kind, "bytes", len(data))
+}
+
+// wait blocks until the job is done or failed.
+func (s *ExportJobService) wait(ctx context.Context, id string) (*exportJobRecord, error) {
+	s.mu.Lock()
+	done := s.running[id]
+	s.mu.Unlock()
+	if done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	// Jobs rendering on another instance are polled
+	for {
+		rec, err := s.load(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if rec.final() {
+			return rec, nil
+		}
+		select {
+		case <-time.After(exportJobPollInterval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// load reads a job, resolving the state of jobs that follow another render.
+func (s *ExportJobService) load(ctx context.Context, id string) (*exportJobRecord, error) {
+	raw, err := s.rdb.Get(ctx, exportJobKey(id)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, model.NewDomainError(model.ErrNotFound, "export job not found")
+		}
+		return nil, fmt.Errorf("loading export job: %w", err)
+	}
+	var rec exportJobRecord
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return nil, fmt.Errorf("decoding export job: %w", err)
+	}
+
+	if rec.FollowID != "" && !rec.final() {
+		leader, err := s.load(ctx, rec.FollowID)
+		if err != nil {
+			var domainErr *model.DomainError
+			if !errors.As(err, &domainErr) {
+				return nil, err
+			}
+			// The leader expired before finishing
+			now := time.Now()
+			leader = &exportJobRecord{Status: ExportJobFailed, Error: "export failed", FinishedAt: &now}
+		}
+		rec.Status, rec.Progress, rec.Error, rec.Filename, rec.FinishedAt =
+			leader.Status, leader.Progress, leader.Error, leader.Filename, leader.FinishedAt
+		if rec.final() {
+			if err := s.save(ctx, &rec); err != nil {
+				s.logger.Error("failed to save export job", "job", rec.ID, "error", err)
+			}
+		}
+	}
+	return &rec, nil
+}
+
+func (s *ExportJobService) save(ctx context.Context, rec *exportJobRecord) error {
+	raw, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encoding export job: %w", err)
+	}
+	if err := s.rdb.Set(ctx, exportJobKey(rec.ID), raw, s.cacheTTL).Err(); err != nil {
+		return fmt.Errorf("saving export job: %w", err)
+	}
+	return nil
+}
+
+func (s *ExportJobService) result(ctx context.Context, rec *exportJobRecord) ([]byte, string, error) {
+	data, err := s.rdb.HGet(ctx, exportResultKey(rec.CacheKey), "data").Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, "", model.NewDomainError(model.ErrNotFound, "export has expired, please start a new one")
+		}
+		return nil, "", fmt.Errorf("loading export result: %w", err)
+	}
+	return data, rec.Filename, nil
+}
+
+// exportCacheKey identifies a rendered export. It changes whenever the event
+// itself (updated_at), its plan (version) or the requested options change.
+func exportCacheKey(event repository.Event, version int64, kind string, opts any) string {
+	raw, _ := json.Marshal(struct {
+		EventID   uuid.UUID `json:"event_id"`
+		UpdatedAt time.Time `json:"updated_at"`
+		Version   int64     `json:"version"`
+		Kind      string    `json:"kind"`
+		Options   any       `json:"options"`
+	}{event.ID, event.UpdatedAt, version, kind, opts})
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+func exportJobKey(id string) string {
+	return "export:job:" + id
+}
+
+func exportResultKey(cacheKey string) string {
+	return "export:result:" + cacheKey
+}
+
+func exportInflightKey(cacheKey string) string {
+	return "export:inflight:" + cacheKey
+}
+
+// releaseInflightScript deletes a key only if it still holds the given value.
+var releaseInflightScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// releaseInflight releases the inflight lock of a job that leads a render.
+// The lock may have expired and been taken by another job since, which must
+// keep it.
+func (s *ExportJobService) releaseInflight(ctx context.Context, rec *exportJobRecord) {
+	if err := releaseInflightScript.Run(ctx, s.rdb, []string{exportInflightKey(rec.CacheKey)}, rec.ID).Err(); err != nil {
+		s.logger.Error("failed to release export lock", "job", rec.ID, "error", err)
+	}
+}
+
+// bumpPlanVersion invalidates cached exports of an event. A failure only
+// leaves a stale cache entry until it expires, so it is logged, not returned.
+func bumpPlanVersion(ctx context.Context, queries *repository.Queries, logger *slog.Logger, eventID uuid.UUID) {
+	if err := queries.BumpEventPlanVersion(ctx, eventID); err != nil {
+		logger.Error("failed to bump plan version", "event_id", eventID, "error", err)
+	}
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/pdf"
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+)
+
+func TestExportCacheKey(t *testing.T) {
+	event := repository.Event{ID: uuid.New(), UpdatedAt: time.Date(2025, 7, 4, 12, 0, 0, 0, time.UTC)}
+	opts := pdf.PDFOptions{Layout: "grid", PaperSize: "A4", Landscape: true}
+
+	key := exportCacheKey(event, 3, "pdf", opts)
+	if again := exportCacheKey(event, 3, "pdf", opts); again != key {
+		t.Errorf("key not deterministic: %s != %s", again, key)
+	}
+
+	list := opts
+	list.Layout = "list"
+	edited := event
+	edited.UpdatedAt = edited.UpdatedAt.Add(time.Second)
+	for name, other := range map[string]string{
+		"plan version": exportCacheKey(event, 4, "pdf", opts),
+		"options":      exportCacheKey(event, 3, "pdf", list),
+		"event edit":   exportCacheKey(edited, 3, "pdf", opts),
+	} {
+		if other == key {
+			t.Errorf("changing the %s kept the cache key", name)
+		}
+	}
+}
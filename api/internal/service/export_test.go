@@ -0,0 +1,216 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+)
+
+func TestFormatCSVTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("tzdata not available: %v", err)
+	}
+	ts := time.Date(2025, 7, 4, 12, 30, 0, 0, time.UTC).In(berlin)
+
+	tests := []struct {
+		name       string
+		dateFormat string
+		timeFormat string
+		want       string
+	}{
+		{name: "rfc3339 keeps offset", dateFormat: "rfc3339", timeFormat: "12h", want: "2025-07-04T14:30:00+02:00"},
+		{name: "iso 24h", dateFormat: "iso", timeFormat: "24h", want: "2025-07-04 14:30"},
+		{name: "iso 12h", dateFormat: "iso", timeFormat: "12h", want: "2025-07-04 2:30 PM"},
+		{name: "eu 24h", dateFormat: "eu", timeFormat: "24h", want: "04.07.2025 14:30"},
+		{name: "us 12h", dateFormat: "us", timeFormat: "12h", want: "07/04/2025 2:30 PM"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatCSVTime(ts, tt.dateFormat, tt.timeFormat); got != tt.want {
+				t.Errorf("formatCSVTime() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCSVOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      CSVOptions
+		wantField string
+	}{
+		{name: "defaults", opts: CSVOptions{}},
+		{name: "unknown column", opts: CSVOptions{Columns: []string{"start", "password"}}, wantField: "columns"},
+		{name: "quote delimiter", opts: CSVOptions{Delimiter: '"'}, wantField: "delimiter"},
+		{name: "bad date format", opts: CSVOptions{DateFormat: "unix"}, wantField: "date_format"},
+		{name: "bad time format", opts: CSVOptions{TimeFormat: "36h"}, wantField: "time_format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			err := validateCSVOptions(&opts)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if opts.Delimiter != ',' || opts.DateFormat != "rfc3339" || len(opts.Columns) == 0 {
+					t.Errorf("defaults not applied: %+v", opts)
+				}
+				return
+			}
+			if err == nil || !hasField(err, tt.wantField) {
+				t.Errorf("error = %v, want field %q", err, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestAggregateShiftHours(t *testing.T) {
+	bar, door := uuid.New(), uuid.New()
+	alice, bob := uuid.New(), uuid.New()
+	base := time.Date(2025, 7, 4, 10, 0, 0, 0, time.UTC)
+	shift := func(user uuid.UUID, username string, team uuid.UUID, hours float64) repository.ListShiftsByEventRow {
+		return repository.ListShiftsByEventRow{
+			UserID:       user,
+			Username:     username,
+			UserFullName: username,
+			TeamID:       team,
+			StartTime:    base,
+			EndTime:      base.Add(time.Duration(hours * float64(time.Hour))),
+		}
+	}
+
+	shifts := []repository.ListShiftsByEventRow{
+		shift(bob, "bob", bar, 2),
+		shift(alice, "alice", door, 1.5),
+		shift(alice, "alice", bar, 3),
+	}
+	teams := []repository.ListEventTeamsRow{
+		{ID: bar, Name: "Bar"},
+		{ID: uuid.New(), Name: "Unused"},
+		{ID: door, Name: "Door"},
+	}
+
+	got := aggregateShiftHours(shifts, teams)
+	want := [][]string{
+		{"Username", "Full Name", "Display Name", "Bar (h)", "Door (h)", "Total (h)"},
+		{"alice", "alice", "", "3.00", "1.50", "4.50"},
+		{"bob", "bob", "", "2.00", "0.00", "2.00"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("aggregateShiftHours() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestAggregateShiftHoursUnlistedTeam(t *testing.T) {
+	bar, gone := uuid.New(), uuid.New()
+	alice := uuid.New()
+	base := time.Date(2025, 7, 4, 10, 0, 0, 0, time.UTC)
+	shifts := []repository.ListShiftsByEventRow{
+		{UserID: alice, Username: "alice", TeamID: gone, TeamName: "Stage", StartTime: base, EndTime: base.Add(time.Hour)},
+		{UserID: alice, Username: "alice", TeamID: bar, TeamName: "Bar", StartTime: base, EndTime: base.Add(2 * time.Hour)},
+	}
+	teams := []repository.ListEventTeamsRow{{ID: bar, Name: "Bar"}}
+
+	got := aggregateShiftHours(shifts, teams)
+	want := [][]string{
+		{"Username", "Full Name", "Display Name", "Bar (h)", "Stage (h)", "Total (h)"},
+		{"alice", "", "", "2.00", "1.00", "3.00"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("aggregateShiftHours() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func hasField(err error, field string) bool {
+	var domainErr *model.DomainError
+	return errors.As(err, &domainErr) && domainErr.Field == field
+}
+
+func TestWriteICalLineFolding(t *testing.T) {
+	var b strings.Builder
+	value := escapeICalText(strings.Repeat("Zäpfle, ", 20))
+	writeICalLine(&b, "DESCRIPTION", value)
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+	if len(lines) < 2 {
+		t.Fatalf("expected folded output, got %d line(s)", len(lines))
+	}
+	var unfolded strings.Builder
+	for i, line := range lines {
+		if len(line) > 75 {
+			t.Errorf("line %d is %d octets, want <= 75", i, len(line))
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line %d splits a UTF-8 sequence", i)
+		}
+		if i > 0 {
+			if !strings.HasPrefix(line, " ") {
+				t.Errorf("continuation line %d does not start with a space", i)
+			}
+			line = line[1:]
+		}
+		unfolded.WriteString(line)
+	}
+	if got, want := unfolded.String(), "DESCRIPTION:"+value; got != want {
+		t.Errorf("unfolded = %q, want %q", got, want)
+	}
+}
+
+func TestEscapeICalText(t *testing.T) {
+	got := escapeICalText("Bar; Door, Stage\\nBackstage\nCrew")
+	want := `Bar\; Door\, Stage\\nBackstage\nCrew`
+	if got != want {
+		t.Errorf("escapeICalText() = %q, want %q", got, want)
+	}
+}
+
+func TestICalCoworkers(t *testing.T) {
+	bar, door := uuid.New(), uuid.New()
+	me, alice, bob := uuid.New(), uuid.New(), uuid.New()
+	base := time.Date(2025, 7, 4, 10, 0, 0, 0, time.UTC)
+	display := "Ally"
+
+	mine := repository.ListShiftsByEventRow{ID: uuid.New(), UserID: me, TeamID: bar, StartTime: base, EndTime: base.Add(2 * time.Hour)}
+	all := []repository.ListShiftsByEventRow{
+		mine,
+		{ID: uuid.New(), UserID: alice, Username: "alice", UserDisplayName: &display, TeamID: bar, StartTime: base.Add(time.Hour), EndTime: base.Add(3 * time.Hour)},
+		{ID: uuid.New(), UserID: bob, Username: "bob", TeamID: door, StartTime: base, EndTime: base.Add(2 * time.Hour)},
+		{ID: uuid.New(), UserID: bob, Username: "bob", TeamID: bar, StartTime: base.Add(2 * time.Hour), EndTime: base.Add(4 * time.Hour)},
+	}
+
+	got := icalCoworkers(all, mine.ID, mine.UserID, mine.TeamID, mine.StartTime, mine.EndTime)
+	if !reflect.DeepEqual(got, []string{"Ally"}) {
+		t.Errorf("icalCoworkers() = %v, want [Ally]", got)
+	}
+}
+
+func TestBuildICalSequence(t *testing.T) {
+	base := time.Date(2025, 7, 4, 10, 0, 0, 0, time.UTC)
+	event := repository.Event{Slug: "camp", Name: "Camp"}
+	shifts := []repository.ListShiftsByEventRow{{
+		ID: uuid.New(), UserID: uuid.New(), TeamID: uuid.New(), Username: "alice", TeamName: "Bar",
+		StartTime: base, EndTime: base.Add(2 * time.Hour),
+		CreatedAt: base.Add(-48 * time.Hour), UpdatedAt: base.Add(-time.Hour), Sequence: 3,
+	}}
+	deleted := []repository.ListShiftDeletionsByEventRow{{
+		ShiftID: uuid.New(), Username: "bob", TeamName: "Door",
+		StartTime: base, EndTime: base.Add(time.Hour),
+		CreatedAt: base.Add(-48 * time.Hour), DeletedAt: base.Add(-time.Hour), Sequence: 1,
+	}}
+
+	got := buildICalFromShifts(event, shifts, deleted, nil)
+	if !strings.Contains(got, "SEQUENCE:3\r\nDTSTART") || !strings.Contains(got, "SEQUENCE:1\r\nDTSTART") {
+		t.Errorf("calendar doesn't carry the stored revisions:\n%s", got)
+	}
+}
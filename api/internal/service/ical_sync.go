@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// ICalSyncService periodically re-imports subscribed availability calendars.
+type ICalSyncService struct {
+	availabilityService *AvailabilityService
+	interval            time.Duration
+	logger              *slog.Logger
+	stopCh              chan struct{}
+}
+
+func NewICalSyncService(availabilityService *AvailabilityService, interval time.Duration, logger *slog.Logger) *ICalSyncService {
+	if interval < 5*time.Minute {
+		interval = 5 * time.Minute
+	}
+	return &ICalSyncService{
+		availabilityService: availabilityService,
+		interval:            interval,
+		logger:              logger,
+		stopCh:              make(chan struct{}),
+	}
+}
+
+func (s *ICalSyncService) Start(ctx context.Context) {
+	// Run once on startup after a short delay
+	timer := time.NewTimer(2 * time.Minute)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			return
+		case <-timer.C:
+			synced, err := s.availabilityService.SyncDueFeeds(ctx, s.interval)
+			if err != nil {
+				s.logger.Error("calendar sync failed", "error", err)
+			} else if synced > 0 {
+				s.logger.Info("calendar sync completed", "feeds", synced)
+			}
+			timer.Reset(s.interval)
+		}
+	}
+}
+
+func (s *ICalSyncService) Stop() {
+	close(s.stopCh)
+}
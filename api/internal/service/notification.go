@@ -8,31 +8,89 @@ import (
 
 	"github.com/echtkpvl/rncasp/internal/model"
 	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/echtkpvl/rncasp/internal/sse"
 	"github.com/google/uuid"
 )
 
-// Trigger types for notifications
+// Trigger types for notifications. The shift.created/updated/deleted triggers
+// reach teammates with overlapping shifts, the personal triggers the user
+// whose shift changed, and the admin triggers the event's admins.
 const (
 	TriggerShiftCreated  = "shift.created"
 	TriggerShiftUpdated  = "shift.updated"
 	TriggerShiftDeleted  = "shift.deleted"
 	TriggerEventLocked   = "event.locked"
 	TriggerEventUnlocked = "event.unlocked"
+
+	TriggerShiftAssignedToYou  = "shift.assigned_to_you"
+	TriggerShiftRemovedFromYou = "shift.removed_from_you"
+	TriggerShiftTimeChanged    = "shift.time_changed"
+
+	TriggerAdminShiftCreated = "admin.shift.created"
+	TriggerAdminShiftUpdated = "admin.shift.updated"
+	TriggerAdminShiftDeleted = "admin.shift.deleted"
+
+	TriggerAnnouncement = "event.announcement"
+
+	TriggerWebhookDisabled = "webhook.disabled"
 )
 
+// notificationTriggers lists the triggers users can set preferences for and
+// which channels are on by default.
+var notificationTriggers = []struct {
+	triggerType  string
+	inAppDefault bool
+	emailDefault bool
+	pushDefault  bool
+}{
+	{TriggerShiftAssignedToYou, true, false, true},
+	{TriggerShiftRemovedFromYou, true, false, true},
+	{TriggerShiftTimeChanged, true, false, true},
+	{TriggerShiftCreated, false, false, false},
+	{TriggerShiftUpdated, false, false, false},
+	{TriggerShiftDeleted, false, false, false},
+	{TriggerAdminShiftCreated, true, false, false},
+	{TriggerAdminShiftUpdated, true, false, false},
+	{TriggerAdminShiftDeleted, true, false, false},
+	{TriggerEventLocked, true, false, false},
+	{TriggerEventUnlocked, true, false, false},
+	{TriggerAnnouncement, true, true, true},
+	{TriggerWebhookDisabled, true, true, false},
+}
+
 // Notification channels
 const (
 	ChannelInApp = "in_app"
 	ChannelEmail = "email"
+	ChannelPush  = "push"
 )
 
+// notificationChannels lists the channels users can set preferences for.
+var notificationChannels = []string{ChannelInApp, ChannelEmail, ChannelPush}
+
 type NotificationService struct {
-	queries *repository.Queries
-	logger  *slog.Logger
+	queries     *repository.Queries
+	logger      *slog.Logger
+	sseBroker   *sse.Broker
+	emailOutbox *EmailOutboxService
+	pushService *PushService
+	baseURL     string
 }
 
-func NewNotificationService(queries *repository.Queries, logger *slog.Logger) *NotificationService {
-	return &NotificationService{queries: queries, logger: logger}
+func NewNotificationService(queries *repository.Queries, logger *slog.Logger, sseBroker *sse.Broker) *NotificationService {
+	return &NotificationService{queries: queries, logger: logger, sseBroker: sseBroker}
+}
+
+// SetEmailOutbox enables the email channel. baseURL is used for links in the
+// emails.
+func (s *NotificationService) SetEmailOutbox(outbox *EmailOutboxService, baseURL string) {
+	s.emailOutbox = outbox
+	s.baseURL = baseURL
+}
+
+// SetPushService enables the Web Push channel.
+func (s *NotificationService) SetPushService(ps *PushService) {
+	s.pushService = ps
 }
 
 type NotificationResponse struct {
@@ -110,6 +168,7 @@ func (s *NotificationService) MarkRead(ctx context.Context, userID uuid.UUID, no
 	if err := s.queries.MarkNotificationRead(ctx, notificationID, userID); err != nil {
 		return fmt.Errorf("marking notification read: %w", err)
 	}
+	s.publishUnread(ctx, userID, sse.TypeNotificationsRead, nil)
 	return nil
 }
 
@@ -117,41 +176,71 @@ func (s *NotificationService) MarkAllRead(ctx context.Context, userID uuid.UUID)
 	if err := s.queries.MarkAllNotificationsRead(ctx, userID); err != nil {
 		return fmt.Errorf("marking all notifications read: %w", err)
 	}
+	s.publishUnread(ctx, userID, sse.TypeNotificationsRead, nil)
 	return nil
 }
 
+// createNotification stores an in-app notification and pushes it to the
+// user's connected sessions.
+func (s *NotificationService) createNotification(ctx context.Context, arg repository.CreateNotificationParams) error {
+	n, err := s.queries.CreateNotification(ctx, arg)
+	if err != nil {
+		return fmt.Errorf("creating notification: %w", err)
+	}
+	resp := notificationToResponse(n)
+	s.publishUnread(ctx, arg.UserID, sse.TypeNotificationCreated, &resp)
+	return nil
+}
+
+// publishUnread sends the user's unread count, and the new notification if
+// any, to their SSE connections on all instances.
+func (s *NotificationService) publishUnread(ctx context.Context, userID uuid.UUID, eventType string, notification *NotificationResponse) {
+	if s.sseBroker == nil {
+		return
+	}
+	count, err := s.queries.CountUnreadNotifications(ctx, userID)
+	if err != nil {
+		s.logger.Error("failed to count unread notifications", "error", err, "user_id", userID)
+		return
+	}
+	s.sseBroker.Publish(ctx, sse.Event{
+		Type:   eventType,
+		UserID: userID.String(),
+		Payload: struct {
+			Notification *NotificationResponse `json:"notification,omitempty"`
+			UnreadCount  int64                 `json:"unread_count"`
+		}{notification, count},
+	})
+}
+
+// GetPreferences returns the effective setting of every trigger and channel,
+// including defaults the user never changed.
 func (s *NotificationService) GetPreferences(ctx context.Context, userID uuid.UUID) ([]NotificationPreferenceResponse, error) {
 	prefs, err := s.queries.GetNotificationPreferences(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("getting notification preferences: %w", err)
 	}
 
-	result := make([]NotificationPreferenceResponse, len(prefs))
-	for i, p := range prefs {
-		result[i] = NotificationPreferenceResponse{
-			TriggerType: p.TriggerType,
-			Channel:     p.Channel,
-			IsEnabled:   p.IsEnabled,
+	result := make([]NotificationPreferenceResponse, 0, len(notificationChannels)*len(notificationTriggers))
+	for _, t := range notificationTriggers {
+		for _, channel := range notificationChannels {
+			result = append(result, NotificationPreferenceResponse{
+				TriggerType: t.triggerType,
+				Channel:     channel,
+				IsEnabled:   channelEnabled(prefs, t.triggerType, channel),
+			})
 		}
 	}
 	return result, nil
 }
 
 func (s *NotificationService) UpdatePreference(ctx context.Context, userID uuid.UUID, input UpdatePreferenceInput) error {
-	validTriggers := map[string]bool{
-		TriggerShiftCreated:  true,
-		TriggerShiftUpdated:  true,
-		TriggerShiftDeleted:  true,
-		TriggerEventLocked:   true,
-		TriggerEventUnlocked: true,
-	}
-	if !validTriggers[input.TriggerType] {
+	if !isNotificationTrigger(input.TriggerType) {
 		return model.NewFieldError(model.ErrInvalidInput, "trigger_type", "invalid trigger type")
 	}
 
-	validChannels := map[string]bool{ChannelInApp: true, ChannelEmail: true}
-	if !validChannels[input.Channel] {
-		return model.NewFieldError(model.ErrInvalidInput, "channel", "invalid channel, must be in_app or email")
+	if !isNotificationChannel(input.Channel) {
+		return model.NewFieldError(model.ErrInvalidInput, "channel", "invalid channel, must be in_app, email, or push")
 	}
 
 	if err := s.queries.UpsertNotificationPreference(ctx, repository.UpsertNotificationPreferenceParams{
@@ -181,54 +270,162 @@ func formatTimeRange(start, end time.Time) string {
 		end.Format("15:04"))
 }
 
-// Notify creates an in-app notification for a user. It checks the user's
-// preference for the given trigger and in_app channel before creating it.
-func (s *NotificationService) Notify(ctx context.Context, userID uuid.UUID, eventID *uuid.UUID, triggerType, title string, body *string) error {
-	// Check if user has disabled in-app notifications for this trigger
+func isNotificationTrigger(triggerType string) bool {
+	for _, t := range notificationTriggers {
+		if t.triggerType == triggerType {
+			return true
+		}
+	}
+	return false
+}
+
+func isNotificationChannel(channel string) bool {
+	for _, c := range notificationChannels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// channelEnabled reports whether the user wants notifications of triggerType
+// on channel. Without a stored preference, the trigger's default applies.
+func channelEnabled(prefs []repository.NotificationPreference, triggerType, channel string) bool {
+	for _, p := range prefs {
+		if p.TriggerType == triggerType && p.Channel == channel {
+			return p.IsEnabled
+		}
+	}
+	for _, t := range notificationTriggers {
+		if t.triggerType == triggerType {
+			switch channel {
+			case ChannelInApp:
+				return t.inAppDefault
+			case ChannelEmail:
+				return t.emailDefault
+			case ChannelPush:
+				return t.pushDefault
+			}
+		}
+	}
+	return false
+}
+
+// Notify notifies a user in their language on every channel they have
+// enabled for the message's trigger: an in-app notification, a push message
+// to their devices, and a queued email. In-app and email channels set to a
+// digest mode queue the message for the next digest instead; push messages
+// are always sent right away. Event-scoped settings (muted or
+// watched events, per-event overrides) take precedence over global ones.
+func (s *NotificationService) Notify(ctx context.Context, userID uuid.UUID, eventID *uuid.UUID, msg NotificationMessage) error {
+	ec := eventNotificationContext{state: EventNotificationsDefault}
+	if eventID != nil {
+		var err error
+		if ec, err = s.eventNotificationContext(ctx, userID, *eventID); err != nil {
+			s.logger.Error("failed to get event notification settings", "error", err, "user_id", userID)
+		}
+	}
+
 	prefs, err := s.queries.GetNotificationPreferences(ctx, userID)
 	if err != nil {
 		s.logger.Error("failed to get notification preferences", "error", err, "user_id", userID)
-		// Continue anyway - default to sending
-	} else {
-		for _, p := range prefs {
-			if p.TriggerType == triggerType && p.Channel == ChannelInApp && !p.IsEnabled {
-				return nil // User has disabled this notification
+		// Continue anyway - without preferences every channel falls back to
+		// the trigger's default
+		prefs = nil
+	}
+	inApp := eventChannelEnabled(prefs, ec, msg.TriggerType, ChannelInApp)
+	email := eventChannelEnabled(prefs, ec, msg.TriggerType, ChannelEmail)
+	push := s.pushService != nil && eventChannelEnabled(prefs, ec, msg.TriggerType, ChannelPush)
+	if !inApp && !email && !push {
+		return nil
+	}
+
+	user, err := s.queries.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("getting user: %w", err)
+	}
+	modes, err := s.queries.GetNotificationDeliveryModes(ctx, userID)
+	if err != nil {
+		s.logger.Error("failed to get delivery modes", "error", err, "user_id", userID)
+		modes = nil
+	}
+
+	title, body := msg.Render(user.Language)
+
+	if inApp {
+		if mode := deliveryMode(modes, ChannelInApp); mode != DeliveryImmediate {
+			if err := s.queueForDigest(ctx, userID, eventID, ChannelInApp, mode, msg); err != nil {
+				return err
 			}
+		} else if err := s.createNotification(ctx, repository.CreateNotificationParams{
+			UserID:      userID,
+			EventID:     eventID,
+			Title:       title,
+			Body:        &body,
+			TriggerType: msg.TriggerType,
+		}); err != nil {
+			return err
 		}
 	}
 
-	_, err = s.queries.CreateNotification(ctx, repository.CreateNotificationParams{
-		UserID:      userID,
-		EventID:     eventID,
-		Title:       title,
-		Body:        body,
-		TriggerType: triggerType,
-	})
-	if err != nil {
-		return fmt.Errorf("creating notification: %w", err)
+	if push && user.IsActive {
+		payload, topic := notificationPush(title, body, s.baseURL, msg)
+		s.pushService.Notify(ctx, userID, payload, topic)
+	}
+
+	if email && s.emailOutbox != nil && user.IsActive && user.Email != nil && *user.Email != "" {
+		if mode := deliveryMode(modes, ChannelEmail); mode != DeliveryImmediate {
+			return s.queueForDigest(ctx, userID, eventID, ChannelEmail, mode, msg)
+		}
+		htmlBody, textBody := notificationEmail(user.Language, title, []string{body}, s.baseURL, msg.EventSlug)
+		if err := s.emailOutbox.Enqueue(ctx, &userID, *user.Email, title, htmlBody, textBody); err != nil {
+			return fmt.Errorf("queueing notification email: %w", err)
+		}
 	}
 	return nil
 }
 
-// NotifyEventUsers creates notifications for all users who have shifts in the given event,
-// except the actor who triggered the change.
-func (s *NotificationService) NotifyEventUsers(ctx context.Context, eventID uuid.UUID, actorID uuid.UUID, triggerType, title string, body *string) {
+// NotifySuperAdmins notifies all active super admins, for problems only they
+// can fix.
+func (s *NotificationService) NotifySuperAdmins(ctx context.Context, eventID *uuid.UUID, msg NotificationMessage) {
+	adminIDs, err := s.queries.ListSuperAdminIDs(ctx)
+	if err != nil {
+		s.logger.Error("failed to list super admins for notification", "error", err)
+		return
+	}
+	for _, userID := range adminIDs {
+		if err := s.Notify(ctx, userID, eventID, msg); err != nil {
+			s.logger.Error("failed to create notification", "error", err, "user_id", userID)
+		}
+	}
+}
+
+// NotifyEventUsers notifies all users who have shifts in the given event and
+// the event's watchers, except the actor who triggered the change. It is used
+// for event-level triggers; shift changes go through NotifyShiftChange.
+func (s *NotificationService) NotifyEventUsers(ctx context.Context, eventID uuid.UUID, actorID uuid.UUID, msg NotificationMessage) {
 	shifts, err := s.queries.ListShiftsByEvent(ctx, eventID)
 	if err != nil {
 		s.logger.Error("failed to list shifts for notification", "error", err, "event_id", eventID)
 		return
 	}
 
+	userIDs := make([]uuid.UUID, 0, len(shifts))
+	for _, shift := range shifts {
+		userIDs = append(userIDs, shift.UserID)
+	}
+	userIDs = append(userIDs, s.eventWatchers(ctx, eventID)...)
+
 	// Deduplicate user IDs
 	notified := make(map[uuid.UUID]bool)
-	for _, shift := range shifts {
-		if shift.UserID == actorID || notified[shift.UserID] {
+	for _, userID := range userIDs {
+		if userID == actorID || notified[userID] {
 			continue
 		}
-		notified[shift.UserID] = true
+		notified[userID] = true
 
-		if err := s.Notify(ctx, shift.UserID, &eventID, triggerType, title, body); err != nil {
-			s.logger.Error("failed to create notification", "error", err, "user_id", shift.UserID)
+		if err := s.Notify(ctx, userID, &eventID, msg); err != nil {
+			s.logger.Error("failed to create notification", "error", err, "user_id", userID)
 		}
 	}
 }
@@ -0,0 +1,345 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Delivery modes for a notification channel
+const (
+	DeliveryImmediate = "immediate"
+	DeliveryHourly    = "hourly"
+	DeliveryDaily     = "daily"
+)
+
+// TriggerDigest is the trigger type of in-app notifications that summarize
+// several changes.
+const TriggerDigest = "digest"
+
+// digestTitles holds the title of a digest with more than one change.
+// Arguments: event name, number of changes.
+var digestTitles = map[string]string{
+	"en": "%s: %d changes",
+	"de": "%s: %d Änderungen",
+}
+
+type DeliveryModeResponse struct {
+	Channel string `json:"channel"`
+	Mode    string `json:"mode"`
+}
+
+// GetDeliveryModes returns the delivery mode of every channel, including
+// channels the user never changed (immediate).
+func (s *NotificationService) GetDeliveryModes(ctx context.Context, userID uuid.UUID) ([]DeliveryModeResponse, error) {
+	modes, err := s.queries.GetNotificationDeliveryModes(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("getting delivery modes: %w", err)
+	}
+
+	return []DeliveryModeResponse{
+		{Channel: ChannelInApp, Mode: deliveryMode(modes, ChannelInApp)},
+		{Channel: ChannelEmail, Mode: deliveryMode(modes, ChannelEmail)},
+	}, nil
+}
+
+func (s *NotificationService) UpdateDeliveryMode(ctx context.Context, userID uuid.UUID, channel, mode string) error {
+	if channel != ChannelInApp && channel != ChannelEmail {
+		return model.NewFieldError(model.ErrInvalidInput, "channel", "invalid channel, must be in_app or email")
+	}
+	if mode != DeliveryImmediate && mode != DeliveryHourly && mode != DeliveryDaily {
+		return model.NewFieldError(model.ErrInvalidInput, "mode", "must be immediate, hourly, or daily")
+	}
+
+	if err := s.queries.UpsertNotificationDeliveryMode(ctx, userID, channel, mode); err != nil {
+		return fmt.Errorf("upserting delivery mode: %w", err)
+	}
+	return nil
+}
+
+func deliveryMode(modes []repository.NotificationDeliveryMode, channel string) string {
+	for _, m := range modes {
+		if m.Channel == channel {
+			return m.Mode
+		}
+	}
+	return DeliveryImmediate
+}
+
+// queueForDigest stores a notification until the next digest of channel.
+func (s *NotificationService) queueForDigest(ctx context.Context, userID uuid.UUID, eventID *uuid.UUID, channel, mode string, msg NotificationMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("encoding notification: %w", err)
+	}
+	if err := s.queries.CreatePendingNotification(ctx, repository.CreatePendingNotificationParams{
+		UserID:  userID,
+		EventID: eventID,
+		Channel: channel,
+		Mode:    mode,
+		Message: data,
+	}); err != nil {
+		return fmt.Errorf("queueing notification for digest: %w", err)
+	}
+	return nil
+}
+
+type digestKey struct {
+	userID  uuid.UUID
+	eventID uuid.UUID // uuid.Nil for notifications without an event
+	channel string
+	mode    string
+}
+
+// FlushDigests sends every digest that is due and returns how many were sent.
+// Digests are grouped by user, event and channel. Hourly digests go out at the
+// start of the hour after their oldest item, daily digests at digestHour in
+// the event's time zone. Items are claimed by deleting them, so with several
+// instances each item is delivered once.
+func (s *NotificationService) FlushDigests(ctx context.Context, now time.Time, digestHour int) (int, error) {
+	pending, err := s.queries.ListPendingNotifications(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("listing pending notifications: %w", err)
+	}
+
+	groups := make(map[digestKey][]repository.PendingNotification)
+	var order []digestKey
+	for _, p := range pending {
+		k := digestKey{userID: p.UserID, channel: p.Channel, mode: p.Mode}
+		if p.EventID != nil {
+			k.eventID = *p.EventID
+		}
+		if _, ok := groups[k]; !ok {
+			order = append(order, k)
+		}
+		groups[k] = append(groups[k], p)
+	}
+
+	sent := 0
+	for _, k := range order {
+		items := groups[k]
+		msgs := make([]NotificationMessage, 0, len(items))
+		for _, p := range items {
+			var msg NotificationMessage
+			if err := json.Unmarshal(p.Message, &msg); err != nil {
+				s.logger.Error("invalid pending notification", "id", p.ID, "error", err)
+				continue
+			}
+			msgs = append(msgs, msg)
+		}
+
+		loc := time.UTC
+		if len(msgs) > 0 && msgs[0].TimeZone != "" {
+			if l, err := time.LoadLocation(msgs[0].TimeZone); err == nil {
+				loc = l
+			}
+		}
+		if digestDue(k.mode, items[0].CreatedAt, loc, digestHour).After(now) {
+			continue
+		}
+
+		ids := make([]uuid.UUID, len(items))
+		for i, p := range items {
+			ids[i] = p.ID
+		}
+		claimed, err := s.queries.DeletePendingNotifications(ctx, ids)
+		if err != nil {
+			s.logger.Error("failed to claim pending notifications", "user_id", k.userID, "error", err)
+			continue
+		}
+		if len(claimed) != len(ids) {
+			// Another instance is flushing the same digest
+			continue
+		}
+
+		msgs = collapseDigest(msgs)
+		if len(msgs) == 0 {
+			continue
+		}
+
+		var eventID *uuid.UUID
+		if k.eventID != uuid.Nil {
+			id := k.eventID
+			eventID = &id
+		}
+		if err := s.deliverDigest(ctx, k.userID, eventID, k.channel, msgs); err != nil {
+			s.logger.Error("failed to deliver digest", "user_id", k.userID, "channel", k.channel, "error", err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+func (s *NotificationService) deliverDigest(ctx context.Context, userID uuid.UUID, eventID *uuid.UUID, channel string, msgs []NotificationMessage) error {
+	user, err := s.queries.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("getting user: %w", err)
+	}
+
+	title, lines := renderDigest(user.Language, msgs)
+	triggerType := TriggerDigest
+	if len(msgs) == 1 {
+		triggerType = msgs[0].TriggerType
+	}
+
+	switch channel {
+	case ChannelInApp:
+		body := strings.Join(lines, "\n")
+		if err := s.createNotification(ctx, repository.CreateNotificationParams{
+			UserID:      userID,
+			EventID:     eventID,
+			Title:       title,
+			Body:        &body,
+			TriggerType: triggerType,
+		}); err != nil {
+			return err
+		}
+	case ChannelEmail:
+		if s.emailOutbox == nil || !user.IsActive || user.Email == nil || *user.Email == "" {
+			return nil
+		}
+		htmlBody, textBody := notificationEmail(user.Language, title, lines, s.baseURL, msgs[0].EventSlug)
+		if err := s.emailOutbox.Enqueue(ctx, &userID, *user.Email, title, htmlBody, textBody); err != nil {
+			return fmt.Errorf("queueing digest email: %w", err)
+		}
+	}
+	return nil
+}
+
+// renderDigest returns the title and one line per change. A digest of a
+// single change looks like the immediate notification.
+func renderDigest(lang string, msgs []NotificationMessage) (string, []string) {
+	lines := make([]string, len(msgs))
+	var title string
+	for i, m := range msgs {
+		title, lines[i] = m.Render(lang)
+	}
+	if len(msgs) > 1 {
+		title = fmt.Sprintf(digestTitles[notificationLanguage(lang)], msgs[0].EventName, len(msgs))
+	}
+	return title, lines
+}
+
+// collapseDigest reduces repeated changes to the same shift to their net
+// effect, in the order the shifts were first changed: a shift created (or
+// assigned) and then updated is reported as created with its final data, an
+// update followed by a deletion as deleted, and a shift created and deleted
+// again not at all.
+func collapseDigest(msgs []NotificationMessage) []NotificationMessage {
+	result := make([]NotificationMessage, 0, len(msgs))
+	index := make(map[uuid.UUID]int)
+	dropped := make(map[int]bool)
+
+	for _, m := range msgs {
+		if m.ShiftID == uuid.Nil {
+			result = append(result, m)
+			continue
+		}
+		i, seen := index[m.ShiftID]
+		if !seen {
+			index[m.ShiftID] = len(result)
+			result = append(result, m)
+			continue
+		}
+
+		if shiftChangeKind(result[i].TriggerType) == TriggerShiftCreated {
+			if shiftChangeKind(m.TriggerType) == TriggerShiftDeleted {
+				dropped[i] = true
+				continue
+			}
+			m.TriggerType = result[i].TriggerType
+		}
+		result[i] = m
+	}
+
+	if len(dropped) == 0 {
+		return result
+	}
+	kept := result[:0]
+	for i, m := range result {
+		if !dropped[i] {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+// shiftChangeKind maps a shift trigger to shift.created, shift.updated or
+// shift.deleted.
+func shiftChangeKind(triggerType string) string {
+	switch triggerType {
+	case TriggerShiftCreated, TriggerAdminShiftCreated, TriggerShiftAssignedToYou:
+		return TriggerShiftCreated
+	case TriggerShiftDeleted, TriggerAdminShiftDeleted, TriggerShiftRemovedFromYou:
+		return TriggerShiftDeleted
+	}
+	return TriggerShiftUpdated
+}
+
+// digestDue returns when a digest whose oldest item was queued at oldest is
+// sent: at the start of the following hour for hourly digests, and at the
+// next digestHour o'clock in loc for daily digests.
+func digestDue(mode string, oldest time.Time, loc *time.Location, digestHour int) time.Time {
+	if mode == DeliveryHourly {
+		return oldest.Truncate(time.Hour).Add(time.Hour)
+	}
+	local := oldest.In(loc)
+	due := time.Date(local.Year(), local.Month(), local.Day(), digestHour, 0, 0, 0, loc)
+	if !due.After(oldest) {
+		due = due.AddDate(0, 0, 1)
+	}
+	return due
+}
+
+// NotificationDigestService periodically sends due notification digests.
+type NotificationDigestService struct {
+	notificationService *NotificationService
+	digestHour          int
+	logger              *slog.Logger
+	stopCh              chan struct{}
+}
+
+func NewNotificationDigestService(notificationService *NotificationService, digestHour int, logger *slog.Logger) *NotificationDigestService {
+	if digestHour < 0 || digestHour > 23 {
+		digestHour = 8
+	}
+	return &NotificationDigestService{
+		notificationService: notificationService,
+		digestHour:          digestHour,
+		logger:              logger,
+		stopCh:              make(chan struct{}),
+	}
+}
+
+func (s *NotificationDigestService) Start(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			sent, err := s.notificationService.FlushDigests(ctx, time.Now(), s.digestHour)
+			if err != nil {
+				s.logger.Error("notification digests failed", "error", err)
+			} else if sent > 0 {
+				s.logger.Info("notification digests sent", "digests", sent)
+			}
+		}
+	}
+}
+
+func (s *NotificationDigestService) Stop() {
+	close(s.stopCh)
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Per-event notification states. Without a stored state the user is notified
+// according to their global preferences, and only about events they take
+// part in.
+const (
+	EventNotificationsDefault  = "default"
+	EventNotificationsWatching = "watching"
+	EventNotificationsMuted    = "muted"
+)
+
+type EventNotificationSettingsResponse struct {
+	EventID     string                           `json:"event_id"`
+	State       string                           `json:"state"`
+	Preferences []NotificationPreferenceResponse `json:"preferences"`
+}
+
+type EventNotificationStateResponse struct {
+	EventID   string `json:"event_id"`
+	EventSlug string `json:"event_slug"`
+	EventName string `json:"event_name"`
+	State     string `json:"state"`
+	CreatedAt string `json:"created_at"`
+}
+
+// eventNotificationContext holds a user's event-scoped settings while
+// deciding which channels a notification goes to.
+type eventNotificationContext struct {
+	state     string
+	overrides []repository.EventNotificationPreference
+}
+
+// ListEventStates returns the events the user watches or has muted.
+func (s *NotificationService) ListEventStates(ctx context.Context, userID uuid.UUID) ([]EventNotificationStateResponse, error) {
+	rows, err := s.queries.ListEventNotificationStates(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("listing event notification states: %w", err)
+	}
+
+	result := make([]EventNotificationStateResponse, len(rows))
+	for i, r := range rows {
+		result[i] = EventNotificationStateResponse{
+			EventID:   r.EventID.String(),
+			EventSlug: r.EventSlug,
+			EventName: r.EventName,
+			State:     r.State,
+			CreatedAt: r.CreatedAt.Format(time.RFC3339),
+		}
+	}
+	return result, nil
+}
+
+// GetEventSettings returns the user's state for the event and the effective
+// setting of every trigger and channel there, including overrides.
+func (s *NotificationService) GetEventSettings(ctx context.Context, userID uuid.UUID, slug string) (EventNotificationSettingsResponse, error) {
+	event, err := s.getEvent(ctx, slug)
+	if err != nil {
+		return EventNotificationSettingsResponse{}, err
+	}
+
+	prefs, err := s.queries.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return EventNotificationSettingsResponse{}, fmt.Errorf("getting notification preferences: %w", err)
+	}
+	ec, err := s.eventNotificationContext(ctx, userID, event.ID)
+	if err != nil {
+		return EventNotificationSettingsResponse{}, err
+	}
+
+	resp := EventNotificationSettingsResponse{
+		EventID:     event.ID.String(),
+		State:       ec.state,
+		Preferences: make([]NotificationPreferenceResponse, 0, len(notificationChannels)*len(notificationTriggers)),
+	}
+	for _, t := range notificationTriggers {
+		for _, channel := range notificationChannels {
+			resp.Preferences = append(resp.Preferences, NotificationPreferenceResponse{
+				TriggerType: t.triggerType,
+				Channel:     channel,
+				IsEnabled:   eventChannelEnabled(prefs, ec, t.triggerType, channel),
+			})
+		}
+	}
+	return resp, nil
+}
+
+// SetEventState watches, mutes, or resets (default) the user's notifications
+// for an event.
+func (s *NotificationService) SetEventState(ctx context.Context, userID uuid.UUID, slug, state string) error {
+	event, err := s.getEvent(ctx, slug)
+	if err != nil {
+		return err
+	}
+
+	switch state {
+	case EventNotificationsDefault:
+		err = s.queries.DeleteEventNotificationState(ctx, userID, event.ID)
+	case EventNotificationsWatching, EventNotificationsMuted:
+		err = s.queries.UpsertEventNotificationState(ctx, userID, event.ID, state)
+	default:
+		return model.NewFieldError(model.ErrInvalidInput, "state", "must be default, watching, or muted")
+	}
+	if err != nil {
+		return fmt.Errorf("setting event notification state: %w", err)
+	}
+	return nil
+}
+
+// UpdateEventPreference overrides a preference for one event. A nil isEnabled
+// removes the override, so the global preference applies again.
+func (s *NotificationService) UpdateEventPreference(ctx context.Context, userID uuid.UUID, slug, triggerType, channel string, isEnabled *bool) error {
+	event, err := s.getEvent(ctx, slug)
+	if err != nil {
+		return err
+	}
+	if !isNotificationTrigger(triggerType) {
+		return model.NewFieldError(model.ErrInvalidInput, "trigger_type", "invalid trigger type")
+	}
+	if !isNotificationChannel(channel) {
+		return model.NewFieldError(model.ErrInvalidInput, "channel", "invalid channel, must be in_app, email, or push")
+	}
+
+	if isEnabled == nil {
+		err = s.queries.DeleteEventNotificationPreference(ctx, userID, event.ID, triggerType, channel)
+	} else {
+		err = s.queries.UpsertEventNotificationPreference(ctx, repository.UpsertEventNotificationPreferenceParams{
+			UserID:      userID,
+			EventID:     event.ID,
+			TriggerType: triggerType,
+			Channel:     channel,
+			IsEnabled:   *isEnabled,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("updating event notification preference: %w", err)
+	}
+	return nil
+}
+
+// eventWatchers returns the users watching an event.
+func (s *NotificationService) eventWatchers(ctx context.Context, eventID uuid.UUID) []uuid.UUID {
+	watchers, err := s.queries.ListEventWatchers(ctx, eventID)
+	if err != nil {
+		s.logger.Error("failed to list event watchers", "error", err, "event_id", eventID)
+		return nil
+	}
+	return watchers
+}
+
+func (s *NotificationService) eventNotificationContext(ctx context.Context, userID, eventID uuid.UUID) (eventNotificationContext, error) {
+	ec := eventNotificationContext{state: EventNotificationsDefault}
+
+	state, err := s.queries.GetEventNotificationState(ctx, userID, eventID)
+	switch {
+	case err == nil:
+		ec.state = state
+	case !errors.Is(err, pgx.ErrNoRows):
+		return ec, fmt.Errorf("getting event notification state: %w", err)
+	}
+
+	ec.overrides, err = s.queries.GetEventNotificationPreferences(ctx, userID, eventID)
+	if err != nil {
+		return ec, fmt.Errorf("getting event notification preferences: %w", err)
+	}
+	return ec, nil
+}
+
+func (s *NotificationService) getEvent(ctx context.Context, slug string) (repository.Event, error) {
+	event, err := s.queries.GetEventBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.Event{}, model.NewDomainError(model.ErrNotFound, "event not found")
+		}
+		return repository.Event{}, fmt.Errorf("fetching event: %w", err)
+	}
+	return event, nil
+}
+
+// eventChannelEnabled reports whether a notification of triggerType for an
+// event goes out on channel. An event override wins; otherwise a muted event
+// sends nothing, and a watched event turns on in-app notifications the user
+// has not configured globally.
+func eventChannelEnabled(prefs []repository.NotificationPreference, ec eventNotificationContext, triggerType, channel string) bool {
+	for _, o := range ec.overrides {
+		if o.TriggerType == triggerType && o.Channel == channel {
+			return o.IsEnabled
+		}
+	}
+	if ec.state == EventNotificationsMuted {
+		return false
+	}
+	if ec.state == EventNotificationsWatching && channel == ChannelInApp {
+		for _, p := range prefs {
+			if p.TriggerType == triggerType && p.Channel == channel {
+				return p.IsEnabled
+			}
+		}
+		return true
+	}
+	return channelEnabled(prefs, triggerType, channel)
+}
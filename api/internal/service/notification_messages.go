@@ -0,0 +1,199 @@
+package service
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationMessage describes a notification independently of language, so
+// title and body can be rendered in each recipient's language. It is stored
+// as JSON while waiting for a digest.
+type NotificationMessage struct {
+	TriggerType string    `json:"trigger_type"`
+	EventName   string    `json:"event_name"`
+	EventSlug   string    `json:"event_slug"`
+	ShiftID     uuid.UUID `json:"shift_id"` // zero for event-level triggers
+	Username    string    `json:"username"` // user whose shift changed
+	TeamName    string    `json:"team_name"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	TimeZone    string    `json:"time_zone"`       // event time zone for Start and End; empty = UTC
+	Title       string    `json:"title,omitempty"` // announcement title, written by an admin; webhook name
+	Body        string    `json:"body,omitempty"`  // announcement text; last webhook error
+}
+
+type notificationText struct {
+	title string
+	body  string
+}
+
+// notificationTexts holds the title and body formats per language and trigger.
+// Admin triggers use the texts of the matching team-level trigger.
+// Arguments: 1 event name, 2 username, 3 team name, 4 formatted time range.
+var notificationTexts = map[string]map[string]notificationText{
+	"en": {
+		TriggerShiftCreated:  {"%[1]s: New shift", "%[2]s signed up for %[3]s (%[4]s)"},
+		TriggerShiftUpdated:  {"%[1]s: Shift updated", "%[2]s's %[3]s shift was updated (%[4]s)"},
+		TriggerShiftDeleted:  {"%[1]s: Shift deleted", "%[2]s's %[3]s shift was removed (%[4]s)"},
+		TriggerEventLocked:   {"%[1]s: Locked", "Event \"%[1]s\" has been locked — shifts can no longer be edited"},
+		TriggerEventUnlocked: {"%[1]s: Unlocked", "Event \"%[1]s\" has been unlocked — shifts can be edited again"},
+
+		TriggerShiftAssignedToYou:  {"%[1]s: New shift for you", "You were assigned to %[3]s (%[4]s)"},
+		TriggerShiftRemovedFromYou: {"%[1]s: Your shift was removed", "Your %[3]s shift was removed (%[4]s)"},
+		TriggerShiftTimeChanged:    {"%[1]s: Your shift was moved", "Your %[3]s shift now takes place %[4]s"},
+	},
+	"de": {
+		TriggerShiftCreated:  {"%[1]s: Neue Schicht", "%[2]s hat sich für %[3]s eingetragen (%[4]s)"},
+		TriggerShiftUpdated:  {"%[1]s: Schicht geändert", "Die %[3]s-Schicht von %[2]s wurde geändert (%[4]s)"},
+		TriggerShiftDeleted:  {"%[1]s: Schicht gelöscht", "Die %[3]s-Schicht von %[2]s wurde entfernt (%[4]s)"},
+		TriggerEventLocked:   {"%[1]s: Gesperrt", "Die Veranstaltung „%[1]s“ wurde gesperrt — Schichten können nicht mehr bearbeitet werden"},
+		TriggerEventUnlocked: {"%[1]s: Entsperrt", "Die Veranstaltung „%[1]s“ wurde entsperrt — Schichten können wieder bearbeitet werden"},
+
+		TriggerShiftAssignedToYou:  {"%[1]s: Neue Schicht für dich", "Du wurdest für %[3]s eingeteilt (%[4]s)"},
+		TriggerShiftRemovedFromYou: {"%[1]s: Deine Schicht wurde entfernt", "Deine %[3]s-Schicht wurde entfernt (%[4]s)"},
+		TriggerShiftTimeChanged:    {"%[1]s: Deine Schicht wurde verschoben", "Deine %[3]s-Schicht findet jetzt %[4]s statt"},
+	},
+}
+
+// webhookDisabledTexts holds the title and body formats of the
+// webhook.disabled trigger per language.
+// Arguments: 1 webhook name, 2 last error.
+var webhookDisabledTexts = map[string]notificationText{
+	"en": {"Webhook disabled: %[1]s", "Deliveries to the webhook \"%[1]s\" failed repeatedly, so it was disabled. Last error: %[2]s"},
+	"de": {"Webhook deaktiviert: %[1]s", "Zustellungen an den Webhook „%[1]s“ sind wiederholt fehlgeschlagen, daher wurde er deaktiviert. Letzter Fehler: %[2]s"},
+}
+
+// emailTexts holds the fixed parts of notification emails per language.
+var emailTexts = map[string]struct {
+	openEvent string
+	footer    string
+	settings  string
+}{
+	"en": {
+		openEvent: "Open event",
+		footer:    "You receive this email because email notifications are enabled for this kind of change.",
+		settings:  "Notification settings",
+	},
+	"de": {
+		openEvent: "Veranstaltung öffnen",
+		footer:    "Du erhältst diese E-Mail, weil E-Mail-Benachrichtigungen für diese Art von Änderung aktiviert sind.",
+		settings:  "Benachrichtigungseinstellungen",
+	},
+}
+
+// notificationLanguage maps a user language to a supported one.
+func notificationLanguage(lang string) string {
+	if _, ok := notificationTexts[lang]; ok {
+		return lang
+	}
+	return "en"
+}
+
+// Render returns the title and body of the message in lang, falling back to
+// English for unsupported languages. Announcements are shown as written.
+// Messages about disabled webhooks name the webhook in Title and its last
+// error in Body.
+func (m NotificationMessage) Render(lang string) (string, string) {
+	if m.TriggerType == TriggerAnnouncement {
+		return m.EventName + ": " + m.Title, m.Body
+	}
+
+	lang = notificationLanguage(lang)
+	if m.TriggerType == TriggerWebhookDisabled {
+		text := webhookDisabledTexts[lang]
+		title := fmt.Sprintf(text.title, m.Title)
+		if m.EventName != "" {
+			title = m.EventName + ": " + title
+		}
+		return title, fmt.Sprintf(text.body, m.Title, m.Body)
+	}
+	text, ok := notificationTexts[lang][strings.TrimPrefix(m.TriggerType, "admin.")]
+	if !ok {
+		return m.EventName, ""
+	}
+
+	args := []any{m.EventName, m.Username, m.TeamName, m.timeRange(lang)}
+	return fmt.Sprintf(text.title, args...), fmt.Sprintf(text.body, args...)
+}
+
+// timeRange formats the message's time range in the event time zone.
+func (m NotificationMessage) timeRange(lang string) string {
+	loc := time.UTC
+	if m.TimeZone != "" {
+		if l, err := time.LoadLocation(m.TimeZone); err == nil {
+			loc = l
+		}
+	}
+	if lang == "de" {
+		return formatTimeRangeDE(m.Start.In(loc), m.End.In(loc))
+	}
+	return formatTimeRange(m.Start.In(loc), m.End.In(loc))
+}
+
+// formatTimeRangeDE is the German counterpart of formatTimeRange.
+// Same day: "15.01., 14:00 – 18:00", cross-day: "15.01., 14:00 – 16.01., 02:00".
+func formatTimeRangeDE(start, end time.Time) string {
+	if start.Year() == end.Year() && start.YearDay() == end.YearDay() {
+		return fmt.Sprintf("%s, %s – %s", start.Format("02.01."), start.Format("15:04"), end.Format("15:04"))
+	}
+	return fmt.Sprintf("%s, %s – %s, %s", start.Format("02.01."), start.Format("15:04"), end.Format("02.01."), end.Format("15:04"))
+}
+
+// notificationEmail renders a notification as an HTML and plain-text email,
+// with one paragraph or list item per line. baseURL may be empty, in which
+// case links are omitted.
+func notificationEmail(lang, title string, lines []string, baseURL, eventSlug string) (string, string) {
+	texts := emailTexts[notificationLanguage(lang)]
+	baseURL = strings.TrimRight(baseURL, "/")
+
+	var eventURL, settingsURL string
+	if baseURL != "" {
+		settingsURL = baseURL + "/settings/notifications"
+		if eventSlug != "" {
+			eventURL = baseURL + "/events/" + eventSlug
+		}
+	}
+
+	var text strings.Builder
+	text.WriteString(title + "\n\n")
+	for _, line := range lines {
+		if len(lines) > 1 {
+			text.WriteString("- ")
+		}
+		text.WriteString(line + "\n")
+	}
+	if eventURL != "" {
+		text.WriteString("\n" + texts.openEvent + ": " + eventURL + "\n")
+	}
+	text.WriteString("\n-- \n" + texts.footer + "\n")
+	if settingsURL != "" {
+		text.WriteString(texts.settings + ": " + settingsURL + "\n")
+	}
+
+	var h strings.Builder
+	h.WriteString(`<!DOCTYPE html><html lang="` + notificationLanguage(lang) + `"><body style="font-family:sans-serif;color:#222">`)
+	h.WriteString("<h2>" + html.EscapeString(title) + "</h2>")
+	if len(lines) == 1 {
+		h.WriteString(`<p style="white-space:pre-line">` + html.EscapeString(lines[0]) + "</p>")
+	} else {
+		h.WriteString("<ul>")
+		for _, line := range lines {
+			h.WriteString("<li>" + html.EscapeString(line) + "</li>")
+		}
+		h.WriteString("</ul>")
+	}
+	if eventURL != "" {
+		h.WriteString(`<p><a href="` + html.EscapeString(eventURL) + `">` + html.EscapeString(texts.openEvent) + "</a></p>")
+	}
+	h.WriteString(`<p style="color:#666;font-size:small">` + html.EscapeString(texts.footer))
+	if settingsURL != "" {
+		h.WriteString(` <a href="` + html.EscapeString(settingsURL) + `">` + html.EscapeString(texts.settings) + "</a>")
+	}
+	h.WriteString("</p></body></html>")
+
+	return h.String(), text.String()
+}
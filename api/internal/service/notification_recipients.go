@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ShiftSnapshot is the state of a shift before or after a change.
+type ShiftSnapshot struct {
+	UserID   uuid.UUID
+	TeamID   uuid.UUID
+	Username string
+	TeamName string
+	Start    time.Time
+	End      time.Time
+}
+
+// ShiftChange describes a change to a shift for notification purposes.
+// Before is nil for created shifts, After is nil for deleted ones.
+type ShiftChange struct {
+	ShiftID   uuid.UUID
+	EventID   uuid.UUID
+	EventName string
+	EventSlug string
+	TimeZone  string
+	ActorID   uuid.UUID
+	Before    *ShiftSnapshot
+	After     *ShiftSnapshot
+}
+
+// shiftRecipient is a user to notify about a shift change and what to tell them.
+type shiftRecipient struct {
+	userID uuid.UUID
+	msg    NotificationMessage
+}
+
+// NotifyShiftChange notifies the users affected by a shift change. The
+// assignee gets a personal trigger, event admins get the admin trigger, and
+// users with overlapping shifts in the same team and the event's watchers get
+// the team-level trigger.
+// Each user is notified at most once per change and the actor not at all.
+func (s *NotificationService) NotifyShiftChange(ctx context.Context, change ShiftChange) {
+	shifts, err := s.queries.ListShiftsByEvent(ctx, change.EventID)
+	if err != nil {
+		s.logger.Error("failed to list shifts for notification", "error", err, "event_id", change.EventID)
+		return
+	}
+	admins, err := s.queries.ListEventAdmins(ctx, change.EventID)
+	if err != nil {
+		s.logger.Error("failed to list event admins for notification", "error", err, "event_id", change.EventID)
+		admins = nil
+	}
+	adminIDs := make([]uuid.UUID, len(admins))
+	for i, a := range admins {
+		adminIDs[i] = a.ID
+	}
+
+	watchers := s.eventWatchers(ctx, change.EventID)
+
+	eventID := change.EventID
+	for _, r := range shiftChangeRecipients(change, shifts, adminIDs, watchers) {
+		if err := s.Notify(ctx, r.userID, &eventID, r.msg); err != nil {
+			s.logger.Error("failed to create notification", "error", err, "user_id", r.userID)
+		}
+	}
+}
+
+// shiftChangeRecipients resolves who is notified about change and with which
+// trigger. Personal triggers take precedence over admin triggers, which take
+// precedence over team-level ones.
+func shiftChangeRecipients(change ShiftChange, shifts []repository.ListShiftsByEventRow, adminIDs, watcherIDs []uuid.UUID) []shiftRecipient {
+	var recipients []shiftRecipient
+	notified := map[uuid.UUID]bool{change.ActorID: true}
+	add := func(userID uuid.UUID, triggerType string, snap *ShiftSnapshot) {
+		if notified[userID] {
+			return
+		}
+		notified[userID] = true
+		recipients = append(recipients, shiftRecipient{userID: userID, msg: change.message(triggerType, snap)})
+	}
+
+	// The shift's current data, or its last data if it was deleted
+	current := change.After
+	if current == nil {
+		current = change.Before
+	}
+
+	before, after := change.Before, change.After
+	teamTrigger, adminTrigger := TriggerShiftUpdated, TriggerAdminShiftUpdated
+	switch {
+	case before == nil:
+		teamTrigger, adminTrigger = TriggerShiftCreated, TriggerAdminShiftCreated
+		add(after.UserID, TriggerShiftAssignedToYou, after)
+	case after == nil:
+		teamTrigger, adminTrigger = TriggerShiftDeleted, TriggerAdminShiftDeleted
+		add(before.UserID, TriggerShiftRemovedFromYou, before)
+	case before.UserID != after.UserID:
+		add(before.UserID, TriggerShiftRemovedFromYou, before)
+		add(after.UserID, TriggerShiftAssignedToYou, after)
+	case before.TeamID != after.TeamID:
+		add(after.UserID, TriggerShiftAssignedToYou, after)
+	case !before.Start.Equal(after.Start) || !before.End.Equal(after.End):
+		add(after.UserID, TriggerShiftTimeChanged, after)
+	default:
+		// Nothing the assignee would notice changed
+		notified[after.UserID] = true
+	}
+
+	for _, id := range adminIDs {
+		add(id, adminTrigger, current)
+	}
+
+	for _, sh := range shifts {
+		if sh.ID == change.ShiftID {
+			continue
+		}
+		if overlapsSnapshot(sh, before) || overlapsSnapshot(sh, after) {
+			add(sh.UserID, teamTrigger, current)
+		}
+	}
+
+	for _, id := range watcherIDs {
+		add(id, teamTrigger, current)
+	}
+	return recipients
+}
+
+// overlapsSnapshot reports whether sh is in the same team as snap and
+// overlaps it in time.
+func overlapsSnapshot(sh repository.ListShiftsByEventRow, snap *ShiftSnapshot) bool {
+	return snap != nil && sh.TeamID == snap.TeamID &&
+		sh.StartTime.Before(snap.End) && snap.Start.Before(sh.EndTime)
+}
+
+func (c ShiftChange) message(triggerType string, snap *ShiftSnapshot) NotificationMessage {
+	return NotificationMessage{
+		TriggerType: triggerType,
+		EventName:   c.EventName,
+		EventSlug:   c.EventSlug,
+		ShiftID:     c.ShiftID,
+		Username:    snap.Username,
+		TeamName:    snap.TeamName,
+		Start:       snap.Start,
+		End:         snap.End,
+		TimeZone:    c.TimeZone,
+	}
+}
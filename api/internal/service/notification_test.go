@@ -0,0 +1,292 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+)
+
+func TestNotificationMessageRender(t *testing.T) {
+	if _, err := time.LoadLocation("Europe/Berlin"); err != nil {
+		t.Skip("time zone data not available")
+	}
+	msg := NotificationMessage{
+		TriggerType: TriggerShiftUpdated,
+		EventName:   "Camp",
+		Username:    "alice",
+		TeamName:    "Bar",
+		Start:       time.Date(2025, 7, 4, 20, 0, 0, 0, time.UTC),
+		End:         time.Date(2025, 7, 5, 0, 0, 0, 0, time.UTC),
+		TimeZone:    "Europe/Berlin",
+	}
+
+	tests := []struct {
+		lang, title, body string
+	}{
+		{"en", "Camp: Shift updated", "alice's Bar shift was updated (Jul 4, 22:00 – Jul 5, 02:00)"},
+		{"de", "Camp: Schicht geändert", "Die Bar-Schicht von alice wurde geändert (04.07., 22:00 – 05.07., 02:00)"},
+		{"fr", "Camp: Shift updated", "alice's Bar shift was updated (Jul 4, 22:00 – Jul 5, 02:00)"},
+	}
+	for _, tt := range tests {
+		title, body := msg.Render(tt.lang)
+		if title != tt.title || body != tt.body {
+			t.Errorf("Render(%q) = %q, %q; want %q, %q", tt.lang, title, body, tt.title, tt.body)
+		}
+	}
+
+	admin := msg
+	admin.TriggerType = TriggerAdminShiftUpdated
+	if title, _ := admin.Render("en"); title != "Camp: Shift updated" {
+		t.Errorf("admin trigger title = %q, want the team-level text", title)
+	}
+
+	moved := msg
+	moved.TriggerType = TriggerShiftTimeChanged
+	if _, body := moved.Render("de"); body != "Deine Bar-Schicht findet jetzt 04.07., 22:00 – 05.07., 02:00 statt" {
+		t.Errorf("time changed body = %q", body)
+	}
+
+	locked := NotificationMessage{TriggerType: TriggerEventLocked, EventName: "Camp"}
+	if _, body := locked.Render("en"); strings.Contains(body, "%!") {
+		t.Errorf("unused arguments leaked into body: %q", body)
+	}
+
+	disabled := NotificationMessage{TriggerType: TriggerWebhookDisabled, EventName: "Camp", Title: "Chat", Body: "HTTP 500"}
+	title, body := disabled.Render("de")
+	if title != "Camp: Webhook deaktiviert: Chat" || !strings.HasSuffix(body, "Letzter Fehler: HTTP 500") {
+		t.Errorf("webhook disabled = %q, %q", title, body)
+	}
+}
+
+func TestChannelEnabled(t *testing.T) {
+	prefs := []repository.NotificationPreference{
+		{TriggerType: TriggerShiftCreated, Channel: ChannelInApp, IsEnabled: false},
+		{TriggerType: TriggerShiftCreated, Channel: ChannelEmail, IsEnabled: true},
+	}
+
+	if channelEnabled(prefs, TriggerShiftCreated, ChannelInApp) {
+		t.Error("disabled in-app preference was ignored")
+	}
+	if !channelEnabled(prefs, TriggerShiftCreated, ChannelEmail) {
+		t.Error("enabled email preference was ignored")
+	}
+	if !channelEnabled(prefs, TriggerShiftRemovedFromYou, ChannelInApp) {
+		t.Error("in-app should default to enabled for personal triggers")
+	}
+	if channelEnabled(prefs, TriggerShiftDeleted, ChannelInApp) {
+		t.Error("team-level triggers should be opt-in")
+	}
+	if channelEnabled(prefs, TriggerShiftRemovedFromYou, ChannelEmail) {
+		t.Error("email should default to disabled")
+	}
+}
+
+func TestShiftChangeRecipients(t *testing.T) {
+	at := func(h int) time.Time { return time.Date(2025, 7, 4, h, 0, 0, 0, time.UTC) }
+	actor, alice, bob, carol, dave, admin := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	bar, gate := uuid.New(), uuid.New()
+	shiftID := uuid.New()
+
+	shifts := []repository.ListShiftsByEventRow{
+		{ID: shiftID, UserID: alice, TeamID: bar, StartTime: at(10), EndTime: at(14)},
+		{ID: uuid.New(), UserID: bob, TeamID: bar, StartTime: at(12), EndTime: at(16)},   // overlaps
+		{ID: uuid.New(), UserID: carol, TeamID: bar, StartTime: at(14), EndTime: at(18)}, // adjacent only
+		{ID: uuid.New(), UserID: dave, TeamID: gate, StartTime: at(10), EndTime: at(14)}, // other team
+		{ID: uuid.New(), UserID: actor, TeamID: bar, StartTime: at(10), EndTime: at(14)},
+	}
+	before := &ShiftSnapshot{UserID: alice, TeamID: bar, Start: at(10), End: at(14)}
+
+	recipients := func(change ShiftChange) map[uuid.UUID]string {
+		got := make(map[uuid.UUID]string)
+		for _, r := range shiftChangeRecipients(change, shifts, []uuid.UUID{admin, actor}, nil) {
+			got[r.userID] = r.msg.TriggerType
+		}
+		return got
+	}
+	check := func(name string, got, want map[uuid.UUID]string) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Errorf("%s: got %d recipients, want %d: %v", name, len(got), len(want), got)
+		}
+		for id, trigger := range want {
+			if got[id] != trigger {
+				t.Errorf("%s: recipient %s got %q, want %q", name, id, got[id], trigger)
+			}
+		}
+	}
+
+	moved := *before
+	moved.Start, moved.End = at(11), at(15)
+	check("time changed", recipients(ShiftChange{ShiftID: shiftID, ActorID: actor, Before: before, After: &moved}),
+		map[uuid.UUID]string{
+			alice: TriggerShiftTimeChanged,
+			admin: TriggerAdminShiftUpdated,
+			bob:   TriggerShiftUpdated,
+			carol: TriggerShiftUpdated, // overlaps the new slot
+		})
+
+	reassigned := *before
+	reassigned.UserID = bob
+	check("reassigned", recipients(ShiftChange{ShiftID: shiftID, ActorID: actor, Before: before, After: &reassigned}),
+		map[uuid.UUID]string{
+			alice: TriggerShiftRemovedFromYou,
+			bob:   TriggerShiftAssignedToYou,
+			admin: TriggerAdminShiftUpdated,
+		})
+
+	check("deleted by assignee", recipients(ShiftChange{ShiftID: shiftID, ActorID: alice, Before: before}),
+		map[uuid.UUID]string{
+			actor: TriggerAdminShiftDeleted,
+			admin: TriggerAdminShiftDeleted,
+			bob:   TriggerShiftDeleted,
+		})
+
+	watched := make(map[uuid.UUID]string)
+	for _, r := range shiftChangeRecipients(ShiftChange{ShiftID: shiftID, ActorID: actor, Before: before}, shifts, []uuid.UUID{admin}, []uuid.UUID{dave, admin, alice}) {
+		watched[r.userID] = r.msg.TriggerType
+	}
+	check("deleted with watchers", watched,
+		map[uuid.UUID]string{
+			alice: TriggerShiftRemovedFromYou,
+			admin: TriggerAdminShiftDeleted,
+			bob:   TriggerShiftDeleted,
+			dave:  TriggerShiftDeleted, // watches the event
+		})
+}
+
+func TestEventChannelEnabled(t *testing.T) {
+	prefs := []repository.NotificationPreference{
+		{TriggerType: TriggerShiftUpdated, Channel: ChannelInApp, IsEnabled: false},
+		{TriggerType: TriggerShiftAssignedToYou, Channel: ChannelEmail, IsEnabled: true},
+	}
+	override := []repository.EventNotificationPreference{
+		{TriggerType: TriggerShiftAssignedToYou, Channel: ChannelEmail, IsEnabled: false},
+		{TriggerType: TriggerEventLocked, Channel: ChannelInApp, IsEnabled: true},
+	}
+
+	tests := []struct {
+		name    string
+		ec      eventNotificationContext
+		trigger string
+		channel string
+		want    bool
+	}{
+		{"default uses global", eventNotificationContext{state: EventNotificationsDefault}, TriggerShiftAssignedToYou, ChannelEmail, true},
+		{"default team trigger is opt-in", eventNotificationContext{state: EventNotificationsDefault}, TriggerShiftCreated, ChannelInApp, false},
+		{"override wins", eventNotificationContext{state: EventNotificationsDefault, overrides: override}, TriggerShiftAssignedToYou, ChannelEmail, false},
+		{"override wins over mute", eventNotificationContext{state: EventNotificationsMuted, overrides: override}, TriggerEventLocked, ChannelInApp, true},
+		{"muted", eventNotificationContext{state: EventNotificationsMuted}, TriggerShiftAssignedToYou, ChannelInApp, false},
+		{"watching turns on in-app", eventNotificationContext{state: EventNotificationsWatching}, TriggerShiftCreated, ChannelInApp, true},
+		{"watching keeps global opt-out", eventNotificationContext{state: EventNotificationsWatching}, TriggerShiftUpdated, ChannelInApp, false},
+		{"watching leaves email alone", eventNotificationContext{state: EventNotificationsWatching}, TriggerShiftCreated, ChannelEmail, false},
+	}
+	for _, tt := range tests {
+		if got := eventChannelEnabled(prefs, tt.ec, tt.trigger, tt.channel); got != tt.want {
+			t.Errorf("%s: eventChannelEnabled = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNotificationEmail(t *testing.T) {
+	htmlBody, textBody := notificationEmail("de", "Camp: Neue Schicht", []string{"<b>bob</b> hat sich eingetragen"}, "https://plan.example.com/", "camp")
+
+	if strings.Contains(htmlBody, "<b>bob</b>") {
+		t.Error("body was not HTML-escaped")
+	}
+	for _, want := range []string{"https://plan.example.com/events/camp", "Veranstaltung öffnen", "https://plan.example.com/settings/notifications"} {
+		if !strings.Contains(htmlBody, want) || !strings.Contains(textBody, want) {
+			t.Errorf("email is missing %q", want)
+		}
+	}
+
+	htmlBody, _ = notificationEmail("en", "t", []string{"b"}, "", "camp")
+	if strings.Contains(htmlBody, "href") {
+		t.Error("links rendered without a base URL")
+	}
+}
+
+func TestCollapseDigest(t *testing.T) {
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	at := func(h int) time.Time { return time.Date(2025, 7, 4, h, 0, 0, 0, time.UTC) }
+	msgs := []NotificationMessage{
+		{TriggerType: TriggerShiftCreated, ShiftID: a, Start: at(10)},
+		{TriggerType: TriggerShiftUpdated, ShiftID: b, Start: at(12)},
+		{TriggerType: TriggerShiftUpdated, ShiftID: a, Start: at(11)},
+		{TriggerType: TriggerEventLocked},
+		{TriggerType: TriggerShiftDeleted, ShiftID: b},
+		{TriggerType: TriggerShiftCreated, ShiftID: c},
+		{TriggerType: TriggerShiftUpdated, ShiftID: a, Start: at(14)},
+		{TriggerType: TriggerShiftDeleted, ShiftID: c},
+	}
+
+	got := collapseDigest(msgs)
+	want := []NotificationMessage{
+		{TriggerType: TriggerShiftCreated, ShiftID: a, Start: at(14)},
+		{TriggerType: TriggerShiftDeleted, ShiftID: b},
+		{TriggerType: TriggerEventLocked},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d messages, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i].TriggerType != want[i].TriggerType || got[i].ShiftID != want[i].ShiftID || !got[i].Start.Equal(want[i].Start) {
+			t.Errorf("message %d = %s %s %s, want %s %s %s", i,
+				got[i].TriggerType, got[i].ShiftID, got[i].Start, want[i].TriggerType, want[i].ShiftID, want[i].Start)
+		}
+	}
+}
+
+func TestCollapseDigestPersonal(t *testing.T) {
+	a := uuid.New()
+	msgs := []NotificationMessage{
+		{TriggerType: TriggerShiftAssignedToYou, ShiftID: a},
+		{TriggerType: TriggerShiftTimeChanged, ShiftID: a},
+	}
+	if got := collapseDigest(msgs); len(got) != 1 || got[0].TriggerType != TriggerShiftAssignedToYou {
+		t.Errorf("assigned then moved = %+v, want one assignment", got)
+	}
+
+	msgs = append(msgs, NotificationMessage{TriggerType: TriggerShiftRemovedFromYou, ShiftID: a})
+	if got := collapseDigest(msgs); len(got) != 0 {
+		t.Errorf("assigned then removed = %+v, want nothing", got)
+	}
+}
+
+func TestDigestDue(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+
+	oldest := time.Date(2025, 7, 4, 14, 25, 0, 0, berlin)
+	if got, want := digestDue(DeliveryHourly, oldest, berlin, 8), time.Date(2025, 7, 4, 15, 0, 0, 0, berlin); !got.Equal(want) {
+		t.Errorf("hourly due = %s, want %s", got, want)
+	}
+	if got, want := digestDue(DeliveryDaily, oldest, berlin, 8), time.Date(2025, 7, 5, 8, 0, 0, 0, berlin); !got.Equal(want) {
+		t.Errorf("daily due = %s, want %s", got, want)
+	}
+	early := time.Date(2025, 7, 4, 6, 0, 0, 0, berlin)
+	if got, want := digestDue(DeliveryDaily, early, berlin, 8), time.Date(2025, 7, 4, 8, 0, 0, 0, berlin); !got.Equal(want) {
+		t.Errorf("daily due before digest hour = %s, want %s", got, want)
+	}
+}
+
+func TestRenderDigest(t *testing.T) {
+	msgs := []NotificationMessage{
+		{TriggerType: TriggerShiftCreated, EventName: "Camp", Username: "alice", TeamName: "Bar"},
+		{TriggerType: TriggerShiftDeleted, EventName: "Camp", Username: "bob", TeamName: "Gate"},
+	}
+
+	title, lines := renderDigest("de", msgs)
+	if title != "Camp: 2 Änderungen" || len(lines) != 2 {
+		t.Errorf("renderDigest = %q, %d lines", title, len(lines))
+	}
+
+	title, lines = renderDigest("en", msgs[:1])
+	if title != "Camp: New shift" || len(lines) != 1 {
+		t.Errorf("single-item digest = %q, %d lines", title, len(lines))
+	}
+}
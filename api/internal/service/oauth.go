@@ -13,22 +13,33 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/echtkpvl/rncasp/internal/config"
 	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/outbound"
 	"github.com/echtkpvl/rncasp/internal/repository"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/redis/go-redis/v9"
 )
 
+// oauthMaxResponseBody caps token, userinfo, discovery and JWKS responses,
+// which come from admin-configured URLs.
+const oauthMaxResponseBody = 1 << 20
+
 type OAuthService struct {
-	queries *repository.Queries
-	rdb     *redis.Client
-	appCfg  *config.AppConfig
-	authCfg *config.AuthConfig
-	logger  *slog.Logger
+	queries        *repository.Queries
+	rdb            *redis.Client
+	appCfg         *config.AppConfig
+	authCfg        *config.AuthConfig
+	logger         *slog.Logger
+	httpClient     *http.Client
+	webhookService *WebhookService
+
+	jwksMu sync.Mutex
+	jwks   map[string]jwksCacheEntry // signing keys by JWKS URL
 }
 
 func NewOAuthService(
@@ -37,28 +48,41 @@ func NewOAuthService(
 	appCfg *config.AppConfig,
 	authCfg *config.AuthConfig,
 	logger *slog.Logger,
+	policy *outbound.Policy,
 ) *OAuthService {
 	return &OAuthService{
-		queries: queries,
-		rdb:     rdb,
-		appCfg:  appCfg,
-		authCfg: authCfg,
-		logger:  logger,
+		queries:    queries,
+		rdb:        rdb,
+		appCfg:     appCfg,
+		authCfg:    authCfg,
+		logger:     logger,
+		httpClient: policy.Client(15*time.Second, oauthMaxResponseBody),
+		jwks:       make(map[string]jwksCacheEntry),
 	}
 }
 
+// SetWebhookService sets the webhook service for trigger dispatch.
+func (s *OAuthService) SetWebhookService(ws *WebhookService) {
+	s.webhookService = ws
+}
+
 // --- Provider CRUD (super-admin only, enforced at handler/middleware level) ---
 
 type ProviderResponse struct {
-	ID           string `json:"id"`
-	Name         string `json:"name"`
-	ClientID     string `json:"client_id"`
-	AuthorizeURL string `json:"authorize_url"`
-	TokenURL     string `json:"token_url"`
-	UserinfoURL  string `json:"userinfo_url"`
-	Scopes       string `json:"scopes"`
-	IsEnabled    bool   `json:"is_enabled"`
-	CreatedAt    string `json:"created_at"`
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	ProviderType  string `json:"provider_type"`
+	ClientID      string `json:"client_id"`
+	IssuerURL     string `json:"issuer_url"`
+	AuthorizeURL  string `json:"authorize_url"`
+	TokenURL      string `json:"token_url"`
+	UserinfoURL   string `json:"userinfo_url"`
+	JWKSURL       string `json:"jwks_url"`
+	EndSessionURL string `json:"end_session_url"`
+	RPLogout      bool   `json:"rp_logout"`
+	Scopes        string `json:"scopes"`
+	IsEnabled     bool   `json:"is_enabled"`
+	CreatedAt     string `json:"created_at"`
 }
 
 // PublicProviderResponse is the lightweight version shown to all users (no secrets)
@@ -69,37 +93,48 @@ type PublicProviderResponse struct {
 
 func providerToResponse(p repository.OauthProvider) ProviderResponse {
 	return ProviderResponse{
-		ID:           p.ID.String(),
-		Name:         p.Name,
-		ClientID:     p.ClientID,
-		AuthorizeURL: p.AuthorizeUrl,
-		TokenURL:     p.TokenUrl,
-		UserinfoURL:  p.UserinfoUrl,
-		Scopes:       p.Scopes,
-		IsEnabled:    p.IsEnabled,
-		CreatedAt:    p.CreatedAt.Format(time.RFC3339),
+		ID:            p.ID.String(),
+		Name:          p.Name,
+		ProviderType:  p.ProviderType,
+		ClientID:      p.ClientID,
+		IssuerURL:     p.IssuerUrl,
+		AuthorizeURL:  p.AuthorizeUrl,
+		TokenURL:      p.TokenUrl,
+		UserinfoURL:   p.UserinfoUrl,
+		JWKSURL:       p.JwksUrl,
+		EndSessionURL: p.EndSessionUrl,
+		RPLogout:      p.RpLogout,
+		Scopes:        p.Scopes,
+		IsEnabled:     p.IsEnabled,
+		CreatedAt:     p.CreatedAt.Format(time.RFC3339),
 	}
 }
 
 type CreateProviderInput struct {
 	Name         string
+	ProviderType string // oauth2 (default) or oidc
 	ClientID     string
 	ClientSecret string
-	AuthorizeURL string
-	TokenURL     string
-	UserinfoURL  string
+	IssuerURL    string // oidc: endpoints are discovered from it
+	AuthorizeURL string // oauth2 only
+	TokenURL     string // oauth2 only
+	UserinfoURL  string // oauth2 only
 	Scopes       string
+	RPLogout     bool // oidc: also log out at the provider
 }
 
 type UpdateProviderInput struct {
 	Name         *string
+	ProviderType *string
 	ClientID     *string
 	ClientSecret *string
+	IssuerURL    *string
 	AuthorizeURL *string
 	TokenURL     *string
 	UserinfoURL  *string
 	Scopes       *string
 	IsEnabled    *bool
+	RPLogout     *bool
 }
 
 func (s *OAuthService) ListProviders(ctx context.Context) ([]ProviderResponse, error) {
@@ -141,6 +176,9 @@ func (s *OAuthService) GetProvider(ctx context.Context, id uuid.UUID) (ProviderR
 }
 
 func (s *OAuthService) CreateProvider(ctx context.Context, input CreateProviderInput) (ProviderResponse, error) {
+	if input.ProviderType == "" {
+		input.ProviderType = ProviderTypeOAuth2
+	}
 	if err := validateProviderInput(input); err != nil {
 		return ProviderResponse{}, err
 	}
@@ -154,7 +192,7 @@ func (s *OAuthService) CreateProvider(ctx context.Context, input CreateProviderI
 		return ProviderResponse{}, fmt.Errorf("checking provider name: %w", err)
 	}
 
-	provider, err := s.queries.CreateOAuthProvider(ctx, repository.CreateOAuthProviderParams{
+	params := repository.CreateOAuthProviderParams{
 		Name:         input.Name,
 		ClientID:     input.ClientID,
 		ClientSecret: input.ClientSecret,
@@ -162,7 +200,24 @@ func (s *OAuthService) CreateProvider(ctx context.Context, input CreateProviderI
 		TokenUrl:     input.TokenURL,
 		UserinfoUrl:  input.UserinfoURL,
 		Scopes:       input.Scopes,
-	})
+		ProviderType: input.ProviderType,
+	}
+	if input.ProviderType == ProviderTypeOIDC {
+		doc, err := s.discoverOIDC(ctx, input.IssuerURL)
+		if err != nil {
+			return ProviderResponse{}, err
+		}
+		params.IssuerUrl = doc.Issuer
+		params.AuthorizeUrl = doc.AuthorizationEndpoint
+		params.TokenUrl = doc.TokenEndpoint
+		params.UserinfoUrl = doc.UserinfoEndpoint
+		params.JwksUrl = doc.JwksURI
+		params.EndSessionUrl = doc.EndSessionEndpoint
+		params.RpLogout = input.RPLogout
+		params.Scopes = oidcScopes(input.Scopes)
+	}
+
+	provider, err := s.queries.CreateOAuthProvider(ctx, params)
 	if err != nil {
 		return ProviderResponse{}, fmt.Errorf("creating provider: %w", err)
 	}
@@ -173,7 +228,7 @@ func (s *OAuthService) CreateProvider(ctx context.Context, input CreateProviderI
 
 func (s *OAuthService) UpdateProvider(ctx context.Context, id uuid.UUID, input UpdateProviderInput) (ProviderResponse, error) {
 	// Verify provider exists
-	_, err := s.queries.GetOAuthProviderByID(ctx, id)
+	current, err := s.queries.GetOAuthProviderByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return ProviderResponse{}, model.NewDomainError(model.ErrNotFound, "provider not found")
@@ -192,7 +247,7 @@ func (s *OAuthService) UpdateProvider(ctx context.Context, id uuid.UUID, input U
 		}
 	}
 
-	provider, err := s.queries.UpdateOAuthProvider(ctx, repository.UpdateOAuthProviderParams{
+	params := repository.UpdateOAuthProviderParams{
 		ID:           id,
 		Name:         input.Name,
 		ClientID:     input.ClientID,
@@ -202,7 +257,14 @@ func (s *OAuthService) UpdateProvider(ctx context.Context, id uuid.UUID, input U
 		UserinfoUrl:  input.UserinfoURL,
 		Scopes:       input.Scopes,
 		IsEnabled:    input.IsEnabled,
-	})
+		ProviderType: input.ProviderType,
+		RpLogout:     input.RPLogout,
+	}
+	if err := s.applyProviderType(ctx, current, input, &params); err != nil {
+		return ProviderResponse{}, err
+	}
+
+	provider, err := s.queries.UpdateOAuthProvider(ctx, params)
 	if err != nil {
 		return ProviderResponse{}, fmt.Errorf("updating provider: %w", err)
 	}
@@ -232,8 +294,10 @@ func (s *OAuthService) DeleteProvider(ctx context.Context, id uuid.UUID) error {
 
 // OAuthState stores the state for CSRF protection during the OAuth flow
 type OAuthState struct {
-	ProviderID string `json:"provider_id"`
-	UserID     string `json:"user_id,omitempty"` // Set when linking an existing account
+	ProviderID   string `json:"provider_id"`
+	UserID       string `json:"user_id,omitempty"` // Set when linking an existing account
+	CodeVerifier string `json:"code_verifier"`     // PKCE verifier sent with the code
+	Nonce        string `json:"nonce,omitempty"`   // Expected in the ID token (OIDC)
 }
 
 // GetAuthorizeURL generates the OAuth2 authorization URL for a provider
@@ -257,13 +321,26 @@ func (s *OAuthService) GetAuthorizeURL(ctx context.Context, providerName string,
 	}
 	stateToken := hex.EncodeToString(stateBytes)
 
+	// PKCE binds the code to this browser's flow. Providers without PKCE
+	// support ignore the extra parameters.
+	codeVerifier, err := randomURLToken(32)
+	if err != nil {
+		return "", fmt.Errorf("generating code verifier: %w", err)
+	}
+
 	// Store state in Redis with 10-minute TTL
 	stateData := OAuthState{
-		ProviderID: provider.ID.String(),
+		ProviderID:   provider.ID.String(),
+		CodeVerifier: codeVerifier,
 	}
 	if linkUserID != nil {
 		stateData.UserID = linkUserID.String()
 	}
+	if provider.ProviderType == ProviderTypeOIDC {
+		if stateData.Nonce, err = randomURLToken(16); err != nil {
+			return "", fmt.Errorf("generating nonce: %w", err)
+		}
+	}
 	stateJSON, _ := json.Marshal(stateData)
 	s.rdb.Set(ctx, oauthStateKey(stateToken), string(stateJSON), 10*time.Minute)
 
@@ -280,6 +357,12 @@ func (s *OAuthService) GetAuthorizeURL(ctx context.Context, providerName string,
 	q.Set("scope", provider.Scopes)
 	q.Set("state", stateToken)
 	q.Set("response_type", "code")
+	q.Set("code_challenge", pkceChallenge(codeVerifier))
+	q.Set("code_challenge_method", "S256")
+	if provider.ProviderType == ProviderTypeOIDC {
+		q.Set("scope", oidcScopes(provider.Scopes))
+		q.Set("nonce", stateData.Nonce)
+	}
 	u.RawQuery = q.Encode()
 
 	return u.String(), nil
@@ -308,16 +391,16 @@ func (s *OAuthService) HandleCallback(ctx context.Context, providerName, code, s
 		return UserResponse{}, SessionInfo{}, fmt.Errorf("fetching provider: %w", err)
 	}
 
-	// Exchange code for access token
-	accessToken, refreshToken, err := s.exchangeCode(provider, providerName, code)
+	// Exchange code for tokens
+	tokens, err := s.exchangeCode(ctx, provider, providerName, code, stateData.CodeVerifier)
 	if err != nil {
 		return UserResponse{}, SessionInfo{}, fmt.Errorf("exchanging code: %w", err)
 	}
+	accessToken, refreshToken := tokens.AccessToken, tokens.RefreshToken
 
-	// Fetch user info from provider
-	userInfo, err := s.fetchUserInfo(provider.UserinfoUrl, accessToken)
+	userInfo, err := s.identify(ctx, provider, tokens, stateData.Nonce)
 	if err != nil {
-		return UserResponse{}, SessionInfo{}, fmt.Errorf("fetching user info: %w", err)
+		return UserResponse{}, SessionInfo{}, err
 	}
 
 	if userInfo.ExternalID == "" {
@@ -330,11 +413,30 @@ func (s *OAuthService) HandleCallback(ctx context.Context, providerName, code, s
 		if err != nil {
 			return UserResponse{}, SessionInfo{}, model.NewDomainError(model.ErrInvalidInput, "invalid user ID in state")
 		}
-		return s.linkAccount(ctx, linkUserID, provider.ID, userInfo, accessToken, refreshToken, ip, userAgent)
+		user, session, err := s.linkAccount(ctx, linkUserID, provider.ID, userInfo, accessToken, refreshToken, ip, userAgent)
+		if err != nil {
+			return UserResponse{}, SessionInfo{}, err
+		}
+		s.rememberLogout(ctx, provider, session, tokens.IDToken)
+		return user, session, nil
 	}
 
 	// Login or auto-create flow
-	return s.loginOrCreateUser(ctx, provider.ID, userInfo, accessToken, refreshToken, ip, userAgent)
+	user, session, err := s.loginOrCreateUser(ctx, provider.ID, userInfo, accessToken, refreshToken, ip, userAgent)
+	if err != nil {
+		return UserResponse{}, SessionInfo{}, err
+	}
+	s.rememberLogout(ctx, provider, session, tokens.IDToken)
+
+	if s.webhookService != nil {
+		go s.webhookService.DispatchGlobal(context.Background(), TriggerUserOAuthLogin, map[string]string{
+			"user_id":  user.ID,
+			"username": user.Username,
+			"provider": provider.Name,
+		})
+	}
+
+	return user, session, nil
 }
 
 // ListConnections returns all OAuth connections for a user
@@ -376,13 +478,23 @@ type ConnectionResponse struct {
 // --- Internal helpers ---
 
 type oauthUserInfo struct {
-	ExternalID string
-	Email      string
-	Name       string
-	Username   string
+	ExternalID      string
+	Email           string
+	EmailUnverified bool // the provider says the email isn't verified
+	Name            string
+	Username        string
+}
+
+// oauthTokens is the token endpoint's answer to a code exchange.
+type oauthTokens struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	IDToken      string `json:"id_token"`
 }
 
-func (s *OAuthService) exchangeCode(provider repository.OauthProvider, providerName, code string) (string, string, error) {
+func (s *OAuthService) exchangeCode(ctx context.Context, provider repository.OauthProvider, providerName, code, codeVerifier string) (oauthTokens, error) {
 	redirectURI := fmt.Sprintf("%s/api/auth/oauth/%s/callback", s.appCfg.BaseURL, url.PathEscape(providerName))
 
 	data := url.Values{
@@ -392,49 +504,98 @@ func (s *OAuthService) exchangeCode(provider repository.OauthProvider, providerN
 		"client_id":     {provider.ClientID},
 		"client_secret": {provider.ClientSecret},
 	}
+	if codeVerifier != "" {
+		data.Set("code_verifier", codeVerifier)
+	}
 
-	resp, err := http.PostForm(provider.TokenUrl, data)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, provider.TokenUrl, strings.NewReader(data.Encode()))
 	if err != nil {
-		return "", "", fmt.Errorf("token request failed: %w", err)
+		return oauthTokens{}, fmt.Errorf("creating token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return oauthTokens{}, fmt.Errorf("token request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20)) // 1MB limit
 	if err != nil {
-		return "", "", fmt.Errorf("reading token response: %w", err)
+		return oauthTokens{}, fmt.Errorf("reading token response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		s.logger.Error("oauth token exchange failed", "status", resp.StatusCode, "body", string(body))
-		return "", "", model.NewDomainError(model.ErrUnauthorized, "failed to exchange authorization code")
+		return oauthTokens{}, model.NewDomainError(model.ErrUnauthorized, "failed to exchange authorization code")
 	}
 
-	var tokenResp struct {
-		AccessToken  string `json:"access_token"`
-		TokenType    string `json:"token_type"`
-		RefreshToken string `json:"refresh_token"`
-		ExpiresIn    int    `json:"expires_in"`
-	}
+	var tokenResp oauthTokens
 	if err := json.Unmarshal(body, &tokenResp); err != nil {
-		return "", "", fmt.Errorf("parsing token response: %w", err)
+		return oauthTokens{}, fmt.Errorf("parsing token response: %w", err)
 	}
 
 	if tokenResp.AccessToken == "" {
-		return "", "", model.NewDomainError(model.ErrUnauthorized, "no access token in response")
+		return oauthTokens{}, model.NewDomainError(model.ErrUnauthorized, "no access token in response")
 	}
 
-	return tokenResp.AccessToken, tokenResp.RefreshToken, nil
+	return tokenResp, nil
+}
+
+// identify works out who logged in. OIDC providers vouch for the user with
+// a signed ID token, completed from the userinfo endpoint if it has one;
+// plain OAuth2 providers only have the userinfo response.
+func (s *OAuthService) identify(ctx context.Context, provider repository.OauthProvider, tokens oauthTokens, nonce string) (oauthUserInfo, error) {
+	if provider.ProviderType != ProviderTypeOIDC {
+		info, err := s.fetchUserInfo(ctx, provider.UserinfoUrl, tokens.AccessToken)
+		if err != nil {
+			return oauthUserInfo{}, fmt.Errorf("fetching user info: %w", err)
+		}
+		return info, nil
+	}
+
+	if tokens.IDToken == "" {
+		return oauthUserInfo{}, model.NewDomainError(model.ErrUnauthorized, "no ID token in response")
+	}
+	claims, err := s.verifyIDToken(ctx, provider, tokens.IDToken, nonce)
+	if err != nil {
+		return oauthUserInfo{}, err
+	}
+	info := claims.userInfo()
+
+	if provider.UserinfoUrl != "" && (info.Email == "" || info.Name == "" || info.Username == "") {
+		extra, err := s.fetchUserInfo(ctx, provider.UserinfoUrl, tokens.AccessToken)
+		switch {
+		case err != nil:
+			s.logger.Warn("oidc userinfo request failed, using ID token claims only", "provider", provider.Name, "error", err)
+		case extra.ExternalID != info.ExternalID:
+			// Userinfo for another subject must not be mixed in
+			s.logger.Warn("oidc userinfo subject differs from ID token", "provider", provider.Name)
+		default:
+			if info.Email == "" && !claims.emailUnverified() && !extra.EmailUnverified {
+				info.Email = extra.Email
+			}
+			if info.Name == "" {
+				info.Name = extra.Name
+			}
+			if info.Username == "" {
+				info.Username = extra.Username
+			}
+		}
+	}
+	return info, nil
 }
 
-func (s *OAuthService) fetchUserInfo(userinfoURL, accessToken string) (oauthUserInfo, error) {
-	req, err := http.NewRequest("GET", userinfoURL, nil)
+func (s *OAuthService) fetchUserInfo(ctx context.Context, userinfoURL, accessToken string) (oauthUserInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", userinfoURL, nil)
 	if err != nil {
 		return oauthUserInfo{}, fmt.Errorf("creating userinfo request: %w", err)
 	}
 	req.Header.Set("Authorization", "Bearer "+accessToken)
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := s.httpClient.Do(req)
 	if err != nil {
 		return oauthUserInfo{}, fmt.Errorf("userinfo request failed: %w", err)
 	}
@@ -471,6 +632,9 @@ func (s *OAuthService) fetchUserInfo(userinfoURL, accessToken string) (oauthUser
 	if v, ok := raw["email"].(string); ok {
 		info.Email = v
 	}
+	if v, ok := raw["email_verified"]; ok && fmt.Sprint(v) == "false" {
+		info.EmailUnverified = true
+	}
 
 	// Display name: try "name", "display_name"
 	if v, ok := raw["name"].(string); ok {
@@ -626,6 +790,15 @@ func (s *OAuthService) loginOrCreateUser(
 	}
 
 	s.logger.Info("oauth user created", "user_id", user.ID, "username", username, "provider_id", providerID)
+
+	if s.webhookService != nil {
+		go s.webhookService.DispatchGlobal(context.Background(), "user.registered", map[string]string{
+			"username":  user.Username,
+			"full_name": user.FullName,
+			"role":      role,
+		})
+	}
+
 	return userToResponse(user), session, nil
 }
 
@@ -790,6 +963,69 @@ func cleanUsername(s string) string {
 	return result
 }
 
+// applyProviderType fills in the update of a provider whose type or issuer
+// changes. OIDC endpoints are discovered again whenever the issuer is sent;
+// switching to plain OAuth2 needs the endpoint URLs and clears the OIDC ones.
+func (s *OAuthService) applyProviderType(ctx context.Context, current repository.OauthProvider, input UpdateProviderInput, params *repository.UpdateOAuthProviderParams) error {
+	providerType := current.ProviderType
+	if input.ProviderType != nil {
+		providerType = *input.ProviderType
+	}
+
+	switch providerType {
+	case ProviderTypeOIDC:
+		// The endpoints of OIDC providers come from discovery only
+		params.AuthorizeUrl, params.TokenUrl, params.UserinfoUrl = nil, nil, nil
+		issuer := current.IssuerUrl
+		if input.IssuerURL != nil {
+			issuer = *input.IssuerURL
+		}
+		if input.IssuerURL == nil && providerType == current.ProviderType {
+			if input.Scopes != nil {
+				scopes := oidcScopes(*input.Scopes)
+				params.Scopes = &scopes
+			}
+			return nil
+		}
+		if issuer == "" {
+			return model.NewFieldError(model.ErrInvalidInput, "issuer_url", "issuer_url is required")
+		}
+		doc, err := s.discoverOIDC(ctx, issuer)
+		if err != nil {
+			return err
+		}
+		params.IssuerUrl = &doc.Issuer
+		params.AuthorizeUrl = &doc.AuthorizationEndpoint
+		params.TokenUrl = &doc.TokenEndpoint
+		params.UserinfoUrl = &doc.UserinfoEndpoint
+		params.JwksUrl = &doc.JwksURI
+		params.EndSessionUrl = &doc.EndSessionEndpoint
+		scopes := current.Scopes
+		if input.Scopes != nil {
+			scopes = *input.Scopes
+		}
+		scopes = oidcScopes(scopes)
+		params.Scopes = &scopes
+	case ProviderTypeOAuth2:
+		if current.ProviderType == ProviderTypeOAuth2 {
+			return nil
+		}
+		if input.AuthorizeURL == nil || *input.AuthorizeURL == "" ||
+			input.TokenURL == nil || *input.TokenURL == "" ||
+			input.UserinfoURL == nil || *input.UserinfoURL == "" {
+			return model.NewDomainError(model.ErrInvalidInput, "authorize_url, token_url and userinfo_url are required for OAuth2 providers")
+		}
+		empty, off := "", false
+		params.IssuerUrl = &empty
+		params.JwksUrl = &empty
+		params.EndSessionUrl = &empty
+		params.RpLogout = &off
+	default:
+		return model.NewFieldError(model.ErrInvalidInput, "provider_type", "provider_type must be oauth2 or oidc")
+	}
+	return nil
+}
+
 func validateProviderInput(input CreateProviderInput) error {
 	if input.Name == "" {
 		return model.NewFieldError(model.ErrInvalidInput, "name", "name is required")
@@ -800,6 +1036,16 @@ func validateProviderInput(input CreateProviderInput) error {
 	if input.ClientSecret == "" {
 		return model.NewFieldError(model.ErrInvalidInput, "client_secret", "client_secret is required")
 	}
+	switch input.ProviderType {
+	case ProviderTypeOIDC:
+		if input.IssuerURL == "" {
+			return model.NewFieldError(model.ErrInvalidInput, "issuer_url", "issuer_url is required")
+		}
+		return nil
+	case ProviderTypeOAuth2:
+	default:
+		return model.NewFieldError(model.ErrInvalidInput, "provider_type", "provider_type must be oauth2 or oidc")
+	}
 	if input.AuthorizeURL == "" {
 		return model.NewFieldError(model.ErrInvalidInput, "authorize_url", "authorize_url is required")
 	}
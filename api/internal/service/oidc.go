@@ -0,0 +1,506 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	_ "crypto/sha512" // registers SHA-384 and SHA-512 for crypto.Hash
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Provider types. Generic OAuth2 providers are configured with their
+// endpoint URLs and identify users by heuristics over the userinfo JSON;
+// OpenID Connect providers are configured from an issuer URL and identify
+// users by a verified ID token.
+const (
+	ProviderTypeOAuth2 = "oauth2"
+	ProviderTypeOIDC   = "oidc"
+)
+
+const (
+	// jwksCacheTTL is how long an issuer's signing keys are reused before
+	// they are fetched again. Unknown key IDs trigger an earlier refresh.
+	jwksCacheTTL = time.Hour
+	// jwksMinRefresh limits refreshes caused by unknown key IDs, so tokens
+	// with made-up key IDs can't make us hammer the provider.
+	jwksMinRefresh = time.Minute
+	// idTokenLeeway is the clock skew tolerated for exp and iat.
+	idTokenLeeway = time.Minute
+)
+
+// oidcDiscovery is the part of an issuer's discovery document we use.
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+}
+
+// discoverOIDC fetches the discovery document of an issuer. The issuer it
+// names must be the one asked for, as ID tokens are checked against it.
+func (s *OAuthService) discoverOIDC(ctx context.Context, issuer string) (oidcDiscovery, error) {
+	issuer = strings.TrimSuffix(issuer, "/")
+	u, err := url.Parse(issuer)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return oidcDiscovery{}, model.NewFieldError(model.ErrInvalidInput, "issuer_url", "issuer_url must be an http(s) URL")
+	}
+
+	var doc oidcDiscovery
+	if err := s.getJSON(ctx, issuer+"/.well-known/openid-configuration", &doc); err != nil {
+		s.logger.Warn("oidc discovery failed", "issuer", issuer, "error", err)
+		return oidcDiscovery{}, model.NewFieldError(model.ErrInvalidInput, "issuer_url", "could not load the issuer's OpenID configuration")
+	}
+
+	if strings.TrimSuffix(doc.Issuer, "/") != issuer {
+		return oidcDiscovery{}, model.NewFieldError(model.ErrInvalidInput, "issuer_url", "discovery document is for issuer "+doc.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JwksURI == "" {
+		return oidcDiscovery{}, model.NewFieldError(model.ErrInvalidInput, "issuer_url", "discovery document lacks authorization, token or JWKS endpoint")
+	}
+	return doc, nil
+}
+
+// getJSON fetches a JSON document through the outbound client.
+func (s *OAuthService) getJSON(ctx context.Context, rawURL string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return json.Unmarshal(body, v)
+}
+
+// --- PKCE and nonce ---
+
+// randomURLToken returns n random bytes, base64url encoded without padding.
+func randomURLToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// pkceChallenge derives the S256 code challenge for a PKCE code verifier.
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// oidcScopes makes sure the scopes asked for include openid, without which
+// the provider won't issue an ID token.
+func oidcScopes(scopes string) string {
+	fields := strings.Fields(scopes)
+	if len(fields) == 0 {
+		return "openid email profile"
+	}
+	if !slices.Contains(fields, "openid") {
+		fields = append([]string{"openid"}, fields...)
+	}
+	return strings.Join(fields, " ")
+}
+
+// --- JWKS ---
+
+type jwksCacheEntry struct {
+	keys      []jsonWebKey
+	fetchedAt time.Time
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+
+	key crypto.PublicKey
+}
+
+// signingKeys returns the keys of a JWKS URL, from the cache unless it is
+// stale or refresh is set and the last fetch is old enough.
+func (s *OAuthService) signingKeys(ctx context.Context, jwksURL string, refresh bool) ([]jsonWebKey, error) {
+	s.jwksMu.Lock()
+	entry, ok := s.jwks[jwksURL]
+	s.jwksMu.Unlock()
+
+	if ok {
+		age := time.Since(entry.fetchedAt)
+		if age < jwksCacheTTL && (!refresh || age < jwksMinRefresh) {
+			return entry.keys, nil
+		}
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := s.getJSON(ctx, jwksURL, &set); err != nil {
+		if ok {
+			// Keep using the keys we have while the provider is unreachable
+			s.logger.Warn("refreshing JWKS failed", "url", jwksURL, "error", err)
+			return entry.keys, nil
+		}
+		return nil, fmt.Errorf("fetching JWKS: %w", err)
+	}
+
+	keys := make([]jsonWebKey, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			// Unsupported key types are skipped, not fatal
+			continue
+		}
+		k.key = pub
+		keys = append(keys, k)
+	}
+
+	s.jwksMu.Lock()
+	s.jwks[jwksURL] = jwksCacheEntry{keys: keys, fetchedAt: time.Now()}
+	s.jwksMu.Unlock()
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, errors.New("EC point not on curve")
+		}
+		return pub, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// --- ID tokens ---
+
+// idTokenClaims are the ID token claims we check or use.
+type idTokenClaims struct {
+	Issuer            string          `json:"iss"`
+	Subject           string          `json:"sub"`
+	Audience          audience        `json:"aud"`
+	AuthorizedParty   string          `json:"azp"`
+	Expiry            int64           `json:"exp"`
+	IssuedAt          int64           `json:"iat"`
+	Nonce             string          `json:"nonce"`
+	Email             string          `json:"email"`
+	EmailVerified     json.RawMessage `json:"email_verified"`
+	Name              string          `json:"name"`
+	PreferredUsername string          `json:"preferred_username"`
+}
+
+// audience accepts the aud claim as a single string or an array.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// emailUnverified reports whether the provider says the email is not
+// verified. Some providers send the flag as a string.
+func (c idTokenClaims) emailUnverified() bool {
+	v := strings.Trim(string(c.EmailVerified), `"`)
+	return v == "false"
+}
+
+// userInfo turns verified claims into the identity used for login.
+func (c idTokenClaims) userInfo() oauthUserInfo {
+	info := oauthUserInfo{
+		ExternalID: c.Subject,
+		Name:       c.Name,
+		Username:   c.PreferredUsername,
+	}
+	// An email the provider hasn't verified must not be used to match an
+	// existing account
+	if !c.emailUnverified() {
+		info.Email = c.Email
+	}
+	return info
+}
+
+// verifyIDToken checks an ID token's signature against the provider's JWKS
+// and its issuer, audience, expiry and nonce, and returns its claims.
+func (s *OAuthService) verifyIDToken(ctx context.Context, provider repository.OauthProvider, rawToken, nonce string) (idTokenClaims, error) {
+	invalid := func(reason string) (idTokenClaims, error) {
+		s.logger.Warn("rejected ID token", "provider", provider.Name, "reason", reason)
+		return idTokenClaims{}, model.NewDomainError(model.ErrUnauthorized, "invalid ID token")
+	}
+
+	parts := strings.Split(rawToken, ".")
+	if len(parts) != 3 {
+		return invalid("malformed token")
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return invalid("malformed header")
+	}
+	payloadJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return invalid("malformed payload")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return invalid("malformed signature")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return invalid("malformed header")
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for attempt := 0; attempt < 2 && !verified; attempt++ {
+		// Refresh once if no key verifies, in case the provider rotated keys
+		keys, err := s.signingKeys(ctx, provider.JwksUrl, attempt > 0)
+		if err != nil {
+			return idTokenClaims{}, err
+		}
+		for _, k := range keys {
+			if header.Kid != "" && k.Kid != "" && k.Kid != header.Kid {
+				continue
+			}
+			if k.Alg != "" && k.Alg != header.Alg {
+				continue
+			}
+			if verifyJWS(header.Alg, k.key, signed, signature) == nil {
+				verified = true
+				break
+			}
+		}
+	}
+	if !verified {
+		return invalid("signature not verified (alg " + header.Alg + ")")
+	}
+
+	var claims idTokenClaims
+	if err := json.Unmarshal(payloadJSON, &claims); err != nil {
+		return invalid("malformed claims")
+	}
+
+	now := time.Now()
+	switch {
+	case claims.Issuer != provider.IssuerUrl:
+		return invalid("issuer mismatch")
+	case !slices.Contains(claims.Audience, provider.ClientID):
+		return invalid("audience mismatch")
+	case len(claims.Audience) > 1 && claims.AuthorizedParty != "" && claims.AuthorizedParty != provider.ClientID:
+		return invalid("authorized party mismatch")
+	case claims.Expiry == 0 || now.After(time.Unix(claims.Expiry, 0).Add(idTokenLeeway)):
+		return invalid("token expired")
+	case claims.IssuedAt != 0 && time.Unix(claims.IssuedAt, 0).After(now.Add(idTokenLeeway)):
+		return invalid("token issued in the future")
+	case nonce == "" || claims.Nonce == "":
+		// Without a nonce on both sides the token could be a replay
+		return invalid("missing nonce")
+	case subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1:
+		return invalid("nonce mismatch")
+	case claims.Subject == "":
+		return invalid("missing subject")
+	}
+	return claims, nil
+}
+
+// verifyJWS checks a JWS signature. Only asymmetric algorithms are accepted,
+// so "none" and HMAC tokens are refused.
+func verifyJWS(alg string, key crypto.PublicKey, signed, signature []byte) error {
+	if len(alg) != 5 {
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	var hash crypto.Hash
+	var curve elliptic.Curve // the curve ES* pins the key to
+	switch alg[2:] {
+	case "256":
+		hash, curve = crypto.SHA256, elliptic.P256()
+	case "384":
+		hash, curve = crypto.SHA384, elliptic.P384()
+	case "512":
+		hash, curve = crypto.SHA512, elliptic.P521()
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch alg[:2] {
+	case "RS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("key is not RSA")
+		}
+		return rsa.VerifyPKCS1v15(pub, hash, digest, signature)
+	case "PS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("key is not RSA")
+		}
+		return rsa.VerifyPSS(pub, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	case "ES":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New("key is not EC")
+		}
+		if pub.Curve != curve {
+			return fmt.Errorf("%s needs a %s key, got %s", alg, curve.Params().Name, pub.Curve.Params().Name)
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("bad signature length")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		sig := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, sig) {
+			return errors.New("signature mismatch")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+}
+
+// --- RP-initiated logout ---
+
+// oidcLogoutState is kept per session of an OIDC login with RP-initiated
+// logout, so logging out can end the session at the provider too.
+type oidcLogoutState struct {
+	ProviderID string `json:"provider_id"`
+	IDToken    string `json:"id_token"`
+}
+
+func oidcLogoutKey(sessionToken string) string {
+	return "oidc_logout:" + oauthHashToken(sessionToken)
+}
+
+// rememberLogout stores what RP-initiated logout needs for a new session.
+func (s *OAuthService) rememberLogout(ctx context.Context, provider repository.OauthProvider, session SessionInfo, idToken string) {
+	if !provider.RpLogout || provider.EndSessionUrl == "" || idToken == "" {
+		return
+	}
+	data, _ := json.Marshal(oidcLogoutState{ProviderID: provider.ID.String(), IDToken: idToken})
+	if err := s.rdb.Set(ctx, oidcLogoutKey(session.Token), string(data), time.Until(session.ExpiresAt)).Err(); err != nil {
+		s.logger.Error("failed to store OIDC logout state", "error", err, "provider_id", provider.ID)
+	}
+}
+
+// LogoutURL returns the provider's end-session URL for a session that
+// logged in through an OIDC provider with RP-initiated logout, or "" if
+// there is none. The stored state is consumed.
+func (s *OAuthService) LogoutURL(ctx context.Context, sessionToken string) string {
+	stateJSON, err := s.rdb.GetDel(ctx, oidcLogoutKey(sessionToken)).Result()
+	if err != nil {
+		return ""
+	}
+	var state oidcLogoutState
+	if err := json.Unmarshal([]byte(stateJSON), &state); err != nil {
+		return ""
+	}
+	providerID, err := uuid.Parse(state.ProviderID)
+	if err != nil {
+		return ""
+	}
+	provider, err := s.queries.GetOAuthProviderByID(ctx, providerID)
+	if err != nil || !provider.RpLogout || provider.EndSessionUrl == "" {
+		return ""
+	}
+
+	u, err := url.Parse(provider.EndSessionUrl)
+	if err != nil {
+		return ""
+	}
+	q := u.Query()
+	q.Set("id_token_hint", state.IDToken)
+	q.Set("client_id", provider.ClientID)
+	q.Set("post_logout_redirect_uri", s.appCfg.BaseURL+"/login")
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -0,0 +1,381 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/config"
+	"github.com/echtkpvl/rncasp/internal/outbound"
+	"github.com/echtkpvl/rncasp/internal/repository"
+)
+
+// mockOIDC is a minimal OpenID provider: discovery, JWKS, token and
+// userinfo endpoints, signing ID tokens with an RSA key.
+type mockOIDC struct {
+	srv *httptest.Server
+
+	mu        sync.Mutex
+	rsaKey    *rsa.PrivateKey
+	kid       string
+	ecKey     *ecdsa.PrivateKey
+	challenge string         // PKCE challenge the next code was issued for
+	claims    map[string]any // ID token claims returned for the code
+	userinfo  map[string]any
+	jwksHits  int
+}
+
+func newMockOIDC(t *testing.T) *mockOIDC {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockOIDC{rsaKey: rsaKey, kid: "key-1", ecKey: ecKey}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.srv.URL,
+			"authorization_endpoint": m.srv.URL + "/authorize",
+			"token_endpoint":         m.srv.URL + "/token",
+			"userinfo_endpoint":      m.srv.URL + "/userinfo",
+			"jwks_uri":               m.srv.URL + "/jwks",
+			"end_session_endpoint":   m.srv.URL + "/logout",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.jwksHits++
+		b64 := base64.RawURLEncoding.EncodeToString
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{
+			{
+				"kty": "RSA", "kid": m.kid, "use": "sig", "alg": "RS256",
+				"n": b64(m.rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(m.rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC", "kid": "ec-1", "use": "sig", "crv": "P-256",
+				"x": b64(m.ecKey.X.FillBytes(make([]byte, 32))), "y": b64(m.ecKey.Y.FillBytes(make([]byte, 32))),
+			},
+			{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"},
+		}})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if r.PostFormValue("code") != "good-code" || pkceChallenge(r.PostFormValue("code_verifier")) != m.challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "access-1",
+			"token_type":   "Bearer",
+			"id_token":     m.signRS256(m.claims),
+		})
+	})
+	mux.HandleFunc("GET /userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		json.NewEncoder(w).Encode(m.userinfo)
+	})
+	m.srv = httptest.NewServer(mux)
+	t.Cleanup(m.srv.Close)
+	return m
+}
+
+func (m *mockOIDC) sign(header, claims map[string]any, sign func(digest []byte) []byte) string {
+	h, _ := json.Marshal(header)
+	c, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	digest := sha256.Sum256([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign(digest[:]))
+}
+
+func (m *mockOIDC) signRS256(claims map[string]any) string {
+	return m.sign(map[string]any{"alg": "RS256", "kid": m.kid}, claims, func(digest []byte) []byte {
+		sig, _ := rsa.SignPKCS1v15(rand.Reader, m.rsaKey, crypto.SHA256, digest)
+		return sig
+	})
+}
+
+func (m *mockOIDC) signES256(claims map[string]any) string {
+	return m.sign(map[string]any{"alg": "ES256", "kid": "ec-1"}, claims, func(digest []byte) []byte {
+		r, s, _ := ecdsa.Sign(rand.Reader, m.ecKey, digest)
+		return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	})
+}
+
+func (m *mockOIDC) validClaims(nonce string) map[string]any {
+	now := time.Now()
+	return map[string]any{
+		"iss":                m.srv.URL,
+		"sub":                "user-42",
+		"aud":                "rncasp",
+		"exp":                now.Add(5 * time.Minute).Unix(),
+		"iat":                now.Unix(),
+		"nonce":              nonce,
+		"email":              "ada@example.org",
+		"email_verified":     true,
+		"preferred_username": "ada",
+	}
+}
+
+func newTestOAuthService(m *mockOIDC) *OAuthService {
+	s := NewOAuthService(nil, nil, &config.AppConfig{BaseURL: "https://rncasp.example.org"}, &config.AuthConfig{},
+		slog.New(slog.NewTextHandler(io.Discard, nil)), outbound.New(outbound.Config{}))
+	// The outbound policy refuses loopback, so talk to the mock directly
+	s.httpClient = m.srv.Client()
+	return s
+}
+
+func discoveredProvider(t *testing.T, s *OAuthService, m *mockOIDC) repository.OauthProvider {
+	t.Helper()
+	doc, err := s.discoverOIDC(context.Background(), m.srv.URL+"/")
+	if err != nil {
+		t.Fatalf("discovery: %v", err)
+	}
+	return repository.OauthProvider{
+		Name:          "mock",
+		ProviderType:  ProviderTypeOIDC,
+		ClientID:      "rncasp",
+		ClientSecret:  "secret",
+		IssuerUrl:     doc.Issuer,
+		AuthorizeUrl:  doc.AuthorizationEndpoint,
+		TokenUrl:      doc.TokenEndpoint,
+		UserinfoUrl:   doc.UserinfoEndpoint,
+		JwksUrl:       doc.JwksURI,
+		EndSessionUrl: doc.EndSessionEndpoint,
+	}
+}
+
+func TestPKCEChallenge(t *testing.T) {
+	// Example from RFC 7636, appendix B
+	got := pkceChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got != want {
+		t.Errorf("challenge = %q, want %q", got, want)
+	}
+}
+
+func TestOIDCScopes(t *testing.T) {
+	tests := map[string]string{
+		"":                     "openid email profile",
+		"email profile":        "openid email profile",
+		"profile openid email": "profile openid email",
+	}
+	for in, want := range tests {
+		if got := oidcScopes(in); got != want {
+			t.Errorf("oidcScopes(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestOIDCDiscovery(t *testing.T) {
+	m := newMockOIDC(t)
+	s := newTestOAuthService(m)
+
+	p := discoveredProvider(t, s, m)
+	if p.IssuerUrl != m.srv.URL || p.TokenUrl != m.srv.URL+"/token" || p.EndSessionUrl != m.srv.URL+"/logout" {
+		t.Errorf("unexpected endpoints: %+v", p)
+	}
+
+	// A document naming another issuer is refused
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.srv.URL,
+			"authorization_endpoint": m.srv.URL + "/authorize",
+			"token_endpoint":         m.srv.URL + "/token",
+			"jwks_uri":               m.srv.URL + "/jwks",
+		})
+	}))
+	defer other.Close()
+	if _, err := s.discoverOIDC(context.Background(), other.URL); err == nil {
+		t.Error("discovery document for another issuer accepted")
+	}
+}
+
+func TestOIDCCodeFlow(t *testing.T) {
+	m := newMockOIDC(t)
+	s := newTestOAuthService(m)
+	p := discoveredProvider(t, s, m)
+	ctx := context.Background()
+
+	verifier, _ := randomURLToken(32)
+	m.challenge = pkceChallenge(verifier)
+	m.claims = m.validClaims("nonce-1")
+	delete(m.claims, "email")
+	m.userinfo = map[string]any{"sub": "user-42", "email": "ada@example.org", "name": "Ada Lovelace"}
+
+	// The code is only redeemed with the verifier it was issued for
+	if _, err := s.exchangeCode(ctx, p, p.Name, "good-code", "wrong-verifier"); err == nil {
+		t.Error("code redeemed with the wrong PKCE verifier")
+	}
+	tokens, err := s.exchangeCode(ctx, p, p.Name, "good-code", verifier)
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+
+	info, err := s.identify(ctx, p, tokens, "nonce-1")
+	if err != nil {
+		t.Fatalf("identify: %v", err)
+	}
+	want := oauthUserInfo{ExternalID: "user-42", Email: "ada@example.org", Name: "Ada Lovelace", Username: "ada"}
+	if info != want {
+		t.Errorf("info = %+v, want %+v", info, want)
+	}
+
+	// Userinfo for another subject is not mixed in
+	m.userinfo["sub"] = "someone-else"
+	info, err = s.identify(ctx, p, tokens, "nonce-1")
+	if err != nil {
+		t.Fatalf("identify: %v", err)
+	}
+	if info.Email != "" || info.Name != "" {
+		t.Errorf("userinfo of another subject used: %+v", info)
+	}
+
+	// A wrong nonce fails the whole login
+	if _, err := s.identify(ctx, p, tokens, "nonce-2"); err == nil {
+		t.Error("ID token with wrong nonce accepted")
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	m := newMockOIDC(t)
+	s := newTestOAuthService(m)
+	p := discoveredProvider(t, s, m)
+	ctx := context.Background()
+
+	with := func(key string, value any) map[string]any {
+		c := m.validClaims("n")
+		if value == nil {
+			delete(c, key)
+		} else {
+			c[key] = value
+		}
+		return c
+	}
+
+	if _, err := s.verifyIDToken(ctx, p, m.signRS256(m.validClaims("n")), "n"); err != nil {
+		t.Errorf("valid RS256 token rejected: %v", err)
+	}
+	if _, err := s.verifyIDToken(ctx, p, m.signES256(m.validClaims("n")), "n"); err != nil {
+		t.Errorf("valid ES256 token rejected: %v", err)
+	}
+	if _, err := s.verifyIDToken(ctx, p, m.signRS256(with("aud", []string{"other", "rncasp"})), "n"); err != nil {
+		t.Errorf("token with several audiences rejected: %v", err)
+	}
+
+	valid := m.signRS256(m.validClaims("n"))
+	parts := strings.Split(valid, ".")
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."
+	tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin"}`)) + "." + parts[2]
+	hmac := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","kid":"hmac"}`)) + "." + parts[1] + "." + parts[2]
+
+	foreignAzp := with("aud", []string{"rncasp", "other"})
+	foreignAzp["azp"] = "other"
+
+	// A token signed by a key the provider never published
+	published := m.rsaKey
+	m.rsaKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+	unknownKey := m.signRS256(m.validClaims("n"))
+	m.rsaKey = published
+
+	// ES384 over the P-256 key: the signature has the right length and
+	// ecdsa.Verify truncates the digest, so only the curve check stops it
+	es384 := func() string {
+		h, _ := json.Marshal(map[string]any{"alg": "ES384", "kid": "ec-1"})
+		c, _ := json.Marshal(m.validClaims("n"))
+		signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+		digest := sha512.Sum384([]byte(signed))
+		r, sig, _ := ecdsa.Sign(rand.Reader, m.ecKey, digest[:])
+		return signed + "." + base64.RawURLEncoding.EncodeToString(append(r.FillBytes(make([]byte, 32)), sig.FillBytes(make([]byte, 32))...))
+	}()
+
+	rejected := map[string]string{
+		"wrong issuer":     m.signRS256(with("iss", "https://evil.example.org")),
+		"wrong audience":   m.signRS256(with("aud", "other")),
+		"foreign azp":      m.signRS256(foreignAzp),
+		"expired":          m.signRS256(with("exp", time.Now().Add(-time.Hour).Unix())),
+		"no expiry":        m.signRS256(with("exp", nil)),
+		"issued in future": m.signRS256(with("iat", time.Now().Add(time.Hour).Unix())),
+		"missing nonce":    m.signRS256(with("nonce", nil)),
+		"missing subject":  m.signRS256(with("sub", nil)),
+		"alg none":         unsigned,
+		"tampered payload": tampered,
+		"garbage":          "not-a-token",
+		"HMAC algorithm":   hmac,
+		"unknown key":      unknownKey,
+		"ES384 on P-256":   es384,
+	}
+
+	for name, token := range rejected {
+		if _, err := s.verifyIDToken(ctx, p, token, "n"); err == nil {
+			t.Errorf("%s: token accepted", name)
+		}
+	}
+
+	// A flow state without a nonce must not accept tokens without one
+	if _, err := s.verifyIDToken(ctx, p, m.signRS256(with("nonce", nil)), ""); err == nil {
+		t.Error("token without nonce accepted for state without nonce")
+	}
+}
+
+func TestVerifyIDTokenKeyRotation(t *testing.T) {
+	m := newMockOIDC(t)
+	s := newTestOAuthService(m)
+	p := discoveredProvider(t, s, m)
+	ctx := context.Background()
+
+	if _, err := s.verifyIDToken(ctx, p, m.signRS256(m.validClaims("n")), "n"); err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+
+	// The provider rotates its key; the cached JWKS is refreshed once the
+	// minimum refresh interval has passed
+	newKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	m.mu.Lock()
+	m.rsaKey, m.kid = newKey, "key-2"
+	m.mu.Unlock()
+	token := m.signRS256(m.validClaims("n"))
+
+	if _, err := s.verifyIDToken(ctx, p, token, "n"); err == nil {
+		t.Error("token verified without refreshing keys")
+	}
+	s.jwksMu.Lock()
+	entry := s.jwks[p.JwksUrl]
+	entry.fetchedAt = time.Now().Add(-2 * jwksMinRefresh)
+	s.jwks[p.JwksUrl] = entry
+	s.jwksMu.Unlock()
+
+	if _, err := s.verifyIDToken(ctx, p, token, "n"); err != nil {
+		t.Errorf("token with rotated key rejected: %v", err)
+	}
+	if m.jwksHits != 2 {
+		t.Errorf("JWKS fetched %d times, want 2", m.jwksHits)
+	}
+}
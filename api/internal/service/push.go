@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/outbound"
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/echtkpvl/rncasp/internal/webpush"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	pushTTL             = 24 * time.Hour // how long push services keep messages for offline devices
+	pushRequestTimeout  = 10 * time.Second
+	pushMaxResponseBody = 64 << 10
+	maxPushUserAgent    = 255
+)
+
+// PushService delivers notifications to browsers and phones over Web Push.
+// The server's VAPID key pair is generated on first start and stored in the
+// database, so all instances share it.
+type PushService struct {
+	queries *repository.Queries
+	logger  *slog.Logger
+	client  *http.Client
+	subject string
+
+	mu   sync.RWMutex
+	keys webpush.Keys
+}
+
+// NewPushService creates a push service. Messages are sent through the
+// outbound policy, as subscription endpoints come from browsers. subject is
+// the contact given to push services, a mailto: or https: URL.
+func NewPushService(queries *repository.Queries, logger *slog.Logger, policy *outbound.Policy, subject string) *PushService {
+	return &PushService{
+		queries: queries,
+		logger:  logger,
+		client:  policy.Client(pushRequestTimeout, pushMaxResponseBody),
+		subject: subject,
+	}
+}
+
+type PushSubscriptionResponse struct {
+	ID         string  `json:"id"`
+	Endpoint   string  `json:"endpoint"`
+	UserAgent  *string `json:"user_agent"`
+	ExpiresAt  *string `json:"expires_at"`
+	LastUsedAt *string `json:"last_used_at"`
+	CreatedAt  string  `json:"created_at"`
+}
+
+// SubscribePushInput is a browser PushSubscription. ExpirationTime is in
+// milliseconds since the epoch, as reported by the browser.
+type SubscribePushInput struct {
+	Endpoint       string
+	P256dh         string
+	Auth           string
+	ExpirationTime *int64
+	UserAgent      string
+}
+
+// pushPayload is the JSON message the service worker receives.
+type pushPayload struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url,omitempty"`
+	Tag   string `json:"tag,omitempty"`
+}
+
+func pushSubscriptionToResponse(p repository.PushSubscription) PushSubscriptionResponse {
+	resp := PushSubscriptionResponse{
+		ID:        p.ID.String(),
+		Endpoint:  p.Endpoint,
+		UserAgent: p.UserAgent,
+		CreatedAt: p.CreatedAt.Format(time.RFC3339),
+	}
+	if p.ExpiresAt != nil {
+		s := p.ExpiresAt.Format(time.RFC3339)
+		resp.ExpiresAt = &s
+	}
+	if p.LastUsedAt != nil {
+		s := p.LastUsedAt.Format(time.RFC3339)
+		resp.LastUsedAt = &s
+	}
+	return resp
+}
+
+// LoadKeys loads the VAPID key pair, generating it if there is none yet.
+func (s *PushService) LoadKeys(ctx context.Context) error {
+	stored, err := s.queries.GetVAPIDKeys(ctx)
+	if errors.Is(err, pgx.ErrNoRows) {
+		keys, genErr := webpush.GenerateKeys()
+		if genErr != nil {
+			return genErr
+		}
+		// Another instance may have stored its keys first; reading back
+		// makes everyone use the same pair.
+		if err := s.queries.InsertVAPIDKeys(ctx, keys.PublicKey, keys.PrivateKey); err != nil {
+			return fmt.Errorf("storing VAPID keys: %w", err)
+		}
+		stored, err = s.queries.GetVAPIDKeys(ctx)
+		if err == nil {
+			s.logger.Info("generated VAPID keys for web push")
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("loading VAPID keys: %w", err)
+	}
+
+	s.mu.Lock()
+	s.keys = webpush.Keys{PublicKey: stored.PublicKey, PrivateKey: stored.PrivateKey}
+	s.mu.Unlock()
+	return nil
+}
+
+// PublicKey returns the VAPID public key browsers subscribe with.
+func (s *PushService) PublicKey() (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.keys.PublicKey == "" {
+		return "", model.NewDomainError(model.ErrNotFound, "push notifications are not available")
+	}
+	return s.keys.PublicKey, nil
+}
+
+func (s *PushService) ListSubscriptions(ctx context.Context, userID uuid.UUID) ([]PushSubscriptionResponse, error) {
+	subs, err := s.queries.ListPushSubscriptionsByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("listing push subscriptions: %w", err)
+	}
+
+	result := make([]PushSubscriptionResponse, len(subs))
+	for i, sub := range subs {
+		result[i] = pushSubscriptionToResponse(sub)
+	}
+	return result, nil
+}
+
+// Subscribe registers a device. Subscribing an endpoint again updates its
+// keys, and moves it to the current user if someone else had registered it.
+func (s *PushService) Subscribe(ctx context.Context, userID uuid.UUID, input SubscribePushInput) (PushSubscriptionResponse, error) {
+	if _, err := s.PublicKey(); err != nil {
+		return PushSubscriptionResponse{}, err
+	}
+
+	u, err := url.Parse(input.Endpoint)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return PushSubscriptionResponse{}, model.NewFieldError(model.ErrInvalidInput, "endpoint", "must be an https URL")
+	}
+	if !validPushKey(input.P256dh, 65) {
+		return PushSubscriptionResponse{}, model.NewFieldError(model.ErrInvalidInput, "keys.p256dh", "must be a base64url-encoded P-256 public key")
+	}
+	if !validPushKey(input.Auth, 16) {
+		return PushSubscriptionResponse{}, model.NewFieldError(model.ErrInvalidInput, "keys.auth", "must be a base64url-encoded 16-byte secret")
+	}
+
+	var expiresAt *time.Time
+	if input.ExpirationTime != nil {
+		t := time.UnixMilli(*input.ExpirationTime)
+		expiresAt = &t
+	}
+	var userAgent *string
+	if ua := strings.TrimSpace(input.UserAgent); ua != "" {
+		ua = truncateRunes(ua, maxPushUserAgent)
+		userAgent = &ua
+	}
+
+	sub, err := s.queries.UpsertPushSubscription(ctx, repository.UpsertPushSubscriptionParams{
+		UserID:    userID,
+		Endpoint:  input.Endpoint,
+		P256dh:    input.P256dh,
+		Auth:      input.Auth,
+		UserAgent: userAgent,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return PushSubscriptionResponse{}, fmt.Errorf("saving push subscription: %w", err)
+	}
+	return pushSubscriptionToResponse(sub), nil
+}
+
+func (s *PushService) Unsubscribe(ctx context.Context, userID, id uuid.UUID) error {
+	n, err := s.queries.DeletePushSubscription(ctx, id, userID)
+	if err != nil {
+		return fmt.Errorf("deleting push subscription: %w", err)
+	}
+	if n == 0 {
+		return model.NewDomainError(model.ErrNotFound, "push subscription not found")
+	}
+	return nil
+}
+
+// Notify sends a notification to all of the user's devices. Subscriptions
+// the push service reports as gone are deleted.
+func (s *PushService) Notify(ctx context.Context, userID uuid.UUID, payload pushPayload, topic string) {
+	s.mu.RLock()
+	keys := s.keys
+	s.mu.RUnlock()
+	if keys.PublicKey == "" {
+		return
+	}
+
+	subs, err := s.queries.ListPushSubscriptionsByUser(ctx, userID)
+	if err != nil {
+		s.logger.Error("failed to list push subscriptions", "error", err, "user_id", userID)
+		return
+	}
+	if len(subs) == 0 {
+		return
+	}
+
+	data, err := encodePushPayload(payload)
+	if err != nil {
+		s.logger.Error("failed to encode push message", "error", err)
+		return
+	}
+
+	delivered, gone := s.send(ctx, keys, subs, data, topic)
+	for _, id := range gone {
+		if err := s.queries.DeletePushSubscriptionByID(ctx, id); err != nil {
+			s.logger.Error("failed to delete push subscription", "error", err, "subscription_id", id)
+		}
+	}
+	for _, id := range delivered {
+		if err := s.queries.MarkPushSubscriptionUsed(ctx, id); err != nil {
+			s.logger.Error("failed to update push subscription", "error", err, "subscription_id", id)
+		}
+	}
+}
+
+// send posts data to each subscription and returns the IDs it was delivered
+// to and those that are gone.
+func (s *PushService) send(ctx context.Context, keys webpush.Keys, subs []repository.PushSubscription, data []byte, topic string) (delivered, gone []uuid.UUID) {
+	opts := webpush.Options{Subject: s.subject, TTL: int(pushTTL.Seconds()), Topic: topic}
+	for _, sub := range subs {
+		err := webpush.Send(ctx, s.client, keys, webpush.Subscription{
+			Endpoint: sub.Endpoint,
+			P256dh:   sub.P256dh,
+			Auth:     sub.Auth,
+		}, data, opts)
+		switch {
+		case err == nil:
+			delivered = append(delivered, sub.ID)
+		case errors.Is(err, webpush.ErrGone):
+			s.logger.Info("push subscription expired", "subscription_id", sub.ID, "user_id", sub.UserID)
+			gone = append(gone, sub.ID)
+		default:
+			s.logger.Warn("push delivery failed", "error", err, "subscription_id", sub.ID)
+		}
+	}
+	return delivered, gone
+}
+
+// encodePushPayload marshals payload, shortening the body until it fits in a
+// push message.
+func encodePushPayload(payload pushPayload) ([]byte, error) {
+	for {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) <= webpush.MaxPayload || payload.Body == "" {
+			return data, nil
+		}
+		n := utf8.RuneCountInString(payload.Body)
+		payload.Body = truncateRunes(payload.Body, n-(len(data)-webpush.MaxPayload)-1) + "…"
+	}
+}
+
+// notificationPush builds the push message for a rendered notification. The
+// topic lets push services replace an undelivered message about the same
+// shift.
+func notificationPush(title, body, baseURL string, msg NotificationMessage) (pushPayload, string) {
+	payload := pushPayload{Title: title, Body: body, Tag: msg.TriggerType}
+	if baseURL = strings.TrimRight(baseURL, "/"); baseURL != "" && msg.EventSlug != "" {
+		payload.URL = baseURL + "/events/" + msg.EventSlug
+	}
+
+	var topic string
+	if msg.ShiftID != uuid.Nil {
+		topic = strings.ReplaceAll(msg.ShiftID.String(), "-", "")
+		payload.Tag = "shift-" + msg.ShiftID.String()
+	}
+	return payload, topic
+}
+
+// validPushKey reports whether s is base64url, with or without padding, for
+// a key of size bytes.
+func validPushKey(s string, size int) bool {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	return err == nil && len(b) == size
+}
+
+func truncateRunes(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+		n--
+	}
+	return s
+}
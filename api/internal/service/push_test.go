@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/echtkpvl/rncasp/internal/outbound"
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/echtkpvl/rncasp/internal/webpush"
+	"github.com/google/uuid"
+)
+
+func TestPushServiceSend(t *testing.T) {
+	keys, err := webpush.GenerateKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A local stand-in for a push service: one device is registered, the
+	// other has unsubscribed.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		if r.Header.Get("Topic") != "abc" {
+			t.Errorf("Topic = %q", r.Header.Get("Topic"))
+		}
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	newSub := func(path string) repository.PushSubscription {
+		priv, err := ecdh.P256().GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		auth := make([]byte, 16)
+		rand.Read(auth)
+		return repository.PushSubscription{
+			ID:       uuid.New(),
+			Endpoint: srv.URL + path,
+			P256dh:   base64.RawURLEncoding.EncodeToString(priv.PublicKey().Bytes()),
+			Auth:     base64.RawURLEncoding.EncodeToString(auth),
+		}
+	}
+	active, gone := newSub("/active"), newSub("/gone")
+
+	s := NewPushService(nil, slog.New(slog.NewTextHandler(io.Discard, nil)), outbound.New(outbound.Config{}), "mailto:ops@example.org")
+	s.client = srv.Client()
+
+	delivered, expired := s.send(context.Background(), keys, []repository.PushSubscription{active, gone}, []byte(`{}`), "abc")
+	if len(delivered) != 1 || delivered[0] != active.ID {
+		t.Errorf("delivered = %v, want [%s]", delivered, active.ID)
+	}
+	if len(expired) != 1 || expired[0] != gone.ID {
+		t.Errorf("gone = %v, want [%s]", expired, gone.ID)
+	}
+}
+
+func TestEncodePushPayload(t *testing.T) {
+	data, err := encodePushPayload(pushPayload{Title: "Camp: Update", Body: strings.Repeat("ä", 4000)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) > webpush.MaxPayload {
+		t.Fatalf("payload is %d bytes, limit %d", len(data), webpush.MaxPayload)
+	}
+	var p pushPayload
+	if err := json.Unmarshal(data, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Title != "Camp: Update" || !strings.HasSuffix(p.Body, "…") {
+		t.Errorf("unexpected payload %+v", p)
+	}
+}
+
+func TestNotificationPush(t *testing.T) {
+	shiftID := uuid.MustParse("0f8fad5b-d9cb-469f-a165-70867728950e")
+	payload, topic := notificationPush("Camp: Your shift was moved", "body", "https://plan.example.org/",
+		NotificationMessage{TriggerType: TriggerShiftTimeChanged, EventSlug: "camp", ShiftID: shiftID})
+
+	if payload.URL != "https://plan.example.org/events/camp" {
+		t.Errorf("URL = %q", payload.URL)
+	}
+	if topic != "0f8fad5bd9cb469fa16570867728950e" {
+		t.Errorf("topic = %q", topic)
+	}
+	if payload.Tag != "shift-"+shiftID.String() {
+		t.Errorf("tag = %q", payload.Tag)
+	}
+
+	payload, topic = notificationPush("Camp: Locked", "", "", NotificationMessage{TriggerType: TriggerEventLocked, EventSlug: "camp"})
+	if topic != "" || payload.URL != "" || payload.Tag != TriggerEventLocked {
+		t.Errorf("event trigger: payload %+v, topic %q", payload, topic)
+	}
+}
+
+func TestPushDefaults(t *testing.T) {
+	if !channelEnabled(nil, TriggerShiftAssignedToYou, ChannelPush) {
+		t.Error("push should default to enabled for personal triggers")
+	}
+	if channelEnabled(nil, TriggerAdminShiftCreated, ChannelPush) {
+		t.Error("push should default to disabled for admin triggers")
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// ReportSchedulerService sends scheduled reports when they become due.
+type ReportSchedulerService struct {
+	reportService *ReportService
+	logger        *slog.Logger
+	stopCh        chan struct{}
+}
+
+func NewReportSchedulerService(reportService *ReportService, logger *slog.Logger) *ReportSchedulerService {
+	return &ReportSchedulerService{
+		reportService: reportService,
+		logger:        logger,
+		stopCh:        make(chan struct{}),
+	}
+}
+
+func (s *ReportSchedulerService) Start(ctx context.Context) {
+	// Send times have minute resolution, so poll once a minute
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			sent, err := s.reportService.RunDue(ctx)
+			if err != nil {
+				s.logger.Error("scheduled reports failed", "error", err)
+			} else if sent > 0 {
+				s.logger.Info("scheduled reports sent", "reports", sent)
+			}
+		}
+	}
+}
+
+func (s *ReportSchedulerService) Stop() {
+	close(s.stopCh)
+}
@@ -0,0 +1,668 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/pdf"
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ReportService manages scheduled reports: per-event subscriptions that
+// export the shift plan on a schedule and mail it to a list of recipients.
+type ReportService struct {
+	queries       *repository.Queries
+	logger        *slog.Logger
+	exportService *ExportService
+	exportJobs    *ExportJobService
+	smtpService   *SMTPService
+}
+
+func NewReportService(queries *repository.Queries, logger *slog.Logger, exportService *ExportService, exportJobs *ExportJobService, smtpService *SMTPService) *ReportService {
+	return &ReportService{
+		queries:       queries,
+		logger:        logger,
+		exportService: exportService,
+		exportJobs:    exportJobs,
+		smtpService:   smtpService,
+	}
+}
+
+const maxReportRecipients = 50
+
+var validReportFormats = map[string]bool{"csv": true, "pdf": true, "ical": true}
+
+// Report time windows, relative to the moment the report is sent.
+// "next_day" is the following calendar day in the event's time zone.
+var validReportWindows = map[string]bool{"all": true, "next_24h": true, "next_day": true, "next_7d": true}
+
+type ReportSubscriptionResponse struct {
+	ID               string   `json:"id"`
+	EventID          string   `json:"event_id"`
+	Name             string   `json:"name"`
+	Format           string   `json:"format"`
+	PDFLayout        string   `json:"pdf_layout"`
+	TeamIDs          []string `json:"team_ids"`
+	TimeWindow       string   `json:"time_window"`
+	RecipientUserIDs []string `json:"recipient_user_ids"`
+	RecipientEmails  []string `json:"recipient_emails"`
+	Frequency        string   `json:"frequency"`
+	Weekday          *int16   `json:"weekday"`
+	SendTime         string   `json:"send_time"`
+	IsEnabled        bool     `json:"is_enabled"`
+	NextRunAt        string   `json:"next_run_at"`
+	LastRunAt        *string  `json:"last_run_at"`
+	LastError        *string  `json:"last_error"`
+	CreatedAt        string   `json:"created_at"`
+}
+
+type CreateReportSubscriptionInput struct {
+	Name             string
+	Format           string
+	PDFLayout        string
+	TeamIDs          []string
+	TimeWindow       string
+	RecipientUserIDs []string
+	RecipientEmails  []string
+	Frequency        string
+	Weekday          *int16
+	SendTime         string
+}
+
+type UpdateReportSubscriptionInput struct {
+	Name             *string
+	Format           *string
+	PDFLayout        *string
+	TeamIDs          *[]string
+	TimeWindow       *string
+	RecipientUserIDs *[]string
+	RecipientEmails  *[]string
+	Frequency        *string
+	Weekday          *int16
+	SendTime         *string
+	IsEnabled        *bool
+}
+
+// reportSettings holds the user-editable fields of a subscription after
+// validation.
+type reportSettings struct {
+	name             string
+	format           string
+	pdfLayout        string
+	teamIDs          []uuid.UUID
+	timeWindow       string
+	recipientUserIDs []uuid.UUID
+	recipientEmails  []string
+	frequency        string
+	weekday          *int16
+	sendTime         string
+}
+
+func reportSubscriptionToResponse(r repository.ReportSubscription) ReportSubscriptionResponse {
+	resp := ReportSubscriptionResponse{
+		ID:               r.ID.String(),
+		EventID:          r.EventID.String(),
+		Name:             r.Name,
+		Format:           r.Format,
+		PDFLayout:        r.PdfLayout,
+		TeamIDs:          uuidStrings(r.TeamIds),
+		TimeWindow:       r.TimeWindow,
+		RecipientUserIDs: uuidStrings(r.RecipientUserIds),
+		RecipientEmails:  r.RecipientEmails,
+		Frequency:        r.Frequency,
+		Weekday:          r.Weekday,
+		SendTime:         r.SendTime,
+		IsEnabled:        r.IsEnabled,
+		NextRunAt:        r.NextRunAt.Format(time.RFC3339),
+		LastError:        r.LastError,
+		CreatedAt:        r.CreatedAt.Format(time.RFC3339),
+	}
+	if resp.RecipientEmails == nil {
+		resp.RecipientEmails = []string{}
+	}
+	if r.LastRunAt != nil {
+		s := r.LastRunAt.Format(time.RFC3339)
+		resp.LastRunAt = &s
+	}
+	return resp
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	result := make([]string, len(ids))
+	for i, id := range ids {
+		result[i] = id.String()
+	}
+	return result
+}
+
+func (s *ReportService) ListByEvent(ctx context.Context, slug string) ([]ReportSubscriptionResponse, error) {
+	event, err := s.getEvent(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	subs, err := s.queries.ListReportSubscriptionsByEvent(ctx, event.ID)
+	if err != nil {
+		return nil, fmt.Errorf("listing report subscriptions: %w", err)
+	}
+
+	result := make([]ReportSubscriptionResponse, len(subs))
+	for i, sub := range subs {
+		result[i] = reportSubscriptionToResponse(sub)
+	}
+	return result, nil
+}
+
+func (s *ReportService) Create(ctx context.Context, slug string, createdBy *uuid.UUID, input CreateReportSubscriptionInput) (ReportSubscriptionResponse, error) {
+	event, err := s.getEvent(ctx, slug)
+	if err != nil {
+		return ReportSubscriptionResponse{}, err
+	}
+
+	if input.PDFLayout == "" {
+		input.PDFLayout = "grid"
+	}
+	if input.TimeWindow == "" {
+		input.TimeWindow = "all"
+	}
+	settings, err := s.validate(ctx, event, input)
+	if err != nil {
+		return ReportSubscriptionResponse{}, err
+	}
+
+	sub, err := s.queries.CreateReportSubscription(ctx, repository.CreateReportSubscriptionParams{
+		EventID:          event.ID,
+		Name:             settings.name,
+		Format:           settings.format,
+		PdfLayout:        settings.pdfLayout,
+		TeamIds:          settings.teamIDs,
+		TimeWindow:       settings.timeWindow,
+		RecipientUserIds: settings.recipientUserIDs,
+		RecipientEmails:  settings.recipientEmails,
+		Frequency:        settings.frequency,
+		Weekday:          settings.weekday,
+		SendTime:         settings.sendTime,
+		IsEnabled:        true,
+		NextRunAt:        settings.nextRun(time.Now(), eventLocation(event)),
+		CreatedBy:        createdBy,
+	})
+	if err != nil {
+		return ReportSubscriptionResponse{}, fmt.Errorf("creating report subscription: %w", err)
+	}
+
+	s.logger.Info("report subscription created", "report_id", sub.ID, "event_id", event.ID)
+	return reportSubscriptionToResponse(sub), nil
+}
+
+func (s *ReportService) Update(ctx context.Context, slug string, id uuid.UUID, input UpdateReportSubscriptionInput) (ReportSubscriptionResponse, error) {
+	event, sub, err := s.getSubscription(ctx, slug, id)
+	if err != nil {
+		return ReportSubscriptionResponse{}, err
+	}
+
+	merged := CreateReportSubscriptionInput{
+		Name:             sub.Name,
+		Format:           sub.Format,
+		PDFLayout:        sub.PdfLayout,
+		TeamIDs:          uuidStrings(sub.TeamIds),
+		TimeWindow:       sub.TimeWindow,
+		RecipientUserIDs: uuidStrings(sub.RecipientUserIds),
+		RecipientEmails:  sub.RecipientEmails,
+		Frequency:        sub.Frequency,
+		Weekday:          sub.Weekday,
+		SendTime:         sub.SendTime,
+	}
+	if input.Name != nil {
+		merged.Name = *input.Name
+	}
+	if input.Format != nil {
+		merged.Format = *input.Format
+	}
+	if input.PDFLayout != nil {
+		merged.PDFLayout = *input.PDFLayout
+	}
+	if input.TeamIDs != nil {
+		merged.TeamIDs = *input.TeamIDs
+	}
+	if input.TimeWindow != nil {
+		merged.TimeWindow = *input.TimeWindow
+	}
+	if input.RecipientUserIDs != nil {
+		merged.RecipientUserIDs = *input.RecipientUserIDs
+	}
+	if input.RecipientEmails != nil {
+		merged.RecipientEmails = *input.RecipientEmails
+	}
+	if input.Frequency != nil {
+		merged.Frequency = *input.Frequency
+	}
+	if input.Weekday != nil {
+		merged.Weekday = input.Weekday
+	}
+	if input.SendTime != nil {
+		merged.SendTime = *input.SendTime
+	}
+	settings, err := s.validate(ctx, event, merged)
+	if err != nil {
+		return ReportSubscriptionResponse{}, err
+	}
+
+	isEnabled := sub.IsEnabled
+	if input.IsEnabled != nil {
+		isEnabled = *input.IsEnabled
+	}
+
+	updated, err := s.queries.UpdateReportSubscription(ctx, repository.UpdateReportSubscriptionParams{
+		ID:               sub.ID,
+		Name:             settings.name,
+		Format:           settings.format,
+		PdfLayout:        settings.pdfLayout,
+		TeamIds:          settings.teamIDs,
+		TimeWindow:       settings.timeWindow,
+		RecipientUserIds: settings.recipientUserIDs,
+		RecipientEmails:  settings.recipientEmails,
+		Frequency:        settings.frequency,
+		Weekday:          settings.weekday,
+		SendTime:         settings.sendTime,
+		IsEnabled:        isEnabled,
+		NextRunAt:        settings.nextRun(time.Now(), eventLocation(event)),
+	})
+	if err != nil {
+		return ReportSubscriptionResponse{}, fmt.Errorf("updating report subscription: %w", err)
+	}
+
+	s.logger.Info("report subscription updated", "report_id", sub.ID)
+	return reportSubscriptionToResponse(updated), nil
+}
+
+func (s *ReportService) Delete(ctx context.Context, slug string, id uuid.UUID) error {
+	if _, _, err := s.getSubscription(ctx, slug, id); err != nil {
+		return err
+	}
+
+	if err := s.queries.DeleteReportSubscription(ctx, id); err != nil {
+		return fmt.Errorf("deleting report subscription: %w", err)
+	}
+
+	s.logger.Info("report subscription deleted", "report_id", id)
+	return nil
+}
+
+// SendNow delivers a report immediately without changing its schedule. The
+// outcome is recorded in last_run_at and last_error like a scheduled run.
+func (s *ReportService) SendNow(ctx context.Context, slug string, id uuid.UUID) (ReportSubscriptionResponse, error) {
+	event, sub, err := s.getSubscription(ctx, slug, id)
+	if err != nil {
+		return ReportSubscriptionResponse{}, err
+	}
+
+	s.run(ctx, event, sub, time.Now())
+
+	sub, err = s.queries.GetReportSubscription(ctx, id)
+	if err != nil {
+		return ReportSubscriptionResponse{}, fmt.Errorf("getting report subscription: %w", err)
+	}
+	return reportSubscriptionToResponse(sub), nil
+}
+
+// RunDue sends every report whose next run has passed and returns how many
+// were sent. Reports of events that have ended are not sent. Each run is
+// claimed by moving next_run_at forward first, so with several instances
+// polling a report still goes out once.
+func (s *ReportService) RunDue(ctx context.Context) (int, error) {
+	now := time.Now()
+	due, err := s.queries.ListDueReportSubscriptions(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("listing due reports: %w", err)
+	}
+
+	sent := 0
+	for _, sub := range due {
+		event, err := s.queries.GetEventByID(ctx, sub.EventID)
+		if err != nil {
+			s.logger.Error("failed to load event for report", "report_id", sub.ID, "error", err)
+			continue
+		}
+
+		// Missed runs (e.g. while the server was down) are skipped, not
+		// replayed: the next run is always computed from now.
+		settings := reportSettings{frequency: sub.Frequency, weekday: sub.Weekday, sendTime: sub.SendTime}
+		next := settings.nextRun(now, eventLocation(event))
+		claimed, err := s.queries.ClaimReportSubscriptionRun(ctx, sub.ID, sub.NextRunAt, next)
+		if err != nil {
+			s.logger.Error("failed to claim report run", "report_id", sub.ID, "error", err)
+			continue
+		}
+		if claimed == 0 {
+			continue
+		}
+
+		s.run(ctx, event, sub, now)
+		sent++
+	}
+	return sent, nil
+}
+
+// run delivers a report and records the outcome.
+func (s *ReportService) run(ctx context.Context, event repository.Event, sub repository.ReportSubscription, now time.Time) {
+	var lastError *string
+	if err := s.deliver(ctx, event, sub, now); err != nil {
+		s.logger.Warn("report delivery failed", "report_id", sub.ID, "error", err)
+		msg := err.Error()
+		lastError = &msg
+	} else {
+		s.logger.Info("report delivered", "report_id", sub.ID, "event_id", event.ID)
+	}
+
+	if err := s.queries.SetReportSubscriptionResult(ctx, sub.ID, now, lastError); err != nil {
+		s.logger.Error("failed to record report result", "report_id", sub.ID, "error", err)
+	}
+}
+
+// deliver renders the report and mails it to every recipient. Each recipient
+// gets a separate message so addresses are not disclosed to one another.
+func (s *ReportService) deliver(ctx context.Context, event repository.Event, sub repository.ReportSubscription, now time.Time) error {
+	smtpConfig, err := s.smtpService.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if smtpConfig == nil {
+		return errors.New("SMTP is not configured")
+	}
+
+	recipients, err := s.recipientAddresses(ctx, sub)
+	if err != nil {
+		return err
+	}
+	if len(recipients) == 0 {
+		return errors.New("no recipient has an email address")
+	}
+
+	loc := eventLocation(event)
+	start, end := reportRange(sub.TimeWindow, now, loc)
+	attachment, err := s.render(ctx, event, sub, start, end)
+	if err != nil {
+		return err
+	}
+
+	htmlBody, textBody := reportEmailBody(event, sub, start, end, loc)
+	var failed []string
+	for _, to := range recipients {
+		err := s.smtpService.Send(ctx, Email{
+			To:          []string{to},
+			Subject:     fmt.Sprintf("%s: %s", event.Name, sub.Name),
+			HTMLBody:    htmlBody,
+			TextBody:    textBody,
+			Attachments: []EmailAttachment{attachment},
+		})
+		if err != nil {
+			s.logger.Warn("failed to send report email", "report_id", sub.ID, "to", to, "error", err)
+			failed = append(failed, to)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("sending failed for %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// recipientAddresses resolves recipient users to their email addresses and
+// merges them with the explicit addresses, dropping duplicates. Deactivated
+// users and users without an address are skipped.
+func (s *ReportService) recipientAddresses(ctx context.Context, sub repository.ReportSubscription) ([]string, error) {
+	seen := make(map[string]bool)
+	var result []string
+	add := func(addr string) {
+		key := strings.ToLower(addr)
+		if !seen[key] {
+			seen[key] = true
+			result = append(result, addr)
+		}
+	}
+
+	for _, id := range sub.RecipientUserIds {
+		u, err := s.queries.GetUserByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			return nil, fmt.Errorf("getting recipient: %w", err)
+		}
+		if u.IsActive && u.Email != nil && *u.Email != "" {
+			add(*u.Email)
+		}
+	}
+	for _, addr := range sub.RecipientEmails {
+		add(addr)
+	}
+	return result, nil
+}
+
+// render produces the report attachment using the regular exports.
+func (s *ReportService) render(ctx context.Context, event repository.Event, sub repository.ReportSubscription, start, end *time.Time) (EmailAttachment, error) {
+	teamIDs := uuidStrings(sub.TeamIds)
+
+	var (
+		data        []byte
+		filename    string
+		contentType string
+		err         error
+	)
+	switch sub.Format {
+	case "csv":
+		data, filename, err = s.exportService.ExportCSV(ctx, event.Slug, CSVOptions{Start: start, End: end, TeamIDs: teamIDs})
+		contentType = "text/csv; charset=utf-8"
+	case "ical":
+		data, filename, err = s.exportService.exportICal(ctx, event.Slug, start, end, teamIDs)
+		contentType = "text/calendar; charset=utf-8"
+	default:
+		data, filename, err = s.exportJobs.RunPDF(ctx, event.Slug, pdf.PDFOptions{
+			Layout:       sub.PdfLayout,
+			PaperSize:    "A4",
+			Landscape:    true,
+			ShowCoverage: true,
+			Start:        start,
+			End:          end,
+			TeamIDs:      teamIDs,
+		})
+		contentType = "application/pdf"
+	}
+	if err != nil {
+		return EmailAttachment{}, fmt.Errorf("rendering report: %w", err)
+	}
+	return EmailAttachment{Filename: filename, ContentType: contentType, Data: data}, nil
+}
+
+func (s *ReportService) getEvent(ctx context.Context, slug string) (repository.Event, error) {
+	event, err := s.queries.GetEventBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.Event{}, model.NewDomainError(model.ErrNotFound, "event not found")
+		}
+		return repository.Event{}, fmt.Errorf("fetching event: %w", err)
+	}
+	return event, nil
+}
+
+// getSubscription loads a subscription and checks that it belongs to the
+// event identified by slug.
+func (s *ReportService) getSubscription(ctx context.Context, slug string, id uuid.UUID) (repository.Event, repository.ReportSubscription, error) {
+	event, err := s.getEvent(ctx, slug)
+	if err != nil {
+		return repository.Event{}, repository.ReportSubscription{}, err
+	}
+
+	sub, err := s.queries.GetReportSubscription(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.Event{}, repository.ReportSubscription{}, model.NewDomainError(model.ErrNotFound, "report not found")
+		}
+		return repository.Event{}, repository.ReportSubscription{}, fmt.Errorf("getting report subscription: %w", err)
+	}
+	if sub.EventID != event.ID {
+		return repository.Event{}, repository.ReportSubscription{}, model.NewDomainError(model.ErrNotFound, "report not found")
+	}
+	return event, sub, nil
+}
+
+func (s *ReportService) validate(ctx context.Context, event repository.Event, input CreateReportSubscriptionInput) (reportSettings, error) {
+	settings := reportSettings{
+		name:       strings.TrimSpace(input.Name),
+		format:     input.Format,
+		pdfLayout:  input.PDFLayout,
+		timeWindow: input.TimeWindow,
+		frequency:  input.Frequency,
+	}
+
+	if settings.name == "" {
+		return reportSettings{}, model.NewFieldError(model.ErrInvalidInput, "name", "name is required")
+	}
+	if len(settings.name) > 255 {
+		return reportSettings{}, model.NewFieldError(model.ErrInvalidInput, "name", "name must be at most 255 characters")
+	}
+	if !validReportFormats[settings.format] {
+		return reportSettings{}, model.NewFieldError(model.ErrInvalidInput, "format", "must be csv, pdf, or ical")
+	}
+	if !pdf.IsLayout(settings.pdfLayout) {
+		return reportSettings{}, model.NewFieldError(model.ErrInvalidInput, "pdf_layout", "must be grid, list, timesheet, certificate, or roster")
+	}
+	if !validReportWindows[settings.timeWindow] {
+		return reportSettings{}, model.NewFieldError(model.ErrInvalidInput, "time_window", "must be all, next_24h, next_day, or next_7d")
+	}
+
+	switch settings.frequency {
+	case "daily":
+		settings.weekday = nil
+	case "weekly":
+		if input.Weekday == nil || *input.Weekday < 0 || *input.Weekday > 6 {
+			return reportSettings{}, model.NewFieldError(model.ErrInvalidInput, "weekday", "weekly reports need a weekday from 0 (Sunday) to 6 (Saturday)")
+		}
+		settings.weekday = input.Weekday
+	default:
+		return reportSettings{}, model.NewFieldError(model.ErrInvalidInput, "frequency", "must be daily or weekly")
+	}
+
+	sendTime, err := time.Parse("15:04", input.SendTime)
+	if err != nil {
+		return reportSettings{}, model.NewFieldError(model.ErrInvalidInput, "send_time", "must be a time in HH:MM format")
+	}
+	settings.sendTime = sendTime.Format("15:04")
+
+	if len(input.TeamIDs) > 0 {
+		eventTeams, err := s.queries.ListEventTeams(ctx, event.ID)
+		if err != nil {
+			return reportSettings{}, fmt.Errorf("listing event teams: %w", err)
+		}
+		inEvent := make(map[uuid.UUID]bool, len(eventTeams))
+		for _, t := range eventTeams {
+			inEvent[t.ID] = true
+		}
+		for _, raw := range input.TeamIDs {
+			id, err := uuid.Parse(raw)
+			if err != nil || !inEvent[id] {
+				return reportSettings{}, model.NewFieldError(model.ErrInvalidInput, "team_ids", "unknown team: "+raw)
+			}
+			settings.teamIDs = append(settings.teamIDs, id)
+		}
+	}
+	if settings.teamIDs == nil {
+		settings.teamIDs = []uuid.UUID{}
+	}
+
+	settings.recipientUserIDs = []uuid.UUID{}
+	for _, raw := range input.RecipientUserIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return reportSettings{}, model.NewFieldError(model.ErrInvalidInput, "recipient_user_ids", "invalid user ID: "+raw)
+		}
+		if _, err := s.queries.GetUserByID(ctx, id); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return reportSettings{}, model.NewFieldError(model.ErrInvalidInput, "recipient_user_ids", "unknown user: "+raw)
+			}
+			return reportSettings{}, fmt.Errorf("getting user: %w", err)
+		}
+		settings.recipientUserIDs = append(settings.recipientUserIDs, id)
+	}
+
+	settings.recipientEmails = []string{}
+	for _, raw := range input.RecipientEmails {
+		addr, err := mail.ParseAddress(raw)
+		if err != nil {
+			return reportSettings{}, model.NewFieldError(model.ErrInvalidInput, "recipient_emails", "invalid email address: "+raw)
+		}
+		settings.recipientEmails = append(settings.recipientEmails, addr.Address)
+	}
+
+	total := len(settings.recipientUserIDs) + len(settings.recipientEmails)
+	if total == 0 {
+		return reportSettings{}, model.NewFieldError(model.ErrInvalidInput, "recipient_user_ids", "at least one recipient is required")
+	}
+	if total > maxReportRecipients {
+		return reportSettings{}, model.NewFieldError(model.ErrInvalidInput, "recipient_emails", fmt.Sprintf("at most %d recipients are allowed", maxReportRecipients))
+	}
+
+	return settings, nil
+}
+
+// nextRun returns the first scheduled time strictly after now. send_time is a
+// wall-clock time in loc, so reports keep their local time across DST changes.
+func (r reportSettings) nextRun(now time.Time, loc *time.Location) time.Time {
+	t, err := time.Parse("15:04", r.sendTime)
+	if err != nil {
+		t = time.Time{}
+	}
+	local := now.In(loc)
+	next := time.Date(local.Year(), local.Month(), local.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+
+	step := 1
+	if r.frequency == "weekly" && r.weekday != nil {
+		step = 7
+		next = next.AddDate(0, 0, (int(*r.weekday)-int(next.Weekday())+7)%7)
+	}
+	for !next.After(now) {
+		next = next.AddDate(0, 0, step)
+	}
+	return next
+}
+
+// reportRange returns the export range for a report sent at now. nil bounds
+// mean the event's start or end.
+func reportRange(window string, now time.Time, loc *time.Location) (*time.Time, *time.Time) {
+	var start, end time.Time
+	switch window {
+	case "next_24h":
+		start, end = now, now.Add(24*time.Hour)
+	case "next_7d":
+		start, end = now, now.AddDate(0, 0, 7)
+	case "next_day":
+		local := now.In(loc)
+		start = time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 0, 1)
+	default:
+		return nil, nil
+	}
+	return &start, &end
+}
+
+// reportEmailBody returns the HTML and plain-text body of a report email.
+func reportEmailBody(event repository.Event, sub repository.ReportSubscription, start, end *time.Time, loc *time.Location) (string, string) {
+	period := "the whole event"
+	if start != nil && end != nil {
+		period = fmt.Sprintf("%s to %s", start.In(loc).Format("Mon 2 Jan 2006 15:04"), end.In(loc).Format("Mon 2 Jan 2006 15:04 MST"))
+	}
+
+	text := fmt.Sprintf("Attached is the report %q for %s, covering %s.\n\nYou receive this email because you are a recipient of a scheduled report. An event admin can change or remove it.\n",
+		sub.Name, event.Name, period)
+	htmlBody := fmt.Sprintf("<p>Attached is the report <strong>%s</strong> for %s, covering %s.</p><p style=\"color:#666;font-size:small\">You receive this email because you are a recipient of a scheduled report. An event admin can change or remove it.</p>",
+		html.EscapeString(sub.Name), html.EscapeString(event.Name), html.EscapeString(period))
+	return htmlBody, text
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReportNextRun(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	friday := int16(5)
+
+	tests := []struct {
+		name     string
+		settings reportSettings
+		now      time.Time
+		want     time.Time
+	}{
+		{
+			name:     "daily later today",
+			settings: reportSettings{frequency: "daily", sendTime: "18:00"},
+			now:      time.Date(2025, 7, 2, 10, 0, 0, 0, berlin),
+			want:     time.Date(2025, 7, 2, 18, 0, 0, 0, berlin),
+		},
+		{
+			name:     "daily already sent today",
+			settings: reportSettings{frequency: "daily", sendTime: "18:00"},
+			now:      time.Date(2025, 7, 2, 18, 0, 0, 0, berlin),
+			want:     time.Date(2025, 7, 3, 18, 0, 0, 0, berlin),
+		},
+		{
+			name:     "daily in event zone, not UTC",
+			settings: reportSettings{frequency: "daily", sendTime: "01:00"},
+			now:      time.Date(2025, 7, 2, 22, 30, 0, 0, time.UTC), // 00:30 in Berlin
+			want:     time.Date(2025, 7, 3, 1, 0, 0, 0, berlin),
+		},
+		{
+			name:     "weekly later this week",
+			settings: reportSettings{frequency: "weekly", weekday: &friday, sendTime: "09:30"},
+			now:      time.Date(2025, 7, 2, 12, 0, 0, 0, berlin), // Wednesday
+			want:     time.Date(2025, 7, 4, 9, 30, 0, 0, berlin),
+		},
+		{
+			name:     "weekly same day after send time",
+			settings: reportSettings{frequency: "weekly", weekday: &friday, sendTime: "09:30"},
+			now:      time.Date(2025, 7, 4, 10, 0, 0, 0, berlin),
+			want:     time.Date(2025, 7, 11, 9, 30, 0, 0, berlin),
+		},
+		{
+			name:     "keeps local time across DST change",
+			settings: reportSettings{frequency: "daily", sendTime: "18:00"},
+			now:      time.Date(2025, 10, 25, 19, 0, 0, 0, berlin),
+			want:     time.Date(2025, 10, 26, 18, 0, 0, 0, berlin),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.settings.nextRun(tt.now, berlin)
+			if !got.Equal(tt.want) {
+				t.Errorf("nextRun = %s, want %s", got.In(berlin), tt.want)
+			}
+		})
+	}
+}
+
+func TestReportRange(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	now := time.Date(2025, 7, 3, 18, 0, 0, 0, berlin)
+
+	if start, end := reportRange("all", now, berlin); start != nil || end != nil {
+		t.Errorf("all: got %v - %v, want whole event", start, end)
+	}
+
+	start, end := reportRange("next_24h", now, berlin)
+	if !start.Equal(now) || !end.Equal(now.Add(24*time.Hour)) {
+		t.Errorf("next_24h: got %s - %s", start, end)
+	}
+
+	start, end = reportRange("next_day", now, berlin)
+	if !start.Equal(time.Date(2025, 7, 4, 0, 0, 0, 0, berlin)) || !end.Equal(time.Date(2025, 7, 5, 0, 0, 0, 0, berlin)) {
+		t.Errorf("next_day: got %s - %s", start.In(berlin), end.In(berlin))
+	}
+}
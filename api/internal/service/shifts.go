@@ -78,6 +78,21 @@ type ShiftWithWarnings struct {
 	Warnings []string      `json:"warnings,omitempty"`
 }
 
+// ShiftUpdate is the webhook data of shift.updated: the shift as it is now and
+// the team, user and time range it had before the change.
+type ShiftUpdate struct {
+	ShiftResponse
+	Previous ShiftPlacement `json:"previous"`
+}
+
+// ShiftPlacement is where and when a shift was, and who had it.
+type ShiftPlacement struct {
+	TeamID    string `json:"team_id"`
+	UserID    string `json:"user_id"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
 // PublicShiftResponse is the public-facing shift representation with sensitive fields removed.
 type PublicShiftResponse struct {
 	ID               string  `json:"id"`
@@ -297,6 +312,7 @@ func (s *ShiftService) Create(ctx context.Context, input CreateShiftInput, calle
 
 	resp := shiftDetailToResponse(fullShift)
 	s.logger.Info("shift created", "shift_id", shift.ID, "event", input.EventSlug, "user", input.UserID)
+	bumpPlanVersion(ctx, s.queries, s.logger, event.ID)
 
 	if s.auditService != nil {
 		go s.auditService.Log(context.Background(), &input.UserID, &event.ID, "create", "shift", &shift.ID, nil, resp, nil)
@@ -310,9 +326,22 @@ func (s *ShiftService) Create(ctx context.Context, input CreateShiftInput, calle
 	go func() {
 		bgCtx := context.Background()
 		if s.notificationService != nil {
-			title := fmt.Sprintf("%s: New shift", event.Name)
-			body := fmt.Sprintf("%s signed up for %s (%s)", resp.Username, resp.TeamName, formatTimeRange(input.StartTime, input.EndTime))
-			s.notificationService.NotifyEventUsers(bgCtx, event.ID, callerID, TriggerShiftCreated, title, &body)
+			s.notificationService.NotifyShiftChange(bgCtx, ShiftChange{
+				ShiftID:   shift.ID,
+				EventID:   event.ID,
+				EventName: event.Name,
+				EventSlug: event.Slug,
+				TimeZone:  event.Timezone,
+				ActorID:   callerID,
+				After: &ShiftSnapshot{
+					UserID:   input.UserID,
+					TeamID:   input.TeamID,
+					Username: resp.Username,
+					TeamName: resp.TeamName,
+					Start:    input.StartTime,
+					End:      input.EndTime,
+				},
+			})
 		}
 		if s.webhookService != nil {
 			s.webhookService.Dispatch(bgCtx, event.ID, TriggerShiftCreated, resp)
@@ -406,6 +435,7 @@ func (s *ShiftService) Update(ctx context.Context, shiftID uuid.UUID, input Upda
 	oldResp := shiftDetailToResponse(existing)
 	resp := shiftDetailToResponse(fullShift)
 	s.logger.Info("shift updated", "shift_id", shiftID)
+	bumpPlanVersion(ctx, s.queries, s.logger, existing.EventID)
 
 	if s.auditService != nil {
 		eventID := existing.EventID
@@ -419,12 +449,28 @@ func (s *ShiftService) Update(ctx context.Context, shiftID uuid.UUID, input Upda
 	go func() {
 		bgCtx := context.Background()
 		if s.notificationService != nil {
-			title := fmt.Sprintf("%s: Shift updated", event.Name)
-			body := fmt.Sprintf("%s's %s shift was updated (%s)", resp.Username, resp.TeamName, formatTimeRange(shift.StartTime, shift.EndTime))
-			s.notificationService.NotifyEventUsers(bgCtx, existing.EventID, callerID, TriggerShiftUpdated, title, &body)
+			s.notificationService.NotifyShiftChange(bgCtx, ShiftChange{
+				ShiftID:   shiftID,
+				EventID:   existing.EventID,
+				EventName: event.Name,
+				EventSlug: event.Slug,
+				TimeZone:  event.Timezone,
+				ActorID:   callerID,
+				Before:    shiftSnapshot(existing),
+				After:     shiftSnapshot(fullShift),
+			})
 		}
 		if s.webhookService != nil {
-			s.webhookService.Dispatch(bgCtx, existing.EventID, TriggerShiftUpdated, resp)
+			update := ShiftUpdate{ShiftResponse: resp, Previous: ShiftPlacement{
+				TeamID:    oldResp.TeamID,
+				UserID:    oldResp.UserID,
+				StartTime: oldResp.StartTime,
+				EndTime:   oldResp.EndTime,
+			}}
+			s.webhookService.Dispatch(bgCtx, existing.EventID, TriggerShiftUpdated, update)
+			if existing.UserID != fullShift.UserID {
+				s.webhookService.DispatchPersonal(bgCtx, existing.EventID, existing.UserID, TriggerShiftUpdated, update)
+			}
 		}
 	}()
 
@@ -469,7 +515,22 @@ func (s *ShiftService) Delete(ctx context.Context, shiftID uuid.UUID, callerID u
 		return fmt.Errorf("deleting shift: %w", err)
 	}
 
+	// Keep a tombstone so iCal feeds can report the shift as cancelled
+	if err := s.queries.CreateShiftDeletion(ctx, repository.CreateShiftDeletionParams{
+		ShiftID:   shiftID,
+		EventID:   existing.EventID,
+		TeamID:    existing.TeamID,
+		UserID:    existing.UserID,
+		StartTime: existing.StartTime,
+		EndTime:   existing.EndTime,
+		CreatedAt: existing.CreatedAt,
+		Sequence:  existing.Sequence + 1,
+	}); err != nil {
+		s.logger.Error("failed to record shift deletion", "shift_id", shiftID, "error", err)
+	}
+
 	s.logger.Info("shift deleted", "shift_id", shiftID)
+	bumpPlanVersion(ctx, s.queries, s.logger, existing.EventID)
 
 	if s.auditService != nil {
 		eventID := existing.EventID
@@ -483,12 +544,18 @@ func (s *ShiftService) Delete(ctx context.Context, shiftID uuid.UUID, callerID u
 	go func() {
 		bgCtx := context.Background()
 		if s.notificationService != nil {
-			title := fmt.Sprintf("%s: Shift deleted", event.Name)
-			body := fmt.Sprintf("%s's %s shift was removed (%s)", existing.Username, existing.TeamName, formatTimeRange(existing.StartTime, existing.EndTime))
-			s.notificationService.NotifyEventUsers(bgCtx, existing.EventID, callerID, TriggerShiftDeleted, title, &body)
+			s.notificationService.NotifyShiftChange(bgCtx, ShiftChange{
+				ShiftID:   shiftID,
+				EventID:   existing.EventID,
+				EventName: event.Name,
+				EventSlug: event.Slug,
+				TimeZone:  event.Timezone,
+				ActorID:   callerID,
+				Before:    shiftSnapshot(existing),
+			})
 		}
 		if s.webhookService != nil {
-			s.webhookService.Dispatch(bgCtx, existing.EventID, TriggerShiftDeleted, map[string]string{"id": shiftID.String()})
+			s.webhookService.Dispatch(bgCtx, existing.EventID, TriggerShiftDeleted, shiftDetailToResponse(existing))
 		}
 	}()
 
@@ -547,11 +614,16 @@ func (s *ShiftService) CreateCoverage(ctx context.Context, input CreateCoverageI
 
 	resp := coverageToResponse(cov)
 	s.logger.Info("coverage requirement created", "event", input.EventSlug, "team", input.TeamID)
+	bumpPlanVersion(ctx, s.queries, s.logger, event.ID)
 
 	if s.sseBroker != nil {
 		s.sseBroker.Publish(ctx, sse.Event{Type: sse.TypeCoverageUpdated, EventID: event.ID.String(), Slug: event.Slug, Payload: resp})
 	}
 
+	if s.webhookService != nil {
+		go s.webhookService.Dispatch(context.Background(), event.ID, TriggerCoverageUpdated, CoverageChange{Action: "created", Coverage: &resp})
+	}
+
 	return resp, nil
 }
 
@@ -587,11 +659,16 @@ func (s *ShiftService) UpdateCoverage(ctx context.Context, input UpdateCoverageI
 
 	resp := coverageToResponse(cov)
 	s.logger.Info("coverage requirement updated", "event", input.EventSlug, "id", input.CoverageID)
+	bumpPlanVersion(ctx, s.queries, s.logger, event.ID)
 
 	if s.sseBroker != nil {
 		s.sseBroker.Publish(ctx, sse.Event{Type: sse.TypeCoverageUpdated, EventID: event.ID.String(), Slug: event.Slug, Payload: resp})
 	}
 
+	if s.webhookService != nil {
+		go s.webhookService.Dispatch(context.Background(), event.ID, TriggerCoverageUpdated, CoverageChange{Action: "updated", Coverage: &resp})
+	}
+
 	return resp, nil
 }
 
@@ -609,11 +686,16 @@ func (s *ShiftService) DeleteCoverage(ctx context.Context, slug string, coverage
 	}
 
 	s.logger.Info("coverage requirement deleted", "event", slug, "id", coverageID)
+	bumpPlanVersion(ctx, s.queries, s.logger, event.ID)
 
 	if s.sseBroker != nil {
 		s.sseBroker.Publish(ctx, sse.Event{Type: sse.TypeCoverageUpdated, EventID: event.ID.String(), Slug: event.Slug, Payload: map[string]string{"id": coverageID.String(), "action": "deleted"}})
 	}
 
+	if s.webhookService != nil {
+		go s.webhookService.Dispatch(context.Background(), event.ID, TriggerCoverageUpdated, CoverageChange{Action: "deleted", ID: coverageID.String()})
+	}
+
 	return nil
 }
 
@@ -631,11 +713,16 @@ func (s *ShiftService) DeleteCoverageByTeam(ctx context.Context, slug string, te
 	}
 
 	s.logger.Info("coverage requirements deleted", "event", slug, "team", teamID)
+	bumpPlanVersion(ctx, s.queries, s.logger, event.ID)
 
 	if s.sseBroker != nil {
 		s.sseBroker.Publish(ctx, sse.Event{Type: sse.TypeCoverageUpdated, EventID: event.ID.String(), Slug: event.Slug, Payload: map[string]string{"team_id": teamID.String(), "action": "deleted"}})
 	}
 
+	if s.webhookService != nil {
+		go s.webhookService.Dispatch(context.Background(), event.ID, TriggerCoverageUpdated, CoverageChange{Action: "deleted", TeamID: teamID.String()})
+	}
+
 	return nil
 }
 
@@ -888,6 +975,17 @@ func shiftDetailToResponse(sh repository.GetShiftByIDRow) ShiftResponse {
 	}
 }
 
+func shiftSnapshot(sh repository.GetShiftByIDRow) *ShiftSnapshot {
+	return &ShiftSnapshot{
+		UserID:   sh.UserID,
+		TeamID:   sh.TeamID,
+		Username: sh.Username,
+		TeamName: sh.TeamName,
+		Start:    sh.StartTime,
+		End:      sh.EndTime,
+	}
+}
+
 func coverageToResponse(c repository.CoverageRequirement) CoverageRequirementResponse {
 	return CoverageRequirementResponse{
 		ID:            c.ID.String(),
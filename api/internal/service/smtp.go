@@ -1,12 +1,20 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
 	"net/smtp"
+	"net/textproto"
 	"strings"
 	"time"
 
@@ -101,32 +109,50 @@ func (s *SMTPService) UpdateConfig(ctx context.Context, input UpdateSMTPConfigIn
 	}, nil
 }
 
+// EmailAttachment is a file attached to an outgoing email.
+type EmailAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Email is an outgoing message. TextBody and Attachments are optional; when
+// TextBody is set it is sent as the plain-text alternative to HTMLBody.
+type Email struct {
+	To          []string
+	Subject     string
+	HTMLBody    string
+	TextBody    string
+	Attachments []EmailAttachment
+}
+
 // SendEmail sends an HTML email using the configured SMTP server.
 // Returns nil without error if SMTP is not configured.
 func (s *SMTPService) SendEmail(ctx context.Context, to, subject, htmlBody string) error {
+	return s.Send(ctx, Email{To: []string{to}, Subject: subject, HTMLBody: htmlBody})
+}
+
+// Send delivers an email using the configured SMTP server.
+// Returns nil without error if SMTP is not configured.
+func (s *SMTPService) Send(ctx context.Context, email Email) error {
 	cfg, err := s.queries.GetSMTPConfig(ctx)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			s.logger.Debug("SMTP not configured, skipping email", "to", to)
+			s.logger.Debug("SMTP not configured, skipping email", "to", email.To)
 			return nil
 		}
 		return fmt.Errorf("getting SMTP config: %w", err)
 	}
 
-	fromHeader := cfg.FromAddress
-	if cfg.FromName != nil && *cfg.FromName != "" {
-		fromHeader = fmt.Sprintf("%s <%s>", *cfg.FromName, cfg.FromAddress)
+	from := mail.Address{Address: cfg.FromAddress}
+	if cfg.FromName != nil {
+		from.Name = *cfg.FromName
 	}
 
-	msg := strings.Join([]string{
-		"From: " + fromHeader,
-		"To: " + to,
-		"Subject: " + subject,
-		"MIME-Version: 1.0",
-		"Content-Type: text/html; charset=UTF-8",
-		"",
-		htmlBody,
-	}, "\r\n")
+	msg, err := buildMessage(from.String(), email, time.Now())
+	if err != nil {
+		return err
+	}
 
 	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
 
@@ -136,13 +162,137 @@ func (s *SMTPService) SendEmail(ctx context.Context, to, subject, htmlBody strin
 	}
 
 	if cfg.UseTls {
-		return s.sendTLS(addr, cfg.Host, cfg.FromAddress, to, auth, []byte(msg))
+		return s.sendTLS(addr, cfg.Host, cfg.FromAddress, email.To, auth, msg)
+	}
+
+	return smtp.SendMail(addr, auth, cfg.FromAddress, email.To, msg)
+}
+
+// buildMessage renders email as a MIME message. The body is a single HTML
+// part, or multipart/alternative when a text body is present, wrapped in
+// multipart/mixed when there are attachments.
+func buildMessage(from string, email Email, now time.Time) ([]byte, error) {
+	if len(email.To) == 0 {
+		return nil, errors.New("email has no recipients")
+	}
+	for _, to := range email.To {
+		if strings.ContainsAny(to, "\r\n") {
+			return nil, fmt.Errorf("invalid recipient %q", to)
+		}
+	}
+
+	var b bytes.Buffer
+	header := func(key, value string) {
+		b.WriteString(key + ": " + value + "\r\n")
+	}
+	header("From", from)
+	header("To", strings.Join(email.To, ", "))
+	header("Subject", mime.QEncoding.Encode("UTF-8", email.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+
+	bodyHeader, body, err := emailBody(email)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(email.Attachments) == 0 {
+		for _, key := range []string{"Content-Type", "Content-Transfer-Encoding"} {
+			if v := bodyHeader.Get(key); v != "" {
+				header(key, v)
+			}
+		}
+		b.WriteString("\r\n")
+		b.Write(body)
+		return b.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&b)
+	header("Content-Type", "multipart/mixed; boundary="+mixed.Boundary())
+	b.WriteString("\r\n")
+
+	w, err := mixed.CreatePart(bodyHeader)
+	if err != nil {
+		return nil, err
+	}
+	w.Write(body)
+
+	for _, a := range email.Attachments {
+		contentType := a.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		w, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(contentType, map[string]string{"name": a.Filename})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		writeBase64Lines(w, a.Data)
+	}
+
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// emailBody returns the header and content of the message body part.
+func emailBody(email Email) (textproto.MIMEHeader, []byte, error) {
+	if email.TextBody == "" {
+		return quotedPrintablePart("text/html; charset=UTF-8", email.HTMLBody)
 	}
 
-	return smtp.SendMail(addr, auth, cfg.FromAddress, []string{to}, []byte(msg))
+	var buf bytes.Buffer
+	alt := multipart.NewWriter(&buf)
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=UTF-8", email.TextBody},
+		{"text/html; charset=UTF-8", email.HTMLBody},
+	} {
+		h, data, err := quotedPrintablePart(part.contentType, part.body)
+		if err != nil {
+			return nil, nil, err
+		}
+		w, err := alt.CreatePart(h)
+		if err != nil {
+			return nil, nil, err
+		}
+		w.Write(data)
+	}
+	if err := alt.Close(); err != nil {
+		return nil, nil, err
+	}
+	return textproto.MIMEHeader{"Content-Type": {"multipart/alternative; boundary=" + alt.Boundary()}}, buf.Bytes(), nil
+}
+
+func quotedPrintablePart(contentType, body string) (textproto.MIMEHeader, []byte, error) {
+	var buf bytes.Buffer
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return nil, nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, nil, err
+	}
+	return textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	}, buf.Bytes(), nil
+}
+
+// writeBase64Lines writes data base64-encoded in lines of 76 characters.
+func writeBase64Lines(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		io.WriteString(w, encoded[:76]+"\r\n")
+		encoded = encoded[76:]
+	}
+	io.WriteString(w, encoded+"\r\n")
 }
 
-func (s *SMTPService) sendTLS(addr, host, from, to string, auth smtp.Auth, msg []byte) error {
+func (s *SMTPService) sendTLS(addr, host, from string, to []string, auth smtp.Auth, msg []byte) error {
 	tlsConfig := &tls.Config{ServerName: host}
 
 	conn, err := tls.Dial("tcp", addr, tlsConfig)
@@ -166,8 +316,10 @@ func (s *SMTPService) sendTLS(addr, host, from, to string, auth smtp.Auth, msg [
 	if err := client.Mail(from); err != nil {
 		return fmt.Errorf("SMTP MAIL FROM failed: %w", err)
 	}
-	if err := client.Rcpt(to); err != nil {
-		return fmt.Errorf("SMTP RCPT TO failed: %w", err)
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("SMTP RCPT TO failed: %w", err)
+		}
 	}
 
 	w, err := client.Data()
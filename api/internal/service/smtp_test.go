@@ -0,0 +1,118 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildMessage(t *testing.T) {
+	now := time.Date(2025, 7, 4, 18, 0, 0, 0, time.UTC)
+	pdfData := bytes.Repeat([]byte("%PDF-1.4 "), 40)
+
+	raw, err := buildMessage("Rncasp <noreply@example.com>", Email{
+		To:       []string{"lead@example.com"},
+		Subject:  "Schichtplan für morgen",
+		HTMLBody: "<p>Plan attached.</p>",
+		TextBody: "Plan attached.",
+		Attachments: []EmailAttachment{
+			{Filename: "plan.pdf", ContentType: "application/pdf", Data: pdfData},
+		},
+	}, now)
+	if err != nil {
+		t.Fatalf("buildMessage: %v", err)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("parsing message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "Schichtplan für morgen" {
+		t.Errorf("subject = %q, %v", subject, err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("content type = %q, %v", mediaType, err)
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+
+	body, err := mr.NextPart()
+	if err != nil {
+		t.Fatalf("body part: %v", err)
+	}
+	bodyType, bodyParams, _ := mime.ParseMediaType(body.Header.Get("Content-Type"))
+	if bodyType != "multipart/alternative" {
+		t.Fatalf("body content type = %q", bodyType)
+	}
+	alt := multipart.NewReader(body, bodyParams["boundary"])
+	for _, want := range []string{"Plan attached.", "<p>Plan attached.</p>"} {
+		part, err := alt.NextPart()
+		if err != nil {
+			t.Fatalf("alternative part: %v", err)
+		}
+		// multipart.Reader decodes quoted-printable parts transparently
+		got, _ := io.ReadAll(part)
+		if string(got) != want {
+			t.Errorf("alternative part = %q, want %q", got, want)
+		}
+	}
+
+	att, err := mr.NextPart()
+	if err != nil {
+		t.Fatalf("attachment part: %v", err)
+	}
+	if att.FileName() != "plan.pdf" {
+		t.Errorf("attachment filename = %q", att.FileName())
+	}
+	encoded, _ := io.ReadAll(att)
+	for _, line := range strings.Split(strings.TrimSpace(string(encoded)), "\r\n") {
+		if len(line) > 76 {
+			t.Errorf("base64 line longer than 76 characters: %d", len(line))
+		}
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", ""))
+	if err != nil || !bytes.Equal(decoded, pdfData) {
+		t.Errorf("attachment data did not round-trip: %v", err)
+	}
+}
+
+func TestBuildMessageHTMLOnly(t *testing.T) {
+	raw, err := buildMessage("noreply@example.com", Email{
+		To:       []string{"a@example.com"},
+		Subject:  "Welcome",
+		HTMLBody: "<p>Hello</p>",
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("buildMessage: %v", err)
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("parsing message: %v", err)
+	}
+	if ct := msg.Header.Get("Content-Type"); ct != "text/html; charset=UTF-8" {
+		t.Errorf("content type = %q", ct)
+	}
+	body, _ := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	if string(body) != "<p>Hello</p>" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestBuildMessageRejectsHeaderInjection(t *testing.T) {
+	_, err := buildMessage("noreply@example.com", Email{
+		To:       []string{"a@example.com\r\nBcc: b@example.com"},
+		HTMLBody: "x",
+	}, time.Now())
+	if err == nil {
+		t.Error("expected an error for a recipient containing a line break")
+	}
+}
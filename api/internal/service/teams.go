@@ -15,9 +15,10 @@ import (
 )
 
 type TeamService struct {
-	queries      *repository.Queries
-	logger       *slog.Logger
-	auditService *AuditService
+	queries        *repository.Queries
+	logger         *slog.Logger
+	auditService   *AuditService
+	webhookService *WebhookService
 }
 
 func NewTeamService(queries *repository.Queries, logger *slog.Logger) *TeamService {
@@ -28,6 +29,11 @@ func (s *TeamService) SetAuditService(as *AuditService) {
 	s.auditService = as
 }
 
+// SetWebhookService sets the webhook service for trigger dispatch.
+func (s *TeamService) SetWebhookService(ws *WebhookService) {
+	s.webhookService = ws
+}
+
 type CreateTeamInput struct {
 	Name         string
 	Abbreviation string
@@ -130,6 +136,10 @@ func (s *TeamService) Create(ctx context.Context, input CreateTeamInput) (TeamRe
 		go s.auditService.Log(context.Background(), nil, nil, "create", "team", &team.ID, nil, teamToResponse(team), nil)
 	}
 
+	if s.webhookService != nil {
+		go s.webhookService.DispatchGlobal(context.Background(), TriggerTeamCreated, teamToResponse(team))
+	}
+
 	return teamToResponse(team), nil
 }
 
@@ -193,6 +203,10 @@ func (s *TeamService) Update(ctx context.Context, id uuid.UUID, input UpdateTeam
 		go s.auditService.Log(context.Background(), nil, nil, "update", "team", &id, teamToResponse(existing), teamToResponse(team), nil)
 	}
 
+	if s.webhookService != nil {
+		go s.webhookService.DispatchGlobal(context.Background(), TriggerTeamUpdated, teamToResponse(team))
+	}
+
 	return teamToResponse(team), nil
 }
 
@@ -215,6 +229,10 @@ func (s *TeamService) Delete(ctx context.Context, id uuid.UUID) error {
 		go s.auditService.Log(context.Background(), nil, nil, "delete", "team", &id, teamToResponse(existing), nil, nil)
 	}
 
+	if s.webhookService != nil {
+		go s.webhookService.DispatchGlobal(context.Background(), TriggerTeamDeleted, teamToResponse(existing))
+	}
+
 	return nil
 }
 
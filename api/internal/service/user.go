@@ -131,6 +131,7 @@ type UpdateUserInput struct {
 	TimeFormat  *string
 	Username    *string
 	AccountType *string
+	Phone       *string
 }
 
 // UpdateUser updates a user's profile (super-admin only).
@@ -164,6 +165,10 @@ func (s *UserService) UpdateUser(ctx context.Context, id uuid.UUID, input Update
 		}
 	}
 
+	if err := validatePhone(input.Phone); err != nil {
+		return UserResponse{}, err
+	}
+
 	// Validate username uniqueness if changed
 	if input.Username != nil && *input.Username != existing.Username {
 		if *input.Username == "" {
@@ -206,6 +211,7 @@ func (s *UserService) UpdateUser(ctx context.Context, id uuid.UUID, input Update
 		TimeFormat:  input.TimeFormat,
 		Username:    input.Username,
 		AccountType: input.AccountType,
+		Phone:       input.Phone,
 	})
 	if err != nil {
 		return UserResponse{}, fmt.Errorf("updating user: %w", err)
@@ -1,7 +1,6 @@
 package service
 
 import (
-	"bytes"
 	"context"
 	"crypto/hmac"
 	"crypto/sha256"
@@ -15,36 +14,72 @@ import (
 	"time"
 
 	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/outbound"
 	"github.com/echtkpvl/rncasp/internal/repository"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type WebhookService struct {
-	queries    *repository.Queries
-	logger     *slog.Logger
-	httpClient *http.Client
+	db                  *pgxpool.Pool
+	queries             *repository.Queries
+	logger              *slog.Logger
+	httpClient          *http.Client
+	outbound            *outbound.Policy
+	notificationService *NotificationService
+	baseURL             string
+	disableAfter        int
+	wakeCh              chan struct{}
+	stopCh              chan struct{}
 }
 
-func NewWebhookService(queries *repository.Queries, logger *slog.Logger) *WebhookService {
+// NewWebhookService creates a webhook service. Deliveries are sent through
+// the outbound policy. baseURL is used to link chat messages to events. A
+// webhook is disabled after disableAfter consecutive failed deliveries; 0
+// never disables webhooks.
+func NewWebhookService(db *pgxpool.Pool, queries *repository.Queries, logger *slog.Logger, policy *outbound.Policy, baseURL string, disableAfter int) *WebhookService {
 	return &WebhookService{
-		queries: queries,
-		logger:  logger,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		db:           db,
+		queries:      queries,
+		logger:       logger,
+		httpClient:   policy.Client(10*time.Second, webhookMaxResponseBody),
+		outbound:     policy,
+		baseURL:      baseURL,
+		disableAfter: disableAfter,
+		wakeCh:       make(chan struct{}, 1),
+		stopCh:       make(chan struct{}),
 	}
 }
 
+// SetNotificationService enables notifying super admins about webhooks that
+// were disabled after failing.
+func (s *WebhookService) SetNotificationService(ns *NotificationService) {
+	s.notificationService = ns
+}
+
 type WebhookResponse struct {
 	ID           string   `json:"id"`
 	EventID      *string  `json:"event_id"`
+	UserID       *string  `json:"user_id,omitempty"` // owner of a personal webhook
 	Name         string   `json:"name"`
 	URL          string   `json:"url"`
 	Format       string   `json:"format"`
 	TriggerTypes []string `json:"trigger_types"`
 	IsEnabled    bool     `json:"is_enabled"`
 	CreatedAt    string   `json:"created_at"`
+
+	Template    *string           `json:"template,omitempty"`
+	ContentType *string           `json:"content_type,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+
+	ConsecutiveFailures int     `json:"consecutive_failures"`
+	DisabledReason      *string `json:"disabled_reason"`
+
+	// Set while deliveries are also signed with the secret before the last rotation
+	PreviousSecretExpiresAt *string `json:"previous_secret_expires_at,omitempty"`
+
+	Filters WebhookFilters `json:"filters"`
 }
 
 type CreateWebhookInput struct {
@@ -54,6 +89,14 @@ type CreateWebhookInput struct {
 	Secret       string
 	Format       string
 	TriggerTypes []string
+
+	// Template format settings
+	Template    *string
+	ContentType *string
+	Headers     map[string]string
+
+	// Event webhooks only
+	Filters *WebhookFilters
 }
 
 type UpdateWebhookInput struct {
@@ -63,6 +106,14 @@ type UpdateWebhookInput struct {
 	Format       *string
 	TriggerTypes *[]string
 	IsEnabled    *bool
+
+	// Template format settings; nil Headers keeps the current headers
+	Template    *string
+	ContentType *string
+	Headers     map[string]string
+
+	// Replaces all filters; nil keeps them
+	Filters *WebhookFilters
 }
 
 func webhookToResponse(w repository.WebhookConfig) WebhookResponse {
@@ -71,15 +122,32 @@ func webhookToResponse(w repository.WebhookConfig) WebhookResponse {
 		s := w.EventID.String()
 		eventID = &s
 	}
+	var userID *string
+	if w.UserID != nil {
+		s := w.UserID.String()
+		userID = &s
+	}
 	return WebhookResponse{
 		ID:           w.ID.String(),
 		EventID:      eventID,
+		UserID:       userID,
 		Name:         w.Name,
 		URL:          w.Url,
 		Format:       w.Format,
 		TriggerTypes: w.TriggerTypes,
 		IsEnabled:    w.IsEnabled,
 		CreatedAt:    w.CreatedAt.Format(time.RFC3339),
+
+		ConsecutiveFailures: int(w.ConsecutiveFailures),
+		DisabledReason:      w.DisabledReason,
+
+		Template:    w.Template,
+		ContentType: w.ContentType,
+		Headers:     decodeWebhookHeaders(w.Headers),
+
+		PreviousSecretExpiresAt: previousSecretExpiry(w, time.Now()),
+
+		Filters: webhookFiltersToResponse(w),
 	}
 }
 
@@ -118,11 +186,26 @@ func (s *WebhookService) Create(ctx context.Context, input CreateWebhookInput) (
 	if format == "" {
 		format = "default"
 	}
-	if format == "default" && input.Secret == "" {
-		return WebhookResponse{}, model.NewFieldError(model.ErrInvalidInput, "secret", "secret is required for default format")
+	headers := encodeWebhookHeaders(input.Headers)
+	if err := validateWebhook(repository.WebhookConfig{
+		Url:         input.URL,
+		Secret:      input.Secret,
+		Format:      format,
+		Template:    input.Template,
+		ContentType: input.ContentType,
+		Headers:     headers,
+	}); err != nil {
+		return WebhookResponse{}, err
+	}
+	if err := validateTriggerTypes(WebhookScopeEvent, input.TriggerTypes); err != nil {
+		return WebhookResponse{}, err
 	}
-	if len(input.TriggerTypes) == 0 {
-		return WebhookResponse{}, model.NewFieldError(model.ErrInvalidInput, "trigger_types", "at least one trigger type is required")
+	if err := s.checkURL(ctx, input.URL); err != nil {
+		return WebhookResponse{}, err
+	}
+	filter, err := s.parseWebhookFilters(ctx, *input.EventID, input.Filters)
+	if err != nil {
+		return WebhookResponse{}, err
 	}
 
 	webhook, err := s.queries.CreateWebhook(ctx, repository.CreateWebhookParams{
@@ -132,6 +215,13 @@ func (s *WebhookService) Create(ctx context.Context, input CreateWebhookInput) (
 		Secret:       input.Secret,
 		TriggerTypes: input.TriggerTypes,
 		Format:       format,
+		Template:     input.Template,
+		ContentType:  input.ContentType,
+		Headers:      headers,
+
+		FilterTeamIds:     filter.teamIDs,
+		FilterUserIds:     filter.userIDs,
+		FilterWithinHours: filter.withinHours,
 	})
 	if err != nil {
 		return WebhookResponse{}, fmt.Errorf("creating webhook: %w", err)
@@ -142,7 +232,7 @@ func (s *WebhookService) Create(ctx context.Context, input CreateWebhookInput) (
 }
 
 func (s *WebhookService) Update(ctx context.Context, webhookID uuid.UUID, input UpdateWebhookInput) (WebhookResponse, error) {
-	_, err := s.queries.GetWebhookByID(ctx, webhookID)
+	existing, err := s.queries.GetWebhookByID(ctx, webhookID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return WebhookResponse{}, model.NewDomainError(model.ErrNotFound, "webhook not found")
@@ -150,6 +240,55 @@ func (s *WebhookService) Update(ctx context.Context, webhookID uuid.UUID, input
 		return WebhookResponse{}, fmt.Errorf("getting webhook: %w", err)
 	}
 
+	headers := encodeWebhookHeaders(input.Headers)
+	target := existing
+	if input.Format != nil {
+		target.Format = *input.Format
+	}
+	if input.URL != nil {
+		target.Url = *input.URL
+	}
+	if input.Secret != nil {
+		target.Secret = *input.Secret
+	}
+	if target.Format == "template" {
+		if input.Template != nil {
+			target.Template = input.Template
+		}
+		if input.ContentType != nil {
+			target.ContentType = input.ContentType
+		}
+		if headers != nil {
+			target.Headers = headers
+		}
+	} else {
+		// Settings of a previous template format are dropped on save
+		target.Template, target.ContentType, target.Headers = input.Template, input.ContentType, headers
+	}
+	if err := validateWebhook(target); err != nil {
+		return WebhookResponse{}, err
+	}
+	if input.TriggerTypes != nil {
+		if err := validateTriggerTypes(webhookScope(existing), *input.TriggerTypes); err != nil {
+			return WebhookResponse{}, err
+		}
+	}
+	if target.Url != existing.Url {
+		if err := s.checkURL(ctx, target.Url); err != nil {
+			return WebhookResponse{}, err
+		}
+	}
+	var filter webhookFilter
+	if input.Filters != nil {
+		if existing.EventID == nil {
+			if err := checkNoWebhookFilters(input.Filters); err != nil {
+				return WebhookResponse{}, err
+			}
+		} else if filter, err = s.parseWebhookFilters(ctx, *existing.EventID, input.Filters); err != nil {
+			return WebhookResponse{}, err
+		}
+	}
+
 	updated, err := s.queries.UpdateWebhook(ctx, repository.UpdateWebhookParams{
 		ID:           webhookID,
 		Name:         input.Name,
@@ -158,15 +297,35 @@ func (s *WebhookService) Update(ctx context.Context, webhookID uuid.UUID, input
 		TriggerTypes: input.TriggerTypes,
 		IsEnabled:    input.IsEnabled,
 		Format:       input.Format,
+		Template:     input.Template,
+		ContentType:  input.ContentType,
+		Headers:      headers,
+
+		FilterTeamIds:     filter.teamIDs,
+		FilterUserIds:     filter.userIDs,
+		FilterWithinHours: filter.withinHours,
 	})
 	if err != nil {
 		return WebhookResponse{}, fmt.Errorf("updating webhook: %w", err)
 	}
+	if !updated.IsEnabled {
+		if err := s.queries.CancelPendingWebhookDeliveries(ctx, webhookID); err != nil {
+			s.logger.Error("failed to cancel webhook deliveries", "error", err, "webhook_id", webhookID)
+		}
+	}
 
 	s.logger.Info("webhook updated", "webhook_id", webhookID)
 	return webhookToResponse(updated), nil
 }
 
+// checkURL rejects webhook URLs the outbound policy would refuse to deliver to.
+func (s *WebhookService) checkURL(ctx context.Context, rawURL string) error {
+	if err := s.outbound.CheckURL(ctx, rawURL); err != nil {
+		return model.NewFieldError(model.ErrInvalidInput, "url", "webhook URL is not allowed: "+err.Error())
+	}
+	return nil
+}
+
 func (s *WebhookService) Delete(ctx context.Context, webhookID uuid.UUID) error {
 	_, err := s.queries.GetWebhookByID(ctx, webhookID)
 	if err != nil {
@@ -210,11 +369,25 @@ func (s *WebhookService) CreateGlobal(ctx context.Context, input CreateWebhookIn
 	if format == "" {
 		format = "default"
 	}
-	if format == "default" && input.Secret == "" {
-		return WebhookResponse{}, model.NewFieldError(model.ErrInvalidInput, "secret", "secret is required for default format")
+	headers := encodeWebhookHeaders(input.Headers)
+	if err := validateWebhook(repository.WebhookConfig{
+		Url:         input.URL,
+		Secret:      input.Secret,
+		Format:      format,
+		Template:    input.Template,
+		ContentType: input.ContentType,
+		Headers:     headers,
+	}); err != nil {
+		return WebhookResponse{}, err
 	}
-	if len(input.TriggerTypes) == 0 {
-		return WebhookResponse{}, model.NewFieldError(model.ErrInvalidInput, "trigger_types", "at least one trigger type is required")
+	if err := validateTriggerTypes(WebhookScopeGlobal, input.TriggerTypes); err != nil {
+		return WebhookResponse{}, err
+	}
+	if err := checkNoWebhookFilters(input.Filters); err != nil {
+		return WebhookResponse{}, err
+	}
+	if err := s.checkURL(ctx, input.URL); err != nil {
+		return WebhookResponse{}, err
 	}
 
 	webhook, err := s.queries.CreateGlobalWebhook(ctx, repository.CreateGlobalWebhookParams{
@@ -223,6 +396,9 @@ func (s *WebhookService) CreateGlobal(ctx context.Context, input CreateWebhookIn
 		Secret:       input.Secret,
 		TriggerTypes: input.TriggerTypes,
 		Format:       format,
+		Template:     input.Template,
+		ContentType:  input.ContentType,
+		Headers:      headers,
 	})
 	if err != nil {
 		return WebhookResponse{}, fmt.Errorf("creating global webhook: %w", err)
@@ -240,8 +416,10 @@ type WebhookPayload struct {
 	Data      any    `json:"data"`
 }
 
-// Dispatch sends a webhook event to all active webhooks matching the trigger type
-// for the given event. Dispatches are fire-and-forget in goroutines.
+// Dispatch queues a webhook event for all active webhooks matching the trigger
+// type for the given event, skipping webhooks whose filters don't match data,
+// and for the personal webhooks of the users the data is about. The
+// deliveries are sent by the background worker.
 func (s *WebhookService) Dispatch(ctx context.Context, eventID uuid.UUID, triggerType string, data any) {
 	webhooks, err := s.queries.ListActiveWebhooksForTrigger(ctx, repository.ListActiveWebhooksForTriggerParams{
 		EventID:     eventID,
@@ -249,11 +427,6 @@ func (s *WebhookService) Dispatch(ctx context.Context, eventID uuid.UUID, trigge
 	})
 	if err != nil {
 		s.logger.Error("failed to list webhooks for dispatch", "error", err, "event_id", eventID)
-		return
-	}
-
-	if len(webhooks) == 0 {
-		return
 	}
 
 	payload := WebhookPayload{
@@ -262,13 +435,34 @@ func (s *WebhookService) Dispatch(ctx context.Context, eventID uuid.UUID, trigge
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 		Data:      data,
 	}
+	subject := webhookSubjectOf(data)
+
+	if len(webhooks) == 0 {
+		s.dispatchPersonal(ctx, subject.userIDs, payload, nil)
+		return
+	}
 
+	var event *repository.Event
+	if e, err := s.queries.GetEventByID(ctx, eventID); err == nil {
+		event = &e
+	} else {
+		s.logger.Warn("failed to get event for webhook message", "error", err, "event_id", eventID)
+	}
+
+	now := time.Now()
 	for _, wh := range webhooks {
-		go s.deliverPayload(wh, payload)
+		if !subject.matches(wh, now) {
+			continue
+		}
+		if _, err := s.enqueue(ctx, wh, payload, event); err != nil {
+			s.logger.Error("failed to queue webhook delivery", "error", err, "webhook_id", wh.ID)
+		}
 	}
+	s.dispatchPersonal(ctx, subject.userIDs, payload, event)
 }
 
-// DispatchGlobal sends a webhook event to all active global webhooks matching the trigger type.
+// DispatchGlobal queues a webhook event for all active global webhooks matching
+// the trigger type.
 func (s *WebhookService) DispatchGlobal(ctx context.Context, triggerType string, data any) {
 	webhooks, err := s.queries.ListActiveGlobalWebhooksForTrigger(ctx, triggerType)
 	if err != nil {
@@ -287,55 +481,18 @@ func (s *WebhookService) DispatchGlobal(ctx context.Context, triggerType string,
 	}
 
 	for _, wh := range webhooks {
-		go s.deliverPayload(wh, payload)
-	}
-}
-
-func (s *WebhookService) deliverPayload(wh repository.WebhookConfig, payload WebhookPayload) {
-	if wh.Format == "discord" {
-		body, err := toDiscordPayload(payload)
-		if err != nil {
-			s.logger.Error("failed to marshal discord payload", "error", err, "webhook_id", wh.ID)
-			return
-		}
-		s.deliver(wh, body)
-	} else {
-		body, err := json.Marshal(payload)
-		if err != nil {
-			s.logger.Error("failed to marshal webhook payload", "error", err, "webhook_id", wh.ID)
-			return
+		if _, err := s.enqueue(ctx, wh, payload, nil); err != nil {
+			s.logger.Error("failed to queue webhook delivery", "error", err, "webhook_id", wh.ID)
 		}
-		s.deliver(wh, body)
 	}
 }
 
-func (s *WebhookService) deliver(wh repository.WebhookConfig, body []byte) {
-	req, err := http.NewRequest(http.MethodPost, wh.Url, bytes.NewReader(body))
-	if err != nil {
-		s.logger.Error("failed to create webhook request", "error", err, "webhook_id", wh.ID)
-		return
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Webhook-ID", wh.ID.String())
-
-	if wh.Secret != "" && wh.Format != "discord" {
-		sig := computeHMAC(body, wh.Secret)
-		req.Header.Set("X-Webhook-Signature", "sha256="+sig)
-	}
-
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		s.logger.Error("webhook delivery failed", "error", err, "webhook_id", wh.ID, "url", wh.Url)
-		return
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 400 {
-		s.logger.Warn("webhook returned error status", "webhook_id", wh.ID, "url", wh.Url, "status", resp.StatusCode)
-	} else {
-		s.logger.Debug("webhook delivered", "webhook_id", wh.ID, "url", wh.Url, "status", resp.StatusCode)
-	}
+// renderWebhookPayload returns the request body for a webhook in its format.
+// event is the event the payload is about, if known.
+func (s *WebhookService) renderWebhookPayload(wh repository.WebhookConfig, payload WebhookPayload, event *repository.Event) ([]byte, error) {
+	msg := describeWebhook(payload, event, s.baseURL)
+	msg.Webhook = wh
+	return webhookFormatter(wh.Format).Render(msg)
 }
 
 func computeHMAC(data []byte, secret string) string {
@@ -344,7 +501,8 @@ func computeHMAC(data []byte, secret string) string {
 	return hex.EncodeToString(mac.Sum(nil))
 }
 
-// Test sends a test webhook payload to the specified webhook.
+// Test queues a test payload for the specified webhook. Disabled webhooks can
+// be tested too, to check they work before enabling them again.
 func (s *WebhookService) Test(ctx context.Context, webhookID uuid.UUID) error {
 	wh, err := s.queries.GetWebhookByID(ctx, webhookID)
 	if err != nil {
@@ -362,7 +520,9 @@ func (s *WebhookService) Test(ctx context.Context, webhookID uuid.UUID) error {
 		},
 	}
 
-	s.deliverPayload(wh, payload)
+	if _, err := s.enqueue(ctx, wh, payload, nil); err != nil {
+		return fmt.Errorf("queueing test webhook: %w", err)
+	}
 	return nil
 }
 
@@ -0,0 +1,484 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	webhookBatchSize       = 20
+	webhookLease           = 5 * time.Minute // how long a claimed delivery is hidden from other workers
+	webhookMaxAttempts     = 8
+	webhookPollInterval    = 15 * time.Second
+	webhookMaxBackoff      = time.Hour
+	webhookMaxResponseBody = 2048 // bytes of the response kept per attempt
+)
+
+type WebhookDeliveryResponse struct {
+	ID                 string  `json:"id"`
+	WebhookID          string  `json:"webhook_id"`
+	TriggerType        string  `json:"trigger_type"`
+	Status             string  `json:"status"`
+	Attempts           int     `json:"attempts"`
+	NextAttemptAt      *string `json:"next_attempt_at"`
+	RedeliveryOf       *string `json:"redelivery_of"`
+	DeliveredAt        *string `json:"delivered_at"`
+	CreatedAt          string  `json:"created_at"`
+	LastResponseStatus *int    `json:"last_response_status"`
+	LastDurationMs     *int    `json:"last_duration_ms"`
+	LastError          *string `json:"last_error"`
+}
+
+// WebhookDeliveryDetailResponse is a delivery with the body that was sent and
+// every request made for it.
+type WebhookDeliveryDetailResponse struct {
+	WebhookDeliveryResponse
+	Payload string                           `json:"payload"`
+	History []WebhookDeliveryAttemptResponse `json:"history"`
+}
+
+type WebhookDeliveryAttemptResponse struct {
+	Attempt        int     `json:"attempt"`
+	ResponseStatus *int    `json:"response_status"`
+	DurationMs     int     `json:"duration_ms"`
+	Error          *string `json:"error"`
+	ResponseBody   *string `json:"response_body"`
+	CreatedAt      string  `json:"created_at"`
+}
+
+// webhookAttempt is the outcome of a single delivery request.
+type webhookAttempt struct {
+	status   int // 0 if no response was received
+	duration time.Duration
+	body     string
+	err      error
+}
+
+func (a webhookAttempt) ok() bool {
+	return a.err == nil && a.status >= 200 && a.status <= 299
+}
+
+// errorMessage describes a failed attempt for the delivery log.
+func (a webhookAttempt) errorMessage() string {
+	if a.err != nil {
+		return a.err.Error()
+	}
+	return fmt.Sprintf("HTTP %d", a.status)
+}
+
+func webhookDeliveryToResponse(d repository.WebhookDelivery) WebhookDeliveryResponse {
+	resp := WebhookDeliveryResponse{
+		ID:          d.ID.String(),
+		WebhookID:   d.WebhookID.String(),
+		TriggerType: d.TriggerType,
+		Status:      d.Status,
+		Attempts:    int(d.Attempts),
+		CreatedAt:   d.CreatedAt.Format(time.RFC3339),
+	}
+	if d.Status == "pending" {
+		s := d.NextAttemptAt.Format(time.RFC3339)
+		resp.NextAttemptAt = &s
+	}
+	if d.RedeliveryOf != nil {
+		s := d.RedeliveryOf.String()
+		resp.RedeliveryOf = &s
+	}
+	if d.DeliveredAt != nil {
+		s := d.DeliveredAt.Format(time.RFC3339)
+		resp.DeliveredAt = &s
+	}
+	return resp
+}
+
+func optionalInt(v *int32) *int {
+	if v == nil {
+		return nil
+	}
+	i := int(*v)
+	return &i
+}
+
+// enqueue renders payload for the webhook and stores it for delivery. event
+// is the event the payload is about, if known.
+func (s *WebhookService) enqueue(ctx context.Context, wh repository.WebhookConfig, payload WebhookPayload, event *repository.Event) (repository.WebhookDelivery, error) {
+	body, err := s.renderWebhookPayload(wh, payload, event)
+	if err != nil {
+		return repository.WebhookDelivery{}, fmt.Errorf("rendering webhook payload: %w", err)
+	}
+	d, err := s.queries.CreateWebhookDelivery(ctx, repository.CreateWebhookDeliveryParams{
+		WebhookID:   wh.ID,
+		TriggerType: payload.Type,
+		Payload:     string(body),
+	})
+	if err != nil {
+		return repository.WebhookDelivery{}, err
+	}
+	s.wake()
+	return d, nil
+}
+
+// wake makes the worker deliver right away instead of at the next poll.
+func (s *WebhookService) wake() {
+	select {
+	case s.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+func (s *WebhookService) Start(ctx context.Context) {
+	timer := time.NewTimer(webhookPollInterval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			return
+		case <-s.wakeCh:
+		case <-timer.C:
+		}
+
+		delivered, err := s.DeliverDue(ctx)
+		if err != nil {
+			s.logger.Error("webhook delivery failed", "error", err)
+		} else if delivered > 0 {
+			s.logger.Debug("webhooks delivered", "count", delivered)
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(webhookPollInterval)
+	}
+}
+
+func (s *WebhookService) Stop() {
+	close(s.stopCh)
+}
+
+// DeliverDue sends queued deliveries whose next attempt is due and returns
+// how many succeeded.
+func (s *WebhookService) DeliverDue(ctx context.Context) (int, error) {
+	delivered := 0
+	for {
+		now := time.Now()
+		deliveries, err := s.queries.ClaimDueWebhookDeliveries(ctx, repository.ClaimDueWebhookDeliveriesParams{
+			Now:        now,
+			LeaseUntil: now.Add(webhookLease),
+			Limit:      webhookBatchSize,
+		})
+		if err != nil {
+			return delivered, fmt.Errorf("claiming webhook deliveries: %w", err)
+		}
+
+		for _, d := range deliveries {
+			if s.attempt(ctx, d) {
+				delivered++
+			}
+		}
+
+		if len(deliveries) < webhookBatchSize {
+			return delivered, nil
+		}
+	}
+}
+
+// attempt sends a claimed delivery, records the outcome and reports whether
+// it succeeded.
+func (s *WebhookService) attempt(ctx context.Context, d repository.ClaimDueWebhookDeliveriesRow) bool {
+	result := sendWebhook(ctx, s.httpClient, d)
+	attempt := d.Attempts + 1
+
+	record := repository.RecordWebhookDeliveryAttemptParams{
+		DeliveryID: d.ID,
+		Attempt:    attempt,
+		DurationMs: int32(result.duration.Milliseconds()),
+	}
+	if result.status != 0 {
+		status := int32(result.status)
+		record.ResponseStatus = &status
+	}
+	if result.body != "" {
+		record.ResponseBody = &result.body
+	}
+	if !result.ok() {
+		msg := result.errorMessage()
+		record.Error = &msg
+	}
+	if err := s.queries.RecordWebhookDeliveryAttempt(ctx, record); err != nil {
+		s.logger.Error("failed to record webhook attempt", "error", err, "delivery_id", d.ID)
+	}
+
+	if result.ok() {
+		if err := s.queries.MarkWebhookDeliveryDelivered(ctx, d.ID); err != nil {
+			s.logger.Error("failed to mark webhook delivered", "error", err, "delivery_id", d.ID)
+		}
+		if err := s.queries.ResetWebhookFailures(ctx, d.WebhookID); err != nil {
+			s.logger.Error("failed to reset webhook failures", "error", err, "webhook_id", d.WebhookID)
+		}
+		return true
+	}
+
+	giveUp := int(attempt) >= webhookMaxAttempts
+	if giveUp {
+		s.logger.Warn("giving up on webhook delivery", "delivery_id", d.ID, "webhook_id", d.WebhookID, "attempts", attempt, "error", result.errorMessage())
+	} else {
+		s.logger.Debug("webhook delivery failed, will retry", "delivery_id", d.ID, "webhook_id", d.WebhookID, "attempts", attempt, "error", result.errorMessage())
+	}
+	if err := s.queries.MarkWebhookDeliveryAttemptFailed(ctx, repository.MarkWebhookDeliveryAttemptFailedParams{
+		ID:            d.ID,
+		NextAttemptAt: time.Now().Add(webhookBackoff(int(attempt))),
+		GiveUp:        giveUp,
+	}); err != nil {
+		s.logger.Error("failed to record webhook failure", "error", err, "delivery_id", d.ID)
+	}
+	if giveUp {
+		s.recordFailure(ctx, d.WebhookID, result.errorMessage())
+	}
+	return false
+}
+
+// recordFailure counts a delivery that ran out of attempts against its
+// webhook, disabling the webhook once too many failed in a row.
+func (s *WebhookService) recordFailure(ctx context.Context, webhookID uuid.UUID, lastError string) {
+	failures, err := s.queries.IncrementWebhookFailures(ctx, webhookID)
+	if err != nil {
+		s.logger.Error("failed to count webhook failure", "error", err, "webhook_id", webhookID)
+		return
+	}
+	if s.disableAfter <= 0 || int(failures) < s.disableAfter {
+		return
+	}
+
+	reason := fmt.Sprintf("%d deliveries in a row failed; last error: %s", failures, lastError)
+	n, err := s.queries.DisableWebhook(ctx, webhookID, &reason)
+	if err != nil {
+		s.logger.Error("failed to disable webhook", "error", err, "webhook_id", webhookID)
+		return
+	}
+	if n == 0 {
+		return // already disabled
+	}
+	if err := s.queries.CancelPendingWebhookDeliveries(ctx, webhookID); err != nil {
+		s.logger.Error("failed to cancel webhook deliveries", "error", err, "webhook_id", webhookID)
+	}
+	s.logger.Warn("webhook disabled after repeated failures", "webhook_id", webhookID, "failures", failures, "error", lastError)
+
+	if s.notificationService != nil {
+		s.notifyDisabled(ctx, webhookID, lastError)
+	}
+}
+
+// notifyDisabled tells super admins, or the owner of a personal webhook, that
+// a webhook was disabled.
+func (s *WebhookService) notifyDisabled(ctx context.Context, webhookID uuid.UUID, lastError string) {
+	wh, err := s.queries.GetWebhookByID(ctx, webhookID)
+	if err != nil {
+		s.logger.Error("failed to get webhook", "error", err, "webhook_id", webhookID)
+		return
+	}
+
+	msg := NotificationMessage{
+		TriggerType: TriggerWebhookDisabled,
+		Title:       wh.Name,
+		Body:        lastError,
+	}
+	if wh.EventID != nil {
+		if event, err := s.queries.GetEventByID(ctx, *wh.EventID); err == nil {
+			msg.EventName = event.Name
+			msg.EventSlug = event.Slug
+		}
+	}
+	if wh.UserID != nil {
+		if err := s.notificationService.Notify(ctx, *wh.UserID, nil, msg); err != nil {
+			s.logger.Error("failed to notify webhook owner", "error", err, "webhook_id", webhookID)
+		}
+		return
+	}
+	s.notificationService.NotifySuperAdmins(ctx, wh.EventID, msg)
+}
+
+// sendWebhook posts a delivery's body to its webhook. Only formats that
+// expect it are signed; chat services ignore the signature. During a secret
+// rotation the body is signed with both secrets.
+func sendWebhook(ctx context.Context, client *http.Client, d repository.ClaimDueWebhookDeliveriesRow) webhookAttempt {
+	f := webhookFormatter(d.Format)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.Endpoint(d.Url), strings.NewReader(d.Payload))
+	if err != nil {
+		return webhookAttempt{err: fmt.Errorf("creating request: %w", err)}
+	}
+	for name, value := range decodeWebhookHeaders(d.Headers) {
+		req.Header.Set(name, value)
+	}
+	contentType := "application/json"
+	if d.ContentType != nil && *d.ContentType != "" {
+		contentType = *d.ContentType
+	}
+	req.Header.Set("Content-Type", contentType)
+	timestamp := time.Now().Unix()
+	req.Header.Set("X-Webhook-ID", d.WebhookID.String())
+	req.Header.Set("X-Webhook-Delivery", d.ID.String())
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	if f.Signed() {
+		secrets := []string{d.Secret}
+		if d.PreviousSecret != nil {
+			secrets = append(secrets, *d.PreviousSecret)
+		}
+		if sig := signWebhook(d.ID.String(), timestamp, []byte(d.Payload), secrets...); sig != "" {
+			req.Header.Set("X-Webhook-Signature", sig)
+		}
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return webhookAttempt{duration: time.Since(start), err: err}
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookMaxResponseBody))
+	return webhookAttempt{
+		status:   resp.StatusCode,
+		duration: time.Since(start),
+		body:     strings.ToValidUTF8(string(bytes.TrimSpace(body)), "�"),
+	}
+}
+
+// webhookBackoff returns the delay before the next attempt after the given
+// number of failed attempts: 30s, 1m, 2m, ... capped at webhookMaxBackoff.
+func webhookBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	d := 30 * time.Second
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= webhookMaxBackoff {
+			return webhookMaxBackoff
+		}
+	}
+	return d
+}
+
+// ListDeliveries returns a webhook's deliveries, newest first.
+func (s *WebhookService) ListDeliveries(ctx context.Context, webhookID uuid.UUID, limit, offset int32) ([]WebhookDeliveryResponse, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := s.queries.ListWebhookDeliveries(ctx, repository.ListWebhookDeliveriesParams{
+		WebhookID: webhookID,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing webhook deliveries: %w", err)
+	}
+
+	result := make([]WebhookDeliveryResponse, len(rows))
+	for i, r := range rows {
+		result[i] = webhookDeliveryToResponse(repository.WebhookDelivery{
+			ID:            r.ID,
+			WebhookID:     r.WebhookID,
+			TriggerType:   r.TriggerType,
+			Status:        r.Status,
+			Attempts:      r.Attempts,
+			NextAttemptAt: r.NextAttemptAt,
+			RedeliveryOf:  r.RedeliveryOf,
+			DeliveredAt:   r.DeliveredAt,
+			CreatedAt:     r.CreatedAt,
+		})
+		result[i].LastResponseStatus = optionalInt(r.LastResponseStatus)
+		result[i].LastDurationMs = optionalInt(r.LastDurationMs)
+		result[i].LastError = r.LastError
+	}
+	return result, nil
+}
+
+// GetDelivery returns a delivery of the webhook with its payload and attempts.
+func (s *WebhookService) GetDelivery(ctx context.Context, webhookID, deliveryID uuid.UUID) (WebhookDeliveryDetailResponse, error) {
+	d, err := s.getDelivery(ctx, webhookID, deliveryID)
+	if err != nil {
+		return WebhookDeliveryDetailResponse{}, err
+	}
+	attempts, err := s.queries.ListWebhookDeliveryAttempts(ctx, d.ID)
+	if err != nil {
+		return WebhookDeliveryDetailResponse{}, fmt.Errorf("listing webhook delivery attempts: %w", err)
+	}
+
+	resp := WebhookDeliveryDetailResponse{
+		WebhookDeliveryResponse: webhookDeliveryToResponse(d),
+		Payload:                 d.Payload,
+		History:                 make([]WebhookDeliveryAttemptResponse, len(attempts)),
+	}
+	for i, a := range attempts {
+		resp.History[i] = WebhookDeliveryAttemptResponse{
+			Attempt:        int(a.Attempt),
+			ResponseStatus: optionalInt(a.ResponseStatus),
+			DurationMs:     int(a.DurationMs),
+			Error:          a.Error,
+			ResponseBody:   a.ResponseBody,
+			CreatedAt:      a.CreatedAt.Format(time.RFC3339),
+		}
+	}
+	if n := len(attempts); n > 0 {
+		last := resp.History[n-1]
+		resp.LastResponseStatus = last.ResponseStatus
+		resp.LastDurationMs = &last.DurationMs
+		resp.LastError = last.Error
+	}
+	return resp, nil
+}
+
+// Redeliver queues the body of an earlier delivery again, as a new delivery.
+// The body is sent as it was recorded, with a signature from the current
+// secret. Like test deliveries, redeliveries go out to disabled webhooks too.
+func (s *WebhookService) Redeliver(ctx context.Context, webhookID, deliveryID uuid.UUID) (WebhookDeliveryResponse, error) {
+	d, err := s.getDelivery(ctx, webhookID, deliveryID)
+	if err != nil {
+		return WebhookDeliveryResponse{}, err
+	}
+
+	redelivery, err := s.queries.CreateWebhookDelivery(ctx, repository.CreateWebhookDeliveryParams{
+		WebhookID:    d.WebhookID,
+		TriggerType:  d.TriggerType,
+		Payload:      d.Payload,
+		RedeliveryOf: &d.ID,
+	})
+	if err != nil {
+		return WebhookDeliveryResponse{}, fmt.Errorf("queueing webhook redelivery: %w", err)
+	}
+	s.wake()
+
+	s.logger.Info("webhook redelivery queued", "webhook_id", webhookID, "delivery_id", redelivery.ID, "redelivery_of", d.ID)
+	return webhookDeliveryToResponse(redelivery), nil
+}
+
+func (s *WebhookService) getDelivery(ctx context.Context, webhookID, deliveryID uuid.UUID) (repository.WebhookDelivery, error) {
+	d, err := s.queries.GetWebhookDelivery(ctx, deliveryID, webhookID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.WebhookDelivery{}, model.NewDomainError(model.ErrNotFound, "webhook delivery not found")
+		}
+		return repository.WebhookDelivery{}, fmt.Errorf("getting webhook delivery: %w", err)
+	}
+	return d, nil
+}
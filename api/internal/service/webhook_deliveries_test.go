@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+)
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{4, 4 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, webhookMaxBackoff},
+		{50, webhookMaxBackoff},
+	}
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempts); got != tt.want {
+			t.Errorf("webhookBackoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestSendWebhook(t *testing.T) {
+	var gotHeader http.Header
+	var gotBody string
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		w.WriteHeader(status)
+		io.WriteString(w, "  received \n")
+	}))
+	defer srv.Close()
+
+	d := repository.ClaimDueWebhookDeliveriesRow{
+		ID:        uuid.New(),
+		WebhookID: uuid.New(),
+		Payload:   `{"type":"shift.created"}`,
+		Url:       srv.URL,
+		Secret:    "s3cret",
+		Format:    "default",
+	}
+
+	result := sendWebhook(context.Background(), srv.Client(), d)
+	if !result.ok() || result.status != http.StatusOK || result.body != "received" {
+		t.Fatalf("result = %+v", result)
+	}
+	if gotBody != d.Payload {
+		t.Errorf("body = %q", gotBody)
+	}
+	if gotHeader.Get("X-Webhook-Delivery") != d.ID.String() || gotHeader.Get("X-Webhook-ID") != d.WebhookID.String() {
+		t.Errorf("headers = %v", gotHeader)
+	}
+	timestamp, err := strconv.ParseInt(gotHeader.Get("X-Webhook-Timestamp"), 10, 64)
+	if err != nil || time.Since(time.Unix(timestamp, 0)) > time.Minute {
+		t.Errorf("timestamp = %q", gotHeader.Get("X-Webhook-Timestamp"))
+	}
+	if sig := gotHeader.Get("X-Webhook-Signature"); sig != signWebhook(d.ID.String(), timestamp, []byte(d.Payload), "s3cret") {
+		t.Errorf("signature = %q", sig)
+	}
+
+	previous := "old"
+	d.PreviousSecret = &previous
+	sendWebhook(context.Background(), srv.Client(), d)
+	if sigs := strings.Fields(gotHeader.Get("X-Webhook-Signature")); len(sigs) != 2 {
+		t.Errorf("signatures during rotation = %q", sigs)
+	}
+	d.PreviousSecret = nil
+
+	contentType := "application/xml"
+	d.Format, d.ContentType, d.Headers = "template", &contentType, []byte(`{"Authorization":"Bearer abc"}`)
+	sendWebhook(context.Background(), srv.Client(), d)
+	if gotHeader.Get("Content-Type") != "application/xml" || gotHeader.Get("Authorization") != "Bearer abc" || gotHeader.Get("X-Webhook-Signature") == "" {
+		t.Errorf("template headers = %v", gotHeader)
+	}
+
+	d.Format, d.ContentType, d.Headers = "discord", nil, nil
+	sendWebhook(context.Background(), srv.Client(), d)
+	if gotHeader.Get("X-Webhook-Signature") != "" {
+		t.Error("discord webhooks should not be signed")
+	}
+
+	status = http.StatusBadGateway
+	result = sendWebhook(context.Background(), srv.Client(), d)
+	if result.ok() || result.errorMessage() != "HTTP 502" {
+		t.Errorf("502 result = %+v", result)
+	}
+
+	srv.Close()
+	result = sendWebhook(context.Background(), srv.Client(), d)
+	if result.ok() || result.status != 0 || result.err == nil {
+		t.Errorf("unreachable result = %+v", result)
+	}
+}
+
+func TestRenderWebhookPayload(t *testing.T) {
+	payload := WebhookPayload{Type: "event.locked", Timestamp: "2026-07-04T10:00:00Z", Data: map[string]string{"slug": "camp"}}
+
+	s := &WebhookService{baseURL: "https://rncasp.example"}
+	body, err := s.renderWebhookPayload(repository.WebhookConfig{Format: "default"}, payload, nil)
+	if err != nil || !strings.Contains(string(body), `"type":"event.locked"`) {
+		t.Errorf("default = %s, %v", body, err)
+	}
+	body, err = s.renderWebhookPayload(repository.WebhookConfig{Format: "discord"}, payload, nil)
+	if err != nil || !strings.HasPrefix(string(body), `{"embeds":`) {
+		t.Errorf("discord = %s, %v", body, err)
+	}
+}
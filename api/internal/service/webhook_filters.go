@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxWebhookFilterHours is the longest time window a webhook filter may have.
+const maxWebhookFilterHours = 24 * 366
+
+// WebhookFilters narrow the changes an event webhook is sent for. A filter
+// only applies to changes that carry the information it checks: a team
+// filter doesn't hold back event.updated, and a time window only applies to
+// shifts and coverage requirements.
+type WebhookFilters struct {
+	// Teams a change must concern, e.g. the team of a shift
+	TeamIDs []string `json:"team_ids"`
+	// Users a change must concern, e.g. the user of a shift
+	UserIDs []string `json:"user_ids"`
+	// Only send changes to shifts starting within this many hours from now
+	WithinHours *int `json:"within_hours"`
+}
+
+// webhookFilter is a validated WebhookFilters.
+type webhookFilter struct {
+	teamIDs     []uuid.UUID
+	userIDs     []uuid.UUID
+	withinHours *int32
+}
+
+func webhookFiltersToResponse(w repository.WebhookConfig) WebhookFilters {
+	f := WebhookFilters{
+		TeamIDs: uuidStrings(w.FilterTeamIds),
+		UserIDs: uuidStrings(w.FilterUserIds),
+	}
+	if w.FilterWithinHours != nil {
+		hours := int(*w.FilterWithinHours)
+		f.WithinHours = &hours
+	}
+	return f
+}
+
+// parseWebhookFilters validates the filters of an event webhook. Teams must
+// take part in the event.
+func (s *WebhookService) parseWebhookFilters(ctx context.Context, eventID uuid.UUID, input *WebhookFilters) (webhookFilter, error) {
+	filter := webhookFilter{teamIDs: []uuid.UUID{}, userIDs: []uuid.UUID{}}
+	if input == nil {
+		return filter, nil
+	}
+
+	if len(input.TeamIDs) > 0 {
+		eventTeams, err := s.queries.ListEventTeams(ctx, eventID)
+		if err != nil {
+			return webhookFilter{}, fmt.Errorf("listing event teams: %w", err)
+		}
+		inEvent := make(map[uuid.UUID]bool, len(eventTeams))
+		for _, t := range eventTeams {
+			inEvent[t.ID] = true
+		}
+		for _, raw := range input.TeamIDs {
+			id, err := uuid.Parse(raw)
+			if err != nil || !inEvent[id] {
+				return webhookFilter{}, model.NewFieldError(model.ErrInvalidInput, "filters.team_ids", "unknown team: "+raw)
+			}
+			if !slices.Contains(filter.teamIDs, id) {
+				filter.teamIDs = append(filter.teamIDs, id)
+			}
+		}
+	}
+
+	for _, raw := range input.UserIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return webhookFilter{}, model.NewFieldError(model.ErrInvalidInput, "filters.user_ids", "invalid user ID: "+raw)
+		}
+		if _, err := s.queries.GetUserByID(ctx, id); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return webhookFilter{}, model.NewFieldError(model.ErrInvalidInput, "filters.user_ids", "unknown user: "+raw)
+			}
+			return webhookFilter{}, fmt.Errorf("getting user: %w", err)
+		}
+		if !slices.Contains(filter.userIDs, id) {
+			filter.userIDs = append(filter.userIDs, id)
+		}
+	}
+
+	if input.WithinHours != nil {
+		if *input.WithinHours < 1 || *input.WithinHours > maxWebhookFilterHours {
+			return webhookFilter{}, model.NewFieldError(model.ErrInvalidInput, "filters.within_hours", fmt.Sprintf("must be between 1 and %d", maxWebhookFilterHours))
+		}
+		hours := int32(*input.WithinHours)
+		filter.withinHours = &hours
+	}
+	return filter, nil
+}
+
+// checkNoWebhookFilters rejects filters on global webhooks, whose changes
+// aren't about shifts.
+func checkNoWebhookFilters(input *WebhookFilters) error {
+	if input != nil && (len(input.TeamIDs) > 0 || len(input.UserIDs) > 0 || input.WithinHours != nil) {
+		return model.NewFieldError(model.ErrInvalidInput, "filters", "filters are only available for event webhooks")
+	}
+	return nil
+}
+
+// webhookSubject is what a change is about, as far as filters are concerned.
+// Empty fields mean the change doesn't say. previous is set for changes that
+// moved something, so a filter can match where it was as well as where it is.
+type webhookSubject struct {
+	teamIDs  []uuid.UUID
+	userIDs  []uuid.UUID
+	start    *time.Time
+	previous *webhookSubject
+}
+
+// webhookSubjectOf returns the subject of the data of a webhook payload.
+func webhookSubjectOf(data any) webhookSubject {
+	var subject webhookSubject
+	addTeam := func(raw string) {
+		if id, err := uuid.Parse(raw); err == nil {
+			subject.teamIDs = append(subject.teamIDs, id)
+		}
+	}
+	setStart := func(raw string) {
+		if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			subject.start = &t
+		}
+	}
+
+	switch d := data.(type) {
+	case ShiftUpdate:
+		subject = webhookSubjectOf(d.ShiftResponse)
+		subject.previous = new(webhookSubject)
+		*subject.previous = webhookSubjectOf(ShiftResponse{TeamID: d.Previous.TeamID, UserID: d.Previous.UserID, StartTime: d.Previous.StartTime})
+	case ShiftResponse:
+		addTeam(d.TeamID)
+		if id, err := uuid.Parse(d.UserID); err == nil {
+			subject.userIDs = append(subject.userIDs, id)
+		}
+		setStart(d.StartTime)
+	case CoverageChange:
+		if d.Coverage != nil {
+			addTeam(d.Coverage.TeamID)
+			setStart(d.Coverage.StartTime)
+		} else {
+			addTeam(d.TeamID)
+		}
+	case AvailabilityChange:
+		if id, err := uuid.Parse(d.UserID); err == nil {
+			subject.userIDs = append(subject.userIDs, id)
+		}
+	case EventTeamChange:
+		addTeam(d.TeamID)
+	case AnnouncementResponse:
+		// Announcements without teams go to everyone
+		for _, raw := range d.TeamIDs {
+			addTeam(raw)
+		}
+	}
+	return subject
+}
+
+// matches reports whether a webhook's filters let a change through at now. A
+// change that moved something matches if either its old or its new state does,
+// so a shift leaving a filtered team or user is reported too.
+func (subject webhookSubject) matches(wh repository.WebhookConfig, now time.Time) bool {
+	if subject.previous != nil && subject.previous.matches(wh, now) {
+		return true
+	}
+	if len(wh.FilterTeamIds) > 0 && len(subject.teamIDs) > 0 && !overlaps(wh.FilterTeamIds, subject.teamIDs) {
+		return false
+	}
+	if len(wh.FilterUserIds) > 0 && len(subject.userIDs) > 0 && !overlaps(wh.FilterUserIds, subject.userIDs) {
+		return false
+	}
+	if wh.FilterWithinHours != nil && subject.start != nil {
+		until := now.Add(time.Duration(*wh.FilterWithinHours) * time.Hour)
+		if subject.start.Before(now) || subject.start.After(until) {
+			return false
+		}
+	}
+	return true
+}
+
+func overlaps(a, b []uuid.UUID) bool {
+	for _, id := range a {
+		if slices.Contains(b, id) {
+			return true
+		}
+	}
+	return false
+}
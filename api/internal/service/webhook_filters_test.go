@@ -0,0 +1,69 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+)
+
+func TestWebhookSubjectMatches(t *testing.T) {
+	now := time.Date(2026, 7, 10, 12, 0, 0, 0, time.UTC)
+	bar, stage := uuid.New(), uuid.New()
+	alice, bob := uuid.New(), uuid.New()
+	hours := int32(24)
+
+	shift := func(team, user uuid.UUID, start time.Time) ShiftResponse {
+		return ShiftResponse{TeamID: team.String(), UserID: user.String(), StartTime: start.Format(time.RFC3339)}
+	}
+	moved := func(after, before ShiftResponse) ShiftUpdate {
+		return ShiftUpdate{ShiftResponse: after, Previous: ShiftPlacement{TeamID: before.TeamID, UserID: before.UserID, StartTime: before.StartTime}}
+	}
+
+	tests := []struct {
+		name string
+		wh   repository.WebhookConfig
+		data any
+		want bool
+	}{
+		{"no filters", repository.WebhookConfig{}, shift(stage, bob, now), true},
+		{"team match", repository.WebhookConfig{FilterTeamIds: []uuid.UUID{bar}}, shift(bar, bob, now), true},
+		{"other team", repository.WebhookConfig{FilterTeamIds: []uuid.UUID{bar}}, shift(stage, bob, now), false},
+		{"user match", repository.WebhookConfig{FilterUserIds: []uuid.UUID{alice, bob}}, shift(stage, bob, now), true},
+		{"other user", repository.WebhookConfig{FilterUserIds: []uuid.UUID{alice}}, shift(stage, bob, now), false},
+		{"team and other user", repository.WebhookConfig{FilterTeamIds: []uuid.UUID{bar}, FilterUserIds: []uuid.UUID{alice}}, shift(bar, bob, now), false},
+		{"within window", repository.WebhookConfig{FilterWithinHours: &hours}, shift(bar, bob, now.Add(23*time.Hour)), true},
+		{"after window", repository.WebhookConfig{FilterWithinHours: &hours}, shift(bar, bob, now.Add(25*time.Hour)), false},
+		{"already started", repository.WebhookConfig{FilterWithinHours: &hours}, shift(bar, bob, now.Add(-time.Hour)), false},
+		{"coverage of other team", repository.WebhookConfig{FilterTeamIds: []uuid.UUID{bar}}, CoverageChange{Action: "deleted", TeamID: stage.String()}, false},
+		{"availability of user", repository.WebhookConfig{FilterUserIds: []uuid.UUID{alice}}, AvailabilityChange{UserID: alice.String()}, true},
+		{"announcement to all teams", repository.WebhookConfig{FilterTeamIds: []uuid.UUID{bar}}, AnnouncementResponse{TeamIDs: []string{}}, true},
+		{"announcement to other team", repository.WebhookConfig{FilterTeamIds: []uuid.UUID{bar}}, AnnouncementResponse{TeamIDs: []string{stage.String()}}, false},
+		{"change without team", repository.WebhookConfig{FilterTeamIds: []uuid.UUID{bar}, FilterWithinHours: &hours}, map[string]string{"slug": "camp"}, true},
+		{"moved into team", repository.WebhookConfig{FilterTeamIds: []uuid.UUID{bar}}, moved(shift(bar, bob, now), shift(stage, bob, now)), true},
+		{"moved out of team", repository.WebhookConfig{FilterTeamIds: []uuid.UUID{bar}}, moved(shift(stage, bob, now), shift(bar, bob, now)), true},
+		{"moved between other teams", repository.WebhookConfig{FilterTeamIds: []uuid.UUID{bar}}, moved(shift(stage, bob, now), shift(stage, alice, now)), false},
+		{"reassigned away from user", repository.WebhookConfig{FilterUserIds: []uuid.UUID{alice}}, moved(shift(bar, bob, now), shift(bar, alice, now)), true},
+		{"moved out of window", repository.WebhookConfig{FilterWithinHours: &hours}, moved(shift(bar, bob, now.Add(48*time.Hour)), shift(bar, bob, now.Add(time.Hour))), true},
+		{"team of old state, user of new state", repository.WebhookConfig{FilterTeamIds: []uuid.UUID{bar}, FilterUserIds: []uuid.UUID{alice}}, moved(shift(stage, alice, now), shift(bar, bob, now)), false},
+	}
+	for _, tt := range tests {
+		if got := webhookSubjectOf(tt.data).matches(tt.wh, now); got != tt.want {
+			t.Errorf("%s: matches = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckNoWebhookFilters(t *testing.T) {
+	hours := 4
+	if err := checkNoWebhookFilters(nil); err != nil {
+		t.Errorf("nil filters: %v", err)
+	}
+	if err := checkNoWebhookFilters(&WebhookFilters{TeamIDs: []string{}}); err != nil {
+		t.Errorf("empty filters: %v", err)
+	}
+	if err := checkNoWebhookFilters(&WebhookFilters{WithinHours: &hours}); err == nil {
+		t.Error("time window on global webhook accepted")
+	}
+}
@@ -0,0 +1,490 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/repository"
+)
+
+// WebhookFormatter renders webhook payloads for one kind of receiver, such as
+// a chat service. Formatters are registered by name with
+// RegisterWebhookFormat; the name is stored as the webhook's format.
+type WebhookFormatter interface {
+	// Render returns the request body for a message.
+	Render(msg WebhookMessage) ([]byte, error)
+	// ValidateURL checks that a webhook URL suits the receiver. The scheme
+	// and host have already been checked.
+	ValidateURL(u *url.URL) error
+	// Endpoint returns the URL requests are posted to.
+	Endpoint(webhookURL string) string
+	// Signed reports whether requests carry the HMAC signature header, in
+	// which case webhooks of this format need a secret.
+	Signed() bool
+}
+
+// WebhookField is a labelled detail of a webhook message.
+type WebhookField struct {
+	Name  string
+	Value string
+}
+
+// WebhookMessage is a webhook payload together with a human-readable summary
+// for chat formats.
+type WebhookMessage struct {
+	Payload   WebhookPayload
+	Webhook   repository.WebhookConfig
+	EventName string
+	EventSlug string // empty for payloads not about an event
+	Title     string
+	Text      string
+	Fields    []WebhookField
+	Link      string // event grid, empty without a base URL or event
+}
+
+var webhookFormats = map[string]WebhookFormatter{}
+
+// RegisterWebhookFormat makes a formatter available under name.
+func RegisterWebhookFormat(name string, f WebhookFormatter) {
+	webhookFormats[name] = f
+}
+
+func init() {
+	RegisterWebhookFormat("default", defaultWebhookFormat{})
+	RegisterWebhookFormat("discord", discordWebhookFormat{})
+	RegisterWebhookFormat("slack", slackWebhookFormat{})
+	RegisterWebhookFormat("teams", teamsWebhookFormat{})
+	RegisterWebhookFormat("matrix", matrixWebhookFormat{})
+	RegisterWebhookFormat("mattermost", mattermostWebhookFormat{})
+	RegisterWebhookFormat("ntfy", ntfyWebhookFormat{})
+	RegisterWebhookFormat("template", templateWebhookFormat{})
+}
+
+// webhookFormatNames returns the registered format names, sorted.
+func webhookFormatNames() []string {
+	names := make([]string, 0, len(webhookFormats))
+	for name := range webhookFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// webhookFormatter returns the formatter for format, falling back to the
+// default JSON format for unknown names.
+func webhookFormatter(format string) WebhookFormatter {
+	if f, ok := webhookFormats[format]; ok {
+		return f
+	}
+	return defaultWebhookFormat{}
+}
+
+// webhookConfigValidator is implemented by formats with settings beyond the
+// URL and secret.
+type webhookConfigValidator interface {
+	ValidateConfig(wh repository.WebhookConfig) error
+}
+
+// validateWebhook checks a webhook's format, URL, secret and format settings
+// together.
+func validateWebhook(wh repository.WebhookConfig) error {
+	f, ok := webhookFormats[wh.Format]
+	if !ok {
+		return model.NewFieldError(model.ErrInvalidInput, "format", "unsupported format, must be one of "+strings.Join(webhookFormatNames(), ", "))
+	}
+	u, err := url.Parse(wh.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return model.NewFieldError(model.ErrInvalidInput, "url", "must be an http or https URL")
+	}
+	if err := f.ValidateURL(u); err != nil {
+		return model.NewFieldError(model.ErrInvalidInput, "url", err.Error())
+	}
+	if f.Signed() && wh.Secret == "" {
+		return model.NewFieldError(model.ErrInvalidInput, "secret", "secret is required for "+wh.Format+" format")
+	}
+	if v, ok := f.(webhookConfigValidator); ok {
+		return v.ValidateConfig(wh)
+	}
+	if wh.Template != nil || wh.ContentType != nil || wh.Headers != nil {
+		return model.NewFieldError(model.ErrInvalidInput, "template", "template, content type and headers are only used by the template format")
+	}
+	return nil
+}
+
+// describeWebhook summarizes a payload for people. event is the webhook
+// event, if any; baseURL is used for links to the event.
+func describeWebhook(payload WebhookPayload, event *repository.Event, baseURL string) WebhookMessage {
+	msg := WebhookMessage{Payload: payload, Title: payload.Type}
+	data := webhookData(payload.Data)
+
+	var eventName, slug, timeZone string
+	if event != nil {
+		eventName, slug, timeZone = event.Name, event.Slug, event.Timezone
+	} else if strings.HasPrefix(payload.Type, "event.") {
+		eventName, slug = data["name"], data["slug"]
+	}
+	if slug == "" {
+		slug = data["slug"]
+	}
+	msg.EventName, msg.EventSlug = eventName, slug
+	if baseURL = strings.TrimRight(baseURL, "/"); baseURL != "" && slug != "" {
+		msg.Link = baseURL + "/events/" + slug
+	}
+
+	switch payload.Type {
+	case TriggerShiftCreated, TriggerShiftUpdated, TriggerShiftDeleted:
+		nm := NotificationMessage{
+			TriggerType: payload.Type,
+			EventName:   eventName,
+			Username:    data["username"],
+			TeamName:    data["team_name"],
+			TimeZone:    timeZone,
+		}
+		nm.Start, _ = time.Parse(time.RFC3339, data["start_time"])
+		nm.End, _ = time.Parse(time.RFC3339, data["end_time"])
+		if nm.Username == "" || nm.Start.IsZero() {
+			msg.Title = eventName + ": " + payload.Type
+			break
+		}
+		msg.Title, msg.Text = nm.Render("en")
+		msg.Fields = []WebhookField{
+			{"Who", nm.Username},
+			{"Team", nm.TeamName},
+			{"When", nm.timeRange("en")},
+		}
+	case TriggerEventLocked, TriggerEventUnlocked:
+		msg.Title, msg.Text = NotificationMessage{TriggerType: payload.Type, EventName: eventName}.Render("en")
+	case TriggerAnnouncement:
+		msg.Title, msg.Text = NotificationMessage{TriggerType: payload.Type, EventName: eventName, Title: data["title"], Body: data["body"]}.Render("en")
+	case "event.created":
+		msg.Title, msg.Text = "New event: "+eventName, fmt.Sprintf("Event \"%s\" was created", eventName)
+	case "event.updated":
+		msg.Title, msg.Text = eventName+": Updated", fmt.Sprintf("Event \"%s\" was updated", eventName)
+	case "event.deleted":
+		msg.Title, msg.Text, msg.Link = "Event deleted: "+eventName, fmt.Sprintf("Event \"%s\" was deleted", eventName), ""
+	case "event.admin_added":
+		msg.Title, msg.Text = eventName+": Admin added", "A user was made an admin of this event"
+	case "event.admin_removed":
+		msg.Title, msg.Text = eventName+": Admin removed", "A user is no longer an admin of this event"
+	case TriggerEventVisibilityChange:
+		visibility := "private"
+		if data["is_public"] == "true" {
+			visibility = "public"
+		}
+		msg.Title, msg.Text = eventName+": Visibility changed", "The event is now "+visibility
+	case TriggerEventTeamChanged:
+		switch {
+		case data["removed"] == "true":
+			msg.Text = fmt.Sprintf("Team \"%s\" was removed from this event", data["team_name"])
+		case data["is_visible"] == "true":
+			msg.Text = fmt.Sprintf("Team \"%s\" is shown in this event", data["team_name"])
+		default:
+			msg.Text = fmt.Sprintf("Team \"%s\" is hidden in this event", data["team_name"])
+		}
+		msg.Title = eventName + ": Teams changed"
+	case TriggerEventHiddenRanges:
+		msg.Title, msg.Text = eventName+": Hidden hours changed", "The hours hidden in the grid were changed"
+	case TriggerEventPinnedAdded:
+		msg.Title, msg.Text = eventName+": User pinned", "A user was pinned to the grid of this event"
+	case TriggerEventPinnedRemoved:
+		msg.Title, msg.Text = eventName+": User unpinned", "A user was unpinned from the grid of this event"
+	case TriggerCoverageUpdated:
+		msg.Title, msg.Text = eventName+": Coverage updated", fmt.Sprintf("A coverage requirement was %s", data["action"])
+	case TriggerAvailabilityUpdated:
+		msg.Title = eventName + ": Availability updated"
+		if data["source"] == availabilitySourceICal {
+			msg.Text = data["username"] + " imported a calendar"
+		} else {
+			msg.Text = data["username"] + " updated their availability"
+		}
+	case TriggerTeamCreated:
+		msg.Title, msg.Text = "New team: "+data["name"], fmt.Sprintf("Team \"%s\" was created", data["name"])
+	case TriggerTeamUpdated:
+		msg.Title, msg.Text = "Team updated: "+data["name"], fmt.Sprintf("Team \"%s\" was updated", data["name"])
+	case TriggerTeamDeleted:
+		msg.Title, msg.Text = "Team deleted: "+data["name"], fmt.Sprintf("Team \"%s\" was deleted", data["name"])
+	case TriggerUserOAuthLogin:
+		msg.Title, msg.Text = "OAuth login: "+data["username"], fmt.Sprintf("%s logged in with %s", data["username"], data["provider"])
+	case "user.registered", "user.created":
+		msg.Title = "New user: " + data["username"]
+		if name := data["full_name"]; name != "" {
+			msg.Text = fmt.Sprintf("%s (%s) joined", name, data["username"])
+		} else {
+			msg.Text = data["username"] + " joined"
+		}
+	case "user.updated":
+		msg.Title, msg.Text = "User updated: "+data["username"], ""
+	case "settings.changed":
+		msg.Title, msg.Text = "Settings changed", fmt.Sprintf("The setting \"%s\" was changed", data["key"])
+	case "webhook.test":
+		msg.Title, msg.Text = "Test webhook", data["message"]
+	default:
+		if eventName != "" {
+			msg.Title = eventName + ": " + payload.Type
+		}
+		keys := make([]string, 0, len(data))
+		for k := range data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			msg.Fields = append(msg.Fields, WebhookField{k, data[k]})
+		}
+	}
+	if eventName != "" && event != nil {
+		msg.Fields = append(msg.Fields, WebhookField{"Event", eventName})
+	}
+	return msg
+}
+
+// webhookData flattens payload data to strings by field name.
+func webhookData(data any) map[string]string {
+	result := map[string]string{}
+	if data == nil {
+		return result
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return result
+	}
+	var m map[string]any
+	if json.Unmarshal(b, &m) != nil {
+		return result
+	}
+	for k, v := range m {
+		switch v := v.(type) {
+		case nil:
+		case string:
+			result[k] = v
+		default:
+			result[k] = fmt.Sprintf("%v", v)
+		}
+	}
+	return result
+}
+
+// messageLines returns the text and fields of a message as plain lines.
+func messageLines(msg WebhookMessage) []string {
+	var lines []string
+	if msg.Text != "" {
+		lines = append(lines, msg.Text)
+	}
+	for _, f := range msg.Fields {
+		if f.Value != "" {
+			lines = append(lines, f.Name+": "+f.Value)
+		}
+	}
+	return lines
+}
+
+// marshalChatJSON encodes v without escaping HTML characters, which chat
+// services don't need and which keeps the delivery log readable.
+func marshalChatJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// webhookEndpoint is embedded by formats that post to the webhook URL
+// unsigned and accept any URL.
+type webhookEndpoint struct{}
+
+func (webhookEndpoint) ValidateURL(*url.URL) error        { return nil }
+func (webhookEndpoint) Endpoint(webhookURL string) string { return webhookURL }
+func (webhookEndpoint) Signed() bool                      { return false }
+
+// defaultWebhookFormat posts the payload as JSON, signed with the secret.
+type defaultWebhookFormat struct{ webhookEndpoint }
+
+func (defaultWebhookFormat) Render(msg WebhookMessage) ([]byte, error) {
+	return json.Marshal(msg.Payload)
+}
+
+func (defaultWebhookFormat) Signed() bool { return true }
+
+type discordWebhookFormat struct{ webhookEndpoint }
+
+func (discordWebhookFormat) Render(msg WebhookMessage) ([]byte, error) {
+	return toDiscordPayload(msg.Payload)
+}
+
+// slackWebhookFormat renders Slack incoming webhook messages with Block Kit.
+type slackWebhookFormat struct{ webhookEndpoint }
+
+func (slackWebhookFormat) Render(msg WebhookMessage) ([]byte, error) {
+	esc := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace
+
+	text := "*" + esc(msg.Title) + "*"
+	if msg.Text != "" {
+		text += "\n" + esc(msg.Text)
+	}
+	blocks := []map[string]any{
+		{"type": "section", "text": map[string]string{"type": "mrkdwn", "text": text}},
+	}
+
+	var fields []map[string]string
+	for _, f := range msg.Fields {
+		if f.Value != "" && len(fields) < 10 { // Slack allows 10 fields per section
+			fields = append(fields, map[string]string{"type": "mrkdwn", "text": "*" + esc(f.Name) + "*\n" + esc(f.Value)})
+		}
+	}
+	if len(fields) > 0 {
+		blocks = append(blocks, map[string]any{"type": "section", "fields": fields})
+	}
+	if msg.Link != "" {
+		blocks = append(blocks, map[string]any{
+			"type": "actions",
+			"elements": []map[string]any{{
+				"type": "button",
+				"text": map[string]string{"type": "plain_text", "text": "Open schedule"},
+				"url":  msg.Link,
+			}},
+		})
+	}
+
+	fallback := msg.Title
+	if msg.Text != "" {
+		fallback += ": " + msg.Text
+	}
+	return marshalChatJSON(map[string]any{"text": fallback, "blocks": blocks})
+}
+
+// mattermostWebhookFormat renders Mattermost incoming webhook messages in
+// Markdown.
+type mattermostWebhookFormat struct{ webhookEndpoint }
+
+func (mattermostWebhookFormat) Render(msg WebhookMessage) ([]byte, error) {
+	var b strings.Builder
+	b.WriteString("#### " + msg.Title)
+	for _, line := range messageLines(msg) {
+		b.WriteString("\n" + line)
+	}
+	if msg.Link != "" {
+		b.WriteString("\n[Open schedule](" + msg.Link + ")")
+	}
+	return marshalChatJSON(map[string]string{"text": b.String()})
+}
+
+// teamsWebhookFormat renders an Adaptive Card, as accepted by Teams
+// workflows ("Post to a channel when a webhook request is received") and
+// incoming webhook connectors.
+type teamsWebhookFormat struct{ webhookEndpoint }
+
+func (teamsWebhookFormat) Render(msg WebhookMessage) ([]byte, error) {
+	body := []map[string]any{
+		{"type": "TextBlock", "text": msg.Title, "weight": "Bolder", "size": "Medium", "wrap": true},
+	}
+	if msg.Text != "" {
+		body = append(body, map[string]any{"type": "TextBlock", "text": msg.Text, "wrap": true})
+	}
+	var facts []map[string]string
+	for _, f := range msg.Fields {
+		if f.Value != "" {
+			facts = append(facts, map[string]string{"title": f.Name, "value": f.Value})
+		}
+	}
+	if len(facts) > 0 {
+		body = append(body, map[string]any{"type": "FactSet", "facts": facts})
+	}
+
+	card := map[string]any{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+	}
+	if msg.Link != "" {
+		card["actions"] = []map[string]string{{"type": "Action.OpenUrl", "title": "Open schedule", "url": msg.Link}}
+	}
+	return marshalChatJSON(map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content":     card,
+		}},
+	})
+}
+
+// matrixWebhookFormat renders messages for Matrix webhook bridges such as
+// matrix-hookshot, which post a plain-text and an HTML body to a room.
+type matrixWebhookFormat struct{ webhookEndpoint }
+
+func (matrixWebhookFormat) Render(msg WebhookMessage) ([]byte, error) {
+	lines := messageLines(msg)
+
+	text := msg.Title
+	htmlBody := "<strong>" + html.EscapeString(msg.Title) + "</strong>"
+	for _, line := range lines {
+		text += "\n" + line
+		htmlBody += "<br>" + html.EscapeString(line)
+	}
+	if msg.Link != "" {
+		text += "\n" + msg.Link
+		htmlBody += `<br><a href="` + html.EscapeString(msg.Link) + `">Open schedule</a>`
+	}
+	return marshalChatJSON(map[string]string{"text": text, "html": htmlBody})
+}
+
+// ntfyWebhookFormat publishes to an ntfy topic. The webhook URL is the topic
+// URL; messages are posted as JSON to the server's root URL.
+type ntfyWebhookFormat struct{}
+
+func (ntfyWebhookFormat) Render(msg WebhookMessage) ([]byte, error) {
+	_, topic := splitNtfyURL(msg.Webhook.Url)
+	body := map[string]any{
+		"topic":   topic,
+		"title":   msg.Title,
+		"message": strings.Join(messageLines(msg), "\n"),
+		"tags":    []string{"calendar"},
+	}
+	if body["message"] == "" {
+		body["message"] = msg.Title
+	}
+	if msg.Link != "" {
+		body["click"] = msg.Link
+	}
+	return marshalChatJSON(body)
+}
+
+func (ntfyWebhookFormat) ValidateURL(u *url.URL) error {
+	if _, topic := splitNtfyURL(u.String()); topic == "" {
+		return fmt.Errorf("must be an ntfy topic URL, like https://ntfy.sh/mytopic")
+	}
+	return nil
+}
+
+func (ntfyWebhookFormat) Endpoint(webhookURL string) string {
+	root, _ := splitNtfyURL(webhookURL)
+	return root
+}
+
+func (ntfyWebhookFormat) Signed() bool { return false }
+
+// splitNtfyURL splits a topic URL into the server's root URL, keeping any
+// query such as ?auth=, and the topic.
+func splitNtfyURL(rawURL string) (string, string) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL, ""
+	}
+	path := strings.TrimRight(u.Path, "/")
+	i := strings.LastIndex(path, "/")
+	if i < 0 {
+		return rawURL, ""
+	}
+	topic := path[i+1:]
+	u.Path = path[:i+1]
+	u.RawPath = ""
+	return u.String(), topic
+}
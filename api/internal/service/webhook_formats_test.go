@@ -0,0 +1,131 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/repository"
+)
+
+func testShiftMessage() WebhookMessage {
+	payload := WebhookPayload{
+		Type:      TriggerShiftCreated,
+		EventID:   "7b0c3c40-0000-0000-0000-000000000000",
+		Timestamp: "2026-07-04T10:00:00Z",
+		Data: ShiftResponse{
+			Username:  "alice",
+			TeamName:  "Bar <North>",
+			StartTime: "2026-07-04T12:00:00Z",
+			EndTime:   "2026-07-04T14:00:00Z",
+		},
+	}
+	event := &repository.Event{Name: "Camp", Slug: "camp", Timezone: "Europe/Berlin"}
+	return describeWebhook(payload, event, "https://rncasp.example/")
+}
+
+func TestDescribeWebhook(t *testing.T) {
+	msg := testShiftMessage()
+	if msg.Link != "https://rncasp.example/events/camp" {
+		t.Errorf("link = %q", msg.Link)
+	}
+	if !strings.Contains(msg.Title, "Camp") || !strings.Contains(msg.Text, "alice") {
+		t.Errorf("title, text = %q, %q", msg.Title, msg.Text)
+	}
+	fields := map[string]string{}
+	for _, f := range msg.Fields {
+		fields[f.Name] = f.Value
+	}
+	if fields["Who"] != "alice" || fields["Team"] != "Bar <North>" || fields["Event"] != "Camp" {
+		t.Errorf("fields = %v", msg.Fields)
+	}
+	// Times are shown in the event's time zone.
+	if !strings.Contains(fields["When"], "14:00") || !strings.Contains(fields["When"], "16:00") {
+		t.Errorf("when = %q", fields["When"])
+	}
+
+	noBase := describeWebhook(WebhookPayload{Type: "event.locked"}, &repository.Event{Name: "Camp", Slug: "camp"}, "")
+	if noBase.Link != "" {
+		t.Errorf("link without base URL = %q", noBase.Link)
+	}
+	global := describeWebhook(WebhookPayload{Type: "event.created", Data: map[string]string{"name": "Camp", "slug": "camp"}}, nil, "https://rncasp.example")
+	if global.Title != "New event: Camp" || global.Link != "https://rncasp.example/events/camp" {
+		t.Errorf("global = %+v", global)
+	}
+}
+
+func TestWebhookFormatsRender(t *testing.T) {
+	msg := testShiftMessage()
+	msg.Webhook.Url = "https://ntfy.example/shifts"
+
+	for _, name := range webhookFormatNames() {
+		if name == "template" {
+			continue // covered by TestWebhookTemplate
+		}
+		body, err := webhookFormats[name].Render(msg)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !json.Valid(body) {
+			t.Errorf("%s: invalid JSON %s", name, body)
+		}
+	}
+
+	slack, _ := webhookFormats["slack"].Render(msg)
+	if !strings.Contains(string(slack), `Bar &lt;North&gt;`) || !strings.Contains(string(slack), `"url":"https://rncasp.example/events/camp"`) {
+		t.Errorf("slack = %s", slack)
+	}
+	teams, _ := webhookFormats["teams"].Render(msg)
+	if !strings.Contains(string(teams), `"contentType":"application/vnd.microsoft.card.adaptive"`) || !strings.Contains(string(teams), `"FactSet"`) {
+		t.Errorf("teams = %s", teams)
+	}
+	matrix, _ := webhookFormats["matrix"].Render(msg)
+	if !strings.Contains(string(matrix), `Bar &lt;North&gt;`) {
+		t.Errorf("matrix html not escaped: %s", matrix)
+	}
+	ntfy, _ := webhookFormats["ntfy"].Render(msg)
+	if !strings.Contains(string(ntfy), `"topic":"shifts"`) || !strings.Contains(string(ntfy), `"click":"https://rncasp.example/events/camp"`) {
+		t.Errorf("ntfy = %s", ntfy)
+	}
+}
+
+func TestNtfyEndpoint(t *testing.T) {
+	f := webhookFormats["ntfy"]
+	if got := f.Endpoint("https://ntfy.example/shifts?auth=abc"); got != "https://ntfy.example/?auth=abc" {
+		t.Errorf("endpoint = %q", got)
+	}
+	if got := f.Endpoint("https://example.com/ntfy/shifts/"); got != "https://example.com/ntfy/" {
+		t.Errorf("endpoint with prefix = %q", got)
+	}
+}
+
+func TestValidateWebhook(t *testing.T) {
+	tests := []struct {
+		format, url, secret string
+		field               string // empty when valid
+	}{
+		{"default", "https://example.com/hook", "s3cret", ""},
+		{"default", "https://example.com/hook", "", "secret"},
+		{"slack", "https://hooks.slack.com/services/x", "", ""},
+		{"ntfy", "https://ntfy.sh/mytopic", "", ""},
+		{"ntfy", "https://ntfy.sh/", "", "url"},
+		{"teams", "ftp://example.com/hook", "", "url"},
+		{"teams", "not a url", "", "url"},
+		{"irc", "https://example.com/hook", "", "format"},
+	}
+	for _, tt := range tests {
+		err := validateWebhook(repository.WebhookConfig{Format: tt.format, Url: tt.url, Secret: tt.secret})
+		if tt.field == "" {
+			if err != nil {
+				t.Errorf("%s %s: unexpected error %v", tt.format, tt.url, err)
+			}
+			continue
+		}
+		var de *model.DomainError
+		if !errors.As(err, &de) || de.Field != tt.field {
+			t.Errorf("%s %s: error = %v, want field %s", tt.format, tt.url, err, tt.field)
+		}
+	}
+}
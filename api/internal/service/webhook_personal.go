@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxPersonalWebhooks is how many personal webhooks a user can have.
+const maxPersonalWebhooks = 5
+
+// ListByUser returns a user's personal webhooks.
+func (s *WebhookService) ListByUser(ctx context.Context, userID uuid.UUID) ([]WebhookResponse, error) {
+	webhooks, err := s.queries.ListWebhooksByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("listing personal webhooks: %w", err)
+	}
+
+	result := make([]WebhookResponse, len(webhooks))
+	for i, w := range webhooks {
+		result[i] = webhookToResponse(w)
+	}
+	return result, nil
+}
+
+// GetPersonal returns a personal webhook of a user. Other webhooks are
+// reported as not found.
+func (s *WebhookService) GetPersonal(ctx context.Context, userID, webhookID uuid.UUID) (WebhookResponse, error) {
+	webhook, err := s.queries.GetWebhookByID(ctx, webhookID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return WebhookResponse{}, fmt.Errorf("getting webhook: %w", err)
+	}
+	if err != nil || webhook.UserID == nil || *webhook.UserID != userID {
+		return WebhookResponse{}, model.NewDomainError(model.ErrNotFound, "webhook not found")
+	}
+	return webhookToResponse(webhook), nil
+}
+
+// CreatePersonal creates a webhook sent for changes to the user's own shifts
+// in any event. Any user can create these, so their templates are held to
+// the loop and time limits of parseWebhookTemplate like all others, and the
+// per-user limit is checked under a lock.
+func (s *WebhookService) CreatePersonal(ctx context.Context, userID uuid.UUID, input CreateWebhookInput) (WebhookResponse, error) {
+	if input.Name == "" {
+		return WebhookResponse{}, model.NewFieldError(model.ErrInvalidInput, "name", "name is required")
+	}
+	if input.URL == "" {
+		return WebhookResponse{}, model.NewFieldError(model.ErrInvalidInput, "url", "url is required")
+	}
+	format := input.Format
+	if format == "" {
+		format = "default"
+	}
+	headers := encodeWebhookHeaders(input.Headers)
+	if err := validateWebhook(repository.WebhookConfig{
+		Url:         input.URL,
+		Secret:      input.Secret,
+		Format:      format,
+		Template:    input.Template,
+		ContentType: input.ContentType,
+		Headers:     headers,
+	}); err != nil {
+		return WebhookResponse{}, err
+	}
+	if err := validateTriggerTypes(WebhookScopePersonal, input.TriggerTypes); err != nil {
+		return WebhookResponse{}, err
+	}
+	if err := checkNoWebhookFilters(input.Filters); err != nil {
+		return WebhookResponse{}, err
+	}
+	if err := s.checkURL(ctx, input.URL); err != nil {
+		return WebhookResponse{}, err
+	}
+
+	// Count and insert under the user's lock, so concurrent requests can't
+	// both pass the limit
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return WebhookResponse{}, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	q := s.queries.WithTx(tx)
+	if err := q.LockUserWebhooks(ctx, userID); err != nil {
+		return WebhookResponse{}, fmt.Errorf("locking personal webhooks: %w", err)
+	}
+
+	count, err := q.CountWebhooksByUser(ctx, userID)
+	if err != nil {
+		return WebhookResponse{}, fmt.Errorf("counting personal webhooks: %w", err)
+	}
+	if count >= maxPersonalWebhooks {
+		return WebhookResponse{}, model.NewDomainError(model.ErrInvalidInput, fmt.Sprintf("you can have at most %d personal webhooks", maxPersonalWebhooks))
+	}
+
+	webhook, err := q.CreatePersonalWebhook(ctx, repository.CreatePersonalWebhookParams{
+		UserID:       userID,
+		Name:         input.Name,
+		Url:          input.URL,
+		Secret:       input.Secret,
+		TriggerTypes: input.TriggerTypes,
+		Format:       format,
+		Template:     input.Template,
+		ContentType:  input.ContentType,
+		Headers:      headers,
+	})
+	if err != nil {
+		return WebhookResponse{}, fmt.Errorf("creating personal webhook: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return WebhookResponse{}, fmt.Errorf("committing personal webhook: %w", err)
+	}
+
+	s.logger.Info("personal webhook created", "webhook_id", webhook.ID, "user_id", userID)
+	return webhookToResponse(webhook), nil
+}
+
+// DispatchPersonal queues a webhook event for the personal webhooks of a user
+// who isn't in the data, like the previous assignee of a reassigned shift.
+func (s *WebhookService) DispatchPersonal(ctx context.Context, eventID, userID uuid.UUID, triggerType string, data any) {
+	payload := WebhookPayload{
+		Type:      triggerType,
+		EventID:   eventID.String(),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Data:      data,
+	}
+	s.dispatchPersonal(ctx, []uuid.UUID{userID}, payload, nil)
+}
+
+// dispatchPersonal queues a payload for the personal webhooks of users
+// subscribed to its trigger. event is the event the payload is about, if
+// known.
+func (s *WebhookService) dispatchPersonal(ctx context.Context, userIDs []uuid.UUID, payload WebhookPayload, event *repository.Event) {
+	if len(userIDs) == 0 || !slices.Contains(personalWebhookTriggers, payload.Type) {
+		return
+	}
+	webhooks, err := s.queries.ListActivePersonalWebhooksForTrigger(ctx, userIDs, payload.Type)
+	if err != nil {
+		s.logger.Error("failed to list personal webhooks for dispatch", "error", err)
+		return
+	}
+	if len(webhooks) > 0 && event == nil {
+		if eventID, err := uuid.Parse(payload.EventID); err == nil {
+			if e, err := s.queries.GetEventByID(ctx, eventID); err == nil {
+				event = &e
+			}
+		}
+	}
+	for _, wh := range webhooks {
+		if _, err := s.enqueue(ctx, wh, payload, event); err != nil {
+			s.logger.Error("failed to queue webhook delivery", "error", err, "webhook_id", wh.ID)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// webhookSecretRotationWindow is how long the previous secret keeps signing
+// deliveries after a rotation.
+const webhookSecretRotationWindow = 24 * time.Hour
+
+// signWebhook returns the X-Webhook-Signature header of a delivery: a
+// "v1=<hex HMAC-SHA256>" entry for every non-empty secret, separated by
+// spaces. The HMAC covers "<delivery ID>.<unix timestamp>.<body>", so a
+// captured request can't be replayed later or as another delivery.
+func signWebhook(deliveryID string, timestamp int64, body []byte, secrets ...string) string {
+	signed := make([]byte, 0, len(deliveryID)+len(body)+24)
+	signed = append(signed, deliveryID...)
+	signed = append(signed, '.')
+	signed = strconv.AppendInt(signed, timestamp, 10)
+	signed = append(signed, '.')
+	signed = append(signed, body...)
+
+	var sigs []string
+	for _, secret := range secrets {
+		if secret != "" {
+			sigs = append(sigs, "v1="+computeHMAC(signed, secret))
+		}
+	}
+	return strings.Join(sigs, " ")
+}
+
+// generateWebhookSecret returns a random secret for signing deliveries.
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// previousSecretExpiry returns when the previous secret of a webhook stops
+// signing deliveries, or nil if there is none at now.
+func previousSecretExpiry(wh repository.WebhookConfig, now time.Time) *string {
+	if wh.PreviousSecret == nil || wh.PreviousSecretExpiresAt == nil || !wh.PreviousSecretExpiresAt.After(now) {
+		return nil
+	}
+	s := wh.PreviousSecretExpiresAt.Format(time.RFC3339)
+	return &s
+}
+
+// WebhookSecretRotation is the result of rotating a webhook's secret. The new
+// secret is only ever returned here.
+type WebhookSecretRotation struct {
+	Secret                  string  `json:"secret"`
+	PreviousSecretExpiresAt *string `json:"previous_secret_expires_at"`
+}
+
+// RotateSecret replaces a webhook's secret with a generated one. Deliveries
+// are signed with both secrets for webhookSecretRotationWindow, so receivers
+// can be switched over without rejecting requests in between.
+func (s *WebhookService) RotateSecret(ctx context.Context, webhookID uuid.UUID) (WebhookSecretRotation, error) {
+	wh, err := s.queries.GetWebhookByID(ctx, webhookID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return WebhookSecretRotation{}, model.NewDomainError(model.ErrNotFound, "webhook not found")
+		}
+		return WebhookSecretRotation{}, fmt.Errorf("getting webhook: %w", err)
+	}
+	if !webhookFormatter(wh.Format).Signed() {
+		return WebhookSecretRotation{}, model.NewDomainError(model.ErrInvalidInput, fmt.Sprintf("%s webhooks are not signed", wh.Format))
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return WebhookSecretRotation{}, err
+	}
+	var expiresAt *time.Time
+	if wh.Secret != "" {
+		t := time.Now().Add(webhookSecretRotationWindow)
+		expiresAt = &t
+	}
+
+	updated, err := s.queries.RotateWebhookSecret(ctx, webhookID, secret, expiresAt)
+	if err != nil {
+		return WebhookSecretRotation{}, fmt.Errorf("rotating webhook secret: %w", err)
+	}
+
+	s.logger.Info("webhook secret rotated", "webhook_id", webhookID)
+	return WebhookSecretRotation{
+		Secret:                  secret,
+		PreviousSecretExpiresAt: previousSecretExpiry(updated, time.Now()),
+	}, nil
+}
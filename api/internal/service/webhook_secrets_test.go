@@ -0,0 +1,57 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/repository"
+)
+
+func TestSignWebhook(t *testing.T) {
+	body := []byte(`{"type":"shift.created"}`)
+	sig := signWebhook("d1", 1767225600, body, "s3cret")
+	if sig != "v1="+computeHMAC([]byte(`d1.1767225600.{"type":"shift.created"}`), "s3cret") {
+		t.Errorf("signature = %q", sig)
+	}
+
+	// The timestamp and delivery ID are part of the signature.
+	if signWebhook("d1", 1767225601, body, "s3cret") == sig || signWebhook("d2", 1767225600, body, "s3cret") == sig {
+		t.Error("signature doesn't cover timestamp and delivery ID")
+	}
+
+	both := signWebhook("d1", 1767225600, body, "s3cret", "old")
+	if !strings.HasPrefix(both, sig+" v1=") || len(strings.Fields(both)) != 2 {
+		t.Errorf("rotation signature = %q", both)
+	}
+	if signWebhook("d1", 1767225600, body, "") != "" {
+		t.Error("empty secret should not sign")
+	}
+}
+
+func TestGenerateWebhookSecret(t *testing.T) {
+	a, err := generateWebhookSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := generateWebhookSecret()
+	if a == b || !strings.HasPrefix(a, "whsec_") || len(a) != len("whsec_")+64 {
+		t.Errorf("secrets = %q, %q", a, b)
+	}
+}
+
+func TestPreviousSecretExpiry(t *testing.T) {
+	now := time.Now()
+	previous := "old"
+	later, earlier := now.Add(time.Hour), now.Add(-time.Hour)
+
+	if got := previousSecretExpiry(repository.WebhookConfig{PreviousSecret: &previous, PreviousSecretExpiresAt: &later}, now); got == nil || *got != later.Format(time.RFC3339) {
+		t.Errorf("active rotation = %v", got)
+	}
+	if got := previousSecretExpiry(repository.WebhookConfig{PreviousSecret: &previous, PreviousSecretExpiresAt: &earlier}, now); got != nil {
+		t.Errorf("expired rotation = %v", *got)
+	}
+	if got := previousSecretExpiry(repository.WebhookConfig{}, now); got != nil {
+		t.Errorf("no rotation = %v", *got)
+	}
+}
@@ -0,0 +1,385 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	webhookTemplateMaxSize   = 16 << 10 // template source
+	webhookTemplateMaxOutput = 64 << 10 // rendered body
+	webhookMaxHeaders        = 20
+
+	// Ranges can only go over payload data and nest this deep, which bounds
+	// the work a template does.
+	webhookTemplateMaxRangeDepth = 2
+)
+
+// webhookTemplateTimeout is how long a template may take to render, as a
+// last resort against templates that are slow anyway.
+var webhookTemplateTimeout = time.Second
+
+// Headers that templates can't set, because the delivery sets them or the
+// HTTP client manages them.
+var reservedWebhookHeaders = map[string]bool{
+	"Content-Type":        true,
+	"Content-Length":      true,
+	"Host":                true,
+	"Connection":          true,
+	"Transfer-Encoding":   true,
+	"X-Webhook-Id":        true,
+	"X-Webhook-Delivery":  true,
+	"X-Webhook-Signature": true,
+	"X-Webhook-Timestamp": true,
+}
+
+// WebhookTemplateData is what webhook templates are executed with.
+type WebhookTemplateData struct {
+	Type      string // trigger type, like shift.created
+	EventID   string // empty for global webhooks
+	Timestamp string
+	Data      any // trigger data with the field names of the default JSON format
+	Event     WebhookTemplateEvent
+	Title     string // human-readable summary, as in the chat formats
+	Text      string
+	Link      string // event grid
+}
+
+// WebhookTemplateEvent is the event a webhook template is rendered for.
+type WebhookTemplateEvent struct {
+	Name string
+	Slug string
+}
+
+var webhookTemplateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := marshalChatJSON(v)
+		return string(b), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"default": func(def, v any) any {
+		if v == nil || v == "" {
+			return def
+		}
+		return v
+	},
+	// formatTime reformats an RFC 3339 time from the payload with a Go layout.
+	"formatTime": func(layout string, v any) (string, error) {
+		s, _ := v.(string)
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return "", fmt.Errorf("formatTime: %q is not an RFC 3339 time", s)
+		}
+		return t.Format(layout), nil
+	},
+}
+
+// parseWebhookTemplate parses a webhook template body. Templates can't define
+// or call other templates, and ranges must go over payload data rather than
+// numbers or function results, so rendering can't loop for long.
+func parseWebhookTemplate(text string) (*template.Template, error) {
+	if len(text) > webhookTemplateMaxSize {
+		return nil, fmt.Errorf("template is longer than %d bytes", webhookTemplateMaxSize)
+	}
+	tmpl, err := template.New("webhook").Funcs(webhookTemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(tmpl.Templates()) > 1 {
+		return nil, errors.New("templates can't use define or block")
+	}
+	if tmpl.Tree != nil {
+		if err := checkWebhookTemplateNode(tmpl.Tree.Root, 0, map[string]bool{}); err != nil {
+			return nil, err
+		}
+	}
+	return tmpl, nil
+}
+
+// checkWebhookTemplateNode enforces the limits of parseWebhookTemplate.
+// computed holds variables that were set to something other than payload
+// data, like a number.
+func checkWebhookTemplateNode(node parse.Node, depth int, computed map[string]bool) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkWebhookTemplateNode(child, depth, computed); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		declareWebhookTemplateVars(n.Pipe, computed)
+	case *parse.TemplateNode:
+		return errors.New("templates can't call other templates")
+	case *parse.IfNode:
+		declareWebhookTemplateVars(n.Pipe, computed)
+		return checkWebhookTemplateBranch(&n.BranchNode, depth, computed)
+	case *parse.WithNode:
+		declareWebhookTemplateVars(n.Pipe, computed)
+		return checkWebhookTemplateBranch(&n.BranchNode, depth, computed)
+	case *parse.RangeNode:
+		if !isWebhookDataPipe(n.Pipe, computed) {
+			return errors.New("range can only go over payload data")
+		}
+		if depth == webhookTemplateMaxRangeDepth {
+			return fmt.Errorf("ranges can't nest more than %d deep", webhookTemplateMaxRangeDepth)
+		}
+		for _, v := range n.Pipe.Decl {
+			computed[v.Ident[0]] = false
+		}
+		return checkWebhookTemplateBranch(&n.BranchNode, depth+1, computed)
+	}
+	return nil
+}
+
+// declareWebhookTemplateVars records the variables a pipeline sets.
+func declareWebhookTemplateVars(pipe *parse.PipeNode, computed map[string]bool) {
+	data := isWebhookDataPipe(pipe, computed)
+	for _, v := range pipe.Decl {
+		computed[v.Ident[0]] = !data
+	}
+}
+
+func checkWebhookTemplateBranch(n *parse.BranchNode, depth int, computed map[string]bool) error {
+	if err := checkWebhookTemplateNode(n.List, depth, computed); err != nil {
+		return err
+	}
+	return checkWebhookTemplateNode(n.ElseList, depth, computed)
+}
+
+// isWebhookDataPipe reports whether a pipeline is just a reference to
+// payload data, like .Data.entries or $entry.
+func isWebhookDataPipe(pipe *parse.PipeNode, computed map[string]bool) bool {
+	if len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+	switch arg := pipe.Cmds[0].Args[0].(type) {
+	case *parse.DotNode, *parse.FieldNode:
+		return true
+	case *parse.VariableNode:
+		return !computed[arg.Ident[0]]
+	}
+	return false
+}
+
+// executeWebhookTemplate renders a parsed template for a message.
+func executeWebhookTemplate(tmpl *template.Template, msg WebhookMessage) ([]byte, error) {
+	payload, err := json.Marshal(msg.Payload)
+	if err != nil {
+		return nil, err
+	}
+	var generic struct {
+		Data any `json:"data"`
+	}
+	if err := json.Unmarshal(payload, &generic); err != nil {
+		return nil, err
+	}
+
+	data := WebhookTemplateData{
+		Type:      msg.Payload.Type,
+		EventID:   msg.Payload.EventID,
+		Timestamp: msg.Payload.Timestamp,
+		Data:      generic.Data,
+		Event:     WebhookTemplateEvent{Name: msg.EventName, Slug: msg.EventSlug},
+		Title:     msg.Title,
+		Text:      msg.Text,
+		Link:      msg.Link,
+	}
+
+	// Execution can't be interrupted, so a template that runs over the
+	// timeout is left to finish in the background while its result is
+	// dropped.
+	type result struct {
+		body []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		w := &limitedBuffer{limit: webhookTemplateMaxOutput}
+		err := tmpl.Execute(w, data)
+		done <- result{w.Bytes(), err}
+	}()
+
+	timer := time.NewTimer(webhookTemplateTimeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return nil, r.err
+		}
+		return r.body, nil
+	case <-timer.C:
+		return nil, fmt.Errorf("template took longer than %s to render", webhookTemplateTimeout)
+	}
+}
+
+// limitedBuffer fails writes beyond limit bytes, stopping runaway templates.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		return 0, fmt.Errorf("rendered body is longer than %d bytes", b.limit)
+	}
+	return b.Buffer.Write(p)
+}
+
+// templateWebhookFormat renders the body with a template set on the webhook.
+type templateWebhookFormat struct{ webhookEndpoint }
+
+func (templateWebhookFormat) Render(msg WebhookMessage) ([]byte, error) {
+	if msg.Webhook.Template == nil {
+		return nil, errors.New("webhook has no template")
+	}
+	tmpl, err := parseWebhookTemplate(*msg.Webhook.Template)
+	if err != nil {
+		return nil, err
+	}
+	return executeWebhookTemplate(tmpl, msg)
+}
+
+// Template bodies are signed like the default format, as the receiver is
+// usually an integration of our own rather than a chat service.
+func (templateWebhookFormat) Signed() bool { return true }
+
+func (templateWebhookFormat) ValidateConfig(wh repository.WebhookConfig) error {
+	if wh.Template == nil || strings.TrimSpace(*wh.Template) == "" {
+		return model.NewFieldError(model.ErrInvalidInput, "template", "template is required for template format")
+	}
+	if _, err := parseWebhookTemplate(*wh.Template); err != nil {
+		return model.NewFieldError(model.ErrInvalidInput, "template", err.Error())
+	}
+	return validateWebhookTemplateHeaders(wh.ContentType, decodeWebhookHeaders(wh.Headers))
+}
+
+// validateWebhookTemplateHeaders checks the content type and extra headers of
+// a template webhook.
+func validateWebhookTemplateHeaders(contentType *string, headers map[string]string) error {
+	if contentType != nil && *contentType != "" {
+		if _, _, err := mime.ParseMediaType(*contentType); err != nil {
+			return model.NewFieldError(model.ErrInvalidInput, "content_type", "invalid content type")
+		}
+	}
+	if len(headers) > webhookMaxHeaders {
+		return model.NewFieldError(model.ErrInvalidInput, "headers", fmt.Sprintf("at most %d headers are allowed", webhookMaxHeaders))
+	}
+	for name, value := range headers {
+		if name == "" || strings.ContainsAny(name, " :\r\n\t") {
+			return model.NewFieldError(model.ErrInvalidInput, "headers", fmt.Sprintf("invalid header name %q", name))
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return model.NewFieldError(model.ErrInvalidInput, "headers", fmt.Sprintf("invalid value for header %s", name))
+		}
+		if reservedWebhookHeaders[http.CanonicalHeaderKey(name)] {
+			return model.NewFieldError(model.ErrInvalidInput, "headers", fmt.Sprintf("header %s can't be set", name))
+		}
+	}
+	return nil
+}
+
+// encodeWebhookHeaders stores headers as JSON; nil stays nil.
+func encodeWebhookHeaders(headers map[string]string) json.RawMessage {
+	if headers == nil {
+		return nil
+	}
+	b, _ := json.Marshal(headers)
+	return b
+}
+
+func decodeWebhookHeaders(raw json.RawMessage) map[string]string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var headers map[string]string
+	if err := json.Unmarshal(raw, &headers); err != nil {
+		return nil
+	}
+	return headers
+}
+
+// WebhookPreviewInput is a template to try out before saving it.
+type WebhookPreviewInput struct {
+	EventID     *uuid.UUID // nil for global webhooks
+	TriggerType string
+	Template    string
+	ContentType string
+	Headers     map[string]string
+}
+
+// WebhookPreviewResponse is a template rendered against sample data. Error is
+// set instead of Body when the template doesn't parse or fails to execute.
+type WebhookPreviewResponse struct {
+	TriggerType string            `json:"trigger_type"`
+	Payload     WebhookPayload    `json:"payload"`
+	Body        string            `json:"body"`
+	ContentType string            `json:"content_type"`
+	Headers     map[string]string `json:"headers"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// PreviewTemplate renders a webhook template against sample data for a
+// trigger. Template errors are reported in the response rather than as an
+// error, so they can be shown next to the template while editing.
+func (s *WebhookService) PreviewTemplate(ctx context.Context, input WebhookPreviewInput) (WebhookPreviewResponse, error) {
+	if input.TriggerType == "" {
+		return WebhookPreviewResponse{}, model.NewFieldError(model.ErrInvalidInput, "trigger_type", "trigger type is required")
+	}
+	if err := validateWebhookTemplateHeaders(&input.ContentType, input.Headers); err != nil {
+		return WebhookPreviewResponse{}, err
+	}
+
+	var event *repository.Event
+	if input.EventID != nil {
+		e, err := s.queries.GetEventByID(ctx, *input.EventID)
+		if err != nil {
+			return WebhookPreviewResponse{}, fmt.Errorf("getting event: %w", err)
+		}
+		event = &e
+	}
+
+	payload := sampleWebhookPayload(input.TriggerType, event)
+	resp := WebhookPreviewResponse{
+		TriggerType: input.TriggerType,
+		Payload:     payload,
+		ContentType: input.ContentType,
+		Headers:     input.Headers,
+	}
+	if resp.ContentType == "" {
+		resp.ContentType = "application/json"
+	}
+	if resp.Headers == nil {
+		resp.Headers = map[string]string{}
+	}
+
+	tmpl, err := parseWebhookTemplate(input.Template)
+	if err != nil {
+		resp.Error = err.Error()
+		return resp, nil
+	}
+	body, err := executeWebhookTemplate(tmpl, describeWebhook(payload, event, s.baseURL))
+	if err != nil {
+		resp.Error = err.Error()
+		return resp, nil
+	}
+	resp.Body = string(body)
+	return resp, nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/repository"
+)
+
+func TestWebhookTemplate(t *testing.T) {
+	msg := testShiftMessage()
+	tmpl := `{"who":{{json .Data.username}},"team":{{json .Data.team_name}},"day":"{{formatTime "2006-01-02" .Data.start_time}}","event":"{{.Event.Slug}}","kind":"{{upper .Type}}","note":"{{default "none" .Data.missing}}"}`
+	msg.Webhook.Template = &tmpl
+
+	body, err := webhookFormats["template"].Render(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"who":"alice","team":"Bar <North>","day":"2026-07-04","event":"camp","kind":"SHIFT.CREATED","note":"none"}`
+	if string(body) != want {
+		t.Errorf("body = %s\nwant  %s", body, want)
+	}
+
+	// The slug doesn't depend on a link to the event
+	noLink := describeWebhook(WebhookPayload{Type: "event.locked"}, &repository.Event{Name: "Camp", Slug: "camp"}, "")
+	slugOnly := `{{.Event.Slug}}`
+	noLink.Webhook.Template = &slugOnly
+	if body, err := webhookFormats["template"].Render(noLink); err != nil || string(body) != "camp" {
+		t.Errorf("slug without base URL = %q, %v", body, err)
+	}
+
+	bad := `{{formatTime "2006" .Data.username}}`
+	msg.Webhook.Template = &bad
+	if _, err := webhookFormats["template"].Render(msg); err == nil {
+		t.Error("expected an execution error")
+	}
+
+	huge := `{{range .Data}}` + strings.Repeat("x", 10000) + `{{end}}`
+	msg.Webhook.Template = &huge
+	if _, err := webhookFormats["template"].Render(msg); err == nil || !strings.Contains(err.Error(), "rendered body") {
+		t.Errorf("huge output error = %v", err)
+	}
+}
+
+func TestWebhookTemplateLimits(t *testing.T) {
+	for _, text := range []string{
+		`{{range 300000000}}{{end}}`,
+		`{{$n := 300000000}}{{range $n}}{{end}}`,
+		`{{$n := 3}}{{$n = 300000000}}{{range $n}}{{end}}`,
+		`{{with $n := 300000000}}{{range $n}}{{end}}{{end}}`,
+		`{{range len .Data}}{{end}}`,
+		`{{range (default 300000000 .Data.missing)}}{{end}}`,
+		`{{range .Data}}{{range $.Data}}{{range $.Data}}{{end}}{{end}}{{end}}`,
+		`{{define "a"}}{{template "a" .}}{{template "a" .}}{{end}}{{template "a" .}}`,
+	} {
+		if _, err := parseWebhookTemplate(text); err == nil {
+			t.Errorf("%s: accepted", text)
+		}
+	}
+	for _, text := range []string{
+		`{{range $i, $e := .Data.entries}}{{range $e.tags}}{{.}}{{end}}{{end}}`,
+		`{{$d := .Data}}{{range $d}}{{.}}{{end}}{{with .Data}}{{range .}}{{end}}{{end}}`,
+	} {
+		if _, err := parseWebhookTemplate(text); err != nil {
+			t.Errorf("%s: %v", text, err)
+		}
+	}
+
+	// Slow templates that pass the checks still time out
+	defer func(d time.Duration) { webhookTemplateTimeout = d }(webhookTemplateTimeout)
+	webhookTemplateTimeout = 10 * time.Millisecond
+	items := make([]int, 3000)
+	msg := WebhookMessage{Payload: WebhookPayload{Type: "test", Data: map[string]any{"items": items}}}
+	tmpl, err := parseWebhookTemplate(`{{range .Data.items}}{{range $.Data.items}}{{end}}{{end}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := executeWebhookTemplate(tmpl, msg); err == nil || !strings.Contains(err.Error(), "took longer") {
+		t.Errorf("slow template error = %v", err)
+	}
+}
+
+func TestValidateWebhookTemplate(t *testing.T) {
+	ok := `{"type":"{{.Type}}"}`
+	unclosed := `{{.Type`
+	badType := "not a type"
+	tests := []struct {
+		name  string
+		wh    repository.WebhookConfig
+		field string // empty when valid
+	}{
+		{"valid", repository.WebhookConfig{Template: &ok, Headers: []byte(`{"Authorization":"Bearer abc"}`)}, ""},
+		{"missing template", repository.WebhookConfig{}, "template"},
+		{"parse error", repository.WebhookConfig{Template: &unclosed}, "template"},
+		{"content type", repository.WebhookConfig{Template: &ok, ContentType: &badType}, "content_type"},
+		{"reserved header", repository.WebhookConfig{Template: &ok, Headers: []byte(`{"x-webhook-signature":"forged"}`)}, "headers"},
+		{"header injection", repository.WebhookConfig{Template: &ok, Headers: []byte(`{"X-Test":"a\r\nB: c"}`)}, "headers"},
+		{"other format", repository.WebhookConfig{Format: "slack", Template: &ok}, "template"},
+	}
+	for _, tt := range tests {
+		wh := tt.wh
+		wh.Url, wh.Secret = "https://example.com/hook", "s3cret"
+		if wh.Format == "" {
+			wh.Format = "template"
+		}
+		err := validateWebhook(wh)
+		if tt.field == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.name, err)
+			}
+			continue
+		}
+		var de *model.DomainError
+		if !errors.As(err, &de) || de.Field != tt.field {
+			t.Errorf("%s: error = %v, want field %s", tt.name, err, tt.field)
+		}
+	}
+}
+
+func TestSampleWebhookPayload(t *testing.T) {
+	s := &WebhookService{baseURL: "https://rncasp.example"}
+	for _, trigger := range []string{TriggerShiftCreated, TriggerAnnouncement, "event.created", "user.updated", "webhook.test"} {
+		payload := sampleWebhookPayload(trigger, nil)
+		tmpl, _ := parseWebhookTemplate(`{{.Type}} {{.Title}}`)
+		body, err := executeWebhookTemplate(tmpl, describeWebhook(payload, nil, s.baseURL))
+		if err != nil || !strings.HasPrefix(string(body), trigger+" ") {
+			t.Errorf("%s: body = %q, err = %v", trigger, body, err)
+		}
+	}
+}
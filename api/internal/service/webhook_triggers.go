@@ -0,0 +1,325 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/repository"
+)
+
+// Webhook-only trigger types. Triggers shared with notifications are defined
+// in notification.go.
+const (
+	TriggerCoverageUpdated       = "coverage.updated"
+	TriggerAvailabilityUpdated   = "availability.updated"
+	TriggerEventTeamChanged      = "event.team_changed"
+	TriggerEventHiddenRanges     = "event.hidden_ranges_updated"
+	TriggerEventPinnedAdded      = "event.pinned_user_added"
+	TriggerEventPinnedRemoved    = "event.pinned_user_removed"
+	TriggerEventVisibilityChange = "event.visibility_changed"
+	TriggerTeamCreated           = "team.created"
+	TriggerTeamUpdated           = "team.updated"
+	TriggerTeamDeleted           = "team.deleted"
+	TriggerUserOAuthLogin        = "user.oauth_login"
+)
+
+// Webhook scopes: event webhooks receive the triggers of their event, global
+// webhooks the triggers that don't belong to an event.
+const (
+	WebhookScopeEvent    = "event"
+	WebhookScopeGlobal   = "global"
+	WebhookScopePersonal = "personal"
+)
+
+// CoverageChange is the data of coverage.updated.
+type CoverageChange struct {
+	Action   string                       `json:"action"`             // created, updated or deleted
+	Coverage *CoverageRequirementResponse `json:"coverage,omitempty"` // created or updated requirement
+	ID       string                       `json:"id,omitempty"`       // deleted requirement
+	TeamID   string                       `json:"team_id,omitempty"`  // set when all of a team's requirements were deleted
+}
+
+// AvailabilityChange is the data of availability.updated.
+type AvailabilityChange struct {
+	EventID  string                 `json:"event_id"`
+	UserID   string                 `json:"user_id"`
+	Username string                 `json:"username"`
+	Source   string                 `json:"source"` // manual or ical
+	Entries  []AvailabilityResponse `json:"entries"`
+}
+
+// EventTeamChange is the data of event.team_changed.
+type EventTeamChange struct {
+	EventID   string `json:"event_id"`
+	Slug      string `json:"slug"`
+	TeamID    string `json:"team_id"`
+	TeamName  string `json:"team_name"`
+	IsVisible bool   `json:"is_visible"`
+	Removed   bool   `json:"removed"`
+}
+
+// HiddenRangesChange is the data of event.hidden_ranges_updated.
+type HiddenRangesChange struct {
+	EventID string                `json:"event_id"`
+	Slug    string                `json:"slug"`
+	Ranges  []HiddenRangeResponse `json:"ranges"`
+}
+
+// WebhookTrigger describes a trigger type webhooks can subscribe to.
+type WebhookTrigger struct {
+	Type        string         `json:"type"`
+	Scope       string         `json:"scope"`
+	Description string         `json:"description"`
+	Example     WebhookPayload `json:"example"`
+}
+
+// webhookExample holds the made-up values example payloads are built from.
+type webhookExample struct {
+	EventID, EventName, Slug string
+	Now                      time.Time
+}
+
+type webhookTriggerDef struct {
+	Type        string
+	Scope       string
+	Description string
+	Example     func(ex webhookExample) any
+}
+
+var (
+	exampleUserID = "5d1f0c52-8a35-4e0a-9d0e-6f1d2b3c4a5e"
+	exampleTeamID = "b7e0d9a4-3c2f-4e8b-a1d6-2f9c8e7b6a50"
+)
+
+// webhookTriggers lists every trigger type. Dispatching a trigger that isn't
+// listed here works, but webhooks can't subscribe to it.
+var webhookTriggers = []webhookTriggerDef{
+	{TriggerShiftCreated, WebhookScopeEvent, "A shift was created", exampleShift},
+	{TriggerShiftUpdated, WebhookScopeEvent, "A shift was moved or reassigned", func(ex webhookExample) any {
+		shift := exampleShift(ex).(ShiftResponse)
+		return ShiftUpdate{ShiftResponse: shift, Previous: ShiftPlacement{
+			TeamID:    shift.TeamID,
+			UserID:    shift.UserID,
+			StartTime: ex.Now.Add(22 * time.Hour).Format(time.RFC3339),
+			EndTime:   ex.Now.Add(24 * time.Hour).Format(time.RFC3339),
+		}}
+	}},
+	{TriggerShiftDeleted, WebhookScopeEvent, "A shift was deleted", exampleShift},
+	{TriggerCoverageUpdated, WebhookScopeEvent, "A coverage requirement was created, updated or deleted", func(ex webhookExample) any {
+		return CoverageChange{Action: "updated", Coverage: &CoverageRequirementResponse{
+			ID:            "0f3b8c1e-6d2a-4f7b-9e5c-1a2b3c4d5e6f",
+			EventID:       ex.EventID,
+			TeamID:        exampleTeamID,
+			StartTime:     ex.Now.Add(24 * time.Hour).Format(time.RFC3339),
+			EndTime:       ex.Now.Add(30 * time.Hour).Format(time.RFC3339),
+			RequiredCount: 3,
+		}}
+	}},
+	{TriggerAvailabilityUpdated, WebhookScopeEvent, "A user changed their availability or imported a calendar", func(ex webhookExample) any {
+		return AvailabilityChange{
+			EventID:  ex.EventID,
+			UserID:   exampleUserID,
+			Username: "alex",
+			Source:   availabilitySourceManual,
+			Entries: []AvailabilityResponse{{
+				ID:        "9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d",
+				EventID:   ex.EventID,
+				UserID:    exampleUserID,
+				StartTime: ex.Now.Add(24 * time.Hour).Format(time.RFC3339),
+				EndTime:   ex.Now.Add(32 * time.Hour).Format(time.RFC3339),
+				Status:    "available",
+				Source:    availabilitySourceManual,
+			}},
+		}
+	}},
+	{TriggerEventLocked, WebhookScopeEvent, "The event was locked", func(ex webhookExample) any {
+		return map[string]any{"slug": ex.Slug, "locked": true}
+	}},
+	{TriggerEventUnlocked, WebhookScopeEvent, "The event was unlocked", func(ex webhookExample) any {
+		return map[string]any{"slug": ex.Slug, "locked": false}
+	}},
+	{"event.updated", WebhookScopeEvent, "The event's details were changed", exampleEvent},
+	{TriggerEventVisibilityChange, WebhookScopeEvent, "The event was made public or private", func(ex webhookExample) any {
+		return map[string]any{"event_id": ex.EventID, "slug": ex.Slug, "is_public": true}
+	}},
+	{TriggerEventTeamChanged, WebhookScopeEvent, "A team was added to the event, removed, or shown or hidden", func(ex webhookExample) any {
+		return EventTeamChange{EventID: ex.EventID, Slug: ex.Slug, TeamID: exampleTeamID, TeamName: "Bar", IsVisible: true}
+	}},
+	{TriggerEventHiddenRanges, WebhookScopeEvent, "The hours hidden in the grid were changed", func(ex webhookExample) any {
+		return HiddenRangesChange{EventID: ex.EventID, Slug: ex.Slug, Ranges: []HiddenRangeResponse{
+			{ID: "3e4f5a6b-7c8d-4e9f-a0b1-c2d3e4f5a6b7", HideStartHour: 2, HideEndHour: 8},
+		}}
+	}},
+	{"event.admin_added", WebhookScopeEvent, "A user was made an admin of the event", exampleEventUser},
+	{"event.admin_removed", WebhookScopeEvent, "A user is no longer an admin of the event", exampleEventUser},
+	{TriggerEventPinnedAdded, WebhookScopeEvent, "A user was pinned to the event's grid", exampleEventUser},
+	{TriggerEventPinnedRemoved, WebhookScopeEvent, "A user was unpinned from the event's grid", exampleEventUser},
+	{TriggerAnnouncement, WebhookScopeEvent, "An announcement was sent", func(ex webhookExample) any {
+		return AnnouncementResponse{
+			ID:             "7c6b5a49-3828-4f17-a6e5-d4c3b2a19080",
+			EventID:        ex.EventID,
+			Title:          "Briefing moved",
+			Body:           "The briefing starts at 18:00 at the main stage.",
+			TeamIDs:        []string{},
+			RecipientCount: 42,
+			CreatedAt:      ex.Now.Format(time.RFC3339),
+		}
+	}},
+
+	{"event.created", WebhookScopeGlobal, "An event was created", exampleEvent},
+	{"event.deleted", WebhookScopeGlobal, "An event was deleted", exampleEvent},
+	{"user.registered", WebhookScopeGlobal, "A user signed up, with a password or through an OAuth provider", func(webhookExample) any {
+		return map[string]string{"username": "alex", "full_name": "Alex Example", "role": "user"}
+	}},
+	{"user.created", WebhookScopeGlobal, "An admin created a user", func(webhookExample) any {
+		return map[string]string{"user_id": exampleUserID, "username": "alex", "account_type": "local"}
+	}},
+	{"user.updated", WebhookScopeGlobal, "A user's role, status or account type was changed", func(webhookExample) any {
+		return map[string]string{"user_id": exampleUserID, "username": "alex", "old_role": "user", "new_role": "admin"}
+	}},
+	{TriggerUserOAuthLogin, WebhookScopeGlobal, "A user logged in through an OAuth provider", func(webhookExample) any {
+		return map[string]string{"user_id": exampleUserID, "username": "alex", "provider": "keycloak"}
+	}},
+	{TriggerTeamCreated, WebhookScopeGlobal, "A team was created", exampleTeam},
+	{TriggerTeamUpdated, WebhookScopeGlobal, "A team was changed", exampleTeam},
+	{TriggerTeamDeleted, WebhookScopeGlobal, "A team was deleted", exampleTeam},
+	{"settings.changed", WebhookScopeGlobal, "An app setting was changed", func(webhookExample) any {
+		return map[string]string{"key": "registration_enabled"}
+	}},
+}
+
+func exampleShift(ex webhookExample) any {
+	displayName := "Alex"
+	return ShiftResponse{
+		ID:               "1c2d3e4f-5a6b-4c7d-8e9f-0a1b2c3d4e5f",
+		EventID:          ex.EventID,
+		TeamID:           exampleTeamID,
+		UserID:           exampleUserID,
+		StartTime:        ex.Now.Add(24 * time.Hour).Format(time.RFC3339),
+		EndTime:          ex.Now.Add(26 * time.Hour).Format(time.RFC3339),
+		TeamName:         "Bar",
+		TeamAbbreviation: "BAR",
+		TeamColor:        "#3b82f6",
+		Username:         "alex",
+		UserFullName:     "Alex Example",
+		UserDisplayName:  &displayName,
+		CreatedAt:        ex.Now.Format(time.RFC3339),
+	}
+}
+
+func exampleEvent(ex webhookExample) any {
+	return map[string]string{"event_id": ex.EventID, "name": ex.EventName, "slug": ex.Slug}
+}
+
+func exampleEventUser(ex webhookExample) any {
+	return map[string]string{"event_id": ex.EventID, "slug": ex.Slug, "user_id": exampleUserID}
+}
+
+func exampleTeam(ex webhookExample) any {
+	return TeamResponse{
+		ID:           exampleTeamID,
+		Name:         "Bar",
+		Abbreviation: "BAR",
+		Color:        "#3b82f6",
+		SortOrder:    1,
+		IsActive:     true,
+		CreatedAt:    ex.Now.Format(time.RFC3339),
+	}
+}
+
+// personalWebhookTriggers are the event triggers personal webhooks can
+// subscribe to. They are sent for changes to the owner's own shifts.
+var personalWebhookTriggers = []string{TriggerShiftCreated, TriggerShiftUpdated, TriggerShiftDeleted}
+
+// availableIn reports whether webhooks of a scope can subscribe to the trigger.
+func (t webhookTriggerDef) availableIn(scope string) bool {
+	if scope == WebhookScopePersonal {
+		return slices.Contains(personalWebhookTriggers, t.Type)
+	}
+	return t.Scope == scope
+}
+
+func findWebhookTrigger(triggerType string) (webhookTriggerDef, bool) {
+	i := slices.IndexFunc(webhookTriggers, func(t webhookTriggerDef) bool { return t.Type == triggerType })
+	if i < 0 {
+		return webhookTriggerDef{}, false
+	}
+	return webhookTriggers[i], true
+}
+
+// ListTriggers returns the trigger types webhooks of a scope can subscribe
+// to, with an example payload each.
+func (s *WebhookService) ListTriggers(scope string) []WebhookTrigger {
+	result := []WebhookTrigger{}
+	for _, t := range webhookTriggers {
+		if t.availableIn(scope) {
+			result = append(result, WebhookTrigger{
+				Type:        t.Type,
+				Scope:       scope,
+				Description: t.Description,
+				Example:     sampleWebhookPayload(t.Type, nil),
+			})
+		}
+	}
+	return result
+}
+
+// validateTriggerTypes checks that webhooks of a scope can subscribe to all
+// trigger types.
+func validateTriggerTypes(scope string, triggerTypes []string) error {
+	if len(triggerTypes) == 0 {
+		return model.NewFieldError(model.ErrInvalidInput, "trigger_types", "at least one trigger type is required")
+	}
+	for _, triggerType := range triggerTypes {
+		t, ok := findWebhookTrigger(triggerType)
+		if !ok {
+			return model.NewFieldError(model.ErrInvalidInput, "trigger_types", fmt.Sprintf("unknown trigger type %q", triggerType))
+		}
+		if !t.availableIn(scope) {
+			return model.NewFieldError(model.ErrInvalidInput, "trigger_types", fmt.Sprintf("trigger type %q is not available for %s webhooks", triggerType, scope))
+		}
+	}
+	return nil
+}
+
+// webhookScope returns the scope of a webhook.
+func webhookScope(wh repository.WebhookConfig) string {
+	if wh.UserID != nil {
+		return WebhookScopePersonal
+	}
+	if wh.EventID == nil {
+		return WebhookScopeGlobal
+	}
+	return WebhookScopeEvent
+}
+
+// sampleWebhookPayload returns a payload for a trigger with made-up data of
+// the same shape as real deliveries. event fills in the event, if given.
+func sampleWebhookPayload(triggerType string, event *repository.Event) WebhookPayload {
+	ex := webhookExample{
+		EventID:   "2a7e4c1d-9b3f-4e6a-8d5c-7f1e0b2a3c4d",
+		EventName: "Summer Camp",
+		Slug:      "summer-camp",
+		Now:       time.Now().UTC().Truncate(time.Hour),
+	}
+	payload := WebhookPayload{
+		Type:      triggerType,
+		Timestamp: ex.Now.Format(time.RFC3339),
+	}
+
+	t, ok := findWebhookTrigger(triggerType)
+	if event != nil {
+		ex.EventID, ex.EventName, ex.Slug = event.ID.String(), event.Name, event.Slug
+	}
+	if (ok && t.Scope == WebhookScopeEvent) || event != nil {
+		payload.EventID = ex.EventID
+	}
+	if ok {
+		payload.Data = t.Example(ex)
+	} else {
+		payload.Data = map[string]string{"message": "This is a test webhook from Rncasp."}
+	}
+	return payload
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/echtkpvl/rncasp/internal/model"
+	"github.com/echtkpvl/rncasp/internal/repository"
+	"github.com/google/uuid"
+)
+
+func TestWebhookTriggerRegistry(t *testing.T) {
+	seen := map[string]bool{}
+	for _, trigger := range webhookTriggers {
+		if seen[trigger.Type] {
+			t.Errorf("%s listed twice", trigger.Type)
+		}
+		seen[trigger.Type] = true
+		if trigger.Scope != WebhookScopeEvent && trigger.Scope != WebhookScopeGlobal {
+			t.Errorf("%s: scope %q", trigger.Type, trigger.Scope)
+		}
+		if trigger.Description == "" {
+			t.Errorf("%s: no description", trigger.Type)
+		}
+
+		payload := sampleWebhookPayload(trigger.Type, nil)
+		if (payload.EventID != "") != (trigger.Scope == WebhookScopeEvent) {
+			t.Errorf("%s: event_id = %q", trigger.Type, payload.EventID)
+		}
+		if b, err := json.Marshal(payload); err != nil || len(webhookData(payload.Data)) == 0 {
+			t.Errorf("%s: example %s, err = %v", trigger.Type, b, err)
+		}
+
+		// Every trigger gets a readable message in the chat formats.
+		msg := describeWebhook(payload, nil, "https://rncasp.example")
+		if msg.Title == trigger.Type || strings.HasSuffix(msg.Title, ": "+trigger.Type) {
+			t.Errorf("%s: no title", trigger.Type)
+		}
+	}
+
+	s := &WebhookService{}
+	event, global := s.ListTriggers(WebhookScopeEvent), s.ListTriggers(WebhookScopeGlobal)
+	if len(event)+len(global) != len(webhookTriggers) {
+		t.Errorf("listed %d event and %d global triggers, want %d", len(event), len(global), len(webhookTriggers))
+	}
+	if personal := s.ListTriggers(WebhookScopePersonal); len(personal) != len(personalWebhookTriggers) {
+		t.Errorf("listed %d personal triggers, want %d", len(personal), len(personalWebhookTriggers))
+	}
+}
+
+func TestSampleWebhookPayloadEvent(t *testing.T) {
+	event := &repository.Event{ID: uuid.New(), Name: "Camp", Slug: "camp"}
+	payload := sampleWebhookPayload(TriggerEventTeamChanged, event)
+	change, ok := payload.Data.(EventTeamChange)
+	if payload.EventID != event.ID.String() || !ok || change.Slug != "camp" {
+		t.Errorf("payload = %+v", payload)
+	}
+}
+
+func TestValidateTriggerTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		scope    string
+		triggers []string
+		wantErr  bool
+	}{
+		{"event triggers", WebhookScopeEvent, []string{TriggerShiftCreated, TriggerCoverageUpdated, TriggerAvailabilityUpdated}, false},
+		{"global triggers", WebhookScopeGlobal, []string{"user.registered", TriggerTeamDeleted, TriggerUserOAuthLogin}, false},
+		{"empty", WebhookScopeEvent, nil, true},
+		{"unknown", WebhookScopeEvent, []string{TriggerShiftCreated, "shift.exploded"}, true},
+		{"global trigger on event webhook", WebhookScopeEvent, []string{"event.created"}, true},
+		{"event trigger on global webhook", WebhookScopeGlobal, []string{TriggerShiftCreated}, true},
+		{"test trigger", WebhookScopeEvent, []string{"webhook.test"}, true},
+		{"personal shift triggers", WebhookScopePersonal, []string{TriggerShiftCreated, TriggerShiftDeleted}, false},
+		{"event trigger on personal webhook", WebhookScopePersonal, []string{TriggerCoverageUpdated}, true},
+	}
+	for _, tt := range tests {
+		err := validateTriggerTypes(tt.scope, tt.triggers)
+		if !tt.wantErr {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.name, err)
+			}
+			continue
+		}
+		var de *model.DomainError
+		if !errors.As(err, &de) || de.Field != "trigger_types" {
+			t.Errorf("%s: error = %v, want trigger_types error", tt.name, err)
+		}
+	}
+}
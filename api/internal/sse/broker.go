@@ -15,6 +15,7 @@ type Event struct {
 	Type    string `json:"type"`
 	EventID string `json:"event_id,omitempty"`
 	Slug    string `json:"-"` // used for routing to per-event subscribers, not serialized
+	UserID  string `json:"-"` // if set, delivered only to this user's connections
 	Payload any    `json:"payload,omitempty"`
 }
 
@@ -26,14 +27,20 @@ const (
 	TypeEventLocked     = "event.locked"
 	TypeEventUnlocked   = "event.unlocked"
 	TypeCoverageUpdated = "coverage.updated"
+
+	// Per-user events
+	TypeNotificationCreated = "notification.created"
+	TypeNotificationsRead   = "notification.read"
 )
 
 const redisPubSubChannel = "sse:events"
 
 // client represents a connected SSE client.
 type client struct {
-	ch   chan []byte
-	slug string // event slug filter; empty string means subscribed to all events
+	ch       chan []byte
+	slug     string // event slug filter; empty string means subscribed to all events
+	userID   string // authenticated user; receives that user's events
+	userOnly bool   // receives only the user's events, no event broadcasts
 }
 
 // Broker manages SSE client connections and event broadcasting.
@@ -75,26 +82,40 @@ func (b *Broker) Close() {
 }
 
 // Subscribe registers a new client to receive SSE events.
-// If slug is empty, the client receives all events.
+// If slug is empty, the client receives all events. The client also receives
+// events addressed to userID.
 // Returns a channel that delivers serialized SSE data and a cleanup function.
-func (b *Broker) Subscribe(slug string) (<-chan []byte, func()) {
-	c := &client{
-		ch:   make(chan []byte, 64),
-		slug: slug,
-	}
+func (b *Broker) Subscribe(slug, userID string) (<-chan []byte, func()) {
+	return b.add(&client{
+		ch:     make(chan []byte, 64),
+		slug:   slug,
+		userID: userID,
+	})
+}
 
+// SubscribeUser registers a client that receives only events addressed to
+// userID, such as new notifications.
+func (b *Broker) SubscribeUser(userID string) (<-chan []byte, func()) {
+	return b.add(&client{
+		ch:       make(chan []byte, 64),
+		userID:   userID,
+		userOnly: true,
+	})
+}
+
+func (b *Broker) add(c *client) (<-chan []byte, func()) {
 	b.mu.Lock()
 	b.clients[c] = struct{}{}
 	b.mu.Unlock()
 
-	b.logger.Debug("sse client subscribed", "slug", slug, "total_clients", b.clientCount())
+	b.logger.Debug("sse client subscribed", "slug", c.slug, "user_only", c.userOnly, "total_clients", b.clientCount())
 
 	cleanup := func() {
 		b.mu.Lock()
 		delete(b.clients, c)
 		close(c.ch)
 		b.mu.Unlock()
-		b.logger.Debug("sse client unsubscribed", "slug", slug, "total_clients", b.clientCount())
+		b.logger.Debug("sse client unsubscribed", "slug", c.slug, "user_only", c.userOnly, "total_clients", b.clientCount())
 	}
 
 	return c.ch, cleanup
@@ -109,11 +130,8 @@ func (b *Broker) Publish(ctx context.Context, evt Event) {
 		return
 	}
 
-	// Wrap in envelope with slug for per-event routing
-	envelope, err := json.Marshal(struct {
-		Slug  string `json:"slug"`
-		Inner string `json:"inner"`
-	}{Slug: evt.Slug, Inner: string(inner)})
+	// Wrap in envelope with slug and user for routing
+	envelope, err := json.Marshal(envelope{Slug: evt.Slug, UserID: evt.UserID, Inner: string(inner)})
 	if err != nil {
 		b.logger.Error("failed to marshal SSE envelope", "error", err)
 		return
@@ -122,10 +140,17 @@ func (b *Broker) Publish(ctx context.Context, evt Event) {
 	if err := b.rdb.Publish(ctx, redisPubSubChannel, envelope).Err(); err != nil {
 		b.logger.Error("failed to publish SSE event to Redis", "error", err)
 		// Fall back to local-only broadcast
-		b.broadcast(formatSSE(inner), evt.Slug)
+		b.broadcast(formatSSE(inner), evt.Slug, evt.UserID)
 	}
 }
 
+// envelope is the Redis Pub/Sub message carrying an event and its routing.
+type envelope struct {
+	Slug   string `json:"slug"`
+	UserID string `json:"user_id,omitempty"`
+	Inner  string `json:"inner"`
+}
+
 // subscribe listens on the Redis Pub/Sub channel and broadcasts to local clients.
 func (b *Broker) subscribe() {
 	pubsub := b.rdb.Subscribe(b.ctx, redisPubSubChannel)
@@ -140,29 +165,26 @@ func (b *Broker) subscribe() {
 			if !ok {
 				return
 			}
-			var envelope struct {
-				Slug  string `json:"slug"`
-				Inner string `json:"inner"`
-			}
-			if err := json.Unmarshal([]byte(msg.Payload), &envelope); err != nil {
+			var env envelope
+			if err := json.Unmarshal([]byte(msg.Payload), &env); err != nil {
 				b.logger.Error("failed to unmarshal SSE envelope from Redis", "error", err)
 				continue
 			}
 
-			sseData := formatSSE([]byte(envelope.Inner))
-			b.broadcast(sseData, envelope.Slug)
+			sseData := formatSSE([]byte(env.Inner))
+			b.broadcast(sseData, env.Slug, env.UserID)
 		}
 	}
 }
 
-// broadcast sends serialized SSE data to matching local clients.
-func (b *Broker) broadcast(data []byte, slug string) {
+// broadcast sends serialized SSE data to matching local clients. Events with
+// a userID go only to that user's clients.
+func (b *Broker) broadcast(data []byte, slug, userID string) {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
 	for c := range b.clients {
-		// Send to clients subscribed to this specific event or to all events
-		if c.slug == "" || c.slug == slug {
+		if c.matches(slug, userID) {
 			select {
 			case c.ch <- data:
 			default:
@@ -173,6 +195,16 @@ func (b *Broker) broadcast(data []byte, slug string) {
 	}
 }
 
+// matches reports whether the client should receive an event routed by slug
+// and userID.
+func (c *client) matches(slug, userID string) bool {
+	if userID != "" {
+		return c.userID == userID
+	}
+	// Send to clients subscribed to this specific event or to all events
+	return !c.userOnly && (c.slug == "" || c.slug == slug)
+}
+
 func (b *Broker) clientCount() int {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
@@ -0,0 +1,26 @@
+package sse
+
+import "testing"
+
+func TestClientMatches(t *testing.T) {
+	tests := []struct {
+		name         string
+		client       client
+		slug, userID string
+		want         bool
+	}{
+		{"global client gets event updates", client{userID: "u1"}, "camp", "", true},
+		{"event client gets its event", client{slug: "camp", userID: "u1"}, "camp", "", true},
+		{"event client skips other events", client{slug: "camp", userID: "u1"}, "fest", "", false},
+		{"user client skips event updates", client{userID: "u1", userOnly: true}, "camp", "", false},
+		{"user client gets own events", client{userID: "u1", userOnly: true}, "", "u1", true},
+		{"event client gets own events", client{slug: "camp", userID: "u1"}, "", "u1", true},
+		{"other user's events are never delivered", client{userID: "u2"}, "", "u1", false},
+		{"anonymous client gets no user events", client{}, "", "u1", false},
+	}
+	for _, tt := range tests {
+		if got := tt.client.matches(tt.slug, tt.userID); got != tt.want {
+			t.Errorf("%s: matches(%q, %q) = %v, want %v", tt.name, tt.slug, tt.userID, got, tt.want)
+		}
+	}
+}
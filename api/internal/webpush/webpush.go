@@ -0,0 +1,261 @@
+// Package webpush sends Web Push messages (RFC 8030) with payloads encrypted
+// per RFC 8291 (aes128gcm) and VAPID authentication (RFC 8292).
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// MaxPayload is the largest payload Send accepts. Push services must accept
+// bodies of 4096 bytes; the rest is taken by the encryption header and tag.
+const MaxPayload = 3993
+
+// recordSize is the aes128gcm record size written in the header. Payloads
+// always fit a single record.
+const recordSize = 4096
+
+// jwtLifetime is how long a VAPID token is valid (at most 24 hours).
+const jwtLifetime = 12 * time.Hour
+
+var (
+	// ErrGone is returned when the push service reports the subscription as
+	// expired or unsubscribed; it should be deleted.
+	ErrGone = errors.New("push subscription is gone")
+
+	ErrPayloadTooLarge = errors.New("push payload too large")
+)
+
+var b64 = base64.RawURLEncoding
+
+// Keys is a VAPID key pair, base64url-encoded without padding: the public
+// key as an uncompressed P-256 point, the private key as its scalar.
+type Keys struct {
+	PublicKey  string
+	PrivateKey string
+}
+
+// Subscription is a browser push subscription as returned by
+// PushManager.subscribe(), with base64url-encoded keys.
+type Subscription struct {
+	Endpoint string
+	P256dh   string
+	Auth     string
+}
+
+// Options control a single push message.
+type Options struct {
+	Subject string // contact for the push service: a mailto: or https: URL
+	TTL     int    // seconds the push service keeps the message for an offline device
+	Topic   string // replaces an undelivered message with the same topic
+	Urgency string // very-low, low, normal, or high; empty means normal
+}
+
+// GenerateKeys creates a new VAPID key pair.
+func GenerateKeys() (Keys, error) {
+	priv, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return Keys{}, fmt.Errorf("generating key: %w", err)
+	}
+	return Keys{
+		PublicKey:  b64.EncodeToString(priv.PublicKey().Bytes()),
+		PrivateKey: b64.EncodeToString(priv.Bytes()),
+	}, nil
+}
+
+// Send encrypts payload for sub and posts it to the subscription's push
+// service. It returns ErrGone for expired subscriptions.
+func Send(ctx context.Context, client *http.Client, keys Keys, sub Subscription, payload []byte, opts Options) error {
+	if len(payload) > MaxPayload {
+		return ErrPayloadTooLarge
+	}
+
+	uaPublic, err := decodeKey(sub.P256dh)
+	if err != nil {
+		return fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	authSecret, err := decodeKey(sub.Auth)
+	if err != nil {
+		return fmt.Errorf("invalid auth secret: %w", err)
+	}
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("generating key: %w", err)
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("generating salt: %w", err)
+	}
+	body, err := encrypt(payload, uaPublic, authSecret, asPrivate, salt)
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil || endpoint.Scheme != "https" && endpoint.Scheme != "http" || endpoint.Host == "" {
+		return fmt.Errorf("invalid endpoint %q", sub.Endpoint)
+	}
+	token, err := vapidToken(keys, endpoint.Scheme+"://"+endpoint.Host, opts.Subject, time.Now())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(opts.TTL))
+	req.Header.Set("Authorization", "vapid t="+token+", k="+keys.PublicKey)
+	if opts.Topic != "" {
+		req.Header.Set("Topic", opts.Topic)
+	}
+	if opts.Urgency != "" {
+		req.Header.Set("Urgency", opts.Urgency)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending push message: %w", err)
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrGone
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return fmt.Errorf("push service returned %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// encrypt builds an aes128gcm message body (RFC 8291 section 3.4) with the
+// sender key asPrivate and the given salt.
+func encrypt(payload, uaPublic, authSecret []byte, asPrivate *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	uaKey, err := ecdh.P256().NewPublicKey(uaPublic)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	ecdhSecret, err := asPrivate.ECDH(uaKey)
+	if err != nil {
+		return nil, fmt.Errorf("key agreement: %w", err)
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublic...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, ecdhSecret, authSecret, keyInfo), ikm); err != nil {
+		return nil, err
+	}
+
+	prk := hkdf.Extract(sha256.New, ikm, salt)
+	cek := make([]byte, 16)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, []byte("Content-Encoding: aes128gcm\x00")), cek); err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, 12)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, []byte("Content-Encoding: nonce\x00")), nonce); err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header: salt, record size, key ID length, key ID (the sender's public key)
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	// A single record: the payload followed by the last-record delimiter
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// vapidToken returns a signed ES256 JWT for the push service at audience.
+func vapidToken(keys Keys, audience, subject string, now time.Time) (string, error) {
+	priv, err := privateKey(keys)
+	if err != nil {
+		return "", err
+	}
+
+	header := b64.EncodeToString([]byte(`{"typ":"JWT","alg":"ES256"}`))
+	claims, err := json.Marshal(map[string]any{
+		"aud": audience,
+		"exp": now.Add(jwtLifetime).Unix(),
+		"sub": subject,
+	})
+	if err != nil {
+		return "", err
+	}
+	signingInput := header + "." + b64.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, priv, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("signing VAPID token: %w", err)
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return signingInput + "." + b64.EncodeToString(sig), nil
+}
+
+// privateKey converts the VAPID private key to an ECDSA signing key.
+func privateKey(keys Keys) (*ecdsa.PrivateKey, error) {
+	d, err := decodeKey(keys.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	priv, err := ecdh.P256().NewPrivateKey(d)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	pub := priv.PublicKey().Bytes() // 0x04 || X || Y
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(pub[1:33]),
+			Y:     new(big.Int).SetBytes(pub[33:]),
+		},
+		D: new(big.Int).SetBytes(d),
+	}, nil
+}
+
+// decodeKey accepts base64url with or without padding, as browsers differ.
+func decodeKey(s string) ([]byte, error) {
+	if b, err := b64.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return base64.URLEncoding.DecodeString(s)
+}
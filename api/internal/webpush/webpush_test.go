@@ -0,0 +1,185 @@
+package webpush
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+func mustDecode(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := decodeKey(s)
+	if err != nil {
+		t.Fatalf("decoding %q: %v", s, err)
+	}
+	return b
+}
+
+// TestEncryptRFC8291 checks encryption against the example in RFC 8291
+// appendix A.
+func TestEncryptRFC8291(t *testing.T) {
+	asPrivate, err := ecdh.P256().NewPrivateKey(mustDecode(t, "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := encrypt(
+		[]byte("When I grow up, I want to be a watermelon"),
+		mustDecode(t, "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"),
+		mustDecode(t, "BTBZMqHH6r4Tts7J_aSIgg"),
+		asPrivate,
+		mustDecode(t, "DGv6ra1nlYgDCS1FRnbzlw"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN"
+	if got := b64.EncodeToString(body); got != want {
+		t.Errorf("encrypt =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// decrypt is the user agent side of encrypt.
+func decrypt(t *testing.T, body []byte, uaPrivate *ecdh.PrivateKey, authSecret []byte) []byte {
+	t.Helper()
+	salt := body[:16]
+	if rs := binary.BigEndian.Uint32(body[16:20]); rs != recordSize {
+		t.Errorf("record size = %d", rs)
+	}
+	idLen := int(body[20])
+	asPublic := body[21 : 21+idLen]
+
+	asKey, err := ecdh.P256().NewPublicKey(asPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdhSecret, err := uaPrivate.ECDH(asKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyInfo := append([]byte("WebPush: info\x00"), uaPrivate.PublicKey().Bytes()...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm := make([]byte, 32)
+	io.ReadFull(hkdf.New(sha256.New, ecdhSecret, authSecret, keyInfo), ikm)
+	prk := hkdf.Extract(sha256.New, ikm, salt)
+	cek, nonce := make([]byte, 16), make([]byte, 12)
+	io.ReadFull(hkdf.Expand(sha256.New, prk, []byte("Content-Encoding: aes128gcm\x00")), cek)
+	io.ReadFull(hkdf.Expand(sha256.New, prk, []byte("Content-Encoding: nonce\x00")), nonce)
+
+	block, _ := aes.NewCipher(cek)
+	gcm, _ := cipher.NewGCM(block)
+	plaintext, err := gcm.Open(nil, nonce, body[21+idLen:], nil)
+	if err != nil {
+		t.Fatalf("decrypting: %v", err)
+	}
+	if plaintext[len(plaintext)-1] != 0x02 {
+		t.Fatalf("missing last-record delimiter")
+	}
+	return plaintext[:len(plaintext)-1]
+}
+
+func verifyVAPID(t *testing.T, header, publicKey, audience string) {
+	t.Helper()
+	parts := strings.Split(strings.TrimPrefix(header, "vapid "), ", ")
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "t=") || parts[1] != "k="+publicKey {
+		t.Fatalf("Authorization = %q", header)
+	}
+	token := strings.Split(strings.TrimPrefix(parts[0], "t="), ".")
+	if len(token) != 3 {
+		t.Fatalf("token has %d parts", len(token))
+	}
+
+	pub, err := ecdh.P256().NewPublicKey(mustDecode(t, publicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := pub.Bytes()
+	key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(raw[1:33]), Y: new(big.Int).SetBytes(raw[33:])}
+	sig := mustDecode(t, token[2])
+	digest := sha256.Sum256([]byte(token[0] + "." + token[1]))
+	if !ecdsa.Verify(key, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])) {
+		t.Error("VAPID signature does not verify")
+	}
+
+	var claims struct {
+		Aud string `json:"aud"`
+		Sub string `json:"sub"`
+		Exp int64  `json:"exp"`
+	}
+	if err := json.Unmarshal(mustDecode(t, token[1]), &claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims.Aud != audience || claims.Sub != "mailto:ops@example.org" || claims.Exp == 0 {
+		t.Errorf("claims = %+v, want aud %q", claims, audience)
+	}
+}
+
+func TestSend(t *testing.T) {
+	keys, err := GenerateKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	uaPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authSecret := make([]byte, 16)
+	rand.Read(authSecret)
+
+	var received []byte
+	status := http.StatusCreated
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "aes128gcm" || r.Header.Get("TTL") != "60" || r.Header.Get("Topic") != "shift" {
+			t.Errorf("unexpected headers: %v", r.Header)
+		}
+		verifyVAPID(t, r.Header.Get("Authorization"), keys.PublicKey, "http://"+r.Host)
+		body, _ := io.ReadAll(r.Body)
+		received = decrypt(t, body, uaPrivate, authSecret)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	sub := Subscription{
+		Endpoint: srv.URL + "/push/abc",
+		P256dh:   b64.EncodeToString(uaPrivate.PublicKey().Bytes()),
+		Auth:     b64.EncodeToString(authSecret),
+	}
+	opts := Options{Subject: "mailto:ops@example.org", TTL: 60, Topic: "shift"}
+
+	if err := Send(context.Background(), srv.Client(), keys, sub, []byte(`{"title":"hi"}`), opts); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if string(received) != `{"title":"hi"}` {
+		t.Errorf("received %q", received)
+	}
+
+	status = http.StatusGone
+	if err := Send(context.Background(), srv.Client(), keys, sub, []byte("x"), opts); !errors.Is(err, ErrGone) {
+		t.Errorf("Send to gone subscription = %v, want ErrGone", err)
+	}
+
+	status = http.StatusTooManyRequests
+	if err := Send(context.Background(), srv.Client(), keys, sub, []byte("x"), opts); err == nil || errors.Is(err, ErrGone) {
+		t.Errorf("Send with 429 = %v, want a temporary error", err)
+	}
+
+	if err := Send(context.Background(), srv.Client(), keys, sub, make([]byte, MaxPayload+1), opts); !errors.Is(err, ErrPayloadTooLarge) {
+		t.Errorf("oversized payload = %v, want ErrPayloadTooLarge", err)
+	}
+}
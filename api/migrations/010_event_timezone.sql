@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE events ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- +goose Down
+ALTER TABLE events DROP COLUMN timezone;
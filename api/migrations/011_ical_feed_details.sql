@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE ical_tokens ADD COLUMN alarm_minutes INTEGER CHECK (alarm_minutes IS NULL OR (alarm_minutes >= 0 AND alarm_minutes <= 10080));
+
+-- Tombstones for deleted shifts so iCal feeds can emit STATUS:CANCELLED
+-- instead of letting events silently vanish from subscribed calendars.
+CREATE TABLE shift_deletions (
+    shift_id UUID PRIMARY KEY,
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    start_time TIMESTAMPTZ NOT NULL,
+    end_time TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    deleted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_shift_deletions_event_id ON shift_deletions(event_id, deleted_at);
+CREATE INDEX idx_shift_deletions_user_id ON shift_deletions(user_id, deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS shift_deletions;
+ALTER TABLE ical_tokens DROP COLUMN alarm_minutes;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Distinguish entries entered in the availability grid from entries derived
+-- from an imported calendar, so each can be replaced without the other.
+ALTER TABLE user_availability ADD COLUMN source VARCHAR(10) NOT NULL DEFAULT 'manual' CHECK (source IN ('manual', 'ical'));
+
+CREATE TABLE availability_ical_feeds (
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    last_synced_at TIMESTAMPTZ,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (event_id, user_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS availability_ical_feeds;
+DELETE FROM user_availability WHERE source = 'ical';
+ALTER TABLE user_availability DROP COLUMN source;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Counter bumped on every change that affects rendered exports (shifts,
+-- coverage, hidden hours, event teams). Cached exports are keyed on it.
+CREATE TABLE event_plan_versions (
+    event_id UUID PRIMARY KEY REFERENCES events(id) ON DELETE CASCADE,
+    version BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS event_plan_versions;
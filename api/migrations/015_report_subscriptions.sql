@@ -0,0 +1,30 @@
+-- +goose Up
+-- Scheduled email delivery of event exports. send_time is a wall-clock time
+-- in the event's time zone; next_run_at is recomputed after every run.
+CREATE TABLE report_subscriptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    format VARCHAR(10) NOT NULL CHECK (format IN ('csv', 'pdf', 'ical')),
+    pdf_layout VARCHAR(20) NOT NULL DEFAULT 'grid',
+    team_ids UUID[] NOT NULL DEFAULT '{}',
+    time_window VARCHAR(20) NOT NULL DEFAULT 'all' CHECK (time_window IN ('all', 'next_24h', 'next_day', 'next_7d')),
+    recipient_user_ids UUID[] NOT NULL DEFAULT '{}',
+    recipient_emails VARCHAR[] NOT NULL DEFAULT '{}',
+    frequency VARCHAR(10) NOT NULL CHECK (frequency IN ('daily', 'weekly')),
+    weekday SMALLINT CHECK (weekday BETWEEN 0 AND 6),
+    send_time VARCHAR(5) NOT NULL,
+    is_enabled BOOLEAN NOT NULL DEFAULT true,
+    next_run_at TIMESTAMPTZ NOT NULL,
+    last_run_at TIMESTAMPTZ,
+    last_error TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_report_subscriptions_event ON report_subscriptions(event_id);
+CREATE INDEX idx_report_subscriptions_due ON report_subscriptions(next_run_at) WHERE is_enabled;
+
+-- +goose Down
+DROP TABLE IF EXISTS report_subscriptions;
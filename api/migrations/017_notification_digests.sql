@@ -0,0 +1,27 @@
+-- +goose Up
+-- Per-channel delivery mode: send each notification immediately or collect
+-- them into hourly or daily digests.
+CREATE TABLE notification_delivery_modes (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('in_app', 'email')),
+    mode VARCHAR(10) NOT NULL CHECK (mode IN ('immediate', 'hourly', 'daily')),
+    PRIMARY KEY (user_id, channel)
+);
+
+-- Notifications waiting for the next digest. message holds the
+-- language-independent notification, rendered when the digest is sent.
+CREATE TABLE pending_notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_id UUID REFERENCES events(id) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL,
+    mode VARCHAR(10) NOT NULL,
+    message JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_pending_notifications_created ON pending_notifications(created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS pending_notifications;
+DROP TABLE IF EXISTS notification_delivery_modes;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Messages from event admins to the event's volunteers. Empty team_ids means
+-- all teams; a NULL window means the whole event. Untargeted announcements
+-- are also shown on the public event page.
+CREATE TABLE announcements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    title VARCHAR(200) NOT NULL,
+    body TEXT NOT NULL,
+    team_ids UUID[] NOT NULL DEFAULT '{}',
+    window_start TIMESTAMPTZ,
+    window_end TIMESTAMPTZ,
+    recipient_count INTEGER NOT NULL DEFAULT 0,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((window_start IS NULL) = (window_end IS NULL))
+);
+
+CREATE INDEX idx_announcements_event ON announcements(event_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS announcements;
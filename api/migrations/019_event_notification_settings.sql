@@ -0,0 +1,27 @@
+-- +goose Up
+-- Per-event notification state. 'watching' makes the user a recipient of the
+-- event's notifications without having shifts in it; 'muted' turns off all
+-- of the event's notifications except triggers re-enabled below.
+CREATE TABLE event_notification_settings (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    state VARCHAR(10) NOT NULL CHECK (state IN ('watching', 'muted')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, event_id)
+);
+
+CREATE INDEX idx_event_notification_settings_event ON event_notification_settings(event_id) WHERE state = 'watching';
+
+-- Event-scoped overrides of notification_preferences.
+CREATE TABLE event_notification_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    trigger_type VARCHAR(50) NOT NULL,
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('in_app', 'email')),
+    is_enabled BOOLEAN NOT NULL,
+    PRIMARY KEY (user_id, event_id, trigger_type, channel)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS event_notification_preferences;
+DROP TABLE IF EXISTS event_notification_settings;
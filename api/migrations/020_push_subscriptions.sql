@@ -0,0 +1,46 @@
+-- +goose Up
+-- VAPID key pair identifying this server to browser push services. Generated
+-- on first start; replacing it invalidates all push subscriptions.
+CREATE TABLE vapid_keys (
+    id SMALLINT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    public_key TEXT NOT NULL,
+    private_key TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Web Push subscriptions, one per browser or device.
+CREATE TABLE push_subscriptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    endpoint TEXT NOT NULL UNIQUE,
+    p256dh TEXT NOT NULL,
+    auth TEXT NOT NULL,
+    user_agent TEXT,
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_push_subscriptions_user ON push_subscriptions(user_id);
+
+ALTER TABLE notification_preferences DROP CONSTRAINT notification_preferences_channel_check;
+ALTER TABLE notification_preferences ADD CONSTRAINT notification_preferences_channel_check
+    CHECK (channel IN ('in_app', 'email', 'push', 'webhook'));
+
+ALTER TABLE event_notification_preferences DROP CONSTRAINT event_notification_preferences_channel_check;
+ALTER TABLE event_notification_preferences ADD CONSTRAINT event_notification_preferences_channel_check
+    CHECK (channel IN ('in_app', 'email', 'push'));
+
+-- +goose Down
+DELETE FROM event_notification_preferences WHERE channel = 'push';
+ALTER TABLE event_notification_preferences DROP CONSTRAINT event_notification_preferences_channel_check;
+ALTER TABLE event_notification_preferences ADD CONSTRAINT event_notification_preferences_channel_check
+    CHECK (channel IN ('in_app', 'email'));
+
+DELETE FROM notification_preferences WHERE channel = 'push';
+ALTER TABLE notification_preferences DROP CONSTRAINT notification_preferences_channel_check;
+ALTER TABLE notification_preferences ADD CONSTRAINT notification_preferences_channel_check
+    CHECK (channel IN ('in_app', 'email', 'webhook'));
+
+DROP TABLE IF EXISTS push_subscriptions;
+DROP TABLE IF EXISTS vapid_keys;
@@ -0,0 +1,46 @@
+-- +goose Up
+-- Webhooks that keep failing are disabled automatically; the reason is shown
+-- to admins until the webhook is enabled again.
+ALTER TABLE webhook_configs
+    ADD COLUMN consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN disabled_reason TEXT;
+
+-- Outgoing webhook deliveries. The body is rendered when the trigger fires and
+-- sent by a background worker, retrying with backoff until it succeeds or the
+-- attempts run out.
+CREATE TABLE webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    webhook_id UUID NOT NULL REFERENCES webhook_configs(id) ON DELETE CASCADE,
+    trigger_type VARCHAR(100) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    redelivery_of UUID REFERENCES webhook_deliveries(id) ON DELETE SET NULL,
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at DESC);
+
+-- One row per HTTP request made for a delivery.
+CREATE TABLE webhook_delivery_attempts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    delivery_id UUID NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+    attempt INTEGER NOT NULL,
+    response_status INTEGER,
+    duration_ms INTEGER NOT NULL,
+    error TEXT,
+    response_body TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_delivery_attempts_delivery ON webhook_delivery_attempts(delivery_id, attempt);
+
+-- +goose Down
+DROP TABLE IF EXISTS webhook_delivery_attempts;
+DROP TABLE IF EXISTS webhook_deliveries;
+ALTER TABLE webhook_configs
+    DROP COLUMN IF EXISTS disabled_reason,
+    DROP COLUMN IF EXISTS consecutive_failures;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Webhooks in the template format render their body with a Go text/template
+-- and may send their own content type and extra headers.
+ALTER TABLE webhook_configs
+    ADD COLUMN template TEXT,
+    ADD COLUMN content_type TEXT,
+    ADD COLUMN headers JSONB;
+
+-- +goose Down
+ALTER TABLE webhook_configs
+    DROP COLUMN headers,
+    DROP COLUMN content_type,
+    DROP COLUMN template;
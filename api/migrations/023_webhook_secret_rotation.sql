@@ -0,0 +1,11 @@
+-- +goose Up
+-- After a secret rotation deliveries are signed with the previous secret as
+-- well until it expires, so receivers can switch over without failing.
+ALTER TABLE webhook_configs
+    ADD COLUMN previous_secret TEXT,
+    ADD COLUMN previous_secret_expires_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE webhook_configs
+    DROP COLUMN previous_secret_expires_at,
+    DROP COLUMN previous_secret;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Optional filters narrowing which changes an event webhook is sent for.
+-- Empty arrays and NULL mean no filter.
+ALTER TABLE webhook_configs
+    ADD COLUMN filter_team_ids UUID[] NOT NULL DEFAULT '{}',
+    ADD COLUMN filter_user_ids UUID[] NOT NULL DEFAULT '{}',
+    ADD COLUMN filter_within_hours INTEGER CHECK (filter_within_hours > 0);
+
+-- +goose Down
+ALTER TABLE webhook_configs
+    DROP COLUMN filter_within_hours,
+    DROP COLUMN filter_user_ids,
+    DROP COLUMN filter_team_ids;
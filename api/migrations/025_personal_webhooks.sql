@@ -0,0 +1,14 @@
+-- +goose Up
+-- Personal webhooks belong to a user and are sent for changes to the user's
+-- own shifts in any event. They have neither an event nor global scope.
+ALTER TABLE webhook_configs
+    ADD COLUMN user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    ADD CONSTRAINT webhook_configs_scope_check CHECK (event_id IS NULL OR user_id IS NULL);
+
+CREATE INDEX idx_webhook_configs_user_id ON webhook_configs(user_id) WHERE user_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_webhook_configs_user_id;
+ALTER TABLE webhook_configs
+    DROP CONSTRAINT webhook_configs_scope_check,
+    DROP COLUMN user_id;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Personal API tokens for scripts, sent as "Authorization: Bearer". Only the
+-- SHA-256 hash of a token is stored; token_prefix identifies it in listings.
+CREATE TABLE api_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(255) UNIQUE NOT NULL,
+    token_prefix VARCHAR(32) NOT NULL,
+    scopes TEXT[] NOT NULL CHECK (cardinality(scopes) > 0),
+    event_id UUID REFERENCES events(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    last_used_ip VARCHAR(45),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_api_tokens_user_id ON api_tokens(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS api_tokens;
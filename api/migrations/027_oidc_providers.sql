@@ -0,0 +1,19 @@
+-- +goose Up
+-- OpenID Connect providers are configured from an issuer URL. Their
+-- endpoints come from the issuer's discovery document and are stored in the
+-- existing URL columns; jwks_url verifies ID tokens and end_session_url is
+-- used for RP-initiated logout when rp_logout is on.
+ALTER TABLE oauth_providers
+    ADD COLUMN provider_type VARCHAR(20) NOT NULL DEFAULT 'oauth2' CHECK (provider_type IN ('oauth2', 'oidc')),
+    ADD COLUMN issuer_url VARCHAR(512) NOT NULL DEFAULT '',
+    ADD COLUMN jwks_url VARCHAR(512) NOT NULL DEFAULT '',
+    ADD COLUMN end_session_url VARCHAR(512) NOT NULL DEFAULT '',
+    ADD COLUMN rp_logout BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE oauth_providers
+    DROP COLUMN IF EXISTS rp_logout,
+    DROP COLUMN IF EXISTS end_session_url,
+    DROP COLUMN IF EXISTS jwks_url,
+    DROP COLUMN IF EXISTS issuer_url,
+    DROP COLUMN IF EXISTS provider_type;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Revision counter for iCal SEQUENCE. Updates increment it; a tombstone
+-- carries the revision after the deletion.
+ALTER TABLE shifts ADD COLUMN sequence INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE shift_deletions ADD COLUMN sequence INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE shift_deletions DROP COLUMN IF EXISTS sequence;
+ALTER TABLE shifts DROP COLUMN IF EXISTS sequence;
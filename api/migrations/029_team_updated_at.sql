@@ -0,0 +1,7 @@
+-- +goose Up
+-- Team renames change iCal feed content, so feeds need to see them in their
+-- ETag like they see user changes.
+ALTER TABLE teams ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- +goose Down
+ALTER TABLE teams DROP COLUMN IF EXISTS updated_at;
@@ -16,12 +16,14 @@ import { OAuthProvidersPage } from "@/pages/OAuthProvidersPage";
 import { NotificationPreferencesPage } from "@/pages/NotificationPreferencesPage";
 import { SMTPSettingsPage } from "@/pages/SMTPSettingsPage";
 import { ICalSettingsPage } from "@/pages/ICalSettingsPage";
+import { APITokensPage } from "@/pages/APITokensPage";
 import { AuditLogPage } from "@/pages/AuditLogPage";
 import { PublicEventPage } from "@/pages/PublicEventPage";
 import { AppSettingsPage } from "@/pages/AppSettingsPage";
 import { AdminDashboardPage } from "@/pages/AdminDashboardPage";
 import { AdminPage } from "@/pages/AdminPage";
 import { AdminWebhooksPage } from "@/pages/AdminWebhooksPage";
+import { AdminAPITokensPage } from "@/pages/AdminAPITokensPage";
 import { EventsPage } from "@/pages/EventsPage";
 import { UserManagementPage } from "@/pages/UserManagementPage";
 
@@ -178,6 +180,14 @@ function App() {
                     </ProtectedRoute>
                   }
                 />
+                <Route
+                  path="settings/api-tokens"
+                  element={
+                    <ProtectedRoute>
+                      <APITokensPage />
+                    </ProtectedRoute>
+                  }
+                />
                 <Route
                   path="admin"
                   element={
@@ -193,6 +203,7 @@ function App() {
                   <Route path="smtp" element={<SMTPSettingsPage />} />
                   <Route path="dummy-accounts" element={<Navigate to="/admin/users" replace />} />
                   <Route path="webhooks" element={<AdminWebhooksPage />} />
+                  <Route path="api-tokens" element={<AdminAPITokensPage />} />
                   <Route path="audit-log" element={<AuditLogPage />} />
                 </Route>
                 <Route
@@ -0,0 +1,27 @@
+import { api } from "./client";
+import type { APIToken, CreatedAPIToken, CreateAPITokenRequest } from "./types";
+
+export const apiTokensApi = {
+  list: async () => {
+    const res = await api.get<APIToken[]>("/users/me/api-tokens");
+    return res.data!;
+  },
+
+  create: async (data: CreateAPITokenRequest) => {
+    const res = await api.post<CreatedAPIToken>("/users/me/api-tokens", data);
+    return res.data!;
+  },
+
+  revoke: async (tokenId: string) => {
+    await api.delete(`/users/me/api-tokens/${tokenId}`);
+  },
+
+  listAll: async () => {
+    const res = await api.get<APIToken[]>("/admin/api-tokens");
+    return res.data!;
+  },
+
+  revokeAny: async (tokenId: string) => {
+    await api.delete(`/admin/api-tokens/${tokenId}`);
+  },
+};
@@ -4,7 +4,7 @@ import type { User, LoginRequest, RegisterRequest, LoginResult, TOTPSetupResult
 export const authApi = {
   login: (data: LoginRequest) => api.post<LoginResult>("/auth/login", data),
   register: (data: RegisterRequest) => api.post<User>("/auth/register", data),
-  logout: () => api.post<{ message: string }>("/auth/logout"),
+  logout: () => api.post<{ message: string; logout_url?: string }>("/auth/logout"),
   me: () => api.get<User>("/auth/me"),
   updateProfile: (data: { full_name?: string; display_name?: string; email?: string; password?: string; time_format?: string }) =>
     api.put<User>("/auth/me", data),
@@ -1,5 +1,12 @@
 import { api } from "./client";
-import type { Notification, NotificationPreference, UpdatePreferenceRequest } from "./types";
+import type {
+  EventNotificationSettings,
+  EventNotificationState,
+  Notification,
+  NotificationPreference,
+  PushSubscriptionInfo,
+  UpdatePreferenceRequest,
+} from "./types";
 
 export const notificationsApi = {
   list: (limit = 50, offset = 0) =>
@@ -19,4 +26,22 @@ export const notificationsApi = {
 
   updatePreference: (data: UpdatePreferenceRequest) =>
     api.put<{ message: string }>("/notifications/preferences", data),
+
+  getPushPublicKey: () =>
+    api.get<{ public_key: string }>("/notifications/push/public-key"),
+
+  listPushSubscriptions: () =>
+    api.get<PushSubscriptionInfo[]>("/notifications/push/subscriptions"),
+
+  subscribePush: (subscription: PushSubscriptionJSON) =>
+    api.post<PushSubscriptionInfo>("/notifications/push/subscriptions", subscription),
+
+  deletePushSubscription: (id: string) =>
+    api.delete<{ message: string }>(`/notifications/push/subscriptions/${id}`),
+
+  getEventSettings: (slug: string) =>
+    api.get<EventNotificationSettings>(`/events/${slug}/notifications`),
+
+  setEventState: (slug: string, state: EventNotificationState) =>
+    api.put<{ message: string }>(`/events/${slug}/notifications/state`, { state }),
 };
@@ -1,5 +1,5 @@
 import { api } from "./client";
-import type { Event, GridData, PrintConfig } from "./types";
+import type { Event, GridData, PrintConfig, PublicAnnouncement } from "./types";
 
 const API_BASE = "/api";
 
@@ -14,6 +14,11 @@ export const publicApi = {
     return res.data!;
   },
 
+  getAnnouncements: async (slug: string) => {
+    const res = await api.get<PublicAnnouncement[]>(`/public/events/${slug}/announcements`);
+    return res.data!;
+  },
+
   downloadPDF: async (slug: string, config: PrintConfig): Promise<Blob> => {
     const params = new URLSearchParams();
     params.set("layout", config.layout);
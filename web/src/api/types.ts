@@ -205,6 +205,7 @@ export interface Availability {
   end_time: string;
   status: "available" | "preferred" | "unavailable";
   note: string | null;
+  source: "manual" | "ical";
 }
 
 export interface AvailabilityGridEntry {
@@ -269,13 +270,20 @@ export interface UserListResponse {
 }
 
 // OAuth
+export type OAuthProviderType = "oauth2" | "oidc";
+
 export interface OAuthProvider {
   id: string;
   name: string;
+  provider_type: OAuthProviderType;
   client_id: string;
+  issuer_url: string;
   authorize_url: string;
   token_url: string;
   userinfo_url: string;
+  jwks_url: string;
+  end_session_url: string;
+  rp_logout: boolean;
   scopes: string;
   is_enabled: boolean;
   created_at: string;
@@ -288,18 +296,24 @@ export interface PublicOAuthProvider {
 
 export interface CreateOAuthProviderRequest {
   name: string;
+  provider_type: OAuthProviderType;
   client_id: string;
   client_secret: string;
+  issuer_url: string;
   authorize_url: string;
   token_url: string;
   userinfo_url: string;
   scopes: string;
+  rp_logout: boolean;
 }
 
 export interface UpdateOAuthProviderRequest {
   name?: string;
+  provider_type?: OAuthProviderType;
   client_id?: string;
   client_secret?: string;
+  issuer_url?: string;
+  rp_logout?: boolean;
   authorize_url?: string;
   token_url?: string;
   userinfo_url?: string;
@@ -329,6 +343,14 @@ export interface TOTPChallenge {
 
 export type LoginResult = User | TOTPChallenge;
 
+// Announcements
+export interface PublicAnnouncement {
+  id: string;
+  title: string;
+  body: string;
+  created_at: string;
+}
+
 // Notifications
 export interface Notification {
   id: string;
@@ -352,16 +374,81 @@ export interface UpdatePreferenceRequest {
   is_enabled: boolean;
 }
 
+export interface PushSubscriptionInfo {
+  id: string;
+  endpoint: string;
+  user_agent: string | null;
+  expires_at: string | null;
+  last_used_at: string | null;
+  created_at: string;
+}
+
+export type EventNotificationState = "default" | "watching" | "muted";
+
+export interface EventNotificationSettings {
+  event_id: string;
+  state: EventNotificationState;
+  preferences: NotificationPreference[];
+}
+
 // Webhooks
 export interface Webhook {
   id: string;
   event_id: string | null;
+  user_id?: string;
   name: string;
   url: string;
   format: string;
   trigger_types: string[];
   is_enabled: boolean;
   created_at: string;
+  consecutive_failures: number;
+  disabled_reason: string | null;
+  template?: string;
+  content_type?: string;
+  headers?: Record<string, string>;
+  previous_secret_expires_at?: string;
+  filters: WebhookFilters;
+}
+
+export interface WebhookFilters {
+  team_ids: string[];
+  user_ids: string[];
+  within_hours: number | null;
+}
+
+export interface WebhookSecretRotation {
+  secret: string;
+  previous_secret_expires_at: string | null;
+}
+
+export interface WebhookDelivery {
+  id: string;
+  webhook_id: string;
+  trigger_type: string;
+  status: "pending" | "delivered" | "failed";
+  attempts: number;
+  next_attempt_at: string | null;
+  redelivery_of: string | null;
+  delivered_at: string | null;
+  created_at: string;
+  last_response_status: number | null;
+  last_duration_ms: number | null;
+  last_error: string | null;
+}
+
+export interface WebhookDeliveryAttempt {
+  attempt: number;
+  response_status: number | null;
+  duration_ms: number;
+  error: string | null;
+  response_body: string | null;
+  created_at: string;
+}
+
+export interface WebhookDeliveryDetail extends WebhookDelivery {
+  payload: string;
+  history: WebhookDeliveryAttempt[];
 }
 
 export interface CreateWebhookRequest {
@@ -370,6 +457,10 @@ export interface CreateWebhookRequest {
   secret: string;
   format?: string;
   trigger_types: string[];
+  template?: string;
+  content_type?: string;
+  headers?: Record<string, string>;
+  filters?: WebhookFilters;
 }
 
 export interface UpdateWebhookRequest {
@@ -379,6 +470,33 @@ export interface UpdateWebhookRequest {
   format?: string;
   trigger_types?: string[];
   is_enabled?: boolean;
+  template?: string;
+  content_type?: string;
+  headers?: Record<string, string>;
+  filters?: WebhookFilters;
+}
+
+export interface WebhookPreviewRequest {
+  trigger_type: string;
+  template: string;
+  content_type?: string;
+  headers?: Record<string, string>;
+}
+
+export interface WebhookTrigger {
+  type: string;
+  scope: "event" | "global";
+  description: string;
+  example: unknown;
+}
+
+export interface WebhookPreview {
+  trigger_type: string;
+  payload: unknown;
+  body: string;
+  content_type: string;
+  headers: Record<string, string>;
+  error?: string;
 }
 
 // SMTP
@@ -414,6 +532,34 @@ export interface ICalToken {
   url: string;
 }
 
+export type APITokenScope = "read" | "shifts:write" | "availability:write" | "admin";
+
+export interface APIToken {
+  id: string;
+  user_id: string;
+  username: string;
+  name: string;
+  prefix: string;
+  scopes: APITokenScope[];
+  event_id: string | null;
+  event_slug: string | null;
+  expires_at: string | null;
+  last_used_at: string | null;
+  last_used_ip: string | null;
+  created_at: string;
+}
+
+export interface CreatedAPIToken extends APIToken {
+  token: string;
+}
+
+export interface CreateAPITokenRequest {
+  name: string;
+  scopes: APITokenScope[];
+  event_id?: string;
+  expires_at?: string;
+}
+
 export interface CreateICalTokenRequest {
   label: string;
   scope: "user" | "event" | "team";
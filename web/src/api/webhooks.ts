@@ -1,5 +1,15 @@
 import { api } from "./client";
-import type { Webhook, CreateWebhookRequest, UpdateWebhookRequest } from "./types";
+import type {
+  Webhook,
+  CreateWebhookRequest,
+  UpdateWebhookRequest,
+  WebhookDelivery,
+  WebhookDeliveryDetail,
+  WebhookPreview,
+  WebhookPreviewRequest,
+  WebhookSecretRotation,
+  WebhookTrigger,
+} from "./types";
 
 export const webhooksApi = {
   list: (slug: string) =>
@@ -11,11 +21,29 @@ export const webhooksApi = {
   update: (slug: string, webhookId: string, data: UpdateWebhookRequest) =>
     api.put<Webhook>(`/events/${slug}/webhooks/${webhookId}`, data),
 
+  triggers: (slug: string) =>
+    api.get<WebhookTrigger[]>(`/events/${slug}/webhooks/triggers`),
+
+  preview: (slug: string, data: WebhookPreviewRequest) =>
+    api.post<WebhookPreview>(`/events/${slug}/webhooks/preview`, data),
+
   delete: (slug: string, webhookId: string) =>
     api.delete<{ message: string }>(`/events/${slug}/webhooks/${webhookId}`),
 
   test: (slug: string, webhookId: string) =>
     api.post<{ message: string }>(`/events/${slug}/webhooks/${webhookId}/test`),
+
+  rotateSecret: (slug: string, webhookId: string) =>
+    api.post<WebhookSecretRotation>(`/events/${slug}/webhooks/${webhookId}/rotate-secret`),
+
+  listDeliveries: (slug: string, webhookId: string, limit = 20, offset = 0) =>
+    api.get<WebhookDelivery[]>(`/events/${slug}/webhooks/${webhookId}/deliveries?limit=${limit}&offset=${offset}`),
+
+  getDelivery: (slug: string, webhookId: string, deliveryId: string) =>
+    api.get<WebhookDeliveryDetail>(`/events/${slug}/webhooks/${webhookId}/deliveries/${deliveryId}`),
+
+  redeliver: (slug: string, webhookId: string, deliveryId: string) =>
+    api.post<WebhookDelivery>(`/events/${slug}/webhooks/${webhookId}/deliveries/${deliveryId}/redeliver`),
 };
 
 export const adminWebhooksApi = {
@@ -28,9 +56,98 @@ export const adminWebhooksApi = {
   update: (webhookId: string, data: UpdateWebhookRequest) =>
     api.put<Webhook>(`/admin/webhooks/${webhookId}`, data),
 
+  triggers: () =>
+    api.get<WebhookTrigger[]>("/admin/webhooks/triggers"),
+
+  preview: (data: WebhookPreviewRequest) =>
+    api.post<WebhookPreview>("/admin/webhooks/preview", data),
+
   delete: (webhookId: string) =>
     api.delete<{ message: string }>(`/admin/webhooks/${webhookId}`),
 
   test: (webhookId: string) =>
     api.post<{ message: string }>(`/admin/webhooks/${webhookId}/test`),
+
+  rotateSecret: (webhookId: string) =>
+    api.post<WebhookSecretRotation>(`/admin/webhooks/${webhookId}/rotate-secret`),
+
+  listDeliveries: (webhookId: string, limit = 20, offset = 0) =>
+    api.get<WebhookDelivery[]>(`/admin/webhooks/${webhookId}/deliveries?limit=${limit}&offset=${offset}`),
+
+  getDelivery: (webhookId: string, deliveryId: string) =>
+    api.get<WebhookDeliveryDetail>(`/admin/webhooks/${webhookId}/deliveries/${deliveryId}`),
+
+  redeliver: (webhookId: string, deliveryId: string) =>
+    api.post<WebhookDelivery>(`/admin/webhooks/${webhookId}/deliveries/${deliveryId}/redeliver`),
+};
+
+export const personalWebhooksApi = {
+  list: () =>
+    api.get<Webhook[]>("/users/me/webhooks"),
+
+  create: (data: CreateWebhookRequest) =>
+    api.post<Webhook>("/users/me/webhooks", data),
+
+  update: (webhookId: string, data: UpdateWebhookRequest) =>
+    api.put<Webhook>(`/users/me/webhooks/${webhookId}`, data),
+
+  triggers: () =>
+    api.get<WebhookTrigger[]>("/users/me/webhooks/triggers"),
+
+  preview: (data: WebhookPreviewRequest) =>
+    api.post<WebhookPreview>("/users/me/webhooks/preview", data),
+
+  delete: (webhookId: string) =>
+    api.delete<{ message: string }>(`/users/me/webhooks/${webhookId}`),
+
+  test: (webhookId: string) =>
+    api.post<{ message: string }>(`/users/me/webhooks/${webhookId}/test`),
+
+  rotateSecret: (webhookId: string) =>
+    api.post<WebhookSecretRotation>(`/users/me/webhooks/${webhookId}/rotate-secret`),
+
+  listDeliveries: (webhookId: string, limit = 20, offset = 0) =>
+    api.get<WebhookDelivery[]>(`/users/me/webhooks/${webhookId}/deliveries?limit=${limit}&offset=${offset}`),
+
+  getDelivery: (webhookId: string, deliveryId: string) =>
+    api.get<WebhookDeliveryDetail>(`/users/me/webhooks/${webhookId}/deliveries/${deliveryId}`),
+
+  redeliver: (webhookId: string, deliveryId: string) =>
+    api.post<WebhookDelivery>(`/users/me/webhooks/${webhookId}/deliveries/${deliveryId}/redeliver`),
 };
+
+// Where a set of webhooks lives: an event, the instance (global) or the
+// logged-in user (personal).
+export interface WebhookScope {
+  slug?: string;
+  global?: boolean;
+  personal?: boolean;
+}
+
+// scopedWebhooksApi returns the webhook endpoints of a scope, with the event
+// slug already bound for event webhooks.
+export function scopedWebhooksApi({ slug, global, personal }: WebhookScope): typeof adminWebhooksApi {
+  if (global) return adminWebhooksApi;
+  if (personal) return personalWebhooksApi;
+  const s = slug!;
+  return {
+    list: () => webhooksApi.list(s),
+    create: (data) => webhooksApi.create(s, data),
+    update: (webhookId, data) => webhooksApi.update(s, webhookId, data),
+    triggers: () => webhooksApi.triggers(s),
+    preview: (data) => webhooksApi.preview(s, data),
+    delete: (webhookId) => webhooksApi.delete(s, webhookId),
+    test: (webhookId) => webhooksApi.test(s, webhookId),
+    rotateSecret: (webhookId) => webhooksApi.rotateSecret(s, webhookId),
+    listDeliveries: (webhookId, limit, offset) => webhooksApi.listDeliveries(s, webhookId, limit, offset),
+    getDelivery: (webhookId, deliveryId) => webhooksApi.getDelivery(s, webhookId, deliveryId),
+    redeliver: (webhookId, deliveryId) => webhooksApi.redeliver(s, webhookId, deliveryId),
+  };
+}
+
+// webhookQueryKey is the query key prefix for the webhooks of a scope.
+export function webhookQueryKey({ slug, global, personal }: WebhookScope): unknown[] {
+  if (global) return ["admin", "webhooks"];
+  if (personal) return ["users", "me", "webhooks"];
+  return ["events", slug, "webhooks"];
+}
@@ -4,7 +4,7 @@ import { useMyAvailability, useSetMyAvailability } from "@/hooks/useAvailability
 import { useEscapeKey } from "@/hooks/useKeyboard";
 import { generateTimeSlots, granularityToMinutes, formatSlotTime, formatDayHeader, isNewDay } from "@/lib/time";
 import { useTimeFormat } from "@/hooks/useTimeFormat";
-import type { Event, AvailabilityEntry } from "@/api/types";
+import type { Event, Availability, AvailabilityEntry } from "@/api/types";
 
 type AvailabilityStatus = "available" | "preferred" | "unavailable";
 
@@ -25,6 +25,28 @@ const STATUS_LABELS: Record<AvailabilityStatus, string> = {
   unavailable: "admin:availability.unavailable",
 };
 
+// slotsOf maps the slots covered by entries to their status. Manual and
+// imported (calendar) entries are kept apart: imported slots are read-only
+// here and never sent back as manual entries.
+function slotsOf(entries: Availability[] | undefined, granMinutes: number) {
+  const manual = new Map<string, AvailabilityStatus>();
+  const imported = new Set<string>();
+  const slotMs = granMinutes * 60 * 1000;
+  for (const entry of entries ?? []) {
+    const start = new Date(entry.start_time).getTime();
+    const end = new Date(entry.end_time).getTime();
+    for (let t = start; t < end; t += slotMs) {
+      const iso = new Date(t).toISOString();
+      if (entry.source === "ical") {
+        imported.add(iso);
+      } else {
+        manual.set(iso, entry.status);
+      }
+    }
+  }
+  return { manual, imported };
+}
+
 export function AvailabilityEditor({ event, onClose }: AvailabilityEditorProps) {
   const { t } = useTranslation(["admin", "common"]);
   const hour12 = useTimeFormat();
@@ -42,39 +64,23 @@ export function AvailabilityEditor({ event, onClose }: AvailabilityEditorProps)
   const [paintStatus, setPaintStatus] = useState<AvailabilityStatus>("available");
   const [isPainting, setIsPainting] = useState(false);
 
-  // Build local availability map: slot ISO string -> status
-  const [slotMap, setSlotMap] = useState<Map<string, AvailabilityStatus>>(() => {
-    const map = new Map<string, AvailabilityStatus>();
-    if (myAvailability) {
-      for (const entry of myAvailability) {
-        // Mark all slots covered by this entry
-        const start = new Date(entry.start_time).getTime();
-        const end = new Date(entry.end_time).getTime();
-        const slotMs = granMinutes * 60 * 1000;
-        for (let t = start; t < end; t += slotMs) {
-          map.set(new Date(t).toISOString(), entry.status);
-        }
-      }
-    }
-    return map;
-  });
+  // Build local availability map of manual entries: slot ISO string -> status
+  const [slotMap, setSlotMap] = useState<Map<string, AvailabilityStatus>>(
+    () => slotsOf(myAvailability, granMinutes).manual,
+  );
+  const importedSlots = useMemo(
+    () => slotsOf(myAvailability, granMinutes).imported,
+    [myAvailability, granMinutes],
+  );
 
   // Re-sync when myAvailability loads
   useMemo(() => {
     if (!myAvailability) return;
-    const map = new Map<string, AvailabilityStatus>();
-    for (const entry of myAvailability) {
-      const start = new Date(entry.start_time).getTime();
-      const end = new Date(entry.end_time).getTime();
-      const slotMs = granMinutes * 60 * 1000;
-      for (let t = start; t < end; t += slotMs) {
-        map.set(new Date(t).toISOString(), entry.status);
-      }
-    }
-    setSlotMap(map);
+    setSlotMap(slotsOf(myAvailability, granMinutes).manual);
   }, [myAvailability, granMinutes]);
 
   const toggleSlot = useCallback((slotIso: string) => {
+    if (importedSlots.has(slotIso)) return;
     setSlotMap((prev) => {
       const next = new Map(prev);
       if (next.get(slotIso) === paintStatus) {
@@ -84,15 +90,16 @@ export function AvailabilityEditor({ event, onClose }: AvailabilityEditorProps)
       }
       return next;
     });
-  }, [paintStatus]);
+  }, [paintStatus, importedSlots]);
 
   const paintSlot = useCallback((slotIso: string) => {
+    if (importedSlots.has(slotIso)) return;
     setSlotMap((prev) => {
       const next = new Map(prev);
       next.set(slotIso, paintStatus);
       return next;
     });
-  }, [paintStatus]);
+  }, [paintStatus, importedSlots]);
 
   function handleMouseDown(slotIso: string) {
     setIsPainting(true);
@@ -109,7 +116,8 @@ export function AvailabilityEditor({ event, onClose }: AvailabilityEditorProps)
     setIsPainting(false);
   }
 
-  // Convert slot map to contiguous entries for the API
+  // Convert slot map to contiguous entries for the API. Imported slots aren't
+  // in the map, so they stay imported.
   function toEntries(): AvailabilityEntry[] {
     const entries: AvailabilityEntry[] = [];
     const sortedSlots = Array.from(slotMap.entries()).sort(
@@ -199,7 +207,8 @@ export function AvailabilityEditor({ event, onClose }: AvailabilityEditorProps)
         >
           {slots.map((slot, i) => {
             const iso = slot.toISOString();
-            const status = slotMap.get(iso);
+            const imported = importedSlots.has(iso);
+            const status: AvailabilityStatus | undefined = imported ? "unavailable" : slotMap.get(iso);
             const showDay = isNewDay(slot, i > 0 ? slots[i - 1] : null);
 
             return (
@@ -210,7 +219,9 @@ export function AvailabilityEditor({ event, onClose }: AvailabilityEditorProps)
                   </div>
                 )}
                 <div
-                  className={`w-12 h-7 border cursor-pointer text-[10px] leading-7 text-center transition-colors ${
+                  className={`w-12 h-7 border text-[10px] leading-7 text-center transition-colors ${
+                    imported ? "cursor-not-allowed border-dashed opacity-70" : "cursor-pointer"
+                  } ${
                     status
                       ? `border-[${STATUS_COLORS[status].border}]`
                       : "border-[var(--color-border)] bg-[var(--color-background)] hover:bg-[var(--color-muted)]"
@@ -221,7 +232,9 @@ export function AvailabilityEditor({ event, onClose }: AvailabilityEditorProps)
                     handleMouseDown(iso);
                   }}
                   onMouseEnter={() => handleMouseEnter(iso)}
-                  title={`${formatSlotTime(slot, hour12)} - ${status || "unset"}`}
+                  title={`${formatSlotTime(slot, hour12)} - ${
+                    imported ? t("admin:availability.imported", "Unavailable (imported from your calendar)") : status || "unset"
+                  }`}
                 >
                   {formatSlotTime(slot, hour12)}
                 </div>
@@ -241,6 +254,15 @@ export function AvailabilityEditor({ event, onClose }: AvailabilityEditorProps)
               {t(STATUS_LABELS[status])}
             </div>
           ))}
+          {importedSlots.size > 0 && (
+            <div className="flex items-center gap-1">
+              <div
+                className="h-3 w-3 rounded-sm border border-dashed opacity-70"
+                style={{ backgroundColor: STATUS_COLORS.unavailable.bg, borderColor: STATUS_COLORS.unavailable.border }}
+              />
+              {t("admin:availability.imported", "Unavailable (imported from your calendar)")}
+            </div>
+          )}
         </div>
 
         {/* Actions */}
@@ -5,6 +5,7 @@ const TABS = [
   { path: "/settings/security", labelKey: "nav.settings" },
   { path: "/settings/notifications", labelKey: "nav.notification_prefs" },
   { path: "/settings/ical", labelKey: "nav.ical_subscriptions" },
+  { path: "/settings/api-tokens", labelKey: "nav.api_tokens" },
 ] as const;
 
 export function SettingsTabs() {
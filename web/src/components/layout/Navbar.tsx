@@ -143,6 +143,7 @@ export function Navbar({ user, onLogout }: NavbarProps) {
               <MobileLink to="/settings/security">{t("nav.settings")}</MobileLink>
               <MobileLink to="/settings/notifications">{t("nav.notification_prefs")}</MobileLink>
               <MobileLink to="/settings/ical">{t("nav.ical_subscriptions")}</MobileLink>
+              <MobileLink to="/settings/api-tokens">{t("nav.api_tokens")}</MobileLink>
               {user.role === "super_admin" && (
                 <>
                   <div className="mt-2 mb-1 text-xs font-semibold text-[var(--color-nav-text)]/50 uppercase tracking-wider">
@@ -154,6 +155,7 @@ export function Navbar({ user, onLogout }: NavbarProps) {
                   <MobileLink to="/admin/oauth">{t("admin:oauth.title")}</MobileLink>
                   <MobileLink to="/admin/smtp">{t("admin:smtp.title")}</MobileLink>
                   <MobileLink to="/admin/webhooks">{t("admin:global_webhooks.title")}</MobileLink>
+                  <MobileLink to="/admin/api-tokens">{t("admin:api_tokens.title")}</MobileLink>
                   <MobileLink to="/admin/audit-log">{t("nav.audit_log")}</MobileLink>
                 </>
               )}
@@ -1,11 +1,13 @@
 import { useState, useRef, useEffect } from "react";
 import { useTranslation } from "react-i18next";
 import { useUnreadCount } from "@/hooks/useNotifications";
+import { useSSE } from "@/hooks/useSSE";
 import { NotificationList } from "./NotificationList";
 
 export function NotificationBell() {
   const { t } = useTranslation("common");
   const { data: unreadCount = 0 } = useUnreadCount();
+  useSSE({ notifications: true });
   const [isOpen, setIsOpen] = useState(false);
   const containerRef = useRef<HTMLDivElement>(null);
 
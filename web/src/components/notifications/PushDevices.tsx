@@ -0,0 +1,100 @@
+import { useEffect, useState } from "react";
+import { useTranslation } from "react-i18next";
+import { useQueryClient } from "@tanstack/react-query";
+import { usePushSubscriptions, useDeletePushSubscription } from "@/hooks/useNotifications";
+import { currentPushSubscription, disablePush, enablePush, isPushSupported } from "@/lib/push";
+
+export function PushDevices() {
+  const { t } = useTranslation(["admin", "common"]);
+  const queryClient = useQueryClient();
+  const { data: devices = [] } = usePushSubscriptions();
+  const deleteDevice = useDeletePushSubscription();
+  const [endpoint, setEndpoint] = useState<string | null>(null);
+  const [busy, setBusy] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+
+  useEffect(() => {
+    currentPushSubscription().then((s) => setEndpoint(s?.endpoint ?? null));
+  }, []);
+
+  const thisDevice = devices.find((d) => d.endpoint === endpoint);
+
+  async function toggle() {
+    setBusy(true);
+    setError(null);
+    try {
+      if (thisDevice) {
+        await disablePush(thisDevice.id);
+        setEndpoint(null);
+      } else {
+        const sub = await enablePush();
+        setEndpoint(sub.endpoint);
+      }
+      queryClient.invalidateQueries({ queryKey: ["notifications", "push-subscriptions"] });
+    } catch (e) {
+      setError(
+        e instanceof Error && e.message === "permission_denied"
+          ? t("notifications.push_permission_denied", "Notifications are blocked for this site in your browser settings.")
+          : t("notifications.push_failed", "Could not enable push notifications on this device.")
+      );
+    } finally {
+      setBusy(false);
+    }
+  }
+
+  return (
+    <div className="mt-8">
+      <h2 className="mb-2 text-lg font-semibold">{t("notifications.push_title", "Push notifications")}</h2>
+      <p className="mb-4 text-sm text-[var(--color-muted-foreground)]">
+        {t("notifications.push_description", "Receive notifications on this browser or phone, even when Rncasp is closed.")}
+      </p>
+
+      {isPushSupported() ? (
+        <button
+          type="button"
+          onClick={toggle}
+          disabled={busy}
+          className="rounded-md bg-[var(--color-primary)] px-4 py-2 text-sm text-[var(--color-primary-foreground)] disabled:opacity-50"
+        >
+          {thisDevice
+            ? t("notifications.push_disable", "Disable on this device")
+            : t("notifications.push_enable", "Enable on this device")}
+        </button>
+      ) : (
+        <p className="text-sm text-[var(--color-muted-foreground)]">
+          {t("notifications.push_unsupported", "This browser does not support push notifications. On iPhone, add Rncasp to the home screen first.")}
+        </p>
+      )}
+      {error && <p className="mt-2 text-sm text-[var(--color-destructive)]">{error}</p>}
+
+      {devices.length > 0 && (
+        <ul className="mt-4 divide-y divide-[var(--color-border)] rounded-lg border border-[var(--color-border)] text-sm">
+          {devices.map((d) => (
+            <li key={d.id} className="flex items-center justify-between gap-4 px-4 py-3">
+              <div className="min-w-0">
+                <div className="truncate">
+                  {d.user_agent || t("notifications.push_unknown_device", "Unknown device")}
+                  {d.id === thisDevice?.id && (
+                    <span className="ml-2 text-xs text-[var(--color-muted-foreground)]">
+                      ({t("notifications.push_this_device", "this device")})
+                    </span>
+                  )}
+                </div>
+                <div className="text-xs text-[var(--color-muted-foreground)]">
+                  {new Date(d.created_at).toLocaleDateString()}
+                </div>
+              </div>
+              <button
+                type="button"
+                onClick={() => deleteDevice.mutate(d.id)}
+                className="shrink-0 text-sm text-[var(--color-destructive)] hover:underline"
+              >
+                {t("common:delete")}
+              </button>
+            </li>
+          ))}
+        </ul>
+      )}
+    </div>
+  );
+}
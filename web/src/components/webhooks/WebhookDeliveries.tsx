@@ -0,0 +1,172 @@
+import { useState } from "react";
+import { useTranslation } from "react-i18next";
+import { useQuery, useMutation, useQueryClient } from "@tanstack/react-query";
+import { scopedWebhooksApi, webhookQueryKey } from "@/api/webhooks";
+import type { WebhookDelivery } from "@/api/types";
+
+const PAGE_SIZE = 20;
+const MAX_LIMIT = 100; // the API returns at most 100 deliveries per request
+
+interface WebhookDeliveriesProps {
+  webhookId: string;
+  slug?: string;
+  global?: boolean;
+  personal?: boolean;
+}
+
+const STATUS_CLASSES: Record<WebhookDelivery["status"], string> = {
+  pending: "bg-[var(--color-muted)] text-[var(--color-muted-foreground)]",
+  delivered: "bg-[var(--color-success-light)] text-[var(--color-success)]",
+  failed: "bg-[var(--color-destructive-light,#fee2e2)] text-[var(--color-destructive)]",
+};
+
+export function WebhookDeliveries({ webhookId, slug, global, personal }: WebhookDeliveriesProps) {
+  const { t } = useTranslation(["admin", "common"]);
+  const queryClient = useQueryClient();
+  const [limit, setLimit] = useState(PAGE_SIZE);
+  const [expandedId, setExpandedId] = useState<string | null>(null);
+
+  const hooksApi = scopedWebhooksApi({ slug, global, personal });
+  const queryKey = [...webhookQueryKey({ slug, global, personal }), webhookId, "deliveries"];
+
+  const { data: deliveries = [], isLoading } = useQuery({
+    queryKey: [...queryKey, limit],
+    queryFn: async () => {
+      const res = await hooksApi.listDeliveries(webhookId, limit);
+      return res.data!;
+    },
+    // Pending deliveries change as the worker retries them
+    refetchInterval: (query) =>
+      query.state.data?.some((d) => d.status === "pending") ? 10_000 : false,
+  });
+
+  const { data: detail } = useQuery({
+    queryKey: [...queryKey, "detail", expandedId],
+    queryFn: async () => {
+      const res = await hooksApi.getDelivery(webhookId, expandedId!);
+      return res.data!;
+    },
+    enabled: !!expandedId,
+  });
+
+  const redeliver = useMutation({
+    mutationFn: (deliveryId: string) => hooksApi.redeliver(webhookId, deliveryId),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey });
+    },
+  });
+
+  if (isLoading) {
+    return <p className="text-xs text-[var(--color-muted-foreground)]">{t("common:loading")}</p>;
+  }
+
+  if (deliveries.length === 0) {
+    return (
+      <p className="text-xs text-[var(--color-muted-foreground)]">
+        {t("webhooks.deliveries_empty", "No deliveries yet.")}
+      </p>
+    );
+  }
+
+  return (
+    <div className="space-y-1">
+      {deliveries.map((d) => (
+        <div key={d.id} className="rounded border border-[var(--color-border)] text-xs">
+          <div className="flex items-center gap-2 px-3 py-2">
+            <button
+              type="button"
+              onClick={() => setExpandedId(expandedId === d.id ? null : d.id)}
+              className="flex min-w-0 flex-1 items-center gap-2 text-left"
+            >
+              <span className={`rounded-full px-2 py-0.5 text-[10px] ${STATUS_CLASSES[d.status]}`}>
+                {t(`webhooks.delivery_status_${d.status}`)}
+              </span>
+              <span className="font-mono">{d.trigger_type}</span>
+              {d.redelivery_of && (
+                <span className="text-[var(--color-muted-foreground)]">
+                  ({t("webhooks.delivery_redelivery", "Redelivery")})
+                </span>
+              )}
+              <span className="text-[var(--color-muted-foreground)]">
+                {d.last_response_status ?? d.last_error ?? ""}
+                {d.last_duration_ms != null && ` · ${d.last_duration_ms} ms`}
+              </span>
+              <span className="ml-auto whitespace-nowrap text-[var(--color-muted-foreground)]">
+                {new Date(d.created_at).toLocaleString()}
+              </span>
+            </button>
+            <button
+              type="button"
+              onClick={() => redeliver.mutate(d.id)}
+              disabled={redeliver.isPending || d.status === "pending"}
+              className="text-[var(--color-primary)] hover:underline disabled:opacity-50"
+            >
+              {t("webhooks.redeliver", "Redeliver")}
+            </button>
+          </div>
+
+          {expandedId === d.id && detail && (
+            <div className="space-y-2 border-t border-[var(--color-border)] px-3 py-2">
+              <div className="text-[var(--color-muted-foreground)]">
+                {t("webhooks.delivery_attempts", { count: detail.attempts })}
+                {detail.next_attempt_at && (
+                  <>
+                    {" · "}
+                    {t("webhooks.delivery_next_attempt", {
+                      time: new Date(detail.next_attempt_at).toLocaleTimeString(),
+                    })}
+                  </>
+                )}
+              </div>
+              {detail.history.length > 0 && (
+                <div>
+                  <div className="mb-1 font-medium">{t("webhooks.delivery_history", "Attempts")}</div>
+                  <ul className="space-y-1">
+                    {detail.history.map((a) => (
+                      <li key={a.attempt}>
+                        <span className="font-mono">
+                          #{a.attempt} {a.response_status ?? t("webhooks.delivery_no_response", "No response")}
+                          {` · ${a.duration_ms} ms · ${new Date(a.created_at).toLocaleString()}`}
+                        </span>
+                        {a.error && <div className="text-[var(--color-destructive)]">{a.error}</div>}
+                        {a.response_body && (
+                          <pre className="mt-0.5 max-h-24 overflow-auto whitespace-pre-wrap break-all rounded bg-[var(--color-muted)] p-1.5">
+                            {a.response_body}
+                          </pre>
+                        )}
+                      </li>
+                    ))}
+                  </ul>
+                </div>
+              )}
+              <div>
+                <div className="mb-1 font-medium">{t("webhooks.delivery_payload", "Payload")}</div>
+                <pre className="max-h-48 overflow-auto whitespace-pre-wrap break-all rounded bg-[var(--color-muted)] p-1.5">
+                  {formatPayload(detail.payload)}
+                </pre>
+              </div>
+            </div>
+          )}
+        </div>
+      ))}
+
+      {deliveries.length >= limit && limit < MAX_LIMIT && (
+        <button
+          type="button"
+          onClick={() => setLimit(Math.min(limit + PAGE_SIZE, MAX_LIMIT))}
+          className="text-xs text-[var(--color-primary)] hover:underline"
+        >
+          {t("webhooks.deliveries_load_more", "Load more")}
+        </button>
+      )}
+    </div>
+  );
+}
+
+function formatPayload(payload: string) {
+  try {
+    return JSON.stringify(JSON.parse(payload), null, 2);
+  } catch {
+    return payload;
+  }
+}
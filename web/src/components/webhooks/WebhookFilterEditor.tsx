@@ -0,0 +1,133 @@
+import { useState } from "react";
+import { useTranslation } from "react-i18next";
+import { useQuery } from "@tanstack/react-query";
+import { usersApi } from "@/api/users";
+import type { WebhookFilters } from "@/api/types";
+import { useEventTeams } from "@/hooks/useEvents";
+import { useSearchUsers } from "@/hooks/useUsers";
+
+interface WebhookFilterEditorProps {
+  slug: string;
+  filters: WebhookFilters;
+  onChange: (filters: WebhookFilters) => void;
+}
+
+export function WebhookFilterEditor({ slug, filters, onChange }: WebhookFilterEditorProps) {
+  const { t } = useTranslation(["admin", "common"]);
+  const { data: teams = [] } = useEventTeams(slug);
+  const [userQuery, setUserQuery] = useState("");
+  const { data: userResults = [] } = useSearchUsers(userQuery);
+
+  function toggleTeam(teamId: string) {
+    onChange({
+      ...filters,
+      team_ids: filters.team_ids.includes(teamId)
+        ? filters.team_ids.filter((id) => id !== teamId)
+        : [...filters.team_ids, teamId],
+    });
+  }
+
+  function addUser(userId: string) {
+    setUserQuery("");
+    if (filters.user_ids.includes(userId)) return;
+    onChange({ ...filters, user_ids: [...filters.user_ids, userId] });
+  }
+
+  return (
+    <div className="sm:col-span-2 space-y-3 rounded-md border border-[var(--color-border)] p-3">
+      <div>
+        <p className="text-xs font-medium">{t("webhooks.filters", "Filters")}</p>
+        <p className="text-xs text-[var(--color-muted-foreground)]">
+          {t("webhooks.filters_hint", "Only send changes matching all filters. Changes that aren't about a team, user or shift are always sent.")}
+        </p>
+      </div>
+      {teams.length > 0 && (
+        <div>
+          <label className="mb-1 block text-xs font-medium">{t("webhooks.filter_teams", "Teams")}</label>
+          <div className="flex flex-wrap gap-2">
+            {teams.map((team) => (
+              <label key={team.team_id} className="flex items-center gap-1.5 text-xs">
+                <input
+                  type="checkbox"
+                  checked={filters.team_ids.includes(team.team_id)}
+                  onChange={() => toggleTeam(team.team_id)}
+                  className="rounded"
+                />
+                {team.team_name}
+              </label>
+            ))}
+          </div>
+        </div>
+      )}
+      <div>
+        <label className="mb-1 block text-xs font-medium">{t("webhooks.filter_users", "Users")}</label>
+        {filters.user_ids.length > 0 && (
+          <div className="mb-2 flex flex-wrap gap-1">
+            {filters.user_ids.map((id) => (
+              <UserChip
+                key={id}
+                userId={id}
+                onRemove={() => onChange({ ...filters, user_ids: filters.user_ids.filter((u) => u !== id) })}
+              />
+            ))}
+          </div>
+        )}
+        <div className="relative">
+          <input
+            type="text"
+            value={userQuery}
+            onChange={(e) => setUserQuery(e.target.value)}
+            placeholder={t("webhooks.filter_users_search", "Search users...")}
+            className="w-full rounded-md border border-[var(--color-border)] bg-[var(--color-background)] px-3 py-1.5 text-sm"
+          />
+          {userQuery && userResults.length > 0 && (
+            <div className="absolute z-10 mt-1 max-h-40 w-full overflow-y-auto rounded-md border border-[var(--color-border)] bg-[var(--color-background)] shadow-lg">
+              {userResults.map((u) => (
+                <button
+                  key={u.id}
+                  type="button"
+                  onClick={() => addUser(u.id)}
+                  className="block w-full px-3 py-1.5 text-left text-sm hover:bg-[var(--color-muted)]"
+                >
+                  {u.display_name || u.full_name}
+                  <span className="ml-1 text-[var(--color-muted-foreground)]">({u.username})</span>
+                </button>
+              ))}
+            </div>
+          )}
+        </div>
+      </div>
+      <div>
+        <label className="mb-1 block text-xs font-medium">{t("webhooks.filter_within_hours", "Only shifts starting within (hours)")}</label>
+        <input
+          type="number"
+          min={1}
+          value={filters.within_hours ?? ""}
+          onChange={(e) => onChange({ ...filters, within_hours: e.target.value ? Number(e.target.value) : null })}
+          className="w-32 rounded-md border border-[var(--color-border)] bg-[var(--color-background)] px-3 py-1.5 text-sm"
+        />
+      </div>
+    </div>
+  );
+}
+
+function UserChip({ userId, onRemove }: { userId: string; onRemove: () => void }) {
+  const { t } = useTranslation("common");
+  const { data: user } = useQuery({
+    queryKey: ["users", userId],
+    queryFn: async () => {
+      const res = await usersApi.getById(userId);
+      return res.data!;
+    },
+    staleTime: Infinity,
+  });
+
+  return (
+    <span className="flex items-center gap-1 rounded bg-[var(--color-muted)] px-1.5 py-0.5 text-xs">
+      {user ? user.display_name || user.full_name : userId.slice(0, 8)}
+      <button type="button" onClick={onRemove} aria-label={t("delete")} className="text-[var(--color-muted-foreground)] hover:text-[var(--color-foreground)]">
+        ×
+      </button>
+    </span>
+  );
+}
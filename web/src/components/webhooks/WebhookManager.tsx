@@ -1,59 +1,71 @@
 import { useState, useCallback } from "react";
 import { useTranslation } from "react-i18next";
 import { useQuery, useMutation, useQueryClient } from "@tanstack/react-query";
-import { webhooksApi, adminWebhooksApi } from "@/api/webhooks";
-import type { CreateWebhookRequest, UpdateWebhookRequest, Webhook } from "@/api/types";
+import { scopedWebhooksApi, webhookQueryKey } from "@/api/webhooks";
+import type { CreateWebhookRequest, UpdateWebhookRequest, Webhook, WebhookFilters, WebhookSecretRotation } from "@/api/types";
 import { ConfirmDialog } from "@/components/common/ConfirmDialog";
+import { WebhookDeliveries } from "./WebhookDeliveries";
+import { WebhookFilterEditor } from "./WebhookFilterEditor";
+import {
+  WebhookTemplateEditor,
+  DEFAULT_WEBHOOK_TEMPLATE,
+  parseHeaders,
+  formatHeaders,
+} from "./WebhookTemplateEditor";
 
-const EVENT_TRIGGER_OPTIONS = [
-  "shift.created",
-  "shift.updated",
-  "shift.deleted",
-  "event.locked",
-  "event.unlocked",
-  "event.updated",
-  "event.admin_added",
-  "event.admin_removed",
-  "coverage.updated",
-];
+const NO_FILTERS: WebhookFilters = { team_ids: [], user_ids: [], within_hours: null };
 
-const GLOBAL_TRIGGER_OPTIONS = [
-  "user.registered",
-  "user.updated",
-  "event.created",
-  "event.deleted",
-  "settings.changed",
+const WEBHOOK_FORMATS = [
+  { value: "default", label: "Default (JSON + HMAC)", hint: "" },
+  { value: "discord", label: "Discord", hint: "Paste the full Discord webhook URL. No secret needed." },
+  { value: "slack", label: "Slack", hint: "Paste the Slack incoming webhook URL. No secret needed." },
+  { value: "teams", label: "Microsoft Teams", hint: "Paste the URL of a Teams workflow or incoming webhook. No secret needed." },
+  { value: "matrix", label: "Matrix", hint: "Paste the webhook URL of a Matrix bridge such as hookshot. No secret needed." },
+  { value: "mattermost", label: "Mattermost", hint: "Paste the Mattermost incoming webhook URL. No secret needed." },
+  { value: "ntfy", label: "ntfy", hint: "Paste the topic URL, like https://ntfy.sh/mytopic. No secret needed." },
+  { value: "template", label: "Custom template", hint: "" },
 ];
 
 interface WebhookManagerProps {
   slug?: string;
   global?: boolean;
+  personal?: boolean;
 }
 
-export function WebhookManager({ slug, global }: WebhookManagerProps) {
+export function WebhookManager({ slug, global, personal }: WebhookManagerProps) {
   const { t } = useTranslation(["admin", "common"]);
   const queryClient = useQueryClient();
 
-  const isGlobal = !!global;
-  const queryKey = isGlobal ? ["admin", "webhooks"] : ["events", slug, "webhooks"];
-  const triggerOptions = isGlobal ? GLOBAL_TRIGGER_OPTIONS : EVENT_TRIGGER_OPTIONS;
+  const scope = { slug, global, personal };
+  const hooksApi = scopedWebhooksApi(scope);
+  const queryKey = webhookQueryKey(scope);
+  // Filters narrow event webhooks; global and personal webhooks don't have them
+  const isEvent = !global && !personal;
+  const enabled = !isEvent || !!slug;
 
   const { data: webhooks = [], isLoading } = useQuery({
     queryKey,
     queryFn: async () => {
-      if (isGlobal) {
-        const res = await adminWebhooksApi.list();
-        return res.data!;
-      }
-      const res = await webhooksApi.list(slug!);
+      const res = await hooksApi.list();
       return res.data!;
     },
-    enabled: isGlobal || !!slug,
+    enabled,
   });
 
+  const { data: triggers = [] } = useQuery({
+    queryKey: [...queryKey, "triggers"],
+    queryFn: async () => {
+      const res = await hooksApi.triggers();
+      return res.data!;
+    },
+    enabled,
+    staleTime: Infinity,
+  });
+  const triggerOptions = triggers.map((trigger) => trigger.type);
+
   const createWebhook = useMutation({
     mutationFn: (data: CreateWebhookRequest) =>
-      isGlobal ? adminWebhooksApi.create(data) : webhooksApi.create(slug!, data),
+      hooksApi.create(data),
     onSuccess: () => {
       queryClient.invalidateQueries({ queryKey });
       setShowForm(false);
@@ -63,7 +75,7 @@ export function WebhookManager({ slug, global }: WebhookManagerProps) {
 
   const updateWebhook = useMutation({
     mutationFn: ({ id, data }: { id: string; data: UpdateWebhookRequest }) =>
-      isGlobal ? adminWebhooksApi.update(id, data) : webhooksApi.update(slug!, id, data),
+      hooksApi.update(id, data),
     onSuccess: () => {
       queryClient.invalidateQueries({ queryKey });
       setEditingId(null);
@@ -72,7 +84,7 @@ export function WebhookManager({ slug, global }: WebhookManagerProps) {
 
   const deleteWebhook = useMutation({
     mutationFn: (id: string) =>
-      isGlobal ? adminWebhooksApi.delete(id) : webhooksApi.delete(slug!, id),
+      hooksApi.delete(id),
     onSuccess: () => {
       queryClient.invalidateQueries({ queryKey });
     },
@@ -80,22 +92,54 @@ export function WebhookManager({ slug, global }: WebhookManagerProps) {
 
   const testWebhook = useMutation({
     mutationFn: (id: string) =>
-      isGlobal ? adminWebhooksApi.test(id) : webhooksApi.test(slug!, id),
+      hooksApi.test(id),
+    onSuccess: (_, id) => {
+      setDeliveriesId(id);
+      queryClient.invalidateQueries({ queryKey: [...queryKey, id, "deliveries"] });
+    },
+  });
+
+  const rotateSecret = useMutation({
+    mutationFn: async (id: string) => {
+      const res = await hooksApi.rotateSecret(id);
+      return res.data!;
+    },
+    onSuccess: (rotation, id) => {
+      setRotatingWebhook(null);
+      setNewSecret({ id, ...rotation });
+      queryClient.invalidateQueries({ queryKey });
+    },
   });
 
   const [showForm, setShowForm] = useState(false);
   const [editingId, setEditingId] = useState<string | null>(null);
   const [deletingWebhook, setDeletingWebhook] = useState<Webhook | null>(null);
+  const [deliveriesId, setDeliveriesId] = useState<string | null>(null);
+  const [rotatingWebhook, setRotatingWebhook] = useState<Webhook | null>(null);
+  const [newSecret, setNewSecret] = useState<(WebhookSecretRotation & { id: string }) | null>(null);
   const [form, setForm] = useState({
     name: "",
     url: "",
     secret: "",
     format: "default" as string,
     trigger_types: [] as string[],
+    template: "",
+    content_type: "",
+    headers: "",
+    filters: NO_FILTERS,
   });
 
   function resetForm() {
-    setForm({ name: "", url: "", secret: "", format: "default", trigger_types: [] });
+    setForm({ name: "", url: "", secret: "", format: "default", trigger_types: [], template: "", content_type: "", headers: "", filters: NO_FILTERS });
+  }
+
+  function templateFields() {
+    if (form.format !== "template") return {};
+    return {
+      template: form.template,
+      content_type: form.content_type,
+      headers: parseHeaders(form.headers),
+    };
   }
 
   function startEdit(wh: Webhook) {
@@ -106,6 +150,10 @@ export function WebhookManager({ slug, global }: WebhookManagerProps) {
       secret: "",
       format: wh.format || "default",
       trigger_types: wh.trigger_types,
+      template: wh.template ?? "",
+      content_type: wh.content_type ?? "",
+      headers: formatHeaders(wh.headers),
+      filters: wh.filters ?? NO_FILTERS,
     });
   }
 
@@ -117,7 +165,9 @@ export function WebhookManager({ slug, global }: WebhookManagerProps) {
       secret: form.secret,
       format: form.format,
       trigger_types: form.trigger_types,
+      ...templateFields(),
     };
+    if (isEvent) data.filters = form.filters;
     createWebhook.mutate(data);
   }
 
@@ -129,8 +179,10 @@ export function WebhookManager({ slug, global }: WebhookManagerProps) {
       url: form.url,
       format: form.format,
       trigger_types: form.trigger_types,
+      ...templateFields(),
     };
     if (form.secret) data.secret = form.secret;
+    if (isEvent) data.filters = form.filters;
     updateWebhook.mutate({ id: editingId, data });
   }
 
@@ -160,7 +212,7 @@ export function WebhookManager({ slug, global }: WebhookManagerProps) {
     setDeletingWebhook(null);
   }, [deletingWebhook, deleteWebhook]);
 
-  const isDiscord = form.format === "discord";
+  const isChat = form.format !== "default" && form.format !== "template";
 
   if (isLoading) {
     return <p className="text-sm text-[var(--color-muted-foreground)]">{t("common:loading")}</p>;
@@ -211,35 +263,46 @@ export function WebhookManager({ slug, global }: WebhookManagerProps) {
             </div>
             <div className="sm:col-span-2">
               <label className="mb-1 block text-xs font-medium">{t("webhooks.format", "Format")}</label>
-              <div className="flex gap-4">
-                <label className="flex items-center gap-1.5 text-sm">
-                  <input
-                    type="radio"
-                    name="format"
-                    value="default"
-                    checked={form.format === "default"}
-                    onChange={() => setForm({ ...form, format: "default" })}
-                  />
-                  {t("webhooks.format_default", "Default (JSON + HMAC)")}
-                </label>
-                <label className="flex items-center gap-1.5 text-sm">
-                  <input
-                    type="radio"
-                    name="format"
-                    value="discord"
-                    checked={form.format === "discord"}
-                    onChange={() => setForm({ ...form, format: "discord", secret: "" })}
-                  />
-                  {t("webhooks.format_discord", "Discord")}
-                </label>
+              <div className="flex flex-wrap gap-x-4 gap-y-1">
+                {WEBHOOK_FORMATS.map((f) => (
+                  <label key={f.value} className="flex items-center gap-1.5 text-sm">
+                    <input
+                      type="radio"
+                      name="format"
+                      value={f.value}
+                      checked={form.format === f.value}
+                      onChange={() =>
+                        setForm({
+                          ...form,
+                          format: f.value,
+                          secret: f.value === "default" || f.value === "template" ? form.secret : "",
+                          template: f.value === "template" && !form.template ? DEFAULT_WEBHOOK_TEMPLATE : form.template,
+                        })
+                      }
+                    />
+                    {t(`webhooks.format_${f.value}`, f.label)}
+                  </label>
+                ))}
               </div>
-              {isDiscord && (
+              {isChat && (
                 <p className="mt-1 text-xs text-[var(--color-muted-foreground)]">
-                  {t("webhooks.discord_hint", "Paste the full Discord webhook URL. No secret needed.")}
+                  {t(`webhooks.${form.format}_hint`, WEBHOOK_FORMATS.find((f) => f.value === form.format)?.hint ?? "")}
                 </p>
               )}
             </div>
-            {!isDiscord && (
+            {form.format === "template" && (
+              <WebhookTemplateEditor
+                slug={slug}
+                global={global}
+                personal={personal}
+                triggerOptions={triggerOptions}
+                template={form.template}
+                contentType={form.content_type}
+                headers={form.headers}
+                onChange={(value) => setForm({ ...form, ...value })}
+              />
+            )}
+            {!isChat && (
               <div className="sm:col-span-2">
                 <label className="mb-1 block text-xs font-medium">
                   {t("webhooks.secret", "Secret")}
@@ -261,19 +324,26 @@ export function WebhookManager({ slug, global }: WebhookManagerProps) {
             <div className="sm:col-span-2">
               <label className="mb-1 block text-xs font-medium">{t("webhooks.triggers", "Trigger Types")}</label>
               <div className="flex flex-wrap gap-2">
-                {triggerOptions.map((trigger) => (
-                  <label key={trigger} className="flex items-center gap-1.5 text-xs">
+                {triggers.map((trigger) => (
+                  <label key={trigger.type} title={trigger.description} className="flex items-center gap-1.5 text-xs">
                     <input
                       type="checkbox"
-                      checked={form.trigger_types.includes(trigger)}
-                      onChange={() => toggleTrigger(trigger)}
+                      checked={form.trigger_types.includes(trigger.type)}
+                      onChange={() => toggleTrigger(trigger.type)}
                       className="rounded"
                     />
-                    {trigger}
+                    {trigger.type}
                   </label>
                 ))}
               </div>
             </div>
+            {isEvent && (
+              <WebhookFilterEditor
+                slug={slug!}
+                filters={form.filters}
+                onChange={(filters) => setForm({ ...form, filters })}
+              />
+            )}
           </div>
           <div className="mt-3 flex gap-2">
             <button
@@ -301,73 +371,127 @@ export function WebhookManager({ slug, global }: WebhookManagerProps) {
       ) : (
         <div className="space-y-2">
           {webhooks.map((wh) => (
-            <div
-              key={wh.id}
-              className="flex items-center justify-between rounded-lg border border-[var(--color-border)] px-4 py-3"
-            >
-              <div className="min-w-0 flex-1">
-                <div className="flex items-center gap-2">
-                  <span className="font-medium text-sm">{wh.name}</span>
-                  {wh.format === "discord" && (
-                    <span className="rounded-full bg-[var(--color-info-light,#e0f2fe)] px-2 py-0.5 text-[10px] text-[var(--color-info,#0284c7)]">
-                      Discord
+            <div key={wh.id} className="rounded-lg border border-[var(--color-border)] px-4 py-3">
+              <div className="flex items-center justify-between">
+                <div className="min-w-0 flex-1">
+                  <div className="flex items-center gap-2">
+                    <span className="font-medium text-sm">{wh.name}</span>
+                    {wh.format && wh.format !== "default" && (
+                      <span className="rounded-full bg-[var(--color-info-light,#e0f2fe)] px-2 py-0.5 text-[10px] text-[var(--color-info,#0284c7)]">
+                        {t(`webhooks.format_${wh.format}`, WEBHOOK_FORMATS.find((f) => f.value === wh.format)?.label ?? wh.format)}
+                      </span>
+                    )}
+                    <span
+                      className={`rounded-full px-2 py-0.5 text-[10px] ${
+                        wh.is_enabled
+                          ? "bg-[var(--color-success-light)] text-[var(--color-success)]"
+                          : "bg-[var(--color-muted)] text-[var(--color-muted-foreground)]"
+                      }`}
+                    >
+                      {wh.is_enabled ? t("admin:oauth.enable") : t("admin:oauth.disable")}
                     </span>
+                  </div>
+                  <div className="mt-0.5 truncate text-xs text-[var(--color-muted-foreground)]">
+                    {wh.url}
+                  </div>
+                  <div className="mt-1 flex flex-wrap gap-1">
+                    {wh.trigger_types.map((tr) => (
+                      <span
+                        key={tr}
+                        className="rounded bg-[var(--color-muted)] px-1.5 py-0.5 text-[10px]"
+                      >
+                        {tr}
+                      </span>
+                    ))}
+                  </div>
+                  {wh.filters && (wh.filters.team_ids.length > 0 || wh.filters.user_ids.length > 0 || wh.filters.within_hours) && (
+                    <div className="mt-1 text-xs text-[var(--color-muted-foreground)]">
+                      {t("webhooks.filtered", "Filtered")}
+                    </div>
+                  )}
+                  {wh.disabled_reason ? (
+                    <div className="mt-1 text-xs text-[var(--color-destructive)]">
+                      {t("webhooks.disabled_reason", { reason: wh.disabled_reason })}
+                    </div>
+                  ) : wh.consecutive_failures > 0 && (
+                    <div className="mt-1 text-xs text-[var(--color-warning,#d97706)]">
+                      {t("webhooks.failures", { count: wh.consecutive_failures })}
+                    </div>
+                  )}
+                  {wh.previous_secret_expires_at && (
+                    <div className="mt-1 text-xs text-[var(--color-muted-foreground)]">
+                      {t("webhooks.rotation_until", { date: new Date(wh.previous_secret_expires_at).toLocaleString() })}
+                    </div>
                   )}
-                  <span
-                    className={`rounded-full px-2 py-0.5 text-[10px] ${
-                      wh.is_enabled
-                        ? "bg-[var(--color-success-light)] text-[var(--color-success)]"
-                        : "bg-[var(--color-muted)] text-[var(--color-muted-foreground)]"
-                    }`}
-                  >
-                    {wh.is_enabled ? t("admin:oauth.enable") : t("admin:oauth.disable")}
-                  </span>
-                </div>
-                <div className="mt-0.5 truncate text-xs text-[var(--color-muted-foreground)]">
-                  {wh.url}
                 </div>
-                <div className="mt-1 flex flex-wrap gap-1">
-                  {wh.trigger_types.map((tr) => (
-                    <span
-                      key={tr}
-                      className="rounded bg-[var(--color-muted)] px-1.5 py-0.5 text-[10px]"
+                <div className="ml-3 flex items-center gap-2">
+                  <button
+                    type="button"
+                    onClick={() => setDeliveriesId(deliveriesId === wh.id ? null : wh.id)}
+                    className="text-xs text-[var(--color-muted-foreground)] hover:text-[var(--color-foreground)]"
+                  >
+                    {t("webhooks.deliveries", "Deliveries")}
+                  </button>
+                  <button
+                    type="button"
+                    onClick={() => testWebhook.mutate(wh.id)}
+                    disabled={testWebhook.isPending}
+                    className="text-xs text-[var(--color-info,#0284c7)] hover:underline disabled:opacity-50"
+                  >
+                    {t("webhooks.test", "Test")}
+                  </button>
+                  {(wh.format === "default" || wh.format === "template") && (
+                    <button
+                      type="button"
+                      onClick={() => setRotatingWebhook(wh)}
+                      className="text-xs text-[var(--color-muted-foreground)] hover:text-[var(--color-foreground)]"
                     >
-                      {tr}
-                    </span>
-                  ))}
+                      {t("webhooks.rotate_secret", "Rotate secret")}
+                    </button>
+                  )}
+                  <button
+                    type="button"
+                    onClick={() => handleToggleEnabled(wh)}
+                    className="text-xs text-[var(--color-primary)] hover:underline"
+                  >
+                    {wh.is_enabled ? t("admin:oauth.disable") : t("admin:oauth.enable")}
+                  </button>
+                  <button
+                    type="button"
+                    onClick={() => startEdit(wh)}
+                    className="text-xs text-[var(--color-muted-foreground)] hover:text-[var(--color-foreground)]"
+                  >
+                    {t("common:edit")}
+                  </button>
+                  <button
+                    type="button"
+                    onClick={() => handleDelete(wh)}
+                    className="text-xs text-[var(--color-destructive)] hover:text-[var(--color-destructive)]"
+                  >
+                    {t("common:delete")}
+                  </button>
                 </div>
               </div>
-              <div className="ml-3 flex items-center gap-2">
-                <button
-                  type="button"
-                  onClick={() => testWebhook.mutate(wh.id)}
-                  disabled={testWebhook.isPending}
-                  className="text-xs text-[var(--color-info,#0284c7)] hover:underline disabled:opacity-50"
-                >
-                  {t("webhooks.test", "Test")}
-                </button>
-                <button
-                  type="button"
-                  onClick={() => handleToggleEnabled(wh)}
-                  className="text-xs text-[var(--color-primary)] hover:underline"
-                >
-                  {wh.is_enabled ? t("admin:oauth.disable") : t("admin:oauth.enable")}
-                </button>
-                <button
-                  type="button"
-                  onClick={() => startEdit(wh)}
-                  className="text-xs text-[var(--color-muted-foreground)] hover:text-[var(--color-foreground)]"
-                >
-                  {t("common:edit")}
-                </button>
-                <button
-                  type="button"
-                  onClick={() => handleDelete(wh)}
-                  className="text-xs text-[var(--color-destructive)] hover:text-[var(--color-destructive)]"
-                >
-                  {t("common:delete")}
-                </button>
-              </div>
+              {newSecret?.id === wh.id && (
+                <div className="mt-3 rounded-md bg-[var(--color-muted)] p-2 text-xs">
+                  <p>{t("webhooks.new_secret")}</p>
+                  <div className="mt-1 flex items-center gap-2">
+                    <code className="break-all font-mono">{newSecret.secret}</code>
+                    <button
+                      type="button"
+                      onClick={() => setNewSecret(null)}
+                      className="ml-auto text-[var(--color-muted-foreground)] hover:text-[var(--color-foreground)]"
+                    >
+                      {t("webhooks.secret_done", "Done")}
+                    </button>
+                  </div>
+                </div>
+              )}
+              {deliveriesId === wh.id && (
+                <div className="mt-3 border-t border-[var(--color-border)] pt-3">
+                  <WebhookDeliveries webhookId={wh.id} slug={slug} global={global} personal={personal} />
+                </div>
+              )}
             </div>
           ))}
         </div>
@@ -382,6 +506,14 @@ export function WebhookManager({ slug, global }: WebhookManagerProps) {
         onConfirm={doDeleteWebhook}
         onCancel={() => setDeletingWebhook(null)}
       />
+      <ConfirmDialog
+        open={!!rotatingWebhook}
+        title={t("webhooks.rotate_secret")}
+        message={rotatingWebhook ? t("webhooks.rotate_confirm", { name: rotatingWebhook.name }) : ""}
+        loading={rotateSecret.isPending}
+        onConfirm={() => rotatingWebhook && rotateSecret.mutate(rotatingWebhook.id)}
+        onCancel={() => setRotatingWebhook(null)}
+      />
     </div>
   );
 }
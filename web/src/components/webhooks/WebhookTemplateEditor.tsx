@@ -0,0 +1,156 @@
+import { useState } from "react";
+import { useTranslation } from "react-i18next";
+import { useMutation } from "@tanstack/react-query";
+import { scopedWebhooksApi } from "@/api/webhooks";
+import type { WebhookPreviewRequest } from "@/api/types";
+
+export const DEFAULT_WEBHOOK_TEMPLATE = `{
+  "type": {{json .Type}},
+  "summary": {{json .Title}},
+  "data": {{json .Data}}
+}`;
+
+// parseHeaders reads "Name: value" lines into a header map.
+export function parseHeaders(text: string): Record<string, string> {
+  const headers: Record<string, string> = {};
+  for (const line of text.split("\n")) {
+    const i = line.indexOf(":");
+    if (i <= 0) continue;
+    headers[line.slice(0, i).trim()] = line.slice(i + 1).trim();
+  }
+  return headers;
+}
+
+export function formatHeaders(headers?: Record<string, string>): string {
+  return Object.entries(headers ?? {})
+    .map(([name, value]) => `${name}: ${value}`)
+    .join("\n");
+}
+
+interface WebhookTemplateEditorProps {
+  slug?: string;
+  global?: boolean;
+  personal?: boolean;
+  triggerOptions: string[];
+  template: string;
+  contentType: string;
+  headers: string;
+  onChange: (value: { template: string; content_type: string; headers: string }) => void;
+}
+
+export function WebhookTemplateEditor({
+  slug,
+  global,
+  personal,
+  triggerOptions,
+  template,
+  contentType,
+  headers,
+  onChange,
+}: WebhookTemplateEditorProps) {
+  const { t } = useTranslation(["admin", "common"]);
+  const [previewTrigger, setPreviewTrigger] = useState(triggerOptions[0] ?? "webhook.test");
+
+  const preview = useMutation({
+    mutationFn: async (data: WebhookPreviewRequest) => {
+      const res = await scopedWebhooksApi({ slug, global, personal }).preview(data);
+      return res.data!;
+    },
+  });
+
+  function runPreview() {
+    preview.mutate({
+      trigger_type: previewTrigger,
+      template,
+      content_type: contentType,
+      headers: parseHeaders(headers),
+    });
+  }
+
+  const inputClass =
+    "w-full rounded-md border border-[var(--color-border)] bg-[var(--color-background)] px-3 py-2 text-sm";
+
+  return (
+    <div className="space-y-3 sm:col-span-2">
+      <div>
+        <label className="mb-1 block text-xs font-medium">{t("webhooks.template", "Body template")}</label>
+        <textarea
+          value={template}
+          onChange={(e) => onChange({ template: e.target.value, content_type: contentType, headers })}
+          rows={8}
+          spellCheck={false}
+          className={`${inputClass} font-mono text-xs`}
+          required
+        />
+        <p className="mt-1 text-xs text-[var(--color-muted-foreground)]">
+          {t(
+            "webhooks.template_hint",
+            "Go text/template. Available: .Type, .EventID, .Timestamp, .Data, .Event.Name, .Event.Slug, .Title, .Text, .Link and the functions json, upper, lower, default, formatTime.",
+          )}
+        </p>
+      </div>
+      <div className="grid gap-3 sm:grid-cols-2">
+        <div>
+          <label className="mb-1 block text-xs font-medium">{t("webhooks.content_type", "Content type")}</label>
+          <input
+            type="text"
+            value={contentType}
+            placeholder="application/json"
+            onChange={(e) => onChange({ template, content_type: e.target.value, headers })}
+            className={inputClass}
+          />
+        </div>
+        <div>
+          <label className="mb-1 block text-xs font-medium">{t("webhooks.headers", "Extra headers")}</label>
+          <textarea
+            value={headers}
+            placeholder="Authorization: Bearer ..."
+            onChange={(e) => onChange({ template, content_type: contentType, headers: e.target.value })}
+            rows={2}
+            spellCheck={false}
+            className={`${inputClass} font-mono text-xs`}
+          />
+        </div>
+      </div>
+      <div className="flex items-center gap-2">
+        <select
+          value={previewTrigger}
+          onChange={(e) => setPreviewTrigger(e.target.value)}
+          className="rounded-md border border-[var(--color-border)] bg-[var(--color-background)] px-2 py-1.5 text-xs"
+        >
+          {[...triggerOptions, "webhook.test"].map((trigger) => (
+            <option key={trigger} value={trigger}>
+              {trigger}
+            </option>
+          ))}
+        </select>
+        <button
+          type="button"
+          onClick={runPreview}
+          disabled={preview.isPending}
+          className="rounded-md bg-[var(--color-muted)] px-3 py-1.5 text-xs"
+        >
+          {t("webhooks.preview", "Preview")}
+        </button>
+      </div>
+      {preview.error && (
+        <p className="text-xs text-[var(--color-destructive)]">{preview.error.message}</p>
+      )}
+      {preview.data?.error && (
+        <pre className="whitespace-pre-wrap rounded-md bg-[var(--color-destructive-light)] p-2 text-xs text-[var(--color-destructive)]">
+          {preview.data.error}
+        </pre>
+      )}
+      {preview.data && !preview.data.error && (
+        <div>
+          <p className="mb-1 text-xs text-[var(--color-muted-foreground)]">
+            Content-Type: {preview.data.content_type}
+          </p>
+          <pre className="max-h-64 overflow-auto whitespace-pre-wrap rounded-md bg-[var(--color-muted)] p-2 font-mono text-xs">
+            {preview.data.body}
+          </pre>
+        </div>
+      )}
+    </div>
+  );
+}
@@ -91,8 +91,12 @@ export function AuthProvider({ children }: { children: ReactNode }) {
   );
 
   const logout = useCallback(async () => {
-    await authApi.logout();
+    const res = await authApi.logout();
     setUser(null);
+    // Sessions from an OpenID Connect provider with RP-initiated logout end there too
+    if (res.data?.logout_url) {
+      window.location.assign(res.data.logout_url);
+    }
   }, []);
 
   const refreshUser = useCallback(async () => {
@@ -0,0 +1,48 @@
+import { useQuery, useMutation, useQueryClient } from "@tanstack/react-query";
+import { apiTokensApi } from "@/api/apiTokens";
+import type { CreateAPITokenRequest } from "@/api/types";
+
+export function useAPITokens() {
+  return useQuery({
+    queryKey: ["api-tokens"],
+    queryFn: apiTokensApi.list,
+  });
+}
+
+export function useCreateAPIToken() {
+  const queryClient = useQueryClient();
+  return useMutation({
+    mutationFn: (data: CreateAPITokenRequest) => apiTokensApi.create(data),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["api-tokens"] });
+    },
+  });
+}
+
+export function useRevokeAPIToken() {
+  const queryClient = useQueryClient();
+  return useMutation({
+    mutationFn: (tokenId: string) => apiTokensApi.revoke(tokenId),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["api-tokens"] });
+    },
+  });
+}
+
+export function useAllAPITokens() {
+  return useQuery({
+    queryKey: ["admin", "api-tokens"],
+    queryFn: apiTokensApi.listAll,
+  });
+}
+
+export function useRevokeAnyAPIToken() {
+  const queryClient = useQueryClient();
+  return useMutation({
+    mutationFn: (tokenId: string) => apiTokensApi.revokeAny(tokenId),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["admin", "api-tokens"] });
+      queryClient.invalidateQueries({ queryKey: ["api-tokens"] });
+    },
+  });
+}
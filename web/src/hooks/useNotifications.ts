@@ -1,6 +1,6 @@
 import { useQuery, useMutation, useQueryClient } from "@tanstack/react-query";
 import { notificationsApi } from "@/api/notifications";
-import type { UpdatePreferenceRequest } from "@/api/types";
+import type { EventNotificationState, UpdatePreferenceRequest } from "@/api/types";
 
 export function useNotifications(limit = 50, offset = 0) {
   return useQuery({
@@ -19,7 +19,8 @@ export function useUnreadCount() {
       const res = await notificationsApi.unreadCount();
       return res.data!.unread_count;
     },
-    refetchInterval: 30_000,
+    // Updates arrive over SSE; polling only covers a lost connection
+    refetchInterval: 300_000,
   });
 }
 
@@ -64,3 +65,45 @@ export function useUpdateNotificationPreference() {
     },
   });
 }
+
+export function useEventNotificationSettings(slug: string) {
+  return useQuery({
+    queryKey: ["notifications", "events", slug],
+    queryFn: async () => {
+      const res = await notificationsApi.getEventSettings(slug);
+      return res.data!;
+    },
+    enabled: !!slug,
+  });
+}
+
+export function useSetEventNotificationState(slug: string) {
+  const queryClient = useQueryClient();
+  return useMutation({
+    mutationFn: (state: EventNotificationState) =>
+      notificationsApi.setEventState(slug, state),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["notifications", "events", slug] });
+    },
+  });
+}
+
+export function usePushSubscriptions() {
+  return useQuery({
+    queryKey: ["notifications", "push-subscriptions"],
+    queryFn: async () => {
+      const res = await notificationsApi.listPushSubscriptions();
+      return res.data!;
+    },
+  });
+}
+
+export function useDeletePushSubscription() {
+  const queryClient = useQueryClient();
+  return useMutation({
+    mutationFn: (id: string) => notificationsApi.deletePushSubscription(id),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["notifications", "push-subscriptions"] });
+    },
+  });
+}
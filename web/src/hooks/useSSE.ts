@@ -3,6 +3,8 @@ import { useQueryClient } from "@tanstack/react-query";
 
 interface SSEOptions {
   slug?: string;
+  /** Subscribe to the user's own notification stream instead of event updates */
+  notifications?: boolean;
   enabled?: boolean;
 }
 
@@ -10,7 +12,7 @@ const BACKOFF_INITIAL_MS = 1000;
 const BACKOFF_MAX_MS = 30000;
 const MAX_RETRIES = 20;
 
-export function useSSE({ slug, enabled = true }: SSEOptions = {}) {
+export function useSSE({ slug, notifications = false, enabled = true }: SSEOptions = {}) {
   const queryClient = useQueryClient();
   const eventSourceRef = useRef<EventSource | null>(null);
   const reconnectTimeoutRef = useRef<ReturnType<typeof setTimeout>>(undefined);
@@ -21,7 +23,11 @@ export function useSSE({ slug, enabled = true }: SSEOptions = {}) {
     if (!enabled) return;
     if (retriesRef.current >= MAX_RETRIES) return;
 
-    const url = slug ? `/api/events/${slug}/sse` : "/api/sse";
+    const url = notifications
+      ? "/api/notifications/sse"
+      : slug
+        ? `/api/events/${slug}/sse`
+        : "/api/sse";
     const es = new EventSource(url, { withCredentials: true });
     eventSourceRef.current = es;
 
@@ -54,8 +60,13 @@ export function useSSE({ slug, enabled = true }: SSEOptions = {}) {
           queryClient.invalidateQueries({ queryKey: ["events"] });
         }
 
-        // Always refresh notification count on any SSE event
-        queryClient.invalidateQueries({ queryKey: ["notifications", "unread-count"] });
+        // Notification events carry the user's current unread count
+        if (eventType.startsWith("notification.")) {
+          queryClient.setQueryData(["notifications", "unread-count"], data.payload.unread_count);
+          if (eventType === "notification.created") {
+            queryClient.invalidateQueries({ queryKey: ["notifications"] });
+          }
+        }
       } catch {
         // Ignore parse errors for non-JSON messages (e.g., "connected")
       }
@@ -71,7 +82,7 @@ export function useSSE({ slug, enabled = true }: SSEOptions = {}) {
         reconnectTimeoutRef.current = setTimeout(connect, delay);
       }
     };
-  }, [enabled, slug, queryClient]);
+  }, [enabled, slug, notifications, queryClient]);
 
   useEffect(() => {
     backoffRef.current = BACKOFF_INITIAL_MS;
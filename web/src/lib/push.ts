@@ -0,0 +1,64 @@
+import { notificationsApi } from "@/api/notifications";
+
+export function isPushSupported() {
+  return "serviceWorker" in navigator && "PushManager" in window && "Notification" in window;
+}
+
+function urlBase64ToUint8Array(base64: string) {
+  const padded = base64 + "=".repeat((4 - (base64.length % 4)) % 4);
+  const raw = atob(padded.replace(/-/g, "+").replace(/_/g, "/"));
+  return Uint8Array.from(raw, (c) => c.charCodeAt(0));
+}
+
+async function registration() {
+  return navigator.serviceWorker.register("/sw.js");
+}
+
+/** Returns this browser's push subscription, if any. */
+export async function currentPushSubscription() {
+  if (!isPushSupported()) return null;
+  const reg = await navigator.serviceWorker.getRegistration("/sw.js");
+  return (await reg?.pushManager.getSubscription()) ?? null;
+}
+
+/** Asks for permission, subscribes this browser and registers it with the server. */
+export async function enablePush() {
+  const permission = await Notification.requestPermission();
+  if (permission !== "granted") {
+    throw new Error("permission_denied");
+  }
+
+  const { data } = await notificationsApi.getPushPublicKey();
+  const reg = await registration();
+  await navigator.serviceWorker.ready;
+  const applicationServerKey = urlBase64ToUint8Array(data!.public_key);
+
+  let subscription = await reg.pushManager.getSubscription();
+  if (subscription) {
+    // Subscriptions made with a different server key cannot be reused
+    const current = subscription.options.applicationServerKey;
+    const same =
+      current &&
+      new Uint8Array(current).every((b, i) => b === applicationServerKey[i]);
+    if (!same) {
+      await subscription.unsubscribe();
+      subscription = null;
+    }
+  }
+  subscription ??= await reg.pushManager.subscribe({
+    userVisibleOnly: true,
+    applicationServerKey,
+  });
+
+  const res = await notificationsApi.subscribePush(subscription.toJSON());
+  return res.data!;
+}
+
+/** Unsubscribes this browser and removes it from the server. */
+export async function disablePush(serverId?: string) {
+  const subscription = await currentPushSubscription();
+  await subscription?.unsubscribe();
+  if (serverId) {
+    await notificationsApi.deletePushSubscription(serverId);
+  }
+}
@@ -0,0 +1,263 @@
+import { useState, type FormEvent } from "react";
+import { useTranslation } from "react-i18next";
+import { useAPITokens, useCreateAPIToken, useRevokeAPIToken } from "@/hooks/useAPITokens";
+import { useEvents } from "@/hooks/useEvents";
+import type { APIToken, APITokenScope, CreateAPITokenRequest, CreatedAPIToken } from "@/api/types";
+import { SettingsTabs } from "@/components/common/SettingsTabs";
+
+const SCOPES: APITokenScope[] = ["read", "shifts:write", "availability:write", "admin"];
+
+export function APITokensPage() {
+  const { t } = useTranslation(["settings", "common"]);
+  const { data: tokens, isLoading } = useAPITokens();
+  const createToken = useCreateAPIToken();
+  const revokeToken = useRevokeAPIToken();
+  const { data: events } = useEvents();
+
+  const [showForm, setShowForm] = useState(false);
+  const [name, setName] = useState("");
+  const [scopes, setScopes] = useState<APITokenScope[]>(["read"]);
+  const [eventId, setEventId] = useState("");
+  const [expiresOn, setExpiresOn] = useState("");
+  const [created, setCreated] = useState<CreatedAPIToken | null>(null);
+  const [copied, setCopied] = useState(false);
+  const [confirmRevoke, setConfirmRevoke] = useState<string | null>(null);
+
+  function resetForm() {
+    setShowForm(false);
+    setName("");
+    setScopes(["read"]);
+    setEventId("");
+    setExpiresOn("");
+  }
+
+  function toggleScope(scope: APITokenScope) {
+    setScopes((prev) => (prev.includes(scope) ? prev.filter((s) => s !== scope) : [...prev, scope]));
+  }
+
+  function handleSubmit(e: FormEvent) {
+    e.preventDefault();
+    const data: CreateAPITokenRequest = { name, scopes };
+    if (eventId) data.event_id = eventId;
+    // The token works until the end of the chosen day
+    if (expiresOn) data.expires_at = new Date(`${expiresOn}T23:59:59`).toISOString();
+
+    createToken.mutate(data, {
+      onSuccess: (token) => {
+        setCreated(token);
+        setCopied(false);
+        resetForm();
+      },
+    });
+  }
+
+  function handleCopy() {
+    if (!created) return;
+    navigator.clipboard.writeText(created.token);
+    setCopied(true);
+  }
+
+  return (
+    <div className="mx-auto max-w-2xl space-y-6">
+      <h1 className="text-2xl font-bold">{t("common:nav.settings")}</h1>
+      <SettingsTabs />
+      <div className="flex items-center justify-between">
+        <h2 className="text-lg font-semibold">{t("settings:api_tokens.title")}</h2>
+        {!showForm && (
+          <button
+            type="button"
+            onClick={() => setShowForm(true)}
+            className="rounded-md bg-[var(--color-primary)] px-3 py-1.5 text-sm text-[var(--color-primary-foreground)]"
+          >
+            + {t("settings:api_tokens.create")}
+          </button>
+        )}
+      </div>
+
+      <p className="text-sm text-[var(--color-muted-foreground)]">{t("settings:api_tokens.description")}</p>
+
+      {/* Newly created token, shown once */}
+      {created && (
+        <div className="rounded-lg border border-[var(--color-primary)] p-4 space-y-2">
+          <p className="text-sm font-medium">{t("settings:api_tokens.created", { name: created.name })}</p>
+          <p className="text-xs text-[var(--color-muted-foreground)]">{t("settings:api_tokens.created_hint")}</p>
+          <div className="flex items-center gap-2">
+            <input
+              type="text"
+              readOnly
+              value={created.token}
+              className="flex-1 rounded-md border border-[var(--color-border)] bg-[var(--color-muted)] px-3 py-1.5 text-xs font-mono select-all"
+              onFocus={(e) => e.target.select()}
+            />
+            <button
+              type="button"
+              onClick={handleCopy}
+              className="shrink-0 rounded-md border border-[var(--color-border)] px-3 py-1.5 text-xs hover:bg-[var(--color-muted)]"
+            >
+              {copied ? t("settings:api_tokens.copied") : t("settings:api_tokens.copy")}
+            </button>
+            <button
+              type="button"
+              onClick={() => setCreated(null)}
+              className="shrink-0 rounded-md border border-[var(--color-border)] px-3 py-1.5 text-xs hover:bg-[var(--color-muted)]"
+            >
+              {t("common:notifications.close")}
+            </button>
+          </div>
+        </div>
+      )}
+
+      {/* Create form */}
+      {showForm && (
+        <form onSubmit={handleSubmit} className="rounded-lg border border-[var(--color-border)] p-4 space-y-3">
+          <h3 className="font-medium">{t("settings:api_tokens.create")}</h3>
+          <div>
+            <label htmlFor="api-token-name" className="block text-sm font-medium">{t("settings:api_tokens.name")}</label>
+            <input
+              id="api-token-name"
+              type="text"
+              required
+              maxLength={255}
+              value={name}
+              onChange={(e) => setName(e.target.value)}
+              className="mt-1 block w-full rounded-md border border-[var(--color-border)] bg-[var(--color-background)] px-3 py-2 text-sm"
+              placeholder={t("settings:api_tokens.name_placeholder")}
+            />
+          </div>
+          <fieldset>
+            <legend className="block text-sm font-medium">{t("settings:api_tokens.scopes")}</legend>
+            <div className="mt-1 space-y-1">
+              {SCOPES.map((scope) => (
+                <label key={scope} className="flex items-start gap-2 text-sm">
+                  <input
+                    type="checkbox"
+                    checked={scopes.includes(scope)}
+                    onChange={() => toggleScope(scope)}
+                    className="mt-0.5 rounded"
+                  />
+                  <span>
+                    <code className="text-xs">{scope}</code>
+                    <span className="ml-1 text-xs text-[var(--color-muted-foreground)]">
+                      {t(`settings:api_tokens.scope_${scope.replace(":", "_")}`)}
+                    </span>
+                  </span>
+                </label>
+              ))}
+            </div>
+          </fieldset>
+          <div>
+            <label htmlFor="api-token-event" className="block text-sm font-medium">{t("settings:api_tokens.event")}</label>
+            <select
+              id="api-token-event"
+              value={eventId}
+              onChange={(e) => setEventId(e.target.value)}
+              className="mt-1 block w-full rounded-md border border-[var(--color-border)] bg-[var(--color-background)] px-3 py-2 text-sm"
+            >
+              <option value="">{t("settings:api_tokens.all_events")}</option>
+              {events?.map((ev) => (
+                <option key={ev.id} value={ev.id}>{ev.name}</option>
+              ))}
+            </select>
+          </div>
+          <div>
+            <label htmlFor="api-token-expiry" className="block text-sm font-medium">{t("settings:api_tokens.expires")}</label>
+            <input
+              id="api-token-expiry"
+              type="date"
+              value={expiresOn}
+              onChange={(e) => setExpiresOn(e.target.value)}
+              className="mt-1 block rounded-md border border-[var(--color-border)] bg-[var(--color-background)] px-3 py-2 text-sm"
+            />
+            <p className="mt-1 text-xs text-[var(--color-muted-foreground)]">{t("settings:api_tokens.expires_hint")}</p>
+          </div>
+          <div className="flex gap-2">
+            <button
+              type="button"
+              onClick={resetForm}
+              className="rounded-md border border-[var(--color-border)] px-3 py-1.5 text-sm hover:bg-[var(--color-muted)]"
+            >
+              {t("common:cancel")}
+            </button>
+            <button
+              type="submit"
+              disabled={createToken.isPending || scopes.length === 0}
+              className="rounded-md bg-[var(--color-primary)] px-3 py-1.5 text-sm text-[var(--color-primary-foreground)] disabled:opacity-50"
+            >
+              {t("common:create")}
+            </button>
+          </div>
+        </form>
+      )}
+
+      {/* Token list */}
+      {isLoading ? (
+        <p className="text-sm text-[var(--color-muted-foreground)]">{t("common:loading")}</p>
+      ) : tokens && tokens.length > 0 ? (
+        <div className="space-y-3">
+          {tokens.map((token) => (
+            <div
+              key={token.id}
+              className="flex items-center justify-between rounded-lg border border-[var(--color-border)] px-4 py-3"
+            >
+              <div>
+                <p className="text-sm font-medium">
+                  {token.name}
+                  <code className="ml-2 text-xs text-[var(--color-muted-foreground)]">{token.prefix}…</code>
+                </p>
+                <APITokenDetails token={token} />
+              </div>
+              {confirmRevoke === token.id ? (
+                <button
+                  type="button"
+                  onClick={() => {
+                    revokeToken.mutate(token.id, { onSuccess: () => setConfirmRevoke(null) });
+                  }}
+                  disabled={revokeToken.isPending}
+                  className="text-sm text-[var(--color-destructive)] hover:underline disabled:opacity-50"
+                >
+                  {t("common:confirm")}
+                </button>
+              ) : (
+                <button
+                  type="button"
+                  onClick={() => setConfirmRevoke(token.id)}
+                  className="text-sm text-[var(--color-destructive)] hover:underline"
+                >
+                  {t("settings:api_tokens.revoke")}
+                </button>
+              )}
+            </div>
+          ))}
+        </div>
+      ) : (
+        <p className="text-sm text-[var(--color-muted-foreground)]">{t("settings:api_tokens.empty")}</p>
+      )}
+    </div>
+  );
+}
+
+// APITokenDetails shows the scopes, event, expiry and last use of a token.
+export function APITokenDetails({ token }: { token: APIToken }) {
+  const { t, i18n } = useTranslation("settings");
+  const expired = token.expires_at !== null && new Date(token.expires_at) < new Date();
+
+  return (
+    <p className="mt-0.5 text-xs text-[var(--color-muted-foreground)]">
+      {token.scopes.join(", ")}
+      {token.event_slug && <> &middot; {token.event_slug}</>}
+      {token.expires_at && (
+        <>
+          {" "}&middot;{" "}
+          <span className={expired ? "text-[var(--color-destructive)]" : undefined}>
+            {expired ? t("api_tokens.expired") : t("api_tokens.expires_on")}:{" "}
+            {new Date(token.expires_at).toLocaleDateString(i18n.language)}
+          </span>
+        </>
+      )}
+      {" "}&middot;{" "}
+      {token.last_used_at
+        ? <>{t("api_tokens.last_used")}: {new Date(token.last_used_at).toLocaleString(i18n.language)}{token.last_used_ip && <> ({token.last_used_ip})</>}</>
+        : t("api_tokens.never_used")}
+    </p>
+  );
+}
@@ -0,0 +1,74 @@
+import { useState } from "react";
+import { useTranslation } from "react-i18next";
+import { useAllAPITokens, useRevokeAnyAPIToken } from "@/hooks/useAPITokens";
+import { APITokenDetails } from "@/pages/APITokensPage";
+
+export function AdminAPITokensPage() {
+  const { t } = useTranslation(["admin", "common"]);
+  const { data: tokens, isLoading } = useAllAPITokens();
+  const revokeToken = useRevokeAnyAPIToken();
+  const [confirmRevoke, setConfirmRevoke] = useState<string | null>(null);
+
+  return (
+    <div>
+      <h2 className="mb-1 text-xl font-bold">{t("api_tokens.title")}</h2>
+      <p className="mb-4 text-sm text-[var(--color-muted-foreground)]">
+        {t("api_tokens.description")}
+      </p>
+
+      {isLoading ? (
+        <p className="text-sm text-[var(--color-muted-foreground)]">{t("common:loading")}</p>
+      ) : tokens && tokens.length > 0 ? (
+        <div className="overflow-x-auto rounded-lg border border-[var(--color-border)]">
+          <table className="w-full text-sm">
+            <thead className="border-b border-[var(--color-border)] bg-[var(--color-muted)]">
+              <tr>
+                <th className="px-4 py-2.5 text-left font-medium">{t("api_tokens.user")}</th>
+                <th className="px-4 py-2.5 text-left font-medium">{t("api_tokens.token")}</th>
+                <th className="px-4 py-2.5 text-left font-medium"></th>
+              </tr>
+            </thead>
+            <tbody className="divide-y divide-[var(--color-border)]">
+              {tokens.map((token) => (
+                <tr key={token.id} className="hover:bg-[var(--color-muted)]/50">
+                  <td className="px-4 py-2.5">
+                    <span className="font-medium">@{token.username}</span>
+                  </td>
+                  <td className="px-4 py-2.5">
+                    {token.name}
+                    <code className="ml-2 text-xs text-[var(--color-muted-foreground)]">{token.prefix}…</code>
+                    <APITokenDetails token={token} />
+                  </td>
+                  <td className="px-4 py-2.5 text-right">
+                    {confirmRevoke === token.id ? (
+                      <button
+                        type="button"
+                        onClick={() => {
+                          revokeToken.mutate(token.id, { onSuccess: () => setConfirmRevoke(null) });
+                        }}
+                        disabled={revokeToken.isPending}
+                        className="text-sm text-[var(--color-destructive)] hover:underline disabled:opacity-50"
+                      >
+                        {t("common:confirm")}
+                      </button>
+                    ) : (
+                      <button
+                        type="button"
+                        onClick={() => setConfirmRevoke(token.id)}
+                        className="text-sm text-[var(--color-destructive)] hover:underline"
+                      >
+                        {t("api_tokens.revoke")}
+                      </button>
+                    )}
+                  </td>
+                </tr>
+              ))}
+            </tbody>
+          </table>
+        </div>
+      ) : (
+        <p className="text-sm text-[var(--color-muted-foreground)]">{t("api_tokens.empty")}</p>
+      )}
+    </div>
+  );
+}
@@ -7,6 +7,7 @@ import { useTeams } from "@/hooks/useTeams";
 import { useAuth } from "@/contexts/AuthContext";
 import { useTimeFormat } from "@/hooks/useTimeFormat";
 import { useSSE } from "@/hooks/useSSE";
+import { useEventNotificationSettings, useSetEventNotificationState } from "@/hooks/useNotifications";
 import { useViewParams } from "@/hooks/useViewParams";
 import { useGridNavigation } from "@/hooks/useKeyboard";
 import { generateTimeSlots } from "@/lib/time";
@@ -22,7 +23,7 @@ import { ExportMenu } from "@/components/export/ExportMenu";
 import { PrintContainer } from "@/components/export/PrintContainer";
 import { AvailabilityEditor } from "@/components/availability/AvailabilityEditor";
 import { GridSkeleton } from "@/components/common/Skeleton";
-import type { Shift, PrintConfig } from "@/api/types";
+import type { Shift, PrintConfig, EventNotificationState } from "@/api/types";
 
 export function EventPage() {
   const { slug } = useParams<{ slug: string }>();
@@ -34,6 +35,8 @@ export function EventPage() {
   const { data: eventTeams } = useEventTeams(slug!);
   // Connect to SSE for real-time updates on this event
   useSSE({ slug, enabled: !!slug });
+  const { data: notificationSettings } = useEventNotificationSettings(slug!);
+  const setNotificationState = useSetEventNotificationState(slug!);
   const { user } = useAuth();
   const { data: pinnedUsersData } = useEventPinnedUsers(slug!, !!user && (user.role === "super_admin" || !!event?.is_event_admin));
   const hour12 = useTimeFormat();
@@ -266,6 +269,17 @@ export function EventPage() {
               {t("events:public")}
             </a>
           )}
+          <select
+            value={notificationSettings?.state ?? "default"}
+            onChange={(e) => setNotificationState.mutate(e.target.value as EventNotificationState)}
+            className="rounded-md border border-[var(--color-border)] bg-[var(--color-background)] px-2 py-1.5 text-sm"
+            title={t("events:notifications")}
+            aria-label={t("events:notifications")}
+          >
+            <option value="default">{t("events:notifications_default")}</option>
+            <option value="watching">{t("events:notifications_watching")}</option>
+            <option value="muted">{t("events:notifications_muted")}</option>
+          </select>
           <ExportMenu
             slug={event.slug}
             event={event}
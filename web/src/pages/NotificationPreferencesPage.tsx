@@ -3,28 +3,45 @@ import {
   useNotificationPreferences,
   useUpdateNotificationPreference,
 } from "@/hooks/useNotifications";
+import { useAuth } from "@/contexts/AuthContext";
 import { SettingsTabs } from "@/components/common/SettingsTabs";
+import { PushDevices } from "@/components/notifications/PushDevices";
+import { WebhookManager } from "@/components/webhooks/WebhookManager";
 
 const TRIGGER_TYPES = [
+  "shift.assigned_to_you",
+  "shift.removed_from_you",
+  "shift.time_changed",
   "shift.created",
   "shift.updated",
   "shift.deleted",
+  "admin.shift.created",
+  "admin.shift.updated",
+  "admin.shift.deleted",
   "event.locked",
   "event.unlocked",
+  "event.announcement",
 ];
 
-const CHANNELS = ["in_app", "email"];
+// Triggers only super admins are notified about
+const SUPER_ADMIN_TRIGGER_TYPES = ["webhook.disabled"];
+
+const CHANNELS = ["in_app", "email", "push"];
 
 export function NotificationPreferencesPage() {
   const { t } = useTranslation(["admin", "common"]);
+  const { user } = useAuth();
   const { data: preferences = [], isLoading } = useNotificationPreferences();
   const updatePref = useUpdateNotificationPreference();
+  const triggerTypes = user?.role === "super_admin"
+    ? [...TRIGGER_TYPES, ...SUPER_ADMIN_TRIGGER_TYPES]
+    : TRIGGER_TYPES;
 
   function isEnabled(triggerType: string, channel: string) {
     const pref = preferences.find(
       (p) => p.trigger_type === triggerType && p.channel === channel
     );
-    return pref ? pref.is_enabled : true; // Default enabled
+    return pref ? pref.is_enabled : false; // The API returns effective defaults
   }
 
   function handleToggle(triggerType: string, channel: string) {
@@ -38,11 +55,19 @@ export function NotificationPreferencesPage() {
 
   function triggerLabel(trigger: string) {
     const labels: Record<string, string> = {
-      "shift.created": t("notifications.trigger_shift_created", "Shift created"),
-      "shift.updated": t("notifications.trigger_shift_updated", "Shift updated"),
-      "shift.deleted": t("notifications.trigger_shift_deleted", "Shift deleted"),
+      "shift.assigned_to_you": t("notifications.trigger_shift_assigned_to_you", "Shift assigned to me"),
+      "shift.removed_from_you": t("notifications.trigger_shift_removed_from_you", "My shift removed"),
+      "shift.time_changed": t("notifications.trigger_shift_time_changed", "My shift moved"),
+      "shift.created": t("notifications.trigger_shift_created", "Overlapping team shift created"),
+      "shift.updated": t("notifications.trigger_shift_updated", "Overlapping team shift updated"),
+      "shift.deleted": t("notifications.trigger_shift_deleted", "Overlapping team shift deleted"),
+      "admin.shift.created": t("notifications.trigger_admin_shift_created", "Shift created (event admin)"),
+      "admin.shift.updated": t("notifications.trigger_admin_shift_updated", "Shift updated (event admin)"),
+      "admin.shift.deleted": t("notifications.trigger_admin_shift_deleted", "Shift deleted (event admin)"),
       "event.locked": t("notifications.trigger_event_locked", "Event locked"),
       "event.unlocked": t("notifications.trigger_event_unlocked", "Event unlocked"),
+      "event.announcement": t("notifications.trigger_event_announcement", "Announcements"),
+      "webhook.disabled": t("notifications.trigger_webhook_disabled", "Webhook disabled after failures"),
     };
     return labels[trigger] || trigger;
   }
@@ -51,6 +76,7 @@ export function NotificationPreferencesPage() {
     const labels: Record<string, string> = {
       in_app: t("notifications.channel_in_app", "In-App"),
       email: t("notifications.channel_email", "Email"),
+      push: t("notifications.channel_push", "Push"),
     };
     return labels[channel] || channel;
   }
@@ -83,7 +109,7 @@ export function NotificationPreferencesPage() {
             </tr>
           </thead>
           <tbody>
-            {TRIGGER_TYPES.map((trigger) => (
+            {triggerTypes.map((trigger) => (
               <tr key={trigger} className="border-b border-[var(--color-border)] last:border-b-0">
                 <td className="px-4 py-3">{triggerLabel(trigger)}</td>
                 {CHANNELS.map((ch) => (
@@ -110,6 +136,15 @@ export function NotificationPreferencesPage() {
           </tbody>
         </table>
       </div>
+
+      <PushDevices />
+
+      <div className="mt-8">
+        <p className="mb-4 text-sm text-[var(--color-muted-foreground)]">
+          {t("personal_webhooks.description", "Send changes to your own shifts in any event to your own webhook, for example a chat bot or a calendar sync.")}
+        </p>
+        <WebhookManager personal />
+      </div>
     </div>
   );
 }
@@ -3,17 +3,20 @@ import { useTranslation } from "react-i18next";
 import { useQuery, useMutation, useQueryClient } from "@tanstack/react-query";
 import { oauthApi } from "@/api/oauth";
 import { ApiError } from "@/api/client";
-import type { OAuthProvider, CreateOAuthProviderRequest } from "@/api/types";
+import type { OAuthProvider, CreateOAuthProviderRequest, UpdateOAuthProviderRequest } from "@/api/types";
 import { ConfirmDialog } from "@/components/common/ConfirmDialog";
 
 const emptyForm: CreateOAuthProviderRequest = {
   name: "",
+  provider_type: "oidc",
   client_id: "",
   client_secret: "",
+  issuer_url: "",
   authorize_url: "",
   token_url: "",
   userinfo_url: "",
   scopes: "openid email profile",
+  rp_logout: false,
 };
 
 export function OAuthProvidersPage() {
@@ -50,7 +53,7 @@ export function OAuthProvidersPage() {
       data,
     }: {
       id: string;
-      data: Partial<CreateOAuthProviderRequest & { is_enabled: boolean }>;
+      data: UpdateOAuthProviderRequest;
     }) => oauthApi.updateProvider(id, data),
     onSuccess: () => {
       queryClient.invalidateQueries({ queryKey: ["oauth-providers-admin"] });
@@ -76,12 +79,15 @@ export function OAuthProvidersPage() {
     setEditId(provider.id);
     setForm({
       name: provider.name,
+      provider_type: provider.provider_type,
       client_id: provider.client_id,
       client_secret: "",
+      issuer_url: provider.issuer_url,
       authorize_url: provider.authorize_url,
       token_url: provider.token_url,
       userinfo_url: provider.userinfo_url,
       scopes: provider.scopes,
+      rp_logout: provider.rp_logout,
     });
     setError("");
   }
@@ -90,14 +96,20 @@ export function OAuthProvidersPage() {
     e.preventDefault();
     setError("");
     if (editId) {
-      const data: Record<string, string> = {};
+      const data: UpdateOAuthProviderRequest = { provider_type: form.provider_type };
       if (form.name) data.name = form.name;
       if (form.client_id) data.client_id = form.client_id;
       if (form.client_secret) data.client_secret = form.client_secret;
-      if (form.authorize_url) data.authorize_url = form.authorize_url;
-      if (form.token_url) data.token_url = form.token_url;
-      if (form.userinfo_url) data.userinfo_url = form.userinfo_url;
       if (form.scopes) data.scopes = form.scopes;
+      if (isOIDC) {
+        // Sending the issuer discovers the endpoints again
+        data.issuer_url = form.issuer_url;
+        data.rp_logout = form.rp_logout;
+      } else {
+        if (form.authorize_url) data.authorize_url = form.authorize_url;
+        if (form.token_url) data.token_url = form.token_url;
+        if (form.userinfo_url) data.userinfo_url = form.userinfo_url;
+      }
       updateMutation.mutate({ id: editId, data });
     } else {
       createMutation.mutate(form);
@@ -112,6 +124,9 @@ export function OAuthProvidersPage() {
   }
 
   const isSubmitting = createMutation.isPending || updateMutation.isPending;
+  const isOIDC = form.provider_type === "oidc";
+  // Switching an existing provider to OAuth2 needs all endpoint URLs
+  const editingType = providers?.find((p) => p.id === editId)?.provider_type;
 
   const doDeleteProvider = useCallback(() => {
     if (!deletingProvider) return;
@@ -142,6 +157,9 @@ export function OAuthProvidersPage() {
                       className={`inline-block h-2 w-2 rounded-full ${provider.is_enabled ? "bg-[var(--color-success)]" : "bg-[var(--color-muted-foreground)]"}`}
                     />
                     <span className="font-medium">{provider.name}</span>
+                    <span className="rounded bg-[var(--color-muted)] px-1.5 py-0.5 text-xs text-[var(--color-muted-foreground)]">
+                      {t(`admin:oauth.type_${provider.provider_type}`)}
+                    </span>
                   </div>
                   <div className="flex items-center gap-2">
                     <button
@@ -171,6 +189,8 @@ export function OAuthProvidersPage() {
                 </div>
                 <p className="mt-1 text-xs text-[var(--color-muted-foreground)]">
                   {provider.client_id} &middot; {provider.scopes}
+                  {provider.provider_type === "oidc" && <> &middot; {provider.issuer_url}</>}
+                  {provider.rp_logout && <> &middot; {t("admin:oauth.rp_logout")}</>}
                 </p>
               </div>
             ))
@@ -195,6 +215,23 @@ export function OAuthProvidersPage() {
           </div>
         )}
         <form onSubmit={handleSubmit} className="mt-4 space-y-4">
+          <div>
+            <label htmlFor="oauth-provider-type" className="block text-sm font-medium">
+              {t("admin:oauth.provider_type")}
+            </label>
+            <select
+              id="oauth-provider-type"
+              value={form.provider_type}
+              onChange={(e) =>
+                setForm({ ...form, provider_type: e.target.value as CreateOAuthProviderRequest["provider_type"] })
+              }
+              className="mt-1 block w-full rounded-md border border-[var(--color-border)] bg-[var(--color-background)] px-3 py-2 text-sm"
+            >
+              <option value="oidc">{t("admin:oauth.type_oidc")}</option>
+              <option value="oauth2">{t("admin:oauth.type_oauth2")}</option>
+            </select>
+          </div>
+
           <div className="grid grid-cols-2 gap-4">
             <div>
               <label className="block text-sm font-medium">
@@ -255,51 +292,90 @@ export function OAuthProvidersPage() {
             </div>
           </div>
 
-          <div>
-            <label className="block text-sm font-medium">
-              {t("admin:oauth.authorize_url")}
-            </label>
-            <input
-              type="url"
-              required={!editId}
-              value={form.authorize_url}
-              onChange={(e) =>
-                setForm({ ...form, authorize_url: e.target.value })
-              }
-              placeholder="https://github.com/login/oauth/authorize"
-              className="mt-1 block w-full rounded-md border border-[var(--color-border)] bg-[var(--color-background)] px-3 py-2 text-sm"
-            />
-          </div>
+          {isOIDC ? (
+            <>
+              <div>
+                <label htmlFor="oauth-issuer-url" className="block text-sm font-medium">
+                  {t("admin:oauth.issuer_url")}
+                </label>
+                <input
+                  id="oauth-issuer-url"
+                  type="url"
+                  required
+                  value={form.issuer_url}
+                  onChange={(e) => setForm({ ...form, issuer_url: e.target.value })}
+                  placeholder="https://accounts.example.org/realms/main"
+                  className="mt-1 block w-full rounded-md border border-[var(--color-border)] bg-[var(--color-background)] px-3 py-2 text-sm"
+                />
+                <p className="mt-1 text-xs text-[var(--color-muted-foreground)]">
+                  {t("admin:oauth.issuer_url_hint")}
+                </p>
+              </div>
 
-          <div>
-            <label className="block text-sm font-medium">
-              {t("admin:oauth.token_url")}
-            </label>
-            <input
-              type="url"
-              required={!editId}
-              value={form.token_url}
-              onChange={(e) => setForm({ ...form, token_url: e.target.value })}
-              placeholder="https://github.com/login/oauth/access_token"
-              className="mt-1 block w-full rounded-md border border-[var(--color-border)] bg-[var(--color-background)] px-3 py-2 text-sm"
-            />
-          </div>
+              <label className="flex items-start gap-2 text-sm">
+                <input
+                  type="checkbox"
+                  checked={form.rp_logout}
+                  onChange={(e) => setForm({ ...form, rp_logout: e.target.checked })}
+                  className="mt-0.5 rounded"
+                />
+                <span>
+                  {t("admin:oauth.rp_logout")}
+                  <span className="block text-xs text-[var(--color-muted-foreground)]">
+                    {t("admin:oauth.rp_logout_hint")}
+                  </span>
+                </span>
+              </label>
+            </>
+          ) : (
+            <>
+              <div>
+                <label className="block text-sm font-medium">
+                  {t("admin:oauth.authorize_url")}
+                </label>
+                <input
+                  type="url"
+                  required={!editId || form.provider_type !== editingType}
+                  value={form.authorize_url}
+                  onChange={(e) =>
+                    setForm({ ...form, authorize_url: e.target.value })
+                  }
+                  placeholder="https://github.com/login/oauth/authorize"
+                  className="mt-1 block w-full rounded-md border border-[var(--color-border)] bg-[var(--color-background)] px-3 py-2 text-sm"
+                />
+              </div>
 
-          <div>
-            <label className="block text-sm font-medium">
-              {t("admin:oauth.userinfo_url")}
-            </label>
-            <input
-              type="url"
-              required={!editId}
-              value={form.userinfo_url}
-              onChange={(e) =>
-                setForm({ ...form, userinfo_url: e.target.value })
-              }
-              placeholder="https://api.github.com/user"
-              className="mt-1 block w-full rounded-md border border-[var(--color-border)] bg-[var(--color-background)] px-3 py-2 text-sm"
-            />
-          </div>
+              <div>
+                <label className="block text-sm font-medium">
+                  {t("admin:oauth.token_url")}
+                </label>
+                <input
+                  type="url"
+                  required={!editId || form.provider_type !== editingType}
+                  value={form.token_url}
+                  onChange={(e) => setForm({ ...form, token_url: e.target.value })}
+                  placeholder="https://github.com/login/oauth/access_token"
+                  className="mt-1 block w-full rounded-md border border-[var(--color-border)] bg-[var(--color-background)] px-3 py-2 text-sm"
+                />
+              </div>
+
+              <div>
+                <label className="block text-sm font-medium">
+                  {t("admin:oauth.userinfo_url")}
+                </label>
+                <input
+                  type="url"
+                  required={!editId || form.provider_type !== editingType}
+                  value={form.userinfo_url}
+                  onChange={(e) =>
+                    setForm({ ...form, userinfo_url: e.target.value })
+                  }
+                  placeholder="https://api.github.com/user"
+                  className="mt-1 block w-full rounded-md border border-[var(--color-border)] bg-[var(--color-background)] px-3 py-2 text-sm"
+                />
+              </div>
+            </>
+          )}
 
           <div className="flex gap-2">
             {editId && (
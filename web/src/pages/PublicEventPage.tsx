@@ -29,6 +29,12 @@ export function PublicEventPage() {
     enabled: !!slug,
   });
 
+  const { data: announcements = [] } = useQuery({
+    queryKey: ["public-announcements", slug],
+    queryFn: () => publicApi.getAnnouncements(slug!),
+    enabled: !!slug && !!event,
+  });
+
   const { data: gridData, isLoading: isGridLoading } = useQuery({
     queryKey: ["public-grid", slug],
     queryFn: () => publicApi.getGrid(slug!),
@@ -182,6 +188,25 @@ export function PublicEventPage() {
         )}
       </div>
 
+      {announcements.length > 0 && (
+        <div className="mt-6">
+          <h2 className="mb-3 text-lg font-semibold">{t("events:announcements")}</h2>
+          <ul className="space-y-3">
+            {announcements.map((a) => (
+              <li key={a.id} className="rounded-lg border border-[var(--color-border)] p-3">
+                <div className="flex items-baseline justify-between gap-3">
+                  <span className="font-medium">{a.title}</span>
+                  <span className="shrink-0 text-xs text-[var(--color-muted-foreground)]">
+                    {dateFormatter.format(new Date(a.created_at))}
+                  </span>
+                </div>
+                <p className="mt-1 whitespace-pre-line text-sm text-[var(--color-muted-foreground)]">{a.body}</p>
+              </li>
+            ))}
+          </ul>
+        </div>
+      )}
+
       {/* Shift Grid toolbar */}
       <div className="mt-8 mb-4 flex flex-wrap items-center justify-between gap-3">
         <h2 className="text-lg font-semibold">{t("shifts:title")}</h2>